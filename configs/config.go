@@ -7,14 +7,46 @@ import (
 )
 
 type Config struct {
-	AppPort              string `mapstructure:"APP_PORT"`
-	DBUsername           string `mapstructure:"DBUSERNAME"`
-	DBPassword           string `mapstructure:"DBPASSWORD"`
-	DBAddress            string `mapstructure:"DBADDRESS"`
-	DBName               string `mapstructure:"DBNAME"`
-	JWTSecret            string `mapstructure:"JWT_SECRET"`
-	MidtransServerKeyDev string `mapstructure:"MIDTRANS_SERVER_KEY_DEV"`
-	AuthString           string `mapstructure:"AUTH_STRING"`
+	AppPort                    string  `mapstructure:"APP_PORT"`
+	TrustedProxies             string  `mapstructure:"TRUSTED_PROXIES"`
+	DBUsername                 string  `mapstructure:"DBUSERNAME"`
+	DBPassword                 string  `mapstructure:"DBPASSWORD"`
+	DBAddress                  string  `mapstructure:"DBADDRESS"`
+	DBName                     string  `mapstructure:"DBNAME"`
+	JWTSecret                  string  `mapstructure:"JWT_SECRET"`
+	AccessTokenTTLMinutes      int     `mapstructure:"ACCESS_TOKEN_TTL_MINUTES"`
+	RefreshTokenTTLHours       int     `mapstructure:"REFRESH_TOKEN_TTL_HOURS"`
+	MidtransServerKeyDev       string  `mapstructure:"MIDTRANS_SERVER_KEY_DEV"`
+	AuthString                 string  `mapstructure:"AUTH_STRING"`
+	OrderPaymentTTLMinutes     int     `mapstructure:"ORDER_PAYMENT_TTL_MINUTES"`
+	OrderExpiryIntervalSeconds int     `mapstructure:"ORDER_EXPIRY_INTERVAL_SECONDS"`
+	LateReturnGraceMinutes     int     `mapstructure:"LATE_RETURN_GRACE_MINUTES"`
+	PricingHolidays            string  `mapstructure:"PRICING_HOLIDAYS"`
+	PaymentProvider            string  `mapstructure:"PAYMENT_PROVIDER"`
+	FakePaymentBaseURL         string  `mapstructure:"FAKE_PAYMENT_BASE_URL"`
+	ReconciliationTime         string  `mapstructure:"RECONCILIATION_TIME"`
+	ReconciliationReportDir    string  `mapstructure:"RECONCILIATION_REPORT_DIR"`
+	PaymentRetryAttempts       int     `mapstructure:"PAYMENT_RETRY_ATTEMPTS"`
+	PaymentRetryBackoffMS      int     `mapstructure:"PAYMENT_RETRY_BACKOFF_MS"`
+	PlatformCommissionPercent  float32 `mapstructure:"PLATFORM_COMMISSION_PERCENT"`
+	FinanceAPIKey              string  `mapstructure:"FINANCE_API_KEY"`
+	InvoiceTaxName             string  `mapstructure:"INVOICE_TAX_NAME"`
+	InvoiceTaxPercent          float32 `mapstructure:"INVOICE_TAX_PERCENT"`
+	AppBaseURL                 string  `mapstructure:"APP_BASE_URL"`
+	Mailer                     string  `mapstructure:"MAILER"`
+	MailFrom                   string  `mapstructure:"MAIL_FROM"`
+	MailDir                    string  `mapstructure:"MAIL_DIR"`
+	SMTPHost                   string  `mapstructure:"SMTP_HOST"`
+	SMTPPort                   int     `mapstructure:"SMTP_PORT"`
+	SMTPUsername               string  `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword               string  `mapstructure:"SMTP_PASSWORD"`
+	EmailVerificationTTLHours  int     `mapstructure:"EMAIL_VERIFICATION_TTL_HOURS"`
+	PasswordResetTTLMinutes    int     `mapstructure:"PASSWORD_RESET_TTL_MINUTES"`
+	LoginFailureWindowMinutes  int     `mapstructure:"LOGIN_FAILURE_WINDOW_MINUTES"`
+	LoginDelayAfterFailures    int     `mapstructure:"LOGIN_DELAY_AFTER_FAILURES"`
+	LoginLockoutFailures       int     `mapstructure:"LOGIN_LOCKOUT_FAILURES"`
+	LoginIPLockoutFailures     int     `mapstructure:"LOGIN_IP_LOCKOUT_FAILURES"`
+	LoginLockoutMinutes        int     `mapstructure:"LOGIN_LOCKOUT_MINUTES"`
 }
 
 var Cfg *Config
@@ -26,6 +58,33 @@ func InitConfig() {
 	viper.SetConfigType("env")
 	viper.AddConfigPath(".")
 
+	viper.SetDefault("ACCESS_TOKEN_TTL_MINUTES", 60)
+	viper.SetDefault("REFRESH_TOKEN_TTL_HOURS", 720)
+	viper.SetDefault("ORDER_PAYMENT_TTL_MINUTES", 60)
+	viper.SetDefault("ORDER_EXPIRY_INTERVAL_SECONDS", 60)
+	viper.SetDefault("LATE_RETURN_GRACE_MINUTES", 15)
+	viper.SetDefault("PAYMENT_PROVIDER", "midtrans")
+	viper.SetDefault("FAKE_PAYMENT_BASE_URL", "http://localhost:8080")
+	viper.SetDefault("RECONCILIATION_TIME", "02:00")
+	viper.SetDefault("RECONCILIATION_REPORT_DIR", "reports")
+	viper.SetDefault("PAYMENT_RETRY_ATTEMPTS", 3)
+	viper.SetDefault("PAYMENT_RETRY_BACKOFF_MS", 200)
+	viper.SetDefault("PLATFORM_COMMISSION_PERCENT", 10)
+	viper.SetDefault("INVOICE_TAX_NAME", "PPN")
+	viper.SetDefault("INVOICE_TAX_PERCENT", 11)
+	viper.SetDefault("APP_BASE_URL", "http://localhost:3000")
+	viper.SetDefault("MAILER", "file")
+	viper.SetDefault("MAIL_FROM", "Go Rent Bike <no-reply@localhost>")
+	viper.SetDefault("MAIL_DIR", "mails")
+	viper.SetDefault("SMTP_PORT", 587)
+	viper.SetDefault("EMAIL_VERIFICATION_TTL_HOURS", 48)
+	viper.SetDefault("PASSWORD_RESET_TTL_MINUTES", 30)
+	viper.SetDefault("LOGIN_FAILURE_WINDOW_MINUTES", 15)
+	viper.SetDefault("LOGIN_DELAY_AFTER_FAILURES", 3)
+	viper.SetDefault("LOGIN_LOCKOUT_FAILURES", 10)
+	viper.SetDefault("LOGIN_IP_LOCKOUT_FAILURES", 50)
+	viper.SetDefault("LOGIN_LOCKOUT_MINUTES", 15)
+
 	if err := viper.ReadInConfig(); err != nil {
 		fmt.Printf("error read env: %v", err)
 	}
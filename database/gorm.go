@@ -27,5 +27,5 @@ func InitMysqlDatabase() {
 
 	DB = db
 
-	_ = DB.AutoMigrate(&model.User{}, &model.Renter{}, &model.Category{}, &model.Bike{}, &model.Payment{}, &model.Order{}, &model.OrderDetail{}, &model.Review{}, &model.History{}, &model.Report{})
+	_ = DB.AutoMigrate(&model.User{}, &model.Renter{}, &model.Category{}, &model.Bike{}, &model.Payment{}, &model.Order{}, &model.OrderDetail{}, &model.Review{}, &model.History{}, &model.Report{}, &model.OrderStatusHistory{}, &model.PricingRule{}, &model.Voucher{}, &model.VoucherUsage{}, &model.PaymentNotification{}, &model.Refund{}, &model.LedgerEntry{}, &model.PayoutBatch{}, &model.Payout{}, &model.Wallet{}, &model.WalletTransaction{}, &model.Invoice{}, &model.Session{}, &model.RevokedToken{}, &model.UserToken{}, &model.LoginAttempt{}, &model.LoginThrottle{})
 }
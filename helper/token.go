@@ -1,6 +1,10 @@
 package helper
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"strings"
 	"time"
@@ -10,19 +14,41 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func CreateToken(userId string, role string) (string, error) {
+// CreateToken signs the access token of a session. The jti tells the token apart when it has to be
+// revoked before it expires.
+func CreateToken(userId string, role string, sessionId string, jti string, expiresAt time.Time) (string, error) {
 	claims := jwt.MapClaims{}
 
 	claims["user_id"] = userId
 	claims["role"] = role
+	claims["session_id"] = sessionId
+	claims["jti"] = jti
 	claims["iat"] = time.Now().Unix()
-	claims["exp"] = time.Now().Add(1 * time.Hour).Unix()
+	claims["exp"] = expiresAt.Unix()
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	return token.SignedString([]byte(configs.Cfg.JWTSecret))
 }
 
+// CreateOpaqueToken creates a random token for the refresh tokens and the links sent by email,
+// only its hash is ever stored.
+func CreateOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
+
 func ExtractToken(c echo.Context) (interface{}, error) {
 	tokenString := c.Request().Header.Get("Authorization")
 
@@ -55,3 +81,29 @@ func ExtractToken(c echo.Context) (interface{}, error) {
 
 	return nil, err
 }
+
+// ExtractTokenClaims reads the claims of the token that the echo JWT middleware already verified
+// and stored in the context, it returns an empty map when the request is not authenticated.
+func ExtractTokenClaims(c echo.Context) map[string]string {
+	data := map[string]string{}
+
+	token, ok := c.Get("user").(*jwt.Token)
+
+	if !ok {
+		return data
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+
+	if !ok {
+		return data
+	}
+
+	for _, key := range []string{"user_id", "role", "session_id", "jti"} {
+		if value, ok := claims[key].(string); ok {
+			data[key] = value
+		}
+	}
+
+	return data
+}
@@ -0,0 +1,365 @@
+package rest_http
+
+import (
+	"errors"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/arvinpaundra/go-rent-bike/helper"
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+type AuthController struct {
+	authUsecase usecase.AuthUsecase
+}
+
+func NewAuthController(authUsecase usecase.AuthUsecase) *AuthController {
+	return &AuthController{authUsecase: authUsecase}
+}
+
+func (h *AuthController) HandlerLogin(c echo.Context) error {
+	loginDTO := dto.LoginDTO{}
+
+	if err := c.Bind(&loginDTO); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status":  "error",
+			"message": "email and password is required",
+			"data":    nil,
+		})
+	}
+
+	authToken, err := h.authUsecase.Login(loginDTO.Email, loginDTO.Password, c.Request().UserAgent(), c.RealIP())
+
+	if err != nil {
+		var throttledErr *usecase.LoginThrottledError
+
+		if errors.As(err, &throttledErr) {
+			retryAfter := math.Ceil(time.Until(throttledErr.RetryAt).Seconds())
+
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+
+			c.Response().Header().Set("Retry-After", strconv.Itoa(int(retryAfter)))
+
+			return c.JSON(http.StatusTooManyRequests, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"status":  "error",
+				"message": "wrong email or password",
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "login success",
+		"data":    authToken,
+	})
+}
+
+func (h *AuthController) HandlerRefreshToken(c echo.Context) error {
+	refreshTokenDTO := dto.RefreshTokenDTO{}
+
+	if err := c.Bind(&refreshTokenDTO); err != nil || refreshTokenDTO.RefreshToken == "" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status":  "error",
+			"message": "refresh token is required",
+			"data":    nil,
+		})
+	}
+
+	authToken, err := h.authUsecase.RefreshToken(refreshTokenDTO.RefreshToken, c.Request().UserAgent(), c.RealIP())
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrInvalidRefreshToken) {
+			return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+				"status":  "error",
+				"message": "invalid or expired refresh token",
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success refresh token",
+		"data":    authToken,
+	})
+}
+
+func (h *AuthController) HandlerLogout(c echo.Context) error {
+	claims := helper.ExtractTokenClaims(c)
+
+	err := h.authUsecase.RevokeSession(claims["user_id"], claims["session_id"])
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "session not found",
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "logout success",
+		"data":    nil,
+	})
+}
+
+func (h *AuthController) HandlerFindAllSessions(c echo.Context) error {
+	claims := helper.ExtractTokenClaims(c)
+
+	sessions, err := h.authUsecase.FindAllSessions(claims["user_id"], claims["session_id"])
+
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success get all sessions",
+		"data": map[string]interface{}{
+			"sessions": sessions,
+		},
+	})
+}
+
+func (h *AuthController) HandlerFindAllLoginAttempts(c echo.Context) error {
+	attempts, err := h.authUsecase.FindAllLoginAttempts(c.QueryParam("email"), c.QueryParam("ip_address"))
+
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success get all login attempts",
+		"data": map[string]interface{}{
+			"login_attempts": attempts,
+		},
+	})
+}
+
+func (h *AuthController) HandlerRevokeSession(c echo.Context) error {
+	sessionId := c.Param("id")
+	userId := helper.ExtractTokenClaims(c)["user_id"]
+
+	err := h.authUsecase.RevokeSession(userId, sessionId)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "session not found",
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success revoke session",
+		"data":    nil,
+	})
+}
+
+func (h *AuthController) HandlerForgotPassword(c echo.Context) error {
+	forgotPasswordDTO := dto.ForgotPasswordDTO{}
+
+	if err := c.Bind(&forgotPasswordDTO); err != nil || forgotPasswordDTO.Email == "" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status":  "error",
+			"message": "email is required",
+			"data":    nil,
+		})
+	}
+
+	err := h.authUsecase.ForgotPassword(forgotPasswordDTO.Email)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrMailNotSent) {
+			return c.JSON(http.StatusServiceUnavailable, map[string]interface{}{
+				"status":  "error",
+				"message": "email could not be sent, try again later",
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	// the same answer is given for an email nobody registered with
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "a password reset link was sent to the email if it is registered",
+		"data":    nil,
+	})
+}
+
+func (h *AuthController) HandlerResetPassword(c echo.Context) error {
+	resetPasswordDTO := dto.ResetPasswordDTO{}
+
+	if err := c.Bind(&resetPasswordDTO); err != nil || resetPasswordDTO.Token == "" || resetPasswordDTO.Password == "" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status":  "error",
+			"message": "token and password is required",
+			"data":    nil,
+		})
+	}
+
+	err := h.authUsecase.ResetPassword(resetPasswordDTO.Token, resetPasswordDTO.Password)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrInvalidUserToken) {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "password reset success, every session was logged out",
+		"data":    nil,
+	})
+}
+
+func (h *AuthController) HandlerVerifyEmail(c echo.Context) error {
+	verifyEmailDTO := dto.VerifyEmailDTO{}
+
+	if err := c.Bind(&verifyEmailDTO); err != nil || verifyEmailDTO.Token == "" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status":  "error",
+			"message": "token is required",
+			"data":    nil,
+		})
+	}
+
+	err := h.authUsecase.VerifyEmail(verifyEmailDTO.Token)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrInvalidUserToken) {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "email verified",
+		"data":    nil,
+	})
+}
+
+func (h *AuthController) HandlerResendEmailVerification(c echo.Context) error {
+	userId := helper.ExtractTokenClaims(c)["user_id"]
+
+	err := h.authUsecase.SendEmailVerification(userId)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrEmailAlreadyVerified) {
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrMailNotSent) {
+			return c.JSON(http.StatusServiceUnavailable, map[string]interface{}{
+				"status":  "error",
+				"message": "email could not be sent, try again later",
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "user not found",
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "verification email sent",
+		"data":    nil,
+	})
+}
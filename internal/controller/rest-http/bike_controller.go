@@ -4,9 +4,12 @@ import (
 	"errors"
 	"github.com/arvinpaundra/go-rent-bike/pkg"
 	"net/http"
+	"time"
 
+	"github.com/arvinpaundra/go-rent-bike/helper"
 	"github.com/arvinpaundra/go-rent-bike/internal/dto"
 	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/pricing"
 	"github.com/arvinpaundra/go-rent-bike/internal/usecase"
 	"github.com/labstack/echo/v4"
 )
@@ -30,7 +33,9 @@ func (h *BikeController) HandlerAddNewBike(c echo.Context) error {
 		})
 	}
 
-	err := h.bikeUsecase.CreateNewBike(bikeDTO)
+	actorId := helper.ExtractTokenClaims(c)["user_id"]
+
+	err := h.bikeUsecase.CreateNewBike(actorId, bikeDTO)
 
 	if err != nil {
 		if errors.Is(err, pkg.ErrRecordNotFound) {
@@ -41,6 +46,30 @@ func (h *BikeController) HandlerAddNewBike(c echo.Context) error {
 			})
 		}
 
+		if errors.Is(err, pkg.ErrForbidden) {
+			return c.JSON(http.StatusForbidden, map[string]interface{}{
+				"status":  "error",
+				"message": "you are not allowed to add bikes to this renter",
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrRenterSuspended) {
+			return c.JSON(http.StatusForbidden, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrInvalidPricing) {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
 		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
 			"status":  "error",
 			"message": err.Error(),
@@ -167,6 +196,104 @@ func (h *BikeController) HandlerFindBikesByCategory(c echo.Context) error {
 	})
 }
 
+func (h *BikeController) HandlerCheckBikeAvailability(c echo.Context) error {
+	bikeId := c.Param("id")
+
+	startAt, errStart := time.Parse(time.RFC3339, c.QueryParam("start_at"))
+	endAt, errEnd := time.Parse(time.RFC3339, c.QueryParam("end_at"))
+
+	if errStart != nil || errEnd != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status":  "error",
+			"message": "start_at and end_at must be RFC3339 timestamps",
+			"data":    nil,
+		})
+	}
+
+	availability, err := h.bikeUsecase.CheckBikeAvailability(bikeId, startAt, endAt)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "bike not found",
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrInvalidRentWindow) {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success check bike availability",
+		"data": map[string]*dto.BikeAvailabilityDTO{
+			"availability": availability,
+		},
+	})
+}
+
+func (h *BikeController) HandlerQuoteBike(c echo.Context) error {
+	bikeId := c.Param("id")
+
+	startAt, errStart := time.Parse(time.RFC3339, c.QueryParam("start"))
+	endAt, errEnd := time.Parse(time.RFC3339, c.QueryParam("end"))
+
+	if errStart != nil || errEnd != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status":  "error",
+			"message": "start and end must be RFC3339 timestamps",
+			"data":    nil,
+		})
+	}
+
+	quote, err := h.bikeUsecase.QuoteBike(bikeId, startAt, endAt)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "bike not found",
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrInvalidRentWindow) {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success quote bike",
+		"data": map[string]*pricing.Quote{
+			"quote": quote,
+		},
+	})
+}
+
 func (h *BikeController) HandlerUpdateBike(c echo.Context) error {
 	bikeId := c.Param("id")
 	bikeDTO := dto.BikeDTO{}
@@ -190,6 +317,14 @@ func (h *BikeController) HandlerUpdateBike(c echo.Context) error {
 			})
 		}
 
+		if errors.Is(err, pkg.ErrInvalidPricing) {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
 		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
 			"status":  "error",
 			"message": err.Error(),
@@ -244,6 +379,15 @@ func (h *BikeController) HandlerCreateNewBikeReview(c echo.Context) error {
 		})
 	}
 
+	// customers only review bikes as themselves
+	if reviewDTO.UserId != helper.ExtractTokenClaims(c)["user_id"] {
+		return c.JSON(http.StatusForbidden, map[string]interface{}{
+			"status":  "error",
+			"message": "you can only review bikes as yourself",
+			"data":    nil,
+		})
+	}
+
 	err := h.bikeUsecase.CreateNewBikeReview(bikeId, reviewDTO)
 
 	if err != nil {
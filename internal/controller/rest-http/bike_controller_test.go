@@ -5,7 +5,10 @@ import (
 	"encoding/json"
 	"github.com/arvinpaundra/go-rent-bike/internal/dto"
 	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/pricing"
 	usecasemock "github.com/arvinpaundra/go-rent-bike/internal/usecase/mock"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/golang-jwt/jwt"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/suite"
 	"net/http"
@@ -40,7 +43,8 @@ func (s *suiteBikes) TestHandlerAddNewBike() {
 		IsAvailable:  "1",
 	}
 
-	s.mocking.Mock.On("CreateNewBike", bikeDTO).Return(nil)
+	s.mocking.Mock.On("CreateNewBike", "0f6d2c8e-3b1a-4e5f-9c7d-2a4b6c8d0e1f", bikeDTO).Return(nil)
+	s.mocking.Mock.On("CreateNewBike", "7c1e9a3b-5d2f-4a6e-8b0c-4d6e8f0a2b3c", bikeDTO).Return(pkg.ErrForbidden)
 
 	testCases := []struct {
 		Name               string
@@ -48,6 +52,7 @@ func (s *suiteBikes) TestHandlerAddNewBike() {
 		Method             string
 		Header             map[string]string
 		Body               map[string]interface{}
+		ActorId            string
 		HasReturnBody      bool
 		ExpectedResult     map[string]interface{}
 	}{
@@ -67,6 +72,7 @@ func (s *suiteBikes) TestHandlerAddNewBike() {
 				"description":    "Description section.",
 				"is_available":   "1",
 			},
+			ActorId:       "0f6d2c8e-3b1a-4e5f-9c7d-2a4b6c8d0e1f",
 			HasReturnBody: true,
 			ExpectedResult: map[string]interface{}{
 				"status":  "success",
@@ -74,6 +80,30 @@ func (s *suiteBikes) TestHandlerAddNewBike() {
 				"data":    nil,
 			},
 		},
+		{
+			Name:               "failed bike of another renter",
+			ExpectedStatusCode: http.StatusForbidden,
+			Method:             "POST",
+			Header: map[string]string{
+				"Content-Type": "application/json",
+			},
+			Body: map[string]interface{}{
+				"renter_id":      "478b3f5e-284e-440c-8c0f-af4f94c70d87",
+				"category_id":    "8cfb93e7-a1f4-47e2-bb5b-ffea24761322",
+				"name":           "Sample Mountain Bike",
+				"price_per_hour": float32(12000),
+				"condition":      "Good",
+				"description":    "Description section.",
+				"is_available":   "1",
+			},
+			ActorId:       "7c1e9a3b-5d2f-4a6e-8b0c-4d6e8f0a2b3c",
+			HasReturnBody: true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "you are not allowed to add bikes to this renter",
+				"data":    nil,
+			},
+		},
 		{
 			Name:               "failed wrong content-type",
 			ExpectedStatusCode: http.StatusBadRequest,
@@ -108,6 +138,7 @@ func (s *suiteBikes) TestHandlerAddNewBike() {
 			e := echo.New()
 			ctx := e.NewContext(r, w)
 			ctx.Request().Header.Set("Content-Type", v.Header["Content-Type"])
+			ctx.Set("user", &jwt.Token{Claims: jwt.MapClaims{"user_id": v.ActorId, "role": "renter"}})
 
 			err := s.handler.HandlerAddNewBike(ctx)
 			s.NoError(err)
@@ -165,6 +196,25 @@ func (s *suiteBikes) TestHandlerCreateNewBikeReview() {
 				"data":    nil,
 			},
 		},
+		{
+			Name:               "failed review as another customer",
+			ExpectedStatusCode: http.StatusForbidden,
+			Method:             "POST",
+			Header: map[string]string{
+				"Content-Type": "application/json",
+			},
+			Body: map[string]interface{}{
+				"user_id":     "3d5f7a9b-1c2e-4f6a-8b0d-5e7f9a1b3c4d",
+				"rating":      1,
+				"description": "What a bad bike.",
+			},
+			HasReturnBody: true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "you can only review bikes as yourself",
+				"data":    nil,
+			},
+		},
 		{
 			Name:               "failed wrong content-type",
 			ExpectedStatusCode: http.StatusBadRequest,
@@ -198,6 +248,7 @@ func (s *suiteBikes) TestHandlerCreateNewBikeReview() {
 			ctx.SetPath("/:id/reviews")
 			ctx.SetParamNames("id")
 			ctx.SetParamValues(bikeId)
+			ctx.Set("user", &jwt.Token{Claims: jwt.MapClaims{"user_id": reviewDTO.UserId, "role": "customer"}})
 
 			err := s.handler.HandlerCreateNewBikeReview(ctx)
 			s.NoError(err)
@@ -371,6 +422,164 @@ func (s *suiteBikes) TestHandlerFindByIdBike() {
 	}
 }
 
+func (s *suiteBikes) TestHandlerCheckBikeAvailability() {
+	bikeId := "6b1f7f4e-1c4e-4f4a-8d0e-7f0b9f3a9c21"
+
+	startAt := time.Date(2022, 11, 20, 8, 0, 0, 0, time.UTC)
+	endAt := time.Date(2022, 11, 20, 12, 0, 0, 0, time.UTC)
+
+	availability := &dto.BikeAvailabilityDTO{
+		BikeId:    bikeId,
+		Available: false,
+		Conflicts: []dto.BookedWindowDTO{
+			{
+				StartAt: time.Date(2022, 11, 20, 10, 0, 0, 0, time.UTC),
+				EndAt:   time.Date(2022, 11, 20, 14, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	s.mocking.Mock.On("CheckBikeAvailability", bikeId, startAt, endAt).Return(availability, nil)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Method             string
+		Query              string
+		HasReturnBody      bool
+		ExpectedResult     map[string]interface{}
+	}{
+		{
+			Name:               "success check bike availability",
+			ExpectedStatusCode: http.StatusOK,
+			Method:             "GET",
+			Query:              "?start_at=2022-11-20T08:00:00Z&end_at=2022-11-20T12:00:00Z",
+			HasReturnBody:      true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "success",
+				"message": "success check bike availability",
+			},
+		},
+		{
+			Name:               "failed invalid timestamp",
+			ExpectedStatusCode: http.StatusBadRequest,
+			Method:             "GET",
+			Query:              "?start_at=tomorrow&end_at=2022-11-20T12:00:00Z",
+			HasReturnBody:      true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "start_at and end_at must be RFC3339 timestamps",
+			},
+		},
+	}
+
+	for _, v := range testCases {
+		s.T().Run(v.Name, func(t *testing.T) {
+			r := httptest.NewRequest(v.Method, "/bikes"+v.Query, nil)
+			w := httptest.NewRecorder()
+
+			e := echo.New()
+			ctx := e.NewContext(r, w)
+			ctx.SetPath("/:id/availability")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(bikeId)
+
+			err := s.handler.HandlerCheckBikeAvailability(ctx)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatusCode, w.Result().StatusCode)
+
+			if v.HasReturnBody {
+				var resp map[string]interface{}
+				err := json.NewDecoder(w.Result().Body).Decode(&resp)
+				s.NoError(err)
+
+				s.Equal(v.ExpectedResult["status"], resp["status"])
+				s.Equal(v.ExpectedResult["message"], resp["message"])
+			}
+		})
+	}
+}
+
+func (s *suiteBikes) TestHandlerQuoteBike() {
+	bikeId := "7c2a8a5f-2d5f-4a5b-9e1f-8a1c0a4b0d32"
+
+	startAt := time.Date(2022, 11, 21, 8, 0, 0, 0, time.UTC)
+	endAt := time.Date(2022, 11, 22, 10, 0, 0, 0, time.UTC)
+
+	quote := &pricing.Quote{
+		StartAt:   startAt,
+		EndAt:     endAt,
+		TotalHour: 26,
+		Lines: []pricing.Line{
+			{Description: "1 day", Hours: 24, Unit: pricing.UnitDay, BasePrice: 100000, Multiplier: 1, Amount: 100000},
+			{Description: "2 hours", Hours: 2, Unit: pricing.UnitHour, BasePrice: 30000, Multiplier: 1, Amount: 30000},
+		},
+		Total: 130000,
+	}
+
+	s.mocking.Mock.On("QuoteBike", bikeId, startAt, endAt).Return(quote, nil)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Method             string
+		Query              string
+		HasReturnBody      bool
+		ExpectedResult     map[string]interface{}
+	}{
+		{
+			Name:               "success quote bike",
+			ExpectedStatusCode: http.StatusOK,
+			Method:             "GET",
+			Query:              "?start=2022-11-21T08:00:00Z&end=2022-11-22T10:00:00Z",
+			HasReturnBody:      true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "success",
+				"message": "success quote bike",
+			},
+		},
+		{
+			Name:               "failed invalid timestamp",
+			ExpectedStatusCode: http.StatusBadRequest,
+			Method:             "GET",
+			Query:              "?start=2022-11-21T08:00:00Z",
+			HasReturnBody:      true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "start and end must be RFC3339 timestamps",
+			},
+		},
+	}
+
+	for _, v := range testCases {
+		s.T().Run(v.Name, func(t *testing.T) {
+			r := httptest.NewRequest(v.Method, "/bikes"+v.Query, nil)
+			w := httptest.NewRecorder()
+
+			e := echo.New()
+			ctx := e.NewContext(r, w)
+			ctx.SetPath("/:id/quote")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(bikeId)
+
+			err := s.handler.HandlerQuoteBike(ctx)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatusCode, w.Result().StatusCode)
+
+			if v.HasReturnBody {
+				var resp map[string]interface{}
+				err := json.NewDecoder(w.Result().Body).Decode(&resp)
+				s.NoError(err)
+
+				s.Equal(v.ExpectedResult["status"], resp["status"])
+				s.Equal(v.ExpectedResult["message"], resp["message"])
+			}
+		})
+	}
+}
+
 func (s *suiteBikes) TestHandlerFindBikesByRenter() {
 	renterId := "8ad58074-228c-430d-918e-01105cc084fa"
 
@@ -33,6 +33,14 @@ func (h *CategoryController) HandlerCreateCategory(c echo.Context) error {
 	err := h.categoryUsecase.CreateCategory(categoryDTO)
 
 	if err != nil {
+		if errors.Is(err, pkg.ErrInvalidPricing) {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
 		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
 			"status":  "error",
 			"message": err.Error(),
@@ -120,6 +128,14 @@ func (h *CategoryController) HandlerUpdateCategory(c echo.Context) error {
 			})
 		}
 
+		if errors.Is(err, pkg.ErrInvalidPricing) {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
 		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
 			"status":  "error",
 			"message": err.Error(),
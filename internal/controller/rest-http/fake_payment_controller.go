@@ -0,0 +1,89 @@
+package rest_http
+
+import (
+	"errors"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"net/http"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/payment"
+	"github.com/arvinpaundra/go-rent-bike/internal/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+// FakePaymentController plays the customer and midtrans for the fake payment provider,
+// it is only routed when PAYMENT_PROVIDER is fake.
+type FakePaymentController struct {
+	fakeProvider          *payment.FakeProvider
+	paymentGatewayUsecase usecase.PaymentGatewayUsecase
+}
+
+func (h *FakePaymentController) HandlerFindFakePayment(c echo.Context) error {
+	orderId := c.Param("id")
+
+	transaction, err := h.fakeProvider.CheckTransaction(orderId)
+
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"status":  "error",
+			"message": "transaction not found",
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success get transaction",
+		"data": map[string]*payment.TransactionStatus{
+			"transaction": transaction,
+		},
+	})
+}
+
+func (h *FakePaymentController) HandlerSimulateFakePayment(c echo.Context) error {
+	orderId := c.Param("id")
+	transactionStatus := c.Param("status")
+
+	paymentType := c.QueryParam("payment_type")
+	if paymentType == "" {
+		paymentType = "bank_transfer"
+	}
+
+	transaction, err := h.fakeProvider.Simulate(orderId, transactionStatus, paymentType)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "transaction not found",
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusConflict, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	// notify the app the same way midtrans does once a transaction changes
+	if err := h.paymentGatewayUsecase.SyncTransaction(orderId); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success simulate payment",
+		"data": map[string]*payment.TransactionStatus{
+			"transaction": transaction,
+		},
+	})
+}
+
+func NewFakePaymentController(fakeProvider *payment.FakeProvider, paymentGatewayUsecase usecase.PaymentGatewayUsecase) *FakePaymentController {
+	return &FakePaymentController{fakeProvider, paymentGatewayUsecase}
+}
@@ -0,0 +1,135 @@
+package rest_http
+
+import (
+	"encoding/json"
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/payment"
+	usecasemock "github.com/arvinpaundra/go-rent-bike/internal/usecase/mock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type suiteFakePayments struct {
+	suite.Suite
+	handler  *FakePaymentController
+	provider *payment.FakeProvider
+	mocking  *usecasemock.PaymentGatewayMock
+}
+
+func (s *suiteFakePayments) SetupSuite() {
+	mock := &usecasemock.PaymentGatewayMock{}
+	s.mocking = mock
+	s.provider = payment.NewFakeProvider("http://localhost:8080")
+
+	s.handler = &FakePaymentController{
+		fakeProvider:          s.provider,
+		paymentGatewayUsecase: s.mocking,
+	}
+}
+
+func (s *suiteFakePayments) TestHandlerSimulateFakePayment() {
+	s.provider.CreateUrlTransactionWithGateway(dto.PaymentGateway{OrderId: "8b9c0d1e-2f3a-4b4c-8d5e-6f7a8b9c0d1f", GrossAmt: 75000})
+	s.provider.CreateUrlTransactionWithGateway(dto.PaymentGateway{OrderId: "9c0d1e2f-3a4b-4c5d-9e6f-7a8b9c0d1e20", GrossAmt: 75000})
+
+	s.mocking.Mock.On("SyncTransaction", "8b9c0d1e-2f3a-4b4c-8d5e-6f7a8b9c0d1f").Return(nil)
+	s.mocking.Mock.On("SyncTransaction", "9c0d1e2f-3a4b-4c5d-9e6f-7a8b9c0d1e20").Return(nil)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		OrderId            string
+		TransactionStatus  string
+		ExpectedMessage    string
+	}{
+		{
+			Name:               "success settle payment",
+			ExpectedStatusCode: http.StatusOK,
+			OrderId:            "8b9c0d1e-2f3a-4b4c-8d5e-6f7a8b9c0d1f",
+			TransactionStatus:  "settlement",
+			ExpectedMessage:    "success simulate payment",
+		},
+		{
+			Name:               "failed payment already settled",
+			ExpectedStatusCode: http.StatusConflict,
+			OrderId:            "8b9c0d1e-2f3a-4b4c-8d5e-6f7a8b9c0d1f",
+			TransactionStatus:  "expire",
+			ExpectedMessage:    "payment gateway error: transaction 8b9c0d1e-2f3a-4b4c-8d5e-6f7a8b9c0d1f is settlement",
+		},
+		{
+			Name:               "success expire payment",
+			ExpectedStatusCode: http.StatusOK,
+			OrderId:            "9c0d1e2f-3a4b-4c5d-9e6f-7a8b9c0d1e20",
+			TransactionStatus:  "expire",
+			ExpectedMessage:    "success simulate payment",
+		},
+		{
+			Name:               "failed transaction not found",
+			ExpectedStatusCode: http.StatusNotFound,
+			OrderId:            "0d1e2f3a-4b5c-4d6e-8f7a-8b9c0d1e2f31",
+			TransactionStatus:  "settlement",
+			ExpectedMessage:    "transaction not found",
+		},
+	}
+
+	for _, v := range testCases {
+		s.T().Run(v.Name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/fake-payments", nil)
+			w := httptest.NewRecorder()
+
+			e := echo.New()
+			ctx := e.NewContext(r, w)
+			ctx.SetPath("/:id/:status")
+			ctx.SetParamNames("id", "status")
+			ctx.SetParamValues(v.OrderId, v.TransactionStatus)
+
+			err := s.handler.HandlerSimulateFakePayment(ctx)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatusCode, w.Result().StatusCode)
+
+			var resp map[string]interface{}
+			err = json.NewDecoder(w.Result().Body).Decode(&resp)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedMessage, resp["message"])
+		})
+	}
+}
+
+func (s *suiteFakePayments) TestHandlerFindFakePayment() {
+	s.provider.CreateUrlTransactionWithGateway(dto.PaymentGateway{OrderId: "1e2f3a4b-5c6d-4e7f-9a8b-9c0d1e2f3a42", GrossAmt: 50000})
+
+	r := httptest.NewRequest("GET", "/fake-payments", nil)
+	w := httptest.NewRecorder()
+
+	e := echo.New()
+	ctx := e.NewContext(r, w)
+	ctx.SetPath("/:id")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("1e2f3a4b-5c6d-4e7f-9a8b-9c0d1e2f3a42")
+
+	err := s.handler.HandlerFindFakePayment(ctx)
+	s.NoError(err)
+
+	s.Equal(http.StatusOK, w.Result().StatusCode)
+
+	var resp map[string]interface{}
+	err = json.NewDecoder(w.Result().Body).Decode(&resp)
+	s.NoError(err)
+
+	transaction := resp["data"].(map[string]interface{})["transaction"].(map[string]interface{})
+
+	s.Equal("pending", transaction["transaction_status"])
+	s.Equal("50000.00", transaction["gross_amount"])
+}
+
+func (s *suiteFakePayments) TearDownSuite() {
+	s.mocking = nil
+}
+
+func TestSuiteFakePayments(t *testing.T) {
+	suite.Run(t, new(suiteFakePayments))
+}
@@ -0,0 +1,98 @@
+package rest_http
+
+import (
+	"errors"
+	"fmt"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"net/http"
+
+	"github.com/arvinpaundra/go-rent-bike/helper"
+	"github.com/arvinpaundra/go-rent-bike/internal/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+type InvoiceController struct {
+	invoiceUsecase usecase.InvoiceUsecase
+}
+
+func NewInvoiceController(invoiceUsecase usecase.InvoiceUsecase) *InvoiceController {
+	return &InvoiceController{invoiceUsecase}
+}
+
+func (h *InvoiceController) HandlerDownloadInvoice(c echo.Context) error {
+	orderId := c.Param("id")
+	actorId := helper.ExtractTokenClaims(c)["user_id"]
+
+	file, err := h.invoiceUsecase.GenerateInvoice(orderId, actorId)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "order not found",
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrForbidden) {
+			return c.JSON(http.StatusForbidden, map[string]interface{}{
+				"status":  "error",
+				"message": "you are not allowed to see the invoices of this order",
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=invoice-%s.pdf", orderId))
+
+	return c.Blob(http.StatusOK, "application/pdf", file)
+}
+
+func (h *InvoiceController) HandlerDownloadReceipt(c echo.Context) error {
+	orderId := c.Param("id")
+	actorId := helper.ExtractTokenClaims(c)["user_id"]
+
+	file, err := h.invoiceUsecase.GenerateReceipt(orderId, actorId)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "order not found",
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrForbidden) {
+			return c.JSON(http.StatusForbidden, map[string]interface{}{
+				"status":  "error",
+				"message": "you are not allowed to see the invoices of this order",
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrPaymentNotSettled) {
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=receipt-%s.pdf", orderId))
+
+	return c.Blob(http.StatusOK, "application/pdf", file)
+}
@@ -0,0 +1,166 @@
+package rest_http
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/arvinpaundra/go-rent-bike/internal/invoice"
+	usecasemock "github.com/arvinpaundra/go-rent-bike/internal/usecase/mock"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type suiteInvoices struct {
+	suite.Suite
+	handler *InvoiceController
+	mocking *usecasemock.InvoiceUsecaseMock
+}
+
+func (s *suiteInvoices) SetupSuite() {
+	mock := &usecasemock.InvoiceUsecaseMock{}
+	s.mocking = mock
+
+	s.handler = &InvoiceController{
+		invoiceUsecase: s.mocking,
+	}
+}
+
+func (s *suiteInvoices) TestHandlerDownloadInvoice() {
+	userId := "3c4d5e6f-7a8b-4c9d-8e0f-1a2b3c4d5e6f"
+	file, _ := invoice.Render([]invoice.Document{{Title: invoice.TitleInvoice, Number: "INV-5E6F7A8B-00042"}})
+
+	s.mocking.Mock.On("GenerateInvoice", "4d5e6f7a-8b9c-4d0e-9f1a-2b3c4d5e6f7a", userId).Return(file, nil)
+	s.mocking.Mock.On("GenerateInvoice", "5e6f7a8b-9c0d-4e1f-8a2b-3c4d5e6f7a8b", userId).Return([]byte(nil), pkg.ErrRecordNotFound)
+	s.mocking.Mock.On("GenerateInvoice", "6f7a8b9c-0d1e-4f2a-9b3c-4d5e6f7a8b9c", userId).Return([]byte(nil), pkg.ErrForbidden)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		OrderId            string
+		ExpectedMessage    string
+	}{
+		{
+			Name:               "success download invoice",
+			ExpectedStatusCode: http.StatusOK,
+			OrderId:            "4d5e6f7a-8b9c-4d0e-9f1a-2b3c4d5e6f7a",
+		},
+		{
+			Name:               "failed order not found",
+			ExpectedStatusCode: http.StatusNotFound,
+			OrderId:            "5e6f7a8b-9c0d-4e1f-8a2b-3c4d5e6f7a8b",
+			ExpectedMessage:    "order not found",
+		},
+		{
+			Name:               "failed not part of the order",
+			ExpectedStatusCode: http.StatusForbidden,
+			OrderId:            "6f7a8b9c-0d1e-4f2a-9b3c-4d5e6f7a8b9c",
+			ExpectedMessage:    "you are not allowed to see the invoices of this order",
+		},
+	}
+
+	for _, v := range testCases {
+		s.T().Run(v.Name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			w := httptest.NewRecorder()
+
+			e := echo.New()
+			ctx := e.NewContext(r, w)
+			ctx.SetPath("/orders/:id/invoice.pdf")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(v.OrderId)
+			ctx.Set("user", &jwt.Token{Claims: jwt.MapClaims{"user_id": userId, "role": "customer"}})
+
+			err := s.handler.HandlerDownloadInvoice(ctx)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatusCode, w.Result().StatusCode)
+
+			if v.ExpectedStatusCode == http.StatusOK {
+				s.Equal("application/pdf", w.Result().Header.Get(echo.HeaderContentType))
+				s.Equal(fmt.Sprintf("attachment; filename=invoice-%s.pdf", v.OrderId), w.Result().Header.Get(echo.HeaderContentDisposition))
+				s.Equal(file, w.Body.Bytes())
+				return
+			}
+
+			var resp map[string]interface{}
+			err = json.NewDecoder(w.Result().Body).Decode(&resp)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedMessage, resp["message"])
+		})
+	}
+}
+
+func (s *suiteInvoices) TestHandlerDownloadReceipt() {
+	userId := "7a8b9c0d-1e2f-4a3b-8c4d-5e6f7a8b9c0d"
+	file, _ := invoice.Render([]invoice.Document{{Title: invoice.TitleReceipt, Number: "INV-5E6F7A8B-00042"}})
+
+	s.mocking.Mock.On("GenerateReceipt", "8b9c0d1e-2f3a-4b4c-9d5e-6f7a8b9c0d1e", userId).Return(file, nil)
+	s.mocking.Mock.On("GenerateReceipt", "9c0d1e2f-3a4b-4c5d-8e6f-7a8b9c0d1e2f", userId).Return([]byte(nil), pkg.ErrPaymentNotSettled)
+	s.mocking.Mock.On("GenerateReceipt", "0d1e2f3a-4b5c-4d6e-9f7a-8b9c0d1e2f3a", userId).Return([]byte(nil), pkg.ErrForbidden)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		OrderId            string
+		ExpectedMessage    string
+	}{
+		{
+			Name:               "success download receipt",
+			ExpectedStatusCode: http.StatusOK,
+			OrderId:            "8b9c0d1e-2f3a-4b4c-9d5e-6f7a8b9c0d1e",
+		},
+		{
+			Name:               "failed payment not settled",
+			ExpectedStatusCode: http.StatusConflict,
+			OrderId:            "9c0d1e2f-3a4b-4c5d-8e6f-7a8b9c0d1e2f",
+			ExpectedMessage:    "payment is not settled yet",
+		},
+		{
+			Name:               "failed not part of the order",
+			ExpectedStatusCode: http.StatusForbidden,
+			OrderId:            "0d1e2f3a-4b5c-4d6e-9f7a-8b9c0d1e2f3a",
+			ExpectedMessage:    "you are not allowed to see the invoices of this order",
+		},
+	}
+
+	for _, v := range testCases {
+		s.T().Run(v.Name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			w := httptest.NewRecorder()
+
+			e := echo.New()
+			ctx := e.NewContext(r, w)
+			ctx.SetPath("/orders/:id/receipt.pdf")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(v.OrderId)
+			ctx.Set("user", &jwt.Token{Claims: jwt.MapClaims{"user_id": userId, "role": "customer"}})
+
+			err := s.handler.HandlerDownloadReceipt(ctx)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatusCode, w.Result().StatusCode)
+
+			if v.ExpectedStatusCode == http.StatusOK {
+				s.Equal("application/pdf", w.Result().Header.Get(echo.HeaderContentType))
+				s.Equal(fmt.Sprintf("attachment; filename=receipt-%s.pdf", v.OrderId), w.Result().Header.Get(echo.HeaderContentDisposition))
+				s.Equal(file, w.Body.Bytes())
+				return
+			}
+
+			var resp map[string]interface{}
+			err = json.NewDecoder(w.Result().Body).Decode(&resp)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedMessage, resp["message"])
+		})
+	}
+}
+
+func TestSuiteInvoices(t *testing.T) {
+	suite.Run(t, new(suiteInvoices))
+}
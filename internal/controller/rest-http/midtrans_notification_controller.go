@@ -5,6 +5,7 @@ import (
 	"github.com/arvinpaundra/go-rent-bike/pkg"
 	"net/http"
 
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
 	"github.com/arvinpaundra/go-rent-bike/internal/usecase"
 	"github.com/labstack/echo/v4"
 )
@@ -14,9 +15,9 @@ type MidtransNotificationController struct {
 }
 
 func (h *MidtransNotificationController) HandlerNotification(c echo.Context) error {
-	var notificationPayloads map[string]interface{}
+	notificationDTO := dto.MidtransNotificationDTO{}
 
-	if err := c.Bind(&notificationPayloads); err != nil {
+	if err := c.Bind(&notificationDTO); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]interface{}{
 			"status":  "error",
 			"message": "fill all required fields",
@@ -24,11 +25,25 @@ func (h *MidtransNotificationController) HandlerNotification(c echo.Context) err
 		})
 	}
 
-	orderId := notificationPayloads["order_id"].(string)
-
-	err := h.paymentGatewayUsecase.MidtransNotification(orderId)
+	err := h.paymentGatewayUsecase.MidtransNotification(notificationDTO)
 
 	if err != nil {
+		if errors.Is(err, pkg.ErrInvalidNotification) {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrInvalidSignature) {
+			return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
 		if errors.Is(err, pkg.ErrRecordNotFound) {
 			return c.JSON(http.StatusNotFound, map[string]interface{}{
 				"status":  "error",
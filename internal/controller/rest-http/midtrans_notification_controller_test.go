@@ -3,7 +3,9 @@ package rest_http
 import (
 	"bytes"
 	"encoding/json"
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
 	usecasemock "github.com/arvinpaundra/go-rent-bike/internal/usecase/mock"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/suite"
 	"net/http"
@@ -27,9 +29,23 @@ func (s *suiteMidtrans) SetupSuite() {
 }
 
 func (s *suiteMidtrans) TestHandlerNotification() {
-	orderId := "478b3f5e-284e-440c-8c0f-af4f94c70d87"
+	notificationDTO := dto.MidtransNotificationDTO{
+		OrderId:           "478b3f5e-284e-440c-8c0f-af4f94c70d87",
+		TransactionStatus: "settlement",
+		FraudStatus:       "accept",
+		StatusCode:        "200",
+		GrossAmount:       "75000.00",
+		SignatureKey:      "valid-signature",
+	}
+
+	forgedDTO := notificationDTO
+	forgedDTO.SignatureKey = "forged-signature"
+
+	malformedDTO := dto.MidtransNotificationDTO{TransactionStatus: "settlement"}
 
-	s.mocking.Mock.On("MidtransNotification", orderId).Return(nil)
+	s.mocking.Mock.On("MidtransNotification", notificationDTO).Return(nil)
+	s.mocking.Mock.On("MidtransNotification", forgedDTO).Return(pkg.ErrInvalidSignature)
+	s.mocking.Mock.On("MidtransNotification", malformedDTO).Return(pkg.ErrInvalidNotification)
 
 	testCases := []struct {
 		Name               string
@@ -41,7 +57,7 @@ func (s *suiteMidtrans) TestHandlerNotification() {
 		ExpectedResult     map[string]interface{}
 	}{
 		{
-			Name:               "success create category",
+			Name:               "success update transaction status",
 			ExpectedStatusCode: http.StatusOK,
 			Method:             "POST",
 			Header: map[string]string{
@@ -49,8 +65,11 @@ func (s *suiteMidtrans) TestHandlerNotification() {
 			},
 			Body: map[string]interface{}{
 				"order_id":           "478b3f5e-284e-440c-8c0f-af4f94c70d87",
-				"transaction_status": "success",
+				"transaction_status": "settlement",
 				"fraud_status":       "accept",
+				"status_code":        "200",
+				"gross_amount":       "75000.00",
+				"signature_key":      "valid-signature",
 			},
 			HasReturnBody: true,
 			ExpectedResult: map[string]interface{}{
@@ -59,6 +78,45 @@ func (s *suiteMidtrans) TestHandlerNotification() {
 				"data":    nil,
 			},
 		},
+		{
+			Name:               "failed forged signature",
+			ExpectedStatusCode: http.StatusUnauthorized,
+			Method:             "POST",
+			Header: map[string]string{
+				"Content-Type": "application/json",
+			},
+			Body: map[string]interface{}{
+				"order_id":           "478b3f5e-284e-440c-8c0f-af4f94c70d87",
+				"transaction_status": "settlement",
+				"fraud_status":       "accept",
+				"status_code":        "200",
+				"gross_amount":       "75000.00",
+				"signature_key":      "forged-signature",
+			},
+			HasReturnBody: true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "invalid signature key",
+				"data":    nil,
+			},
+		},
+		{
+			Name:               "failed malformed payload",
+			ExpectedStatusCode: http.StatusBadRequest,
+			Method:             "POST",
+			Header: map[string]string{
+				"Content-Type": "application/json",
+			},
+			Body: map[string]interface{}{
+				"transaction_status": "settlement",
+			},
+			HasReturnBody: true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "invalid notification payload",
+				"data":    nil,
+			},
+		},
 		{
 			Name:               "failed wrong content-type",
 			ExpectedStatusCode: http.StatusBadRequest,
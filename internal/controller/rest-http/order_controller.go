@@ -5,7 +5,9 @@ import (
 	"github.com/arvinpaundra/go-rent-bike/pkg"
 	"net/http"
 
+	"github.com/arvinpaundra/go-rent-bike/helper"
 	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
 	"github.com/arvinpaundra/go-rent-bike/internal/usecase"
 	"github.com/labstack/echo/v4"
 )
@@ -29,7 +31,8 @@ func (h *OrderController) HandlerCreateNewOrder(c echo.Context) error {
 		})
 	}
 
-	data, err := h.orderUsecase.CreateOrder(orderDTO)
+	// the order is placed for the user of the token, so a wallet only ever pays for its own user
+	data, err := h.orderUsecase.CreateOrder(helper.ExtractTokenClaims(c)["user_id"], orderDTO)
 
 	if err != nil {
 		if errors.Is(err, pkg.ErrRecordNotFound) {
@@ -40,6 +43,46 @@ func (h *OrderController) HandlerCreateNewOrder(c echo.Context) error {
 			})
 		}
 
+		if errors.Is(err, pkg.ErrEmailNotVerified) {
+			return c.JSON(http.StatusForbidden, map[string]interface{}{
+				"status":  "error",
+				"message": "verify your email before ordering",
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrBookingConflict) || errors.Is(err, pkg.ErrBikeNotAvailable) || errors.Is(err, pkg.ErrRenterSuspended) {
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrInvalidRentWindow) || errors.Is(err, pkg.ErrNoBikeChosen) || errors.Is(err, pkg.ErrVoucherNotApplicable) {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrInsufficientBalance) {
+			return c.JSON(http.StatusUnprocessableEntity, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		if statusCode := paymentGatewayStatusCode(err); statusCode != 0 {
+			return c.JSON(statusCode, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
 		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
 			"status":  "error",
 			"message": err.Error(),
@@ -54,10 +97,48 @@ func (h *OrderController) HandlerCreateNewOrder(c echo.Context) error {
 	})
 }
 
+func (h *OrderController) HandlerPickupBike(c echo.Context) error {
+	orderId := c.Param("id")
+	actorId := helper.ExtractTokenClaims(c)["user_id"]
+
+	err := h.orderUsecase.PickupBike(orderId, actorId)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "order not found",
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrInvalidStatusTransition) {
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success pick up bike",
+		"data":    nil,
+	})
+}
+
 func (h *OrderController) HandlerReturnBike(c echo.Context) error {
 	orderId := c.Param("id")
+	actorId := helper.ExtractTokenClaims(c)["user_id"]
 
-	err := h.orderUsecase.UpdateRentStatus(orderId)
+	data, err := h.orderUsecase.UpdateRentStatus(orderId, actorId)
 
 	if err != nil {
 		if errors.Is(err, pkg.ErrRecordNotFound) {
@@ -68,6 +149,22 @@ func (h *OrderController) HandlerReturnBike(c echo.Context) error {
 			})
 		}
 
+		if errors.Is(err, pkg.ErrInvalidStatusTransition) {
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		if statusCode := paymentGatewayStatusCode(err); statusCode != 0 {
+			return c.JSON(statusCode, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
 		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
 			"status":  "error",
 			"message": err.Error(),
@@ -78,6 +175,258 @@ func (h *OrderController) HandlerReturnBike(c echo.Context) error {
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"status":  "success",
 		"message": "success return bike",
+		"data":    data,
+	})
+}
+
+func (h *OrderController) HandlerCancelOrder(c echo.Context) error {
+	orderId := c.Param("id")
+	actorId := helper.ExtractTokenClaims(c)["user_id"]
+
+	data, err := h.orderUsecase.CancelOrder(orderId, actorId)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "order not found",
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrInvalidStatusTransition) {
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		if statusCode := paymentGatewayStatusCode(err); statusCode != 0 {
+			return c.JSON(statusCode, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success cancel order",
+		"data":    data,
+	})
+}
+
+func (h *OrderController) HandlerSettleDeposit(c echo.Context) error {
+	orderId := c.Param("id")
+	actorId := helper.ExtractTokenClaims(c)["user_id"]
+	depositDTO := dto.OrderDepositDTO{}
+
+	if err := c.Bind(&depositDTO); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status":  "error",
+			"message": "fill all required fields",
+			"data":    nil,
+		})
+	}
+
+	data, err := h.orderUsecase.SettleDeposit(orderId, actorId, depositDTO)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "order not found",
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrForbidden) {
+			return c.JSON(http.StatusForbidden, map[string]interface{}{
+				"status":  "error",
+				"message": "you are not allowed to settle the deposit of this order",
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrInvalidDeposit) {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrDepositNotSettleable) {
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		if statusCode := paymentGatewayStatusCode(err); statusCode != 0 {
+			return c.JSON(statusCode, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success settle deposit",
+		"data":    data,
+	})
+}
+
+func (h *OrderController) HandlerExtendOrder(c echo.Context) error {
+	orderId := c.Param("id")
+	extensionDTO := dto.OrderExtensionDTO{}
+
+	if err := c.Bind(&extensionDTO); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status":  "error",
+			"message": "fill all required fields",
+			"data":    nil,
+		})
+	}
+
+	data, err := h.orderUsecase.ExtendOrder(orderId, extensionDTO)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "order not found",
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrInvalidRentWindow) {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrOrderNotExtendable) || errors.Is(err, pkg.ErrExtensionPending) || errors.Is(err, pkg.ErrBookingConflict) {
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		if statusCode := paymentGatewayStatusCode(err); statusCode != 0 {
+			return c.JSON(statusCode, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success extend order",
+		"data":    data,
+	})
+}
+
+func (h *OrderController) HandlerFindOrderStatusHistories(c echo.Context) error {
+	orderId := c.Param("id")
+
+	statusHistories, err := h.orderUsecase.FindOrderStatusHistories(orderId)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "order not found",
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success get order status histories",
+		"data": map[string]*[]model.OrderStatusHistory{
+			"status_histories": statusHistories,
+		},
+	})
+}
+
+func (h *OrderController) HandlerOverrideOrderStatus(c echo.Context) error {
+	orderId := c.Param("id")
+	actorId := helper.ExtractTokenClaims(c)["user_id"]
+	overrideDTO := dto.OrderOverrideDTO{}
+
+	if err := c.Bind(&overrideDTO); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status":  "error",
+			"message": "fill all required fields",
+			"data":    nil,
+		})
+	}
+
+	err := h.orderUsecase.OverrideOrderStatus(orderId, actorId, overrideDTO)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "order not found",
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrInvalidOrderOverride) {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"status":  "error",
+				"message": "a reason and a status other than the current one are required",
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success override order status",
 		"data":    nil,
 	})
 }
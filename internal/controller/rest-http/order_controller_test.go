@@ -3,10 +3,14 @@ package rest_http
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"github.com/arvinpaundra/go-rent-bike/internal/dto"
 	"github.com/arvinpaundra/go-rent-bike/internal/model"
 	usecasemock "github.com/arvinpaundra/go-rent-bike/internal/usecase/mock"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/golang-jwt/jwt"
 	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"net/http"
 	"net/http/httptest"
@@ -30,10 +34,15 @@ func (s *suiteOrders) SetupSuite() {
 }
 
 func (s *suiteOrders) TestHandlerCreateNewOrder() {
+	startAt := time.Date(2022, 11, 20, 8, 0, 0, 0, time.UTC)
+	endAt := time.Date(2022, 11, 20, 12, 0, 0, 0, time.UTC)
+
+	customerId := "81cef832-e4de-4588-a026-6a106cf10a19"
+
 	orderDTO := dto.OrderDTO{
-		CustomerId:  "81cef832-e4de-4588-a026-6a106cf10a19",
 		BikeIds:     []string{"92d88bd9-d3d2-4bd5-adba-a8161cc26cc1"},
-		TotalHour:   4,
+		StartAt:     startAt,
+		EndAt:       endAt,
 		PaymentType: "bank_transfer",
 	}
 
@@ -47,6 +56,8 @@ func (s *suiteOrders) TestHandlerCreateNewOrder() {
 
 	order := map[string]interface{}{
 		"order_id":       "53d60e0e-8b92-416b-ab2d-0b645f54483e",
+		"start_at":       startAt,
+		"end_at":         endAt,
 		"total_payments": float32(200000),
 		"payments": map[string]interface{}{
 			"id":             payment.ID,
@@ -58,7 +69,37 @@ func (s *suiteOrders) TestHandlerCreateNewOrder() {
 		"payment_link": "https://app.sandbox.midtrans.com/v3/redirect/....",
 	}
 
-	s.mocking.Mock.On("CreateOrder", orderDTO).Return(order, nil)
+	s.mocking.Mock.On("CreateOrder", customerId, orderDTO).Return(order, nil)
+
+	conflictDTO := orderDTO
+	conflictDTO.BikeIds = []string{"0f6d5f4e-6d0a-4b55-9a2f-1a7c2c4b9e55"}
+
+	s.mocking.Mock.On("CreateOrder", customerId, conflictDTO).Return(map[string]interface{}{}, fmt.Errorf("%w: Sample BMX Bike is booked from 2022-11-20T10:00:00Z to 2022-11-20T14:00:00Z", pkg.ErrBookingConflict))
+
+	voucherDTO := orderDTO
+	voucherDTO.VoucherCode = "OLDPROMO"
+
+	s.mocking.Mock.On("CreateOrder", customerId, voucherDTO).Return(map[string]interface{}(nil), fmt.Errorf("%w: voucher OLDPROMO has expired", pkg.ErrVoucherNotApplicable))
+
+	timeoutDTO := orderDTO
+	timeoutDTO.PaymentType = "gopay"
+
+	s.mocking.Mock.On("CreateOrder", customerId, timeoutDTO).Return(map[string]interface{}(nil), fmt.Errorf("%w: no response in 30s", pkg.ErrGatewayTimeout))
+
+	duplicateDTO := orderDTO
+	duplicateDTO.PaymentType = "qris"
+
+	s.mocking.Mock.On("CreateOrder", customerId, duplicateDTO).Return(map[string]interface{}(nil), fmt.Errorf("%w: transaction_details.order_id has already been taken", pkg.ErrGatewayDuplicateOrder))
+
+	walletDTO := orderDTO
+	walletDTO.PaymentType = model.PaymentTypeWallet
+
+	s.mocking.Mock.On("CreateOrder", customerId, walletDTO).Return(map[string]interface{}(nil), fmt.Errorf("%w: 50000 available", pkg.ErrInsufficientBalance))
+
+	unverifiedDTO := orderDTO
+	unverifiedDTO.PaymentType = "credit_card"
+
+	s.mocking.Mock.On("CreateOrder", customerId, unverifiedDTO).Return(map[string]interface{}(nil), pkg.ErrEmailNotVerified)
 
 	testCases := []struct {
 		Name               string
@@ -77,9 +118,151 @@ func (s *suiteOrders) TestHandlerCreateNewOrder() {
 				"Content-Type": "application/json",
 			},
 			Body: map[string]interface{}{
-				"customer_id":  "81cef832-e4de-4588-a026-6a106cf10a19",
 				"bike_ids":     []string{"92d88bd9-d3d2-4bd5-adba-a8161cc26cc1"},
-				"total_hour":   int(4),
+				"start_at":     "2022-11-20T08:00:00Z",
+				"end_at":       "2022-11-20T12:00:00Z",
+				"payment_type": "bank_transfer",
+			},
+			HasReturnBody: true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "success",
+				"message": "success create an order",
+				"data":    order,
+			},
+		},
+		{
+			Name:               "failed bike already booked",
+			ExpectedStatusCode: http.StatusConflict,
+			Method:             "POST",
+			Header: map[string]string{
+				"Content-Type": "application/json",
+			},
+			Body: map[string]interface{}{
+				"bike_ids":     []string{"0f6d5f4e-6d0a-4b55-9a2f-1a7c2c4b9e55"},
+				"start_at":     "2022-11-20T08:00:00Z",
+				"end_at":       "2022-11-20T12:00:00Z",
+				"payment_type": "bank_transfer",
+			},
+			HasReturnBody: true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "bike already booked: Sample BMX Bike is booked from 2022-11-20T10:00:00Z to 2022-11-20T14:00:00Z",
+				"data":    nil,
+			},
+		},
+		{
+			Name:               "failed voucher not applicable",
+			ExpectedStatusCode: http.StatusBadRequest,
+			Method:             "POST",
+			Header: map[string]string{
+				"Content-Type": "application/json",
+			},
+			Body: map[string]interface{}{
+				"bike_ids":     []string{"92d88bd9-d3d2-4bd5-adba-a8161cc26cc1"},
+				"start_at":     "2022-11-20T08:00:00Z",
+				"end_at":       "2022-11-20T12:00:00Z",
+				"payment_type": "bank_transfer",
+				"voucher_code": "OLDPROMO",
+			},
+			HasReturnBody: true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "voucher cannot be applied: voucher OLDPROMO has expired",
+				"data":    nil,
+			},
+		},
+		{
+			Name:               "failed payment gateway timed out",
+			ExpectedStatusCode: http.StatusGatewayTimeout,
+			Method:             "POST",
+			Header: map[string]string{
+				"Content-Type": "application/json",
+			},
+			Body: map[string]interface{}{
+				"bike_ids":     []string{"92d88bd9-d3d2-4bd5-adba-a8161cc26cc1"},
+				"start_at":     "2022-11-20T08:00:00Z",
+				"end_at":       "2022-11-20T12:00:00Z",
+				"payment_type": "gopay",
+			},
+			HasReturnBody: true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "payment gateway timed out: no response in 30s",
+				"data":    nil,
+			},
+		},
+		{
+			Name:               "failed order id already used in payment gateway",
+			ExpectedStatusCode: http.StatusConflict,
+			Method:             "POST",
+			Header: map[string]string{
+				"Content-Type": "application/json",
+			},
+			Body: map[string]interface{}{
+				"bike_ids":     []string{"92d88bd9-d3d2-4bd5-adba-a8161cc26cc1"},
+				"start_at":     "2022-11-20T08:00:00Z",
+				"end_at":       "2022-11-20T12:00:00Z",
+				"payment_type": "qris",
+			},
+			HasReturnBody: true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "order id already used in the payment gateway: transaction_details.order_id has already been taken",
+				"data":    nil,
+			},
+		},
+		{
+			Name:               "failed insufficient wallet balance",
+			ExpectedStatusCode: http.StatusUnprocessableEntity,
+			Method:             "POST",
+			Header: map[string]string{
+				"Content-Type": "application/json",
+			},
+			Body: map[string]interface{}{
+				"bike_ids":     []string{"92d88bd9-d3d2-4bd5-adba-a8161cc26cc1"},
+				"start_at":     "2022-11-20T08:00:00Z",
+				"end_at":       "2022-11-20T12:00:00Z",
+				"payment_type": "wallet",
+			},
+			HasReturnBody: true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "insufficient wallet balance: 50000 available",
+				"data":    nil,
+			},
+		},
+		{
+			Name:               "failed email not verified",
+			ExpectedStatusCode: http.StatusForbidden,
+			Method:             "POST",
+			Header: map[string]string{
+				"Content-Type": "application/json",
+			},
+			Body: map[string]interface{}{
+				"bike_ids":     []string{"92d88bd9-d3d2-4bd5-adba-a8161cc26cc1"},
+				"start_at":     "2022-11-20T08:00:00Z",
+				"end_at":       "2022-11-20T12:00:00Z",
+				"payment_type": "credit_card",
+			},
+			HasReturnBody: true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "verify your email before ordering",
+				"data":    nil,
+			},
+		},
+		{
+			Name:               "customer of the body is ignored",
+			ExpectedStatusCode: http.StatusCreated,
+			Method:             "POST",
+			Header: map[string]string{
+				"Content-Type": "application/json",
+			},
+			Body: map[string]interface{}{
+				"customer_id":  "c2d3e4f5-a6b7-4c8d-9e0f-1a2b3c4d5e6f",
+				"bike_ids":     []string{"92d88bd9-d3d2-4bd5-adba-a8161cc26cc1"},
+				"start_at":     "2022-11-20T08:00:00Z",
+				"end_at":       "2022-11-20T12:00:00Z",
 				"payment_type": "bank_transfer",
 			},
 			HasReturnBody: true,
@@ -97,9 +280,9 @@ func (s *suiteOrders) TestHandlerCreateNewOrder() {
 				"Content-Type": "text/plain",
 			},
 			Body: map[string]interface{}{
-				"customer_id":  "81cef832-e4de-4588-a026-6a106cf10a19",
 				"bike_ids":     []string{"92d88bd9-d3d2-4bd5-adba-a8161cc26cc1"},
-				"total_hour":   int(4),
+				"start_at":     "2022-11-20T08:00:00Z",
+				"end_at":       "2022-11-20T12:00:00Z",
 				"payment_type": "bank_transfer",
 			},
 			HasReturnBody: true,
@@ -120,6 +303,7 @@ func (s *suiteOrders) TestHandlerCreateNewOrder() {
 			e := echo.New()
 			ctx := e.NewContext(r, w)
 			ctx.Request().Header.Set("Content-Type", v.Header["Content-Type"])
+			ctx.Set("user", &jwt.Token{Claims: jwt.MapClaims{"user_id": customerId, "role": "customer"}})
 
 			err := s.handler.HandlerCreateNewOrder(ctx)
 			s.NoError(err)
@@ -136,17 +320,99 @@ func (s *suiteOrders) TestHandlerCreateNewOrder() {
 			}
 		})
 	}
+
+	// orders are only ever placed for the user of the token
+	s.mocking.Mock.AssertNotCalled(s.T(), "CreateOrder", "c2d3e4f5-a6b7-4c8d-9e0f-1a2b3c4d5e6f", mock.Anything)
+}
+
+func (s *suiteOrders) TestHandlerPickupBike() {
+	actorId := "ffad8203-b32d-46dd-b488-a700ad61dac7"
+
+	s.mocking.Mock.On("PickupBike", "9c1f5e2a-3b4d-4e6f-8a7b-0c1d2e3f4a5b", actorId).Return(nil)
+	s.mocking.Mock.On("PickupBike", "2d3e4f5a-6b7c-4d8e-9f0a-1b2c3d4e5f6a", actorId).Return(fmt.Errorf("%w: pending_payment to picked_up", pkg.ErrInvalidStatusTransition))
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Method             string
+		OrderId            string
+		HasReturnBody      bool
+		ExpectedResult     map[string]interface{}
+	}{
+		{
+			Name:               "success pick up bike",
+			ExpectedStatusCode: http.StatusOK,
+			Method:             "POST",
+			OrderId:            "9c1f5e2a-3b4d-4e6f-8a7b-0c1d2e3f4a5b",
+			HasReturnBody:      true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "success",
+				"message": "success pick up bike",
+				"data":    nil,
+			},
+		},
+		{
+			Name:               "order not paid yet",
+			ExpectedStatusCode: http.StatusConflict,
+			Method:             "POST",
+			OrderId:            "2d3e4f5a-6b7c-4d8e-9f0a-1b2c3d4e5f6a",
+			HasReturnBody:      true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "invalid order status transition: pending_payment to picked_up",
+				"data":    nil,
+			},
+		},
+	}
+
+	for _, v := range testCases {
+		s.T().Run(v.Name, func(t *testing.T) {
+			r := httptest.NewRequest(v.Method, "/orders", nil)
+			w := httptest.NewRecorder()
+
+			e := echo.New()
+			ctx := e.NewContext(r, w)
+			ctx.SetPath("/:id/pickup")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(v.OrderId)
+			ctx.Set("user", &jwt.Token{Claims: jwt.MapClaims{"user_id": actorId, "role": "renter"}})
+
+			err := s.handler.HandlerPickupBike(ctx)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatusCode, w.Result().StatusCode)
+
+			if v.HasReturnBody {
+				var resp map[string]interface{}
+				err := json.NewDecoder(w.Result().Body).Decode(&resp)
+				s.NoError(err)
+
+				s.Equal(v.ExpectedResult["status"], resp["status"])
+				s.Equal(v.ExpectedResult["message"], resp["message"])
+			}
+		})
+	}
 }
 
 func (s *suiteOrders) TestHandlerReturnBike() {
-	orderId := "47fed3fe-5718-4b20-a525-a914ab80ba5a"
+	actorId := "ffad8203-b32d-46dd-b488-a700ad61dac7"
+
+	data := map[string]interface{}{
+		"order_id":    "47fed3fe-5718-4b20-a525-a914ab80ba5a",
+		"status":      model.OrderStatusClosed,
+		"returned_at": time.Now(),
+		"late_hours":  0,
+		"late_fee":    float32(0),
+	}
 
-	s.mocking.Mock.On("UpdateRentStatus", orderId).Return(nil)
+	s.mocking.Mock.On("UpdateRentStatus", "47fed3fe-5718-4b20-a525-a914ab80ba5a", actorId).Return(data, nil)
+	s.mocking.Mock.On("UpdateRentStatus", "5e6f7a8b-9c0d-4e1f-a2b3-c4d5e6f7a8b9", actorId).Return(map[string]interface{}(nil), fmt.Errorf("%w: pending_payment to returned", pkg.ErrInvalidStatusTransition))
 
 	testCases := []struct {
 		Name               string
 		ExpectedStatusCode int
 		Method             string
+		OrderId            string
 		HasReturnBody      bool
 		ExpectedResult     map[string]interface{}
 	}{
@@ -154,6 +420,7 @@ func (s *suiteOrders) TestHandlerReturnBike() {
 			Name:               "success return bike",
 			ExpectedStatusCode: http.StatusOK,
 			Method:             "GET",
+			OrderId:            "47fed3fe-5718-4b20-a525-a914ab80ba5a",
 			HasReturnBody:      true,
 			ExpectedResult: map[string]interface{}{
 				"status":  "success",
@@ -161,6 +428,18 @@ func (s *suiteOrders) TestHandlerReturnBike() {
 				"data":    nil,
 			},
 		},
+		{
+			Name:               "return unpaid order",
+			ExpectedStatusCode: http.StatusConflict,
+			Method:             "GET",
+			OrderId:            "5e6f7a8b-9c0d-4e1f-a2b3-c4d5e6f7a8b9",
+			HasReturnBody:      true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "invalid order status transition: pending_payment to returned",
+				"data":    nil,
+			},
+		},
 	}
 
 	for _, v := range testCases {
@@ -172,7 +451,8 @@ func (s *suiteOrders) TestHandlerReturnBike() {
 			ctx := e.NewContext(r, w)
 			ctx.SetPath("/:id/return")
 			ctx.SetParamNames("id")
-			ctx.SetParamValues(orderId)
+			ctx.SetParamValues(v.OrderId)
+			ctx.Set("user", &jwt.Token{Claims: jwt.MapClaims{"user_id": actorId, "role": "renter"}})
 
 			err := s.handler.HandlerReturnBike(ctx)
 			s.NoError(err)
@@ -191,6 +471,569 @@ func (s *suiteOrders) TestHandlerReturnBike() {
 	}
 }
 
+func (s *suiteOrders) TestHandlerCancelOrder() {
+	actorId := "02629953-7ac7-4c77-83c0-136a0f252427"
+
+	data := map[string]interface{}{
+		"order_id":         "6d7e8f9a-0b1c-4d2e-8f3a-4b5c6d7e8f9a",
+		"status":           model.OrderStatusCanceled,
+		"payment_status":   "cancel",
+		"cancellation_fee": float32(0),
+		"refund_amount":    float32(75000),
+	}
+
+	s.mocking.Mock.On("CancelOrder", "6d7e8f9a-0b1c-4d2e-8f3a-4b5c6d7e8f9a", actorId).Return(data, nil)
+	s.mocking.Mock.On("CancelOrder", "7e8f9a0b-1c2d-4e3f-9a4b-5c6d7e8f9a0b", actorId).Return(map[string]interface{}(nil), fmt.Errorf("%w: picked_up to canceled", pkg.ErrInvalidStatusTransition))
+	s.mocking.Mock.On("CancelOrder", "8f9a0b1c-2d3e-4f4a-8b5c-6d7e8f9a0b1c", actorId).Return(map[string]interface{}(nil), fmt.Errorf("%w: refund is not supported", pkg.ErrPaymentGateway))
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Method             string
+		OrderId            string
+		HasReturnBody      bool
+		ExpectedResult     map[string]interface{}
+	}{
+		{
+			Name:               "success cancel order",
+			ExpectedStatusCode: http.StatusOK,
+			Method:             "POST",
+			OrderId:            "6d7e8f9a-0b1c-4d2e-8f3a-4b5c6d7e8f9a",
+			HasReturnBody:      true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "success",
+				"message": "success cancel order",
+			},
+		},
+		{
+			Name:               "bike already picked up",
+			ExpectedStatusCode: http.StatusConflict,
+			Method:             "POST",
+			OrderId:            "7e8f9a0b-1c2d-4e3f-9a4b-5c6d7e8f9a0b",
+			HasReturnBody:      true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "invalid order status transition: picked_up to canceled",
+			},
+		},
+		{
+			Name:               "payment gateway failed",
+			ExpectedStatusCode: http.StatusBadGateway,
+			Method:             "POST",
+			OrderId:            "8f9a0b1c-2d3e-4f4a-8b5c-6d7e8f9a0b1c",
+			HasReturnBody:      true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "payment gateway error: refund is not supported",
+			},
+		},
+	}
+
+	for _, v := range testCases {
+		s.T().Run(v.Name, func(t *testing.T) {
+			r := httptest.NewRequest(v.Method, "/orders", nil)
+			w := httptest.NewRecorder()
+
+			e := echo.New()
+			ctx := e.NewContext(r, w)
+			ctx.SetPath("/:id/cancel")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(v.OrderId)
+			ctx.Set("user", &jwt.Token{Claims: jwt.MapClaims{"user_id": actorId, "role": "customer"}})
+
+			err := s.handler.HandlerCancelOrder(ctx)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatusCode, w.Result().StatusCode)
+
+			if v.HasReturnBody {
+				var resp map[string]interface{}
+				err := json.NewDecoder(w.Result().Body).Decode(&resp)
+				s.NoError(err)
+
+				s.Equal(v.ExpectedResult["status"], resp["status"])
+				s.Equal(v.ExpectedResult["message"], resp["message"])
+			}
+		})
+	}
+}
+
+func (s *suiteOrders) TestHandlerExtendOrder() {
+	endAt := time.Date(2022, 11, 20, 16, 0, 0, 0, time.UTC)
+
+	data := map[string]interface{}{
+		"order_id":       "9a0b1c2d-3e4f-4a5b-9c6d-7e8f9a0b1c2d",
+		"end_at":         time.Date(2022, 11, 20, 13, 0, 0, 0, time.UTC),
+		"pending_end_at": endAt,
+		"extra_hours":    3,
+		"extra_payment":  float32(45000),
+		"payment_link":   "https://app.sandbox.midtrans.com/snap/v3/redirection/extension",
+	}
+
+	s.mocking.Mock.On("ExtendOrder", "9a0b1c2d-3e4f-4a5b-9c6d-7e8f9a0b1c2d", dto.OrderExtensionDTO{EndAt: endAt}).Return(data, nil)
+	s.mocking.Mock.On("ExtendOrder", "0b1c2d3e-4f5a-4b6c-8d7e-8f9a0b1c2d3e", dto.OrderExtensionDTO{EndAt: endAt}).Return(map[string]interface{}(nil), fmt.Errorf("%w: Sample BMX Bike is booked from 2022-11-20T14:00:00Z to 2022-11-20T18:00:00Z", pkg.ErrBookingConflict))
+	s.mocking.Mock.On("ExtendOrder", "1c2d3e4f-5a6b-4c7d-9e8f-9a0b1c2d3e4f", dto.OrderExtensionDTO{EndAt: endAt}).Return(map[string]interface{}(nil), pkg.ErrOrderNotExtendable)
+	s.mocking.Mock.On("ExtendOrder", "2d3e4f5a-6b7c-4d8e-8f9a-0b1c2d3e4f5a", dto.OrderExtensionDTO{EndAt: endAt}).Return(map[string]interface{}(nil), pkg.ErrRecordNotFound)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Method             string
+		OrderId            string
+		Header             map[string]string
+		Body               map[string]interface{}
+		HasReturnBody      bool
+		ExpectedResult     map[string]interface{}
+	}{
+		{
+			Name:               "success extend order",
+			ExpectedStatusCode: http.StatusOK,
+			Method:             "POST",
+			OrderId:            "9a0b1c2d-3e4f-4a5b-9c6d-7e8f9a0b1c2d",
+			Header: map[string]string{
+				"Content-Type": "application/json",
+			},
+			Body: map[string]interface{}{
+				"end_at": "2022-11-20T16:00:00Z",
+			},
+			HasReturnBody: true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "success",
+				"message": "success extend order",
+			},
+		},
+		{
+			Name:               "failed bike already booked",
+			ExpectedStatusCode: http.StatusConflict,
+			Method:             "POST",
+			OrderId:            "0b1c2d3e-4f5a-4b6c-8d7e-8f9a0b1c2d3e",
+			Header: map[string]string{
+				"Content-Type": "application/json",
+			},
+			Body: map[string]interface{}{
+				"end_at": "2022-11-20T16:00:00Z",
+			},
+			HasReturnBody: true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "bike already booked: Sample BMX Bike is booked from 2022-11-20T14:00:00Z to 2022-11-20T18:00:00Z",
+			},
+		},
+		{
+			Name:               "failed order not extendable",
+			ExpectedStatusCode: http.StatusConflict,
+			Method:             "POST",
+			OrderId:            "1c2d3e4f-5a6b-4c7d-9e8f-9a0b1c2d3e4f",
+			Header: map[string]string{
+				"Content-Type": "application/json",
+			},
+			Body: map[string]interface{}{
+				"end_at": "2022-11-20T16:00:00Z",
+			},
+			HasReturnBody: true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "only paid or picked up orders can be extended",
+			},
+		},
+		{
+			Name:               "failed order not found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Method:             "POST",
+			OrderId:            "2d3e4f5a-6b7c-4d8e-8f9a-0b1c2d3e4f5a",
+			Header: map[string]string{
+				"Content-Type": "application/json",
+			},
+			Body: map[string]interface{}{
+				"end_at": "2022-11-20T16:00:00Z",
+			},
+			HasReturnBody: true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "order not found",
+			},
+		},
+		{
+			Name:               "failed wrong content-type",
+			ExpectedStatusCode: http.StatusBadRequest,
+			Method:             "POST",
+			OrderId:            "9a0b1c2d-3e4f-4a5b-9c6d-7e8f9a0b1c2d",
+			Header: map[string]string{
+				"Content-Type": "text/plain",
+			},
+			Body: map[string]interface{}{
+				"end_at": "2022-11-20T16:00:00Z",
+			},
+			HasReturnBody: true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "fill all required fields",
+			},
+		},
+	}
+
+	for _, v := range testCases {
+		s.T().Run(v.Name, func(t *testing.T) {
+			res, _ := json.Marshal(v.Body)
+			r := httptest.NewRequest(v.Method, "/orders", bytes.NewReader(res))
+			w := httptest.NewRecorder()
+
+			e := echo.New()
+			ctx := e.NewContext(r, w)
+			ctx.Request().Header.Set("Content-Type", v.Header["Content-Type"])
+			ctx.SetPath("/:id/extend")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(v.OrderId)
+
+			err := s.handler.HandlerExtendOrder(ctx)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatusCode, w.Result().StatusCode)
+
+			if v.HasReturnBody {
+				var resp map[string]interface{}
+				err := json.NewDecoder(w.Result().Body).Decode(&resp)
+				s.NoError(err)
+
+				s.Equal(v.ExpectedResult["status"], resp["status"])
+				s.Equal(v.ExpectedResult["message"], resp["message"])
+			}
+		})
+	}
+}
+
+func (s *suiteOrders) TestHandlerSettleDeposit() {
+	actorId := "5e6f7a8b-9c0d-4e1f-8a2b-3c4d5e6f7a8b"
+	depositDTO := dto.OrderDepositDTO{WithheldAmount: 20000, Reason: "broken brake lever"}
+
+	data := map[string]interface{}{
+		"order_id":         "3e4f5a6b-7c8d-4e9f-8a0b-1c2d3e4f5a6b",
+		"deposit":          float32(50000),
+		"deposit_status":   model.DepositStatusWithheld,
+		"deposit_withheld": float32(20000),
+		"deposit_released": float32(30000),
+		"deposit_reason":   "broken brake lever",
+		"payment_status":   model.PaymentStatusPartialRefund,
+	}
+
+	s.mocking.Mock.On("SettleDeposit", "3e4f5a6b-7c8d-4e9f-8a0b-1c2d3e4f5a6b", actorId, depositDTO).Return(data, nil)
+	s.mocking.Mock.On("SettleDeposit", "4f5a6b7c-8d9e-4f0a-9b1c-2d3e4f5a6b7c", actorId, depositDTO).Return(map[string]interface{}(nil), fmt.Errorf("%w: the bikes of the order are not returned yet", pkg.ErrDepositNotSettleable))
+	s.mocking.Mock.On("SettleDeposit", "5a6b7c8d-9e0f-4a1b-8c2d-3e4f5a6b7c8d", actorId, depositDTO).Return(map[string]interface{}(nil), fmt.Errorf("%w: at most 10000 can be withheld", pkg.ErrInvalidDeposit))
+	s.mocking.Mock.On("SettleDeposit", "6b7c8d9e-0f1a-4b2c-9d3e-4f5a6b7c8d9e", actorId, depositDTO).Return(map[string]interface{}(nil), pkg.ErrForbidden)
+	s.mocking.Mock.On("SettleDeposit", "7c8d9e0f-1a2b-4c3d-8e4f-5a6b7c8d9e0f", actorId, depositDTO).Return(map[string]interface{}(nil), pkg.ErrRecordNotFound)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Method             string
+		OrderId            string
+		Header             map[string]string
+		Body               map[string]interface{}
+		HasReturnBody      bool
+		ExpectedResult     map[string]interface{}
+	}{
+		{
+			Name:               "success settle deposit",
+			ExpectedStatusCode: http.StatusOK,
+			Method:             "POST",
+			OrderId:            "3e4f5a6b-7c8d-4e9f-8a0b-1c2d3e4f5a6b",
+			Header: map[string]string{
+				"Content-Type": "application/json",
+			},
+			Body: map[string]interface{}{
+				"withheld_amount": 20000,
+				"reason":          "broken brake lever",
+			},
+			HasReturnBody: true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "success",
+				"message": "success settle deposit",
+			},
+		},
+		{
+			Name:               "failed bikes not returned",
+			ExpectedStatusCode: http.StatusConflict,
+			Method:             "POST",
+			OrderId:            "4f5a6b7c-8d9e-4f0a-9b1c-2d3e4f5a6b7c",
+			Header: map[string]string{
+				"Content-Type": "application/json",
+			},
+			Body: map[string]interface{}{
+				"withheld_amount": 20000,
+				"reason":          "broken brake lever",
+			},
+			HasReturnBody: true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "deposit cannot be settled: the bikes of the order are not returned yet",
+			},
+		},
+		{
+			Name:               "failed withheld more than deposit",
+			ExpectedStatusCode: http.StatusBadRequest,
+			Method:             "POST",
+			OrderId:            "5a6b7c8d-9e0f-4a1b-8c2d-3e4f5a6b7c8d",
+			Header: map[string]string{
+				"Content-Type": "application/json",
+			},
+			Body: map[string]interface{}{
+				"withheld_amount": 20000,
+				"reason":          "broken brake lever",
+			},
+			HasReturnBody: true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "invalid deposit: at most 10000 can be withheld",
+			},
+		},
+		{
+			Name:               "failed not a renter of the order",
+			ExpectedStatusCode: http.StatusForbidden,
+			Method:             "POST",
+			OrderId:            "6b7c8d9e-0f1a-4b2c-9d3e-4f5a6b7c8d9e",
+			Header: map[string]string{
+				"Content-Type": "application/json",
+			},
+			Body: map[string]interface{}{
+				"withheld_amount": 20000,
+				"reason":          "broken brake lever",
+			},
+			HasReturnBody: true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "you are not allowed to settle the deposit of this order",
+			},
+		},
+		{
+			Name:               "failed order not found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Method:             "POST",
+			OrderId:            "7c8d9e0f-1a2b-4c3d-8e4f-5a6b7c8d9e0f",
+			Header: map[string]string{
+				"Content-Type": "application/json",
+			},
+			Body: map[string]interface{}{
+				"withheld_amount": 20000,
+				"reason":          "broken brake lever",
+			},
+			HasReturnBody: true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "order not found",
+			},
+		},
+		{
+			Name:               "failed wrong content-type",
+			ExpectedStatusCode: http.StatusBadRequest,
+			Method:             "POST",
+			OrderId:            "3e4f5a6b-7c8d-4e9f-8a0b-1c2d3e4f5a6b",
+			Header: map[string]string{
+				"Content-Type": "text/plain",
+			},
+			Body: map[string]interface{}{
+				"withheld_amount": 20000,
+			},
+			HasReturnBody: true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "fill all required fields",
+			},
+		},
+	}
+
+	for _, v := range testCases {
+		s.T().Run(v.Name, func(t *testing.T) {
+			res, _ := json.Marshal(v.Body)
+			r := httptest.NewRequest(v.Method, "/orders", bytes.NewReader(res))
+			w := httptest.NewRecorder()
+
+			e := echo.New()
+			ctx := e.NewContext(r, w)
+			ctx.Request().Header.Set("Content-Type", v.Header["Content-Type"])
+			ctx.SetPath("/:id/deposit")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(v.OrderId)
+			ctx.Set("user", &jwt.Token{Claims: jwt.MapClaims{"user_id": actorId, "role": "renter"}})
+
+			err := s.handler.HandlerSettleDeposit(ctx)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatusCode, w.Result().StatusCode)
+
+			if v.HasReturnBody {
+				var resp map[string]interface{}
+				err := json.NewDecoder(w.Result().Body).Decode(&resp)
+				s.NoError(err)
+
+				s.Equal(v.ExpectedResult["status"], resp["status"])
+				s.Equal(v.ExpectedResult["message"], resp["message"])
+			}
+		})
+	}
+}
+
+func (s *suiteOrders) TestHandlerFindOrderStatusHistories() {
+	orderId := "8a9b0c1d-2e3f-4a5b-8c7d-9e0f1a2b3c4d"
+
+	statusHistories := &[]model.OrderStatusHistory{
+		{
+			ID:        "3f4a5b6c-7d8e-4f9a-8b1c-2d3e4f5a6b7c",
+			OrderId:   orderId,
+			ToStatus:  model.OrderStatusPendingPayment,
+			Actor:     "02629953-7ac7-4c77-83c0-136a0f252427",
+			CreatedAt: time.Now(),
+		},
+	}
+
+	s.mocking.Mock.On("FindOrderStatusHistories", orderId).Return(statusHistories, nil)
+	s.mocking.Mock.On("FindOrderStatusHistories", "0f1e2d3c-4b5a-4968-8776-a5b4c3d2e1f0").Return((*[]model.OrderStatusHistory)(nil), pkg.ErrRecordNotFound)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Method             string
+		OrderId            string
+		HasReturnBody      bool
+		ExpectedResult     map[string]interface{}
+	}{
+		{
+			Name:               "success get order status histories",
+			ExpectedStatusCode: http.StatusOK,
+			Method:             "GET",
+			OrderId:            orderId,
+			HasReturnBody:      true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "success",
+				"message": "success get order status histories",
+			},
+		},
+		{
+			Name:               "order not found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Method:             "GET",
+			OrderId:            "0f1e2d3c-4b5a-4968-8776-a5b4c3d2e1f0",
+			HasReturnBody:      true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "order not found",
+			},
+		},
+	}
+
+	for _, v := range testCases {
+		s.T().Run(v.Name, func(t *testing.T) {
+			r := httptest.NewRequest(v.Method, "/orders", nil)
+			w := httptest.NewRecorder()
+
+			e := echo.New()
+			ctx := e.NewContext(r, w)
+			ctx.SetPath("/:id/statuses")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(v.OrderId)
+
+			err := s.handler.HandlerFindOrderStatusHistories(ctx)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatusCode, w.Result().StatusCode)
+
+			if v.HasReturnBody {
+				var resp map[string]interface{}
+				err := json.NewDecoder(w.Result().Body).Decode(&resp)
+				s.NoError(err)
+
+				s.Equal(v.ExpectedResult["status"], resp["status"])
+				s.Equal(v.ExpectedResult["message"], resp["message"])
+			}
+		})
+	}
+}
+
+func (s *suiteOrders) TestHandlerOverrideOrderStatus() {
+	adminId := "1b3d5f7a-9c1e-4b3d-8f7a-9c1e3b5d7f90"
+	overrideDTO := dto.OrderOverrideDTO{Status: model.OrderStatusClosed, Reason: "bike returned to the shop"}
+
+	s.mocking.Mock.On("OverrideOrderStatus", "3d5f7a9c-1e3b-4d5f-9a9c-1e3b5d7f9a12", adminId, overrideDTO).Return(nil)
+	s.mocking.Mock.On("OverrideOrderStatus", "5f7a9c1e-3b5d-4f7a-8c1e-3b5d7f9a1c34", adminId, overrideDTO).Return(pkg.ErrRecordNotFound)
+	s.mocking.Mock.On("OverrideOrderStatus", "7a9c1e3b-5d7f-4a9c-9e3b-5d7f9a1c3e56", adminId, dto.OrderOverrideDTO{Status: model.OrderStatusClosed}).Return(pkg.ErrInvalidOrderOverride)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Method             string
+		OrderId            string
+		Body               map[string]interface{}
+		HasReturnBody      bool
+		ExpectedResult     map[string]interface{}
+	}{
+		{
+			Name:               "success override order status",
+			ExpectedStatusCode: http.StatusOK,
+			Method:             "POST",
+			OrderId:            "3d5f7a9c-1e3b-4d5f-9a9c-1e3b5d7f9a12",
+			Body:               map[string]interface{}{"status": "closed", "reason": "bike returned to the shop"},
+			HasReturnBody:      true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "success",
+				"message": "success override order status",
+			},
+		},
+		{
+			Name:               "order not found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Method:             "POST",
+			OrderId:            "5f7a9c1e-3b5d-4f7a-8c1e-3b5d7f9a1c34",
+			Body:               map[string]interface{}{"status": "closed", "reason": "bike returned to the shop"},
+			HasReturnBody:      true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "order not found",
+			},
+		},
+		{
+			Name:               "failed without reason",
+			ExpectedStatusCode: http.StatusBadRequest,
+			Method:             "POST",
+			OrderId:            "7a9c1e3b-5d7f-4a9c-9e3b-5d7f9a1c3e56",
+			Body:               map[string]interface{}{"status": "closed"},
+			HasReturnBody:      true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "a reason and a status other than the current one are required",
+			},
+		},
+	}
+
+	for _, v := range testCases {
+		s.T().Run(v.Name, func(t *testing.T) {
+			res, _ := json.Marshal(v.Body)
+			r := httptest.NewRequest(v.Method, "/admin/orders", bytes.NewReader(res))
+			w := httptest.NewRecorder()
+
+			e := echo.New()
+			ctx := e.NewContext(r, w)
+			ctx.Request().Header.Set("Content-Type", "application/json")
+			ctx.SetPath("/:id/status")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(v.OrderId)
+			ctx.Set("user", &jwt.Token{Claims: jwt.MapClaims{"user_id": adminId, "role": "admin"}})
+
+			err := s.handler.HandlerOverrideOrderStatus(ctx)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatusCode, w.Result().StatusCode)
+
+			if v.HasReturnBody {
+				var resp map[string]interface{}
+				err := json.NewDecoder(w.Result().Body).Decode(&resp)
+				s.NoError(err)
+
+				s.Equal(v.ExpectedResult["status"], resp["status"])
+				s.Equal(v.ExpectedResult["message"], resp["message"])
+			}
+		})
+	}
+}
+
 func (s *suiteOrders) TearDownSuite() {
 	s.mocking = nil
 }
@@ -0,0 +1,25 @@
+package rest_http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+)
+
+// paymentGatewayStatusCode maps a failure of the payment gateway onto a http status code,
+// it returns 0 when err did not come from the payment gateway.
+func paymentGatewayStatusCode(err error) int {
+	switch {
+	case errors.Is(err, pkg.ErrGatewayTimeout):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, pkg.ErrGatewayDuplicateOrder):
+		return http.StatusConflict
+	case errors.Is(err, pkg.ErrGatewayInvalidRequest):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, pkg.ErrPaymentGateway), errors.Is(err, pkg.ErrPaymentLinkNotCreated):
+		return http.StatusBadGateway
+	}
+
+	return 0
+}
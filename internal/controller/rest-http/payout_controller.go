@@ -0,0 +1,242 @@
+package rest_http
+
+import (
+	"errors"
+	"fmt"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"net/http"
+
+	"github.com/arvinpaundra/go-rent-bike/helper"
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+type PayoutController struct {
+	payoutUsecase usecase.PayoutUsecase
+}
+
+func NewPayoutController(payoutUsecase usecase.PayoutUsecase) *PayoutController {
+	return &PayoutController{payoutUsecase}
+}
+
+func (h *PayoutController) HandlerCreatePayoutBatch(c echo.Context) error {
+	batch, err := h.payoutUsecase.CreatePayoutBatch()
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrNoPayableBalance) {
+			return c.JSON(http.StatusUnprocessableEntity, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"status":  "success",
+		"message": "success create payout batch",
+		"data": map[string]*model.PayoutBatch{
+			"payout_batch": batch,
+		},
+	})
+}
+
+func (h *PayoutController) HandlerFindAllPayoutBatches(c echo.Context) error {
+	batches, err := h.payoutUsecase.FindAllPayoutBatches()
+
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success get all payout batches",
+		"data": map[string]*[]model.PayoutBatch{
+			"payout_batches": batches,
+		},
+	})
+}
+
+func (h *PayoutController) HandlerFindPayoutBatchById(c echo.Context) error {
+	batchId := c.Param("id")
+
+	batch, err := h.payoutUsecase.FindPayoutBatchById(batchId)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "payout batch not found",
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success get payout batch by id",
+		"data": map[string]*model.PayoutBatch{
+			"payout_batch": batch,
+		},
+	})
+}
+
+func (h *PayoutController) HandlerExportPayoutBatch(c echo.Context) error {
+	batchId := c.Param("id")
+
+	file, err := h.payoutUsecase.ExportPayoutBatch(batchId)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "payout batch not found",
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=payout-batch-%s.csv", batchId))
+
+	return c.Blob(http.StatusOK, "text/csv", file)
+}
+
+func (h *PayoutController) HandlerUpdatePayoutStatus(c echo.Context) error {
+	batchId := c.Param("id")
+	payoutId := c.Param("payoutId")
+	payoutStatusDTO := dto.PayoutStatusDTO{}
+
+	if err := c.Bind(&payoutStatusDTO); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status":  "error",
+			"message": "fill all required fields",
+			"data":    nil,
+		})
+	}
+
+	payout, err := h.payoutUsecase.UpdatePayoutStatus(batchId, payoutId, payoutStatusDTO)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "payout not found",
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrInvalidPayout) {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrPayoutNotPending) {
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success update payout status",
+		"data": map[string]*model.Payout{
+			"payout": payout,
+		},
+	})
+}
+
+func (h *PayoutController) HandlerFindRenterEarnings(c echo.Context) error {
+	actorId := helper.ExtractTokenClaims(c)["user_id"]
+	renterId := c.Param("id")
+
+	earnings, err := h.payoutUsecase.FindRenterEarnings(actorId, renterId)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrForbidden) {
+			return c.JSON(http.StatusForbidden, map[string]interface{}{
+				"status":  "error",
+				"message": "you are not allowed to see earnings of this renter",
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success get renter earnings",
+		"data":    earnings,
+	})
+}
+
+func (h *PayoutController) HandlerFindAllRenterPayouts(c echo.Context) error {
+	actorId := helper.ExtractTokenClaims(c)["user_id"]
+	renterId := c.Param("id")
+
+	payouts, err := h.payoutUsecase.FindAllRenterPayouts(actorId, renterId)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrForbidden) {
+			return c.JSON(http.StatusForbidden, map[string]interface{}{
+				"status":  "error",
+				"message": "you are not allowed to see payouts of this renter",
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success get all renter payouts",
+		"data": map[string]*[]model.Payout{
+			"payouts": payouts,
+		},
+	})
+}
@@ -0,0 +1,230 @@
+package rest_http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	usecasemock "github.com/arvinpaundra/go-rent-bike/internal/usecase/mock"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type suitePayouts struct {
+	suite.Suite
+	handler *PayoutController
+	mocking *usecasemock.PayoutUsecaseMock
+}
+
+func (s *suitePayouts) SetupSuite() {
+	mock := &usecasemock.PayoutUsecaseMock{}
+	s.mocking = mock
+
+	s.handler = &PayoutController{
+		payoutUsecase: s.mocking,
+	}
+}
+
+func (s *suitePayouts) TestHandlerCreatePayoutBatch() {
+	s.mocking.Mock.On("CreatePayoutBatch").Return((*model.PayoutBatch)(nil), pkg.ErrNoPayableBalance).Once()
+
+	r := httptest.NewRequest("POST", "/payout-batches", nil)
+	w := httptest.NewRecorder()
+
+	e := echo.New()
+	ctx := e.NewContext(r, w)
+
+	err := s.handler.HandlerCreatePayoutBatch(ctx)
+	s.NoError(err)
+
+	s.Equal(http.StatusUnprocessableEntity, w.Result().StatusCode)
+
+	var resp map[string]interface{}
+	err = json.NewDecoder(w.Result().Body).Decode(&resp)
+	s.NoError(err)
+
+	s.Equal("no renter balance to pay out", resp["message"])
+}
+
+func (s *suitePayouts) TestHandlerUpdatePayoutStatus() {
+	batchId := "1e2f3a4b-5c6d-4e7f-8a9b-0c1d2e3f4a5b"
+
+	payout := &model.Payout{
+		ID:        "2f3a4b5c-6d7e-4f8a-9b0c-1d2e3f4a5b6c",
+		BatchId:   batchId,
+		Amount:    67500,
+		Status:    model.PayoutStatusPaid,
+		Reference: "TRF-1",
+	}
+
+	s.mocking.Mock.On("UpdatePayoutStatus", batchId, payout.ID, dto.PayoutStatusDTO{Status: "paid", Reference: "TRF-1"}).Return(payout, nil)
+	s.mocking.Mock.On("UpdatePayoutStatus", batchId, payout.ID, dto.PayoutStatusDTO{Status: "failed"}).Return((*model.Payout)(nil), fmt.Errorf("%w: payout is paid", pkg.ErrPayoutNotPending))
+	s.mocking.Mock.On("UpdatePayoutStatus", batchId, payout.ID, dto.PayoutStatusDTO{Status: "sent"}).Return((*model.Payout)(nil), fmt.Errorf("%w: status must be paid or failed", pkg.ErrInvalidPayout))
+	s.mocking.Mock.On("UpdatePayoutStatus", batchId, "3a4b5c6d-7e8f-4a9b-8c0d-2e3f4a5b6c7d", dto.PayoutStatusDTO{Status: "paid"}).Return((*model.Payout)(nil), pkg.ErrRecordNotFound)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		PayoutId           string
+		ContentType        string
+		Body               map[string]interface{}
+		ExpectedMessage    string
+	}{
+		{
+			Name:               "success update payout status",
+			ExpectedStatusCode: http.StatusOK,
+			PayoutId:           payout.ID,
+			ContentType:        "application/json",
+			Body:               map[string]interface{}{"status": "paid", "reference": "TRF-1"},
+			ExpectedMessage:    "success update payout status",
+		},
+		{
+			Name:               "failed payout already paid",
+			ExpectedStatusCode: http.StatusConflict,
+			PayoutId:           payout.ID,
+			ContentType:        "application/json",
+			Body:               map[string]interface{}{"status": "failed"},
+			ExpectedMessage:    "payout already settled: payout is paid",
+		},
+		{
+			Name:               "failed unknown status",
+			ExpectedStatusCode: http.StatusBadRequest,
+			PayoutId:           payout.ID,
+			ContentType:        "application/json",
+			Body:               map[string]interface{}{"status": "sent"},
+			ExpectedMessage:    "invalid payout: status must be paid or failed",
+		},
+		{
+			Name:               "failed payout not found",
+			ExpectedStatusCode: http.StatusNotFound,
+			PayoutId:           "3a4b5c6d-7e8f-4a9b-8c0d-2e3f4a5b6c7d",
+			ContentType:        "application/json",
+			Body:               map[string]interface{}{"status": "paid"},
+			ExpectedMessage:    "payout not found",
+		},
+		{
+			Name:               "failed wrong content-type",
+			ExpectedStatusCode: http.StatusBadRequest,
+			PayoutId:           payout.ID,
+			ContentType:        "text/plain",
+			Body:               map[string]interface{}{"status": "paid"},
+			ExpectedMessage:    "fill all required fields",
+		},
+	}
+
+	for _, v := range testCases {
+		s.T().Run(v.Name, func(t *testing.T) {
+			res, _ := json.Marshal(v.Body)
+			r := httptest.NewRequest("PUT", "/payout-batches", bytes.NewReader(res))
+			w := httptest.NewRecorder()
+
+			e := echo.New()
+			ctx := e.NewContext(r, w)
+			ctx.SetPath("/:id/payouts/:payoutId")
+			ctx.SetParamNames("id", "payoutId")
+			ctx.SetParamValues(batchId, v.PayoutId)
+			ctx.Request().Header.Set("Content-Type", v.ContentType)
+
+			err := s.handler.HandlerUpdatePayoutStatus(ctx)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatusCode, w.Result().StatusCode)
+
+			var resp map[string]interface{}
+			err = json.NewDecoder(w.Result().Body).Decode(&resp)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedMessage, resp["message"])
+		})
+	}
+}
+
+func (s *suitePayouts) TestHandlerExportPayoutBatch() {
+	batchId := "4b5c6d7e-8f9a-4b0c-9d1e-3f4a5b6c7d8e"
+	file := []byte("payout_id,bank_name,bank_account_number,bank_account_name,amount,description\n")
+
+	s.mocking.Mock.On("ExportPayoutBatch", batchId).Return(file, nil)
+
+	r := httptest.NewRequest("GET", "/payout-batches", nil)
+	w := httptest.NewRecorder()
+
+	e := echo.New()
+	ctx := e.NewContext(r, w)
+	ctx.SetPath("/:id/export")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(batchId)
+
+	err := s.handler.HandlerExportPayoutBatch(ctx)
+	s.NoError(err)
+
+	s.Equal(http.StatusOK, w.Result().StatusCode)
+	s.Equal("text/csv", w.Result().Header.Get(echo.HeaderContentType))
+	s.Equal("attachment; filename=payout-batch-"+batchId+".csv", w.Result().Header.Get(echo.HeaderContentDisposition))
+	s.Equal(file, w.Body.Bytes())
+}
+
+func (s *suitePayouts) TestHandlerFindRenterEarnings() {
+	actorId := "5c6d7e8f-9a0b-4c1d-8e2f-4a5b6c7d8e9f"
+
+	s.mocking.Mock.On("FindRenterEarnings", actorId, "6d7e8f9a-0b1c-4d2e-9f3a-5b6c7d8e9f0a").Return(map[string]interface{}{
+		"renter_id": "6d7e8f9a-0b1c-4d2e-9f3a-5b6c7d8e9f0a",
+		"balance":   float32(40500),
+		"entries":   &[]model.LedgerEntry{},
+	}, nil)
+	s.mocking.Mock.On("FindRenterEarnings", actorId, "7e8f9a0b-1c2d-4e3f-8a4b-6c7d8e9f0a1b").Return(map[string]interface{}(nil), pkg.ErrForbidden)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		RenterId           string
+		ExpectedMessage    string
+	}{
+		{
+			Name:               "success get renter earnings",
+			ExpectedStatusCode: http.StatusOK,
+			RenterId:           "6d7e8f9a-0b1c-4d2e-9f3a-5b6c7d8e9f0a",
+			ExpectedMessage:    "success get renter earnings",
+		},
+		{
+			Name:               "failed earnings of another renter",
+			ExpectedStatusCode: http.StatusForbidden,
+			RenterId:           "7e8f9a0b-1c2d-4e3f-8a4b-6c7d8e9f0a1b",
+			ExpectedMessage:    "you are not allowed to see earnings of this renter",
+		},
+	}
+
+	for _, v := range testCases {
+		s.T().Run(v.Name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/renters", nil)
+			w := httptest.NewRecorder()
+
+			e := echo.New()
+			ctx := e.NewContext(r, w)
+			ctx.SetPath("/:id/earnings")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(v.RenterId)
+			ctx.Set("user", &jwt.Token{Claims: jwt.MapClaims{"user_id": actorId, "role": "renter"}})
+
+			err := s.handler.HandlerFindRenterEarnings(ctx)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatusCode, w.Result().StatusCode)
+
+			var resp map[string]interface{}
+			err = json.NewDecoder(w.Result().Body).Decode(&resp)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedMessage, resp["message"])
+		})
+	}
+}
+
+func TestSuitePayouts(t *testing.T) {
+	suite.Run(t, new(suitePayouts))
+}
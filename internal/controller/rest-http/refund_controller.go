@@ -0,0 +1,136 @@
+package rest_http
+
+import (
+	"errors"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"net/http"
+
+	"github.com/arvinpaundra/go-rent-bike/helper"
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+type RefundController struct {
+	refundUsecase usecase.RefundUsecase
+}
+
+func NewRefundController(refundUsecase usecase.RefundUsecase) *RefundController {
+	return &RefundController{refundUsecase}
+}
+
+func (h *RefundController) HandlerCreateRefund(c echo.Context) error {
+	claims := helper.ExtractTokenClaims(c)
+	actorId := claims["user_id"]
+	actorRole := claims["role"]
+	paymentId := c.Param("id")
+	refundDTO := dto.RefundDTO{}
+
+	if err := c.Bind(&refundDTO); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status":  "error",
+			"message": "fill all required fields",
+			"data":    nil,
+		})
+	}
+
+	refund, err := h.refundUsecase.CreateRefund(actorId, actorRole, paymentId, refundDTO)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "payment not found",
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrForbidden) {
+			return c.JSON(http.StatusForbidden, map[string]interface{}{
+				"status":  "error",
+				"message": "you are not allowed to refund this payment",
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrInvalidRefund) || errors.Is(err, pkg.ErrRefundExceedsCaptured) {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrPaymentNotRefundable) {
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		if statusCode := paymentGatewayStatusCode(err); statusCode != 0 {
+			return c.JSON(statusCode, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"status":  "success",
+		"message": "success create refund",
+		"data": map[string]*model.Refund{
+			"refund": refund,
+		},
+	})
+}
+
+func (h *RefundController) HandlerFindAllRefunds(c echo.Context) error {
+	claims := helper.ExtractTokenClaims(c)
+	actorId := claims["user_id"]
+	actorRole := claims["role"]
+	paymentId := c.Param("id")
+
+	refunds, err := h.refundUsecase.FindAllRefunds(actorId, actorRole, paymentId)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "payment not found",
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrForbidden) {
+			return c.JSON(http.StatusForbidden, map[string]interface{}{
+				"status":  "error",
+				"message": "you are not allowed to see refunds of this payment",
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success get all refunds",
+		"data": map[string]*[]model.Refund{
+			"refunds": refunds,
+		},
+	})
+}
@@ -0,0 +1,265 @@
+package rest_http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	usecasemock "github.com/arvinpaundra/go-rent-bike/internal/usecase/mock"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type suiteRefunds struct {
+	suite.Suite
+	handler *RefundController
+	mocking *usecasemock.RefundUsecaseMock
+}
+
+func (s *suiteRefunds) SetupSuite() {
+	mock := &usecasemock.RefundUsecaseMock{}
+	s.mocking = mock
+
+	s.handler = &RefundController{
+		refundUsecase: s.mocking,
+	}
+}
+
+func (s *suiteRefunds) TestHandlerCreateRefund() {
+	actorId := "3a4b5c6d-7e8f-4a9b-8c0d-1e2f3a4b5c6d"
+
+	refund := &model.Refund{
+		ID:          "4b5c6d7e-8f9a-4b0c-9d1e-2f3a4b5c6d7e",
+		PaymentId:   "5c6d7e8f-9a0b-4c1d-8e2f-3a4b5c6d7e8f",
+		Amount:      25000,
+		Reason:      "bike was damaged",
+		Status:      model.RefundStatusPending,
+		RequestedBy: actorId,
+	}
+
+	s.mocking.Mock.On("CreateRefund", actorId, "renter", "5c6d7e8f-9a0b-4c1d-8e2f-3a4b5c6d7e8f", dto.RefundDTO{Amount: 25000, Reason: "bike was damaged"}).Return(refund, nil)
+	s.mocking.Mock.On("CreateRefund", actorId, "renter", "5c6d7e8f-9a0b-4c1d-8e2f-3a4b5c6d7e8f", dto.RefundDTO{Amount: 90000}).Return((*model.Refund)(nil), fmt.Errorf("%w: 50000 left to refund", pkg.ErrRefundExceedsCaptured))
+	s.mocking.Mock.On("CreateRefund", actorId, "renter", "6d7e8f9a-0b1c-4d2e-9f3a-4b5c6d7e8f9a", dto.RefundDTO{Amount: 25000}).Return((*model.Refund)(nil), pkg.ErrPaymentNotRefundable)
+	s.mocking.Mock.On("CreateRefund", actorId, "renter", "7e8f9a0b-1c2d-4e3f-8a4b-5c6d7e8f9a0b", dto.RefundDTO{Amount: 25000}).Return((*model.Refund)(nil), pkg.ErrForbidden)
+	s.mocking.Mock.On("CreateRefund", actorId, "renter", "8f9a0b1c-2d3e-4f4a-9b5c-6d7e8f9a0b1c", dto.RefundDTO{Amount: 25000}).Return((*model.Refund)(nil), pkg.ErrRecordNotFound)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		PaymentId          string
+		ContentType        string
+		Body               map[string]interface{}
+		ExpectedResult     map[string]interface{}
+	}{
+		{
+			Name:               "success create refund",
+			ExpectedStatusCode: http.StatusCreated,
+			PaymentId:          "5c6d7e8f-9a0b-4c1d-8e2f-3a4b5c6d7e8f",
+			ContentType:        "application/json",
+			Body: map[string]interface{}{
+				"amount": 25000,
+				"reason": "bike was damaged",
+			},
+			ExpectedResult: map[string]interface{}{
+				"status":  "success",
+				"message": "success create refund",
+			},
+		},
+		{
+			Name:               "failed refund exceeds the captured amount",
+			ExpectedStatusCode: http.StatusBadRequest,
+			PaymentId:          "5c6d7e8f-9a0b-4c1d-8e2f-3a4b5c6d7e8f",
+			ContentType:        "application/json",
+			Body: map[string]interface{}{
+				"amount": 90000,
+			},
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "refund exceeds the refundable amount: 50000 left to refund",
+			},
+		},
+		{
+			Name:               "failed payment not settled",
+			ExpectedStatusCode: http.StatusConflict,
+			PaymentId:          "6d7e8f9a-0b1c-4d2e-9f3a-4b5c6d7e8f9a",
+			ContentType:        "application/json",
+			Body: map[string]interface{}{
+				"amount": 25000,
+			},
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "only settled payments can be refunded",
+			},
+		},
+		{
+			Name:               "failed payment of another renter",
+			ExpectedStatusCode: http.StatusForbidden,
+			PaymentId:          "7e8f9a0b-1c2d-4e3f-8a4b-5c6d7e8f9a0b",
+			ContentType:        "application/json",
+			Body: map[string]interface{}{
+				"amount": 25000,
+			},
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "you are not allowed to refund this payment",
+			},
+		},
+		{
+			Name:               "failed payment not found",
+			ExpectedStatusCode: http.StatusNotFound,
+			PaymentId:          "8f9a0b1c-2d3e-4f4a-9b5c-6d7e8f9a0b1c",
+			ContentType:        "application/json",
+			Body: map[string]interface{}{
+				"amount": 25000,
+			},
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "payment not found",
+			},
+		},
+		{
+			Name:               "failed wrong content-type",
+			ExpectedStatusCode: http.StatusBadRequest,
+			PaymentId:          "5c6d7e8f-9a0b-4c1d-8e2f-3a4b5c6d7e8f",
+			ContentType:        "text/plain",
+			Body: map[string]interface{}{
+				"amount": 25000,
+			},
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "fill all required fields",
+			},
+		},
+	}
+
+	for _, v := range testCases {
+		s.T().Run(v.Name, func(t *testing.T) {
+			res, _ := json.Marshal(v.Body)
+			r := httptest.NewRequest("POST", "/payments", bytes.NewReader(res))
+			w := httptest.NewRecorder()
+
+			e := echo.New()
+			ctx := e.NewContext(r, w)
+			ctx.SetPath("/:id/refunds")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(v.PaymentId)
+			ctx.Request().Header.Set("Content-Type", v.ContentType)
+			ctx.Set("user", &jwt.Token{Claims: jwt.MapClaims{"user_id": actorId, "role": "renter"}})
+
+			err := s.handler.HandlerCreateRefund(ctx)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatusCode, w.Result().StatusCode)
+
+			var resp map[string]interface{}
+			err = json.NewDecoder(w.Result().Body).Decode(&resp)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedResult["status"], resp["status"])
+			s.Equal(v.ExpectedResult["message"], resp["message"])
+		})
+	}
+}
+
+func (s *suiteRefunds) TestHandlerCreateRefundAdmin() {
+	adminId := "3e4f5a6b-7c8d-4e9f-8a0b-2c3d4e5f6a7b"
+	paymentId := "4f5a6b7c-8d9e-4f0a-9b1c-3d4e5f6a7b8c"
+
+	refund := &model.Refund{
+		ID:          "5a6b7c8d-9e0f-4a1b-8c2d-4e5f6a7b8c9d",
+		PaymentId:   paymentId,
+		Amount:      30000,
+		Status:      model.RefundStatusPending,
+		RequestedBy: adminId,
+	}
+
+	s.mocking.Mock.On("CreateRefund", adminId, "admin", paymentId, dto.RefundDTO{Amount: 30000, Reason: "goodwill refund"}).Return(refund, nil)
+
+	res, _ := json.Marshal(map[string]interface{}{"amount": 30000, "reason": "goodwill refund"})
+	r := httptest.NewRequest("POST", "/payments", bytes.NewReader(res))
+	w := httptest.NewRecorder()
+
+	e := echo.New()
+	ctx := e.NewContext(r, w)
+	ctx.SetPath("/:id/refunds")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(paymentId)
+	ctx.Request().Header.Set("Content-Type", "application/json")
+	ctx.Set("user", &jwt.Token{Claims: jwt.MapClaims{"user_id": adminId, "role": "admin"}})
+
+	err := s.handler.HandlerCreateRefund(ctx)
+	s.NoError(err)
+
+	s.Equal(http.StatusCreated, w.Result().StatusCode)
+}
+
+func (s *suiteRefunds) TestHandlerFindAllRefunds() {
+	actorId := "9a0b1c2d-3e4f-4a5b-8c6d-7e8f9a0b1c2d"
+
+	refunds := &[]model.Refund{
+		{
+			ID:        "0b1c2d3e-4f5a-4b6c-9d7e-8f9a0b1c2d3e",
+			PaymentId: "1c2d3e4f-5a6b-4c7d-8e8f-9a0b1c2d3e4f",
+			Amount:    25000,
+			Status:    model.RefundStatusSucceeded,
+		},
+	}
+
+	s.mocking.Mock.On("FindAllRefunds", actorId, "renter", "1c2d3e4f-5a6b-4c7d-8e8f-9a0b1c2d3e4f").Return(refunds, nil)
+	s.mocking.Mock.On("FindAllRefunds", actorId, "renter", "2d3e4f5a-6b7c-4d8e-9f9a-0b1c2d3e4f5a").Return((*[]model.Refund)(nil), pkg.ErrForbidden)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		PaymentId          string
+		ExpectedMessage    string
+	}{
+		{
+			Name:               "success get all refunds",
+			ExpectedStatusCode: http.StatusOK,
+			PaymentId:          "1c2d3e4f-5a6b-4c7d-8e8f-9a0b1c2d3e4f",
+			ExpectedMessage:    "success get all refunds",
+		},
+		{
+			Name:               "failed payment of another renter",
+			ExpectedStatusCode: http.StatusForbidden,
+			PaymentId:          "2d3e4f5a-6b7c-4d8e-9f9a-0b1c2d3e4f5a",
+			ExpectedMessage:    "you are not allowed to see refunds of this payment",
+		},
+	}
+
+	for _, v := range testCases {
+		s.T().Run(v.Name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/payments", nil)
+			w := httptest.NewRecorder()
+
+			e := echo.New()
+			ctx := e.NewContext(r, w)
+			ctx.SetPath("/:id/refunds")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(v.PaymentId)
+			ctx.Set("user", &jwt.Token{Claims: jwt.MapClaims{"user_id": actorId, "role": "renter"}})
+
+			err := s.handler.HandlerFindAllRefunds(ctx)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatusCode, w.Result().StatusCode)
+
+			var resp map[string]interface{}
+			err = json.NewDecoder(w.Result().Body).Decode(&resp)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedMessage, resp["message"])
+		})
+	}
+}
+
+func TestSuiteRefunds(t *testing.T) {
+	suite.Run(t, new(suiteRefunds))
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/arvinpaundra/go-rent-bike/pkg"
 	"net/http"
 
+	"github.com/arvinpaundra/go-rent-bike/helper"
 	"github.com/arvinpaundra/go-rent-bike/internal/dto"
 	"github.com/arvinpaundra/go-rent-bike/internal/model"
 	"github.com/arvinpaundra/go-rent-bike/internal/usecase"
@@ -30,9 +31,26 @@ func (r RenterController) HandlerCreateRenter(c echo.Context) error {
 		})
 	}
 
+	// users only register themselves as a renter
+	if renterDTO.UserId != helper.ExtractTokenClaims(c)["user_id"] {
+		return c.JSON(http.StatusForbidden, map[string]interface{}{
+			"status":  "error",
+			"message": "you can only register yourself as a renter",
+			"data":    nil,
+		})
+	}
+
 	err := r.renterUsecase.CreateRenter(renterDTO)
 
 	if err != nil {
+		if errors.Is(err, pkg.ErrInvalidCancellationPolicy) {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
 		if errors.Is(err, pkg.ErrRecordNotFound) {
 			return c.JSON(http.StatusNotFound, map[string]interface{}{
 				"status":  "error",
@@ -174,6 +192,110 @@ func (r RenterController) HandlerFindAllRenterReports(c echo.Context) error {
 	})
 }
 
+func (r RenterController) HandlerCreatePricingRule(c echo.Context) error {
+	renterId := c.Param("id")
+	pricingRuleDTO := dto.PricingRuleDTO{}
+
+	if err := c.Bind(&pricingRuleDTO); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status":  "error",
+			"message": "fill all required fields",
+			"data":    nil,
+		})
+	}
+
+	err := r.renterUsecase.CreatePricingRule(renterId, pricingRuleDTO)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "renter not found",
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrInvalidPricing) {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"status":  "success",
+		"message": "success create pricing rule",
+		"data":    nil,
+	})
+}
+
+func (r RenterController) HandlerFindAllPricingRules(c echo.Context) error {
+	renterId := c.Param("id")
+
+	pricingRules, err := r.renterUsecase.FindAllPricingRules(renterId)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "renter not found",
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success get all pricing rules",
+		"data": map[string]*[]model.PricingRule{
+			"pricing_rules": pricingRules,
+		},
+	})
+}
+
+func (r RenterController) HandlerDeletePricingRule(c echo.Context) error {
+	renterId := c.Param("id")
+	pricingRuleId := c.Param("ruleId")
+
+	err := r.renterUsecase.DeletePricingRule(renterId, pricingRuleId)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "pricing rule not found",
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success delete pricing rule",
+		"data":    nil,
+	})
+}
+
 func (r RenterController) HandlerUpdateRenter(c echo.Context) error {
 	renterId := c.Param("id")
 	renterDTO := dto.RenterDTO{}
@@ -189,6 +311,14 @@ func (r RenterController) HandlerUpdateRenter(c echo.Context) error {
 	err := r.renterUsecase.UpdateRenter(renterId, renterDTO)
 
 	if err != nil {
+		if errors.Is(err, pkg.ErrInvalidCancellationPolicy) {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
 		if errors.Is(err, pkg.ErrRecordNotFound) {
 			return c.JSON(http.StatusNotFound, map[string]interface{}{
 				"status":  "error",
@@ -238,3 +368,144 @@ func (r RenterController) HandlerDeleteRenter(c echo.Context) error {
 		"data":    nil,
 	})
 }
+
+func (r RenterController) HandlerSuspendRenter(c echo.Context) error {
+	renterId := c.Param("id")
+	suspensionDTO := dto.RenterSuspensionDTO{}
+
+	if err := c.Bind(&suspensionDTO); err != nil || suspensionDTO.Reason == "" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status":  "error",
+			"message": "reason is required",
+			"data":    nil,
+		})
+	}
+
+	err := r.renterUsecase.SuspendRenter(renterId, suspensionDTO)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "renter not found",
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success suspend renter",
+		"data":    nil,
+	})
+}
+
+func (r RenterController) HandlerUnsuspendRenter(c echo.Context) error {
+	renterId := c.Param("id")
+
+	err := r.renterUsecase.UnsuspendRenter(renterId)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "renter not found",
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success unsuspend renter",
+		"data":    nil,
+	})
+}
+
+func (r RenterController) HandlerFindAllReports(c echo.Context) error {
+	status := c.QueryParam("status")
+
+	reports, err := r.renterUsecase.FindAllReports(status)
+
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success get all reports",
+		"data": map[string]*[]model.Report{
+			"reports": reports,
+		},
+	})
+}
+
+func (r RenterController) HandlerResolveReport(c echo.Context) error {
+	reportId := c.Param("id")
+	actorId := helper.ExtractTokenClaims(c)["user_id"]
+	resolutionDTO := dto.ReportResolutionDTO{}
+
+	if err := c.Bind(&resolutionDTO); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status":  "error",
+			"message": "fill all required fields",
+			"data":    nil,
+		})
+	}
+
+	err := r.renterUsecase.ResolveReport(reportId, actorId, resolutionDTO)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "report not found",
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrInvalidReportResolution) {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"status":  "error",
+				"message": "status must be resolved or dismissed",
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrReportAlreadyResolved) {
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success resolve report",
+		"data":    nil,
+	})
+}
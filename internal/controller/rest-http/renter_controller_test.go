@@ -11,6 +11,8 @@ import (
 	"github.com/arvinpaundra/go-rent-bike/internal/dto"
 	"github.com/arvinpaundra/go-rent-bike/internal/model"
 	usecasemock "github.com/arvinpaundra/go-rent-bike/internal/usecase/mock"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/golang-jwt/jwt"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/suite"
 )
@@ -31,7 +33,59 @@ func (s *suiteRenter) SetupSuite() {
 }
 
 func (s *suiteRenter) TestHandlerCreateRenter() {
+	userId := "6e8a0c2d-4f1b-4a3c-9e5d-7f9b1d3e5a6c"
 
+	renterDTO := dto.RenterDTO{
+		UserId:      userId,
+		RentName:    "Twins' Brother Bike Rental",
+		RentAddress: "Jl Morioh",
+	}
+
+	s.mocking.Mock.On("CreateRenter", renterDTO).Return(nil)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Body               map[string]interface{}
+		ExpectedMessage    string
+	}{
+		{
+			Name:               "success register renter",
+			ExpectedStatusCode: http.StatusCreated,
+			Body:               map[string]interface{}{"user_id": userId, "rent_name": "Twins' Brother Bike Rental", "rent_address": "Jl Morioh"},
+			ExpectedMessage:    "register success",
+		},
+		{
+			Name:               "failed register another user",
+			ExpectedStatusCode: http.StatusForbidden,
+			Body:               map[string]interface{}{"user_id": "8a0c2e4f-6b3d-4c5e-8f7a-9b1d3f5a7c8e", "rent_name": "Twins' Brother Bike Rental", "rent_address": "Jl Morioh"},
+			ExpectedMessage:    "you can only register yourself as a renter",
+		},
+	}
+
+	for _, v := range testCases {
+		s.T().Run(v.Name, func(t *testing.T) {
+			res, _ := json.Marshal(v.Body)
+			r := httptest.NewRequest("POST", "/renters", bytes.NewReader(res))
+			w := httptest.NewRecorder()
+
+			e := echo.New()
+			ctx := e.NewContext(r, w)
+			ctx.Request().Header.Set("Content-Type", "application/json")
+			ctx.Set("user", &jwt.Token{Claims: jwt.MapClaims{"user_id": userId, "role": "customer"}})
+
+			err := s.handler.HandlerCreateRenter(ctx)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatusCode, w.Result().StatusCode)
+
+			var resp map[string]interface{}
+			err = json.NewDecoder(w.Result().Body).Decode(&resp)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedMessage, resp["message"])
+		})
+	}
 }
 
 func (s *suiteRenter) TestHandlerCreateReportRenter() {
@@ -327,6 +381,187 @@ func (s *suiteRenter) TestHandlerFindAllRenterReports() {
 	}
 }
 
+func (s *suiteRenter) TestHandlerCreatePricingRule() {
+	renterId := "f2d85d5b-3e45-4cb4-9853-84c1241b0bf6"
+
+	pricingRuleDTO := dto.PricingRuleDTO{
+		Name:       "high season",
+		StartAt:    time.Date(2022, 12, 20, 0, 0, 0, 0, time.UTC),
+		EndAt:      time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC),
+		Multiplier: 1.5,
+	}
+
+	invalidPricingRuleDTO := dto.PricingRuleDTO{
+		Name:       "high season",
+		StartAt:    time.Date(2022, 12, 20, 0, 0, 0, 0, time.UTC),
+		EndAt:      time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC),
+		Multiplier: 0,
+	}
+
+	s.mocking.Mock.On("CreatePricingRule", renterId, pricingRuleDTO).Return(nil)
+	s.mocking.Mock.On("CreatePricingRule", renterId, invalidPricingRuleDTO).Return(pkg.ErrInvalidPricing)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Method             string
+		Header             map[string]string
+		Body               map[string]interface{}
+		HasReturnBody      bool
+		ExpectedResult     map[string]interface{}
+	}{
+		{
+			Name:               "success create pricing rule",
+			ExpectedStatusCode: http.StatusCreated,
+			Method:             "POST",
+			Header: map[string]string{
+				"Content-Type": "application/json",
+			},
+			Body: map[string]interface{}{
+				"name":       "high season",
+				"start_at":   "2022-12-20T00:00:00Z",
+				"end_at":     "2023-01-03T00:00:00Z",
+				"multiplier": 1.5,
+			},
+			HasReturnBody: true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "success",
+				"message": "success create pricing rule",
+			},
+		},
+		{
+			Name:               "failed invalid pricing rule",
+			ExpectedStatusCode: http.StatusBadRequest,
+			Method:             "POST",
+			Header: map[string]string{
+				"Content-Type": "application/json",
+			},
+			Body: map[string]interface{}{
+				"name":       "high season",
+				"start_at":   "2022-12-20T00:00:00Z",
+				"end_at":     "2023-01-03T00:00:00Z",
+				"multiplier": 0,
+			},
+			HasReturnBody: true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "invalid pricing",
+			},
+		},
+		{
+			Name:               "failed wrong content-type",
+			ExpectedStatusCode: http.StatusBadRequest,
+			Method:             "POST",
+			Header: map[string]string{
+				"Content-Type": "text/plain",
+			},
+			Body: map[string]interface{}{
+				"name": "high season",
+			},
+			HasReturnBody: true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "fill all required fields",
+			},
+		},
+	}
+
+	for _, v := range testCases {
+		s.T().Run(v.Name, func(t *testing.T) {
+			res, _ := json.Marshal(v.Body)
+			r := httptest.NewRequest(v.Method, "/renters", bytes.NewReader(res))
+			w := httptest.NewRecorder()
+
+			e := echo.New()
+			ctx := e.NewContext(r, w)
+			ctx.Request().Header.Set("Content-Type", v.Header["Content-Type"])
+			ctx.SetPath("/:id/pricing-rules")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(renterId)
+
+			err := s.handler.HandlerCreatePricingRule(ctx)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatusCode, w.Result().StatusCode)
+
+			if v.HasReturnBody {
+				var resp map[string]interface{}
+				err := json.NewDecoder(w.Result().Body).Decode(&resp)
+				s.NoError(err)
+
+				s.Equal(v.ExpectedResult["status"], resp["status"])
+				s.Equal(v.ExpectedResult["message"], resp["message"])
+			}
+		})
+	}
+}
+
+func (s *suiteRenter) TestHandlerDeletePricingRule() {
+	renterId := "03e96e6c-4f56-4dc5-8964-95d2352c1c07"
+
+	s.mocking.Mock.On("DeletePricingRule", renterId, "14fa7f7d-5067-4ed6-9a75-a6e3463d2d18").Return(nil)
+	s.mocking.Mock.On("DeletePricingRule", renterId, "250b808e-6178-4fe7-8b86-b7f4574e3e29").Return(pkg.ErrRecordNotFound)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Method             string
+		PricingRuleId      string
+		HasReturnBody      bool
+		ExpectedResult     map[string]interface{}
+	}{
+		{
+			Name:               "success delete pricing rule",
+			ExpectedStatusCode: http.StatusOK,
+			Method:             "DELETE",
+			PricingRuleId:      "14fa7f7d-5067-4ed6-9a75-a6e3463d2d18",
+			HasReturnBody:      true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "success",
+				"message": "success delete pricing rule",
+			},
+		},
+		{
+			Name:               "failed pricing rule not found",
+			ExpectedStatusCode: http.StatusNotFound,
+			Method:             "DELETE",
+			PricingRuleId:      "250b808e-6178-4fe7-8b86-b7f4574e3e29",
+			HasReturnBody:      true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "pricing rule not found",
+			},
+		},
+	}
+
+	for _, v := range testCases {
+		s.T().Run(v.Name, func(t *testing.T) {
+			r := httptest.NewRequest(v.Method, "/renters", nil)
+			w := httptest.NewRecorder()
+
+			e := echo.New()
+			ctx := e.NewContext(r, w)
+			ctx.SetPath("/:id/pricing-rules/:ruleId")
+			ctx.SetParamNames("id", "ruleId")
+			ctx.SetParamValues(renterId, v.PricingRuleId)
+
+			err := s.handler.HandlerDeletePricingRule(ctx)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatusCode, w.Result().StatusCode)
+
+			if v.HasReturnBody {
+				var resp map[string]interface{}
+				err := json.NewDecoder(w.Result().Body).Decode(&resp)
+				s.NoError(err)
+
+				s.Equal(v.ExpectedResult["status"], resp["status"])
+				s.Equal(v.ExpectedResult["message"], resp["message"])
+			}
+		})
+	}
+}
+
 func (s *suiteRenter) TestHandlerUpdateRenter() {
 	renterId := "e1c74c4a-2d34-4ba3-8742-73b0130afae5"
 	renterDTO := dto.RenterDTO{
@@ -469,6 +704,189 @@ func (s *suiteRenter) TestHandlerDeleteRenter() {
 	}
 }
 
+func (s *suiteRenter) TestHandlerSuspendRenter() {
+	suspensionDTO := dto.RenterSuspensionDTO{Reason: "fake bikes listed"}
+
+	s.mocking.Mock.On("SuspendRenter", "0a2c4e6f-8b1d-4f3a-9c5e-7a9b1d3f5e70", suspensionDTO).Return(nil)
+	s.mocking.Mock.On("SuspendRenter", "2c4e6f8b-1d3f-4a5c-8e7a-9b1d3f5e7a92", suspensionDTO).Return(pkg.ErrRecordNotFound)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		RenterId           string
+		Body               map[string]interface{}
+		ExpectedMessage    string
+	}{
+		{
+			Name:               "success suspend renter",
+			ExpectedStatusCode: http.StatusOK,
+			RenterId:           "0a2c4e6f-8b1d-4f3a-9c5e-7a9b1d3f5e70",
+			Body:               map[string]interface{}{"reason": "fake bikes listed"},
+			ExpectedMessage:    "success suspend renter",
+		},
+		{
+			Name:               "renter not found",
+			ExpectedStatusCode: http.StatusNotFound,
+			RenterId:           "2c4e6f8b-1d3f-4a5c-8e7a-9b1d3f5e7a92",
+			Body:               map[string]interface{}{"reason": "fake bikes listed"},
+			ExpectedMessage:    "renter not found",
+		},
+		{
+			Name:               "failed without reason",
+			ExpectedStatusCode: http.StatusBadRequest,
+			RenterId:           "0a2c4e6f-8b1d-4f3a-9c5e-7a9b1d3f5e70",
+			Body:               map[string]interface{}{},
+			ExpectedMessage:    "reason is required",
+		},
+	}
+
+	for _, v := range testCases {
+		s.T().Run(v.Name, func(t *testing.T) {
+			res, _ := json.Marshal(v.Body)
+			r := httptest.NewRequest(http.MethodPut, "/admin/renters", bytes.NewReader(res))
+			w := httptest.NewRecorder()
+
+			e := echo.New()
+			ctx := e.NewContext(r, w)
+			ctx.Request().Header.Set("Content-Type", "application/json")
+			ctx.SetPath("/:id/suspension")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(v.RenterId)
+
+			err := s.handler.HandlerSuspendRenter(ctx)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatusCode, w.Result().StatusCode)
+
+			var resp map[string]interface{}
+			err = json.NewDecoder(w.Result().Body).Decode(&resp)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedMessage, resp["message"])
+		})
+	}
+}
+
+func (s *suiteRenter) TestHandlerUnsuspendRenter() {
+	renterId := "4e6f8b1d-3f5a-4c7e-9a9b-1d3f5e7a9c14"
+
+	s.mocking.Mock.On("UnsuspendRenter", renterId).Return(nil)
+
+	r := httptest.NewRequest(http.MethodDelete, "/admin/renters", nil)
+	w := httptest.NewRecorder()
+
+	e := echo.New()
+	ctx := e.NewContext(r, w)
+	ctx.SetPath("/:id/suspension")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(renterId)
+
+	err := s.handler.HandlerUnsuspendRenter(ctx)
+	s.NoError(err)
+
+	s.Equal(http.StatusOK, w.Result().StatusCode)
+}
+
+func (s *suiteRenter) TestHandlerFindAllReports() {
+	reports := &[]model.Report{
+		{ID: "6f8b1d3f-5a7c-4e9a-8b1d-3f5e7a9c1e36", Status: model.ReportStatusOpen},
+	}
+
+	s.mocking.Mock.On("FindAllReports", "open").Return(reports, nil)
+
+	r := httptest.NewRequest(http.MethodGet, "/admin/reports?status=open", nil)
+	w := httptest.NewRecorder()
+
+	e := echo.New()
+	ctx := e.NewContext(r, w)
+
+	err := s.handler.HandlerFindAllReports(ctx)
+	s.NoError(err)
+
+	s.Equal(http.StatusOK, w.Result().StatusCode)
+
+	var resp map[string]interface{}
+	err = json.NewDecoder(w.Result().Body).Decode(&resp)
+	s.NoError(err)
+
+	s.Equal("success get all reports", resp["message"])
+	s.Len(resp["data"].(map[string]interface{})["reports"], 1)
+}
+
+func (s *suiteRenter) TestHandlerResolveReport() {
+	adminId := "8b1d3f5e-7a9c-4e1a-9d3f-5e7a9c1e3b58"
+	resolutionDTO := dto.ReportResolutionDTO{Status: "resolved", Resolution: "renter warned"}
+
+	s.mocking.Mock.On("ResolveReport", "1d3f5e7a-9c1e-4a3b-8f5e-7a9c1e3b5d70", adminId, resolutionDTO).Return(nil)
+	s.mocking.Mock.On("ResolveReport", "3f5e7a9c-1e3b-4d5f-9e7a-9c1e3b5d7f92", adminId, resolutionDTO).Return(pkg.ErrReportAlreadyResolved)
+	s.mocking.Mock.On("ResolveReport", "5e7a9c1e-3b5d-4f7a-8a9c-1e3b5d7f9a14", adminId, resolutionDTO).Return(pkg.ErrRecordNotFound)
+	s.mocking.Mock.On("ResolveReport", "1d3f5e7a-9c1e-4a3b-8f5e-7a9c1e3b5d70", adminId, dto.ReportResolutionDTO{Status: "open"}).Return(pkg.ErrInvalidReportResolution)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		ReportId           string
+		Body               map[string]interface{}
+		ExpectedMessage    string
+	}{
+		{
+			Name:               "success resolve report",
+			ExpectedStatusCode: http.StatusOK,
+			ReportId:           "1d3f5e7a-9c1e-4a3b-8f5e-7a9c1e3b5d70",
+			Body:               map[string]interface{}{"status": "resolved", "resolution": "renter warned"},
+			ExpectedMessage:    "success resolve report",
+		},
+		{
+			Name:               "failed report already resolved",
+			ExpectedStatusCode: http.StatusConflict,
+			ReportId:           "3f5e7a9c-1e3b-4d5f-9e7a-9c1e3b5d7f92",
+			Body:               map[string]interface{}{"status": "resolved", "resolution": "renter warned"},
+			ExpectedMessage:    "report already resolved",
+		},
+		{
+			Name:               "report not found",
+			ExpectedStatusCode: http.StatusNotFound,
+			ReportId:           "5e7a9c1e-3b5d-4f7a-8a9c-1e3b5d7f9a14",
+			Body:               map[string]interface{}{"status": "resolved", "resolution": "renter warned"},
+			ExpectedMessage:    "report not found",
+		},
+		{
+			Name:               "failed invalid status",
+			ExpectedStatusCode: http.StatusBadRequest,
+			ReportId:           "1d3f5e7a-9c1e-4a3b-8f5e-7a9c1e3b5d70",
+			Body:               map[string]interface{}{"status": "open"},
+			ExpectedMessage:    "status must be resolved or dismissed",
+		},
+	}
+
+	for _, v := range testCases {
+		s.T().Run(v.Name, func(t *testing.T) {
+			res, _ := json.Marshal(v.Body)
+			r := httptest.NewRequest(http.MethodPut, "/admin/reports", bytes.NewReader(res))
+			w := httptest.NewRecorder()
+
+			e := echo.New()
+			ctx := e.NewContext(r, w)
+			ctx.Request().Header.Set("Content-Type", "application/json")
+			ctx.SetPath("/:id")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(v.ReportId)
+			ctx.Set("user", &jwt.Token{Claims: jwt.MapClaims{"user_id": adminId, "role": "admin"}})
+
+			err := s.handler.HandlerResolveReport(ctx)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatusCode, w.Result().StatusCode)
+
+			var resp map[string]interface{}
+			err = json.NewDecoder(w.Result().Body).Decode(&resp)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedMessage, resp["message"])
+		})
+	}
+}
+
 func (s *suiteRenter) TearDownSuite() {
 	s.mocking = nil
 }
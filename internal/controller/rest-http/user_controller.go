@@ -30,7 +30,7 @@ func (h *UserController) HandlerRegister(c echo.Context) error {
 		})
 	}
 
-	if userDTO.Role != "customer" && userDTO.Role != "renter" {
+	if userDTO.Role != model.RoleCustomer && userDTO.Role != model.RoleRenter {
 		return c.JSON(http.StatusBadRequest, map[string]interface{}{
 			"status":  "error",
 			"message": "only allowed roles renter and customer",
@@ -49,41 +49,11 @@ func (h *UserController) HandlerRegister(c echo.Context) error {
 			})
 		}
 
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"status":  "error",
-			"message": err.Error(),
-			"data":    nil,
-		})
-	}
-
-	return c.JSON(http.StatusCreated, map[string]interface{}{
-		"status":  "success",
-		"message": "register success",
-		"data":    nil,
-	})
-}
-
-func (h *UserController) HandlerLogin(c echo.Context) error {
-	loginDTO := struct {
-		Email    string `json:"email" form:"email"`
-		Password string `json:"password" form:"password"`
-	}{}
-
-	if err := c.Bind(&loginDTO); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"status":  "error",
-			"message": "email and password is required",
-			"data":    nil,
-		})
-	}
-
-	token, err := h.userUsecase.LoginUser(loginDTO.Email, loginDTO.Password)
-
-	if err != nil {
-		if errors.Is(err, pkg.ErrRecordNotFound) {
-			return c.JSON(http.StatusConflict, map[string]interface{}{
-				"status":  "error",
-				"message": "wrong email or password",
+		// the account exists, another verification email can be asked for after logging in
+		if errors.Is(err, pkg.ErrMailNotSent) {
+			return c.JSON(http.StatusCreated, map[string]interface{}{
+				"status":  "success",
+				"message": "register success, but the verification email could not be sent, log in to send it again",
 				"data":    nil,
 			})
 		}
@@ -95,12 +65,10 @@ func (h *UserController) HandlerLogin(c echo.Context) error {
 		})
 	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
+	return c.JSON(http.StatusCreated, map[string]interface{}{
 		"status":  "success",
-		"message": "login success",
-		"data": map[string]string{
-			"token": token,
-		},
+		"message": "register success, check your email to verify it",
+		"data":    nil,
 	})
 }
 
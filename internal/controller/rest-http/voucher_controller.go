@@ -0,0 +1,397 @@
+package rest_http
+
+import (
+	"errors"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"net/http"
+
+	"github.com/arvinpaundra/go-rent-bike/helper"
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+type VoucherController struct {
+	voucherUsecase usecase.VoucherUsecase
+}
+
+func NewVoucherController(voucherUsecase usecase.VoucherUsecase) *VoucherController {
+	return &VoucherController{voucherUsecase}
+}
+
+func (h *VoucherController) HandlerCreateVoucher(c echo.Context) error {
+	actorId := helper.ExtractTokenClaims(c)["user_id"]
+	voucherDTO := dto.VoucherDTO{}
+
+	if err := c.Bind(&voucherDTO); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status":  "error",
+			"message": "fill all required fields",
+			"data":    nil,
+		})
+	}
+
+	voucher, err := h.voucherUsecase.CreateVoucher(actorId, voucherDTO)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "renter not found",
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrInvalidVoucher) {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrDataAlreadyExist) {
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"status":  "error",
+				"message": "voucher code already exist",
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"status":  "success",
+		"message": "success create voucher",
+		"data": map[string]*model.Voucher{
+			"voucher": voucher,
+		},
+	})
+}
+
+func (h *VoucherController) HandlerFindAllVouchers(c echo.Context) error {
+	actorId := helper.ExtractTokenClaims(c)["user_id"]
+
+	vouchers, err := h.voucherUsecase.FindAllVouchers(actorId)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "renter not found",
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success get all vouchers",
+		"data": map[string]*[]model.Voucher{
+			"vouchers": vouchers,
+		},
+	})
+}
+
+func (h *VoucherController) HandlerFindVoucherById(c echo.Context) error {
+	actorId := helper.ExtractTokenClaims(c)["user_id"]
+	voucherId := c.Param("id")
+
+	voucher, err := h.voucherUsecase.FindByIdVoucher(actorId, voucherId)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "voucher not found",
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success get voucher by id",
+		"data": map[string]*model.Voucher{
+			"voucher": voucher,
+		},
+	})
+}
+
+func (h *VoucherController) HandlerUpdateVoucher(c echo.Context) error {
+	actorId := helper.ExtractTokenClaims(c)["user_id"]
+	voucherId := c.Param("id")
+	voucherDTO := dto.VoucherDTO{}
+
+	if err := c.Bind(&voucherDTO); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status":  "error",
+			"message": "fill all required fields",
+			"data":    nil,
+		})
+	}
+
+	err := h.voucherUsecase.UpdateVoucher(actorId, voucherId, voucherDTO)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "voucher not found",
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrInvalidVoucher) {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrDataAlreadyExist) {
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"status":  "error",
+				"message": "voucher code already exist",
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success update voucher",
+		"data":    nil,
+	})
+}
+
+func (h *VoucherController) HandlerDeleteVoucher(c echo.Context) error {
+	actorId := helper.ExtractTokenClaims(c)["user_id"]
+	voucherId := c.Param("id")
+
+	err := h.voucherUsecase.DeleteVoucher(actorId, voucherId)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "voucher not found",
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success delete voucher",
+		"data":    nil,
+	})
+}
+
+func (h *VoucherController) HandlerCreatePlatformVoucher(c echo.Context) error {
+	voucherDTO := dto.VoucherDTO{}
+
+	if err := c.Bind(&voucherDTO); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status":  "error",
+			"message": "fill all required fields",
+			"data":    nil,
+		})
+	}
+
+	voucher, err := h.voucherUsecase.CreatePlatformVoucher(voucherDTO)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrInvalidVoucher) {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrDataAlreadyExist) {
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"status":  "error",
+				"message": "voucher code already exist",
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"status":  "success",
+		"message": "success create voucher",
+		"data": map[string]*model.Voucher{
+			"voucher": voucher,
+		},
+	})
+}
+
+func (h *VoucherController) HandlerFindAllPlatformVouchers(c echo.Context) error {
+	vouchers, err := h.voucherUsecase.FindAllPlatformVouchers()
+
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success get all vouchers",
+		"data": map[string]*[]model.Voucher{
+			"vouchers": vouchers,
+		},
+	})
+}
+
+func (h *VoucherController) HandlerFindPlatformVoucherById(c echo.Context) error {
+	voucherId := c.Param("id")
+
+	voucher, err := h.voucherUsecase.FindByIdPlatformVoucher(voucherId)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "voucher not found",
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success get voucher by id",
+		"data": map[string]*model.Voucher{
+			"voucher": voucher,
+		},
+	})
+}
+
+func (h *VoucherController) HandlerUpdatePlatformVoucher(c echo.Context) error {
+	voucherId := c.Param("id")
+	voucherDTO := dto.VoucherDTO{}
+
+	if err := c.Bind(&voucherDTO); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status":  "error",
+			"message": "fill all required fields",
+			"data":    nil,
+		})
+	}
+
+	err := h.voucherUsecase.UpdatePlatformVoucher(voucherId, voucherDTO)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "voucher not found",
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrInvalidVoucher) {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrDataAlreadyExist) {
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"status":  "error",
+				"message": "voucher code already exist",
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success update voucher",
+		"data":    nil,
+	})
+}
+
+func (h *VoucherController) HandlerDeletePlatformVoucher(c echo.Context) error {
+	voucherId := c.Param("id")
+
+	err := h.voucherUsecase.DeletePlatformVoucher(voucherId)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "voucher not found",
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success delete voucher",
+		"data":    nil,
+	})
+}
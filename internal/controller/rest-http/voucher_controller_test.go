@@ -0,0 +1,447 @@
+package rest_http
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	usecasemock "github.com/arvinpaundra/go-rent-bike/internal/usecase/mock"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type suiteVouchers struct {
+	suite.Suite
+	handler *VoucherController
+	mocking *usecasemock.VoucherUsecaseMock
+}
+
+func (s *suiteVouchers) SetupSuite() {
+	mock := &usecasemock.VoucherUsecaseMock{}
+	s.mocking = mock
+
+	s.handler = &VoucherController{
+		voucherUsecase: s.mocking,
+	}
+}
+
+func (s *suiteVouchers) TestHandlerCreateVoucher() {
+	actorId := "5e6f7a8b-9c0d-4e1f-8a2b-3c4d5e6f7a8b"
+
+	voucherDTO := dto.VoucherDTO{
+		Code:          "NEWYEAR",
+		DiscountType:  "percentage",
+		DiscountValue: 20,
+		MaxDiscount:   50000,
+		StartAt:       time.Date(2022, 12, 25, 0, 0, 0, 0, time.UTC),
+		EndAt:         time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+
+	voucher := &model.Voucher{
+		ID:            "6f7a8b9c-0d1e-4f2a-9b3c-4d5e6f7a8b9c",
+		RenterId:      "7a8b9c0d-1e2f-4a3b-8c4d-5e6f7a8b9c0d",
+		Code:          "NEWYEAR",
+		DiscountType:  "percentage",
+		DiscountValue: 20,
+		MaxDiscount:   50000,
+		StartAt:       voucherDTO.StartAt,
+		EndAt:         voucherDTO.EndAt,
+	}
+
+	takenDTO := voucherDTO
+	takenDTO.Code = "TAKEN"
+
+	invalidDTO := voucherDTO
+	invalidDTO.DiscountValue = 120
+
+	s.mocking.Mock.On("CreateVoucher", actorId, voucherDTO).Return(voucher, nil)
+	s.mocking.Mock.On("CreateVoucher", actorId, takenDTO).Return((*model.Voucher)(nil), pkg.ErrDataAlreadyExist)
+	s.mocking.Mock.On("CreateVoucher", actorId, invalidDTO).Return((*model.Voucher)(nil), pkg.ErrInvalidVoucher)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Method             string
+		Header             map[string]string
+		Body               map[string]interface{}
+		HasReturnBody      bool
+		ExpectedResult     map[string]interface{}
+	}{
+		{
+			Name:               "success create voucher",
+			ExpectedStatusCode: http.StatusCreated,
+			Method:             "POST",
+			Header: map[string]string{
+				"Content-Type": "application/json",
+			},
+			Body: map[string]interface{}{
+				"code":           "NEWYEAR",
+				"discount_type":  "percentage",
+				"discount_value": 20,
+				"max_discount":   50000,
+				"start_at":       "2022-12-25T00:00:00Z",
+				"end_at":         "2023-01-02T00:00:00Z",
+			},
+			HasReturnBody: true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "success",
+				"message": "success create voucher",
+			},
+		},
+		{
+			Name:               "failed code already exist",
+			ExpectedStatusCode: http.StatusConflict,
+			Method:             "POST",
+			Header: map[string]string{
+				"Content-Type": "application/json",
+			},
+			Body: map[string]interface{}{
+				"code":           "TAKEN",
+				"discount_type":  "percentage",
+				"discount_value": 20,
+				"max_discount":   50000,
+				"start_at":       "2022-12-25T00:00:00Z",
+				"end_at":         "2023-01-02T00:00:00Z",
+			},
+			HasReturnBody: true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "voucher code already exist",
+			},
+		},
+		{
+			Name:               "failed invalid voucher",
+			ExpectedStatusCode: http.StatusBadRequest,
+			Method:             "POST",
+			Header: map[string]string{
+				"Content-Type": "application/json",
+			},
+			Body: map[string]interface{}{
+				"code":           "NEWYEAR",
+				"discount_type":  "percentage",
+				"discount_value": 120,
+				"max_discount":   50000,
+				"start_at":       "2022-12-25T00:00:00Z",
+				"end_at":         "2023-01-02T00:00:00Z",
+			},
+			HasReturnBody: true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "invalid voucher",
+			},
+		},
+		{
+			Name:               "failed wrong content-type",
+			ExpectedStatusCode: http.StatusBadRequest,
+			Method:             "POST",
+			Header: map[string]string{
+				"Content-Type": "text/plain",
+			},
+			Body: map[string]interface{}{
+				"code": "NEWYEAR",
+			},
+			HasReturnBody: true,
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "fill all required fields",
+			},
+		},
+	}
+
+	for _, v := range testCases {
+		s.T().Run(v.Name, func(t *testing.T) {
+			res, _ := json.Marshal(v.Body)
+			r := httptest.NewRequest(v.Method, "/vouchers", bytes.NewReader(res))
+			w := httptest.NewRecorder()
+
+			e := echo.New()
+			ctx := e.NewContext(r, w)
+			ctx.Request().Header.Set("Content-Type", v.Header["Content-Type"])
+			ctx.Set("user", &jwt.Token{Claims: jwt.MapClaims{"user_id": actorId, "role": "renter"}})
+
+			err := s.handler.HandlerCreateVoucher(ctx)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatusCode, w.Result().StatusCode)
+
+			if v.HasReturnBody {
+				var resp map[string]interface{}
+				err := json.NewDecoder(w.Result().Body).Decode(&resp)
+				s.NoError(err)
+
+				s.Equal(v.ExpectedResult["status"], resp["status"])
+				s.Equal(v.ExpectedResult["message"], resp["message"])
+			}
+		})
+	}
+}
+
+func (s *suiteVouchers) TestHandlerFindAllVouchers() {
+	actorId := "8b9c0d1e-2f3a-4b4c-9d5e-6f7a8b9c0d1e"
+
+	vouchers := &[]model.Voucher{
+		{
+			ID:   "9c0d1e2f-3a4b-4c5d-8e6f-7a8b9c0d1e2f",
+			Code: "NEWYEAR",
+		},
+	}
+
+	s.mocking.Mock.On("FindAllVouchers", actorId).Return(vouchers, nil)
+
+	r := httptest.NewRequest("GET", "/vouchers", nil)
+	w := httptest.NewRecorder()
+
+	e := echo.New()
+	ctx := e.NewContext(r, w)
+	ctx.Set("user", &jwt.Token{Claims: jwt.MapClaims{"user_id": actorId, "role": "renter"}})
+
+	err := s.handler.HandlerFindAllVouchers(ctx)
+	s.NoError(err)
+
+	s.Equal(http.StatusOK, w.Result().StatusCode)
+
+	var resp map[string]interface{}
+	err = json.NewDecoder(w.Result().Body).Decode(&resp)
+	s.NoError(err)
+
+	s.Equal("success get all vouchers", resp["message"])
+	s.Len(resp["data"].(map[string]interface{})["vouchers"], 1)
+}
+
+func (s *suiteVouchers) TestHandlerFindVoucherById() {
+	actorId := "0d1e2f3a-4b5c-4d6e-9f7a-8b9c0d1e2f3a"
+
+	s.mocking.Mock.On("FindByIdVoucher", actorId, "1e2f3a4b-5c6d-4e7f-8a8b-9c0d1e2f3a4b").Return(&model.Voucher{ID: "1e2f3a4b-5c6d-4e7f-8a8b-9c0d1e2f3a4b", Code: "NEWYEAR"}, nil)
+	s.mocking.Mock.On("FindByIdVoucher", actorId, "2f3a4b5c-6d7e-4f8a-9b9c-0d1e2f3a4b5c").Return((*model.Voucher)(nil), pkg.ErrRecordNotFound)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		VoucherId          string
+		ExpectedMessage    string
+	}{
+		{
+			Name:               "success get voucher",
+			ExpectedStatusCode: http.StatusOK,
+			VoucherId:          "1e2f3a4b-5c6d-4e7f-8a8b-9c0d1e2f3a4b",
+			ExpectedMessage:    "success get voucher by id",
+		},
+		{
+			Name:               "failed voucher of another renter",
+			ExpectedStatusCode: http.StatusNotFound,
+			VoucherId:          "2f3a4b5c-6d7e-4f8a-9b9c-0d1e2f3a4b5c",
+			ExpectedMessage:    "voucher not found",
+		},
+	}
+
+	for _, v := range testCases {
+		s.T().Run(v.Name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/vouchers", nil)
+			w := httptest.NewRecorder()
+
+			e := echo.New()
+			ctx := e.NewContext(r, w)
+			ctx.SetPath("/:id")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(v.VoucherId)
+			ctx.Set("user", &jwt.Token{Claims: jwt.MapClaims{"user_id": actorId, "role": "renter"}})
+
+			err := s.handler.HandlerFindVoucherById(ctx)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatusCode, w.Result().StatusCode)
+
+			var resp map[string]interface{}
+			err = json.NewDecoder(w.Result().Body).Decode(&resp)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedMessage, resp["message"])
+		})
+	}
+}
+
+func (s *suiteVouchers) TestHandlerUpdateVoucher() {
+	actorId := "3a4b5c6d-7e8f-4a9b-8c0d-1e2f3a4b5c6d"
+	voucherId := "4b5c6d7e-8f9a-4b0c-9d1e-2f3a4b5c6d7e"
+
+	voucherDTO := dto.VoucherDTO{
+		Code:          "SUMMER",
+		DiscountType:  "fixed",
+		DiscountValue: 15000,
+		StartAt:       time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC),
+		EndAt:         time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	s.mocking.Mock.On("UpdateVoucher", actorId, voucherId, voucherDTO).Return(nil)
+
+	res, _ := json.Marshal(map[string]interface{}{
+		"code":           "SUMMER",
+		"discount_type":  "fixed",
+		"discount_value": 15000,
+		"start_at":       "2023-06-01T00:00:00Z",
+		"end_at":         "2023-09-01T00:00:00Z",
+	})
+	r := httptest.NewRequest("PUT", "/vouchers", bytes.NewReader(res))
+	w := httptest.NewRecorder()
+
+	e := echo.New()
+	ctx := e.NewContext(r, w)
+	ctx.Request().Header.Set("Content-Type", "application/json")
+	ctx.SetPath("/:id")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(voucherId)
+	ctx.Set("user", &jwt.Token{Claims: jwt.MapClaims{"user_id": actorId, "role": "renter"}})
+
+	err := s.handler.HandlerUpdateVoucher(ctx)
+	s.NoError(err)
+
+	s.Equal(http.StatusOK, w.Result().StatusCode)
+}
+
+func (s *suiteVouchers) TestHandlerDeleteVoucher() {
+	actorId := "5c6d7e8f-9a0b-4c1d-8e2f-3a4b5c6d7e8f"
+
+	s.mocking.Mock.On("DeleteVoucher", actorId, "6d7e8f9a-0b1c-4d2e-9f3a-4b5c6d7e8f9a").Return(nil)
+	s.mocking.Mock.On("DeleteVoucher", actorId, "7e8f9a0b-1c2d-4e3f-8a4b-5c6d7e8f9a0b").Return(pkg.ErrRecordNotFound)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		VoucherId          string
+		ExpectedMessage    string
+	}{
+		{
+			Name:               "success delete voucher",
+			ExpectedStatusCode: http.StatusOK,
+			VoucherId:          "6d7e8f9a-0b1c-4d2e-9f3a-4b5c6d7e8f9a",
+			ExpectedMessage:    "success delete voucher",
+		},
+		{
+			Name:               "failed voucher not found",
+			ExpectedStatusCode: http.StatusNotFound,
+			VoucherId:          "7e8f9a0b-1c2d-4e3f-8a4b-5c6d7e8f9a0b",
+			ExpectedMessage:    "voucher not found",
+		},
+	}
+
+	for _, v := range testCases {
+		s.T().Run(v.Name, func(t *testing.T) {
+			r := httptest.NewRequest("DELETE", "/vouchers", nil)
+			w := httptest.NewRecorder()
+
+			e := echo.New()
+			ctx := e.NewContext(r, w)
+			ctx.SetPath("/:id")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(v.VoucherId)
+			ctx.Set("user", &jwt.Token{Claims: jwt.MapClaims{"user_id": actorId, "role": "renter"}})
+
+			err := s.handler.HandlerDeleteVoucher(ctx)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatusCode, w.Result().StatusCode)
+
+			var resp map[string]interface{}
+			err = json.NewDecoder(w.Result().Body).Decode(&resp)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedMessage, resp["message"])
+		})
+	}
+}
+
+func (s *suiteVouchers) TestHandlerCreatePlatformVoucher() {
+	voucherDTO := dto.VoucherDTO{
+		Code:          "PLATFORM10",
+		DiscountType:  "percentage",
+		DiscountValue: 10,
+		StartAt:       time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndAt:         time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	voucher := &model.Voucher{
+		ID:            "0d1e2f3a-4b5c-4d6e-9f7a-8b9c0d1e2f3b",
+		Code:          "PLATFORM10",
+		DiscountType:  "percentage",
+		DiscountValue: 10,
+		StartAt:       voucherDTO.StartAt,
+		EndAt:         voucherDTO.EndAt,
+	}
+
+	takenDTO := voucherDTO
+	takenDTO.Code = "PLATFORMTAKEN"
+
+	s.mocking.Mock.On("CreatePlatformVoucher", voucherDTO).Return(voucher, nil)
+	s.mocking.Mock.On("CreatePlatformVoucher", takenDTO).Return((*model.Voucher)(nil), pkg.ErrDataAlreadyExist)
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		Code               string
+		ExpectedResult     map[string]interface{}
+	}{
+		{
+			Name:               "success create platform voucher",
+			ExpectedStatusCode: http.StatusCreated,
+			Code:               "PLATFORM10",
+			ExpectedResult: map[string]interface{}{
+				"status":  "success",
+				"message": "success create voucher",
+			},
+		},
+		{
+			Name:               "failed code already exist",
+			ExpectedStatusCode: http.StatusConflict,
+			Code:               "PLATFORMTAKEN",
+			ExpectedResult: map[string]interface{}{
+				"status":  "error",
+				"message": "voucher code already exist",
+			},
+		},
+	}
+
+	for _, v := range testCases {
+		s.T().Run(v.Name, func(t *testing.T) {
+			res, _ := json.Marshal(map[string]interface{}{
+				"code":           v.Code,
+				"discount_type":  "percentage",
+				"discount_value": 10,
+				"start_at":       "2023-01-01T00:00:00Z",
+				"end_at":         "2023-02-01T00:00:00Z",
+			})
+			r := httptest.NewRequest("POST", "/admin/vouchers", bytes.NewReader(res))
+			w := httptest.NewRecorder()
+
+			e := echo.New()
+			ctx := e.NewContext(r, w)
+			ctx.Request().Header.Set("Content-Type", "application/json")
+			ctx.Set("user", &jwt.Token{Claims: jwt.MapClaims{"user_id": "1e2f3a4b-5c6d-4e7f-8a9b-0c1d2e3f4a5c", "role": "admin"}})
+
+			err := s.handler.HandlerCreatePlatformVoucher(ctx)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatusCode, w.Result().StatusCode)
+
+			var resp map[string]interface{}
+			err = json.NewDecoder(w.Result().Body).Decode(&resp)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedResult["status"], resp["status"])
+			s.Equal(v.ExpectedResult["message"], resp["message"])
+		})
+	}
+}
+
+func (s *suiteVouchers) TearDownSuite() {
+	s.mocking = nil
+}
+
+func TestSuiteVouchers(t *testing.T) {
+	suite.Run(t, new(suiteVouchers))
+}
@@ -0,0 +1,118 @@
+package rest_http
+
+import (
+	"errors"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"net/http"
+
+	"github.com/arvinpaundra/go-rent-bike/helper"
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+type WalletController struct {
+	walletUsecase usecase.WalletUsecase
+}
+
+func NewWalletController(walletUsecase usecase.WalletUsecase) *WalletController {
+	return &WalletController{walletUsecase}
+}
+
+func (h *WalletController) HandlerFindWallet(c echo.Context) error {
+	userId := helper.ExtractTokenClaims(c)["user_id"]
+
+	wallet, err := h.walletUsecase.FindWallet(userId)
+
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success get wallet",
+		"data": map[string]*model.Wallet{
+			"wallet": wallet,
+		},
+	})
+}
+
+func (h *WalletController) HandlerFindAllWalletTransactions(c echo.Context) error {
+	userId := helper.ExtractTokenClaims(c)["user_id"]
+
+	transactions, err := h.walletUsecase.FindAllWalletTransactions(userId)
+
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "success get all wallet transactions",
+		"data": map[string]*[]model.WalletTransaction{
+			"transactions": transactions,
+		},
+	})
+}
+
+func (h *WalletController) HandlerTopUpWallet(c echo.Context) error {
+	userId := helper.ExtractTokenClaims(c)["user_id"]
+	topUpDTO := dto.WalletTopUpDTO{}
+
+	if err := c.Bind(&topUpDTO); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"status":  "error",
+			"message": "fill all required fields",
+			"data":    nil,
+		})
+	}
+
+	data, err := h.walletUsecase.TopUpWallet(userId, topUpDTO)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"status":  "error",
+				"message": "user not found",
+				"data":    nil,
+			})
+		}
+
+		if errors.Is(err, pkg.ErrInvalidTopUp) {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		if statusCode := paymentGatewayStatusCode(err); statusCode != 0 {
+			return c.JSON(statusCode, map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+		}
+
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"status":  "success",
+		"message": "success create wallet top up",
+		"data":    data,
+	})
+}
@@ -0,0 +1,144 @@
+package rest_http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	usecasemock "github.com/arvinpaundra/go-rent-bike/internal/usecase/mock"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type suiteWallets struct {
+	suite.Suite
+	handler *WalletController
+	mocking *usecasemock.WalletUsecaseMock
+}
+
+func (s *suiteWallets) SetupSuite() {
+	mock := &usecasemock.WalletUsecaseMock{}
+	s.mocking = mock
+
+	s.handler = &WalletController{
+		walletUsecase: s.mocking,
+	}
+}
+
+func (s *suiteWallets) TestHandlerFindWallet() {
+	userId := "1f2a3b4c-5d6e-4f7a-8b9c-0d1e2f3a4b5c"
+
+	wallet := &model.Wallet{
+		ID:      "2a3b4c5d-6e7f-4a8b-9c0d-1e2f3a4b5c6d",
+		UserId:  userId,
+		Balance: 25000,
+	}
+
+	s.mocking.Mock.On("FindWallet", userId).Return(wallet, nil)
+
+	r := httptest.NewRequest("GET", "/wallet", nil)
+	w := httptest.NewRecorder()
+
+	e := echo.New()
+	ctx := e.NewContext(r, w)
+	ctx.Set("user", &jwt.Token{Claims: jwt.MapClaims{"user_id": userId, "role": "customer"}})
+
+	err := s.handler.HandlerFindWallet(ctx)
+	s.NoError(err)
+
+	s.Equal(http.StatusOK, w.Result().StatusCode)
+
+	var resp map[string]interface{}
+	err = json.NewDecoder(w.Result().Body).Decode(&resp)
+	s.NoError(err)
+
+	s.Equal("success get wallet", resp["message"])
+	s.Equal(float64(25000), resp["data"].(map[string]interface{})["wallet"].(map[string]interface{})["balance"])
+}
+
+func (s *suiteWallets) TestHandlerTopUpWallet() {
+	userId := "3b4c5d6e-7f8a-4b9c-8d0e-2f3a4b5c6d7e"
+
+	data := map[string]interface{}{
+		"wallet_id":      "4c5d6e7f-8a9b-4c0d-9e1f-3a4b5c6d7e8f",
+		"payment_id":     "5d6e7f8a-9b0c-4d1e-8f2a-4b5c6d7e8f9a",
+		"amount":         float32(50000),
+		"payment_status": "pending",
+		"payment_link":   "https://app.sandbox.midtrans.com/snap/v3/redirection/d4e5f6",
+	}
+
+	s.mocking.Mock.On("TopUpWallet", userId, dto.WalletTopUpDTO{Amount: 50000}).Return(data, nil)
+	s.mocking.Mock.On("TopUpWallet", userId, dto.WalletTopUpDTO{Amount: -1}).Return(map[string]interface{}(nil), fmt.Errorf("%w: amount must be a positive whole amount", pkg.ErrInvalidTopUp))
+	s.mocking.Mock.On("TopUpWallet", userId, dto.WalletTopUpDTO{Amount: 75000}).Return(map[string]interface{}(nil), fmt.Errorf("%w: no response in 30s", pkg.ErrGatewayTimeout))
+
+	testCases := []struct {
+		Name               string
+		ExpectedStatusCode int
+		ContentType        string
+		Body               map[string]interface{}
+		ExpectedMessage    string
+	}{
+		{
+			Name:               "success create wallet top up",
+			ExpectedStatusCode: http.StatusCreated,
+			ContentType:        "application/json",
+			Body:               map[string]interface{}{"amount": 50000},
+			ExpectedMessage:    "success create wallet top up",
+		},
+		{
+			Name:               "failed invalid amount",
+			ExpectedStatusCode: http.StatusBadRequest,
+			ContentType:        "application/json",
+			Body:               map[string]interface{}{"amount": -1},
+			ExpectedMessage:    "invalid top up: amount must be a positive whole amount",
+		},
+		{
+			Name:               "failed payment gateway timed out",
+			ExpectedStatusCode: http.StatusGatewayTimeout,
+			ContentType:        "application/json",
+			Body:               map[string]interface{}{"amount": 75000},
+			ExpectedMessage:    "payment gateway timed out: no response in 30s",
+		},
+		{
+			Name:               "failed wrong content-type",
+			ExpectedStatusCode: http.StatusBadRequest,
+			ContentType:        "text/plain",
+			Body:               map[string]interface{}{"amount": 50000},
+			ExpectedMessage:    "fill all required fields",
+		},
+	}
+
+	for _, v := range testCases {
+		s.T().Run(v.Name, func(t *testing.T) {
+			res, _ := json.Marshal(v.Body)
+			r := httptest.NewRequest("POST", "/wallet/top-ups", bytes.NewReader(res))
+			w := httptest.NewRecorder()
+
+			e := echo.New()
+			ctx := e.NewContext(r, w)
+			ctx.Request().Header.Set("Content-Type", v.ContentType)
+			ctx.Set("user", &jwt.Token{Claims: jwt.MapClaims{"user_id": userId, "role": "customer"}})
+
+			err := s.handler.HandlerTopUpWallet(ctx)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatusCode, w.Result().StatusCode)
+
+			var resp map[string]interface{}
+			err = json.NewDecoder(w.Result().Body).Decode(&resp)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedMessage, resp["message"])
+		})
+	}
+}
+
+func TestSuiteWallets(t *testing.T) {
+	suite.Run(t, new(suiteWallets))
+}
@@ -0,0 +1,34 @@
+package dto
+
+import "time"
+
+type LoginDTO struct {
+	Email    string `json:"email" form:"email"`
+	Password string `json:"password" form:"password"`
+}
+
+type RefreshTokenDTO struct {
+	RefreshToken string `json:"refresh_token" form:"refresh_token"`
+}
+
+// AuthTokenDTO is the pair of tokens of a session, the access token expires at ExpiresAt
+// and the refresh token at RefreshExpiresAt.
+type AuthTokenDTO struct {
+	Token            string    `json:"token"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+type ForgotPasswordDTO struct {
+	Email string `json:"email" form:"email"`
+}
+
+type ResetPasswordDTO struct {
+	Token    string `json:"token" form:"token"`
+	Password string `json:"password" form:"password"`
+}
+
+type VerifyEmailDTO struct {
+	Token string `json:"token" form:"token"`
+}
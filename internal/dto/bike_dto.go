@@ -1,11 +1,29 @@
 package dto
 
+import "time"
+
 type BikeDTO struct {
-	RenterId     string  `json:"renter_id" form:"renter_id"`
-	CategoryId   string  `json:"category_id" form:"category_id"`
-	Name         string  `json:"name" form:"name"`
-	PricePerHour float32 `json:"price_per_hour" form:"price_per_hour"`
-	Condition    string  `json:"condition" form:"condition"`
-	Description  string  `json:"description" form:"description"`
-	IsAvailable  string  `json:"is_available" form:"is_available"`
+	RenterId          string  `json:"renter_id" form:"renter_id"`
+	CategoryId        string  `json:"category_id" form:"category_id"`
+	Name              string  `json:"name" form:"name"`
+	PricePerHour      float32 `json:"price_per_hour" form:"price_per_hour"`
+	PricePerDay       float32 `json:"price_per_day" form:"price_per_day"`
+	PricePerWeek      float32 `json:"price_per_week" form:"price_per_week"`
+	WeekendMultiplier float32 `json:"weekend_multiplier" form:"weekend_multiplier"`
+	HolidayMultiplier float32 `json:"holiday_multiplier" form:"holiday_multiplier"`
+	Deposit           float32 `json:"deposit" form:"deposit"`
+	Condition         string  `json:"condition" form:"condition"`
+	Description       string  `json:"description" form:"description"`
+	IsAvailable       string  `json:"is_available" form:"is_available"`
+}
+
+type BookedWindowDTO struct {
+	StartAt time.Time `json:"start_at"`
+	EndAt   time.Time `json:"end_at"`
+}
+
+type BikeAvailabilityDTO struct {
+	BikeId    string            `json:"bike_id"`
+	Available bool              `json:"available"`
+	Conflicts []BookedWindowDTO `json:"conflicts"`
 }
@@ -1,5 +1,6 @@
 package dto
 
 type CategoryDTO struct {
-	Name string `json:"name" form:"name"`
+	Name    string  `json:"name" form:"name"`
+	Deposit float32 `json:"deposit" form:"deposit"`
 }
@@ -1,8 +1,31 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+)
+
 type OrderDTO struct {
-	CustomerId  string   `json:"customer_id" form:"customer_id"`
-	BikeIds     []string `json:"bike_ids" form:"bike_ids"`
-	TotalHour   int      `json:"total_hour" form:"total_hour"`
-	PaymentType string   `json:"payment_type" form:"payment_type"`
+	BikeIds     []string  `json:"bike_ids" form:"bike_ids"`
+	StartAt     time.Time `json:"start_at" form:"start_at"`
+	EndAt       time.Time `json:"end_at" form:"end_at"`
+	PaymentType string    `json:"payment_type" form:"payment_type"`
+	VoucherCode string    `json:"voucher_code" form:"voucher_code"`
+}
+
+// OrderDepositDTO settles the deposit of a returned order, a withheld amount of zero releases all of it.
+type OrderDepositDTO struct {
+	WithheldAmount float32 `json:"withheld_amount" form:"withheld_amount"`
+	Reason         string  `json:"reason" form:"reason"`
+}
+
+// OrderOverrideDTO moves an order to any status, outside of its lifecycle, with the reason an admin did it.
+type OrderOverrideDTO struct {
+	Status model.OrderStatus `json:"status" form:"status"`
+	Reason string            `json:"reason" form:"reason"`
+}
+
+type OrderExtensionDTO struct {
+	EndAt time.Time `json:"end_at" form:"end_at"`
 }
@@ -1,11 +1,34 @@
 package dto
 
-import "github.com/midtrans/midtrans-go"
+import (
+	"time"
+
+	"github.com/midtrans/midtrans-go"
+)
 
 type PaymentGateway struct {
-	Email    string
-	Phone    string
-	OrderId  string
-	GrossAmt int64
-	Items    []midtrans.ItemDetails
+	Email         string
+	Phone         string
+	OrderId       string
+	GrossAmt      int64
+	Items         []midtrans.ItemDetails
+	ExpiryStartAt time.Time
+	ExpiryMinutes int64
+}
+
+// MidtransNotificationDTO is the body of the http notification midtrans sends when a transaction changes.
+type MidtransNotificationDTO struct {
+	TransactionTime   string `json:"transaction_time"`
+	TransactionStatus string `json:"transaction_status"`
+	TransactionId     string `json:"transaction_id"`
+	StatusMessage     string `json:"status_message"`
+	StatusCode        string `json:"status_code"`
+	SignatureKey      string `json:"signature_key"`
+	PaymentType       string `json:"payment_type"`
+	OrderId           string `json:"order_id"`
+	MerchantId        string `json:"merchant_id"`
+	GrossAmount       string `json:"gross_amount"`
+	FraudStatus       string `json:"fraud_status"`
+	Currency          string `json:"currency"`
+	RefundAmount      string `json:"refund_amount"`
 }
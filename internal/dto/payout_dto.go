@@ -0,0 +1,7 @@
+package dto
+
+type PayoutStatusDTO struct {
+	Status        string `json:"status" form:"status"`
+	Reference     string `json:"reference" form:"reference"`
+	FailureReason string `json:"failure_reason" form:"failure_reason"`
+}
@@ -0,0 +1,10 @@
+package dto
+
+import "time"
+
+type PricingRuleDTO struct {
+	Name       string    `json:"name" form:"name"`
+	StartAt    time.Time `json:"start_at" form:"start_at"`
+	EndAt      time.Time `json:"end_at" form:"end_at"`
+	Multiplier float32   `json:"multiplier" form:"multiplier"`
+}
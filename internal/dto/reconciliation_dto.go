@@ -0,0 +1,35 @@
+package dto
+
+import "time"
+
+const (
+	ReconciliationIssueCheckFailed    = "check_failed"
+	ReconciliationIssueAmountMismatch = "amount_mismatch"
+	ReconciliationIssueSyncFailed     = "sync_failed"
+	ReconciliationIssueStatusFixed    = "status_fixed"
+	ReconciliationIssueStatusMismatch = "status_mismatch"
+	ReconciliationIssueRefundOwed     = "refund_owed"
+	ReconciliationIssueRefundResent   = "refund_resent"
+)
+
+// ReconciliationReport lists the payments that did not match the payment gateway in one reconciliation run.
+type ReconciliationReport struct {
+	GeneratedAt   time.Time                   `json:"generated_at"`
+	Checked       int                         `json:"checked"`
+	Fixed         int                         `json:"fixed"`
+	Discrepancies []ReconciliationDiscrepancy `json:"discrepancies"`
+}
+
+type ReconciliationDiscrepancy struct {
+	PaymentId      string   `json:"payment_id"`
+	OrderId        string   `json:"order_id"`
+	GatewayOrderId string   `json:"gateway_order_id"`
+	PaymentKind    string   `json:"payment_kind"`
+	LocalStatus    string   `json:"local_status"`
+	GatewayStatus  string   `json:"gateway_status"`
+	StatusAfter    string   `json:"status_after"`
+	LocalAmount    float32  `json:"local_amount"`
+	GatewayAmount  string   `json:"gateway_amount"`
+	Issues         []string `json:"issues"`
+	Error          string   `json:"error,omitempty"`
+}
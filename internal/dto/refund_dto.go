@@ -0,0 +1,6 @@
+package dto
+
+type RefundDTO struct {
+	Amount float32 `json:"amount" form:"amount"`
+	Reason string  `json:"reason" form:"reason"`
+}
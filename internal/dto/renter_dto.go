@@ -1,8 +1,17 @@
 package dto
 
 type RenterDTO struct {
-	UserId      string `json:"user_id" form:"user_id"`
-	RentName    string `json:"rent_name" form:"rent_name"`
-	RentAddress string `json:"rent_address" form:"rent_address"`
-	Description string `json:"description" form:"description"`
+	UserId                 string  `json:"user_id" form:"user_id"`
+	RentName               string  `json:"rent_name" form:"rent_name"`
+	RentAddress            string  `json:"rent_address" form:"rent_address"`
+	Description            string  `json:"description" form:"description"`
+	FreeCancellationHours  int     `json:"free_cancellation_hours" form:"free_cancellation_hours"`
+	CancellationFeePercent float32 `json:"cancellation_fee_percent" form:"cancellation_fee_percent"`
+	BankName               string  `json:"bank_name" form:"bank_name"`
+	BankAccountNumber      string  `json:"bank_account_number" form:"bank_account_number"`
+	BankAccountName        string  `json:"bank_account_name" form:"bank_account_name"`
+}
+
+type RenterSuspensionDTO struct {
+	Reason string `json:"reason" form:"reason"`
 }
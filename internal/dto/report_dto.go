@@ -5,3 +5,9 @@ type ReportDTO struct {
 	TitleIssue string `json:"title_issue" form:"title_issue"`
 	BodyIssue  string `json:"body_issue" form:"body_issue"`
 }
+
+// ReportResolutionDTO closes a report as resolved or dismissed.
+type ReportResolutionDTO struct {
+	Status     string `json:"status" form:"status"`
+	Resolution string `json:"resolution" form:"resolution"`
+}
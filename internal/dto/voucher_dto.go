@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+type VoucherDTO struct {
+	Code          string    `json:"code" form:"code"`
+	DiscountType  string    `json:"discount_type" form:"discount_type"`
+	DiscountValue float32   `json:"discount_value" form:"discount_value"`
+	MaxDiscount   float32   `json:"max_discount" form:"max_discount"`
+	MinSpend      float32   `json:"min_spend" form:"min_spend"`
+	UsageLimit    int       `json:"usage_limit" form:"usage_limit"`
+	PerUserLimit  int       `json:"per_user_limit" form:"per_user_limit"`
+	StartAt       time.Time `json:"start_at" form:"start_at"`
+	EndAt         time.Time `json:"end_at" form:"end_at"`
+}
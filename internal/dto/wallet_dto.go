@@ -0,0 +1,5 @@
+package dto
+
+type WalletTopUpDTO struct {
+	Amount float32 `json:"amount" form:"amount"`
+}
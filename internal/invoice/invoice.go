@@ -0,0 +1,86 @@
+package invoice
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	TitleInvoice = "INVOICE"
+	TitleReceipt = "RECEIPT"
+)
+
+// Party is the renter issuing a document or the customer billed by it.
+type Party struct {
+	Name    string
+	Address string
+	Email   string
+	Phone   string
+}
+
+// Line is one item of a document. A line without hours, e.g. a discount or a deposit, leaves the hours blank.
+type Line struct {
+	Description string
+	Hours       int
+	Amount      float32
+}
+
+// Tax is a tax included in the total of a document.
+type Tax struct {
+	Name    string
+	Percent float32
+	Amount  float32
+}
+
+// Document is an invoice, or the receipt of its payment, issued by one renter for its part of an order.
+type Document struct {
+	Title       string
+	Number      string
+	IssuedAt    time.Time
+	OrderId     string
+	StartAt     time.Time
+	EndAt       time.Time
+	Seller      Party
+	Customer    Party
+	Lines       []Line
+	Taxes       []Tax
+	Total       float32
+	PaymentType string
+	// PaidAt is only set on a receipt
+	PaidAt *time.Time
+}
+
+// IncludedTax is the part of a tax inclusive amount that is tax, rounded to whole rupiah.
+func IncludedTax(amount float32, percent float32) float32 {
+	if percent <= 0 {
+		return 0
+	}
+
+	return float32(math.Round(float64(amount) * float64(percent) / float64(100+percent)))
+}
+
+// FormatAmount formats an amount of rupiah with dots between the thousands, e.g. Rp 1.250.000.
+func FormatAmount(amount float32) string {
+	value := int64(math.Round(float64(amount)))
+
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+
+	digits := fmt.Sprintf("%d", value)
+
+	var b strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+
+		b.WriteRune(digit)
+	}
+
+	return sign + "Rp " + b.String()
+}
@@ -0,0 +1,239 @@
+package invoice
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	pageWidth    = 595
+	pageHeight   = 842
+	marginLeft   = 50
+	marginRight  = pageWidth - 50
+	marginTop    = pageHeight - 60
+	marginBottom = 80
+
+	fontRegular = "F1"
+	fontBold    = "F2"
+
+	dateLayout     = "02 Jan 2006"
+	dateTimeLayout = "02 Jan 2006 15:04"
+)
+
+var errNoDocuments = errors.New("no documents to render")
+
+// page collects the drawing operators of one pdf page.
+type page struct {
+	content bytes.Buffer
+}
+
+func (p *page) text(font string, size float64, x float64, y float64, s string) {
+	fmt.Fprintf(&p.content, "BT /%s %.0f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, escapeText(s))
+}
+
+// textRight draws s so that it ends at x.
+func (p *page) textRight(font string, size float64, x float64, y float64, s string) {
+	p.text(font, size, x-textWidth(s, size), y, s)
+}
+
+func (p *page) rule(y float64) {
+	fmt.Fprintf(&p.content, "0.5 w %d %.2f m %d %.2f l S\n", marginLeft, y, marginRight, y)
+}
+
+// Render renders the documents to a single pdf, every document starts on a new page.
+// Only the standard Helvetica fonts are used, so nothing has to be embedded.
+func Render(docs []Document) ([]byte, error) {
+	if len(docs) == 0 {
+		return nil, errNoDocuments
+	}
+
+	pages := []*page{}
+	for _, doc := range docs {
+		pages = append(pages, layout(doc)...)
+	}
+
+	return writePDF(pages), nil
+}
+
+// layout draws a document on as many pages as its lines need.
+func layout(doc Document) []*page {
+	pages := []*page{{}}
+	p := pages[0]
+
+	p.text(fontBold, 20, marginLeft, marginTop, doc.Title)
+	p.textRight(fontBold, 11, marginRight, marginTop+4, doc.Number)
+	p.textRight(fontRegular, 9, marginRight, marginTop-10, "Issued "+doc.IssuedAt.Format(dateLayout))
+
+	y := float64(marginTop - 40)
+
+	p.text(fontBold, 10, marginLeft, y, truncate(doc.Seller.Name, 45))
+	p.text(fontBold, 10, 320, y, "Bill to")
+
+	seller := []string{doc.Seller.Address, doc.Seller.Email, doc.Seller.Phone}
+	customer := []string{doc.Customer.Name, doc.Customer.Email, doc.Customer.Phone}
+
+	for i := range seller {
+		y -= 13
+		p.text(fontRegular, 9, marginLeft, y, truncate(seller[i], 50))
+		p.text(fontRegular, 9, 320, y, truncate(customer[i], 45))
+	}
+
+	y -= 26
+	p.text(fontRegular, 9, marginLeft, y, "Order "+doc.OrderId)
+	y -= 13
+	p.text(fontRegular, 9, marginLeft, y, fmt.Sprintf("Rent period %s - %s", doc.StartAt.Format(dateTimeLayout), doc.EndAt.Format(dateTimeLayout)))
+
+	y -= 30
+	p.text(fontBold, 9, marginLeft, y, "Description")
+	p.textRight(fontBold, 9, 400, y, "Hours")
+	p.textRight(fontBold, 9, marginRight, y, "Amount")
+	y -= 6
+	p.rule(y)
+
+	for _, line := range doc.Lines {
+		y -= 16
+
+		if y < marginBottom {
+			p = &page{}
+			pages = append(pages, p)
+			y = marginTop
+		}
+
+		p.text(fontRegular, 9, marginLeft, y, truncate(line.Description, 60))
+
+		if line.Hours > 0 {
+			p.textRight(fontRegular, 9, 400, y, fmt.Sprintf("%d", line.Hours))
+		}
+
+		p.textRight(fontRegular, 9, marginRight, y, FormatAmount(line.Amount))
+	}
+
+	// the totals are kept together, so they move to a new page when they do not fit below the lines
+	if y-float64(40+16*len(doc.Taxes)) < marginBottom {
+		p = &page{}
+		pages = append(pages, p)
+		y = marginTop
+	}
+
+	y -= 8
+	p.rule(y)
+	y -= 16
+	p.text(fontBold, 10, 320, y, "Total")
+	p.textRight(fontBold, 10, marginRight, y, FormatAmount(doc.Total))
+
+	for _, tax := range doc.Taxes {
+		y -= 14
+		p.text(fontRegular, 9, 320, y, fmt.Sprintf("%s %g%% (included)", tax.Name, tax.Percent))
+		p.textRight(fontRegular, 9, marginRight, y, FormatAmount(tax.Amount))
+	}
+
+	if doc.PaidAt != nil {
+		y -= 30
+		p.text(fontBold, 10, marginLeft, y, fmt.Sprintf("PAID %s via %s", doc.PaidAt.Format(dateTimeLayout), strings.ReplaceAll(doc.PaymentType, "_", " ")))
+	}
+
+	return pages
+}
+
+// writePDF writes the catalog, the page tree, the two fonts and every page with its content stream,
+// followed by the cross reference table pointing at each of those objects.
+func writePDF(pages []*page) []byte {
+	var buf bytes.Buffer
+	offsets := []int{}
+
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	// objects 1 to 4 are fixed, every page then takes two objects: the page and its content
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, p := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /%s 3 0 R /%s 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, fontRegular, fontBold, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", p.content.Len(), p.content.String()))
+	}
+
+	xref := buf.Len()
+
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.Bytes()
+}
+
+// escapeText escapes a string for a pdf literal string. Characters outside latin-1 cannot be shown
+// by the standard fonts and are replaced.
+func escapeText(s string) string {
+	var b strings.Builder
+
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 32 && r < 127:
+			b.WriteRune(r)
+		case r >= 160 && r <= 255:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+
+	return b.String()
+}
+
+// glyphWidths are the Helvetica widths, in thousandths of the font size, of the characters amounts are made of.
+var glyphWidths = map[rune]float64{
+	' ': 278,
+	'.': 278,
+	',': 278,
+	'-': 333,
+	'R': 722,
+	'p': 556,
+}
+
+// textWidth measures s in Helvetica, characters without a known width count as wide as a digit.
+func textWidth(s string, size float64) float64 {
+	var width float64
+
+	for _, r := range s {
+		w, ok := glyphWidths[r]
+
+		if !ok {
+			w = 556
+		}
+
+		width += w
+	}
+
+	return width * size / 1000
+}
+
+func truncate(s string, limit int) string {
+	runes := []rune(s)
+
+	if len(runes) <= limit {
+		return s
+	}
+
+	return string(runes[:limit-3]) + "..."
+}
@@ -0,0 +1,111 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sampleDocument(lines int) Document {
+	startAt := time.Date(2022, 11, 20, 8, 0, 0, 0, time.UTC)
+
+	doc := Document{
+		Title:    TitleInvoice,
+		Number:   "INV-5E6F7A8B-00042",
+		IssuedAt: startAt,
+		OrderId:  "6d7e8f9a-0b1c-4d2e-8f3a-4b5c6d7e8f9a",
+		StartAt:  startAt,
+		EndAt:    startAt.Add(5 * time.Hour),
+		Seller:   Party{Name: "Twins' Brother Bike Rental", Address: "Jl. Malioboro (north)"},
+		Customer: Party{Name: "Arvin Paundra", Email: "arvin@mail.com"},
+		Taxes:    []Tax{{Name: "PPN", Percent: 11, Amount: 7432}},
+		Total:    75000,
+	}
+
+	for i := 0; i < lines; i++ {
+		doc.Lines = append(doc.Lines, Line{Description: fmt.Sprintf("Sample BMX Bike %d", i+1), Hours: 5, Amount: 75000})
+	}
+
+	return doc
+}
+
+func TestRender(t *testing.T) {
+	file, err := Render([]Document{sampleDocument(1)})
+
+	assert.Nil(t, err)
+	assert.True(t, bytes.HasPrefix(file, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(file, []byte("%%EOF\n")))
+
+	assert.Contains(t, string(file), "(INVOICE) Tj")
+	assert.Contains(t, string(file), "(INV-5E6F7A8B-00042) Tj")
+	assert.Contains(t, string(file), "(Jl. Malioboro \\(north\\)) Tj")
+	assert.Contains(t, string(file), "(Rp 75.000) Tj")
+	assert.Contains(t, string(file), "(PPN 11% \\(included\\)) Tj")
+	assert.NotContains(t, string(file), "PAID")
+
+	// every entry of the cross reference table points at the start of its object
+	xref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(file)
+	assert.NotNil(t, xref)
+
+	start, _ := strconv.Atoi(string(xref[1]))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(file[start:], -1)
+	assert.Len(t, entries, 6)
+
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		assert.True(t, bytes.HasPrefix(file[offset:], []byte(fmt.Sprintf("%d 0 obj\n", i+1))))
+	}
+}
+
+func TestRender_Receipt(t *testing.T) {
+	doc := sampleDocument(1)
+	paidAt := time.Date(2022, 11, 19, 10, 30, 0, 0, time.UTC)
+
+	doc.Title = TitleReceipt
+	doc.PaidAt = &paidAt
+	doc.PaymentType = "bank_transfer"
+
+	file, err := Render([]Document{doc})
+
+	assert.Nil(t, err)
+	assert.Contains(t, string(file), "(RECEIPT) Tj")
+	assert.Contains(t, string(file), "(PAID 19 Nov 2022 10:30 via bank transfer) Tj")
+}
+
+func TestRender_Pages(t *testing.T) {
+	// the lines of the first document do not fit on one page
+	file, err := Render([]Document{sampleDocument(50), sampleDocument(1)})
+
+	assert.Nil(t, err)
+	assert.Contains(t, string(file), "/Count 3 >>")
+}
+
+func TestRender_NoDocuments(t *testing.T) {
+	file, err := Render(nil)
+
+	assert.Nil(t, file)
+	assert.Equal(t, errNoDocuments, err)
+}
+
+func TestFormatAmount(t *testing.T) {
+	assert.Equal(t, "Rp 0", FormatAmount(0))
+	assert.Equal(t, "Rp 750", FormatAmount(750))
+	assert.Equal(t, "Rp 75.000", FormatAmount(75000))
+	assert.Equal(t, "Rp 1.250.000", FormatAmount(1250000))
+	assert.Equal(t, "-Rp 15.000", FormatAmount(-15000))
+}
+
+func TestIncludedTax(t *testing.T) {
+	assert.Equal(t, float32(7432), IncludedTax(75000, 11))
+	assert.Equal(t, float32(0), IncludedTax(75000, 0))
+}
+
+func TestEscapeText(t *testing.T) {
+	assert.Equal(t, `a \(b\) \\ c`, escapeText(`a (b) \ c`))
+	assert.Equal(t, `caf\351 ?`, escapeText("café 🚲"))
+}
@@ -0,0 +1,20 @@
+package mailer
+
+const (
+	ProviderSMTP   = "smtp"
+	ProviderFile   = "file"
+	ProviderMemory = "memory"
+)
+
+// Message is a plain text email to a single recipient.
+type Message struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Mailer sends the emails of the app, through an smtp server in production. The file and memory
+// mailers keep the emails on disk or in memory, to run the app and its tests without one.
+type Mailer interface {
+	Send(msg Message) error
+}
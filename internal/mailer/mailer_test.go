@@ -0,0 +1,142 @@
+package mailer
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildMessage(t *testing.T) {
+	now := time.Date(2022, 12, 1, 10, 0, 0, 0, time.UTC)
+
+	raw := string(buildMessage("Go Rent Bike <no-reply@mail.com>", Message{
+		To:      "arvin@mail.com",
+		Subject: "Reset your password\r\nBcc: eve@mail.com",
+		Body:    "Hi,\nfollow the link",
+	}, now))
+
+	assert.Contains(t, raw, "From: Go Rent Bike <no-reply@mail.com>\r\n")
+	assert.Contains(t, raw, "To: arvin@mail.com\r\n")
+	assert.Contains(t, raw, "Subject: Reset your password  Bcc: eve@mail.com\r\n")
+	assert.Contains(t, raw, "Date: Thu, 01 Dec 2022 10:00:00 +0000\r\n")
+	assert.True(t, strings.HasSuffix(raw, "\r\n\r\nHi,\r\nfollow the link"))
+}
+
+func TestFileMailer_Send(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mails")
+	m := NewFileMailer(dir, "no-reply@mail.com")
+
+	err := m.Send(Message{To: "arvin@mail.com", Subject: "Verify your email", Body: "follow the link"})
+
+	assert.Nil(t, err)
+
+	files, _ := os.ReadDir(dir)
+
+	assert.Len(t, files, 1)
+	assert.True(t, strings.HasSuffix(files[0].Name(), "-arvin@mail.com.eml"))
+
+	raw, _ := os.ReadFile(filepath.Join(dir, files[0].Name()))
+
+	assert.Contains(t, string(raw), "Subject: Verify your email\r\n")
+	assert.Contains(t, string(raw), "follow the link")
+}
+
+func TestMemoryMailer_Send(t *testing.T) {
+	m := NewMemoryMailer()
+
+	_, ok := m.Last("arvin@mail.com")
+	assert.False(t, ok)
+
+	_ = m.Send(Message{To: "arvin@mail.com", Subject: "first"})
+	_ = m.Send(Message{To: "paundra@mail.com", Subject: "second"})
+	_ = m.Send(Message{To: "arvin@mail.com", Subject: "third"})
+
+	assert.Len(t, m.Messages(), 3)
+
+	last, ok := m.Last("arvin@mail.com")
+
+	assert.True(t, ok)
+	assert.Equal(t, "third", last.Subject)
+}
+
+// TestSMTPMailer_Send runs the mailer against a minimal smtp server that records the envelope and the data.
+func TestSMTPMailer_Send(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	defer ln.Close()
+
+	received := make(chan []string, 1)
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		var lines []string
+		r := bufio.NewReader(conn)
+		reply := func(s string) { _, _ = conn.Write([]byte(s + "\r\n")) }
+
+		reply("220 localhost ready")
+
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				break
+			}
+
+			line = strings.TrimRight(line, "\r\n")
+			lines = append(lines, line)
+
+			switch {
+			case strings.HasPrefix(line, "EHLO"), strings.HasPrefix(line, "HELO"):
+				reply("250 localhost")
+			case line == "DATA":
+				reply("354 go ahead")
+
+				for {
+					data, err := r.ReadString('\n')
+					if err != nil || data == ".\r\n" {
+						break
+					}
+
+					lines = append(lines, strings.TrimRight(data, "\r\n"))
+				}
+
+				reply("250 queued")
+			case line == "QUIT":
+				reply("221 bye")
+				received <- lines
+
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+
+		received <- lines
+	}()
+
+	port := ln.Addr().(*net.TCPAddr).Port
+	m := NewSMTPMailer("127.0.0.1", port, "", "", "Go Rent Bike <no-reply@mail.com>")
+
+	err = m.Send(Message{To: "arvin@mail.com", Subject: "Verify your email", Body: "follow the link"})
+
+	assert.Nil(t, err)
+
+	lines := <-received
+
+	assert.Contains(t, lines, "MAIL FROM:<no-reply@mail.com>")
+	assert.Contains(t, lines, "RCPT TO:<arvin@mail.com>")
+	assert.Contains(t, lines, "Subject: Verify your email")
+	assert.Contains(t, lines, "follow the link")
+}
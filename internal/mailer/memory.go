@@ -0,0 +1,44 @@
+package mailer
+
+import "sync"
+
+// MemoryMailer keeps every email it is given in memory, for the tests to read back.
+type MemoryMailer struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func (m *MemoryMailer) Send(msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, msg)
+
+	return nil
+}
+
+// Messages returns the emails sent so far, oldest first.
+func (m *MemoryMailer) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Message{}, m.messages...)
+}
+
+// Last returns the last email sent to a recipient.
+func (m *MemoryMailer) Last(to string) (Message, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].To == to {
+			return m.messages[i], true
+		}
+	}
+
+	return Message{}, false
+}
+
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
@@ -0,0 +1,16 @@
+package mailermock
+
+import (
+	"github.com/arvinpaundra/go-rent-bike/internal/mailer"
+	"github.com/stretchr/testify/mock"
+)
+
+type MailerMock struct {
+	Mock mock.Mock
+}
+
+func (m *MailerMock) Send(msg mailer.Message) error {
+	ret := m.Mock.Called(msg)
+
+	return ret.Error(0)
+}
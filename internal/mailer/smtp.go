@@ -0,0 +1,67 @@
+package mailer
+
+import (
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPMailer sends every email through an smtp server, authenticating only when a username is set.
+type SMTPMailer struct {
+	host     string
+	port     int
+	username string
+	password string
+	from     string
+}
+
+func (m *SMTPMailer) Send(msg Message) error {
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	// the envelope only takes the address, from may also carry a display name
+	sender := m.from
+	if addr, err := mail.ParseAddress(m.from); err == nil {
+		sender = addr.Address
+	}
+
+	addr := net.JoinHostPort(m.host, strconv.Itoa(m.port))
+
+	return smtp.SendMail(addr, auth, sender, []string{msg.To}, buildMessage(m.from, msg, time.Now()))
+}
+
+// buildMessage writes msg as a plain text email, its lines ending with CRLF as smtp requires.
+func buildMessage(from string, msg Message, now time.Time) []byte {
+	var b strings.Builder
+
+	b.WriteString("From: " + headerValue(from) + "\r\n")
+	b.WriteString("To: " + headerValue(msg.To) + "\r\n")
+	b.WriteString("Subject: " + headerValue(msg.Subject) + "\r\n")
+	b.WriteString("Date: " + now.Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n"))
+
+	return []byte(b.String())
+}
+
+// headerValue keeps a value on its header line, so it cannot add headers of its own.
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
+
+func NewSMTPMailer(host string, port int, username string, password string, from string) *SMTPMailer {
+	return &SMTPMailer{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
@@ -0,0 +1,53 @@
+package mddlwrs
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/arvinpaundra/go-rent-bike/helper"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/labstack/echo/v4"
+)
+
+// Authorize lets a request through when check passes for the user of its token. A resource owned by
+// somebody else answers 403 and one that does not exist 404, whatever route it is on.
+func Authorize(check func(c echo.Context, actorId string) error) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			err := check(c, helper.ExtractTokenClaims(c)["user_id"])
+
+			if err != nil {
+				if errors.Is(err, pkg.ErrForbidden) {
+					return c.JSON(http.StatusForbidden, map[string]interface{}{
+						"status":  "error",
+						"message": "you are not allowed to access this resource",
+						"data":    nil,
+					})
+				}
+
+				if errors.Is(err, pkg.ErrRecordNotFound) {
+					return c.JSON(http.StatusNotFound, map[string]interface{}{
+						"status":  "error",
+						"message": err.Error(),
+						"data":    nil,
+					})
+				}
+
+				return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+					"status":  "error",
+					"message": err.Error(),
+					"data":    nil,
+				})
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// AuthorizeParam authorizes the routes acting on the resource named by the path parameter param.
+func AuthorizeParam(param string, check func(actorId string, resourceId string) error) echo.MiddlewareFunc {
+	return Authorize(func(c echo.Context, actorId string) error {
+		return check(actorId, c.Param(param))
+	})
+}
@@ -0,0 +1,51 @@
+package mddlwrs
+
+import (
+	"net/http"
+
+	"github.com/arvinpaundra/go-rent-bike/configs"
+	"github.com/arvinpaundra/go-rent-bike/helper"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// JWT verifies the access token the same way the echo JWT middleware does, then rejects the tokens revoked
+// before they expired. Tokens without a jti cannot be revoked, so they are rejected as well.
+func JWT(sessionRepository repository.SessionRepository) echo.MiddlewareFunc {
+	verify := middleware.JWT([]byte(configs.Cfg.JWTSecret))
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return verify(func(c echo.Context) error {
+			jti := helper.ExtractTokenClaims(c)["jti"]
+
+			if jti == "" {
+				return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+					"status":  "error",
+					"message": "token can no longer be used, login again",
+					"data":    nil,
+				})
+			}
+
+			revoked, err := sessionRepository.IsTokenRevoked(jti)
+
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+					"status":  "error",
+					"message": err.Error(),
+					"data":    nil,
+				})
+			}
+
+			if revoked {
+				return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+					"status":  "error",
+					"message": "token has been revoked",
+					"data":    nil,
+				})
+			}
+
+			return next(c)
+		})
+	}
+}
@@ -2,34 +2,29 @@ package mddlwrs
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/arvinpaundra/go-rent-bike/helper"
 	"github.com/labstack/echo/v4"
 )
 
-func CheckIsRenter(next echo.HandlerFunc) echo.HandlerFunc {
-	return func(c echo.Context) error {
-		tokenExtracted, err := helper.ExtractToken(c)
+// RequireRole only lets through the users whose token carries one of roles, it runs after the JWT middleware.
+func RequireRole(roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			role := helper.ExtractTokenClaims(c)["role"]
 
-		if err != nil {
-			return c.JSON(http.StatusUnauthorized, map[string]interface{}{
-				"status":  "error",
-				"message": err.Error(),
-				"data":    nil,
-			})
-		}
-
-		payloads := tokenExtracted.(map[string]string)
-		role := payloads["role"]
+			for _, allowed := range roles {
+				if role == allowed {
+					return next(c)
+				}
+			}
 
-		if role != "renter" {
 			return c.JSON(http.StatusForbidden, map[string]interface{}{
 				"status":  "error",
-				"message": "user role must be renter",
+				"message": "user role must be " + strings.Join(roles, " or "),
 				"data":    nil,
 			})
 		}
-
-		return next(c)
 	}
 }
@@ -0,0 +1,42 @@
+package mddlwrs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireRole(t *testing.T) {
+	testCases := []struct {
+		Name         string
+		Roles        []string
+		Role         string
+		ExpectedCode int
+	}{
+		{"renter on renter route", []string{"renter"}, "renter", http.StatusOK},
+		{"customer on renter route", []string{"renter"}, "customer", http.StatusForbidden},
+		{"admin on admin route", []string{"admin"}, "admin", http.StatusOK},
+		{"renter on admin route", []string{"admin"}, "renter", http.StatusForbidden},
+		{"admin on route of several roles", []string{"renter", "admin"}, "admin", http.StatusOK},
+		{"no role", []string{"admin"}, "", http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+			ctx.Set("user", &jwt.Token{Claims: jwt.MapClaims{"user_id": "8b0e6c2f-8d5d-4a51-9d0a-3f6a9d3a4c11", "role": tc.Role}})
+
+			handler := RequireRole(tc.Roles...)(func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+
+			assert.NoError(t, handler(ctx))
+			assert.Equal(t, tc.ExpectedCode, rec.Code)
+		})
+	}
+}
@@ -2,48 +2,159 @@ package pg_midtrans
 
 import (
 	"context"
-	"fmt"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
 
-	"github.com/arvinpaundra/go-rent-bike/configs"
 	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/payment"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
 	"github.com/midtrans/midtrans-go"
+	"github.com/midtrans/midtrans-go/coreapi"
 	"github.com/midtrans/midtrans-go/snap"
 )
 
-var snapClient snap.Client
+// PaymentGateway is the midtrans payment provider, snap creates the payment links
+// and the core api checks, cancels and refunds the transactions.
+type PaymentGateway struct {
+	snapClient *snap.Client
+	coreClient *coreapi.Client
+}
+
+func (r PaymentGateway) CreateTransaction(req dto.PaymentGateway) (string, error) {
+	snapToken, err := r.snapClient.CreateTransactionToken(generateSnapReq(req))
+
+	if err != nil {
+		return "", gatewayError(err)
+	}
 
-type PaymentGatewayInterface interface {
-	InitializeClientMidtrans()
-	CreateTransaction(snap dto.PaymentGateway) string
-	CreateUrlTransactionWithGateway(snap dto.PaymentGateway) string
+	return snapToken, nil
 }
 
-type PaymentGateway struct{}
+func (r PaymentGateway) CreateUrlTransactionWithGateway(req dto.PaymentGateway) (string, error) {
+	r.snapClient.Options.SetContext(context.Background())
+
+	snapUrl, err := r.snapClient.CreateTransactionUrl(generateSnapReq(req))
 
-func (r PaymentGateway) InitializeClientMidtrans() {
-	snapClient.New(configs.Cfg.MidtransServerKeyDev, midtrans.Sandbox)
+	if err != nil {
+		return "", gatewayError(err)
+	}
+
+	return snapUrl, nil
 }
 
-func (r PaymentGateway) CreateTransaction(req dto.PaymentGateway) string {
-	snapUrl, err := snapClient.CreateTransactionToken(generateSnapReq(req))
+func (r PaymentGateway) CheckTransaction(orderId string) (*payment.TransactionStatus, error) {
+	res, err := r.coreClient.CheckTransaction(orderId)
 
 	if err != nil {
-		fmt.Printf("Midtrans error : %v", err.GetMessage())
+		if err.GetStatusCode() == http.StatusNotFound {
+			return nil, pkg.ErrRecordNotFound
+		}
+
+		return nil, gatewayError(err)
+	}
+
+	transactionStatus := &payment.TransactionStatus{
+		OrderId:           res.OrderID,
+		TransactionId:     res.TransactionID,
+		TransactionStatus: res.TransactionStatus,
+		FraudStatus:       res.FraudStatus,
+		PaymentType:       res.PaymentType,
+		StatusCode:        res.StatusCode,
+		GrossAmount:       res.GrossAmount,
+	}
+
+	for _, refund := range res.Refunds {
+		transactionStatus.Refunds = append(transactionStatus.Refunds, payment.TransactionRefund{
+			RefundKey:    refund.RefundKey,
+			RefundAmount: refund.RefundAmount,
+		})
 	}
 
-	return snapUrl
+	return transactionStatus, nil
 }
 
-func (r PaymentGateway) CreateUrlTransactionWithGateway(req dto.PaymentGateway) string {
-	snapClient.Options.SetContext(context.Background())
+func (r PaymentGateway) CancelTransaction(orderId string) error {
+	_, err := r.coreClient.CancelTransaction(orderId)
 
-	snapUrl, err := snapClient.CreateTransactionUrl(generateSnapReq(req))
+	// midtrans only knows the transaction once the customer picked a payment method in snap,
+	// there is nothing to cancel before that
+	if err != nil && err.GetStatusCode() != http.StatusNotFound {
+		return gatewayError(err)
+	}
+
+	return nil
+}
+
+func (r PaymentGateway) RefundTransaction(orderId string, refundKey string, amount int64, reason string) error {
+	refundReq := &coreapi.RefundReq{
+		RefundKey: refundKey,
+		Amount:    amount,
+		Reason:    reason,
+	}
+
+	_, err := r.coreClient.RefundTransaction(orderId, refundReq)
 
 	if err != nil {
-		fmt.Printf("Midtrans error : %v", err.GetMessage())
+		return gatewayError(err)
 	}
 
-	return snapUrl
+	return nil
+}
+
+// gatewayError sorts a midtrans error by what the app can do about it, only timeouts and
+// an unavailable or unreachable midtrans are worth sending again.
+func gatewayError(err *midtrans.Error) error {
+	gatewayErr := &payment.GatewayError{
+		Kind:       pkg.ErrGatewayInvalidRequest,
+		StatusCode: err.GetStatusCode(),
+		Message:    err.GetMessage(),
+	}
+
+	var netErr net.Error
+
+	switch statusCode := err.GetStatusCode(); {
+	case isUnreachable(err.RawError):
+		// the connection was never made, so midtrans never saw the request
+		gatewayErr.Kind = pkg.ErrGatewayUnreachable
+	case errors.As(err.RawError, &netErr) && netErr.Timeout(), statusCode == http.StatusGatewayTimeout:
+		gatewayErr.Kind = pkg.ErrGatewayTimeout
+	case statusCode == 0, statusCode == http.StatusTooManyRequests, statusCode >= http.StatusInternalServerError:
+		// no response at all, too many requests or midtrans is down
+		gatewayErr.Kind = pkg.ErrGatewayUnavailable
+	case statusCode == http.StatusUnauthorized:
+		gatewayErr.Kind = pkg.ErrGatewayUnauthorized
+	case statusCode == http.StatusNotAcceptable, isDuplicateOrderId(err.GetMessage()):
+		// the core api answers 406 to a used order id, snap answers 400 with a message
+		gatewayErr.Kind = pkg.ErrGatewayDuplicateOrder
+	}
+
+	return gatewayErr
+}
+
+// isUnreachable reports whether err failed to look up or connect to midtrans, before any request was sent.
+func isUnreachable(err error) bool {
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+
+	return errors.As(err, &dnsErr) || (errors.As(err, &opErr) && opErr.Op == "dial")
+}
+
+func isDuplicateOrderId(message string) bool {
+	message = strings.ToLower(message)
+
+	return strings.Contains(message, "order_id") && (strings.Contains(message, "already") || strings.Contains(message, "sudah digunakan"))
+}
+
+// NotificationSignature is the signature key midtrans puts on its notifications,
+// the SHA512 hash of the order id, status code, gross amount and server key.
+func NotificationSignature(orderId string, statusCode string, grossAmount string, serverKey string) string {
+	hash := sha512.Sum512([]byte(orderId + statusCode + grossAmount + serverKey))
+
+	return hex.EncodeToString(hash[:])
 }
 
 func generateSnapReq(req dto.PaymentGateway) *snap.Request {
@@ -68,5 +179,27 @@ func generateSnapReq(req dto.PaymentGateway) *snap.Request {
 		Items: &req.Items,
 	}
 
+	// let the payment link expire together with the unpaid order
+	if req.ExpiryMinutes > 0 {
+		reqSnap.Expiry = &snap.ExpiryDetails{
+			StartTime: req.ExpiryStartAt.Format("2006-01-02 15:04:05 -0700"),
+			Unit:      "minute",
+			Duration:  req.ExpiryMinutes,
+		}
+	}
+
 	return reqSnap
 }
+
+func NewPaymentGateway(serverKey string) payment.PaymentProvider {
+	snapClient := &snap.Client{}
+	snapClient.New(serverKey, midtrans.Sandbox)
+
+	coreClient := &coreapi.Client{}
+	coreClient.New(serverKey, midtrans.Sandbox)
+
+	return PaymentGateway{
+		snapClient: snapClient,
+		coreClient: coreClient,
+	}
+}
@@ -3,16 +3,31 @@ package model
 import "time"
 
 type Bike struct {
-	ID           string    `json:"id" gorm:"primaryKey;size:255"`
-	RenterId     string    `json:"renter_id" gorm:"size:255"`
-	CategoryId   string    `json:"category_id" gorm:"size:255"`
-	Name         string    `json:"name" gorm:"size:255"`
-	PricePerHour float32   `json:"price_per_hour"`
-	Condition    string    `json:"condition" gorm:"size:100"`
-	Description  string    `json:"description"`
-	IsAvailable  string    `json:"is_available" gorm:"size:1"`
-	Category     Category  `json:"category"`
-	Reviews      []Review  `json:"reviews,omitempty"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID                string    `json:"id" gorm:"primaryKey;size:255"`
+	RenterId          string    `json:"renter_id" gorm:"size:255"`
+	CategoryId        string    `json:"category_id" gorm:"size:255"`
+	Name              string    `json:"name" gorm:"size:255"`
+	PricePerHour      float32   `json:"price_per_hour"`
+	PricePerDay       float32   `json:"price_per_day"`
+	PricePerWeek      float32   `json:"price_per_week"`
+	WeekendMultiplier float32   `json:"weekend_multiplier"`
+	HolidayMultiplier float32   `json:"holiday_multiplier"`
+	Deposit           float32   `json:"deposit"`
+	Condition         string    `json:"condition" gorm:"size:100"`
+	Description       string    `json:"description"`
+	IsAvailable       string    `json:"is_available" gorm:"size:1"`
+	Category          Category  `json:"category"`
+	Reviews           []Review  `json:"reviews,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// DepositAmount is the refundable deposit held for the bike on every rental,
+// a bike without a deposit of its own takes the deposit of its category.
+func (b Bike) DepositAmount() float32 {
+	if b.Deposit > 0 {
+		return b.Deposit
+	}
+
+	return b.Category.Deposit
 }
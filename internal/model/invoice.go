@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// Invoice numbers the invoice a renter issues for its bikes in an order. The sequence counts up per renter,
+// so the invoices of every renter are numbered without gaps.
+type Invoice struct {
+	ID        string    `json:"id" gorm:"primaryKey;size:255"`
+	OrderId   string    `json:"order_id" gorm:"size:255;uniqueIndex:idx_invoices_order_renter"`
+	RenterId  string    `json:"renter_id" gorm:"size:255;uniqueIndex:idx_invoices_order_renter;uniqueIndex:idx_invoices_renter_sequence"`
+	Sequence  int       `json:"sequence" gorm:"uniqueIndex:idx_invoices_renter_sequence"`
+	Number    string    `json:"number" gorm:"size:50"`
+	CreatedAt time.Time `json:"created_at"`
+}
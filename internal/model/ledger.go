@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// the accounts of the double-entry ledger
+const (
+	// LedgerAccountGatewayClearing holds the money the payment gateway collected, until it is paid out
+	LedgerAccountGatewayClearing = "gateway_clearing"
+	// LedgerAccountRenterPayable is what the platform owes a renter
+	LedgerAccountRenterPayable = "renter_payable"
+	// LedgerAccountPlatformCommission is what the platform earns on every order
+	LedgerAccountPlatformCommission = "platform_commission"
+	// LedgerAccountPayoutClearing holds the money of a payout until the bank transfer is done
+	LedgerAccountPayoutClearing = "payout_clearing"
+	// LedgerAccountCustomerWallet is what the platform holds in the wallets of the customers
+	LedgerAccountCustomerWallet = "customer_wallet"
+	// LedgerAccountDepositHeld holds the deposits of the rentals until they are released or withheld
+	LedgerAccountDepositHeld = "deposit_held"
+)
+
+// LedgerEntry is one side of a journal. The entries of a journal are posted together
+// and their debits always equal their credits.
+type LedgerEntry struct {
+	ID          string    `json:"id" gorm:"primaryKey;size:255"`
+	JournalId   string    `json:"journal_id" gorm:"size:255;index"`
+	Account     string    `json:"account" gorm:"size:50;index:idx_ledger_entries_account_renter"`
+	RenterId    string    `json:"renter_id,omitempty" gorm:"size:255;index:idx_ledger_entries_account_renter"`
+	PaymentId   string    `json:"payment_id,omitempty" gorm:"size:255;index"`
+	RefundId    string    `json:"refund_id,omitempty" gorm:"size:255"`
+	PayoutId    string    `json:"payout_id,omitempty" gorm:"size:255;index"`
+	Description string    `json:"description" gorm:"size:255"`
+	Debit       float32   `json:"debit"`
+	Credit      float32   `json:"credit"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// RenterBalance is what the platform owes a renter, the credits of its payable account minus the debits.
+type RenterBalance struct {
+	RenterId string  `json:"renter_id"`
+	Balance  float32 `json:"balance"`
+}
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+const (
+	LoginFailureUnknownEmail  = "unknown_email"
+	LoginFailureWrongPassword = "wrong_password"
+	// LoginFailureThrottled and LoginFailureLocked are attempts refused before the password was checked
+	LoginFailureThrottled = "throttled"
+	LoginFailureLocked    = "locked"
+)
+
+// LoginAttempt is the audit trail of the logins, successful or not. The user is empty when nobody
+// registered with the email.
+type LoginAttempt struct {
+	ID            string    `json:"id" gorm:"primaryKey;size:255"`
+	UserId        string    `json:"user_id" gorm:"size:255;index"`
+	Email         string    `json:"email" gorm:"size:255;index"`
+	IPAddress     string    `json:"ip_address" gorm:"size:45;index"`
+	UserAgent     string    `json:"user_agent" gorm:"size:255"`
+	Success       bool      `json:"success"`
+	FailureReason string    `json:"failure_reason,omitempty" gorm:"size:50"`
+	CreatedAt     time.Time `json:"created_at" gorm:"index"`
+}
+
+// LoginThrottle counts the failed logins of an email or of an ip address, keyed by "email:" or "ip:" and the value.
+// The failures are forgotten once none happened for a while, LockedUntil refuses every login until then.
+type LoginThrottle struct {
+	Key           string     `json:"key" gorm:"primaryKey;size:255"`
+	Failures      int        `json:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
@@ -2,15 +2,33 @@ package model
 
 import "time"
 
+const (
+	DepositStatusHeld     = "held"
+	DepositStatusReleased = "released"
+	DepositStatusWithheld = "withheld"
+)
+
 type Order struct {
-	ID           string        `json:"id" gorm:"primaryKey;size:255"`
-	UserId       string        `json:"user_id" gorm:"size:255"`
-	PaymentId    string        `json:"payment_id" gorm:"size:255"`
-	TotalPayment float32       `json:"total_payment"`
-	TotalQty     int           `json:"total_qty"`
-	TotalHour    int           `json:"total_hour"`
-	OrderDetails []OrderDetail `json:"order_details,omitempty"`
-	Payment      *Payment      `json:"payment_details,omitempty"`
-	CreatedAt    time.Time     `json:"created_at"`
-	UpdatedAt    time.Time     `json:"updated_at"`
+	ID              string        `json:"id" gorm:"primaryKey;size:255"`
+	UserId          string        `json:"user_id" gorm:"size:255"`
+	PaymentId       string        `json:"payment_id" gorm:"size:255"`
+	TotalPayment    float32       `json:"total_payment"`
+	Discount        float32       `json:"discount"`
+	TotalQty        int           `json:"total_qty"`
+	TotalHour       int           `json:"total_hour"`
+	StartAt         time.Time     `json:"start_at" gorm:"index"`
+	EndAt           time.Time     `json:"end_at" gorm:"index"`
+	PendingEndAt    *time.Time    `json:"pending_end_at,omitempty"`
+	Status          OrderStatus   `json:"status" gorm:"size:50;index"`
+	CancellationFee float32       `json:"cancellation_fee"`
+	ReturnedAt      *time.Time    `json:"returned_at,omitempty"`
+	LateFee         float32       `json:"late_fee"`
+	Deposit         float32       `json:"deposit"`
+	DepositStatus   string        `json:"deposit_status,omitempty" gorm:"size:20"`
+	DepositWithheld float32       `json:"deposit_withheld"`
+	DepositReason   string        `json:"deposit_reason,omitempty" gorm:"size:255"`
+	OrderDetails    []OrderDetail `json:"order_details,omitempty"`
+	Payment         *Payment      `json:"payment_details,omitempty" gorm:"foreignKey:PaymentId"`
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
 }
@@ -0,0 +1,53 @@
+package model
+
+type OrderStatus string
+
+const (
+	OrderStatusPendingPayment OrderStatus = "pending_payment"
+	OrderStatusPaid           OrderStatus = "paid"
+	OrderStatusPickedUp       OrderStatus = "picked_up"
+	OrderStatusReturned       OrderStatus = "returned"
+	OrderStatusClosed         OrderStatus = "closed"
+	OrderStatusCanceled       OrderStatus = "canceled"
+	OrderStatusExpired        OrderStatus = "expired"
+	OrderStatusDenied         OrderStatus = "denied"
+)
+
+// orderTransitions lists every status an order may move to from its current status,
+// statuses without an entry are final.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusPendingPayment: {OrderStatusPaid, OrderStatusCanceled, OrderStatusExpired, OrderStatusDenied},
+	OrderStatusPaid:           {OrderStatusPickedUp, OrderStatusCanceled},
+	OrderStatusPickedUp:       {OrderStatusReturned},
+	OrderStatusReturned:       {OrderStatusClosed},
+}
+
+// IsValid reports whether s is one of the statuses an order can be in.
+func (s OrderStatus) IsValid() bool {
+	switch s {
+	case OrderStatusPendingPayment, OrderStatusPaid, OrderStatusPickedUp, OrderStatusReturned,
+		OrderStatusClosed, OrderStatusCanceled, OrderStatusExpired, OrderStatusDenied:
+		return true
+	}
+
+	return false
+}
+
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range orderTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s OrderStatus) IsFinal() bool {
+	return len(orderTransitions[s]) == 0
+}
+
+// HoldsBikes reports whether the bikes of an order in this status are still reserved.
+func (s OrderStatus) HoldsBikes() bool {
+	return s == OrderStatusPendingPayment || s == OrderStatusPaid || s == OrderStatusPickedUp
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+type OrderStatusHistory struct {
+	ID         string      `json:"id" gorm:"primaryKey;size:255"`
+	OrderId    string      `json:"order_id" gorm:"size:255;index"`
+	FromStatus OrderStatus `json:"from_status" gorm:"size:50"`
+	ToStatus   OrderStatus `json:"to_status" gorm:"size:50"`
+	Actor      string      `json:"actor" gorm:"size:255"`
+	Reason     string      `json:"reason,omitempty" gorm:"size:255"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
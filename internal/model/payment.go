@@ -2,8 +2,57 @@ package model
 
 import "time"
 
+const (
+	PaymentKindRent      = "rent"
+	PaymentKindLateFee   = "late_fee"
+	PaymentKindExtension = "extension"
+	PaymentKindTopUp     = "top_up"
+)
+
+// PaymentTypeWallet pays from the wallet of the customer instead of the payment gateway
+const PaymentTypeWallet = "wallet"
+
+const (
+	PaymentStatusPending       = "pending"
+	PaymentStatusSettlement    = "settlement"
+	PaymentStatusDeny          = "deny"
+	PaymentStatusCancel        = "cancel"
+	PaymentStatusExpire        = "expire"
+	PaymentStatusPartialRefund = "partial_refund"
+	PaymentStatusRefund        = "refund"
+
+	// PaymentStatusLateSettlement is a payment the gateway settled after it expired or was canceled here,
+	// its order no longer holds the bikes, so the money is owed back to the customer.
+	PaymentStatusLateSettlement = "late_settlement"
+)
+
+// paymentTransitions lists every status a payment may move to, so a payment status only ever moves forward.
+var paymentTransitions = map[string][]string{
+	PaymentStatusPending:        {PaymentStatusSettlement, PaymentStatusDeny, PaymentStatusCancel, PaymentStatusExpire},
+	PaymentStatusSettlement:     {PaymentStatusPartialRefund, PaymentStatusRefund},
+	PaymentStatusPartialRefund:  {PaymentStatusPartialRefund, PaymentStatusRefund},
+	PaymentStatusCancel:         {PaymentStatusLateSettlement},
+	PaymentStatusExpire:         {PaymentStatusLateSettlement},
+	PaymentStatusLateSettlement: {PaymentStatusPartialRefund, PaymentStatusRefund},
+}
+
+func CanTransitionPaymentStatus(current string, next string) bool {
+	for _, allowed := range paymentTransitions[current] {
+		if allowed == next {
+			return true
+		}
+	}
+
+	return false
+}
+
 type Payment struct {
 	ID            string    `json:"id" gorm:"size:255"`
+	OrderId       string    `json:"order_id" gorm:"size:255;index"`
+	WalletId      string    `json:"wallet_id,omitempty" gorm:"size:255;index"`
+	Kind          string    `json:"kind" gorm:"size:20"`
+	Amount        float32   `json:"amount"`
+	Deposit       float32   `json:"deposit"`
 	PaymentStatus string    `json:"payment_status" gorm:"size:20"`
 	PaymentType   string    `json:"payment_type" gorm:"size:50"`
 	PaymentLink   string    `json:"payment_link" gorm:"size:255"`
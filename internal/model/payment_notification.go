@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// PaymentNotification is a notification of the payment gateway that was already processed,
+// one per transaction and status. Every partial refund moves the refunded amount, so it is part of the key too.
+type PaymentNotification struct {
+	ID                string    `json:"id" gorm:"primaryKey;size:255"`
+	TransactionId     string    `json:"transaction_id" gorm:"size:255;uniqueIndex:idx_payment_notifications_transaction"`
+	TransactionStatus string    `json:"transaction_status" gorm:"size:20;uniqueIndex:idx_payment_notifications_transaction"`
+	OrderId           string    `json:"order_id" gorm:"size:255;index"`
+	StatusCode        string    `json:"status_code" gorm:"size:10"`
+	FraudStatus       string    `json:"fraud_status" gorm:"size:20"`
+	GrossAmount       string    `json:"gross_amount" gorm:"size:50"`
+	RefundAmount      string    `json:"refund_amount" gorm:"size:50;uniqueIndex:idx_payment_notifications_transaction"`
+	Payload           string    `json:"payload" gorm:"type:text"`
+	CreatedAt         time.Time `json:"created_at"`
+}
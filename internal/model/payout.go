@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+const (
+	PayoutBatchStatusPending   = "pending"
+	PayoutBatchStatusCompleted = "completed"
+)
+
+const (
+	PayoutStatusPending = "pending"
+	PayoutStatusPaid    = "paid"
+	PayoutStatusFailed  = "failed"
+)
+
+// PayoutBatch groups the payouts sent to the bank together, it is completed once none of them is pending.
+type PayoutBatch struct {
+	ID          string    `json:"id" gorm:"primaryKey;size:255"`
+	Status      string    `json:"status" gorm:"size:20"`
+	TotalAmount float32   `json:"total_amount"`
+	TotalPayout int       `json:"total_payout"`
+	Payouts     []Payout  `json:"payouts,omitempty" gorm:"foreignKey:BatchId"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Payout is the balance of a renter transferred to its bank account. The bank account is copied
+// from the renter, so the exported transfer does not change when the renter changes it later.
+type Payout struct {
+	ID                string    `json:"id" gorm:"primaryKey;size:255"`
+	BatchId           string    `json:"batch_id" gorm:"size:255;index"`
+	RenterId          string    `json:"renter_id" gorm:"size:255;index"`
+	Amount            float32   `json:"amount"`
+	BankName          string    `json:"bank_name" gorm:"size:100"`
+	BankAccountNumber string    `json:"bank_account_number" gorm:"size:50"`
+	BankAccountName   string    `json:"bank_account_name" gorm:"size:255"`
+	Status            string    `json:"status" gorm:"size:20"`
+	Reference         string    `json:"reference" gorm:"size:255"`
+	FailureReason     string    `json:"failure_reason" gorm:"size:255"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
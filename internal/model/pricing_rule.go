@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+type PricingRule struct {
+	ID         string    `json:"id" gorm:"primaryKey;size:255"`
+	RenterId   string    `json:"renter_id" gorm:"size:255;index"`
+	Name       string    `json:"name" gorm:"size:100"`
+	StartAt    time.Time `json:"start_at"`
+	EndAt      time.Time `json:"end_at"`
+	Multiplier float32   `json:"multiplier"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+const (
+	RefundStatusPending   = "pending"
+	RefundStatusSucceeded = "succeeded"
+)
+
+const (
+	RefundKindPayment = "payment"
+	RefundKindDeposit = "deposit"
+)
+
+// Refund is money given back on a payment. Its id is the refund key sent to the payment gateway,
+// and it stays pending until the gateway reports the refund on the transaction.
+type Refund struct {
+	ID          string    `json:"id" gorm:"primaryKey;size:255"`
+	PaymentId   string    `json:"payment_id" gorm:"size:255;index"`
+	Kind        string    `json:"kind" gorm:"size:20"`
+	Amount      float32   `json:"amount"`
+	Reason      string    `json:"reason" gorm:"size:255"`
+	Status      string    `json:"status" gorm:"size:20"`
+	RequestedBy string    `json:"requested_by" gorm:"size:255"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
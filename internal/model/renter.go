@@ -3,14 +3,26 @@ package model
 import "time"
 
 type Renter struct {
-	ID          string    `json:"id" gorm:"primaryKey;size:255"`
-	UserId      string    `json:"user_id" gorm:"size:255"`
-	RentName    string    `json:"rent_name" gorm:"size:255"`
-	RentAddress string    `json:"rent_address"`
-	Description string    `json:"description"`
-	User        User      `json:"user"`
-	Bikes       []Bike    `json:"bikes,omitempty"`
-	Report      []Report  `json:"reports,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID                     string     `json:"id" gorm:"primaryKey;size:255"`
+	UserId                 string     `json:"user_id" gorm:"size:255"`
+	RentName               string     `json:"rent_name" gorm:"size:255"`
+	RentAddress            string     `json:"rent_address"`
+	Description            string     `json:"description"`
+	FreeCancellationHours  int        `json:"free_cancellation_hours"`
+	CancellationFeePercent float32    `json:"cancellation_fee_percent"`
+	BankName               string     `json:"bank_name" gorm:"size:100"`
+	BankAccountNumber      string     `json:"bank_account_number" gorm:"size:50"`
+	BankAccountName        string     `json:"bank_account_name" gorm:"size:255"`
+	SuspendedAt            *time.Time `json:"suspended_at"`
+	SuspendReason          string     `json:"suspend_reason,omitempty" gorm:"size:255"`
+	User                   User       `json:"user"`
+	Bikes                  []Bike     `json:"bikes,omitempty"`
+	Report                 []Report   `json:"reports,omitempty"`
+	CreatedAt              time.Time  `json:"created_at"`
+	UpdatedAt              time.Time  `json:"updated_at"`
+}
+
+// IsSuspended reports whether an admin suspended the renter, its bikes cannot be added or ordered until lifted.
+func (r Renter) IsSuspended() bool {
+	return r.SuspendedAt != nil
 }
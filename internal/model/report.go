@@ -2,13 +2,23 @@ package model
 
 import "time"
 
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusResolved  = "resolved"
+	ReportStatusDismissed = "dismissed"
+)
+
 type Report struct {
-	ID         string    `json:"id"`
-	RenterId   string    `json:"renter_id"`
-	UserId     string    `json:"user_id"`
-	TitleIssue string    `json:"title_issue"`
-	BodyIssue  string    `json:"body_issue"`
-	User       *User     `json:"user,omitempty"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID         string     `json:"id"`
+	RenterId   string     `json:"renter_id"`
+	UserId     string     `json:"user_id"`
+	TitleIssue string     `json:"title_issue"`
+	BodyIssue  string     `json:"body_issue"`
+	Status     string     `json:"status" gorm:"size:50;index"`
+	Resolution string     `json:"resolution,omitempty"`
+	ResolvedBy string     `json:"resolved_by,omitempty" gorm:"size:255"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	User       *User      `json:"user,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
 }
@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// Session is a login of a user on one device. Only the hash of its refresh token is stored, the token is
+// rotated on every refresh and the hash of the one it replaced is kept to tell when a stolen token is reused.
+type Session struct {
+	ID                       string     `json:"id" gorm:"primaryKey;size:255"`
+	UserId                   string     `json:"user_id" gorm:"size:255;index"`
+	RefreshTokenHash         string     `json:"-" gorm:"size:64;uniqueIndex"`
+	PreviousRefreshTokenHash string     `json:"-" gorm:"size:64;index"`
+	AccessTokenJti           string     `json:"-" gorm:"size:255"`
+	AccessTokenExpiresAt     time.Time  `json:"-"`
+	UserAgent                string     `json:"user_agent" gorm:"size:255"`
+	IPAddress                string     `json:"ip_address" gorm:"size:45"`
+	ExpiresAt                time.Time  `json:"expires_at"`
+	LastUsedAt               time.Time  `json:"last_used_at"`
+	RevokedAt                *time.Time `json:"revoked_at,omitempty"`
+	Current                  bool       `json:"current" gorm:"-"`
+	CreatedAt                time.Time  `json:"created_at"`
+	UpdatedAt                time.Time  `json:"updated_at"`
+}
+
+// RevokedToken is an access token revoked before it expired, it only has to be kept until then.
+type RevokedToken struct {
+	Jti       string    `json:"jti" gorm:"primaryKey;size:255"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"index"`
+	CreatedAt time.Time `json:"created_at"`
+}
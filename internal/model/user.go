@@ -2,16 +2,29 @@ package model
 
 import "time"
 
+const (
+	RoleCustomer = "customer"
+	RoleRenter   = "renter"
+	// RoleAdmin runs the back-office, admins cannot register themselves and are created from the command line
+	RoleAdmin = "admin"
+)
+
 type User struct {
-	ID        string    `json:"id" gorm:"primaryKey;size:255"`
-	Fullname  string    `json:"fullname" gorm:"size:255"`
-	Phone     string    `json:"phone" gorm:"size:13"`
-	Address   string    `json:"address"`
-	Role      string    `json:"role" gorm:"size:50"`
-	Email     string    `json:"email" gorm:"size:255"`
-	Password  string    `json:"password,omitempty" gorm:"size:255"`
-	Orders    []Order   `json:"orders,omitempty"`
-	Reviews   []Review  `json:"reviews,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              string     `json:"id" gorm:"primaryKey;size:255"`
+	Fullname        string     `json:"fullname" gorm:"size:255"`
+	Phone           string     `json:"phone" gorm:"size:13"`
+	Address         string     `json:"address"`
+	Role            string     `json:"role" gorm:"size:50"`
+	Email           string     `json:"email" gorm:"size:255"`
+	Password        string     `json:"password,omitempty" gorm:"size:255"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	Orders          []Order    `json:"orders,omitempty"`
+	Reviews         []Review   `json:"reviews,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// IsEmailVerified tells whether the user followed the link of the verification email, users cannot order before.
+func (u User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
 }
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+const (
+	UserTokenEmailVerification = "email_verification"
+	UserTokenPasswordReset     = "password_reset"
+)
+
+// UserToken is a single use token sent to the email of a user, to verify the email or to reset the password.
+// Only the hash of the token is stored, it cannot be used after it expires or once it was used.
+type UserToken struct {
+	ID        string     `json:"id" gorm:"primaryKey;size:255"`
+	UserId    string     `json:"user_id" gorm:"size:255;index"`
+	Purpose   string     `json:"purpose" gorm:"size:50"`
+	TokenHash string     `json:"-" gorm:"size:64;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (t UserToken) IsUsable(purpose string, now time.Time) bool {
+	return t.Purpose == purpose && t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+const (
+	VoucherDiscountPercentage = "percentage"
+	VoucherDiscountFixed      = "fixed"
+)
+
+// Voucher is a discount code. A voucher without renter is platform-wide,
+// otherwise it only discounts the bikes of that renter. Zero limits are unlimited.
+type Voucher struct {
+	ID            string    `json:"id" gorm:"primaryKey;size:255"`
+	RenterId      string    `json:"renter_id" gorm:"size:255;index"`
+	Code          string    `json:"code" gorm:"size:50;uniqueIndex"`
+	DiscountType  string    `json:"discount_type" gorm:"size:20"`
+	DiscountValue float32   `json:"discount_value"`
+	MaxDiscount   float32   `json:"max_discount"`
+	MinSpend      float32   `json:"min_spend"`
+	UsageLimit    int       `json:"usage_limit"`
+	PerUserLimit  int       `json:"per_user_limit"`
+	StartAt       time.Time `json:"start_at"`
+	EndAt         time.Time `json:"end_at"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type VoucherUsage struct {
+	ID        string    `json:"id" gorm:"primaryKey;size:255"`
+	VoucherId string    `json:"voucher_id" gorm:"size:255;index"`
+	UserId    string    `json:"user_id" gorm:"size:255;index"`
+	OrderId   string    `json:"order_id" gorm:"size:255"`
+	Discount  float32   `json:"discount"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+const (
+	WalletTransactionTopUp   = "top_up"
+	WalletTransactionPayment = "payment"
+	WalletTransactionRefund  = "refund"
+)
+
+// Wallet is the stored balance of a customer, topped up through the payment gateway and spent on orders.
+type Wallet struct {
+	ID        string    `json:"id" gorm:"primaryKey;size:255"`
+	UserId    string    `json:"user_id" gorm:"size:255;uniqueIndex"`
+	Balance   float32   `json:"balance"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WalletTransaction is one change of a wallet balance. Transactions are only ever added,
+// a credit has a positive amount and a debit a negative one.
+type WalletTransaction struct {
+	ID           string    `json:"id" gorm:"primaryKey;size:255"`
+	WalletId     string    `json:"wallet_id" gorm:"size:255;index"`
+	Type         string    `json:"type" gorm:"size:20"`
+	Amount       float32   `json:"amount"`
+	BalanceAfter float32   `json:"balance_after"`
+	PaymentId    string    `json:"payment_id,omitempty" gorm:"size:255;index"`
+	RefundId     string    `json:"refund_id,omitempty" gorm:"size:255"`
+	Description  string    `json:"description" gorm:"size:255"`
+	CreatedAt    time.Time `json:"created_at"`
+}
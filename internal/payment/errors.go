@@ -0,0 +1,55 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+)
+
+// GatewayError is a request the payment gateway failed or rejected. It matches pkg.ErrPaymentGateway
+// and its kind, one of pkg.ErrGatewayTimeout, ErrGatewayUnavailable, ErrGatewayUnreachable, ErrGatewayUnauthorized,
+// ErrGatewayInvalidRequest and ErrGatewayDuplicateOrder.
+type GatewayError struct {
+	Kind       error
+	StatusCode int
+	Message    string
+}
+
+func (e *GatewayError) Error() string {
+	return fmt.Sprintf("%v: %s", e.Kind, e.Message)
+}
+
+func (e *GatewayError) Is(target error) bool {
+	return target == pkg.ErrPaymentGateway
+}
+
+func (e *GatewayError) Unwrap() error {
+	return e.Kind
+}
+
+// Temporary reports whether the same request may succeed when it is sent again.
+func (e *GatewayError) Temporary() bool {
+	return e.Kind == pkg.ErrGatewayTimeout || e.Kind == pkg.ErrGatewayUnavailable || e.Kind == pkg.ErrGatewayUnreachable
+}
+
+// Unprocessed reports whether the payment gateway surely did not act on the request: it never reached the gateway,
+// or the gateway turned it away before handling it. After a timeout the request may have been handled or not.
+func (e *GatewayError) Unprocessed() bool {
+	return e.Kind == pkg.ErrGatewayUnreachable || e.StatusCode == http.StatusTooManyRequests || e.StatusCode == http.StatusServiceUnavailable
+}
+
+// IsTemporary reports whether err is a gateway error worth retrying.
+func IsTemporary(err error) bool {
+	var gatewayErr *GatewayError
+
+	return errors.As(err, &gatewayErr) && gatewayErr.Temporary()
+}
+
+// IsUnprocessed reports whether err is a gateway error for a request the gateway never acted on.
+func IsUnprocessed(err error) bool {
+	var gatewayErr *GatewayError
+
+	return errors.As(err, &gatewayErr) && gatewayErr.Unprocessed()
+}
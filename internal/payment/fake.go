@@ -0,0 +1,198 @@
+package payment
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/google/uuid"
+)
+
+// FakeProvider keeps every transaction in memory, so the app runs without midtrans.
+// Transactions stay pending until they are moved with Simulate.
+type FakeProvider struct {
+	mu           sync.Mutex
+	baseUrl      string
+	transactions map[string]*fakeTransaction
+}
+
+type fakeTransaction struct {
+	status   TransactionStatus
+	amount   int64
+	refunded int64
+}
+
+func (p *FakeProvider) CreateTransaction(req dto.PaymentGateway) (string, error) {
+	transactionId, err := p.create(req)
+
+	if err != nil {
+		return "", err
+	}
+
+	return transactionId, nil
+}
+
+func (p *FakeProvider) CreateUrlTransactionWithGateway(req dto.PaymentGateway) (string, error) {
+	if _, err := p.create(req); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s/api/v1/fake-payments/%s", p.baseUrl, req.OrderId), nil
+}
+
+// create stores a pending transaction, an order id can only be used once like in midtrans.
+func (p *FakeProvider) create(req dto.PaymentGateway) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if req.GrossAmt <= 0 {
+		return "", &GatewayError{Kind: pkg.ErrGatewayInvalidRequest, StatusCode: 400, Message: "gross amount must be positive"}
+	}
+
+	if _, ok := p.transactions[req.OrderId]; ok {
+		return "", &GatewayError{Kind: pkg.ErrGatewayDuplicateOrder, StatusCode: 406, Message: fmt.Sprintf("order id %s has already been taken", req.OrderId)}
+	}
+
+	p.transactions[req.OrderId] = &fakeTransaction{
+		status: TransactionStatus{
+			OrderId:           req.OrderId,
+			TransactionId:     uuid.NewString(),
+			TransactionStatus: "pending",
+			StatusCode:        "201",
+			GrossAmount:       fmt.Sprintf("%d.00", req.GrossAmt),
+		},
+		amount: req.GrossAmt,
+	}
+
+	return p.transactions[req.OrderId].status.TransactionId, nil
+}
+
+func (p *FakeProvider) CheckTransaction(orderId string) (*TransactionStatus, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	transaction, ok := p.transactions[orderId]
+
+	if !ok {
+		return nil, pkg.ErrRecordNotFound
+	}
+
+	status := transaction.status
+	status.Refunds = append([]TransactionRefund(nil), transaction.status.Refunds...)
+
+	return &status, nil
+}
+
+func (p *FakeProvider) CancelTransaction(orderId string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	transaction, ok := p.transactions[orderId]
+
+	// same as midtrans, there is nothing to cancel before the transaction exists
+	if !ok {
+		return nil
+	}
+
+	if transaction.status.TransactionStatus != "pending" {
+		return fmt.Errorf("%w: transaction %s is %s", pkg.ErrPaymentGateway, orderId, transaction.status.TransactionStatus)
+	}
+
+	transaction.status.TransactionStatus = "cancel"
+	transaction.status.StatusCode = "200"
+
+	return nil
+}
+
+func (p *FakeProvider) RefundTransaction(orderId string, refundKey string, amount int64, reason string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	transaction, ok := p.transactions[orderId]
+
+	if !ok {
+		return pkg.ErrRecordNotFound
+	}
+
+	switch transaction.status.TransactionStatus {
+	case "settlement", "partial_refund":
+	default:
+		return fmt.Errorf("%w: transaction %s is %s", pkg.ErrPaymentGateway, orderId, transaction.status.TransactionStatus)
+	}
+
+	if amount <= 0 || transaction.refunded+amount > transaction.amount {
+		return fmt.Errorf("%w: refund of %d exceeds the refundable amount of %d", pkg.ErrPaymentGateway, amount, transaction.amount-transaction.refunded)
+	}
+
+	// a refund key is only refunded once, like in midtrans
+	for _, refund := range transaction.status.Refunds {
+		if refund.RefundKey == refundKey {
+			return fmt.Errorf("%w: refund key %s already used", pkg.ErrPaymentGateway, refundKey)
+		}
+	}
+
+	transaction.refunded += amount
+	transaction.status.Refunds = append(transaction.status.Refunds, TransactionRefund{
+		RefundKey:    refundKey,
+		RefundAmount: fmt.Sprintf("%d.00", amount),
+	})
+	transaction.status.TransactionStatus = "partial_refund"
+	transaction.status.StatusCode = "200"
+
+	if transaction.refunded == transaction.amount {
+		transaction.status.TransactionStatus = "refund"
+	}
+
+	return nil
+}
+
+// Simulate moves a pending transaction the way a customer paying, or not paying, in midtrans would.
+func (p *FakeProvider) Simulate(orderId string, transactionStatus string, paymentType string) (*TransactionStatus, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	transaction, ok := p.transactions[orderId]
+
+	if !ok {
+		return nil, pkg.ErrRecordNotFound
+	}
+
+	if transaction.status.TransactionStatus != "pending" {
+		return nil, fmt.Errorf("%w: transaction %s is %s", pkg.ErrPaymentGateway, orderId, transaction.status.TransactionStatus)
+	}
+
+	statusCodes := map[string]string{
+		"settlement": "200",
+		"deny":       "202",
+		"cancel":     "200",
+		"expire":     "407",
+	}
+
+	statusCode, ok := statusCodes[transactionStatus]
+
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown transaction status %s", pkg.ErrPaymentGateway, transactionStatus)
+	}
+
+	transaction.status.TransactionStatus = transactionStatus
+	transaction.status.StatusCode = statusCode
+	transaction.status.PaymentType = paymentType
+
+	if transactionStatus == "settlement" {
+		transaction.status.FraudStatus = "accept"
+	}
+
+	status := transaction.status
+	status.Refunds = append([]TransactionRefund(nil), transaction.status.Refunds...)
+
+	return &status, nil
+}
+
+// NewFakeProvider creates a fake payment provider, its payment links start with baseUrl.
+func NewFakeProvider(baseUrl string) *FakeProvider {
+	return &FakeProvider{
+		baseUrl:      baseUrl,
+		transactions: map[string]*fakeTransaction{},
+	}
+}
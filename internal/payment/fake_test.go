@@ -0,0 +1,116 @@
+package payment
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeProvider_Settlement(t *testing.T) {
+	provider := NewFakeProvider("http://localhost:8080")
+
+	link, err := provider.CreateUrlTransactionWithGateway(dto.PaymentGateway{OrderId: "OID-1", GrossAmt: 75000})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "http://localhost:8080/api/v1/fake-payments/OID-1", link)
+
+	// an order id can only be used once
+	link, err = provider.CreateUrlTransactionWithGateway(dto.PaymentGateway{OrderId: "OID-1", GrossAmt: 75000})
+
+	assert.Equal(t, "", link)
+	assert.True(t, errors.Is(err, pkg.ErrGatewayDuplicateOrder))
+	assert.True(t, errors.Is(err, pkg.ErrPaymentGateway))
+
+	status, err := provider.CheckTransaction("OID-1")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "pending", status.TransactionStatus)
+	assert.Equal(t, "75000.00", status.GrossAmount)
+
+	_, err = provider.Simulate("OID-1", "settlement", "gopay")
+
+	assert.Nil(t, err)
+
+	status, _ = provider.CheckTransaction("OID-1")
+
+	assert.Equal(t, "settlement", status.TransactionStatus)
+	assert.Equal(t, "accept", status.FraudStatus)
+	assert.Equal(t, "gopay", status.PaymentType)
+
+	// a settled transaction can not be paid or canceled again
+	_, err = provider.Simulate("OID-1", "expire", "gopay")
+	assert.True(t, errors.Is(err, pkg.ErrPaymentGateway))
+
+	err = provider.CancelTransaction("OID-1")
+	assert.True(t, errors.Is(err, pkg.ErrPaymentGateway))
+}
+
+func TestFakeProvider_Simulate(t *testing.T) {
+	provider := NewFakeProvider("")
+
+	for _, transactionStatus := range []string{"deny", "cancel", "expire"} {
+		provider.CreateUrlTransactionWithGateway(dto.PaymentGateway{OrderId: transactionStatus, GrossAmt: 10000})
+
+		status, err := provider.Simulate(transactionStatus, transactionStatus, "bank_transfer")
+
+		assert.Nil(t, err)
+		assert.Equal(t, transactionStatus, status.TransactionStatus)
+		assert.Equal(t, "", status.FraudStatus)
+	}
+
+	provider.CreateUrlTransactionWithGateway(dto.PaymentGateway{OrderId: "OID-2", GrossAmt: 10000})
+
+	_, err := provider.Simulate("OID-2", "paid", "bank_transfer")
+	assert.True(t, errors.Is(err, pkg.ErrPaymentGateway))
+
+	_, err = provider.Simulate("OID-3", "settlement", "bank_transfer")
+	assert.Equal(t, pkg.ErrRecordNotFound, err)
+}
+
+func TestFakeProvider_Cancel(t *testing.T) {
+	provider := NewFakeProvider("")
+
+	// like midtrans, an unknown transaction has nothing to cancel
+	assert.Nil(t, provider.CancelTransaction("OID-1"))
+
+	provider.CreateUrlTransactionWithGateway(dto.PaymentGateway{OrderId: "OID-1", GrossAmt: 10000})
+
+	assert.Nil(t, provider.CancelTransaction("OID-1"))
+
+	status, _ := provider.CheckTransaction("OID-1")
+	assert.Equal(t, "cancel", status.TransactionStatus)
+}
+
+func TestFakeProvider_Refund(t *testing.T) {
+	provider := NewFakeProvider("")
+
+	provider.CreateUrlTransactionWithGateway(dto.PaymentGateway{OrderId: "OID-1", GrossAmt: 100000})
+
+	// nothing is captured yet
+	err := provider.RefundTransaction("OID-1", "RF-1", 10000, "order canceled by customer")
+	assert.True(t, errors.Is(err, pkg.ErrPaymentGateway))
+
+	_, _ = provider.Simulate("OID-1", "settlement", "bank_transfer")
+
+	assert.Nil(t, provider.RefundTransaction("OID-1", "RF-1", 40000, "order canceled by customer"))
+
+	status, _ := provider.CheckTransaction("OID-1")
+	assert.Equal(t, "partial_refund", status.TransactionStatus)
+	assert.Equal(t, []TransactionRefund{{RefundKey: "RF-1", RefundAmount: "40000.00"}}, status.Refunds)
+
+	// the same refund key is not refunded twice
+	err = provider.RefundTransaction("OID-1", "RF-1", 40000, "order canceled by customer")
+	assert.True(t, errors.Is(err, pkg.ErrPaymentGateway))
+
+	err = provider.RefundTransaction("OID-1", "RF-2", 70000, "order canceled by customer")
+	assert.True(t, errors.Is(err, pkg.ErrPaymentGateway))
+
+	assert.Nil(t, provider.RefundTransaction("OID-1", "RF-2", 60000, "order canceled by customer"))
+
+	status, _ = provider.CheckTransaction("OID-1")
+	assert.Equal(t, "refund", status.TransactionStatus)
+	assert.Len(t, status.Refunds, 2)
+}
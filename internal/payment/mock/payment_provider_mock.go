@@ -0,0 +1,41 @@
+package paymentmock
+
+import (
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/payment"
+	"github.com/stretchr/testify/mock"
+)
+
+type PaymentProviderMock struct {
+	Mock mock.Mock
+}
+
+func (p *PaymentProviderMock) CreateTransaction(req dto.PaymentGateway) (string, error) {
+	ret := p.Mock.Called(req)
+
+	return ret.String(0), ret.Error(1)
+}
+
+func (p *PaymentProviderMock) CreateUrlTransactionWithGateway(req dto.PaymentGateway) (string, error) {
+	ret := p.Mock.Called(req)
+
+	return ret.String(0), ret.Error(1)
+}
+
+func (p *PaymentProviderMock) CheckTransaction(orderId string) (*payment.TransactionStatus, error) {
+	ret := p.Mock.Called(orderId)
+
+	return ret.Get(0).(*payment.TransactionStatus), ret.Error(1)
+}
+
+func (p *PaymentProviderMock) CancelTransaction(orderId string) error {
+	ret := p.Mock.Called(orderId)
+
+	return ret.Error(0)
+}
+
+func (p *PaymentProviderMock) RefundTransaction(orderId string, refundKey string, amount int64, reason string) error {
+	ret := p.Mock.Called(orderId, refundKey, amount, reason)
+
+	return ret.Error(0)
+}
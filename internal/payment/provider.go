@@ -0,0 +1,37 @@
+package payment
+
+import "github.com/arvinpaundra/go-rent-bike/internal/dto"
+
+const (
+	ProviderMidtrans = "midtrans"
+	ProviderFake     = "fake"
+)
+
+// TransactionStatus is the state of a transaction as the payment provider knows it,
+// written with the midtrans transaction statuses (pending, settlement, deny, cancel, expire, refund).
+type TransactionStatus struct {
+	OrderId           string              `json:"order_id"`
+	TransactionId     string              `json:"transaction_id"`
+	TransactionStatus string              `json:"transaction_status"`
+	FraudStatus       string              `json:"fraud_status"`
+	PaymentType       string              `json:"payment_type"`
+	StatusCode        string              `json:"status_code"`
+	GrossAmount       string              `json:"gross_amount"`
+	Refunds           []TransactionRefund `json:"refunds,omitempty"`
+}
+
+// TransactionRefund is a refund the payment provider made on a transaction, found by the refund key the app sent.
+type TransactionRefund struct {
+	RefundKey    string `json:"refund_key"`
+	RefundAmount string `json:"refund_amount"`
+}
+
+// PaymentProvider is a payment gateway, where the order id of a transaction is the id
+// the app sends when it creates the transaction. Failures of the gateway itself are returned as a *GatewayError.
+type PaymentProvider interface {
+	CreateTransaction(req dto.PaymentGateway) (string, error)
+	CreateUrlTransactionWithGateway(req dto.PaymentGateway) (string, error)
+	CheckTransaction(orderId string) (*TransactionStatus, error)
+	CancelTransaction(orderId string) error
+	RefundTransaction(orderId string, refundKey string, amount int64, reason string) error
+}
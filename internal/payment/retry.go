@@ -0,0 +1,134 @@
+package payment
+
+import (
+	"errors"
+	"time"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+)
+
+// RetryProvider sends a request again when the payment gateway failed for a moment, and waits twice as long
+// before every new attempt. A request is only sent again when the gateway surely did not act on it. After a
+// timeout it may have, so a cancel or a refund is first looked up in the transaction status, and a new payment
+// link is never asked twice, snap would refuse the order id it already knows.
+type RetryProvider struct {
+	provider PaymentProvider
+	attempts int
+	backoff  time.Duration
+	sleep    func(time.Duration)
+}
+
+func (p RetryProvider) CreateTransaction(req dto.PaymentGateway) (string, error) {
+	var token string
+
+	err := p.retry(func() (err error) {
+		token, err = p.provider.CreateTransaction(req)
+		return err
+	}, nil)
+
+	return token, err
+}
+
+func (p RetryProvider) CreateUrlTransactionWithGateway(req dto.PaymentGateway) (string, error) {
+	var url string
+
+	err := p.retry(func() (err error) {
+		url, err = p.provider.CreateUrlTransactionWithGateway(req)
+		return err
+	}, nil)
+
+	return url, err
+}
+
+func (p RetryProvider) CheckTransaction(orderId string) (*TransactionStatus, error) {
+	var transactionStatus *TransactionStatus
+
+	// checking changes nothing, it is always safe to ask again
+	err := p.retry(func() (err error) {
+		transactionStatus, err = p.provider.CheckTransaction(orderId)
+		return err
+	}, func() (bool, error) {
+		return false, nil
+	})
+
+	return transactionStatus, err
+}
+
+func (p RetryProvider) CancelTransaction(orderId string) error {
+	return p.retry(func() error {
+		return p.provider.CancelTransaction(orderId)
+	}, func() (bool, error) {
+		transactionStatus, err := p.provider.CheckTransaction(orderId)
+
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+
+		return transactionStatus.TransactionStatus == "cancel", nil
+	})
+}
+
+func (p RetryProvider) RefundTransaction(orderId string, refundKey string, amount int64, reason string) error {
+	return p.retry(func() error {
+		return p.provider.RefundTransaction(orderId, refundKey, amount, reason)
+	}, func() (bool, error) {
+		transactionStatus, err := p.provider.CheckTransaction(orderId)
+
+		if err != nil {
+			return false, err
+		}
+
+		for _, refund := range transactionStatus.Refunds {
+			if refund.RefundKey == refundKey {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	})
+}
+
+// retry sends fn until it succeeds, fails for good or runs out of attempts. A request the gateway may have
+// acted on is only sent again when applied tells it did not, and counts as done when applied tells it did.
+// Without applied such a request is never sent again.
+func (p RetryProvider) retry(fn func() error, applied func() (bool, error)) error {
+	backoff := p.backoff
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+
+		if err == nil || attempt >= p.attempts || !IsTemporary(err) {
+			return err
+		}
+
+		if !IsUnprocessed(err) && applied == nil {
+			return err
+		}
+
+		p.sleep(backoff)
+		backoff *= 2
+
+		if !IsUnprocessed(err) {
+			done, checkErr := applied()
+
+			if checkErr != nil {
+				return err
+			} else if done {
+				return nil
+			}
+		}
+	}
+}
+
+// NewRetryProvider retries the temporary failures of provider, sending every request at most attempts times.
+func NewRetryProvider(provider PaymentProvider, attempts int, backoff time.Duration) PaymentProvider {
+	return RetryProvider{
+		provider: provider,
+		attempts: attempts,
+		backoff:  backoff,
+		sleep:    time.Sleep,
+	}
+}
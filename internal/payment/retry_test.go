@@ -0,0 +1,169 @@
+package payment
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyProvider fails the first requests with errs before creating the transaction
+type flakyProvider struct {
+	FakeProvider
+	errs  []error
+	calls int
+}
+
+func (p *flakyProvider) CreateUrlTransactionWithGateway(req dto.PaymentGateway) (string, error) {
+	p.calls++
+
+	if p.calls <= len(p.errs) {
+		return "", p.errs[p.calls-1]
+	}
+
+	return "http://localhost:8080/api/v1/fake-payments/" + req.OrderId, nil
+}
+
+func newTestRetryProvider(provider PaymentProvider, attempts int) (RetryProvider, *[]time.Duration) {
+	var waits []time.Duration
+
+	return RetryProvider{
+		provider: provider,
+		attempts: attempts,
+		backoff:  100 * time.Millisecond,
+		sleep: func(d time.Duration) {
+			waits = append(waits, d)
+		},
+	}, &waits
+}
+
+func TestRetryProvider_RetriesUnprocessedRequests(t *testing.T) {
+	flaky := &flakyProvider{errs: []error{
+		&GatewayError{Kind: pkg.ErrGatewayUnreachable},
+		&GatewayError{Kind: pkg.ErrGatewayUnavailable, StatusCode: 503},
+	}}
+	provider, waits := newTestRetryProvider(flaky, 3)
+
+	link, err := provider.CreateUrlTransactionWithGateway(dto.PaymentGateway{OrderId: "OID-1", GrossAmt: 75000})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "http://localhost:8080/api/v1/fake-payments/OID-1", link)
+	assert.Equal(t, 3, flaky.calls)
+	assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}, *waits)
+}
+
+func TestRetryProvider_GivesUpAfterAttempts(t *testing.T) {
+	flaky := &flakyProvider{errs: []error{
+		&GatewayError{Kind: pkg.ErrGatewayUnreachable},
+		&GatewayError{Kind: pkg.ErrGatewayUnreachable},
+		&GatewayError{Kind: pkg.ErrGatewayUnreachable},
+	}}
+	provider, waits := newTestRetryProvider(flaky, 2)
+
+	link, err := provider.CreateUrlTransactionWithGateway(dto.PaymentGateway{OrderId: "OID-1", GrossAmt: 75000})
+
+	assert.Equal(t, "", link)
+	assert.True(t, errors.Is(err, pkg.ErrGatewayUnreachable))
+	assert.Equal(t, 2, flaky.calls)
+	assert.Len(t, *waits, 1)
+}
+
+func TestRetryProvider_DoesNotRetryRejectedRequests(t *testing.T) {
+	flaky := &flakyProvider{errs: []error{
+		&GatewayError{Kind: pkg.ErrGatewayInvalidRequest, StatusCode: 400},
+	}}
+	provider, waits := newTestRetryProvider(flaky, 3)
+
+	_, err := provider.CreateUrlTransactionWithGateway(dto.PaymentGateway{OrderId: "OID-1", GrossAmt: 75000})
+
+	assert.True(t, errors.Is(err, pkg.ErrGatewayInvalidRequest))
+	assert.True(t, errors.Is(err, pkg.ErrPaymentGateway))
+	assert.Equal(t, 1, flaky.calls)
+	assert.Empty(t, *waits)
+}
+
+func TestRetryProvider_DoesNotResendPaymentLinkAfterTimeout(t *testing.T) {
+	flaky := &flakyProvider{errs: []error{
+		&GatewayError{Kind: pkg.ErrGatewayTimeout, StatusCode: 504},
+	}}
+	provider, waits := newTestRetryProvider(flaky, 3)
+
+	// snap may have made the link, asking again with the same order id would only be refused
+	_, err := provider.CreateUrlTransactionWithGateway(dto.PaymentGateway{OrderId: "OID-1", GrossAmt: 75000})
+
+	assert.True(t, errors.Is(err, pkg.ErrGatewayTimeout))
+	assert.Equal(t, 1, flaky.calls)
+	assert.Empty(t, *waits)
+}
+
+// lostRefundProvider times out on the first refunds, after the fake provider made them when applied is set
+type lostRefundProvider struct {
+	*FakeProvider
+	timeouts int
+	applied  bool
+	calls    int
+}
+
+func (p *lostRefundProvider) RefundTransaction(orderId string, refundKey string, amount int64, reason string) error {
+	p.calls++
+
+	if p.calls > p.timeouts {
+		return p.FakeProvider.RefundTransaction(orderId, refundKey, amount, reason)
+	}
+
+	if p.applied {
+		if err := p.FakeProvider.RefundTransaction(orderId, refundKey, amount, reason); err != nil {
+			return err
+		}
+	}
+
+	return &GatewayError{Kind: pkg.ErrGatewayTimeout, StatusCode: 504}
+}
+
+func TestRetryProvider_RefundAfterTimeout(t *testing.T) {
+	testCases := []struct {
+		name          string
+		applied       bool
+		expectedCalls int
+	}{
+		{
+			name:          "refund made before the response was lost is not sent again",
+			applied:       true,
+			expectedCalls: 1,
+		},
+		{
+			name:          "refund not made is sent again",
+			applied:       false,
+			expectedCalls: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := NewFakeProvider("http://localhost:8080")
+
+			_, err := fake.CreateTransaction(dto.PaymentGateway{OrderId: "OID-REFUND", GrossAmt: 75000})
+			assert.Nil(t, err)
+
+			_, err = fake.Simulate("OID-REFUND", "settlement", "gopay")
+			assert.Nil(t, err)
+
+			lost := &lostRefundProvider{FakeProvider: fake, timeouts: 1, applied: tc.applied}
+			provider, waits := newTestRetryProvider(lost, 3)
+
+			err = provider.RefundTransaction("OID-REFUND", "RKEY-1", 25000, "bike was damaged")
+
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expectedCalls, lost.calls)
+			assert.Len(t, *waits, 1)
+
+			transaction, err := fake.CheckTransaction("OID-REFUND")
+			assert.Nil(t, err)
+			assert.Len(t, transaction.Refunds, 1)
+			assert.Equal(t, "partial_refund", transaction.TransactionStatus)
+		})
+	}
+}
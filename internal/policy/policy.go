@@ -0,0 +1,156 @@
+package policy
+
+import (
+	"errors"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+)
+
+// Policy decides whether the actor of a request may act on a resource, by following the resource back to
+// the user owning it. Every method returns pkg.ErrForbidden when the actor is not that user and
+// pkg.ErrRecordNotFound when the resource does not exist.
+type Policy interface {
+	AuthorizeCustomer(actorId string, userId string) error
+	AuthorizeRenter(actorId string, renterId string) error
+	AuthorizeBike(actorId string, bikeId string) error
+	AuthorizeOrder(actorId string, orderId string) error
+	AuthorizeOrderCustomer(actorId string, orderId string) error
+	AuthorizeOrderRenter(actorId string, orderId string) error
+	AuthorizeCustomerOrder(actorId string, userId string, orderId string) error
+}
+
+type ownershipPolicy struct {
+	renterRepository repository.RenterRepository
+	bikeRepository   repository.BikeRepository
+	orderRepository  repository.OrderRepository
+}
+
+// AuthorizeCustomer only lets users act on their own account.
+func (p ownershipPolicy) AuthorizeCustomer(actorId string, userId string) error {
+	if actorId == "" || actorId != userId {
+		return pkg.ErrForbidden
+	}
+
+	return nil
+}
+
+// AuthorizeRenter only lets the user that registered a renter act on it.
+func (p ownershipPolicy) AuthorizeRenter(actorId string, renterId string) error {
+	renter, err := p.renterRepository.FindById(renterId)
+
+	if err != nil {
+		return err
+	}
+
+	return p.AuthorizeCustomer(actorId, renter.UserId)
+}
+
+// AuthorizeBike only lets the user of the renter renting a bike out act on it.
+func (p ownershipPolicy) AuthorizeBike(actorId string, bikeId string) error {
+	bike, err := p.bikeRepository.FindById(bikeId)
+
+	if err != nil {
+		return err
+	}
+
+	return p.AuthorizeRenter(actorId, bike.RenterId)
+}
+
+// AuthorizeOrder lets both the customer of an order and the renters of the bikes in it act on it, it is
+// meant for what both sides of an order may read.
+func (p ownershipPolicy) AuthorizeOrder(actorId string, orderId string) error {
+	order, err := p.orderRepository.FindById(orderId)
+
+	if err != nil {
+		return err
+	}
+
+	if isOrderCustomer(actorId, *order) {
+		return nil
+	}
+
+	return p.authorizeOrderRenter(actorId, *order)
+}
+
+// AuthorizeOrderCustomer only lets the customer that placed an order act on it.
+func (p ownershipPolicy) AuthorizeOrderCustomer(actorId string, orderId string) error {
+	order, err := p.orderRepository.FindById(orderId)
+
+	if err != nil {
+		return err
+	}
+
+	if !isOrderCustomer(actorId, *order) {
+		return pkg.ErrForbidden
+	}
+
+	return nil
+}
+
+// AuthorizeOrderRenter only lets the renters of the bikes in an order act on it.
+func (p ownershipPolicy) AuthorizeOrderRenter(actorId string, orderId string) error {
+	order, err := p.orderRepository.FindById(orderId)
+
+	if err != nil {
+		return err
+	}
+
+	return p.authorizeOrderRenter(actorId, *order)
+}
+
+func isOrderCustomer(actorId string, order model.Order) bool {
+	return actorId != "" && order.UserId == actorId
+}
+
+func (p ownershipPolicy) authorizeOrderRenter(actorId string, order model.Order) error {
+	renter, err := p.renterRepository.FindByIdUser(actorId)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return pkg.ErrForbidden
+		}
+
+		return err
+	}
+
+	for _, detail := range order.OrderDetails {
+		if detail.Bike != nil && detail.Bike.RenterId == renter.ID {
+			return nil
+		}
+	}
+
+	return pkg.ErrForbidden
+}
+
+// AuthorizeCustomerOrder only lets customers see their own orders, through their own account.
+func (p ownershipPolicy) AuthorizeCustomerOrder(actorId string, userId string, orderId string) error {
+	if err := p.AuthorizeCustomer(actorId, userId); err != nil {
+		return err
+	}
+
+	order, err := p.orderRepository.FindById(orderId)
+
+	if err != nil {
+		return err
+	}
+
+	if order.UserId != userId {
+		return pkg.ErrForbidden
+	}
+
+	return nil
+}
+
+func NewPolicy(
+	renterRepo repository.RenterRepository,
+	bikeRepo repository.BikeRepository,
+	orderRepo repository.OrderRepository,
+) Policy {
+	return ownershipPolicy{
+		renterRepository: renterRepo,
+		bikeRepository:   bikeRepo,
+		orderRepository:  orderRepo,
+	}
+}
@@ -0,0 +1,208 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	repomock "github.com/arvinpaundra/go-rent-bike/internal/repository/gormdb/mock"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	customerId    = "8b0e6c2f-8d5d-4a51-9d0a-3f6a9d3a4c11"
+	renterUserId  = "2f7c1a8e-5b3d-4c9e-8a61-0d4e7b2c9f32"
+	otherUserId   = "c5d9e3b1-7a2f-4e8c-b613-9f0a2d4c6e53"
+	renterId      = "4a6e8c0b-2d4f-4618-8a0c-e2b4d6f8a174"
+	otherRenterId = "9e1c3a5f-7b9d-4f1b-a3c5-e7091b3d5f95"
+	bikeId        = "1d3f5b7e-9a1c-4e3f-85a7-c9e1b3d5f716"
+	orderId       = "6f8a0c2e-4b6d-4f8a-9c0e-2a4c6e8a0b37"
+	missingId     = "00000000-0000-0000-0000-000000000000"
+)
+
+func newTestPolicy() Policy {
+	renterRepository := &repomock.RenterRepositoryMock{Mock: mock.Mock{}}
+	bikeRepository := &repomock.BikeRepositoryMock{Mock: mock.Mock{}}
+	orderRepository := &repomock.OrderRepositoryMock{Mock: mock.Mock{}}
+
+	renter := &model.Renter{ID: renterId, UserId: renterUserId}
+	otherRenter := &model.Renter{ID: otherRenterId, UserId: otherUserId}
+	bike := &model.Bike{ID: bikeId, RenterId: renterId}
+	order := &model.Order{
+		ID:     orderId,
+		UserId: customerId,
+		OrderDetails: []model.OrderDetail{
+			{BikeId: bikeId, Bike: bike},
+		},
+	}
+
+	renterRepository.Mock.On("FindById", renterId).Return(renter, nil)
+	renterRepository.Mock.On("FindById", mock.Anything).Return(&model.Renter{}, pkg.ErrRecordNotFound)
+	renterRepository.Mock.On("FindByIdUser", renterUserId).Return(renter, nil)
+	renterRepository.Mock.On("FindByIdUser", otherUserId).Return(otherRenter, nil)
+	renterRepository.Mock.On("FindByIdUser", mock.Anything).Return(&model.Renter{}, pkg.ErrRecordNotFound)
+	bikeRepository.Mock.On("FindById", bikeId).Return(bike, nil)
+	bikeRepository.Mock.On("FindById", mock.Anything).Return(&model.Bike{}, pkg.ErrRecordNotFound)
+	orderRepository.Mock.On("FindById", orderId).Return(order, nil)
+	orderRepository.Mock.On("FindById", mock.Anything).Return(&model.Order{}, pkg.ErrRecordNotFound)
+
+	return NewPolicy(renterRepository, bikeRepository, orderRepository)
+}
+
+func TestPolicy_AuthorizeCustomer(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		ActorId string
+		UserId  string
+		Err     error
+	}{
+		{"own account", customerId, customerId, nil},
+		{"another account", otherUserId, customerId, pkg.ErrForbidden},
+		{"no actor", "", "", pkg.ErrForbidden},
+	}
+
+	p := newTestPolicy()
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.ErrorIs(t, p.AuthorizeCustomer(tc.ActorId, tc.UserId), tc.Err)
+		})
+	}
+}
+
+func TestPolicy_AuthorizeRenter(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		ActorId  string
+		RenterId string
+		Err      error
+	}{
+		{"own renter", renterUserId, renterId, nil},
+		{"another renter", otherUserId, renterId, pkg.ErrForbidden},
+		{"customer", customerId, renterId, pkg.ErrForbidden},
+		{"renter not found", renterUserId, missingId, pkg.ErrRecordNotFound},
+	}
+
+	p := newTestPolicy()
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.ErrorIs(t, p.AuthorizeRenter(tc.ActorId, tc.RenterId), tc.Err)
+		})
+	}
+}
+
+func TestPolicy_AuthorizeBike(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		ActorId string
+		BikeId  string
+		Err     error
+	}{
+		{"own bike", renterUserId, bikeId, nil},
+		{"bike of another renter", otherUserId, bikeId, pkg.ErrForbidden},
+		{"customer", customerId, bikeId, pkg.ErrForbidden},
+		{"bike not found", renterUserId, missingId, pkg.ErrRecordNotFound},
+	}
+
+	p := newTestPolicy()
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.ErrorIs(t, p.AuthorizeBike(tc.ActorId, tc.BikeId), tc.Err)
+		})
+	}
+}
+
+func TestPolicy_AuthorizeOrder(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		ActorId string
+		OrderId string
+		Err     error
+	}{
+		{"customer of the order", customerId, orderId, nil},
+		{"renter of a bike in the order", renterUserId, orderId, nil},
+		{"renter of no bike in the order", otherUserId, orderId, pkg.ErrForbidden},
+		{"no actor", "", orderId, pkg.ErrForbidden},
+		{"order not found", customerId, missingId, pkg.ErrRecordNotFound},
+	}
+
+	p := newTestPolicy()
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.ErrorIs(t, p.AuthorizeOrder(tc.ActorId, tc.OrderId), tc.Err)
+		})
+	}
+}
+
+func TestPolicy_AuthorizeOrderCustomer(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		ActorId string
+		OrderId string
+		Err     error
+	}{
+		{"customer of the order", customerId, orderId, nil},
+		{"renter of a bike in the order", renterUserId, orderId, pkg.ErrForbidden},
+		{"renter of no bike in the order", otherUserId, orderId, pkg.ErrForbidden},
+		{"no actor", "", orderId, pkg.ErrForbidden},
+		{"order not found", customerId, missingId, pkg.ErrRecordNotFound},
+	}
+
+	p := newTestPolicy()
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.ErrorIs(t, p.AuthorizeOrderCustomer(tc.ActorId, tc.OrderId), tc.Err)
+		})
+	}
+}
+
+func TestPolicy_AuthorizeOrderRenter(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		ActorId string
+		OrderId string
+		Err     error
+	}{
+		{"renter of a bike in the order", renterUserId, orderId, nil},
+		{"customer of the order", customerId, orderId, pkg.ErrForbidden},
+		{"renter of no bike in the order", otherUserId, orderId, pkg.ErrForbidden},
+		{"no actor", "", orderId, pkg.ErrForbidden},
+		{"order not found", renterUserId, missingId, pkg.ErrRecordNotFound},
+	}
+
+	p := newTestPolicy()
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.ErrorIs(t, p.AuthorizeOrderRenter(tc.ActorId, tc.OrderId), tc.Err)
+		})
+	}
+}
+
+func TestPolicy_AuthorizeCustomerOrder(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		ActorId string
+		UserId  string
+		OrderId string
+		Err     error
+	}{
+		{"own order", customerId, customerId, orderId, nil},
+		{"order of another customer", renterUserId, renterUserId, orderId, pkg.ErrForbidden},
+		{"another account", otherUserId, customerId, orderId, pkg.ErrForbidden},
+		{"order not found", customerId, customerId, missingId, pkg.ErrRecordNotFound},
+	}
+
+	p := newTestPolicy()
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.ErrorIs(t, p.AuthorizeCustomerOrder(tc.ActorId, tc.UserId, tc.OrderId), tc.Err)
+		})
+	}
+}
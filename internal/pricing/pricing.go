@@ -0,0 +1,211 @@
+package pricing
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	UnitHour = "hour"
+	UnitDay  = "day"
+	UnitWeek = "week"
+
+	hoursPerDay  = 24
+	hoursPerWeek = 7 * hoursPerDay
+)
+
+// Rates is the rate table of a bike. A zero daily or weekly rate is not offered,
+// and a zero multiplier leaves the price of weekends or holidays as it is.
+type Rates struct {
+	Hourly            float32
+	Daily             float32
+	Weekly            float32
+	WeekendMultiplier float32
+	HolidayMultiplier float32
+}
+
+// Rule multiplies the price of every hour that starts inside its window, e.g. a high season set by a renter.
+type Rule struct {
+	Name       string
+	StartAt    time.Time
+	EndAt      time.Time
+	Multiplier float32
+}
+
+type Line struct {
+	Description string    `json:"description"`
+	StartAt     time.Time `json:"start_at"`
+	EndAt       time.Time `json:"end_at"`
+	Hours       int       `json:"hours"`
+	Unit        string    `json:"unit"`
+	BasePrice   float32   `json:"base_price"`
+	Multiplier  float32   `json:"multiplier"`
+	Adjustments []string  `json:"adjustments,omitempty"`
+	Amount      float32   `json:"amount"`
+}
+
+type Quote struct {
+	StartAt   time.Time `json:"start_at"`
+	EndAt     time.Time `json:"end_at"`
+	TotalHour int       `json:"total_hour"`
+	Lines     []Line    `json:"lines"`
+	Total     float32   `json:"total"`
+}
+
+type Engine struct {
+	holidays map[string]bool
+}
+
+// Quote prices the rent window in blocks: a full week at the weekly rate, then days capped at the daily rate,
+// and the hours left over. Each block is charged at the average multiplier of the hours inside it,
+// where the weekend, holiday and rule multipliers of one hour are multiplied together.
+func (e Engine) Quote(rates Rates, rules []Rule, startAt time.Time, endAt time.Time) Quote {
+	totalHour := int(math.Ceil(endAt.Sub(startAt).Hours()))
+
+	quote := Quote{
+		StartAt:   startAt,
+		EndAt:     endAt,
+		TotalHour: totalHour,
+		Lines:     []Line{},
+	}
+
+	for offset := 0; offset < totalHour; {
+		hours, unit, basePrice := nextBlock(rates, totalHour-offset)
+
+		blockStartAt := startAt.Add(time.Duration(offset) * time.Hour)
+		multiplier, adjustments := e.blockMultiplier(rates, rules, blockStartAt, hours)
+
+		line := Line{
+			Description: describeBlock(hours, unit),
+			StartAt:     blockStartAt,
+			EndAt:       blockStartAt.Add(time.Duration(hours) * time.Hour),
+			Hours:       hours,
+			Unit:        unit,
+			BasePrice:   basePrice,
+			Multiplier:  multiplier,
+			Adjustments: adjustments,
+			Amount:      float32(math.Round(float64(basePrice * multiplier))),
+		}
+
+		quote.Lines = append(quote.Lines, line)
+		quote.Total += line.Amount
+
+		offset += hours
+	}
+
+	return quote
+}
+
+// nextBlock picks the biggest block that fits into the hours left and prices it without multipliers.
+func nextBlock(rates Rates, hoursLeft int) (int, string, float32) {
+	if hoursLeft >= hoursPerWeek && rates.Weekly > 0 {
+		dailyPrice, _ := dayPrice(rates, hoursPerDay)
+
+		if weekByDays := 7 * dailyPrice; weekByDays < rates.Weekly {
+			return hoursPerWeek, UnitDay, weekByDays
+		}
+
+		return hoursPerWeek, UnitWeek, rates.Weekly
+	}
+
+	hours := hoursLeft
+	if hours > hoursPerDay {
+		hours = hoursPerDay
+	}
+
+	price, unit := dayPrice(rates, hours)
+
+	return hours, unit, price
+}
+
+// dayPrice charges the hours of one day by the hour, up to the daily rate.
+func dayPrice(rates Rates, hours int) (float32, string) {
+	price := rates.Hourly * float32(hours)
+
+	if rates.Daily > 0 && rates.Daily < price {
+		return rates.Daily, UnitDay
+	}
+
+	return price, UnitHour
+}
+
+func (e Engine) blockMultiplier(rates Rates, rules []Rule, blockStartAt time.Time, hours int) (float32, []string) {
+	var sum float64
+
+	applied := map[string]bool{}
+	adjustments := []string{}
+
+	apply := func(name string) {
+		if !applied[name] {
+			applied[name] = true
+			adjustments = append(adjustments, name)
+		}
+	}
+
+	for h := 0; h < hours; h++ {
+		at := blockStartAt.Add(time.Duration(h) * time.Hour)
+		multiplier := float64(1)
+
+		if weekday := at.Weekday(); rates.WeekendMultiplier > 0 && (weekday == time.Saturday || weekday == time.Sunday) {
+			multiplier *= float64(rates.WeekendMultiplier)
+			apply("weekend")
+		}
+
+		if rates.HolidayMultiplier > 0 && e.holidays[at.Format("2006-01-02")] {
+			multiplier *= float64(rates.HolidayMultiplier)
+			apply("holiday")
+		}
+
+		for i := range rules {
+			if rules[i].Multiplier > 0 && !at.Before(rules[i].StartAt) && at.Before(rules[i].EndAt) {
+				multiplier *= float64(rules[i].Multiplier)
+				apply(rules[i].Name)
+			}
+		}
+
+		sum += multiplier
+	}
+
+	if len(adjustments) == 0 {
+		return 1, nil
+	}
+
+	// keep the multiplier readable in the breakdown, e.g. 1.25 instead of 1.2499999
+	average := math.Round(sum/float64(hours)*10000) / 10000
+
+	return float32(average), adjustments
+}
+
+func describeBlock(hours int, unit string) string {
+	switch unit {
+	case UnitWeek:
+		return "1 week"
+	case UnitDay:
+		if hours == hoursPerWeek {
+			return "7 days"
+		}
+
+		return "1 day"
+	}
+
+	if hours == 1 {
+		return "1 hour"
+	}
+
+	return fmt.Sprintf("%d hours", hours)
+}
+
+// NewEngine creates a pricing engine with the platform holidays, written as YYYY-MM-DD dates.
+func NewEngine(holidays []string) Engine {
+	days := map[string]bool{}
+
+	for i := range holidays {
+		if day := strings.TrimSpace(holidays[i]); day != "" {
+			days[day] = true
+		}
+	}
+
+	return Engine{holidays: days}
+}
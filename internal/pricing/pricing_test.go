@@ -0,0 +1,125 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngine_Quote(t *testing.T) {
+	engine := NewEngine([]string{"2022-12-25", " "})
+
+	// a monday
+	monday := time.Date(2022, 11, 21, 8, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		Name          string
+		Rates         Rates
+		Rules         []Rule
+		StartAt       time.Time
+		EndAt         time.Time
+		ExpectedUnits []string
+		ExpectedTotal float32
+	}{
+		{
+			Name:          "hourly only",
+			Rates:         Rates{Hourly: 15000},
+			StartAt:       monday,
+			EndAt:         monday.Add(4*time.Hour + 30*time.Minute),
+			ExpectedUnits: []string{UnitHour},
+			ExpectedTotal: 75000,
+		},
+		{
+			Name:          "daily cap",
+			Rates:         Rates{Hourly: 15000, Daily: 100000},
+			StartAt:       monday,
+			EndAt:         monday.Add(48 * time.Hour),
+			ExpectedUnits: []string{UnitDay, UnitDay},
+			ExpectedTotal: 200000,
+		},
+		{
+			Name:          "hours left under the daily cap",
+			Rates:         Rates{Hourly: 15000, Daily: 100000},
+			StartAt:       monday,
+			EndAt:         monday.Add(26 * time.Hour),
+			ExpectedUnits: []string{UnitDay, UnitHour},
+			ExpectedTotal: 130000,
+		},
+		{
+			Name:          "weekly rate",
+			Rates:         Rates{Hourly: 15000, Daily: 100000, Weekly: 500000},
+			StartAt:       monday,
+			EndAt:         monday.Add(8 * 24 * time.Hour),
+			ExpectedUnits: []string{UnitWeek, UnitDay},
+			ExpectedTotal: 600000,
+		},
+		{
+			Name:          "weekly rate above seven days",
+			Rates:         Rates{Hourly: 15000, Daily: 50000, Weekly: 500000},
+			StartAt:       monday,
+			EndAt:         monday.Add(7 * 24 * time.Hour),
+			ExpectedUnits: []string{UnitDay},
+			ExpectedTotal: 350000,
+		},
+		{
+			Name:          "weekend multiplier",
+			Rates:         Rates{Hourly: 10000, WeekendMultiplier: 1.5},
+			StartAt:       monday.Add(5 * 24 * time.Hour),
+			EndAt:         monday.Add(5*24*time.Hour + 2*time.Hour),
+			ExpectedUnits: []string{UnitHour},
+			ExpectedTotal: 30000,
+		},
+		{
+			Name:          "holiday on a weekend",
+			Rates:         Rates{Hourly: 10000, WeekendMultiplier: 1.5, HolidayMultiplier: 2},
+			StartAt:       time.Date(2022, 12, 25, 8, 0, 0, 0, time.UTC),
+			EndAt:         time.Date(2022, 12, 25, 10, 0, 0, 0, time.UTC),
+			ExpectedUnits: []string{UnitHour},
+			ExpectedTotal: 60000,
+		},
+		{
+			Name:  "seasonal rule on part of the window",
+			Rates: Rates{Hourly: 10000},
+			Rules: []Rule{
+				{Name: "high season", StartAt: monday.Add(2 * time.Hour), EndAt: monday.Add(30 * 24 * time.Hour), Multiplier: 2},
+			},
+			StartAt:       monday,
+			EndAt:         monday.Add(4 * time.Hour),
+			ExpectedUnits: []string{UnitHour},
+			ExpectedTotal: 60000,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			quote := engine.Quote(tc.Rates, tc.Rules, tc.StartAt, tc.EndAt)
+
+			units := []string{}
+			var sum float32
+			for i := range quote.Lines {
+				units = append(units, quote.Lines[i].Unit)
+				sum += quote.Lines[i].Amount
+			}
+
+			assert.Equal(t, tc.ExpectedUnits, units)
+			assert.Equal(t, tc.ExpectedTotal, quote.Total)
+			assert.Equal(t, quote.Total, sum)
+			assert.Equal(t, tc.StartAt.Add(time.Duration(quote.TotalHour)*time.Hour), quote.Lines[len(quote.Lines)-1].EndAt)
+		})
+	}
+}
+
+func TestEngine_QuoteAdjustments(t *testing.T) {
+	engine := NewEngine(nil)
+
+	// friday evening into saturday
+	startAt := time.Date(2022, 11, 25, 22, 0, 0, 0, time.UTC)
+
+	quote := engine.Quote(Rates{Hourly: 10000, WeekendMultiplier: 2}, nil, startAt, startAt.Add(4*time.Hour))
+
+	assert.Len(t, quote.Lines, 1)
+	assert.Equal(t, []string{"weekend"}, quote.Lines[0].Adjustments)
+	assert.Equal(t, float32(1.5), quote.Lines[0].Multiplier)
+	assert.Equal(t, float32(60000), quote.Total)
+}
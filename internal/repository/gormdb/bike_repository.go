@@ -2,11 +2,14 @@ package gormdb
 
 import (
 	"errors"
+	"time"
+
 	"github.com/arvinpaundra/go-rent-bike/pkg"
 
 	"github.com/arvinpaundra/go-rent-bike/internal/model"
 	"github.com/arvinpaundra/go-rent-bike/internal/repository"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type BikeRepository struct {
@@ -51,6 +54,37 @@ func (r BikeRepository) FindById(bikeId string) (*model.Bike, error) {
 	return bike, nil
 }
 
+func (r BikeRepository) FindByIdsForUpdate(bikeIds []string) (*[]model.Bike, error) {
+	bikes := &[]model.Bike{}
+
+	// rows are locked in id order so concurrent bookings of the same bikes can not deadlock
+	err := r.DB.Model(&model.Bike{}).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id IN ?", bikeIds).Order("id").Find(&bikes).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return bikes, nil
+}
+
+// FindByIdOrderForUpdate locks the bikes of an order, in the same id order as FindByIdsForUpdate.
+func (r BikeRepository) FindByIdOrderForUpdate(orderId string) (*[]model.Bike, error) {
+	bikes := &[]model.Bike{}
+
+	err := r.DB.Model(&model.Bike{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Joins("JOIN order_details ON order_details.bike_id = bikes.id").
+		Where("order_details.order_id = ?", orderId).
+		Order("bikes.id").
+		Find(&bikes).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return bikes, nil
+}
+
 func (r BikeRepository) FindByIdRenter(renterId string) (*[]model.Bike, error) {
 	bikes := &[]model.Bike{}
 
@@ -83,6 +117,25 @@ func (r BikeRepository) FindByIdCategory(categoryId string) (*[]model.Bike, erro
 	return bikes, nil
 }
 
+func (r BikeRepository) FindOverlappingOrders(bikeId string, startAt time.Time, endAt time.Time) (*[]model.Order, error) {
+	orders := &[]model.Order{}
+
+	// two windows overlap when each one starts before the other ends, an order waiting for its extension
+	// to be paid already holds its bikes until the extended end. Orders that no longer hold their bikes are left out
+	err := r.DB.Model(&model.Order{}).
+		Joins("JOIN order_details ON order_details.order_id = orders.id").
+		Where("order_details.bike_id = ? AND orders.start_at < ? AND COALESCE(orders.pending_end_at, orders.end_at) > ?", bikeId, endAt, startAt).
+		Where("orders.status IN ?", []model.OrderStatus{model.OrderStatusPendingPayment, model.OrderStatusPaid, model.OrderStatusPickedUp}).
+		Order("orders.start_at").
+		Find(&orders).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
 func (r BikeRepository) Update(bikeId string, bikeUC model.Bike) error {
 	err := r.DB.Model(&model.Bike{}).Where("id = ?", bikeId).Updates(&bikeUC).Error
 
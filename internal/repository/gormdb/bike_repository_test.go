@@ -53,8 +53,8 @@ func (s *suiteBike) TestCreate() {
 	}
 
 	s.mock.ExpectBegin()
-	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `bikes` (`id`,`renter_id`,`category_id`,`name`,`price_per_hour`,`condition`,`description`,`is_available`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?,?)")).
-		WithArgs("BID-1", "RID-1", "CID-1", "Sample Mountain Bike", float64(15000), "Perfect", "Bike descriptions.", "1", pkg.Anytime{}, pkg.Anytime{}).
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `bikes` (`id`,`renter_id`,`category_id`,`name`,`price_per_hour`,`price_per_day`,`price_per_week`,`weekend_multiplier`,`holiday_multiplier`,`deposit`,`condition`,`description`,`is_available`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)")).
+		WithArgs("BID-1", "RID-1", "CID-1", "Sample Mountain Bike", float64(15000), float64(0), float64(0), float64(0), float64(0), float64(0), "Perfect", "Bike descriptions.", "1", pkg.Anytime{}, pkg.Anytime{}).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	s.mock.ExpectCommit()
 
@@ -180,6 +180,51 @@ func (s *suiteBike) TestFindById() {
 	s.Equal(bike.IsAvailable, result.IsAvailable)
 }
 
+func (s *suiteBike) TestFindByIdsForUpdate() {
+	bike := model.Bike{
+		ID:           "BID-1",
+		RenterId:     "RID-1",
+		CategoryId:   "CID-1",
+		Name:         "Sample Mountain Bike",
+		PricePerHour: 15000,
+		Condition:    "Perfect",
+		Description:  "Bike descriptions.",
+		IsAvailable:  "1",
+	}
+
+	bikeRow := sqlmock.NewRows([]string{"id", "renter_id", "category_id", "name", "price_per_hour", "condition", "description", "is_available"}).
+		AddRow(bike.ID, bike.RenterId, bike.CategoryId, bike.Name, bike.PricePerHour, bike.Condition, bike.Description, bike.IsAvailable)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `bikes` WHERE id IN (?,?) ORDER BY id FOR UPDATE")).
+		WithArgs("BID-1", "BID-2").
+		WillReturnRows(bikeRow)
+
+	results, err := s.bikeRepository.FindByIdsForUpdate([]string{"BID-1", "BID-2"})
+
+	s.Nil(err)
+	s.NotNil(results)
+
+	s.Len(*results, 1)
+	s.Equal(bike.ID, (*results)[0].ID)
+}
+
+func (s *suiteBike) TestFindByIdOrderForUpdate() {
+	bikeRow := sqlmock.NewRows([]string{"id", "renter_id", "category_id", "name", "price_per_hour", "is_available"}).
+		AddRow("BID-1", "RID-1", "CID-1", "Sample Mountain Bike", float32(15000), "1")
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("FROM `bikes` JOIN order_details ON order_details.bike_id = bikes.id WHERE order_details.order_id = ? ORDER BY bikes.id FOR UPDATE")).
+		WithArgs("OID-1").
+		WillReturnRows(bikeRow)
+
+	results, err := s.bikeRepository.FindByIdOrderForUpdate("OID-1")
+
+	s.Nil(err)
+	s.NotNil(results)
+
+	s.Len(*results, 1)
+	s.Equal("BID-1", (*results)[0].ID)
+}
+
 func (s *suiteBike) TestFindByIdRenter() {
 	bike := model.Bike{
 		ID:           "BID-1",
@@ -280,6 +325,40 @@ func (s *suiteBike) TestFindByIdCategory() {
 	s.Equal(bike.IsAvailable, (*results)[0].IsAvailable)
 }
 
+func (s *suiteBike) TestFindOverlappingOrders() {
+	startAt := time.Date(2022, 11, 20, 8, 0, 0, 0, time.UTC)
+	endAt := time.Date(2022, 11, 20, 12, 0, 0, 0, time.UTC)
+
+	order := model.Order{
+		ID:           "OID-1",
+		UserId:       "UID-1",
+		PaymentId:    "PID-1",
+		TotalPayment: 45000,
+		TotalQty:     1,
+		TotalHour:    3,
+		StartAt:      time.Date(2022, 11, 20, 10, 0, 0, 0, time.UTC),
+		EndAt:        time.Date(2022, 11, 20, 13, 0, 0, 0, time.UTC),
+		Status:       model.OrderStatusPaid,
+	}
+
+	row := sqlmock.NewRows([]string{"id", "user_id", "payment_id", "total_payment", "total_qty", "total_hour", "start_at", "end_at", "status"}).
+		AddRow(order.ID, order.UserId, order.PaymentId, order.TotalPayment, order.TotalQty, order.TotalHour, order.StartAt, order.EndAt, order.Status)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT `orders`.`id`,`orders`.`user_id`,`orders`.`payment_id`,`orders`.`total_payment`,`orders`.`discount`,`orders`.`total_qty`,`orders`.`total_hour`,`orders`.`start_at`,`orders`.`end_at`,`orders`.`pending_end_at`,`orders`.`status`,`orders`.`cancellation_fee`,`orders`.`returned_at`,`orders`.`late_fee`,`orders`.`deposit`,`orders`.`deposit_status`,`orders`.`deposit_withheld`,`orders`.`deposit_reason`,`orders`.`created_at`,`orders`.`updated_at` FROM `orders` JOIN order_details ON order_details.order_id = orders.id WHERE (order_details.bike_id = ? AND orders.start_at < ? AND COALESCE(orders.pending_end_at, orders.end_at) > ?) AND orders.status IN (?,?,?) ORDER BY orders.start_at")).
+		WithArgs("BID-1", endAt, startAt, "pending_payment", "paid", "picked_up").
+		WillReturnRows(row)
+
+	results, err := s.bikeRepository.FindOverlappingOrders("BID-1", startAt, endAt)
+
+	s.Nil(err)
+	s.NotNil(results)
+
+	s.Equal(order.ID, (*results)[0].ID)
+	s.Equal(order.StartAt, (*results)[0].StartAt)
+	s.Equal(order.EndAt, (*results)[0].EndAt)
+	s.Equal(order.Status, (*results)[0].Status)
+}
+
 func (s *suiteBike) TestUpdate() {
 	bikeUC := model.Bike{
 		CategoryId:   "CID-1",
@@ -47,8 +47,8 @@ func (s *suiteCategory) TestCreate() {
 	}
 
 	s.mock.ExpectBegin()
-	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `categories` (`id`,`name`,`created_at`,`updated_at`) VALUES (?,?,?,?)")).
-		WithArgs(categoryUC.ID, categoryUC.Name, pkg.Anytime{}, pkg.Anytime{}).
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `categories` (`id`,`name`,`deposit`,`created_at`,`updated_at`) VALUES (?,?,?,?,?)")).
+		WithArgs(categoryUC.ID, categoryUC.Name, categoryUC.Deposit, pkg.Anytime{}, pkg.Anytime{}).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	s.mock.ExpectCommit()
 
@@ -0,0 +1,59 @@
+package gormdb
+
+import (
+	"errors"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InvoiceRepository struct {
+	DB *gorm.DB
+}
+
+func (r InvoiceRepository) Create(invoiceUC model.Invoice) error {
+	err := r.DB.Model(&model.Invoice{}).Create(&invoiceUC).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r InvoiceRepository) FindByIdOrder(orderId string) (*[]model.Invoice, error) {
+	invoices := &[]model.Invoice{}
+
+	err := r.DB.Model(&model.Invoice{}).Where("order_id = ?", orderId).Order("renter_id").Find(&invoices).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return invoices, nil
+}
+
+// FindLastByIdRenterForUpdate locks the last invoice of the renter, so two invoices issued at the same time
+// cannot take the same sequence.
+func (r InvoiceRepository) FindLastByIdRenterForUpdate(renterId string) (*model.Invoice, error) {
+	invoice := &model.Invoice{}
+
+	err := r.DB.Model(&model.Invoice{}).Clauses(clause.Locking{Strength: "UPDATE"}).Where("renter_id = ?", renterId).Order("sequence DESC").Take(&invoice).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.ErrRecordNotFound
+		}
+
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+func NewInvoiceRepository(db *gorm.DB) repository.InvoiceRepository {
+	return InvoiceRepository{db}
+}
@@ -0,0 +1,105 @@
+package gormdb
+
+import (
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type suiteInvoice struct {
+	suite.Suite
+	mock              sqlmock.Sqlmock
+	invoiceRepository repository.InvoiceRepository
+}
+
+func (s *suiteInvoice) SetupSuite() {
+	var (
+		db  *sql.DB
+		err error
+	)
+
+	db, s.mock, err = sqlmock.New()
+
+	s.NoError(err)
+
+	dbGorm, _ := gorm.Open(mysql.New(mysql.Config{
+		SkipInitializeWithVersion: true,
+		Conn:                      db,
+	}))
+
+	s.invoiceRepository = NewInvoiceRepository(dbGorm)
+}
+
+func (s *suiteInvoice) TestCreate() {
+	invoiceUC := model.Invoice{
+		ID:        "IID-1",
+		OrderId:   "OID-1",
+		RenterId:  "RID-1",
+		Sequence:  42,
+		Number:    "INV-RID-00042",
+		CreatedAt: time.Now(),
+	}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `invoices` (`id`,`order_id`,`renter_id`,`sequence`,`number`,`created_at`) VALUES (?,?,?,?,?,?)")).
+		WithArgs("IID-1", "OID-1", "RID-1", 42, "INV-RID-00042", pkg.Anytime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.invoiceRepository.Create(invoiceUC)
+
+	s.Nil(err)
+}
+
+func (s *suiteInvoice) TestFindByIdOrder() {
+	rows := sqlmock.NewRows([]string{"id", "order_id", "renter_id", "sequence", "number"}).
+		AddRow("IID-1", "OID-1", "RID-1", 42, "INV-RID-00042").
+		AddRow("IID-2", "OID-1", "RID-2", 7, "INV-RID-00007")
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `invoices` WHERE order_id = ? ORDER BY renter_id")).
+		WithArgs("OID-1").
+		WillReturnRows(rows)
+
+	result, err := s.invoiceRepository.FindByIdOrder("OID-1")
+
+	s.Nil(err)
+	s.Len(*result, 2)
+	s.Equal(7, (*result)[1].Sequence)
+}
+
+func (s *suiteInvoice) TestFindLastByIdRenterForUpdate() {
+	rows := sqlmock.NewRows([]string{"id", "renter_id", "sequence"}).
+		AddRow("IID-1", "RID-1", 42)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `invoices` WHERE renter_id = ? ORDER BY sequence DESC LIMIT 1 FOR UPDATE")).
+		WithArgs("RID-1").
+		WillReturnRows(rows)
+
+	result, err := s.invoiceRepository.FindLastByIdRenterForUpdate("RID-1")
+
+	s.Nil(err)
+	s.Equal(42, result.Sequence)
+}
+
+func (s *suiteInvoice) TestFindLastByIdRenterForUpdateNotFound() {
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `invoices` WHERE renter_id = ? ORDER BY sequence DESC LIMIT 1 FOR UPDATE")).
+		WithArgs("RID-2").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	result, err := s.invoiceRepository.FindLastByIdRenterForUpdate("RID-2")
+
+	s.Nil(result)
+	s.Equal(pkg.ErrRecordNotFound, err)
+}
+
+func TestInvoiceRepository(t *testing.T) {
+	suite.Run(t, new(suiteInvoice))
+}
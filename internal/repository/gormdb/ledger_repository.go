@@ -0,0 +1,86 @@
+package gormdb
+
+import (
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LedgerRepository struct {
+	DB *gorm.DB
+}
+
+func (r LedgerRepository) Create(entriesUC []model.LedgerEntry) error {
+	err := r.DB.Model(&model.LedgerEntry{}).Create(&entriesUC).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r LedgerRepository) FindByIdPayment(paymentId string) (*[]model.LedgerEntry, error) {
+	entries := &[]model.LedgerEntry{}
+
+	err := r.DB.Model(&model.LedgerEntry{}).Where("payment_id = ?", paymentId).Order("created_at").Find(&entries).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r LedgerRepository) FindByIdRenter(renterId string) (*[]model.LedgerEntry, error) {
+	entries := &[]model.LedgerEntry{}
+
+	err := r.DB.Model(&model.LedgerEntry{}).
+		Where("account = ? AND renter_id = ?", model.LedgerAccountRenterPayable, renterId).
+		Order("created_at").
+		Find(&entries).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r LedgerRepository) FindRenterBalance(renterId string) (*model.RenterBalance, error) {
+	balance := &model.RenterBalance{RenterId: renterId}
+
+	err := r.DB.Model(&model.LedgerEntry{}).
+		Select("COALESCE(SUM(credit) - SUM(debit), 0)").
+		Where("account = ? AND renter_id = ?", model.LedgerAccountRenterPayable, renterId).
+		Scan(&balance.Balance).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return balance, nil
+}
+
+func (r LedgerRepository) FindRenterBalancesForUpdate() (*[]model.RenterBalance, error) {
+	balances := &[]model.RenterBalance{}
+
+	err := r.DB.Model(&model.LedgerEntry{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("renter_id, SUM(credit) - SUM(debit) AS balance").
+		Where("account = ?", model.LedgerAccountRenterPayable).
+		Group("renter_id").
+		Order("renter_id").
+		Scan(&balances).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return balances, nil
+}
+
+func NewLedgerRepository(db *gorm.DB) repository.LedgerRepository {
+	return LedgerRepository{db}
+}
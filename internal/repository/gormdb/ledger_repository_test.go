@@ -0,0 +1,126 @@
+package gormdb
+
+import (
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type suiteLedger struct {
+	suite.Suite
+	mock             sqlmock.Sqlmock
+	ledgerRepository repository.LedgerRepository
+}
+
+func (s *suiteLedger) SetupSuite() {
+	var (
+		db  *sql.DB
+		err error
+	)
+
+	db, s.mock, err = sqlmock.New()
+
+	s.NoError(err)
+
+	dbGorm, _ := gorm.Open(mysql.New(mysql.Config{
+		SkipInitializeWithVersion: true,
+		Conn:                      db,
+	}))
+
+	s.ledgerRepository = NewLedgerRepository(dbGorm)
+}
+
+func (s *suiteLedger) TestCreate() {
+	entriesUC := []model.LedgerEntry{
+		{
+			ID:          "LID-1",
+			JournalId:   "JID-1",
+			Account:     model.LedgerAccountGatewayClearing,
+			PaymentId:   "PID-1",
+			Description: "rent payment of order OID-1",
+			Debit:       75000,
+			CreatedAt:   time.Now(),
+		},
+		{
+			ID:          "LID-2",
+			JournalId:   "JID-1",
+			Account:     model.LedgerAccountRenterPayable,
+			RenterId:    "RID-1",
+			PaymentId:   "PID-1",
+			Description: "rent payment of order OID-1",
+			Credit:      75000,
+			CreatedAt:   time.Now(),
+		},
+	}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `ledger_entries` (`id`,`journal_id`,`account`,`renter_id`,`payment_id`,`refund_id`,`payout_id`,`description`,`debit`,`credit`,`created_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?),(?,?,?,?,?,?,?,?,?,?,?)")).
+		WithArgs(
+			"LID-1", "JID-1", "gateway_clearing", "", "PID-1", "", "", "rent payment of order OID-1", float32(75000), float32(0), pkg.Anytime{},
+			"LID-2", "JID-1", "renter_payable", "RID-1", "PID-1", "", "", "rent payment of order OID-1", float32(0), float32(75000), pkg.Anytime{},
+		).
+		WillReturnResult(sqlmock.NewResult(2, 2))
+	s.mock.ExpectCommit()
+
+	err := s.ledgerRepository.Create(entriesUC)
+
+	s.Nil(err)
+}
+
+func (s *suiteLedger) TestFindByIdRenter() {
+	rows := sqlmock.NewRows([]string{"id", "account", "renter_id", "credit"}).
+		AddRow("LID-2", "renter_payable", "RID-1", float32(75000))
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `ledger_entries` WHERE account = ? AND renter_id = ? ORDER BY created_at")).
+		WithArgs("renter_payable", "RID-1").
+		WillReturnRows(rows)
+
+	results, err := s.ledgerRepository.FindByIdRenter("RID-1")
+
+	s.Nil(err)
+	s.Len(*results, 1)
+	s.Equal(float32(75000), (*results)[0].Credit)
+}
+
+func (s *suiteLedger) TestFindRenterBalance() {
+	rows := sqlmock.NewRows([]string{"balance"}).AddRow(float32(40500))
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(credit) - SUM(debit), 0) FROM `ledger_entries` WHERE account = ? AND renter_id = ?")).
+		WithArgs("renter_payable", "RID-1").
+		WillReturnRows(rows)
+
+	result, err := s.ledgerRepository.FindRenterBalance("RID-1")
+
+	s.Nil(err)
+	s.Equal("RID-1", result.RenterId)
+	s.Equal(float32(40500), result.Balance)
+}
+
+func (s *suiteLedger) TestFindRenterBalancesForUpdate() {
+	rows := sqlmock.NewRows([]string{"renter_id", "balance"}).
+		AddRow("RID-1", float32(40500)).
+		AddRow("RID-2", float32(27000))
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT renter_id, SUM(credit) - SUM(debit) AS balance FROM `ledger_entries` WHERE account = ? GROUP BY `renter_id` ORDER BY renter_id FOR UPDATE")).
+		WithArgs("renter_payable").
+		WillReturnRows(rows)
+
+	results, err := s.ledgerRepository.FindRenterBalancesForUpdate()
+
+	s.Nil(err)
+	s.Len(*results, 2)
+	s.Equal("RID-2", (*results)[1].RenterId)
+	s.Equal(float32(27000), (*results)[1].Balance)
+}
+
+func TestLedgerRepository(t *testing.T) {
+	suite.Run(t, new(suiteLedger))
+}
@@ -0,0 +1,126 @@
+package gormdb
+
+import (
+	"errors"
+	"time"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoginAttemptRepository struct {
+	DB *gorm.DB
+}
+
+func (r LoginAttemptRepository) Create(attemptUC model.LoginAttempt) error {
+	err := r.DB.Model(&model.LoginAttempt{}).Create(&attemptUC).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindAll lists the newest attempts first, an empty email or ip address does not filter on it
+func (r LoginAttemptRepository) FindAll(email string, ipAddress string, limit int) (*[]model.LoginAttempt, error) {
+	attempts := &[]model.LoginAttempt{}
+
+	query := r.DB.Model(&model.LoginAttempt{})
+
+	if email != "" {
+		query = query.Where("email = ?", email)
+	}
+
+	if ipAddress != "" {
+		query = query.Where("ip_address = ?", ipAddress)
+	}
+
+	err := query.Order("created_at DESC").Limit(limit).Find(&attempts).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return attempts, nil
+}
+
+func (r LoginAttemptRepository) FindThrottle(key string) (*model.LoginThrottle, error) {
+	throttle := &model.LoginThrottle{}
+
+	err := r.DB.Model(&model.LoginThrottle{}).Where("`key` = ?", key).Take(&throttle).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.ErrRecordNotFound
+		}
+
+		return nil, err
+	}
+
+	return throttle, nil
+}
+
+func (r LoginAttemptRepository) FindThrottleForUpdate(key string) (*model.LoginThrottle, error) {
+	throttle := &model.LoginThrottle{}
+
+	err := r.DB.Model(&model.LoginThrottle{}).Clauses(clause.Locking{Strength: "UPDATE"}).Where("`key` = ?", key).Take(&throttle).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.ErrRecordNotFound
+		}
+
+		return nil, err
+	}
+
+	return throttle, nil
+}
+
+// IncrementThrottle counts a failure on the throttle of a key, creating it on the first one. The count is
+// incremented by the database so concurrent failures are all counted, it starts over when the last failure
+// is older than windowStart. The assignments run in order, failures reads the last failure before it is set.
+func (r LoginAttemptRepository) IncrementThrottle(key string, failedAt time.Time, windowStart time.Time) error {
+	err := r.DB.Model(&model.LoginThrottle{}).Clauses(clause.OnConflict{
+		DoUpdates: []clause.Assignment{
+			{Column: clause.Column{Name: "failures"}, Value: gorm.Expr("IF(`last_failure_at` < ?, 1, `failures` + 1)", windowStart)},
+			{Column: clause.Column{Name: "last_failure_at"}, Value: failedAt},
+			{Column: clause.Column{Name: "updated_at"}, Value: failedAt},
+		},
+	}).Create(&model.LoginThrottle{Key: key, Failures: 1, LastFailureAt: failedAt, UpdatedAt: failedAt}).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r LoginAttemptRepository) LockThrottle(key string, lockedUntil time.Time) error {
+	err := r.DB.Model(&model.LoginThrottle{}).Where("`key` = ?", key).Updates(map[string]interface{}{
+		"locked_until": lockedUntil,
+	}).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r LoginAttemptRepository) DeleteThrottle(key string) error {
+	err := r.DB.Where("`key` = ?", key).Delete(&model.LoginThrottle{}).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) repository.LoginAttemptRepository {
+	return LoginAttemptRepository{db}
+}
@@ -0,0 +1,169 @@
+package gormdb
+
+import (
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type suiteLoginAttempt struct {
+	suite.Suite
+	mock                   sqlmock.Sqlmock
+	loginAttemptRepository repository.LoginAttemptRepository
+}
+
+func (s *suiteLoginAttempt) SetupSuite() {
+	var (
+		db  *sql.DB
+		err error
+	)
+
+	db, s.mock, err = sqlmock.New()
+
+	s.NoError(err)
+
+	dbGorm, _ := gorm.Open(mysql.New(mysql.Config{
+		SkipInitializeWithVersion: true,
+		Conn:                      db,
+	}))
+
+	s.loginAttemptRepository = NewLoginAttemptRepository(dbGorm)
+}
+
+func (s *suiteLoginAttempt) TestCreate() {
+	attemptUC := model.LoginAttempt{
+		ID:            "LID-1",
+		Email:         "nobody@mail.com",
+		IPAddress:     "10.0.0.1",
+		UserAgent:     "curl/7.85.0",
+		FailureReason: model.LoginFailureUnknownEmail,
+		CreatedAt:     time.Now(),
+	}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `login_attempts` (`id`,`user_id`,`email`,`ip_address`,`user_agent`,`success`,`failure_reason`,`created_at`) VALUES (?,?,?,?,?,?,?,?)")).
+		WithArgs("LID-1", "", "nobody@mail.com", "10.0.0.1", "curl/7.85.0", false, model.LoginFailureUnknownEmail, pkg.Anytime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.loginAttemptRepository.Create(attemptUC)
+
+	s.Nil(err)
+}
+
+func (s *suiteLoginAttempt) TestFindAll() {
+	rows := sqlmock.NewRows([]string{"id", "email", "ip_address", "success"}).
+		AddRow("LID-2", "arvin@mail.com", "10.0.0.1", true).
+		AddRow("LID-1", "arvin@mail.com", "10.0.0.1", false)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `login_attempts` WHERE email = ? AND ip_address = ? ORDER BY created_at DESC LIMIT 100")).
+		WithArgs("arvin@mail.com", "10.0.0.1").
+		WillReturnRows(rows)
+
+	result, err := s.loginAttemptRepository.FindAll("arvin@mail.com", "10.0.0.1", 100)
+
+	s.Nil(err)
+	s.Len(*result, 2)
+	s.Equal("LID-2", (*result)[0].ID)
+}
+
+func (s *suiteLoginAttempt) TestFindAllWithoutFilter() {
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `login_attempts` ORDER BY created_at DESC LIMIT 100")).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("LID-1"))
+
+	result, err := s.loginAttemptRepository.FindAll("", "", 100)
+
+	s.Nil(err)
+	s.Len(*result, 1)
+}
+
+func (s *suiteLoginAttempt) TestFindThrottleForUpdate() {
+	rows := sqlmock.NewRows([]string{"key", "failures"}).AddRow("email:arvin@mail.com", 3)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `login_throttles` WHERE `key` = ? LIMIT 1 FOR UPDATE")).
+		WithArgs("email:arvin@mail.com").
+		WillReturnRows(rows)
+
+	result, err := s.loginAttemptRepository.FindThrottleForUpdate("email:arvin@mail.com")
+
+	s.Nil(err)
+	s.Equal(3, result.Failures)
+}
+
+func (s *suiteLoginAttempt) TestFindThrottleNotFound() {
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `login_throttles` WHERE `key` = ? LIMIT 1 FOR UPDATE")).
+		WithArgs("ip:10.0.0.9").
+		WillReturnRows(sqlmock.NewRows([]string{"key"}))
+
+	result, err := s.loginAttemptRepository.FindThrottleForUpdate("ip:10.0.0.9")
+
+	s.Nil(result)
+	s.Equal(pkg.ErrRecordNotFound, err)
+}
+
+func (s *suiteLoginAttempt) TestFindThrottle() {
+	rows := sqlmock.NewRows([]string{"key", "failures"}).AddRow("ip:10.0.0.1", 2)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `login_throttles` WHERE `key` = ? LIMIT 1")).
+		WithArgs("ip:10.0.0.1").
+		WillReturnRows(rows)
+
+	result, err := s.loginAttemptRepository.FindThrottle("ip:10.0.0.1")
+
+	s.Nil(err)
+	s.Equal(2, result.Failures)
+}
+
+func (s *suiteLoginAttempt) TestIncrementThrottle() {
+	now := time.Now()
+	windowStart := now.Add(-15 * time.Minute)
+
+	// the failures are counted by the database, not overwritten with a count read before
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `login_throttles` (`key`,`failures`,`last_failure_at`,`locked_until`,`updated_at`) VALUES (?,?,?,?,?) ON DUPLICATE KEY UPDATE `failures`=IF(`last_failure_at` < ?, 1, `failures` + 1),`last_failure_at`=?,`updated_at`=?")).
+		WithArgs("email:arvin@mail.com", 1, now, nil, now, windowStart, now, now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.loginAttemptRepository.IncrementThrottle("email:arvin@mail.com", now, windowStart)
+
+	s.Nil(err)
+}
+
+func (s *suiteLoginAttempt) TestLockThrottle() {
+	lockedUntil := time.Now().Add(15 * time.Minute)
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("UPDATE `login_throttles` SET `locked_until`=?,`updated_at`=? WHERE `key` = ?")).
+		WithArgs(lockedUntil, pkg.Anytime{}, "email:arvin@mail.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	err := s.loginAttemptRepository.LockThrottle("email:arvin@mail.com", lockedUntil)
+
+	s.Nil(err)
+}
+
+func (s *suiteLoginAttempt) TestDeleteThrottle() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `login_throttles` WHERE `key` = ?")).
+		WithArgs("email:arvin@mail.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	err := s.loginAttemptRepository.DeleteThrottle("email:arvin@mail.com")
+
+	s.Nil(err)
+}
+
+func TestLoginAttemptRepository(t *testing.T) {
+	suite.Run(t, new(suiteLoginAttempt))
+}
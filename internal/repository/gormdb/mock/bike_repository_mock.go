@@ -1,6 +1,8 @@
 package repomock
 
 import (
+	"time"
+
 	"github.com/arvinpaundra/go-rent-bike/internal/model"
 	"github.com/stretchr/testify/mock"
 )
@@ -27,6 +29,18 @@ func (r *BikeRepositoryMock) FindById(bikeId string) (*model.Bike, error) {
 	return ret.Get(0).(*model.Bike), ret.Error(1)
 }
 
+func (r *BikeRepositoryMock) FindByIdsForUpdate(bikeIds []string) (*[]model.Bike, error) {
+	ret := r.Mock.Called(bikeIds)
+
+	return ret.Get(0).(*[]model.Bike), ret.Error(1)
+}
+
+func (r *BikeRepositoryMock) FindByIdOrderForUpdate(orderId string) (*[]model.Bike, error) {
+	ret := r.Mock.Called(orderId)
+
+	return ret.Get(0).(*[]model.Bike), ret.Error(1)
+}
+
 func (r *BikeRepositoryMock) FindByIdRenter(renterId string) (*[]model.Bike, error) {
 	ret := r.Mock.Called(renterId)
 
@@ -39,6 +53,12 @@ func (r *BikeRepositoryMock) FindByIdCategory(categoryId string) (*[]model.Bike,
 	return ret.Get(0).(*[]model.Bike), ret.Error(1)
 }
 
+func (r *BikeRepositoryMock) FindOverlappingOrders(bikeId string, startAt time.Time, endAt time.Time) (*[]model.Order, error) {
+	ret := r.Mock.Called(bikeId, startAt, endAt)
+
+	return ret.Get(0).(*[]model.Order), ret.Error(1)
+}
+
 func (r *BikeRepositoryMock) Update(bikeId string, bikeUC model.Bike) error {
 	ret := r.Mock.Called(bikeId, bikeUC)
 
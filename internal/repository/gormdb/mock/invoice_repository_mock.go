@@ -0,0 +1,28 @@
+package repomock
+
+import (
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type InvoiceRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (r *InvoiceRepositoryMock) Create(invoiceUC model.Invoice) error {
+	ret := r.Mock.Called(invoiceUC)
+
+	return ret.Error(0)
+}
+
+func (r *InvoiceRepositoryMock) FindByIdOrder(orderId string) (*[]model.Invoice, error) {
+	ret := r.Mock.Called(orderId)
+
+	return ret.Get(0).(*[]model.Invoice), ret.Error(1)
+}
+
+func (r *InvoiceRepositoryMock) FindLastByIdRenterForUpdate(renterId string) (*model.Invoice, error) {
+	ret := r.Mock.Called(renterId)
+
+	return ret.Get(0).(*model.Invoice), ret.Error(1)
+}
@@ -0,0 +1,40 @@
+package repomock
+
+import (
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type LedgerRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (r *LedgerRepositoryMock) Create(entriesUC []model.LedgerEntry) error {
+	ret := r.Mock.Called(entriesUC)
+
+	return ret.Error(0)
+}
+
+func (r *LedgerRepositoryMock) FindByIdPayment(paymentId string) (*[]model.LedgerEntry, error) {
+	ret := r.Mock.Called(paymentId)
+
+	return ret.Get(0).(*[]model.LedgerEntry), ret.Error(1)
+}
+
+func (r *LedgerRepositoryMock) FindByIdRenter(renterId string) (*[]model.LedgerEntry, error) {
+	ret := r.Mock.Called(renterId)
+
+	return ret.Get(0).(*[]model.LedgerEntry), ret.Error(1)
+}
+
+func (r *LedgerRepositoryMock) FindRenterBalance(renterId string) (*model.RenterBalance, error) {
+	ret := r.Mock.Called(renterId)
+
+	return ret.Get(0).(*model.RenterBalance), ret.Error(1)
+}
+
+func (r *LedgerRepositoryMock) FindRenterBalancesForUpdate() (*[]model.RenterBalance, error) {
+	ret := r.Mock.Called()
+
+	return ret.Get(0).(*[]model.RenterBalance), ret.Error(1)
+}
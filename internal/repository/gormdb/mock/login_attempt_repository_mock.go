@@ -0,0 +1,54 @@
+package repomock
+
+import (
+	"time"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type LoginAttemptRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (r *LoginAttemptRepositoryMock) Create(attemptUC model.LoginAttempt) error {
+	ret := r.Mock.Called(attemptUC)
+
+	return ret.Error(0)
+}
+
+func (r *LoginAttemptRepositoryMock) FindAll(email string, ipAddress string, limit int) (*[]model.LoginAttempt, error) {
+	ret := r.Mock.Called(email, ipAddress, limit)
+
+	return ret.Get(0).(*[]model.LoginAttempt), ret.Error(1)
+}
+
+func (r *LoginAttemptRepositoryMock) FindThrottle(key string) (*model.LoginThrottle, error) {
+	ret := r.Mock.Called(key)
+
+	return ret.Get(0).(*model.LoginThrottle), ret.Error(1)
+}
+
+func (r *LoginAttemptRepositoryMock) FindThrottleForUpdate(key string) (*model.LoginThrottle, error) {
+	ret := r.Mock.Called(key)
+
+	return ret.Get(0).(*model.LoginThrottle), ret.Error(1)
+}
+
+func (r *LoginAttemptRepositoryMock) IncrementThrottle(key string, failedAt time.Time, windowStart time.Time) error {
+	ret := r.Mock.Called(key, failedAt, windowStart)
+
+	return ret.Error(0)
+}
+
+func (r *LoginAttemptRepositoryMock) LockThrottle(key string, lockedUntil time.Time) error {
+	ret := r.Mock.Called(key, lockedUntil)
+
+	return ret.Error(0)
+}
+
+func (r *LoginAttemptRepositoryMock) DeleteThrottle(key string) error {
+	ret := r.Mock.Called(key)
+
+	return ret.Error(0)
+}
@@ -1,6 +1,8 @@
 package repomock
 
 import (
+	"time"
+
 	"github.com/arvinpaundra/go-rent-bike/internal/model"
 	"github.com/stretchr/testify/mock"
 )
@@ -27,8 +29,26 @@ func (o *OrderRepositoryMock) FindById(orderId string) (*model.Order, error) {
 	return ret.Get(0).(*model.Order), ret.Error(1)
 }
 
+func (o *OrderRepositoryMock) FindByIdForUpdate(orderId string) (*model.Order, error) {
+	ret := o.Mock.Called(orderId)
+
+	return ret.Get(0).(*model.Order), ret.Error(1)
+}
+
+func (o *OrderRepositoryMock) FindPendingPayment(createdBefore time.Time) (*[]model.Order, error) {
+	ret := o.Mock.Called(createdBefore)
+
+	return ret.Get(0).(*[]model.Order), ret.Error(1)
+}
+
 func (o *OrderRepositoryMock) Update(orderId string, orderUC model.Order) error {
 	ret := o.Mock.Called(orderId, orderUC)
 
 	return ret.Error(0)
 }
+
+func (o *OrderRepositoryMock) UpdatePendingEndAt(orderId string, pendingEndAt *time.Time) error {
+	ret := o.Mock.Called(orderId, pendingEndAt)
+
+	return ret.Error(0)
+}
@@ -0,0 +1,22 @@
+package repomock
+
+import (
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type OrderStatusHistoryRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (o *OrderStatusHistoryRepositoryMock) Create(statusHistoryUC model.OrderStatusHistory) error {
+	ret := o.Mock.Called(statusHistoryUC)
+
+	return ret.Error(0)
+}
+
+func (o *OrderStatusHistoryRepositoryMock) FindByIdOrder(orderId string) (*[]model.OrderStatusHistory, error) {
+	ret := o.Mock.Called(orderId)
+
+	return ret.Get(0).(*[]model.OrderStatusHistory), ret.Error(1)
+}
@@ -0,0 +1,22 @@
+package repomock
+
+import (
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type PaymentNotificationRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (r *PaymentNotificationRepositoryMock) Create(notificationUC model.PaymentNotification) error {
+	ret := r.Mock.Called(notificationUC)
+
+	return ret.Error(0)
+}
+
+func (r *PaymentNotificationRepositoryMock) FindByTransaction(transactionId string, transactionStatus string, refundAmount string) (*model.PaymentNotification, error) {
+	ret := r.Mock.Called(transactionId, transactionStatus, refundAmount)
+
+	return ret.Get(0).(*model.PaymentNotification), ret.Error(1)
+}
@@ -1,6 +1,8 @@
 package repomock
 
 import (
+	"time"
+
 	"github.com/arvinpaundra/go-rent-bike/internal/model"
 	"github.com/stretchr/testify/mock"
 )
@@ -21,6 +23,24 @@ func (p *PaymentRepositoryMock) FindById(paymentId string) (*model.Payment, erro
 	return ret.Get(0).(*model.Payment), ret.Error(1)
 }
 
+func (p *PaymentRepositoryMock) FindByIdForUpdate(paymentId string) (*model.Payment, error) {
+	ret := p.Mock.Called(paymentId)
+
+	return ret.Get(0).(*model.Payment), ret.Error(1)
+}
+
+func (p *PaymentRepositoryMock) FindPending(kind string, createdBefore time.Time) (*[]model.Payment, error) {
+	ret := p.Mock.Called(kind, createdBefore)
+
+	return ret.Get(0).(*[]model.Payment), ret.Error(1)
+}
+
+func (p *PaymentRepositoryMock) FindUnreconciled() (*[]model.Payment, error) {
+	ret := p.Mock.Called()
+
+	return ret.Get(0).(*[]model.Payment), ret.Error(1)
+}
+
 func (p *PaymentRepositoryMock) Update(paymentId string, paymentUC model.Payment) error {
 	ret := p.Mock.Called(paymentId, paymentUC)
 
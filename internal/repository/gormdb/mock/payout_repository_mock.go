@@ -0,0 +1,64 @@
+package repomock
+
+import (
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type PayoutRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (r *PayoutRepositoryMock) CreateBatch(batchUC model.PayoutBatch) error {
+	ret := r.Mock.Called(batchUC)
+
+	return ret.Error(0)
+}
+
+func (r *PayoutRepositoryMock) Create(payoutsUC []model.Payout) error {
+	ret := r.Mock.Called(payoutsUC)
+
+	return ret.Error(0)
+}
+
+func (r *PayoutRepositoryMock) FindAllBatches() (*[]model.PayoutBatch, error) {
+	ret := r.Mock.Called()
+
+	return ret.Get(0).(*[]model.PayoutBatch), ret.Error(1)
+}
+
+func (r *PayoutRepositoryMock) FindBatchById(batchId string) (*model.PayoutBatch, error) {
+	ret := r.Mock.Called(batchId)
+
+	return ret.Get(0).(*model.PayoutBatch), ret.Error(1)
+}
+
+func (r *PayoutRepositoryMock) FindByIdForUpdate(payoutId string) (*model.Payout, error) {
+	ret := r.Mock.Called(payoutId)
+
+	return ret.Get(0).(*model.Payout), ret.Error(1)
+}
+
+func (r *PayoutRepositoryMock) FindByIdRenter(renterId string) (*[]model.Payout, error) {
+	ret := r.Mock.Called(renterId)
+
+	return ret.Get(0).(*[]model.Payout), ret.Error(1)
+}
+
+func (r *PayoutRepositoryMock) CountPending(batchId string) (int64, error) {
+	ret := r.Mock.Called(batchId)
+
+	return ret.Get(0).(int64), ret.Error(1)
+}
+
+func (r *PayoutRepositoryMock) Update(payoutId string, payoutUC model.Payout) error {
+	ret := r.Mock.Called(payoutId, payoutUC)
+
+	return ret.Error(0)
+}
+
+func (r *PayoutRepositoryMock) UpdateBatch(batchId string, batchUC model.PayoutBatch) error {
+	ret := r.Mock.Called(batchId, batchUC)
+
+	return ret.Error(0)
+}
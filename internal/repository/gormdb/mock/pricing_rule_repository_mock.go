@@ -0,0 +1,36 @@
+package repomock
+
+import (
+	"time"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type PricingRuleRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (r *PricingRuleRepositoryMock) Create(pricingRuleUC model.PricingRule) error {
+	ret := r.Mock.Called(pricingRuleUC)
+
+	return ret.Error(0)
+}
+
+func (r *PricingRuleRepositoryMock) FindByIdRenter(renterId string) (*[]model.PricingRule, error) {
+	ret := r.Mock.Called(renterId)
+
+	return ret.Get(0).(*[]model.PricingRule), ret.Error(1)
+}
+
+func (r *PricingRuleRepositoryMock) FindActive(renterId string, startAt time.Time, endAt time.Time) (*[]model.PricingRule, error) {
+	ret := r.Mock.Called(renterId, startAt, endAt)
+
+	return ret.Get(0).(*[]model.PricingRule), ret.Error(1)
+}
+
+func (r *PricingRuleRepositoryMock) Delete(pricingRuleId string) error {
+	ret := r.Mock.Called(pricingRuleId)
+
+	return ret.Error(0)
+}
@@ -0,0 +1,28 @@
+package repomock
+
+import (
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type RefundRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (r *RefundRepositoryMock) Create(refundUC model.Refund) error {
+	ret := r.Mock.Called(refundUC)
+
+	return ret.Error(0)
+}
+
+func (r *RefundRepositoryMock) FindByIdPayment(paymentId string) (*[]model.Refund, error) {
+	ret := r.Mock.Called(paymentId)
+
+	return ret.Get(0).(*[]model.Refund), ret.Error(1)
+}
+
+func (r *RefundRepositoryMock) Update(refundId string, refundUC model.Refund) error {
+	ret := r.Mock.Called(refundId, refundUC)
+
+	return ret.Error(0)
+}
@@ -1,6 +1,8 @@
 package repomock
 
 import (
+	"time"
+
 	"github.com/arvinpaundra/go-rent-bike/internal/model"
 	"github.com/stretchr/testify/mock"
 )
@@ -39,6 +41,12 @@ func (r *RenterRepositoryMock) Update(renterId string, renterUC model.Renter) er
 	return ret.Error(0)
 }
 
+func (r *RenterRepositoryMock) UpdateSuspension(renterId string, suspendedAt *time.Time, reason string) error {
+	ret := r.Mock.Called(renterId, suspendedAt, reason)
+
+	return ret.Error(0)
+}
+
 func (r *RenterRepositoryMock) Delete(renterId string) error {
 	ret := r.Mock.Called(renterId)
 
@@ -20,3 +20,21 @@ func (r *ReportRepositoryMock) FindAll(renterId string) (*[]model.Report, error)
 
 	return ret.Get(0).(*[]model.Report), ret.Error(1)
 }
+
+func (r *ReportRepositoryMock) FindAllByStatus(status string) (*[]model.Report, error) {
+	ret := r.Mock.Called(status)
+
+	return ret.Get(0).(*[]model.Report), ret.Error(1)
+}
+
+func (r *ReportRepositoryMock) FindById(reportId string) (*model.Report, error) {
+	ret := r.Mock.Called(reportId)
+
+	return ret.Get(0).(*model.Report), ret.Error(1)
+}
+
+func (r *ReportRepositoryMock) Update(reportId string, reportUC model.Report) error {
+	ret := r.Mock.Called(reportId, reportUC)
+
+	return ret.Error(0)
+}
@@ -0,0 +1,60 @@
+package repomock
+
+import (
+	"time"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type SessionRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (r *SessionRepositoryMock) Create(sessionUC model.Session) error {
+	ret := r.Mock.Called(sessionUC)
+
+	return ret.Error(0)
+}
+
+func (r *SessionRepositoryMock) FindByIdForUpdate(sessionId string) (*model.Session, error) {
+	ret := r.Mock.Called(sessionId)
+
+	return ret.Get(0).(*model.Session), ret.Error(1)
+}
+
+func (r *SessionRepositoryMock) FindByRefreshTokenHashForUpdate(hash string) (*model.Session, error) {
+	ret := r.Mock.Called(hash)
+
+	return ret.Get(0).(*model.Session), ret.Error(1)
+}
+
+func (r *SessionRepositoryMock) FindAllActiveByIdUser(userId string, now time.Time) (*[]model.Session, error) {
+	ret := r.Mock.Called(userId, now)
+
+	return ret.Get(0).(*[]model.Session), ret.Error(1)
+}
+
+func (r *SessionRepositoryMock) Update(sessionId string, sessionUC model.Session) error {
+	ret := r.Mock.Called(sessionId, sessionUC)
+
+	return ret.Error(0)
+}
+
+func (r *SessionRepositoryMock) RevokeToken(tokenUC model.RevokedToken) error {
+	ret := r.Mock.Called(tokenUC)
+
+	return ret.Error(0)
+}
+
+func (r *SessionRepositoryMock) IsTokenRevoked(jti string) (bool, error) {
+	ret := r.Mock.Called(jti)
+
+	return ret.Bool(0), ret.Error(1)
+}
+
+func (r *SessionRepositoryMock) DeleteExpiredRevokedTokens(now time.Time) error {
+	ret := r.Mock.Called(now)
+
+	return ret.Error(0)
+}
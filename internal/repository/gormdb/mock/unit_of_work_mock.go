@@ -0,0 +1,17 @@
+package repomock
+
+import (
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/stretchr/testify/mock"
+)
+
+// UnitOfWorkMock runs the given function straight away with the mocked repositories,
+// so the expectations are set on those mocks as usual.
+type UnitOfWorkMock struct {
+	Mock         mock.Mock
+	Repositories repository.Repositories
+}
+
+func (u *UnitOfWorkMock) WithTx(fn func(repos repository.Repositories) error) error {
+	return fn(u.Repositories)
+}
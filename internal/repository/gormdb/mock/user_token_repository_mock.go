@@ -0,0 +1,36 @@
+package repomock
+
+import (
+	"time"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type UserTokenRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (r *UserTokenRepositoryMock) Create(tokenUC model.UserToken) error {
+	ret := r.Mock.Called(tokenUC)
+
+	return ret.Error(0)
+}
+
+func (r *UserTokenRepositoryMock) FindByTokenHashForUpdate(hash string) (*model.UserToken, error) {
+	ret := r.Mock.Called(hash)
+
+	return ret.Get(0).(*model.UserToken), ret.Error(1)
+}
+
+func (r *UserTokenRepositoryMock) MarkUsed(tokenId string, usedAt time.Time) error {
+	ret := r.Mock.Called(tokenId, usedAt)
+
+	return ret.Error(0)
+}
+
+func (r *UserTokenRepositoryMock) MarkAllUsed(userId string, purpose string, usedAt time.Time) error {
+	ret := r.Mock.Called(userId, purpose, usedAt)
+
+	return ret.Error(0)
+}
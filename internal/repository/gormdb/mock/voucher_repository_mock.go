@@ -0,0 +1,64 @@
+package repomock
+
+import (
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type VoucherRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (r *VoucherRepositoryMock) Create(voucherUC model.Voucher) error {
+	ret := r.Mock.Called(voucherUC)
+
+	return ret.Error(0)
+}
+
+func (r *VoucherRepositoryMock) FindAll(renterId string) (*[]model.Voucher, error) {
+	ret := r.Mock.Called(renterId)
+
+	return ret.Get(0).(*[]model.Voucher), ret.Error(1)
+}
+
+func (r *VoucherRepositoryMock) FindById(voucherId string) (*model.Voucher, error) {
+	ret := r.Mock.Called(voucherId)
+
+	return ret.Get(0).(*model.Voucher), ret.Error(1)
+}
+
+func (r *VoucherRepositoryMock) FindByCode(code string) (*model.Voucher, error) {
+	ret := r.Mock.Called(code)
+
+	return ret.Get(0).(*model.Voucher), ret.Error(1)
+}
+
+func (r *VoucherRepositoryMock) FindByCodeForUpdate(code string) (*model.Voucher, error) {
+	ret := r.Mock.Called(code)
+
+	return ret.Get(0).(*model.Voucher), ret.Error(1)
+}
+
+func (r *VoucherRepositoryMock) CountUsages(voucherId string, userId string) (int64, error) {
+	ret := r.Mock.Called(voucherId, userId)
+
+	return ret.Get(0).(int64), ret.Error(1)
+}
+
+func (r *VoucherRepositoryMock) CreateUsage(voucherUsageUC model.VoucherUsage) error {
+	ret := r.Mock.Called(voucherUsageUC)
+
+	return ret.Error(0)
+}
+
+func (r *VoucherRepositoryMock) Update(voucherId string, voucherUC model.Voucher) error {
+	ret := r.Mock.Called(voucherId, voucherUC)
+
+	return ret.Error(0)
+}
+
+func (r *VoucherRepositoryMock) Delete(voucherId string) error {
+	ret := r.Mock.Called(voucherId)
+
+	return ret.Error(0)
+}
@@ -0,0 +1,52 @@
+package repomock
+
+import (
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type WalletRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (r *WalletRepositoryMock) Create(walletUC model.Wallet) error {
+	ret := r.Mock.Called(walletUC)
+
+	return ret.Error(0)
+}
+
+func (r *WalletRepositoryMock) FindByIdUser(userId string) (*model.Wallet, error) {
+	ret := r.Mock.Called(userId)
+
+	return ret.Get(0).(*model.Wallet), ret.Error(1)
+}
+
+func (r *WalletRepositoryMock) FindByIdUserForUpdate(userId string) (*model.Wallet, error) {
+	ret := r.Mock.Called(userId)
+
+	return ret.Get(0).(*model.Wallet), ret.Error(1)
+}
+
+func (r *WalletRepositoryMock) FindByIdForUpdate(walletId string) (*model.Wallet, error) {
+	ret := r.Mock.Called(walletId)
+
+	return ret.Get(0).(*model.Wallet), ret.Error(1)
+}
+
+func (r *WalletRepositoryMock) UpdateBalance(walletId string, walletUC model.Wallet) error {
+	ret := r.Mock.Called(walletId, walletUC)
+
+	return ret.Error(0)
+}
+
+func (r *WalletRepositoryMock) CreateTransaction(transactionUC model.WalletTransaction) error {
+	ret := r.Mock.Called(transactionUC)
+
+	return ret.Error(0)
+}
+
+func (r *WalletRepositoryMock) FindTransactions(walletId string) (*[]model.WalletTransaction, error) {
+	ret := r.Mock.Called(walletId)
+
+	return ret.Get(0).(*[]model.WalletTransaction), ret.Error(1)
+}
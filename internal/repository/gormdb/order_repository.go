@@ -2,6 +2,8 @@ package gormdb
 
 import (
 	"errors"
+	"time"
+
 	"github.com/arvinpaundra/go-rent-bike/pkg"
 
 	"github.com/arvinpaundra/go-rent-bike/internal/model"
@@ -52,6 +54,56 @@ func (r OrderRepository) FindById(orderId string) (*model.Order, error) {
 	return order, nil
 }
 
+// FindByIdForUpdate locks the order row, so writers of the same order take turns and read what the one before committed.
+func (r OrderRepository) FindByIdForUpdate(orderId string) (*model.Order, error) {
+	order := &model.Order{}
+
+	err := r.DB.Model(&model.Order{}).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", orderId).Preload("OrderDetails.Bike.Category").Preload(clause.Associations).Take(&order).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.ErrRecordNotFound
+		}
+
+		return nil, err
+	}
+
+	return order, nil
+}
+
+func (r OrderRepository) FindPendingPayment(createdBefore time.Time) (*[]model.Order, error) {
+	orders := &[]model.Order{}
+
+	err := r.DB.Model(&model.Order{}).Where("status = ? AND created_at < ?", model.OrderStatusPendingPayment, createdBefore).Order("created_at").Find(&orders).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func (r OrderRepository) Update(orderId string, orderUC model.Order) error {
+	err := r.DB.Model(&model.Order{}).Where("id = ?", orderId).Updates(&orderUC).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdatePendingEndAt sets or, with a nil pendingEndAt, clears the extended end an order is waiting for.
+func (r OrderRepository) UpdatePendingEndAt(orderId string, pendingEndAt *time.Time) error {
+	err := r.DB.Model(&model.Order{}).Where("id = ?", orderId).Update("pending_end_at", pendingEndAt).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func NewOrderRepository(db *gorm.DB) repository.OrderRepository {
 	return OrderRepository{db}
 }
@@ -46,13 +46,16 @@ func (s *suiteOrder) TestCreate() {
 		TotalPayment: 200000,
 		TotalQty:     3,
 		TotalHour:    5,
+		StartAt:      time.Now(),
+		EndAt:        time.Now().Add(5 * time.Hour),
+		Status:       model.OrderStatusPendingPayment,
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 	}
 
 	s.mock.ExpectBegin()
-	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `orders` (`id`,`user_id`,`payment_id`,`total_payment`,`total_qty`,`total_hour`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?)")).
-		WithArgs("OID-1", "UID-1", "PID-1", float32(200000), 3, 5, pkg.Anytime{}, pkg.Anytime{}).
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `orders` (`id`,`user_id`,`payment_id`,`total_payment`,`discount`,`total_qty`,`total_hour`,`start_at`,`end_at`,`pending_end_at`,`status`,`cancellation_fee`,`returned_at`,`late_fee`,`deposit`,`deposit_status`,`deposit_withheld`,`deposit_reason`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)")).
+		WithArgs("OID-1", "UID-1", "PID-1", float32(200000), float32(0), 3, 5, pkg.Anytime{}, pkg.Anytime{}, nil, "pending_payment", float32(0), nil, float32(0), float32(0), "", float32(0), "", pkg.Anytime{}, pkg.Anytime{}).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	s.mock.ExpectCommit()
 
@@ -69,12 +72,15 @@ func (s *suiteOrder) TestFindAll() {
 		TotalPayment: 200000,
 		TotalQty:     3,
 		TotalHour:    5,
+		StartAt:      time.Now(),
+		EndAt:        time.Now().Add(5 * time.Hour),
+		Status:       model.OrderStatusPendingPayment,
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 	}
 
-	row := sqlmock.NewRows([]string{"id", "user_id", "payment_id", "total_payment", "total_qty", "total_hour", "created_at", "updated_at"}).
-		AddRow(order.ID, order.UserId, order.PaymentId, order.TotalPayment, order.TotalQty, order.TotalHour, order.CreatedAt, order.UpdatedAt)
+	row := sqlmock.NewRows([]string{"id", "user_id", "payment_id", "total_payment", "total_qty", "total_hour", "start_at", "end_at", "status", "created_at", "updated_at"}).
+		AddRow(order.ID, order.UserId, order.PaymentId, order.TotalPayment, order.TotalQty, order.TotalHour, order.StartAt, order.EndAt, order.Status, order.CreatedAt, order.UpdatedAt)
 
 	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `orders` WHERE user_id = ?")).
 		WithArgs("UID-1").
@@ -101,12 +107,15 @@ func (s *suiteOrder) TestFindById() {
 		TotalPayment: 200000,
 		TotalQty:     3,
 		TotalHour:    5,
+		StartAt:      time.Now(),
+		EndAt:        time.Now().Add(5 * time.Hour),
+		Status:       model.OrderStatusPendingPayment,
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 	}
 
-	orderRow := sqlmock.NewRows([]string{"id", "user_id", "payment_id", "total_payment", "total_qty", "total_hour", "created_at", "updated_at"}).
-		AddRow(order.ID, order.UserId, order.PaymentId, order.TotalPayment, order.TotalQty, order.TotalHour, order.CreatedAt, order.UpdatedAt)
+	orderRow := sqlmock.NewRows([]string{"id", "user_id", "payment_id", "total_payment", "total_qty", "total_hour", "start_at", "end_at", "status", "created_at", "updated_at"}).
+		AddRow(order.ID, order.UserId, order.PaymentId, order.TotalPayment, order.TotalQty, order.TotalHour, order.StartAt, order.EndAt, order.Status, order.CreatedAt, order.UpdatedAt)
 
 	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `orders` WHERE id = ? LIMIT 1")).
 		WithArgs("OID-1").
@@ -186,6 +195,93 @@ func (s *suiteOrder) TestFindById() {
 	s.Equal(order.TotalPayment, result.TotalPayment)
 	s.Equal(order.TotalHour, result.TotalHour)
 	s.Equal(order.TotalQty, result.TotalQty)
+	s.Equal(order.Status, result.Status)
+}
+
+func (s *suiteOrder) TestFindByIdForUpdate() {
+	orderRow := sqlmock.NewRows([]string{"id", "user_id", "payment_id", "total_payment", "status"}).
+		AddRow("OID-1", "UID-1", "PID-1", float32(200000), model.OrderStatusPendingPayment)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `orders` WHERE id = ? LIMIT 1 FOR UPDATE")).
+		WithArgs("OID-1").
+		WillReturnRows(orderRow)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `order_details` WHERE `order_details`.`order_id` = ?")).
+		WithArgs("OID-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "bike_id"}))
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `payments` WHERE `payments`.`id` = ?")).
+		WithArgs("PID-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "payment_status"}).AddRow("PID-1", "pending"))
+
+	result, err := s.orderRepository.FindByIdForUpdate("OID-1")
+
+	s.Nil(err)
+	s.NotNil(result)
+
+	s.Equal("OID-1", result.ID)
+	s.Equal(model.OrderStatusPendingPayment, result.Status)
+}
+
+func (s *suiteOrder) TestFindPendingPayment() {
+	createdBefore := time.Date(2022, 11, 20, 8, 0, 0, 0, time.UTC)
+
+	order := model.Order{
+		ID:           "OID-1",
+		UserId:       "UID-1",
+		PaymentId:    "PID-1",
+		TotalPayment: 200000,
+		TotalQty:     3,
+		TotalHour:    5,
+		Status:       model.OrderStatusPendingPayment,
+		CreatedAt:    time.Date(2022, 11, 20, 6, 0, 0, 0, time.UTC),
+	}
+
+	row := sqlmock.NewRows([]string{"id", "user_id", "payment_id", "total_payment", "total_qty", "total_hour", "status", "created_at"}).
+		AddRow(order.ID, order.UserId, order.PaymentId, order.TotalPayment, order.TotalQty, order.TotalHour, order.Status, order.CreatedAt)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `orders` WHERE status = ? AND created_at < ? ORDER BY created_at")).
+		WithArgs("pending_payment", createdBefore).
+		WillReturnRows(row)
+
+	results, err := s.orderRepository.FindPendingPayment(createdBefore)
+
+	s.Nil(err)
+	s.NotNil(results)
+
+	s.Equal(order.ID, (*results)[0].ID)
+	s.Equal(order.Status, (*results)[0].Status)
+}
+
+func (s *suiteOrder) TestUpdate() {
+	orderUC := model.Order{
+		Status:    model.OrderStatusPaid,
+		UpdatedAt: time.Now(),
+	}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("UPDATE `orders` SET `status`=?,`updated_at`=? WHERE id = ?")).
+		WithArgs("paid", pkg.Anytime{}, "OID-1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.orderRepository.Update("OID-1", orderUC)
+
+	s.Nil(err)
+}
+
+func (s *suiteOrder) TestUpdatePendingEndAt() {
+	pendingEndAt := time.Date(2022, 11, 20, 16, 0, 0, 0, time.UTC)
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("UPDATE `orders` SET `pending_end_at`=?,`updated_at`=? WHERE id = ?")).
+		WithArgs(pendingEndAt, pkg.Anytime{}, "OID-1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.orderRepository.UpdatePendingEndAt("OID-1", &pendingEndAt)
+
+	s.Nil(err)
 }
 
 func TestOrderRepository(t *testing.T) {
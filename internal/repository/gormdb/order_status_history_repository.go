@@ -0,0 +1,37 @@
+package gormdb
+
+import (
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"gorm.io/gorm"
+)
+
+type OrderStatusHistoryRepository struct {
+	DB *gorm.DB
+}
+
+func (r OrderStatusHistoryRepository) Create(statusHistoryUC model.OrderStatusHistory) error {
+	err := r.DB.Model(&model.OrderStatusHistory{}).Create(&statusHistoryUC).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r OrderStatusHistoryRepository) FindByIdOrder(orderId string) (*[]model.OrderStatusHistory, error) {
+	statusHistories := &[]model.OrderStatusHistory{}
+
+	err := r.DB.Model(&model.OrderStatusHistory{}).Where("order_id = ?", orderId).Order("created_at").Find(&statusHistories).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return statusHistories, nil
+}
+
+func NewOrderStatusHistoryRepository(db *gorm.DB) repository.OrderStatusHistoryRepository {
+	return OrderStatusHistoryRepository{db}
+}
@@ -0,0 +1,92 @@
+package gormdb
+
+import (
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type suiteOrderStatusHistory struct {
+	suite.Suite
+	mock                         sqlmock.Sqlmock
+	orderStatusHistoryRepository repository.OrderStatusHistoryRepository
+}
+
+func (s *suiteOrderStatusHistory) SetupSuite() {
+	var (
+		db  *sql.DB
+		err error
+	)
+
+	db, s.mock, err = sqlmock.New()
+
+	s.NoError(err)
+
+	dbGorm, _ := gorm.Open(mysql.New(mysql.Config{
+		SkipInitializeWithVersion: true,
+		Conn:                      db,
+	}))
+
+	s.orderStatusHistoryRepository = NewOrderStatusHistoryRepository(dbGorm)
+}
+
+func (s *suiteOrderStatusHistory) TestCreate() {
+	statusHistoryUC := model.OrderStatusHistory{
+		ID:         "OSHID-1",
+		OrderId:    "OID-1",
+		FromStatus: model.OrderStatusPendingPayment,
+		ToStatus:   model.OrderStatusPaid,
+		Actor:      "midtrans",
+		CreatedAt:  time.Now(),
+	}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `order_status_histories` (`id`,`order_id`,`from_status`,`to_status`,`actor`,`reason`,`created_at`) VALUES (?,?,?,?,?,?,?)")).
+		WithArgs("OSHID-1", "OID-1", "pending_payment", "paid", "midtrans", "", pkg.Anytime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.orderStatusHistoryRepository.Create(statusHistoryUC)
+
+	s.Nil(err)
+}
+
+func (s *suiteOrderStatusHistory) TestFindByIdOrder() {
+	statusHistory := model.OrderStatusHistory{
+		ID:         "OSHID-1",
+		OrderId:    "OID-1",
+		FromStatus: model.OrderStatusPendingPayment,
+		ToStatus:   model.OrderStatusPaid,
+		Actor:      "midtrans",
+		CreatedAt:  time.Now(),
+	}
+
+	row := sqlmock.NewRows([]string{"id", "order_id", "from_status", "to_status", "actor", "created_at"}).
+		AddRow(statusHistory.ID, statusHistory.OrderId, statusHistory.FromStatus, statusHistory.ToStatus, statusHistory.Actor, statusHistory.CreatedAt)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `order_status_histories` WHERE order_id = ? ORDER BY created_at")).
+		WithArgs("OID-1").
+		WillReturnRows(row)
+
+	results, err := s.orderStatusHistoryRepository.FindByIdOrder("OID-1")
+
+	s.Nil(err)
+	s.NotNil(results)
+
+	s.Equal(statusHistory.ID, (*results)[0].ID)
+	s.Equal(statusHistory.FromStatus, (*results)[0].FromStatus)
+	s.Equal(statusHistory.ToStatus, (*results)[0].ToStatus)
+	s.Equal(statusHistory.Actor, (*results)[0].Actor)
+}
+
+func TestOrderStatusHistoryRepository(t *testing.T) {
+	suite.Run(t, new(suiteOrderStatusHistory))
+}
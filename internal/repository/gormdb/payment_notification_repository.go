@@ -0,0 +1,44 @@
+package gormdb
+
+import (
+	"errors"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"gorm.io/gorm"
+)
+
+type PaymentNotificationRepository struct {
+	DB *gorm.DB
+}
+
+func (r PaymentNotificationRepository) Create(notificationUC model.PaymentNotification) error {
+	err := r.DB.Model(&model.PaymentNotification{}).Create(&notificationUC).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r PaymentNotificationRepository) FindByTransaction(transactionId string, transactionStatus string, refundAmount string) (*model.PaymentNotification, error) {
+	notification := &model.PaymentNotification{}
+
+	err := r.DB.Model(&model.PaymentNotification{}).Where("transaction_id = ? AND transaction_status = ? AND refund_amount = ?", transactionId, transactionStatus, refundAmount).Take(&notification).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.ErrRecordNotFound
+		}
+
+		return nil, err
+	}
+
+	return notification, nil
+}
+
+func NewPaymentNotificationRepository(db *gorm.DB) repository.PaymentNotificationRepository {
+	return PaymentNotificationRepository{db}
+}
@@ -0,0 +1,94 @@
+package gormdb
+
+import (
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type suitePaymentNotification struct {
+	suite.Suite
+	mock                          sqlmock.Sqlmock
+	paymentNotificationRepository repository.PaymentNotificationRepository
+}
+
+func (s *suitePaymentNotification) SetupSuite() {
+	var (
+		db  *sql.DB
+		err error
+	)
+
+	db, s.mock, err = sqlmock.New()
+
+	s.NoError(err)
+
+	dbGorm, _ := gorm.Open(mysql.New(mysql.Config{
+		SkipInitializeWithVersion: true,
+		Conn:                      db,
+	}))
+
+	s.paymentNotificationRepository = NewPaymentNotificationRepository(dbGorm)
+}
+
+func (s *suitePaymentNotification) TestCreate() {
+	notificationUC := model.PaymentNotification{
+		ID:                "PNID-1",
+		TransactionId:     "TID-1",
+		TransactionStatus: "settlement",
+		OrderId:           "OID-1",
+		StatusCode:        "200",
+		FraudStatus:       "accept",
+		GrossAmount:       "75000.00",
+		Payload:           "{}",
+		CreatedAt:         time.Now(),
+	}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `payment_notifications` (`id`,`transaction_id`,`transaction_status`,`order_id`,`status_code`,`fraud_status`,`gross_amount`,`refund_amount`,`payload`,`created_at`) VALUES (?,?,?,?,?,?,?,?,?,?)")).
+		WithArgs("PNID-1", "TID-1", "settlement", "OID-1", "200", "accept", "75000.00", "", "{}", pkg.Anytime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.paymentNotificationRepository.Create(notificationUC)
+
+	s.Nil(err)
+}
+
+func (s *suitePaymentNotification) TestFindByTransaction() {
+	row := sqlmock.NewRows([]string{"id", "transaction_id", "transaction_status", "order_id"}).
+		AddRow("PNID-1", "TID-1", "settlement", "OID-1")
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `payment_notifications` WHERE transaction_id = ? AND transaction_status = ? AND refund_amount = ? LIMIT 1")).
+		WithArgs("TID-1", "settlement", "").
+		WillReturnRows(row)
+
+	result, err := s.paymentNotificationRepository.FindByTransaction("TID-1", "settlement", "")
+
+	s.Nil(err)
+	s.NotNil(result)
+
+	s.Equal("OID-1", result.OrderId)
+}
+
+func (s *suitePaymentNotification) TestFindByTransactionNotFound() {
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `payment_notifications` WHERE transaction_id = ? AND transaction_status = ? AND refund_amount = ? LIMIT 1")).
+		WithArgs("TID-1", "partial_refund", "25000.00").
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	result, err := s.paymentNotificationRepository.FindByTransaction("TID-1", "partial_refund", "25000.00")
+
+	s.Nil(result)
+	s.Equal(pkg.ErrRecordNotFound, err)
+}
+
+func TestPaymentNotificationRepository(t *testing.T) {
+	suite.Run(t, new(suitePaymentNotification))
+}
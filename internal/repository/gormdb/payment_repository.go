@@ -2,11 +2,14 @@ package gormdb
 
 import (
 	"errors"
+	"time"
+
 	"github.com/arvinpaundra/go-rent-bike/pkg"
 
 	"github.com/arvinpaundra/go-rent-bike/internal/model"
 	"github.com/arvinpaundra/go-rent-bike/internal/repository"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PaymentRepository struct {
@@ -39,6 +42,51 @@ func (r PaymentRepository) FindById(paymentId string) (*model.Payment, error) {
 	return payment, nil
 }
 
+func (r PaymentRepository) FindByIdForUpdate(paymentId string) (*model.Payment, error) {
+	payment := &model.Payment{}
+
+	err := r.DB.Model(&model.Payment{}).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", paymentId).Take(&payment).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.ErrRecordNotFound
+		}
+
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+func (r PaymentRepository) FindPending(kind string, createdBefore time.Time) (*[]model.Payment, error) {
+	payments := &[]model.Payment{}
+
+	err := r.DB.Model(&model.Payment{}).Where("kind = ? AND payment_status = ? AND created_at < ?", kind, "pending", createdBefore).Order("created_at").Find(&payments).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+// FindUnreconciled finds the payments the payment gateway may still change, the pending ones and the ones waiting for a refund,
+// and the payments settled too late that are still owed back.
+func (r PaymentRepository) FindUnreconciled() (*[]model.Payment, error) {
+	payments := &[]model.Payment{}
+
+	pendingRefunds := r.DB.Model(&model.Refund{}).Select("payment_id").Where("status = ?", model.RefundStatusPending)
+	statuses := []string{model.PaymentStatusPending, model.PaymentStatusLateSettlement}
+
+	err := r.DB.Model(&model.Payment{}).Where("payment_status IN ? OR id IN (?)", statuses, pendingRefunds).Order("created_at").Find(&payments).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
 func (r PaymentRepository) Update(paymentId string, paymentUC model.Payment) error {
 	err := r.DB.Model(&model.Payment{}).Where("id = ?", paymentId).Updates(&paymentUC).Error
 
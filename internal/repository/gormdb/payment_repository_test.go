@@ -41,6 +41,9 @@ func (s *suitePayment) SetupSuite() {
 func (s *suitePayment) TestCreate() {
 	paymentUC := model.Payment{
 		ID:            "PID-1",
+		OrderId:       "OID-1",
+		Kind:          model.PaymentKindRent,
+		Amount:        200000,
 		PaymentStatus: "pending",
 		PaymentType:   "bank_transfer",
 		PaymentLink:   "https://app.sandbox.midtrans.com/snap/redirect/v3/...",
@@ -49,8 +52,8 @@ func (s *suitePayment) TestCreate() {
 	}
 
 	s.mock.ExpectBegin()
-	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `payments` (`payment_status`,`payment_type`,`payment_link`,`created_at`,`updated_at`,`id`) VALUES (?,?,?,?,?,?)")).
-		WithArgs("pending", "bank_transfer", "https://app.sandbox.midtrans.com/snap/redirect/v3/...", pkg.Anytime{}, pkg.Anytime{}, "PID-1").
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `payments` (`order_id`,`wallet_id`,`kind`,`amount`,`deposit`,`payment_status`,`payment_type`,`payment_link`,`created_at`,`updated_at`,`id`) VALUES (?,?,?,?,?,?,?,?,?,?,?)")).
+		WithArgs("OID-1", "", "rent", float32(200000), float32(0), "pending", "bank_transfer", "https://app.sandbox.midtrans.com/snap/redirect/v3/...", pkg.Anytime{}, pkg.Anytime{}, "PID-1").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	s.mock.ExpectCommit()
 
@@ -87,6 +90,72 @@ func (s *suitePayment) TestFindById() {
 	s.Equal(payment.PaymentType, result.PaymentType)
 }
 
+func (s *suitePayment) TestFindByIdForUpdate() {
+	row := sqlmock.NewRows([]string{"id", "order_id", "amount", "payment_status"}).
+		AddRow("PID-1", "OID-1", float32(75000), "settlement")
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `payments` WHERE id = ? LIMIT 1 FOR UPDATE")).
+		WithArgs("PID-1").
+		WillReturnRows(row)
+
+	result, err := s.paymentRepository.FindByIdForUpdate("PID-1")
+
+	s.Nil(err)
+	s.NotNil(result)
+
+	s.Equal("PID-1", result.ID)
+	s.Equal(float32(75000), result.Amount)
+}
+
+func (s *suitePayment) TestFindPending() {
+	createdBefore := time.Date(2022, 11, 20, 8, 0, 0, 0, time.UTC)
+
+	payment := model.Payment{
+		ID:            "PID-2",
+		OrderId:       "OID-1",
+		Kind:          model.PaymentKindExtension,
+		Amount:        45000,
+		PaymentStatus: "pending",
+		CreatedAt:     time.Date(2022, 11, 20, 6, 0, 0, 0, time.UTC),
+	}
+
+	row := sqlmock.NewRows([]string{"id", "order_id", "kind", "amount", "payment_status", "created_at"}).
+		AddRow(payment.ID, payment.OrderId, payment.Kind, payment.Amount, payment.PaymentStatus, payment.CreatedAt)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `payments` WHERE kind = ? AND payment_status = ? AND created_at < ? ORDER BY created_at")).
+		WithArgs("extension", "pending", createdBefore).
+		WillReturnRows(row)
+
+	results, err := s.paymentRepository.FindPending(model.PaymentKindExtension, createdBefore)
+
+	s.Nil(err)
+	s.NotNil(results)
+
+	s.Equal(payment.ID, (*results)[0].ID)
+	s.Equal(payment.OrderId, (*results)[0].OrderId)
+	s.Equal(payment.Kind, (*results)[0].Kind)
+}
+
+func (s *suitePayment) TestFindUnreconciled() {
+	rows := sqlmock.NewRows([]string{"id", "order_id", "kind", "amount", "payment_status"}).
+		AddRow("PID-1", "OID-1", "rent", float32(75000), "pending").
+		AddRow("PID-2", "OID-2", "rent", float32(50000), "partial_refund").
+		AddRow("PID-3", "OID-3", "rent", float32(60000), "late_settlement")
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `payments` WHERE payment_status IN (?,?) OR id IN (SELECT `payment_id` FROM `refunds` WHERE status = ?) ORDER BY created_at")).
+		WithArgs("pending", "late_settlement", "pending").
+		WillReturnRows(rows)
+
+	results, err := s.paymentRepository.FindUnreconciled()
+
+	s.Nil(err)
+	s.NotNil(results)
+
+	s.Len(*results, 3)
+	s.Equal("PID-2", (*results)[1].ID)
+	s.Equal("late_settlement", (*results)[2].PaymentStatus)
+}
+
 func (s *suitePayment) TestUpdate() {
 	paymentUC := model.Payment{
 		PaymentStatus: "settlement",
@@ -0,0 +1,132 @@
+package gormdb
+
+import (
+	"errors"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PayoutRepository struct {
+	DB *gorm.DB
+}
+
+func (r PayoutRepository) CreateBatch(batchUC model.PayoutBatch) error {
+	err := r.DB.Model(&model.PayoutBatch{}).Omit("Payouts").Create(&batchUC).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r PayoutRepository) Create(payoutsUC []model.Payout) error {
+	err := r.DB.Model(&model.Payout{}).Create(&payoutsUC).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r PayoutRepository) FindAllBatches() (*[]model.PayoutBatch, error) {
+	batches := &[]model.PayoutBatch{}
+
+	err := r.DB.Model(&model.PayoutBatch{}).Order("created_at DESC").Find(&batches).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return batches, nil
+}
+
+func (r PayoutRepository) FindBatchById(batchId string) (*model.PayoutBatch, error) {
+	batch := &model.PayoutBatch{}
+
+	err := r.DB.Model(&model.PayoutBatch{}).
+		Where("id = ?", batchId).
+		Preload("Payouts", func(db *gorm.DB) *gorm.DB {
+			return db.Order("renter_id")
+		}).
+		Take(&batch).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.ErrRecordNotFound
+		}
+
+		return nil, err
+	}
+
+	return batch, nil
+}
+
+func (r PayoutRepository) FindByIdForUpdate(payoutId string) (*model.Payout, error) {
+	payout := &model.Payout{}
+
+	err := r.DB.Model(&model.Payout{}).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", payoutId).Take(&payout).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.ErrRecordNotFound
+		}
+
+		return nil, err
+	}
+
+	return payout, nil
+}
+
+func (r PayoutRepository) FindByIdRenter(renterId string) (*[]model.Payout, error) {
+	payouts := &[]model.Payout{}
+
+	err := r.DB.Model(&model.Payout{}).Where("renter_id = ?", renterId).Order("created_at DESC").Find(&payouts).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return payouts, nil
+}
+
+func (r PayoutRepository) CountPending(batchId string) (int64, error) {
+	var count int64
+
+	err := r.DB.Model(&model.Payout{}).Where("batch_id = ? AND status = ?", batchId, model.PayoutStatusPending).Count(&count).Error
+
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r PayoutRepository) Update(payoutId string, payoutUC model.Payout) error {
+	err := r.DB.Model(&model.Payout{}).Where("id = ?", payoutId).Updates(&payoutUC).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r PayoutRepository) UpdateBatch(batchId string, batchUC model.PayoutBatch) error {
+	err := r.DB.Model(&model.PayoutBatch{}).Where("id = ?", batchId).Omit("Payouts").Updates(&batchUC).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func NewPayoutRepository(db *gorm.DB) repository.PayoutRepository {
+	return PayoutRepository{db}
+}
@@ -0,0 +1,143 @@
+package gormdb
+
+import (
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type suitePayout struct {
+	suite.Suite
+	mock             sqlmock.Sqlmock
+	payoutRepository repository.PayoutRepository
+}
+
+func (s *suitePayout) SetupSuite() {
+	var (
+		db  *sql.DB
+		err error
+	)
+
+	db, s.mock, err = sqlmock.New()
+
+	s.NoError(err)
+
+	dbGorm, _ := gorm.Open(mysql.New(mysql.Config{
+		SkipInitializeWithVersion: true,
+		Conn:                      db,
+	}))
+
+	s.payoutRepository = NewPayoutRepository(dbGorm)
+}
+
+func (s *suitePayout) TestCreateBatch() {
+	batchUC := model.PayoutBatch{
+		ID:          "BID-1",
+		Status:      model.PayoutBatchStatusPending,
+		TotalAmount: 67500,
+		TotalPayout: 1,
+		Payouts:     []model.Payout{{ID: "POID-1"}},
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	// the payouts are created on their own
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `payout_batches` (`id`,`status`,`total_amount`,`total_payout`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?)")).
+		WithArgs("BID-1", "pending", float32(67500), 1, pkg.Anytime{}, pkg.Anytime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.payoutRepository.CreateBatch(batchUC)
+
+	s.Nil(err)
+}
+
+func (s *suitePayout) TestFindBatchById() {
+	batchRow := sqlmock.NewRows([]string{"id", "status", "total_amount", "total_payout"}).
+		AddRow("BID-1", "pending", float32(67500), 1)
+	payoutRows := sqlmock.NewRows([]string{"id", "batch_id", "renter_id", "amount", "status"}).
+		AddRow("POID-1", "BID-1", "RID-1", float32(67500), "pending")
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `payout_batches` WHERE id = ? LIMIT 1")).
+		WithArgs("BID-1").
+		WillReturnRows(batchRow)
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `payouts` WHERE `payouts`.`batch_id` = ? ORDER BY renter_id")).
+		WithArgs("BID-1").
+		WillReturnRows(payoutRows)
+
+	result, err := s.payoutRepository.FindBatchById("BID-1")
+
+	s.Nil(err)
+	s.Equal("BID-1", result.ID)
+	s.Len(result.Payouts, 1)
+	s.Equal("RID-1", result.Payouts[0].RenterId)
+}
+
+func (s *suitePayout) TestFindBatchByIdNotFound() {
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `payout_batches` WHERE id = ? LIMIT 1")).
+		WithArgs("BID-2").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	result, err := s.payoutRepository.FindBatchById("BID-2")
+
+	s.Nil(result)
+	s.Equal(pkg.ErrRecordNotFound, err)
+}
+
+func (s *suitePayout) TestFindByIdForUpdate() {
+	rows := sqlmock.NewRows([]string{"id", "batch_id", "status"}).
+		AddRow("POID-1", "BID-1", "pending")
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `payouts` WHERE id = ? LIMIT 1 FOR UPDATE")).
+		WithArgs("POID-1").
+		WillReturnRows(rows)
+
+	result, err := s.payoutRepository.FindByIdForUpdate("POID-1")
+
+	s.Nil(err)
+	s.Equal("BID-1", result.BatchId)
+}
+
+func (s *suitePayout) TestCountPending() {
+	rows := sqlmock.NewRows([]string{"count"}).AddRow(2)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `payouts` WHERE batch_id = ? AND status = ?")).
+		WithArgs("BID-1", "pending").
+		WillReturnRows(rows)
+
+	count, err := s.payoutRepository.CountPending("BID-1")
+
+	s.Nil(err)
+	s.Equal(int64(2), count)
+}
+
+func (s *suitePayout) TestUpdate() {
+	payoutUC := model.Payout{
+		Status:    model.PayoutStatusPaid,
+		Reference: "TRF-1",
+		UpdatedAt: time.Now(),
+	}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("UPDATE `payouts` SET `status`=?,`reference`=?,`updated_at`=? WHERE id = ?")).
+		WithArgs("paid", "TRF-1", pkg.Anytime{}, "POID-1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.payoutRepository.Update("POID-1", payoutUC)
+
+	s.Nil(err)
+}
+
+func TestPayoutRepository(t *testing.T) {
+	suite.Run(t, new(suitePayout))
+}
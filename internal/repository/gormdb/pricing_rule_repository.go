@@ -0,0 +1,62 @@
+package gormdb
+
+import (
+	"time"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"gorm.io/gorm"
+)
+
+type PricingRuleRepository struct {
+	DB *gorm.DB
+}
+
+func (r PricingRuleRepository) Create(pricingRuleUC model.PricingRule) error {
+	err := r.DB.Model(&model.PricingRule{}).Create(&pricingRuleUC).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r PricingRuleRepository) FindByIdRenter(renterId string) (*[]model.PricingRule, error) {
+	pricingRules := &[]model.PricingRule{}
+
+	err := r.DB.Model(&model.PricingRule{}).Where("renter_id = ?", renterId).Order("start_at").Find(&pricingRules).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return pricingRules, nil
+}
+
+// FindActive returns the rules of a renter whose window overlaps the given window.
+func (r PricingRuleRepository) FindActive(renterId string, startAt time.Time, endAt time.Time) (*[]model.PricingRule, error) {
+	pricingRules := &[]model.PricingRule{}
+
+	err := r.DB.Model(&model.PricingRule{}).Where("renter_id = ? AND start_at < ? AND end_at > ?", renterId, endAt, startAt).Order("start_at").Find(&pricingRules).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return pricingRules, nil
+}
+
+func (r PricingRuleRepository) Delete(pricingRuleId string) error {
+	err := r.DB.Model(&model.PricingRule{}).Where("id = ?", pricingRuleId).Delete(&model.PricingRule{}).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func NewPricingRuleRepository(db *gorm.DB) repository.PricingRuleRepository {
+	return PricingRuleRepository{db}
+}
@@ -0,0 +1,115 @@
+package gormdb
+
+import (
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type suitePricingRule struct {
+	suite.Suite
+	mock                  sqlmock.Sqlmock
+	pricingRuleRepository repository.PricingRuleRepository
+}
+
+func (s *suitePricingRule) SetupSuite() {
+	var (
+		db  *sql.DB
+		err error
+	)
+
+	db, s.mock, err = sqlmock.New()
+
+	s.NoError(err)
+
+	dbGorm, _ := gorm.Open(mysql.New(mysql.Config{
+		SkipInitializeWithVersion: true,
+		Conn:                      db,
+	}))
+
+	s.pricingRuleRepository = NewPricingRuleRepository(dbGorm)
+}
+
+func (s *suitePricingRule) TestCreate() {
+	pricingRuleUC := model.PricingRule{
+		ID:         "PRID-1",
+		RenterId:   "RID-1",
+		Name:       "high season",
+		StartAt:    time.Date(2022, 12, 20, 0, 0, 0, 0, time.UTC),
+		EndAt:      time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC),
+		Multiplier: 1.5,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `pricing_rules` (`id`,`renter_id`,`name`,`start_at`,`end_at`,`multiplier`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?)")).
+		WithArgs("PRID-1", "RID-1", "high season", pkg.Anytime{}, pkg.Anytime{}, float32(1.5), pkg.Anytime{}, pkg.Anytime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.pricingRuleRepository.Create(pricingRuleUC)
+
+	s.Nil(err)
+}
+
+func (s *suitePricingRule) TestFindByIdRenter() {
+	row := sqlmock.NewRows([]string{"id", "renter_id", "name", "multiplier"}).
+		AddRow("PRID-1", "RID-1", "high season", 1.5)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `pricing_rules` WHERE renter_id = ? ORDER BY start_at")).
+		WithArgs("RID-1").
+		WillReturnRows(row)
+
+	results, err := s.pricingRuleRepository.FindByIdRenter("RID-1")
+
+	s.Nil(err)
+	s.NotNil(results)
+
+	s.Equal("PRID-1", (*results)[0].ID)
+	s.Equal(float32(1.5), (*results)[0].Multiplier)
+}
+
+func (s *suitePricingRule) TestFindActive() {
+	startAt := time.Date(2022, 12, 24, 8, 0, 0, 0, time.UTC)
+	endAt := time.Date(2022, 12, 24, 12, 0, 0, 0, time.UTC)
+
+	row := sqlmock.NewRows([]string{"id", "renter_id", "name", "multiplier"}).
+		AddRow("PRID-1", "RID-1", "high season", 1.5)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `pricing_rules` WHERE renter_id = ? AND start_at < ? AND end_at > ? ORDER BY start_at")).
+		WithArgs("RID-1", endAt, startAt).
+		WillReturnRows(row)
+
+	results, err := s.pricingRuleRepository.FindActive("RID-1", startAt, endAt)
+
+	s.Nil(err)
+	s.NotNil(results)
+
+	s.Equal("PRID-1", (*results)[0].ID)
+	s.Equal("high season", (*results)[0].Name)
+}
+
+func (s *suitePricingRule) TestDelete() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `pricing_rules` WHERE id = ?")).
+		WithArgs("PRID-1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.pricingRuleRepository.Delete("PRID-1")
+
+	s.Nil(err)
+}
+
+func TestPricingRuleRepository(t *testing.T) {
+	suite.Run(t, new(suitePricingRule))
+}
@@ -0,0 +1,47 @@
+package gormdb
+
+import (
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"gorm.io/gorm"
+)
+
+type RefundRepository struct {
+	DB *gorm.DB
+}
+
+func (r RefundRepository) Create(refundUC model.Refund) error {
+	err := r.DB.Model(&model.Refund{}).Create(&refundUC).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r RefundRepository) FindByIdPayment(paymentId string) (*[]model.Refund, error) {
+	refunds := &[]model.Refund{}
+
+	err := r.DB.Model(&model.Refund{}).Where("payment_id = ?", paymentId).Order("created_at").Find(&refunds).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return refunds, nil
+}
+
+func (r RefundRepository) Update(refundId string, refundUC model.Refund) error {
+	err := r.DB.Model(&model.Refund{}).Where("id = ?", refundId).Updates(&refundUC).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func NewRefundRepository(db *gorm.DB) repository.RefundRepository {
+	return RefundRepository{db}
+}
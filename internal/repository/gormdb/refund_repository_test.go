@@ -0,0 +1,103 @@
+package gormdb
+
+import (
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type suiteRefund struct {
+	suite.Suite
+	mock             sqlmock.Sqlmock
+	refundRepository repository.RefundRepository
+}
+
+func (s *suiteRefund) SetupSuite() {
+	var (
+		db  *sql.DB
+		err error
+	)
+
+	db, s.mock, err = sqlmock.New()
+
+	s.NoError(err)
+
+	dbGorm, _ := gorm.Open(mysql.New(mysql.Config{
+		SkipInitializeWithVersion: true,
+		Conn:                      db,
+	}))
+
+	s.refundRepository = NewRefundRepository(dbGorm)
+}
+
+func (s *suiteRefund) TestCreate() {
+	refundUC := model.Refund{
+		ID:          "RFID-1",
+		PaymentId:   "PID-1",
+		Kind:        model.RefundKindPayment,
+		Amount:      25000,
+		Reason:      "bike was damaged",
+		Status:      model.RefundStatusPending,
+		RequestedBy: "UID-1",
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `refunds` (`id`,`payment_id`,`kind`,`amount`,`reason`,`status`,`requested_by`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?)")).
+		WithArgs("RFID-1", "PID-1", "payment", float32(25000), "bike was damaged", "pending", "UID-1", pkg.Anytime{}, pkg.Anytime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.refundRepository.Create(refundUC)
+
+	s.Nil(err)
+}
+
+func (s *suiteRefund) TestFindByIdPayment() {
+	rows := sqlmock.NewRows([]string{"id", "payment_id", "amount", "status"}).
+		AddRow("RFID-1", "PID-1", float32(25000), "succeeded").
+		AddRow("RFID-2", "PID-1", float32(10000), "pending")
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `refunds` WHERE payment_id = ? ORDER BY created_at")).
+		WithArgs("PID-1").
+		WillReturnRows(rows)
+
+	results, err := s.refundRepository.FindByIdPayment("PID-1")
+
+	s.Nil(err)
+	s.NotNil(results)
+
+	s.Len(*results, 2)
+	s.Equal("RFID-2", (*results)[1].ID)
+	s.Equal(model.RefundStatusPending, (*results)[1].Status)
+}
+
+func (s *suiteRefund) TestUpdate() {
+	refundUC := model.Refund{
+		Status:    model.RefundStatusSucceeded,
+		UpdatedAt: time.Now(),
+	}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("UPDATE `refunds` SET `status`=?,`updated_at`=? WHERE id = ?")).
+		WithArgs("succeeded", pkg.Anytime{}, "RFID-1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.refundRepository.Update("RFID-1", refundUC)
+
+	s.Nil(err)
+}
+
+func TestRefundRepository(t *testing.T) {
+	suite.Run(t, new(suiteRefund))
+}
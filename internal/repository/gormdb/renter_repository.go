@@ -2,6 +2,8 @@ package gormdb
 
 import (
 	"errors"
+	"time"
+
 	"github.com/arvinpaundra/go-rent-bike/internal/model"
 	"github.com/arvinpaundra/go-rent-bike/internal/repository"
 	"github.com/arvinpaundra/go-rent-bike/pkg"
@@ -80,6 +82,21 @@ func (r RenterRepository) Update(renterId string, renterUC model.Renter) error {
 	return nil
 }
 
+// UpdateSuspension suspends the renter since suspendedAt or, with a nil suspendedAt, lifts its suspension.
+func (r RenterRepository) UpdateSuspension(renterId string, suspendedAt *time.Time, reason string) error {
+	err := r.DB.Model(&model.Renter{}).Where("id = ?", renterId).Select("suspended_at", "suspend_reason", "updated_at").Updates(&model.Renter{
+		SuspendedAt:   suspendedAt,
+		SuspendReason: reason,
+		UpdatedAt:     time.Now(),
+	}).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (r RenterRepository) Delete(renterId string) error {
 	err := r.DB.Model(&model.Renter{}).Where("id = ?", renterId).Delete(&model.Renter{}).Error
 
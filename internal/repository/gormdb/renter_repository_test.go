@@ -40,18 +40,23 @@ func (s *suiteRenter) SetupSuite() {
 
 func (s *suiteRenter) TestCreate() {
 	renterUC := model.Renter{
-		ID:          "RID-1",
-		UserId:      "UID-1",
-		RentName:    "Twins' Brother Bike Rental",
-		RentAddress: "Jl Morioh",
-		Description: "Full with description texts",
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		ID:                     "RID-1",
+		UserId:                 "UID-1",
+		RentName:               "Twins' Brother Bike Rental",
+		RentAddress:            "Jl Morioh",
+		Description:            "Full with description texts",
+		FreeCancellationHours:  24,
+		CancellationFeePercent: 50,
+		BankName:               "BCA",
+		BankAccountNumber:      "1234567890",
+		BankAccountName:        "Josuke Higashikata",
+		CreatedAt:              time.Now(),
+		UpdatedAt:              time.Now(),
 	}
 
 	s.mock.ExpectBegin()
-	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `renters` (`id`,`user_id`,`rent_name`,`rent_address`,`description`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?)")).
-		WithArgs("RID-1", "UID-1", "Twins' Brother Bike Rental", "Jl Morioh", "Full with description texts", pkg.Anytime{}, pkg.Anytime{}).
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `renters` (`id`,`user_id`,`rent_name`,`rent_address`,`description`,`free_cancellation_hours`,`cancellation_fee_percent`,`bank_name`,`bank_account_number`,`bank_account_name`,`suspended_at`,`suspend_reason`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?)")).
+		WithArgs("RID-1", "UID-1", "Twins' Brother Bike Rental", "Jl Morioh", "Full with description texts", 24, float32(50), "BCA", "1234567890", "Josuke Higashikata", nil, "", pkg.Anytime{}, pkg.Anytime{}).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	s.mock.ExpectCommit()
 
@@ -90,7 +95,7 @@ func (s *suiteRenter) TestFindAll() {
 	row := sqlmock.NewRows([]string{"id", "fullname", "phone", "address", "role", "email", "created_at", "updated_at"}).
 		AddRow(user.ID, user.Fullname, user.Phone, user.Address, user.Role, user.Email, user.CreatedAt, user.UpdatedAt)
 
-	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT `users`.`id`,`users`.`fullname`,`users`.`phone`,`users`.`address`,`users`.`role`,`users`.`email`,`users`.`created_at`,`users`.`updated_at` FROM `users` WHERE `users`.`id` = ?")).
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT `users`.`id`,`users`.`fullname`,`users`.`phone`,`users`.`address`,`users`.`role`,`users`.`email`,`users`.`email_verified_at`,`users`.`created_at`,`users`.`updated_at` FROM `users` WHERE `users`.`id` = ?")).
 		WithArgs("UID-1").
 		WillReturnRows(row)
 
@@ -136,7 +141,7 @@ func (s *suiteRenter) TestFindById() {
 	row := sqlmock.NewRows([]string{"id", "fullname", "phone", "address", "role", "email", "created_at", "updated_at"}).
 		AddRow(user.ID, user.Fullname, user.Phone, user.Address, user.Role, user.Email, user.CreatedAt, user.UpdatedAt)
 
-	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT `users`.`id`,`users`.`fullname`,`users`.`phone`,`users`.`address`,`users`.`role`,`users`.`email`,`users`.`created_at`,`users`.`updated_at` FROM `users` WHERE `users`.`id` = ?")).
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT `users`.`id`,`users`.`fullname`,`users`.`phone`,`users`.`address`,`users`.`role`,`users`.`email`,`users`.`email_verified_at`,`users`.`created_at`,`users`.`updated_at` FROM `users` WHERE `users`.`id` = ?")).
 		WithArgs("UID-1").
 		WillReturnRows(row)
 
@@ -220,15 +225,17 @@ func (s *suiteRenter) TestFindByIdUser() {
 
 func (s *suiteRenter) TestUpdate() {
 	renterUC := model.Renter{
-		RentName:    "Twins' Brother Bike Rental",
-		RentAddress: "Jl Morioh",
-		Description: "Full with description texts",
-		UpdatedAt:   time.Now(),
+		RentName:               "Twins' Brother Bike Rental",
+		RentAddress:            "Jl Morioh",
+		Description:            "Full with description texts",
+		FreeCancellationHours:  24,
+		CancellationFeePercent: 50,
+		UpdatedAt:              time.Now(),
 	}
 
 	s.mock.ExpectBegin()
-	s.mock.ExpectExec(regexp.QuoteMeta("UPDATE `renters` SET `rent_name`=?,`rent_address`=?,`description`=?,`updated_at`=? WHERE id = ?")).
-		WithArgs("Twins' Brother Bike Rental", "Jl Morioh", "Full with description texts", pkg.Anytime{}, "RID-1").
+	s.mock.ExpectExec(regexp.QuoteMeta("UPDATE `renters` SET `rent_name`=?,`rent_address`=?,`description`=?,`free_cancellation_hours`=?,`cancellation_fee_percent`=?,`updated_at`=? WHERE id = ?")).
+		WithArgs("Twins' Brother Bike Rental", "Jl Morioh", "Full with description texts", 24, float32(50), pkg.Anytime{}, "RID-1").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	s.mock.ExpectCommit()
 
@@ -237,6 +244,30 @@ func (s *suiteRenter) TestUpdate() {
 	s.Nil(err)
 }
 
+func (s *suiteRenter) TestUpdateSuspension() {
+	suspendedAt := time.Now()
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("UPDATE `renters` SET `suspended_at`=?,`suspend_reason`=?,`updated_at`=? WHERE id = ?")).
+		WithArgs(pkg.Anytime{}, "fake bikes listed", pkg.Anytime{}, "RID-1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.renterRepository.UpdateSuspension("RID-1", &suspendedAt, "fake bikes listed")
+
+	s.Nil(err)
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("UPDATE `renters` SET `suspended_at`=?,`suspend_reason`=?,`updated_at`=? WHERE id = ?")).
+		WithArgs(nil, "", pkg.Anytime{}, "RID-1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err = s.renterRepository.UpdateSuspension("RID-1", nil, "")
+
+	s.Nil(err)
+}
+
 func (s *suiteRenter) TestDelete() {
 	s.mock.ExpectBegin()
 	s.mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `renters` WHERE id = ?")).
@@ -1,8 +1,11 @@
 package gormdb
 
 import (
+	"errors"
+
 	"github.com/arvinpaundra/go-rent-bike/internal/model"
 	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
 	"gorm.io/gorm"
 )
 
@@ -34,6 +37,53 @@ func (r ReportRepository) FindAll(renterId string) (*[]model.Report, error) {
 	return reports, nil
 }
 
+// FindAllByStatus lists the reports of every renter in status, oldest first, or all of them with an empty status.
+func (r ReportRepository) FindAllByStatus(status string) (*[]model.Report, error) {
+	reports := &[]model.Report{}
+
+	query := r.DB.Model(&model.Report{})
+
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	err := query.Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Omit("password")
+	}).Order("created_at ASC").Find(&reports).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
+
+func (r ReportRepository) FindById(reportId string) (*model.Report, error) {
+	report := &model.Report{}
+
+	err := r.DB.Model(&model.Report{}).Where("id = ?", reportId).Take(&report).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.ErrRecordNotFound
+		}
+
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (r ReportRepository) Update(reportId string, reportUC model.Report) error {
+	err := r.DB.Model(&model.Report{}).Where("id = ?", reportId).Updates(&reportUC).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func NewReportRepository(db *gorm.DB) repository.ReportRepository {
 	return ReportRepository{db}
 }
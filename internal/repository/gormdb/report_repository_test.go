@@ -45,13 +45,14 @@ func (s *suiteReport) TestCreate() {
 		UserId:     "UID-1",
 		TitleIssue: "Title Issue",
 		BodyIssue:  "Body issue.",
+		Status:     model.ReportStatusOpen,
 		CreatedAt:  time.Now(),
 		UpdatedAt:  time.Now(),
 	}
 
 	s.mock.ExpectBegin()
-	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `reports` (`renter_id`,`user_id`,`title_issue`,`body_issue`,`created_at`,`updated_at`,`id`) VALUES (?,?,?,?,?,?,?)")).
-		WithArgs("RID-1", "UID-1", "Title Issue", "Body issue.", pkg.Anytime{}, pkg.Anytime{}, "ID-1").
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `reports` (`renter_id`,`user_id`,`title_issue`,`body_issue`,`status`,`resolution`,`resolved_by`,`resolved_at`,`created_at`,`updated_at`,`id`) VALUES (?,?,?,?,?,?,?,?,?,?,?)")).
+		WithArgs("RID-1", "UID-1", "Title Issue", "Body issue.", "open", "", "", nil, pkg.Anytime{}, pkg.Anytime{}, "ID-1").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	s.mock.ExpectCommit()
 
@@ -92,7 +93,7 @@ func (s *suiteReport) TestFindAll() {
 	userRow := sqlmock.NewRows([]string{"id", "fullname", "phone", "address", "role", "email", "created_at", "updated_at"}).
 		AddRow(user.ID, user.Fullname, user.Phone, user.Address, user.Role, user.Email, user.CreatedAt, user.UpdatedAt)
 
-	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT `users`.`id`,`users`.`fullname`,`users`.`phone`,`users`.`address`,`users`.`role`,`users`.`email`,`users`.`created_at`,`users`.`updated_at` FROM `users` WHERE `users`.`id` = ?")).
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT `users`.`id`,`users`.`fullname`,`users`.`phone`,`users`.`address`,`users`.`role`,`users`.`email`,`users`.`email_verified_at`,`users`.`created_at`,`users`.`updated_at` FROM `users` WHERE `users`.`id` = ?")).
 		WillReturnRows(userRow)
 
 	results, err := s.reportRepository.FindAll("RID-1")
@@ -107,6 +108,78 @@ func (s *suiteReport) TestFindAll() {
 	s.Equal(report.BodyIssue, (*results)[0].BodyIssue)
 }
 
+func (s *suiteReport) TestFindAllByStatus() {
+	reportRow := sqlmock.NewRows([]string{"id", "renter_id", "user_id", "title_issue", "body_issue", "status"}).
+		AddRow("ID-1", "RID-1", "UID-1", "Title Issue", "Body issue.", model.ReportStatusOpen)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `reports` WHERE status = ? ORDER BY created_at ASC")).
+		WithArgs("open").
+		WillReturnRows(reportRow)
+
+	userRow := sqlmock.NewRows([]string{"id", "fullname"}).AddRow("UID-1", "Arvin Paundra")
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT `users`.`id`,`users`.`fullname`,`users`.`phone`,`users`.`address`,`users`.`role`,`users`.`email`,`users`.`email_verified_at`,`users`.`created_at`,`users`.`updated_at` FROM `users` WHERE `users`.`id` = ?")).
+		WillReturnRows(userRow)
+
+	results, err := s.reportRepository.FindAllByStatus(model.ReportStatusOpen)
+
+	s.Nil(err)
+	s.Len(*results, 1)
+	s.Equal("ID-1", (*results)[0].ID)
+	s.Equal("Arvin Paundra", (*results)[0].User.Fullname)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `reports` ORDER BY created_at ASC")).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	results, err = s.reportRepository.FindAllByStatus("")
+
+	s.Nil(err)
+	s.Empty(*results)
+}
+
+func (s *suiteReport) TestFindById() {
+	reportRow := sqlmock.NewRows([]string{"id", "renter_id", "user_id", "status"}).
+		AddRow("ID-1", "RID-1", "UID-1", model.ReportStatusOpen)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `reports` WHERE id = ? LIMIT 1")).
+		WithArgs("ID-1").
+		WillReturnRows(reportRow)
+
+	result, err := s.reportRepository.FindById("ID-1")
+
+	s.Nil(err)
+	s.Equal(model.ReportStatusOpen, result.Status)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `reports` WHERE id = ? LIMIT 1")).
+		WithArgs("ID-2").
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	result, err = s.reportRepository.FindById("ID-2")
+
+	s.Nil(result)
+	s.ErrorIs(err, pkg.ErrRecordNotFound)
+}
+
+func (s *suiteReport) TestUpdate() {
+	resolvedAt := time.Now()
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("UPDATE `reports` SET `status`=?,`resolution`=?,`resolved_by`=?,`resolved_at`=?,`updated_at`=? WHERE id = ?")).
+		WithArgs("resolved", "renter warned", "AID-1", pkg.Anytime{}, pkg.Anytime{}, "ID-1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.reportRepository.Update("ID-1", model.Report{
+		Status:     model.ReportStatusResolved,
+		Resolution: "renter warned",
+		ResolvedBy: "AID-1",
+		ResolvedAt: &resolvedAt,
+		UpdatedAt:  resolvedAt,
+	})
+
+	s.Nil(err)
+}
+
 func TestReportRepository(t *testing.T) {
 	suite.Run(t, new(suiteReport))
 }
@@ -0,0 +1,124 @@
+package gormdb
+
+import (
+	"errors"
+	"time"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SessionRepository struct {
+	DB *gorm.DB
+}
+
+func (r SessionRepository) Create(sessionUC model.Session) error {
+	err := r.DB.Model(&model.Session{}).Create(&sessionUC).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r SessionRepository) FindByIdForUpdate(sessionId string) (*model.Session, error) {
+	session := &model.Session{}
+
+	err := r.DB.Model(&model.Session{}).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", sessionId).Take(&session).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.ErrRecordNotFound
+		}
+
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// FindByRefreshTokenHashForUpdate also finds the session by the refresh token it rotated away from,
+// so a refresh token used twice can be told apart from one that never existed
+func (r SessionRepository) FindByRefreshTokenHashForUpdate(hash string) (*model.Session, error) {
+	session := &model.Session{}
+
+	err := r.DB.Model(&model.Session{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("refresh_token_hash = ? OR previous_refresh_token_hash = ?", hash, hash).
+		Take(&session).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.ErrRecordNotFound
+		}
+
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (r SessionRepository) FindAllActiveByIdUser(userId string, now time.Time) (*[]model.Session, error) {
+	sessions := &[]model.Session{}
+
+	err := r.DB.Model(&model.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userId, now).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (r SessionRepository) Update(sessionId string, sessionUC model.Session) error {
+	err := r.DB.Model(&model.Session{}).Where("id = ?", sessionId).Updates(&sessionUC).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r SessionRepository) RevokeToken(tokenUC model.RevokedToken) error {
+	err := r.DB.Model(&model.RevokedToken{}).Clauses(clause.OnConflict{DoNothing: true}).Create(&tokenUC).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r SessionRepository) IsTokenRevoked(jti string) (bool, error) {
+	var count int64
+
+	err := r.DB.Model(&model.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error
+
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// DeleteExpiredRevokedTokens forgets the revoked tokens that expired since, they are rejected anyway
+func (r SessionRepository) DeleteExpiredRevokedTokens(now time.Time) error {
+	err := r.DB.Where("expires_at <= ?", now).Delete(&model.RevokedToken{}).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func NewSessionRepository(db *gorm.DB) repository.SessionRepository {
+	return SessionRepository{db}
+}
@@ -0,0 +1,166 @@
+package gormdb
+
+import (
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type suiteSession struct {
+	suite.Suite
+	mock              sqlmock.Sqlmock
+	sessionRepository repository.SessionRepository
+}
+
+func (s *suiteSession) SetupSuite() {
+	var (
+		db  *sql.DB
+		err error
+	)
+
+	db, s.mock, err = sqlmock.New()
+
+	s.NoError(err)
+
+	dbGorm, _ := gorm.Open(mysql.New(mysql.Config{
+		SkipInitializeWithVersion: true,
+		Conn:                      db,
+	}))
+
+	s.sessionRepository = NewSessionRepository(dbGorm)
+}
+
+func (s *suiteSession) TestCreate() {
+	now := time.Now()
+
+	sessionUC := model.Session{
+		ID:                   "SID-1",
+		UserId:               "UID-1",
+		RefreshTokenHash:     "HASH-1",
+		AccessTokenJti:       "JTI-1",
+		AccessTokenExpiresAt: now.Add(time.Hour),
+		UserAgent:            "Mozilla/5.0",
+		IPAddress:            "10.0.0.1",
+		ExpiresAt:            now.Add(720 * time.Hour),
+		LastUsedAt:           now,
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `sessions` (`id`,`user_id`,`refresh_token_hash`,`previous_refresh_token_hash`,`access_token_jti`,`access_token_expires_at`,`user_agent`,`ip_address`,`expires_at`,`last_used_at`,`revoked_at`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?)")).
+		WithArgs("SID-1", "UID-1", "HASH-1", "", "JTI-1", pkg.Anytime{}, "Mozilla/5.0", "10.0.0.1", pkg.Anytime{}, pkg.Anytime{}, nil, pkg.Anytime{}, pkg.Anytime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.sessionRepository.Create(sessionUC)
+
+	s.Nil(err)
+}
+
+func (s *suiteSession) TestFindByRefreshTokenHashForUpdate() {
+	rows := sqlmock.NewRows([]string{"id", "user_id", "refresh_token_hash", "previous_refresh_token_hash"}).
+		AddRow("SID-1", "UID-1", "HASH-2", "HASH-1")
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `sessions` WHERE refresh_token_hash = ? OR previous_refresh_token_hash = ? LIMIT 1 FOR UPDATE")).
+		WithArgs("HASH-1", "HASH-1").
+		WillReturnRows(rows)
+
+	result, err := s.sessionRepository.FindByRefreshTokenHashForUpdate("HASH-1")
+
+	s.Nil(err)
+	s.Equal("SID-1", result.ID)
+	s.Equal("HASH-2", result.RefreshTokenHash)
+}
+
+func (s *suiteSession) TestFindByRefreshTokenHashNotFound() {
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `sessions` WHERE refresh_token_hash = ? OR previous_refresh_token_hash = ? LIMIT 1 FOR UPDATE")).
+		WithArgs("HASH-3", "HASH-3").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	result, err := s.sessionRepository.FindByRefreshTokenHashForUpdate("HASH-3")
+
+	s.Nil(result)
+	s.Equal(pkg.ErrRecordNotFound, err)
+}
+
+func (s *suiteSession) TestFindAllActiveByIdUser() {
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "user_id", "user_agent"}).
+		AddRow("SID-2", "UID-1", "curl/7.85.0").
+		AddRow("SID-1", "UID-1", "Mozilla/5.0")
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `sessions` WHERE user_id = ? AND revoked_at IS NULL AND expires_at > ? ORDER BY last_used_at DESC")).
+		WithArgs("UID-1", now).
+		WillReturnRows(rows)
+
+	result, err := s.sessionRepository.FindAllActiveByIdUser("UID-1", now)
+
+	s.Nil(err)
+	s.Len(*result, 2)
+	s.Equal("SID-2", (*result)[0].ID)
+}
+
+func (s *suiteSession) TestUpdateRevoked() {
+	now := time.Now()
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("UPDATE `sessions` SET `revoked_at`=?,`updated_at`=? WHERE id = ?")).
+		WithArgs(pkg.Anytime{}, pkg.Anytime{}, "SID-1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.sessionRepository.Update("SID-1", model.Session{RevokedAt: &now, UpdatedAt: now})
+
+	s.Nil(err)
+}
+
+func (s *suiteSession) TestRevokeToken() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `revoked_tokens` (`jti`,`expires_at`,`created_at`) VALUES (?,?,?) ON DUPLICATE KEY UPDATE `jti`=`jti`")).
+		WithArgs("JTI-1", pkg.Anytime{}, pkg.Anytime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.sessionRepository.RevokeToken(model.RevokedToken{Jti: "JTI-1", ExpiresAt: time.Now().Add(time.Hour), CreatedAt: time.Now()})
+
+	s.Nil(err)
+}
+
+func (s *suiteSession) TestIsTokenRevoked() {
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `revoked_tokens` WHERE jti = ?")).
+		WithArgs("JTI-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(1))
+
+	revoked, err := s.sessionRepository.IsTokenRevoked("JTI-1")
+
+	s.Nil(err)
+	s.True(revoked)
+}
+
+func (s *suiteSession) TestDeleteExpiredRevokedTokens() {
+	now := time.Now()
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `revoked_tokens` WHERE expires_at <= ?")).
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	s.mock.ExpectCommit()
+
+	err := s.sessionRepository.DeleteExpiredRevokedTokens(now)
+
+	s.Nil(err)
+}
+
+func TestSessionRepository(t *testing.T) {
+	suite.Run(t, new(suiteSession))
+}
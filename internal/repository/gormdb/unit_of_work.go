@@ -0,0 +1,44 @@
+package gormdb
+
+import (
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"gorm.io/gorm"
+)
+
+type UnitOfWork struct {
+	DB *gorm.DB
+}
+
+func (u UnitOfWork) WithTx(fn func(repos repository.Repositories) error) error {
+	return u.DB.Transaction(func(tx *gorm.DB) error {
+		return fn(newRepositories(tx))
+	})
+}
+
+func newRepositories(db *gorm.DB) repository.Repositories {
+	return repository.Repositories{
+		User:                NewUserRepositoryGorm(db),
+		Renter:              NewRenterRepositoryGorm(db),
+		Bike:                NewBikeRepositoryGorm(db),
+		Order:               NewOrderRepository(db),
+		OrderDetail:         NewOrderDetailRepository(db),
+		Payment:             NewPaymentRepository(db),
+		History:             NewHistoryRepository(db),
+		OrderStatusHistory:  NewOrderStatusHistoryRepository(db),
+		PricingRule:         NewPricingRuleRepository(db),
+		Voucher:             NewVoucherRepository(db),
+		PaymentNotification: NewPaymentNotificationRepository(db),
+		Refund:              NewRefundRepository(db),
+		Ledger:              NewLedgerRepository(db),
+		Payout:              NewPayoutRepository(db),
+		Wallet:              NewWalletRepository(db),
+		Invoice:             NewInvoiceRepository(db),
+		Session:             NewSessionRepository(db),
+		UserToken:           NewUserTokenRepository(db),
+		LoginAttempt:        NewLoginAttemptRepository(db),
+	}
+}
+
+func NewUnitOfWork(db *gorm.DB) repository.UnitOfWork {
+	return UnitOfWork{db}
+}
@@ -0,0 +1,111 @@
+package gormdb
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type suiteUnitOfWork struct {
+	suite.Suite
+	mock       sqlmock.Sqlmock
+	unitOfWork repository.UnitOfWork
+}
+
+func (s *suiteUnitOfWork) SetupSuite() {
+	var (
+		db  *sql.DB
+		err error
+	)
+
+	db, s.mock, err = sqlmock.New()
+
+	s.NoError(err)
+
+	dbGorm, _ := gorm.Open(mysql.New(mysql.Config{
+		SkipInitializeWithVersion: true,
+		Conn:                      db,
+	}))
+
+	s.unitOfWork = NewUnitOfWork(dbGorm)
+}
+
+func (s *suiteUnitOfWork) TestWithTxCommit() {
+	paymentUC := model.Payment{
+		ID:            "PID-1",
+		PaymentStatus: "pending",
+		PaymentType:   "bank_transfer",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	historyUC := model.History{
+		ID:         "HID-1",
+		OrderId:    "OID-1",
+		RentStatus: "pending payment",
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `payments` (`order_id`,`wallet_id`,`kind`,`amount`,`deposit`,`payment_status`,`payment_type`,`payment_link`,`created_at`,`updated_at`,`id`) VALUES (?,?,?,?,?,?,?,?,?,?,?)")).
+		WithArgs("", "", "", float32(0), float32(0), "pending", "bank_transfer", "", pkg.Anytime{}, pkg.Anytime{}, "PID-1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `histories` (`id`,`order_id`,`rent_status`,`created_at`,`updated_at`) VALUES (?,?,?,?,?)")).
+		WithArgs("HID-1", "OID-1", "pending payment", pkg.Anytime{}, pkg.Anytime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.unitOfWork.WithTx(func(repos repository.Repositories) error {
+		if err := repos.Payment.Create(paymentUC); err != nil {
+			return err
+		}
+
+		return repos.History.Create(historyUC)
+	})
+
+	s.Nil(err)
+	s.Nil(s.mock.ExpectationsWereMet())
+}
+
+func (s *suiteUnitOfWork) TestWithTxRollback() {
+	paymentUC := model.Payment{
+		ID:            "PID-2",
+		PaymentStatus: "pending",
+		PaymentType:   "bank_transfer",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	errGateway := errors.New("payment gateway unreachable")
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `payments` (`order_id`,`wallet_id`,`kind`,`amount`,`deposit`,`payment_status`,`payment_type`,`payment_link`,`created_at`,`updated_at`,`id`) VALUES (?,?,?,?,?,?,?,?,?,?,?)")).
+		WithArgs("", "", "", float32(0), float32(0), "pending", "bank_transfer", "", pkg.Anytime{}, pkg.Anytime{}, "PID-2").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectRollback()
+
+	err := s.unitOfWork.WithTx(func(repos repository.Repositories) error {
+		if err := repos.Payment.Create(paymentUC); err != nil {
+			return err
+		}
+
+		return errGateway
+	})
+
+	s.ErrorIs(err, errGateway)
+	s.Nil(s.mock.ExpectationsWereMet())
+}
+
+func TestUnitOfWork(t *testing.T) {
+	suite.Run(t, new(suiteUnitOfWork))
+}
@@ -49,8 +49,8 @@ func (s *suiteUser) TestCreate() {
 	}
 
 	s.mock.ExpectBegin()
-	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `users` (`id`,`fullname`,`phone`,`address`,`role`,`email`,`password`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?)")).
-		WithArgs(user.ID, user.Fullname, user.Phone, user.Address, user.Role, user.Email, user.Password, pkg.Anytime{}, pkg.Anytime{}).
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `users` (`id`,`fullname`,`phone`,`address`,`role`,`email`,`password`,`email_verified_at`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?,?)")).
+		WithArgs(user.ID, user.Fullname, user.Phone, user.Address, user.Role, user.Email, user.Password, nil, pkg.Anytime{}, pkg.Anytime{}).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	s.mock.ExpectCommit()
 
@@ -102,7 +102,7 @@ func (s *suiteUser) TestFindAll() {
 	row := sqlmock.NewRows([]string{"id", "fullname", "phone", "address", "role", "email", "password", "created_at", "updated_at"}).
 		AddRow(user.ID, user.Fullname, user.Phone, user.Address, user.Role, user.Email, user.Password, user.CreatedAt, user.UpdatedAt)
 
-	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT `users`.`id`,`users`.`fullname`,`users`.`phone`,`users`.`address`,`users`.`role`,`users`.`email`,`users`.`created_at`,`users`.`updated_at` FROM `users`")).
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT `users`.`id`,`users`.`fullname`,`users`.`phone`,`users`.`address`,`users`.`role`,`users`.`email`,`users`.`email_verified_at`,`users`.`created_at`,`users`.`updated_at` FROM `users`")).
 		WillReturnRows(row)
 
 	results, err := s.userRepository.FindAll()
@@ -133,7 +133,7 @@ func (s *suiteUser) TestFindById() {
 	row := sqlmock.NewRows([]string{"id", "fullname", "phone", "address", "role", "email", "password", "created_at", "updated_at"}).
 		AddRow(user.ID, user.Fullname, user.Phone, user.Address, user.Role, user.Email, user.Password, user.CreatedAt, user.UpdatedAt)
 
-	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT `users`.`id`,`users`.`fullname`,`users`.`phone`,`users`.`address`,`users`.`role`,`users`.`email`,`users`.`created_at`,`users`.`updated_at` FROM `users`")).
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT `users`.`id`,`users`.`fullname`,`users`.`phone`,`users`.`address`,`users`.`role`,`users`.`email`,`users`.`email_verified_at`,`users`.`created_at`,`users`.`updated_at` FROM `users`")).
 		WithArgs("ID-1").
 		WillReturnRows(row)
 
@@ -0,0 +1,69 @@
+package gormdb
+
+import (
+	"errors"
+	"time"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type UserTokenRepository struct {
+	DB *gorm.DB
+}
+
+func (r UserTokenRepository) Create(tokenUC model.UserToken) error {
+	err := r.DB.Model(&model.UserToken{}).Create(&tokenUC).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r UserTokenRepository) FindByTokenHashForUpdate(hash string) (*model.UserToken, error) {
+	token := &model.UserToken{}
+
+	err := r.DB.Model(&model.UserToken{}).Clauses(clause.Locking{Strength: "UPDATE"}).Where("token_hash = ?", hash).Take(&token).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.ErrRecordNotFound
+		}
+
+		return nil, err
+	}
+
+	return token, nil
+}
+
+func (r UserTokenRepository) MarkUsed(tokenId string, usedAt time.Time) error {
+	err := r.DB.Model(&model.UserToken{}).Where("id = ?", tokenId).Update("used_at", usedAt).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarkAllUsed uses up the tokens of a user sent for purpose that were not used yet, only the last one sent works
+func (r UserTokenRepository) MarkAllUsed(userId string, purpose string, usedAt time.Time) error {
+	err := r.DB.Model(&model.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userId, purpose).
+		Update("used_at", usedAt).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func NewUserTokenRepository(db *gorm.DB) repository.UserTokenRepository {
+	return UserTokenRepository{db}
+}
@@ -0,0 +1,120 @@
+package gormdb
+
+import (
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type suiteUserToken struct {
+	suite.Suite
+	mock                sqlmock.Sqlmock
+	userTokenRepository repository.UserTokenRepository
+}
+
+func (s *suiteUserToken) SetupSuite() {
+	var (
+		db  *sql.DB
+		err error
+	)
+
+	db, s.mock, err = sqlmock.New()
+
+	s.NoError(err)
+
+	dbGorm, _ := gorm.Open(mysql.New(mysql.Config{
+		SkipInitializeWithVersion: true,
+		Conn:                      db,
+	}))
+
+	s.userTokenRepository = NewUserTokenRepository(dbGorm)
+}
+
+func (s *suiteUserToken) TestCreate() {
+	now := time.Now()
+
+	tokenUC := model.UserToken{
+		ID:        "TID-1",
+		UserId:    "UID-1",
+		Purpose:   model.UserTokenPasswordReset,
+		TokenHash: "HASH-1",
+		ExpiresAt: now.Add(30 * time.Minute),
+		CreatedAt: now,
+	}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `user_tokens` (`id`,`user_id`,`purpose`,`token_hash`,`expires_at`,`used_at`,`created_at`) VALUES (?,?,?,?,?,?,?)")).
+		WithArgs("TID-1", "UID-1", model.UserTokenPasswordReset, "HASH-1", pkg.Anytime{}, nil, pkg.Anytime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.userTokenRepository.Create(tokenUC)
+
+	s.Nil(err)
+}
+
+func (s *suiteUserToken) TestFindByTokenHashForUpdate() {
+	rows := sqlmock.NewRows([]string{"id", "user_id", "purpose", "token_hash"}).
+		AddRow("TID-1", "UID-1", model.UserTokenEmailVerification, "HASH-1")
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `user_tokens` WHERE token_hash = ? LIMIT 1 FOR UPDATE")).
+		WithArgs("HASH-1").
+		WillReturnRows(rows)
+
+	result, err := s.userTokenRepository.FindByTokenHashForUpdate("HASH-1")
+
+	s.Nil(err)
+	s.Equal("TID-1", result.ID)
+	s.Equal(model.UserTokenEmailVerification, result.Purpose)
+}
+
+func (s *suiteUserToken) TestFindByTokenHashNotFound() {
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `user_tokens` WHERE token_hash = ? LIMIT 1 FOR UPDATE")).
+		WithArgs("HASH-2").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	result, err := s.userTokenRepository.FindByTokenHashForUpdate("HASH-2")
+
+	s.Nil(result)
+	s.Equal(pkg.ErrRecordNotFound, err)
+}
+
+func (s *suiteUserToken) TestMarkUsed() {
+	now := time.Now()
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("UPDATE `user_tokens` SET `used_at`=? WHERE id = ?")).
+		WithArgs(now, "TID-1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.userTokenRepository.MarkUsed("TID-1", now)
+
+	s.Nil(err)
+}
+
+func (s *suiteUserToken) TestMarkAllUsed() {
+	now := time.Now()
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("UPDATE `user_tokens` SET `used_at`=? WHERE user_id = ? AND purpose = ? AND used_at IS NULL")).
+		WithArgs(now, "UID-1", model.UserTokenPasswordReset).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	s.mock.ExpectCommit()
+
+	err := s.userTokenRepository.MarkAllUsed("UID-1", model.UserTokenPasswordReset, now)
+
+	s.Nil(err)
+}
+
+func TestUserTokenRepository(t *testing.T) {
+	suite.Run(t, new(suiteUserToken))
+}
@@ -0,0 +1,144 @@
+package gormdb
+
+import (
+	"errors"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type VoucherRepository struct {
+	DB *gorm.DB
+}
+
+func (r VoucherRepository) Create(voucherUC model.Voucher) error {
+	err := r.DB.Model(&model.Voucher{}).Create(&voucherUC).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r VoucherRepository) FindAll(renterId string) (*[]model.Voucher, error) {
+	vouchers := &[]model.Voucher{}
+
+	err := r.DB.Model(&model.Voucher{}).Where("renter_id = ?", renterId).Order("created_at").Find(&vouchers).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return vouchers, nil
+}
+
+func (r VoucherRepository) FindById(voucherId string) (*model.Voucher, error) {
+	voucher := &model.Voucher{}
+
+	err := r.DB.Model(&model.Voucher{}).Where("id = ?", voucherId).Take(&voucher).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.ErrRecordNotFound
+		}
+
+		return nil, err
+	}
+
+	return voucher, nil
+}
+
+func (r VoucherRepository) FindByCode(code string) (*model.Voucher, error) {
+	voucher := &model.Voucher{}
+
+	err := r.DB.Model(&model.Voucher{}).Where("code = ?", code).Take(&voucher).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.ErrRecordNotFound
+		}
+
+		return nil, err
+	}
+
+	return voucher, nil
+}
+
+// FindByCodeForUpdate locks the voucher until the transaction ends, so concurrent orders
+// can not both take the last use of a limited voucher.
+func (r VoucherRepository) FindByCodeForUpdate(code string) (*model.Voucher, error) {
+	voucher := &model.Voucher{}
+
+	err := r.DB.Model(&model.Voucher{}).Clauses(clause.Locking{Strength: "UPDATE"}).Where("code = ?", code).Take(&voucher).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.ErrRecordNotFound
+		}
+
+		return nil, err
+	}
+
+	return voucher, nil
+}
+
+// CountUsages counts the uses of a voucher, by one user when userId is not empty. Uses by orders
+// that were canceled, expired or denied are given back and not counted.
+func (r VoucherRepository) CountUsages(voucherId string, userId string) (int64, error) {
+	var count int64
+
+	db := r.DB.Model(&model.VoucherUsage{}).
+		Joins("JOIN orders ON orders.id = voucher_usages.order_id").
+		Where("voucher_usages.voucher_id = ?", voucherId).
+		Where("orders.status NOT IN ?", []model.OrderStatus{model.OrderStatusCanceled, model.OrderStatusExpired, model.OrderStatusDenied})
+
+	if userId != "" {
+		db = db.Where("voucher_usages.user_id = ?", userId)
+	}
+
+	err := db.Count(&count).Error
+
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r VoucherRepository) CreateUsage(voucherUsageUC model.VoucherUsage) error {
+	err := r.DB.Model(&model.VoucherUsage{}).Create(&voucherUsageUC).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r VoucherRepository) Update(voucherId string, voucherUC model.Voucher) error {
+	err := r.DB.Model(&model.Voucher{}).Where("id = ?", voucherId).Updates(&voucherUC).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r VoucherRepository) Delete(voucherId string) error {
+	err := r.DB.Model(&model.Voucher{}).Where("id = ?", voucherId).Delete(&model.Voucher{}).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func NewVoucherRepository(db *gorm.DB) repository.VoucherRepository {
+	return VoucherRepository{db}
+}
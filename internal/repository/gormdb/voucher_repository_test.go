@@ -0,0 +1,223 @@
+package gormdb
+
+import (
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type suiteVoucher struct {
+	suite.Suite
+	mock              sqlmock.Sqlmock
+	voucherRepository repository.VoucherRepository
+}
+
+func (s *suiteVoucher) SetupSuite() {
+	var (
+		db  *sql.DB
+		err error
+	)
+
+	db, s.mock, err = sqlmock.New()
+
+	s.NoError(err)
+
+	dbGorm, _ := gorm.Open(mysql.New(mysql.Config{
+		SkipInitializeWithVersion: true,
+		Conn:                      db,
+	}))
+
+	s.voucherRepository = NewVoucherRepository(dbGorm)
+}
+
+func (s *suiteVoucher) TestCreate() {
+	voucherUC := model.Voucher{
+		ID:            "VID-1",
+		RenterId:      "RID-1",
+		Code:          "HEMAT10",
+		DiscountType:  model.VoucherDiscountPercentage,
+		DiscountValue: 10,
+		MaxDiscount:   20000,
+		MinSpend:      50000,
+		UsageLimit:    100,
+		PerUserLimit:  1,
+		StartAt:       time.Date(2022, 12, 1, 0, 0, 0, 0, time.UTC),
+		EndAt:         time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC),
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `vouchers` (`id`,`renter_id`,`code`,`discount_type`,`discount_value`,`max_discount`,`min_spend`,`usage_limit`,`per_user_limit`,`start_at`,`end_at`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?)")).
+		WithArgs("VID-1", "RID-1", "HEMAT10", "percentage", float32(10), float32(20000), float32(50000), 100, 1, pkg.Anytime{}, pkg.Anytime{}, pkg.Anytime{}, pkg.Anytime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.voucherRepository.Create(voucherUC)
+
+	s.Nil(err)
+}
+
+func (s *suiteVoucher) TestFindAll() {
+	row := sqlmock.NewRows([]string{"id", "renter_id", "code", "discount_type", "discount_value"}).
+		AddRow("VID-1", "RID-1", "HEMAT10", "percentage", 10)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `vouchers` WHERE renter_id = ? ORDER BY created_at")).
+		WithArgs("RID-1").
+		WillReturnRows(row)
+
+	results, err := s.voucherRepository.FindAll("RID-1")
+
+	s.Nil(err)
+	s.NotNil(results)
+
+	s.Equal("VID-1", (*results)[0].ID)
+	s.Equal("HEMAT10", (*results)[0].Code)
+}
+
+func (s *suiteVoucher) TestFindById() {
+	row := sqlmock.NewRows([]string{"id", "renter_id", "code"}).
+		AddRow("VID-1", "RID-1", "HEMAT10")
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `vouchers` WHERE id = ? LIMIT 1")).
+		WithArgs("VID-1").
+		WillReturnRows(row)
+
+	result, err := s.voucherRepository.FindById("VID-1")
+
+	s.Nil(err)
+	s.NotNil(result)
+
+	s.Equal("HEMAT10", result.Code)
+}
+
+func (s *suiteVoucher) TestFindByIdNotFound() {
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `vouchers` WHERE id = ? LIMIT 1")).
+		WithArgs("VID-2").
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	result, err := s.voucherRepository.FindById("VID-2")
+
+	s.Nil(result)
+	s.Equal(pkg.ErrRecordNotFound, err)
+}
+
+func (s *suiteVoucher) TestFindByCode() {
+	row := sqlmock.NewRows([]string{"id", "renter_id", "code"}).
+		AddRow("VID-1", "RID-1", "HEMAT10")
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `vouchers` WHERE code = ? LIMIT 1")).
+		WithArgs("HEMAT10").
+		WillReturnRows(row)
+
+	result, err := s.voucherRepository.FindByCode("HEMAT10")
+
+	s.Nil(err)
+	s.NotNil(result)
+
+	s.Equal("VID-1", result.ID)
+}
+
+func (s *suiteVoucher) TestFindByCodeForUpdate() {
+	row := sqlmock.NewRows([]string{"id", "renter_id", "code"}).
+		AddRow("VID-1", "RID-1", "HEMAT10")
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `vouchers` WHERE code = ? LIMIT 1 FOR UPDATE")).
+		WithArgs("HEMAT10").
+		WillReturnRows(row)
+
+	result, err := s.voucherRepository.FindByCodeForUpdate("HEMAT10")
+
+	s.Nil(err)
+	s.NotNil(result)
+
+	s.Equal("VID-1", result.ID)
+}
+
+func (s *suiteVoucher) TestCountUsages() {
+	row := sqlmock.NewRows([]string{"count"}).AddRow(2)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `voucher_usages` JOIN orders ON orders.id = voucher_usages.order_id WHERE voucher_usages.voucher_id = ? AND orders.status NOT IN (?,?,?)")).
+		WithArgs("VID-1", "canceled", "expired", "denied").
+		WillReturnRows(row)
+
+	count, err := s.voucherRepository.CountUsages("VID-1", "")
+
+	s.Nil(err)
+	s.Equal(int64(2), count)
+}
+
+func (s *suiteVoucher) TestCountUsagesByUser() {
+	row := sqlmock.NewRows([]string{"count"}).AddRow(1)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `voucher_usages` JOIN orders ON orders.id = voucher_usages.order_id WHERE voucher_usages.voucher_id = ? AND orders.status NOT IN (?,?,?) AND voucher_usages.user_id = ?")).
+		WithArgs("VID-1", "canceled", "expired", "denied", "UID-1").
+		WillReturnRows(row)
+
+	count, err := s.voucherRepository.CountUsages("VID-1", "UID-1")
+
+	s.Nil(err)
+	s.Equal(int64(1), count)
+}
+
+func (s *suiteVoucher) TestCreateUsage() {
+	voucherUsageUC := model.VoucherUsage{
+		ID:        "VUID-1",
+		VoucherId: "VID-1",
+		UserId:    "UID-1",
+		OrderId:   "OID-1",
+		Discount:  15000,
+		CreatedAt: time.Now(),
+	}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `voucher_usages` (`id`,`voucher_id`,`user_id`,`order_id`,`discount`,`created_at`) VALUES (?,?,?,?,?,?)")).
+		WithArgs("VUID-1", "VID-1", "UID-1", "OID-1", float32(15000), pkg.Anytime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.voucherRepository.CreateUsage(voucherUsageUC)
+
+	s.Nil(err)
+}
+
+func (s *suiteVoucher) TestUpdate() {
+	voucherUC := model.Voucher{
+		DiscountValue: 15,
+		UpdatedAt:     time.Now(),
+	}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("UPDATE `vouchers` SET `discount_value`=?,`updated_at`=? WHERE id = ?")).
+		WithArgs(float32(15), pkg.Anytime{}, "VID-1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.voucherRepository.Update("VID-1", voucherUC)
+
+	s.Nil(err)
+}
+
+func (s *suiteVoucher) TestDelete() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `vouchers` WHERE id = ?")).
+		WithArgs("VID-1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.voucherRepository.Delete("VID-1")
+
+	s.Nil(err)
+}
+
+func TestVoucherRepository(t *testing.T) {
+	suite.Run(t, new(suiteVoucher))
+}
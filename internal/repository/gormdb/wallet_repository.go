@@ -0,0 +1,110 @@
+package gormdb
+
+import (
+	"errors"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WalletRepository struct {
+	DB *gorm.DB
+}
+
+func (r WalletRepository) Create(walletUC model.Wallet) error {
+	err := r.DB.Model(&model.Wallet{}).Create(&walletUC).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r WalletRepository) FindByIdUser(userId string) (*model.Wallet, error) {
+	wallet := &model.Wallet{}
+
+	err := r.DB.Model(&model.Wallet{}).Where("user_id = ?", userId).Take(&wallet).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.ErrRecordNotFound
+		}
+
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
+func (r WalletRepository) FindByIdUserForUpdate(userId string) (*model.Wallet, error) {
+	wallet := &model.Wallet{}
+
+	err := r.DB.Model(&model.Wallet{}).Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userId).Take(&wallet).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.ErrRecordNotFound
+		}
+
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
+func (r WalletRepository) FindByIdForUpdate(walletId string) (*model.Wallet, error) {
+	wallet := &model.Wallet{}
+
+	err := r.DB.Model(&model.Wallet{}).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", walletId).Take(&wallet).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.ErrRecordNotFound
+		}
+
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
+// UpdateBalance always writes the balance, a wallet spent down to zero must be saved as well
+func (r WalletRepository) UpdateBalance(walletId string, walletUC model.Wallet) error {
+	err := r.DB.Model(&model.Wallet{}).Where("id = ?", walletId).Select("balance", "updated_at").Updates(&walletUC).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r WalletRepository) CreateTransaction(transactionUC model.WalletTransaction) error {
+	err := r.DB.Model(&model.WalletTransaction{}).Create(&transactionUC).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r WalletRepository) FindTransactions(walletId string) (*[]model.WalletTransaction, error) {
+	transactions := &[]model.WalletTransaction{}
+
+	err := r.DB.Model(&model.WalletTransaction{}).Where("wallet_id = ?", walletId).Order("created_at DESC").Find(&transactions).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+func NewWalletRepository(db *gorm.DB) repository.WalletRepository {
+	return WalletRepository{db}
+}
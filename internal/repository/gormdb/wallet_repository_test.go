@@ -0,0 +1,140 @@
+package gormdb
+
+import (
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type suiteWallet struct {
+	suite.Suite
+	mock             sqlmock.Sqlmock
+	walletRepository repository.WalletRepository
+}
+
+func (s *suiteWallet) SetupSuite() {
+	var (
+		db  *sql.DB
+		err error
+	)
+
+	db, s.mock, err = sqlmock.New()
+
+	s.NoError(err)
+
+	dbGorm, _ := gorm.Open(mysql.New(mysql.Config{
+		SkipInitializeWithVersion: true,
+		Conn:                      db,
+	}))
+
+	s.walletRepository = NewWalletRepository(dbGorm)
+}
+
+func (s *suiteWallet) TestCreate() {
+	walletUC := model.Wallet{
+		ID:        "WID-1",
+		UserId:    "UID-1",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `wallets` (`id`,`user_id`,`balance`,`created_at`,`updated_at`) VALUES (?,?,?,?,?)")).
+		WithArgs("WID-1", "UID-1", float32(0), pkg.Anytime{}, pkg.Anytime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.walletRepository.Create(walletUC)
+
+	s.Nil(err)
+}
+
+func (s *suiteWallet) TestFindByIdUserForUpdate() {
+	rows := sqlmock.NewRows([]string{"id", "user_id", "balance"}).
+		AddRow("WID-1", "UID-1", float32(50000))
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `wallets` WHERE user_id = ? LIMIT 1 FOR UPDATE")).
+		WithArgs("UID-1").
+		WillReturnRows(rows)
+
+	result, err := s.walletRepository.FindByIdUserForUpdate("UID-1")
+
+	s.Nil(err)
+	s.Equal("WID-1", result.ID)
+	s.Equal(float32(50000), result.Balance)
+}
+
+func (s *suiteWallet) TestFindByIdUserNotFound() {
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `wallets` WHERE user_id = ? LIMIT 1")).
+		WithArgs("UID-2").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	result, err := s.walletRepository.FindByIdUser("UID-2")
+
+	s.Nil(result)
+	s.Equal(pkg.ErrRecordNotFound, err)
+}
+
+func (s *suiteWallet) TestUpdateBalanceToZero() {
+	// a wallet spent down to zero still saves its balance
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("UPDATE `wallets` SET `balance`=?,`updated_at`=? WHERE id = ?")).
+		WithArgs(float32(0), pkg.Anytime{}, "WID-1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.walletRepository.UpdateBalance("WID-1", model.Wallet{Balance: 0, UpdatedAt: time.Now()})
+
+	s.Nil(err)
+}
+
+func (s *suiteWallet) TestCreateTransaction() {
+	transactionUC := model.WalletTransaction{
+		ID:           "WTID-1",
+		WalletId:     "WID-1",
+		Type:         model.WalletTransactionPayment,
+		Amount:       -50000,
+		BalanceAfter: 0,
+		PaymentId:    "PID-1",
+		Description:  "payment of order OID-1",
+		CreatedAt:    time.Now(),
+	}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `wallet_transactions` (`id`,`wallet_id`,`type`,`amount`,`balance_after`,`payment_id`,`refund_id`,`description`,`created_at`) VALUES (?,?,?,?,?,?,?,?,?)")).
+		WithArgs("WTID-1", "WID-1", "payment", float32(-50000), float32(0), "PID-1", "", "payment of order OID-1", pkg.Anytime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.walletRepository.CreateTransaction(transactionUC)
+
+	s.Nil(err)
+}
+
+func (s *suiteWallet) TestFindTransactions() {
+	rows := sqlmock.NewRows([]string{"id", "wallet_id", "type", "amount", "balance_after"}).
+		AddRow("WTID-2", "WID-1", "payment", float32(-50000), float32(0)).
+		AddRow("WTID-1", "WID-1", "top_up", float32(50000), float32(50000))
+
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `wallet_transactions` WHERE wallet_id = ? ORDER BY created_at DESC")).
+		WithArgs("WID-1").
+		WillReturnRows(rows)
+
+	result, err := s.walletRepository.FindTransactions("WID-1")
+
+	s.Nil(err)
+	s.Len(*result, 2)
+	s.Equal("WTID-2", (*result)[0].ID)
+}
+
+func TestWalletRepository(t *testing.T) {
+	suite.Run(t, new(suiteWallet))
+}
@@ -1,9 +1,40 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/arvinpaundra/go-rent-bike/internal/model"
 )
 
+// Repositories groups the repositories that can take part in a single unit of work.
+type Repositories struct {
+	User                UserRepository
+	Renter              RenterRepository
+	Bike                BikeRepository
+	Order               OrderRepository
+	OrderDetail         OrderDetailRepository
+	Payment             PaymentRepository
+	History             HistoryRepository
+	OrderStatusHistory  OrderStatusHistoryRepository
+	PricingRule         PricingRuleRepository
+	Voucher             VoucherRepository
+	PaymentNotification PaymentNotificationRepository
+	Refund              RefundRepository
+	Ledger              LedgerRepository
+	Payout              PayoutRepository
+	Wallet              WalletRepository
+	Invoice             InvoiceRepository
+	Session             SessionRepository
+	UserToken           UserTokenRepository
+	LoginAttempt        LoginAttemptRepository
+}
+
+// UnitOfWork runs fn inside one database transaction. The repositories handed to fn are bound to
+// that transaction, which is committed when fn returns nil and rolled back when it returns an error.
+type UnitOfWork interface {
+	WithTx(fn func(repos Repositories) error) error
+}
+
 type UserRepository interface {
 	Create(userUC model.User) error
 	FindByEmail(email string) (*model.User, error)
@@ -27,6 +58,7 @@ type RenterRepository interface {
 	FindById(renterId string) (*model.Renter, error)
 	FindByIdUser(userId string) (*model.Renter, error)
 	Update(renterId string, renterUC model.Renter) error
+	UpdateSuspension(renterId string, suspendedAt *time.Time, reason string) error
 	Delete(renterId string) error
 }
 
@@ -34,8 +66,11 @@ type BikeRepository interface {
 	Create(bikeUC model.Bike) error
 	FindAll(bikeName string) (*[]model.Bike, error)
 	FindById(bikeId string) (*model.Bike, error)
+	FindByIdsForUpdate(bikeIds []string) (*[]model.Bike, error)
+	FindByIdOrderForUpdate(orderId string) (*[]model.Bike, error)
 	FindByIdRenter(renterId string) (*[]model.Bike, error)
 	FindByIdCategory(categoryId string) (*[]model.Bike, error)
+	FindOverlappingOrders(bikeId string, startAt time.Time, endAt time.Time) (*[]model.Order, error)
 	Update(bikeId string, bikeUC model.Bike) error
 	Delete(bikeId string) error
 }
@@ -43,6 +78,9 @@ type BikeRepository interface {
 type PaymentRepository interface {
 	Create(paymentUC model.Payment) error
 	FindById(paymentId string) (*model.Payment, error)
+	FindByIdForUpdate(paymentId string) (*model.Payment, error)
+	FindPending(kind string, createdBefore time.Time) (*[]model.Payment, error)
+	FindUnreconciled() (*[]model.Payment, error)
 	Update(paymentId string, paymentUC model.Payment) error
 }
 
@@ -50,6 +88,10 @@ type OrderRepository interface {
 	Create(orderUC model.Order) error
 	FindAll(userId string) (*[]model.Order, error)
 	FindById(orderId string) (*model.Order, error)
+	FindByIdForUpdate(orderId string) (*model.Order, error)
+	FindPendingPayment(createdBefore time.Time) (*[]model.Order, error)
+	Update(orderId string, orderUC model.Order) error
+	UpdatePendingEndAt(orderId string, pendingEndAt *time.Time) error
 }
 
 type OrderDetailRepository interface {
@@ -68,7 +110,109 @@ type HistoryRepository interface {
 	Update(orderId string, historyUC model.History) error
 }
 
+type OrderStatusHistoryRepository interface {
+	Create(statusHistoryUC model.OrderStatusHistory) error
+	FindByIdOrder(orderId string) (*[]model.OrderStatusHistory, error)
+}
+
+type PaymentNotificationRepository interface {
+	Create(notificationUC model.PaymentNotification) error
+	FindByTransaction(transactionId string, transactionStatus string, refundAmount string) (*model.PaymentNotification, error)
+}
+
+type RefundRepository interface {
+	Create(refundUC model.Refund) error
+	FindByIdPayment(paymentId string) (*[]model.Refund, error)
+	Update(refundId string, refundUC model.Refund) error
+}
+
+type LedgerRepository interface {
+	Create(entriesUC []model.LedgerEntry) error
+	FindByIdPayment(paymentId string) (*[]model.LedgerEntry, error)
+	FindByIdRenter(renterId string) (*[]model.LedgerEntry, error)
+	FindRenterBalance(renterId string) (*model.RenterBalance, error)
+	FindRenterBalancesForUpdate() (*[]model.RenterBalance, error)
+}
+
+type PayoutRepository interface {
+	CreateBatch(batchUC model.PayoutBatch) error
+	Create(payoutsUC []model.Payout) error
+	FindAllBatches() (*[]model.PayoutBatch, error)
+	FindBatchById(batchId string) (*model.PayoutBatch, error)
+	FindByIdForUpdate(payoutId string) (*model.Payout, error)
+	FindByIdRenter(renterId string) (*[]model.Payout, error)
+	CountPending(batchId string) (int64, error)
+	Update(payoutId string, payoutUC model.Payout) error
+	UpdateBatch(batchId string, batchUC model.PayoutBatch) error
+}
+
+type PricingRuleRepository interface {
+	Create(pricingRuleUC model.PricingRule) error
+	FindByIdRenter(renterId string) (*[]model.PricingRule, error)
+	FindActive(renterId string, startAt time.Time, endAt time.Time) (*[]model.PricingRule, error)
+	Delete(pricingRuleId string) error
+}
+
+type VoucherRepository interface {
+	Create(voucherUC model.Voucher) error
+	FindAll(renterId string) (*[]model.Voucher, error)
+	FindById(voucherId string) (*model.Voucher, error)
+	FindByCode(code string) (*model.Voucher, error)
+	FindByCodeForUpdate(code string) (*model.Voucher, error)
+	CountUsages(voucherId string, userId string) (int64, error)
+	CreateUsage(voucherUsageUC model.VoucherUsage) error
+	Update(voucherId string, voucherUC model.Voucher) error
+	Delete(voucherId string) error
+}
+
 type ReportRepository interface {
 	Create(reportUC model.Report) error
 	FindAll(renterId string) (*[]model.Report, error)
+	FindAllByStatus(status string) (*[]model.Report, error)
+	FindById(reportId string) (*model.Report, error)
+	Update(reportId string, reportUC model.Report) error
+}
+
+type WalletRepository interface {
+	Create(walletUC model.Wallet) error
+	FindByIdUser(userId string) (*model.Wallet, error)
+	FindByIdUserForUpdate(userId string) (*model.Wallet, error)
+	FindByIdForUpdate(walletId string) (*model.Wallet, error)
+	UpdateBalance(walletId string, walletUC model.Wallet) error
+	CreateTransaction(transactionUC model.WalletTransaction) error
+	FindTransactions(walletId string) (*[]model.WalletTransaction, error)
+}
+
+type InvoiceRepository interface {
+	Create(invoiceUC model.Invoice) error
+	FindByIdOrder(orderId string) (*[]model.Invoice, error)
+	FindLastByIdRenterForUpdate(renterId string) (*model.Invoice, error)
+}
+
+type SessionRepository interface {
+	Create(sessionUC model.Session) error
+	FindByIdForUpdate(sessionId string) (*model.Session, error)
+	FindByRefreshTokenHashForUpdate(hash string) (*model.Session, error)
+	FindAllActiveByIdUser(userId string, now time.Time) (*[]model.Session, error)
+	Update(sessionId string, sessionUC model.Session) error
+	RevokeToken(tokenUC model.RevokedToken) error
+	IsTokenRevoked(jti string) (bool, error)
+	DeleteExpiredRevokedTokens(now time.Time) error
+}
+
+type UserTokenRepository interface {
+	Create(tokenUC model.UserToken) error
+	FindByTokenHashForUpdate(hash string) (*model.UserToken, error)
+	MarkUsed(tokenId string, usedAt time.Time) error
+	MarkAllUsed(userId string, purpose string, usedAt time.Time) error
+}
+
+type LoginAttemptRepository interface {
+	Create(attemptUC model.LoginAttempt) error
+	FindAll(email string, ipAddress string, limit int) (*[]model.LoginAttempt, error)
+	FindThrottle(key string) (*model.LoginThrottle, error)
+	FindThrottleForUpdate(key string) (*model.LoginThrottle, error)
+	IncrementThrottle(key string, failedAt time.Time, windowStart time.Time) error
+	LockThrottle(key string, lockedUntil time.Time) error
+	DeleteThrottle(key string) error
 }
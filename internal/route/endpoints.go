@@ -0,0 +1,183 @@
+package route
+
+import (
+	"net/http"
+
+	controller "github.com/arvinpaundra/go-rent-bike/internal/controller/rest-http"
+	mddlwrs "github.com/arvinpaundra/go-rent-bike/internal/middlewares"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/policy"
+	"github.com/labstack/echo/v4"
+)
+
+// endpoint is a route of the api with the middlewares run before its handler.
+type endpoint struct {
+	method      string
+	path        string
+	handler     echo.HandlerFunc
+	middlewares []echo.MiddlewareFunc
+}
+
+// controllers holds the handlers of the endpoints.
+type controllers struct {
+	user           *controller.UserController
+	auth           *controller.AuthController
+	renter         *controller.RenterController
+	payout         *controller.PayoutController
+	category       *controller.CategoryController
+	bike           *controller.BikeController
+	voucher        *controller.VoucherController
+	order          *controller.OrderController
+	invoice        *controller.InvoiceController
+	wallet         *controller.WalletController
+	refund         *controller.RefundController
+	paymentGateway *controller.MidtransNotificationController
+}
+
+// guards holds the middlewares checking who may call an endpoint.
+type guards struct {
+	jwtAuth           echo.MiddlewareFunc
+	isRenter          echo.MiddlewareFunc
+	isAdmin           echo.MiddlewareFunc
+	isRenterOrAdmin   echo.MiddlewareFunc
+	ownsCustomer      echo.MiddlewareFunc
+	ownsCustomerOrder echo.MiddlewareFunc
+	ownsRenter        echo.MiddlewareFunc
+	ownsBike          echo.MiddlewareFunc
+	ownsOrder         echo.MiddlewareFunc
+	placedOrder       echo.MiddlewareFunc
+	rentsOrder        echo.MiddlewareFunc
+}
+
+// newGuards checks the access token with jwtAuth, then lets users only act on the accounts, renters, bikes
+// and orders they own.
+func newGuards(jwtAuth echo.MiddlewareFunc, ownership policy.Policy) guards {
+	return guards{
+		jwtAuth:         jwtAuth,
+		isRenter:        mddlwrs.RequireRole(model.RoleRenter),
+		isAdmin:         mddlwrs.RequireRole(model.RoleAdmin),
+		isRenterOrAdmin: mddlwrs.RequireRole(model.RoleRenter, model.RoleAdmin),
+		ownsCustomer:    mddlwrs.AuthorizeParam("id", ownership.AuthorizeCustomer),
+		ownsCustomerOrder: mddlwrs.Authorize(func(c echo.Context, actorId string) error {
+			return ownership.AuthorizeCustomerOrder(actorId, c.Param("id"), c.Param("orderId"))
+		}),
+		ownsRenter:  mddlwrs.AuthorizeParam("id", ownership.AuthorizeRenter),
+		ownsBike:    mddlwrs.AuthorizeParam("id", ownership.AuthorizeBike),
+		ownsOrder:   mddlwrs.AuthorizeParam("id", ownership.AuthorizeOrder),
+		placedOrder: mddlwrs.AuthorizeParam("id", ownership.AuthorizeOrderCustomer),
+		rentsOrder:  mddlwrs.AuthorizeParam("id", ownership.AuthorizeOrderRenter),
+	}
+}
+
+func with(middlewares ...echo.MiddlewareFunc) []echo.MiddlewareFunc {
+	return middlewares
+}
+
+// endpoints lists every route of the api under /api/v1, it is the one place their middlewares are set.
+// The routes only there with some configuration, the fake payments and the payout batches, are left out.
+func endpoints(c controllers, g guards) []endpoint {
+	return []endpoint{
+		// midtrans notif
+		{http.MethodPost, "/webhook/midtrans", c.paymentGateway.HandlerNotification, nil},
+
+		// user auth
+		{http.MethodPost, "/auth/register", c.user.HandlerRegister, nil},
+		{http.MethodPost, "/auth/login", c.auth.HandlerLogin, nil},
+		{http.MethodPost, "/auth/refresh", c.auth.HandlerRefreshToken, nil},
+		{http.MethodPost, "/auth/forgot-password", c.auth.HandlerForgotPassword, nil},
+		{http.MethodPost, "/auth/reset-password", c.auth.HandlerResetPassword, nil},
+		{http.MethodPost, "/auth/verify-email", c.auth.HandlerVerifyEmail, nil},
+		{http.MethodPost, "/auth/verify-email/resend", c.auth.HandlerResendEmailVerification, with(g.jwtAuth)},
+		{http.MethodPost, "/auth/logout", c.auth.HandlerLogout, with(g.jwtAuth)},
+		{http.MethodGet, "/auth/sessions", c.auth.HandlerFindAllSessions, with(g.jwtAuth)},
+		{http.MethodDelete, "/auth/sessions/:id", c.auth.HandlerRevokeSession, with(g.jwtAuth)},
+
+		// customer
+		{http.MethodGet, "/customers/:id/histories", c.user.HandlerFindAllUserHistories, with(g.jwtAuth, g.ownsCustomer)},
+		{http.MethodGet, "/customers/:id/orders", c.user.HandlerFindAllOrdersUser, with(g.jwtAuth, g.ownsCustomer)},
+		{http.MethodGet, "/customers/:id/orders/:orderId", c.user.HandlerFindByIdOrderUser, with(g.jwtAuth, g.ownsCustomerOrder)},
+		{http.MethodGet, "/customers/:id", c.user.HandlerFindUserById, with(g.jwtAuth, g.ownsCustomer)},
+		{http.MethodPut, "/customers/:id", c.user.HandlerUpdateUser, with(g.jwtAuth, g.ownsCustomer)},
+		{http.MethodDelete, "/customers/:id", c.user.HandlerDeleteUser, with(g.jwtAuth, g.ownsCustomer)},
+
+		// renter
+		{http.MethodPost, "/renters", c.renter.HandlerCreateRenter, with(g.jwtAuth)},
+		{http.MethodGet, "/renters", c.renter.HandlerFindAllRenters, nil},
+		{http.MethodGet, "/renters/:id", c.renter.HandlerFindRenterById, nil},
+		{http.MethodPost, "/renters/:id/reports", c.renter.HandlerCreateReportRenter, nil},
+		{http.MethodGet, "/renters/:id/reports", c.renter.HandlerFindAllRenterReports, nil},
+		{http.MethodPost, "/renters/:id/pricing-rules", c.renter.HandlerCreatePricingRule, with(g.jwtAuth, g.isRenter, g.ownsRenter)},
+		{http.MethodGet, "/renters/:id/pricing-rules", c.renter.HandlerFindAllPricingRules, nil},
+		{http.MethodDelete, "/renters/:id/pricing-rules/:ruleId", c.renter.HandlerDeletePricingRule, with(g.jwtAuth, g.isRenter, g.ownsRenter)},
+		{http.MethodGet, "/renters/:id/earnings", c.payout.HandlerFindRenterEarnings, with(g.jwtAuth, g.isRenter, g.ownsRenter)},
+		{http.MethodGet, "/renters/:id/payouts", c.payout.HandlerFindAllRenterPayouts, with(g.jwtAuth, g.isRenter, g.ownsRenter)},
+		{http.MethodPut, "/renters/:id", c.renter.HandlerUpdateRenter, with(g.jwtAuth, g.isRenter, g.ownsRenter)},
+		{http.MethodDelete, "/renters/:id", c.renter.HandlerDeleteRenter, with(g.jwtAuth, g.isRenter, g.ownsRenter)},
+
+		// category
+		{http.MethodPost, "/categories", c.category.HandlerCreateCategory, with(g.jwtAuth, g.isRenter)},
+		{http.MethodGet, "/categories", c.category.HandlerFindAllCategories, nil},
+		{http.MethodGet, "/categories/:id", c.category.HandlerFindCategoryById, nil},
+		{http.MethodPut, "/categories/:id", c.category.HandlerUpdateCategory, with(g.jwtAuth, g.isRenter)},
+		{http.MethodDelete, "/categories/:id", c.category.HandlerDeleteCategory, with(g.jwtAuth, g.isRenter)},
+
+		// bike
+		{http.MethodPost, "/bikes", c.bike.HandlerAddNewBike, with(g.jwtAuth, g.isRenter)},
+		{http.MethodGet, "/bikes", c.bike.HandlerFindAllBikes, nil},
+		{http.MethodGet, "/bikes/renters/:renterId", c.bike.HandlerFindBikesByRenter, nil},
+		{http.MethodGet, "/bikes/categories/:categoryId", c.bike.HandlerFindBikesByCategory, nil},
+		{http.MethodGet, "/bikes/:id", c.bike.HandlerFindByIdBike, nil},
+		{http.MethodGet, "/bikes/:id/availability", c.bike.HandlerCheckBikeAvailability, nil},
+		{http.MethodGet, "/bikes/:id/quote", c.bike.HandlerQuoteBike, nil},
+		{http.MethodPut, "/bikes/:id", c.bike.HandlerUpdateBike, with(g.jwtAuth, g.isRenter, g.ownsBike)},
+		{http.MethodDelete, "/bikes/:id", c.bike.HandlerDeleteBike, with(g.jwtAuth, g.isRenter, g.ownsBike)},
+		{http.MethodPost, "/bikes/:id/reviews", c.bike.HandlerCreateNewBikeReview, with(g.jwtAuth)},
+
+		// voucher
+		{http.MethodPost, "/vouchers", c.voucher.HandlerCreateVoucher, with(g.jwtAuth, g.isRenter)},
+		{http.MethodGet, "/vouchers", c.voucher.HandlerFindAllVouchers, with(g.jwtAuth, g.isRenter)},
+		{http.MethodGet, "/vouchers/:id", c.voucher.HandlerFindVoucherById, with(g.jwtAuth, g.isRenter)},
+		{http.MethodPut, "/vouchers/:id", c.voucher.HandlerUpdateVoucher, with(g.jwtAuth, g.isRenter)},
+		{http.MethodDelete, "/vouchers/:id", c.voucher.HandlerDeleteVoucher, with(g.jwtAuth, g.isRenter)},
+
+		// order, placed for the user of the token
+		{http.MethodPost, "/orders", c.order.HandlerCreateNewOrder, with(g.jwtAuth)},
+		// the customer cancels and extends an order, the renters hand the bikes over and take them back
+		{http.MethodPost, "/orders/:id/pickup", c.order.HandlerPickupBike, with(g.jwtAuth, g.rentsOrder)},
+		{http.MethodGet, "/orders/:id/return", c.order.HandlerReturnBike, with(g.jwtAuth, g.rentsOrder)},
+		{http.MethodPost, "/orders/:id/cancel", c.order.HandlerCancelOrder, with(g.jwtAuth, g.placedOrder)},
+		{http.MethodPost, "/orders/:id/extend", c.order.HandlerExtendOrder, with(g.jwtAuth, g.placedOrder)},
+		{http.MethodPost, "/orders/:id/deposit", c.order.HandlerSettleDeposit, with(g.jwtAuth, g.rentsOrder)},
+		{http.MethodGet, "/orders/:id/statuses", c.order.HandlerFindOrderStatusHistories, with(g.jwtAuth, g.ownsOrder)},
+
+		// invoice
+		{http.MethodGet, "/orders/:id/invoice.pdf", c.invoice.HandlerDownloadInvoice, with(g.jwtAuth, g.ownsOrder)},
+		{http.MethodGet, "/orders/:id/receipt.pdf", c.invoice.HandlerDownloadReceipt, with(g.jwtAuth, g.ownsOrder)},
+
+		// wallet
+		{http.MethodGet, "/wallet", c.wallet.HandlerFindWallet, with(g.jwtAuth)},
+		{http.MethodGet, "/wallet/transactions", c.wallet.HandlerFindAllWalletTransactions, with(g.jwtAuth)},
+		{http.MethodPost, "/wallet/top-ups", c.wallet.HandlerTopUpWallet, with(g.jwtAuth)},
+
+		// refund, by the renters of the order or by an admin
+		{http.MethodPost, "/payments/:id/refunds", c.refund.HandlerCreateRefund, with(g.jwtAuth, g.isRenterOrAdmin)},
+		{http.MethodGet, "/payments/:id/refunds", c.refund.HandlerFindAllRefunds, with(g.jwtAuth, g.isRenterOrAdmin)},
+
+		// admin back-office, admins are created with the create-admin command
+		{http.MethodGet, "/admin/users", c.user.HandlerFindAllUsers, with(g.jwtAuth, g.isAdmin)},
+		{http.MethodGet, "/admin/users/:id", c.user.HandlerFindUserById, with(g.jwtAuth, g.isAdmin)},
+		{http.MethodDelete, "/admin/users/:id", c.user.HandlerDeleteUser, with(g.jwtAuth, g.isAdmin)},
+		{http.MethodPut, "/admin/renters/:id/suspension", c.renter.HandlerSuspendRenter, with(g.jwtAuth, g.isAdmin)},
+		{http.MethodDelete, "/admin/renters/:id/suspension", c.renter.HandlerUnsuspendRenter, with(g.jwtAuth, g.isAdmin)},
+		{http.MethodGet, "/admin/reports", c.renter.HandlerFindAllReports, with(g.jwtAuth, g.isAdmin)},
+		{http.MethodPut, "/admin/reports/:id", c.renter.HandlerResolveReport, with(g.jwtAuth, g.isAdmin)},
+		{http.MethodGet, "/admin/orders/:id/statuses", c.order.HandlerFindOrderStatusHistories, with(g.jwtAuth, g.isAdmin)},
+		{http.MethodPost, "/admin/orders/:id/status", c.order.HandlerOverrideOrderStatus, with(g.jwtAuth, g.isAdmin)},
+		{http.MethodGet, "/admin/login-attempts", c.auth.HandlerFindAllLoginAttempts, with(g.jwtAuth, g.isAdmin)},
+		{http.MethodPost, "/admin/vouchers", c.voucher.HandlerCreatePlatformVoucher, with(g.jwtAuth, g.isAdmin)},
+		{http.MethodGet, "/admin/vouchers", c.voucher.HandlerFindAllPlatformVouchers, with(g.jwtAuth, g.isAdmin)},
+		{http.MethodGet, "/admin/vouchers/:id", c.voucher.HandlerFindPlatformVoucherById, with(g.jwtAuth, g.isAdmin)},
+		{http.MethodPut, "/admin/vouchers/:id", c.voucher.HandlerUpdatePlatformVoucher, with(g.jwtAuth, g.isAdmin)},
+		{http.MethodDelete, "/admin/vouchers/:id", c.voucher.HandlerDeletePlatformVoucher, with(g.jwtAuth, g.isAdmin)},
+	}
+}
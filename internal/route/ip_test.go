@@ -0,0 +1,77 @@
+package route
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	controller "github.com/arvinpaundra/go-rent-bike/internal/controller/rest-http"
+	"github.com/arvinpaundra/go-rent-bike/internal/mailer"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/usecase"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNewIPExtractor_LoginThrottleKey(t *testing.T) {
+	pkg.UserRepository.Mock.On("FindByEmail", mock.Anything).Return(nil, pkg.ErrRecordNotFound)
+	pkg.LoginAttemptRepository.Mock.On("FindThrottle", mock.Anything).Return((*model.LoginThrottle)(nil), pkg.ErrRecordNotFound)
+	pkg.LoginAttemptRepository.Mock.On("IncrementThrottle", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	pkg.LoginAttemptRepository.Mock.On("Create", mock.Anything).Return(nil)
+
+	authUsecase := usecase.NewAuthUsecase(&pkg.UnitOfWork, &pkg.UserRepository, &pkg.SessionRepository, &pkg.LoginAttemptRepository, mailer.NewMemoryMailer(), usecase.SessionPolicy{}, usecase.AccountPolicy{}, usecase.LoginPolicy{})
+	authController := controller.NewAuthController(authUsecase)
+
+	testCases := []struct {
+		Name           string
+		TrustedProxies string
+		RemoteAddr     string
+		ForwardedFor   string
+		ThrottleKey    string
+	}{
+		{"no trusted proxy ignores the headers", "", "192.0.2.10:52114", "198.51.100.7", "ip:192.0.2.10"},
+		{"untrusted proxy ignores the headers", "10.0.0.0/8", "192.0.2.11:52114", "198.51.100.7", "ip:192.0.2.11"},
+		{"trusted proxy forwards the client", "10.0.0.1", "10.0.0.1:52114", "198.51.100.8", "ip:198.51.100.8"},
+		{"trusted proxy skips the spoofed hops", "10.0.0.0/8", "10.0.0.1:52114", "198.51.100.7, 192.0.2.12", "ip:192.0.2.12"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ipExtractor, err := NewIPExtractor(tc.TrustedProxies)
+			assert.Nil(t, err)
+
+			e := echo.New()
+			e.IPExtractor = ipExtractor
+			e.POST("/auth/login", authController.HandlerLogin)
+
+			req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"email":"diavolo@mail.com","password":"123"}`))
+			req.RemoteAddr = tc.RemoteAddr
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Forwarded-For", tc.ForwardedFor)
+			req.Header.Set("X-Real-IP", "198.51.100.7")
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusConflict, rec.Code)
+			pkg.LoginAttemptRepository.Mock.AssertCalled(t, "FindThrottle", tc.ThrottleKey)
+			pkg.LoginAttemptRepository.Mock.AssertCalled(t, "IncrementThrottle", tc.ThrottleKey, mock.Anything, mock.Anything)
+		})
+	}
+
+	// the spoofed address never reaches the throttle nor the audit
+	pkg.LoginAttemptRepository.Mock.AssertNotCalled(t, "FindThrottle", "ip:198.51.100.7")
+	pkg.LoginAttemptRepository.Mock.AssertNotCalled(t, "IncrementThrottle", "ip:198.51.100.7", mock.Anything, mock.Anything)
+	pkg.LoginAttemptRepository.Mock.AssertNotCalled(t, "Create", mock.MatchedBy(func(attempt model.LoginAttempt) bool {
+		return attempt.IPAddress == "198.51.100.7"
+	}))
+}
+
+func TestNewIPExtractor_InvalidProxy(t *testing.T) {
+	_, err := NewIPExtractor("10.0.0.0/8, proxy.local")
+
+	assert.NotNil(t, err)
+}
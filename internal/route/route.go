@@ -1,11 +1,22 @@
 package route
 
 import (
+	"context"
+	"crypto/subtle"
+	"strings"
+	"time"
+
 	"github.com/arvinpaundra/go-rent-bike/configs"
 	controller "github.com/arvinpaundra/go-rent-bike/internal/controller/rest-http"
+	"github.com/arvinpaundra/go-rent-bike/internal/mailer"
 	mddlwrs "github.com/arvinpaundra/go-rent-bike/internal/middlewares"
+	pgMidtrans "github.com/arvinpaundra/go-rent-bike/internal/midtrans"
+	"github.com/arvinpaundra/go-rent-bike/internal/payment"
+	"github.com/arvinpaundra/go-rent-bike/internal/policy"
+	"github.com/arvinpaundra/go-rent-bike/internal/pricing"
 	"github.com/arvinpaundra/go-rent-bike/internal/repository/gormdb"
 	"github.com/arvinpaundra/go-rent-bike/internal/usecase"
+	"github.com/arvinpaundra/go-rent-bike/internal/worker"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"gorm.io/gorm"
@@ -22,86 +33,161 @@ func New(db *gorm.DB, e *echo.Echo) {
 	orderRepository := gormdb.NewOrderRepository(db)
 	orderDetailRepository := gormdb.NewOrderDetailRepository(db)
 	historyRepository := gormdb.NewHistoryRepository(db)
+	orderStatusHistoryRepository := gormdb.NewOrderStatusHistoryRepository(db)
 	reportRepository := gormdb.NewReportRepository(db)
 	reviewRepository := gormdb.NewReviewRepositoryGorm(db)
 	paymentRepository := gormdb.NewPaymentRepository(db)
+	pricingRuleRepository := gormdb.NewPricingRuleRepository(db)
+	voucherRepository := gormdb.NewVoucherRepository(db)
+	refundRepository := gormdb.NewRefundRepository(db)
+	ledgerRepository := gormdb.NewLedgerRepository(db)
+	payoutRepository := gormdb.NewPayoutRepository(db)
+	walletRepository := gormdb.NewWalletRepository(db)
+	sessionRepository := gormdb.NewSessionRepository(db)
+	loginAttemptRepository := gormdb.NewLoginAttemptRepository(db)
+	unitOfWork := gormdb.NewUnitOfWork(db)
+
+	// pick the payment provider, the fake one keeps the payments in memory to run without midtrans
+	var paymentProvider payment.PaymentProvider
+	fakePaymentProvider := payment.NewFakeProvider(configs.Cfg.FakePaymentBaseURL)
+
+	switch configs.Cfg.PaymentProvider {
+	case payment.ProviderMidtrans:
+		paymentProvider = pgMidtrans.NewPaymentGateway(configs.Cfg.MidtransServerKeyDev)
+	case payment.ProviderFake:
+		paymentProvider = fakePaymentProvider
+	default:
+		e.Logger.Fatalf("unknown payment provider %q", configs.Cfg.PaymentProvider)
+	}
+
+	// requests the gateway failed for a moment are sent again when that cannot charge or refund twice
+	paymentProvider = payment.NewRetryProvider(
+		paymentProvider,
+		configs.Cfg.PaymentRetryAttempts,
+		time.Duration(configs.Cfg.PaymentRetryBackoffMS)*time.Millisecond,
+	)
+
+	// pick the mailer, the file one writes the emails to a directory to run without an smtp server
+	var appMailer mailer.Mailer
+
+	switch configs.Cfg.Mailer {
+	case mailer.ProviderSMTP:
+		appMailer = mailer.NewSMTPMailer(configs.Cfg.SMTPHost, configs.Cfg.SMTPPort, configs.Cfg.SMTPUsername, configs.Cfg.SMTPPassword, configs.Cfg.MailFrom)
+	case mailer.ProviderFile:
+		appMailer = mailer.NewFileMailer(configs.Cfg.MailDir, configs.Cfg.MailFrom)
+	case mailer.ProviderMemory:
+		appMailer = mailer.NewMemoryMailer()
+	default:
+		e.Logger.Fatalf("unknown mailer %q", configs.Cfg.Mailer)
+	}
+
+	accountPolicy := usecase.AccountPolicy{
+		AppBaseURL:      strings.TrimSuffix(configs.Cfg.AppBaseURL, "/"),
+		VerificationTTL: time.Duration(configs.Cfg.EmailVerificationTTLHours) * time.Hour,
+		ResetTTL:        time.Duration(configs.Cfg.PasswordResetTTLMinutes) * time.Minute,
+	}
+
+	// the same pricing engine quotes bikes and charges orders
+	pricingEngine := pricing.NewEngine(strings.Split(configs.Cfg.PricingHolidays, ","))
 
 	// inject usecase with repository
-	userUsecase := usecase.NewUserUsecase(userRepository, historyRepository, orderRepository)
-	renterUsecase := usecase.NewRenterUsecase(renterRepository, userRepository, reportRepository)
+	userUsecase := usecase.NewUserUsecase(userRepository, historyRepository, orderRepository, unitOfWork, appMailer, accountPolicy)
+	authUsecase := usecase.NewAuthUsecase(unitOfWork, userRepository, sessionRepository, loginAttemptRepository, appMailer, usecase.SessionPolicy{
+		AccessTokenTTL:  time.Duration(configs.Cfg.AccessTokenTTLMinutes) * time.Minute,
+		RefreshTokenTTL: time.Duration(configs.Cfg.RefreshTokenTTLHours) * time.Hour,
+	}, accountPolicy, usecase.LoginPolicy{
+		FailureWindow:      time.Duration(configs.Cfg.LoginFailureWindowMinutes) * time.Minute,
+		DelayAfterFailures: configs.Cfg.LoginDelayAfterFailures,
+		LockoutFailures:    configs.Cfg.LoginLockoutFailures,
+		IPLockoutFailures:  configs.Cfg.LoginIPLockoutFailures,
+		LockoutDuration:    time.Duration(configs.Cfg.LoginLockoutMinutes) * time.Minute,
+	})
+	renterUsecase := usecase.NewRenterUsecase(renterRepository, userRepository, reportRepository, pricingRuleRepository)
 	categoryUsecase := usecase.NewCategoryUsecase(categoryRepository)
-	bikeUsecase := usecase.NewBikeUsecase(bikeRepository, renterRepository, categoryRepository, userRepository, reviewRepository)
+	voucherUsecase := usecase.NewVoucherUsecase(voucherRepository, renterRepository)
+	bikeUsecase := usecase.NewBikeUsecase(bikeRepository, renterRepository, categoryRepository, userRepository, reviewRepository, pricingRuleRepository, pricingEngine)
 	orderUsecase := usecase.NewOrderUsecase(
+		unitOfWork,
+		paymentProvider,
 		orderRepository,
 		orderDetailRepository,
 		userRepository,
 		bikeRepository,
 		paymentRepository,
 		historyRepository,
+		orderStatusHistoryRepository,
+		usecase.OrderPolicy{
+			PaymentTTL:        time.Duration(configs.Cfg.OrderPaymentTTLMinutes) * time.Minute,
+			LateReturnGrace:   time.Duration(configs.Cfg.LateReturnGraceMinutes) * time.Minute,
+			CommissionPercent: configs.Cfg.PlatformCommissionPercent,
+		},
+		pricingEngine,
 	)
 
+	refundUsecase := usecase.NewRefundUsecase(unitOfWork, paymentProvider, renterRepository, paymentRepository, orderDetailRepository, refundRepository)
+	payoutUsecase := usecase.NewPayoutUsecase(unitOfWork, renterRepository, ledgerRepository, payoutRepository)
+	walletUsecase := usecase.NewWalletUsecase(unitOfWork, paymentProvider, userRepository, walletRepository, usecase.WalletPolicy{
+		PaymentTTL: time.Duration(configs.Cfg.OrderPaymentTTLMinutes) * time.Minute,
+	})
+	invoiceUsecase := usecase.NewInvoiceUsecase(unitOfWork, usecase.InvoicePolicy{
+		TaxName:    configs.Cfg.InvoiceTaxName,
+		TaxPercent: configs.Cfg.InvoiceTaxPercent,
+	})
+
+	// expire the orders that are never paid, so their bikes can be booked again
+	orderExpiryWorker := worker.NewOrderExpiryWorker(orderUsecase, time.Duration(configs.Cfg.OrderExpiryIntervalSeconds)*time.Second)
+	go orderExpiryWorker.Start(context.Background())
+
 	// midtrans notif
-	paymentGatewayUsecase := usecase.NewPaymentGatewayUsecase(orderRepository, paymentRepository, historyRepository)
+	paymentGatewayUsecase := usecase.NewPaymentGatewayUsecase(unitOfWork, paymentProvider, configs.Cfg.MidtransServerKeyDev, configs.Cfg.PlatformCommissionPercent, orderRepository, paymentRepository, historyRepository, refundRepository)
 	paymentGatewayController := controller.NewMidtransNotificationController(paymentGatewayUsecase)
 
-	v1.POST("/webhook/midtrans", paymentGatewayController.HandlerNotification)
-
-	//	user auth
-	userController := controller.NewUserController(userUsecase)
-
-	auth := v1.Group("/auth")
-	auth.POST("/register", userController.HandlerRegister)
-	auth.POST("/login", userController.HandlerLogin)
-
-	// customer
-	u := v1.Group("/customers", middleware.JWT([]byte(configs.Cfg.JWTSecret)))
-	u.GET("", userController.HandlerFindAllUsers)
-	u.GET("/:id/histories", userController.HandlerFindAllUserHistories)
-	u.GET("/:id/orders", userController.HandlerFindAllOrdersUser)
-	u.GET("/:id/orders/:orderId", userController.HandlerFindByIdOrderUser)
-	u.GET("/:id", userController.HandlerFindUserById)
-	u.PUT("/:id", userController.HandlerUpdateUser)
-	u.DELETE("/:id", userController.HandlerDeleteUser)
-
-	// renter
-	renterController := controller.NewRenterController(renterUsecase)
-
-	r := v1.Group("/renters")
-	r.POST("", renterController.HandlerCreateRenter, middleware.JWT([]byte(configs.Cfg.JWTSecret)))
-	r.GET("", renterController.HandlerFindAllRenters)
-	r.GET("/:id", renterController.HandlerFindRenterById)
-	r.POST("/:id/reports", renterController.HandlerCreateReportRenter)
-	r.GET("/:id/reports", renterController.HandlerFindAllRenterReports)
-	r.PUT("/:id", renterController.HandlerUpdateRenter, middleware.JWT([]byte(configs.Cfg.JWTSecret)), mddlwrs.CheckIsRenter)
-	r.DELETE("/:id", renterController.HandlerDeleteRenter, middleware.JWT([]byte(configs.Cfg.JWTSecret)), mddlwrs.CheckIsRenter)
-
-	// category
-	categoryController := controller.NewCategoryController(categoryUsecase)
-
-	c := v1.Group("/categories")
-	c.POST("", categoryController.HandlerCreateCategory, middleware.JWT([]byte(configs.Cfg.JWTSecret)), mddlwrs.CheckIsRenter)
-	c.GET("", categoryController.HandlerFindAllCategories)
-	c.GET("/:id", categoryController.HandlerFindCategoryById)
-	c.PUT("/:id", categoryController.HandlerUpdateCategory, middleware.JWT([]byte(configs.Cfg.JWTSecret)), mddlwrs.CheckIsRenter)
-	c.DELETE("/:id", categoryController.HandlerDeleteCategory, middleware.JWT([]byte(configs.Cfg.JWTSecret)), mddlwrs.CheckIsRenter)
-
-	// bike
-	bikeController := controller.NewBikeController(bikeUsecase)
-
-	b := v1.Group("/bikes")
-	b.POST("", bikeController.HandlerAddNewBike, middleware.JWT([]byte(configs.Cfg.JWTSecret)), mddlwrs.CheckIsRenter)
-	b.GET("", bikeController.HandlerFindAllBikes)
-	b.GET("/renters/:renterId", bikeController.HandlerFindBikesByRenter)
-	b.GET("/categories/:categoryId", bikeController.HandlerFindBikesByCategory)
-	b.GET("/:id", bikeController.HandlerFindByIdBike)
-	b.PUT("/:id", bikeController.HandlerUpdateBike, middleware.JWT([]byte(configs.Cfg.JWTSecret)), mddlwrs.CheckIsRenter)
-	b.DELETE("/:id", bikeController.HandlerDeleteBike, middleware.JWT([]byte(configs.Cfg.JWTSecret)), mddlwrs.CheckIsRenter)
-	b.POST("/:id/reviews", bikeController.HandlerCreateNewBikeReview, middleware.JWT([]byte(configs.Cfg.JWTSecret)))
-
-	// order
-	orderController := controller.NewOrderController(orderUsecase)
-
-	o := v1.Group("/orders", middleware.JWT([]byte(configs.Cfg.JWTSecret)))
-	o.POST("", orderController.HandlerCreateNewOrder)
-	o.GET("/:id/return", orderController.HandlerReturnBike)
+	// webhooks get lost, so the payments still moving are checked against the payment gateway every night
+	paymentReconciliationWorker := worker.NewPaymentReconciliationWorker(paymentGatewayUsecase, configs.Cfg.ReconciliationTime, configs.Cfg.ReconciliationReportDir)
+	go paymentReconciliationWorker.Start(context.Background())
+
+	if configs.Cfg.PaymentProvider == payment.ProviderFake {
+		fakePaymentController := controller.NewFakePaymentController(fakePaymentProvider, paymentGatewayUsecase)
+
+		fp := v1.Group("/fake-payments")
+		fp.GET("/:id", fakePaymentController.HandlerFindFakePayment)
+		fp.POST("/:id/:status", fakePaymentController.HandlerSimulateFakePayment)
+	}
+
+	payoutController := controller.NewPayoutController(payoutUsecase)
+
+	// access tokens are checked against the tokens revoked by a logout,
+	// users only act on the accounts, renters, bikes and orders they own
+	routeGuards := newGuards(mddlwrs.JWT(sessionRepository), policy.NewPolicy(renterRepository, bikeRepository, orderRepository))
+
+	routeControllers := controllers{
+		user:           controller.NewUserController(userUsecase),
+		auth:           controller.NewAuthController(authUsecase),
+		renter:         controller.NewRenterController(renterUsecase),
+		payout:         payoutController,
+		category:       controller.NewCategoryController(categoryUsecase),
+		bike:           controller.NewBikeController(bikeUsecase),
+		voucher:        controller.NewVoucherController(voucherUsecase),
+		order:          controller.NewOrderController(orderUsecase),
+		invoice:        controller.NewInvoiceController(invoiceUsecase),
+		wallet:         controller.NewWalletController(walletUsecase),
+		refund:         controller.NewRefundController(refundUsecase),
+		paymentGateway: paymentGatewayController,
+	}
+
+	for _, ep := range endpoints(routeControllers, routeGuards) {
+		v1.Add(ep.method, ep.path, ep.handler, ep.middlewares...)
+	}
+
+	// payout batches are run by finance with the finance api key, they are off without one
+	if configs.Cfg.FinanceAPIKey != "" {
+		pb := v1.Group("/payout-batches", middleware.KeyAuth(func(key string, c echo.Context) (bool, error) {
+			return subtle.ConstantTimeCompare([]byte(key), []byte(configs.Cfg.FinanceAPIKey)) == 1, nil
+		}))
+		pb.POST("", payoutController.HandlerCreatePayoutBatch)
+		pb.GET("", payoutController.HandlerFindAllPayoutBatches)
+		pb.GET("/:id", payoutController.HandlerFindPayoutBatchById)
+		pb.GET("/:id/export", payoutController.HandlerExportPayoutBatch)
+		pb.PUT("/:id/payouts/:payoutId", payoutController.HandlerUpdatePayoutStatus)
+	}
 }
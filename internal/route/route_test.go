@@ -0,0 +1,216 @@
+package route
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/arvinpaundra/go-rent-bike/configs"
+	"github.com/arvinpaundra/go-rent-bike/helper"
+	controller "github.com/arvinpaundra/go-rent-bike/internal/controller/rest-http"
+	mddlwrs "github.com/arvinpaundra/go-rent-bike/internal/middlewares"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/policy"
+	repomock "github.com/arvinpaundra/go-rent-bike/internal/repository/gormdb/mock"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	customerId    = "8b0e6c2f-8d5d-4a51-9d0a-3f6a9d3a4c11"
+	renterUserId  = "2f7c1a8e-5b3d-4c9e-8a61-0d4e7b2c9f32"
+	otherUserId   = "c5d9e3b1-7a2f-4e8c-b613-9f0a2d4c6e53"
+	adminId       = "e7f9a1b3-c5d7-4e9f-a1b3-c5d7e9f1a3b5"
+	renterId      = "4a6e8c0b-2d4f-4618-8a0c-e2b4d6f8a174"
+	otherRenterId = "9e1c3a5f-7b9d-4f1b-a3c5-e7091b3d5f95"
+	bikeId        = "1d3f5b7e-9a1c-4e3f-85a7-c9e1b3d5f716"
+	orderId       = "6f8a0c2e-4b6d-4f8a-9c0e-2a4c6e8a0b37"
+	missingId     = "00000000-0000-0000-0000-000000000000"
+)
+
+// roles are the roles the tokens of the actors carry.
+var roles = map[string]string{
+	customerId:   model.RoleCustomer,
+	renterUserId: model.RoleRenter,
+	otherUserId:  model.RoleRenter,
+	adminId:      model.RoleAdmin,
+}
+
+// newOwnershipRouter registers the endpoints of the api with their real middlewares, in front of a handler
+// that always succeeds, so only the middlewares decide the answer.
+func newOwnershipRouter() *echo.Echo {
+	renterRepository := &repomock.RenterRepositoryMock{Mock: mock.Mock{}}
+	bikeRepository := &repomock.BikeRepositoryMock{Mock: mock.Mock{}}
+	orderRepository := &repomock.OrderRepositoryMock{Mock: mock.Mock{}}
+	sessionRepository := &repomock.SessionRepositoryMock{Mock: mock.Mock{}}
+
+	renter := &model.Renter{ID: renterId, UserId: renterUserId}
+	otherRenter := &model.Renter{ID: otherRenterId, UserId: otherUserId}
+	bike := &model.Bike{ID: bikeId, RenterId: renterId}
+	order := &model.Order{
+		ID:           orderId,
+		UserId:       customerId,
+		OrderDetails: []model.OrderDetail{{BikeId: bikeId, Bike: bike}},
+	}
+
+	renterRepository.Mock.On("FindById", renterId).Return(renter, nil)
+	renterRepository.Mock.On("FindById", otherRenterId).Return(otherRenter, nil)
+	renterRepository.Mock.On("FindById", mock.Anything).Return(&model.Renter{}, pkg.ErrRecordNotFound)
+	renterRepository.Mock.On("FindByIdUser", renterUserId).Return(renter, nil)
+	renterRepository.Mock.On("FindByIdUser", otherUserId).Return(otherRenter, nil)
+	renterRepository.Mock.On("FindByIdUser", mock.Anything).Return(&model.Renter{}, pkg.ErrRecordNotFound)
+	bikeRepository.Mock.On("FindById", bikeId).Return(bike, nil)
+	bikeRepository.Mock.On("FindById", mock.Anything).Return(&model.Bike{}, pkg.ErrRecordNotFound)
+	orderRepository.Mock.On("FindById", orderId).Return(order, nil)
+	orderRepository.Mock.On("FindById", mock.Anything).Return(&model.Order{}, pkg.ErrRecordNotFound)
+	sessionRepository.Mock.On("IsTokenRevoked", mock.Anything).Return(false, nil)
+
+	routeGuards := newGuards(mddlwrs.JWT(sessionRepository), policy.NewPolicy(renterRepository, bikeRepository, orderRepository))
+
+	// the handlers are never reached through the controllers, they only have to exist
+	routeControllers := controllers{
+		user:           controller.NewUserController(nil),
+		auth:           controller.NewAuthController(nil),
+		renter:         controller.NewRenterController(nil),
+		payout:         controller.NewPayoutController(nil),
+		category:       controller.NewCategoryController(nil),
+		bike:           controller.NewBikeController(nil),
+		voucher:        controller.NewVoucherController(nil),
+		order:          controller.NewOrderController(nil),
+		invoice:        controller.NewInvoiceController(nil),
+		wallet:         controller.NewWalletController(nil),
+		refund:         controller.NewRefundController(nil),
+		paymentGateway: controller.NewMidtransNotificationController(nil),
+	}
+
+	ok := func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}
+
+	e := echo.New()
+	v1 := e.Group("/api/v1")
+
+	for _, ep := range endpoints(routeControllers, routeGuards) {
+		v1.Add(ep.method, ep.path, ok, ep.middlewares...)
+	}
+
+	return e
+}
+
+func TestEndpoints_Authorization(t *testing.T) {
+	configs.InitConfig()
+	configs.Cfg.JWTSecret = "route-test-secret"
+
+	testCases := []struct {
+		Name         string
+		Method       string
+		Path         string
+		ActorId      string
+		ExpectedCode int
+	}{
+		{"customer histories own", http.MethodGet, "/customers/" + customerId + "/histories", customerId, http.StatusOK},
+		{"customer histories other", http.MethodGet, "/customers/" + customerId + "/histories", otherUserId, http.StatusForbidden},
+		{"customer orders own", http.MethodGet, "/customers/" + customerId + "/orders", customerId, http.StatusOK},
+		{"customer orders other", http.MethodGet, "/customers/" + customerId + "/orders", otherUserId, http.StatusForbidden},
+		{"customer order own", http.MethodGet, "/customers/" + customerId + "/orders/" + orderId, customerId, http.StatusOK},
+		{"customer order of other customer", http.MethodGet, "/customers/" + otherUserId + "/orders/" + orderId, otherUserId, http.StatusForbidden},
+		{"customer order other", http.MethodGet, "/customers/" + customerId + "/orders/" + orderId, otherUserId, http.StatusForbidden},
+		{"customer order not found", http.MethodGet, "/customers/" + customerId + "/orders/" + missingId, customerId, http.StatusNotFound},
+		{"customer get own", http.MethodGet, "/customers/" + customerId, customerId, http.StatusOK},
+		{"customer get other", http.MethodGet, "/customers/" + customerId, otherUserId, http.StatusForbidden},
+		{"customer update own", http.MethodPut, "/customers/" + customerId, customerId, http.StatusOK},
+		{"customer update other", http.MethodPut, "/customers/" + customerId, otherUserId, http.StatusForbidden},
+		{"customer delete own", http.MethodDelete, "/customers/" + customerId, customerId, http.StatusOK},
+		{"customer delete other", http.MethodDelete, "/customers/" + customerId, otherUserId, http.StatusForbidden},
+		{"customer delete without token", http.MethodDelete, "/customers/" + customerId, "", http.StatusBadRequest},
+
+		{"renter pricing rule create own", http.MethodPost, "/renters/" + renterId + "/pricing-rules", renterUserId, http.StatusOK},
+		{"renter pricing rule create other", http.MethodPost, "/renters/" + renterId + "/pricing-rules", otherUserId, http.StatusForbidden},
+		{"renter pricing rule delete own", http.MethodDelete, "/renters/" + renterId + "/pricing-rules/" + missingId, renterUserId, http.StatusOK},
+		{"renter pricing rule delete other", http.MethodDelete, "/renters/" + renterId + "/pricing-rules/" + missingId, otherUserId, http.StatusForbidden},
+		{"renter earnings own", http.MethodGet, "/renters/" + renterId + "/earnings", renterUserId, http.StatusOK},
+		{"renter earnings other", http.MethodGet, "/renters/" + renterId + "/earnings", otherUserId, http.StatusForbidden},
+		{"renter earnings customer", http.MethodGet, "/renters/" + renterId + "/earnings", customerId, http.StatusForbidden},
+		{"renter payouts own", http.MethodGet, "/renters/" + renterId + "/payouts", renterUserId, http.StatusOK},
+		{"renter payouts other", http.MethodGet, "/renters/" + renterId + "/payouts", otherUserId, http.StatusForbidden},
+		{"renter update own", http.MethodPut, "/renters/" + renterId, renterUserId, http.StatusOK},
+		{"renter update other", http.MethodPut, "/renters/" + otherRenterId, renterUserId, http.StatusForbidden},
+		{"renter update not found", http.MethodPut, "/renters/" + missingId, renterUserId, http.StatusNotFound},
+		{"renter delete own", http.MethodDelete, "/renters/" + renterId, renterUserId, http.StatusOK},
+		{"renter delete other", http.MethodDelete, "/renters/" + renterId, otherUserId, http.StatusForbidden},
+
+		{"bike update own", http.MethodPut, "/bikes/" + bikeId, renterUserId, http.StatusOK},
+		{"bike update other", http.MethodPut, "/bikes/" + bikeId, otherUserId, http.StatusForbidden},
+		{"bike update customer", http.MethodPut, "/bikes/" + bikeId, customerId, http.StatusForbidden},
+		{"bike update not found", http.MethodPut, "/bikes/" + missingId, renterUserId, http.StatusNotFound},
+		{"bike delete own", http.MethodDelete, "/bikes/" + bikeId, renterUserId, http.StatusOK},
+		{"bike delete other", http.MethodDelete, "/bikes/" + bikeId, otherUserId, http.StatusForbidden},
+
+		{"voucher create renter", http.MethodPost, "/vouchers", renterUserId, http.StatusOK},
+		{"voucher create customer", http.MethodPost, "/vouchers", customerId, http.StatusForbidden},
+
+		{"order create customer", http.MethodPost, "/orders", customerId, http.StatusOK},
+		{"order create without token", http.MethodPost, "/orders", "", http.StatusBadRequest},
+		{"order pickup customer", http.MethodPost, "/orders/" + orderId + "/pickup", customerId, http.StatusForbidden},
+		{"order pickup renter", http.MethodPost, "/orders/" + orderId + "/pickup", renterUserId, http.StatusOK},
+		{"order pickup other", http.MethodPost, "/orders/" + orderId + "/pickup", otherUserId, http.StatusForbidden},
+		{"order pickup not found", http.MethodPost, "/orders/" + missingId + "/pickup", renterUserId, http.StatusNotFound},
+		{"order return customer", http.MethodGet, "/orders/" + orderId + "/return", customerId, http.StatusForbidden},
+		{"order return renter", http.MethodGet, "/orders/" + orderId + "/return", renterUserId, http.StatusOK},
+		{"order return other", http.MethodGet, "/orders/" + orderId + "/return", otherUserId, http.StatusForbidden},
+		{"order cancel customer", http.MethodPost, "/orders/" + orderId + "/cancel", customerId, http.StatusOK},
+		{"order cancel renter", http.MethodPost, "/orders/" + orderId + "/cancel", renterUserId, http.StatusForbidden},
+		{"order cancel other", http.MethodPost, "/orders/" + orderId + "/cancel", otherUserId, http.StatusForbidden},
+		{"order cancel not found", http.MethodPost, "/orders/" + missingId + "/cancel", customerId, http.StatusNotFound},
+		{"order extend customer", http.MethodPost, "/orders/" + orderId + "/extend", customerId, http.StatusOK},
+		{"order extend renter", http.MethodPost, "/orders/" + orderId + "/extend", renterUserId, http.StatusForbidden},
+		{"order extend other", http.MethodPost, "/orders/" + orderId + "/extend", otherUserId, http.StatusForbidden},
+		{"order deposit customer", http.MethodPost, "/orders/" + orderId + "/deposit", customerId, http.StatusForbidden},
+		{"order deposit renter", http.MethodPost, "/orders/" + orderId + "/deposit", renterUserId, http.StatusOK},
+		{"order deposit other", http.MethodPost, "/orders/" + orderId + "/deposit", otherUserId, http.StatusForbidden},
+		{"order statuses customer", http.MethodGet, "/orders/" + orderId + "/statuses", customerId, http.StatusOK},
+		{"order statuses renter", http.MethodGet, "/orders/" + orderId + "/statuses", renterUserId, http.StatusOK},
+		{"order statuses other", http.MethodGet, "/orders/" + orderId + "/statuses", otherUserId, http.StatusForbidden},
+		{"order invoice customer", http.MethodGet, "/orders/" + orderId + "/invoice.pdf", customerId, http.StatusOK},
+		{"order invoice other", http.MethodGet, "/orders/" + orderId + "/invoice.pdf", otherUserId, http.StatusForbidden},
+		{"order invoice not found", http.MethodGet, "/orders/" + missingId + "/invoice.pdf", customerId, http.StatusNotFound},
+		{"order receipt renter", http.MethodGet, "/orders/" + orderId + "/receipt.pdf", renterUserId, http.StatusOK},
+		{"order receipt other", http.MethodGet, "/orders/" + orderId + "/receipt.pdf", otherUserId, http.StatusForbidden},
+
+		{"refund create renter", http.MethodPost, "/payments/" + missingId + "/refunds", renterUserId, http.StatusOK},
+		{"refund create customer", http.MethodPost, "/payments/" + missingId + "/refunds", customerId, http.StatusForbidden},
+		{"refund create admin", http.MethodPost, "/payments/" + missingId + "/refunds", adminId, http.StatusOK},
+		{"refund list admin", http.MethodGet, "/payments/" + missingId + "/refunds", adminId, http.StatusOK},
+
+		{"admin users admin", http.MethodGet, "/admin/users", adminId, http.StatusOK},
+		{"admin users renter", http.MethodGet, "/admin/users", renterUserId, http.StatusForbidden},
+		{"admin login attempts customer", http.MethodGet, "/admin/login-attempts", customerId, http.StatusForbidden},
+		{"admin order status admin", http.MethodPost, "/admin/orders/" + orderId + "/status", adminId, http.StatusOK},
+		{"platform voucher create admin", http.MethodPost, "/admin/vouchers", adminId, http.StatusOK},
+		{"platform voucher create renter", http.MethodPost, "/admin/vouchers", renterUserId, http.StatusForbidden},
+	}
+
+	e := newOwnershipRouter()
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.Method, "/api/v1"+tc.Path, nil)
+
+			if tc.ActorId != "" {
+				token, err := helper.CreateToken(tc.ActorId, roles[tc.ActorId], "a1b2c3d4-e5f6-4a7b-8c9d-0e1f2a3b4c5d", "jti-"+tc.ActorId, time.Now().Add(time.Hour))
+				assert.NoError(t, err)
+
+				req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+			}
+
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.ExpectedCode, rec.Code)
+		})
+	}
+}
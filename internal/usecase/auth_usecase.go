@@ -0,0 +1,757 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arvinpaundra/go-rent-bike/helper"
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/mailer"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/google/uuid"
+)
+
+type AuthUsecase interface {
+	Login(email string, password string, userAgent string, ipAddress string) (*dto.AuthTokenDTO, error)
+	RefreshToken(refreshToken string, userAgent string, ipAddress string) (*dto.AuthTokenDTO, error)
+	FindAllSessions(userId string, currentSessionId string) (*[]model.Session, error)
+	RevokeSession(userId string, sessionId string) error
+	SendEmailVerification(userId string) error
+	VerifyEmail(token string) error
+	ForgotPassword(email string) error
+	ResetPassword(token string, password string) error
+	FindAllLoginAttempts(email string, ipAddress string) (*[]model.LoginAttempt, error)
+}
+
+// SessionPolicy holds how long the tokens of a session last. The access token is short lived,
+// every refresh moves the expiry of the refresh token forward.
+type SessionPolicy struct {
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
+// AccountPolicy holds where the links sent by email point to and how long they work. The links open
+// the /verify-email and /reset-password pages of the app, which post the token of the link to the api.
+type AccountPolicy struct {
+	AppBaseURL      string
+	VerificationTTL time.Duration
+	ResetTTL        time.Duration
+}
+
+// LoginPolicy holds how failed logins are throttled, per email and per ip address. From DelayAfterFailures
+// failures on, the next login waits a second, doubled after every failure up to a minute. LockoutFailures
+// failures of an email, or IPLockoutFailures of an ip address, refuse every login for LockoutDuration.
+// The failures are forgotten once none happened for FailureWindow.
+type LoginPolicy struct {
+	FailureWindow      time.Duration
+	DelayAfterFailures int
+	LockoutFailures    int
+	IPLockoutFailures  int
+	LockoutDuration    time.Duration
+}
+
+const (
+	baseLoginDelay = time.Second
+	maxLoginDelay  = time.Minute
+	// loginAttemptsLimit is how many of the newest login attempts the admins are shown
+	loginAttemptsLimit = 100
+)
+
+// LoginThrottledError is a login refused before its password was checked, because of the failed logins
+// before it. It matches its kind, pkg.ErrTooManyLoginAttempts or pkg.ErrAccountLocked.
+type LoginThrottledError struct {
+	Kind    error
+	RetryAt time.Time
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("%v, try again after %s", e.Kind, e.RetryAt.Format(time.RFC3339))
+}
+
+func (e *LoginThrottledError) Unwrap() error {
+	return e.Kind
+}
+
+type authUsecase struct {
+	unitOfWork             repository.UnitOfWork
+	userRepository         repository.UserRepository
+	sessionRepository      repository.SessionRepository
+	loginAttemptRepository repository.LoginAttemptRepository
+	userTokens             userTokenMailer
+	policy                 SessionPolicy
+	loginPolicy            LoginPolicy
+}
+
+// Login starts a new session on the device of the user. Every attempt is recorded, the failed ones count
+// towards the throttling of the email and of the ip address. A wrong password and an unknown email are
+// answered alike, with pkg.ErrRecordNotFound and after the same bcrypt work. The password is checked
+// before any throttle is locked, so the logins of an email or an ip address never wait on each other.
+func (u authUsecase) Login(email string, password string, userAgent string, ipAddress string) (*dto.AuthTokenDTO, error) {
+	now := time.Now()
+
+	attempt := model.LoginAttempt{
+		ID:        uuid.NewString(),
+		Email:     truncate(email, 255),
+		IPAddress: ipAddress,
+		UserAgent: truncate(userAgent, 255),
+		CreatedAt: now,
+	}
+
+	emailThrottle, err := findLoginThrottle(u.loginAttemptRepository, loginThrottleKey("email", email))
+
+	if err != nil {
+		return nil, err
+	}
+
+	ipThrottle, err := findLoginThrottle(u.loginAttemptRepository, loginThrottleKey("ip", ipAddress))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if loginErr := u.loginPolicy.refuse(*emailThrottle, *ipThrottle, now); loginErr != nil {
+		attempt.FailureReason = model.LoginFailureThrottled
+
+		if errors.Is(loginErr, pkg.ErrAccountLocked) {
+			attempt.FailureReason = model.LoginFailureLocked
+		}
+
+		err = u.loginAttemptRepository.Create(attempt)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, loginErr
+	}
+
+	user, err := u.userRepository.FindByEmail(email)
+
+	if err != nil && !errors.Is(err, pkg.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if user == nil {
+		// spend the same bcrypt work as on a real password, so an unknown email takes as long to answer
+		helper.ComparePassword(dummyPasswordHash(), password)
+
+		attempt.FailureReason = model.LoginFailureUnknownEmail
+	} else if !helper.ComparePassword(user.Password, password) {
+		attempt.UserId = user.ID
+		attempt.FailureReason = model.LoginFailureWrongPassword
+	}
+
+	if attempt.FailureReason != "" {
+		err = u.unitOfWork.WithTx(func(repos repository.Repositories) error {
+			err := u.loginPolicy.countFailure(repos.LoginAttempt, emailThrottle.Key, u.loginPolicy.LockoutFailures, now)
+
+			if err != nil {
+				return err
+			}
+
+			err = u.loginPolicy.countFailure(repos.LoginAttempt, ipThrottle.Key, u.loginPolicy.IPLockoutFailures, now)
+
+			if err != nil {
+				return err
+			}
+
+			return repos.LoginAttempt.Create(attempt)
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, pkg.ErrRecordNotFound
+	}
+
+	var authToken *dto.AuthTokenDTO
+
+	err = u.unitOfWork.WithTx(func(repos repository.Repositories) error {
+		session := model.Session{
+			ID:        uuid.NewString(),
+			UserId:    user.ID,
+			UserAgent: truncate(userAgent, 255),
+			IPAddress: ipAddress,
+			CreatedAt: now,
+		}
+
+		authToken, err = u.issueTokens(&session, *user, now)
+
+		if err != nil {
+			return err
+		}
+
+		err = repos.Session.Create(session)
+
+		if err != nil {
+			return err
+		}
+
+		// the failures of the email are forgotten, those of the ip address are left to expire
+		if !emailThrottle.UpdatedAt.IsZero() {
+			err = repos.LoginAttempt.DeleteThrottle(emailThrottle.Key)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		attempt.UserId = user.ID
+		attempt.Success = true
+
+		return repos.LoginAttempt.Create(attempt)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return authToken, nil
+}
+
+// RefreshToken rotates the refresh token of a session and issues a new access token with it. A refresh token
+// that was already rotated away is a copy somebody else holds, so the whole session is revoked.
+func (u authUsecase) RefreshToken(refreshToken string, userAgent string, ipAddress string) (*dto.AuthTokenDTO, error) {
+	hash := helper.HashToken(refreshToken)
+	reused := false
+
+	var authToken *dto.AuthTokenDTO
+
+	err := u.unitOfWork.WithTx(func(repos repository.Repositories) error {
+		session, err := repos.Session.FindByRefreshTokenHashForUpdate(hash)
+
+		if err != nil {
+			if errors.Is(err, pkg.ErrRecordNotFound) {
+				return pkg.ErrInvalidRefreshToken
+			}
+
+			return err
+		}
+
+		now := time.Now()
+
+		if session.RevokedAt != nil || !now.Before(session.ExpiresAt) {
+			return pkg.ErrInvalidRefreshToken
+		}
+
+		// the revocation has to be committed, so the error is only returned after the transaction
+		if session.RefreshTokenHash != hash {
+			reused = true
+
+			return revokeSession(repos.Session, *session, now)
+		}
+
+		user, err := repos.User.FindById(session.UserId)
+
+		if err != nil {
+			return err
+		}
+
+		// the access token issued along with the old refresh token is replaced as well
+		err = revokeAccessToken(repos.Session, *session, now)
+
+		if err != nil {
+			return err
+		}
+
+		session.UserAgent = truncate(userAgent, 255)
+		session.IPAddress = ipAddress
+
+		authToken, err = u.issueTokens(session, *user, now)
+
+		if err != nil {
+			return err
+		}
+
+		return repos.Session.Update(session.ID, *session)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if reused {
+		return nil, pkg.ErrInvalidRefreshToken
+	}
+
+	return authToken, nil
+}
+
+// FindAllSessions lists the sessions of the user that can still be refreshed, marking the one of the request.
+func (u authUsecase) FindAllSessions(userId string, currentSessionId string) (*[]model.Session, error) {
+	sessions, err := u.sessionRepository.FindAllActiveByIdUser(userId, time.Now())
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range *sessions {
+		(*sessions)[i].Current = (*sessions)[i].ID == currentSessionId
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession logs a session of the user out, its refresh token and its access token stop working at once.
+// The sessions of other users are not found.
+func (u authUsecase) RevokeSession(userId string, sessionId string) error {
+	return u.unitOfWork.WithTx(func(repos repository.Repositories) error {
+		session, err := repos.Session.FindByIdForUpdate(sessionId)
+
+		if err != nil {
+			return err
+		}
+
+		if session.UserId != userId {
+			return pkg.ErrRecordNotFound
+		}
+
+		if session.RevokedAt != nil {
+			return nil
+		}
+
+		return revokeSession(repos.Session, *session, time.Now())
+	})
+}
+
+// SendEmailVerification sends a new verification email to the user, the links of the previous ones stop working.
+func (u authUsecase) SendEmailVerification(userId string) error {
+	user, err := u.userRepository.FindById(userId)
+
+	if err != nil {
+		return err
+	}
+
+	if user.IsEmailVerified() {
+		return pkg.ErrEmailAlreadyVerified
+	}
+
+	return u.userTokens.send(*user, model.UserTokenEmailVerification)
+}
+
+// VerifyEmail marks the email of the user the token was sent to as verified, using the token up.
+func (u authUsecase) VerifyEmail(token string) error {
+	return u.unitOfWork.WithTx(func(repos repository.Repositories) error {
+		now := time.Now()
+
+		userToken, err := useUserToken(repos.UserToken, token, model.UserTokenEmailVerification, now)
+
+		if err != nil {
+			return err
+		}
+
+		user, err := repos.User.FindById(userToken.UserId)
+
+		if err != nil {
+			return err
+		}
+
+		if user.IsEmailVerified() {
+			return nil
+		}
+
+		return repos.User.Update(user.ID, model.User{EmailVerifiedAt: &now, UpdatedAt: now})
+	})
+}
+
+// ForgotPassword sends a password reset email to the user of email. An email nobody registered with is not
+// told apart, so the endpoint cannot be used to find out who has an account.
+func (u authUsecase) ForgotPassword(email string) error {
+	user, err := u.userRepository.FindByEmail(email)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return nil
+		}
+
+		return err
+	}
+
+	return u.userTokens.send(*user, model.UserTokenPasswordReset)
+}
+
+// ResetPassword sets a new password for the user the token was sent to and logs every session of the user
+// out. Following the link proved the email belongs to the user, so it is verified as well.
+func (u authUsecase) ResetPassword(token string, password string) error {
+	hashedPassword, err := helper.HashPassword(password)
+
+	if err != nil {
+		return err
+	}
+
+	return u.unitOfWork.WithTx(func(repos repository.Repositories) error {
+		now := time.Now()
+
+		userToken, err := useUserToken(repos.UserToken, token, model.UserTokenPasswordReset, now)
+
+		if err != nil {
+			return err
+		}
+
+		user, err := repos.User.FindById(userToken.UserId)
+
+		if err != nil {
+			return err
+		}
+
+		userUC := model.User{Password: hashedPassword, UpdatedAt: now}
+
+		if !user.IsEmailVerified() {
+			userUC.EmailVerifiedAt = &now
+		}
+
+		err = repos.User.Update(user.ID, userUC)
+
+		if err != nil {
+			return err
+		}
+
+		// the other reset links sent before stop working too
+		err = repos.UserToken.MarkAllUsed(user.ID, model.UserTokenPasswordReset, now)
+
+		if err != nil {
+			return err
+		}
+
+		sessions, err := repos.Session.FindAllActiveByIdUser(user.ID, now)
+
+		if err != nil {
+			return err
+		}
+
+		for _, session := range *sessions {
+			err = revokeSession(repos.Session, session, now)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// FindAllLoginAttempts lists the newest login attempts, of an email or an ip address when they are given.
+func (u authUsecase) FindAllLoginAttempts(email string, ipAddress string) (*[]model.LoginAttempt, error) {
+	attempts, err := u.loginAttemptRepository.FindAll(email, ipAddress, loginAttemptsLimit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return attempts, nil
+}
+
+// issueTokens creates the next pair of tokens of a session and stores the hash of the refresh token on it,
+// keeping the hash of the refresh token it replaces.
+func (u authUsecase) issueTokens(session *model.Session, user model.User, now time.Time) (*dto.AuthTokenDTO, error) {
+	refreshToken, err := helper.CreateOpaqueToken()
+
+	if err != nil {
+		return nil, err
+	}
+
+	jti := uuid.NewString()
+	expiresAt := now.Add(u.policy.AccessTokenTTL)
+
+	token, err := helper.CreateToken(user.ID, user.Role, session.ID, jti, expiresAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	session.PreviousRefreshTokenHash = session.RefreshTokenHash
+	session.RefreshTokenHash = helper.HashToken(refreshToken)
+	session.AccessTokenJti = jti
+	session.AccessTokenExpiresAt = expiresAt
+	session.ExpiresAt = now.Add(u.policy.RefreshTokenTTL)
+	session.LastUsedAt = now
+	session.UpdatedAt = now
+
+	return &dto.AuthTokenDTO{
+		Token:            token,
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+func revokeSession(sessionRepository repository.SessionRepository, session model.Session, now time.Time) error {
+	err := sessionRepository.Update(session.ID, model.Session{RevokedAt: &now, UpdatedAt: now})
+
+	if err != nil {
+		return err
+	}
+
+	return revokeAccessToken(sessionRepository, session, now)
+}
+
+// revokeAccessToken puts the last access token of a session on the revocation list until it expires.
+// The tokens that expired since are dropped from the list on the way.
+func revokeAccessToken(sessionRepository repository.SessionRepository, session model.Session, now time.Time) error {
+	if session.AccessTokenJti == "" || !session.AccessTokenExpiresAt.After(now) {
+		return nil
+	}
+
+	err := sessionRepository.DeleteExpiredRevokedTokens(now)
+
+	if err != nil {
+		return err
+	}
+
+	return sessionRepository.RevokeToken(model.RevokedToken{
+		Jti:       session.AccessTokenJti,
+		ExpiresAt: session.AccessTokenExpiresAt,
+		CreatedAt: now,
+	})
+}
+
+// userTokenMailer sends the emails carrying a user token, only the last token sent for a purpose works.
+type userTokenMailer struct {
+	unitOfWork repository.UnitOfWork
+	mailer     mailer.Mailer
+	policy     AccountPolicy
+}
+
+func (m userTokenMailer) send(user model.User, purpose string) error {
+	token, err := helper.CreateOpaqueToken()
+
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	var (
+		ttl time.Duration
+		msg mailer.Message
+	)
+
+	switch purpose {
+	case model.UserTokenEmailVerification:
+		ttl = m.policy.VerificationTTL
+		msg = mailer.Message{
+			To:      user.Email,
+			Subject: "Verify your email",
+			Body: fmt.Sprintf(
+				"Hi %s,\n\nplease verify your email to start renting bikes by opening this link:\n\n%s/verify-email?token=%s\n\nThe link works once, for %s.\n",
+				user.Fullname, m.policy.AppBaseURL, token, formatTTL(ttl),
+			),
+		}
+	case model.UserTokenPasswordReset:
+		ttl = m.policy.ResetTTL
+		msg = mailer.Message{
+			To:      user.Email,
+			Subject: "Reset your password",
+			Body: fmt.Sprintf(
+				"Hi %s,\n\nopen this link to choose a new password for your account:\n\n%s/reset-password?token=%s\n\nThe link works once, for %s. If you did not ask for it, ignore this email and your password stays the same.\n",
+				user.Fullname, m.policy.AppBaseURL, token, formatTTL(ttl),
+			),
+		}
+	default:
+		return fmt.Errorf("unknown user token purpose %q", purpose)
+	}
+
+	err = m.unitOfWork.WithTx(func(repos repository.Repositories) error {
+		err := repos.UserToken.MarkAllUsed(user.ID, purpose, now)
+
+		if err != nil {
+			return err
+		}
+
+		return repos.UserToken.Create(model.UserToken{
+			ID:        uuid.NewString(),
+			UserId:    user.ID,
+			Purpose:   purpose,
+			TokenHash: helper.HashToken(token),
+			ExpiresAt: now.Add(ttl),
+			CreatedAt: now,
+		})
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if err := m.mailer.Send(msg); err != nil {
+		return fmt.Errorf("%w: %s", pkg.ErrMailNotSent, err)
+	}
+
+	return nil
+}
+
+// useUserToken finds the token sent for purpose and marks it used, a token that is unknown, used up,
+// expired or sent for another purpose is rejected the same way.
+func useUserToken(userTokenRepository repository.UserTokenRepository, token string, purpose string, now time.Time) (*model.UserToken, error) {
+	userToken, err := userTokenRepository.FindByTokenHashForUpdate(helper.HashToken(token))
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return nil, pkg.ErrInvalidUserToken
+		}
+
+		return nil, err
+	}
+
+	if !userToken.IsUsable(purpose, now) {
+		return nil, pkg.ErrInvalidUserToken
+	}
+
+	err = userTokenRepository.MarkUsed(userToken.ID, now)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return userToken, nil
+}
+
+func formatTTL(ttl time.Duration) string {
+	if ttl >= time.Hour && ttl%time.Hour == 0 {
+		return fmt.Sprintf("%d hours", int(ttl.Hours()))
+	}
+
+	return fmt.Sprintf("%d minutes", int(ttl.Minutes()))
+}
+
+// refuse tells whether a login is refused before its password is checked, because of the failures of its
+// email or of its ip address.
+func (p LoginPolicy) refuse(emailThrottle model.LoginThrottle, ipThrottle model.LoginThrottle, now time.Time) error {
+	if emailThrottle.LockedUntil != nil && now.Before(*emailThrottle.LockedUntil) {
+		return &LoginThrottledError{Kind: pkg.ErrAccountLocked, RetryAt: *emailThrottle.LockedUntil}
+	}
+
+	if ipThrottle.LockedUntil != nil && now.Before(*ipThrottle.LockedUntil) {
+		return &LoginThrottledError{Kind: pkg.ErrTooManyLoginAttempts, RetryAt: *ipThrottle.LockedUntil}
+	}
+
+	retryAt := p.nextAttemptAt(emailThrottle)
+
+	if ipRetryAt := p.nextAttemptAt(ipThrottle); ipRetryAt.After(retryAt) {
+		retryAt = ipRetryAt
+	}
+
+	if now.Before(retryAt) {
+		return &LoginThrottledError{Kind: pkg.ErrTooManyLoginAttempts, RetryAt: retryAt}
+	}
+
+	return nil
+}
+
+// nextAttemptAt is when the next login of a throttle is let through, the delay doubles with every failure.
+func (p LoginPolicy) nextAttemptAt(throttle model.LoginThrottle) time.Time {
+	if p.DelayAfterFailures <= 0 || throttle.Failures < p.DelayAfterFailures {
+		return time.Time{}
+	}
+
+	delay := maxLoginDelay
+
+	if shift := throttle.Failures - p.DelayAfterFailures; shift < 6 {
+		delay = baseLoginDelay << shift
+	}
+
+	if delay > maxLoginDelay {
+		delay = maxLoginDelay
+	}
+
+	return throttle.LastFailureAt.Add(delay)
+}
+
+// countFailure counts a failed login on the throttle of key and locks it once it reaches lockoutFailures,
+// 0 never locks. The count is incremented by the database, the throttle is only read back to lock it.
+func (p LoginPolicy) countFailure(loginAttemptRepository repository.LoginAttemptRepository, key string, lockoutFailures int, now time.Time) error {
+	err := loginAttemptRepository.IncrementThrottle(key, now, now.Add(-p.FailureWindow))
+
+	if err != nil {
+		return err
+	}
+
+	if lockoutFailures <= 0 {
+		return nil
+	}
+
+	throttle, err := loginAttemptRepository.FindThrottleForUpdate(key)
+
+	if err != nil {
+		return err
+	}
+
+	if throttle.Failures < lockoutFailures {
+		return nil
+	}
+
+	return loginAttemptRepository.LockThrottle(key, now.Add(p.LockoutDuration))
+}
+
+// loginThrottleKey keys the throttle of an email by its hash, so the column fits any email and does not hold it.
+func loginThrottleKey(kind string, value string) string {
+	if kind == "email" {
+		return "email:" + helper.HashToken(strings.ToLower(strings.TrimSpace(value)))
+	}
+
+	return kind + ":" + value
+}
+
+// findLoginThrottle reads the throttle of key, a key without failures gets an empty one.
+func findLoginThrottle(loginAttemptRepository repository.LoginAttemptRepository, key string) (*model.LoginThrottle, error) {
+	throttle, err := loginAttemptRepository.FindThrottle(key)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return &model.LoginThrottle{Key: key}, nil
+		}
+
+		return nil, err
+	}
+
+	return throttle, nil
+}
+
+var (
+	dummyPasswordHashOnce sync.Once
+	dummyPasswordHashed   string
+)
+
+// dummyPasswordHash is a bcrypt hash no password is checked against for real, it is only hashed once.
+func dummyPasswordHash() string {
+	dummyPasswordHashOnce.Do(func() {
+		dummyPasswordHashed, _ = helper.HashPassword(uuid.NewString())
+	})
+
+	return dummyPasswordHashed
+}
+
+func truncate(value string, size int) string {
+	if len(value) > size {
+		return value[:size]
+	}
+
+	return value
+}
+
+func NewAuthUsecase(
+	unitOfWork repository.UnitOfWork,
+	userRepo repository.UserRepository,
+	sessionRepo repository.SessionRepository,
+	loginAttemptRepo repository.LoginAttemptRepository,
+	mailer mailer.Mailer,
+	policy SessionPolicy,
+	accountPolicy AccountPolicy,
+	loginPolicy LoginPolicy,
+) AuthUsecase {
+	return authUsecase{
+		unitOfWork:             unitOfWork,
+		userRepository:         userRepo,
+		sessionRepository:      sessionRepo,
+		loginAttemptRepository: loginAttemptRepo,
+		userTokens:             userTokenMailer{unitOfWork: unitOfWork, mailer: mailer, policy: accountPolicy},
+		policy:                 policy,
+		loginPolicy:            loginPolicy,
+	}
+}
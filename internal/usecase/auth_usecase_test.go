@@ -0,0 +1,799 @@
+package usecase
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/arvinpaundra/go-rent-bike/configs"
+	"github.com/arvinpaundra/go-rent-bike/helper"
+	"github.com/arvinpaundra/go-rent-bike/internal/mailer"
+	mailermock "github.com/arvinpaundra/go-rent-bike/internal/mailer/mock"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	testMailer        = mailer.NewMemoryMailer()
+	testAccountPolicy = AccountPolicy{
+		AppBaseURL:      "http://localhost:3000",
+		VerificationTTL: 48 * time.Hour,
+		ResetTTL:        30 * time.Minute,
+	}
+	testLoginPolicy = LoginPolicy{
+		FailureWindow:      15 * time.Minute,
+		DelayAfterFailures: 3,
+		LockoutFailures:    10,
+		IPLockoutFailures:  50,
+		LockoutDuration:    15 * time.Minute,
+	}
+	authUsecaseTest = NewAuthUsecase(&pkg.UnitOfWork, &pkg.UserRepository, &pkg.SessionRepository, &pkg.LoginAttemptRepository, testMailer, SessionPolicy{
+		AccessTokenTTL:  time.Hour,
+		RefreshTokenTTL: 720 * time.Hour,
+	}, testAccountPolicy, testLoginPolicy)
+)
+
+// mockLoginThrottles stores the throttles of the email and of the ip address, nil when they have no failures.
+func mockLoginThrottles(email string, emailThrottle *model.LoginThrottle, ipAddress string, ipThrottle *model.LoginThrottle) {
+	for key, throttle := range map[string]*model.LoginThrottle{
+		loginThrottleKey("email", email):  emailThrottle,
+		loginThrottleKey("ip", ipAddress): ipThrottle,
+	} {
+		if throttle == nil {
+			pkg.LoginAttemptRepository.Mock.On("FindThrottle", key).Return((*model.LoginThrottle)(nil), pkg.ErrRecordNotFound)
+		} else {
+			pkg.LoginAttemptRepository.Mock.On("FindThrottle", key).Return(throttle, nil)
+		}
+	}
+}
+
+// mockLoginAttempt records the login attempts of email into attempts.
+func mockLoginAttempt(email string, attempts *[]model.LoginAttempt) {
+	pkg.LoginAttemptRepository.Mock.On("Create", mock.MatchedBy(func(attempt model.LoginAttempt) bool {
+		if attempt.Email != email {
+			return false
+		}
+
+		*attempts = append(*attempts, attempt)
+
+		return true
+	})).Return(nil)
+}
+
+// loginFailure is a failed login counted on a throttle, with the lock it got.
+type loginFailure struct {
+	Counted     int
+	FailedAt    time.Time
+	WindowStart time.Time
+	LockedUntil *time.Time
+}
+
+// mockLoginFailure counts the failures of key into failure, once counted the throttle holds failures.
+func mockLoginFailure(key string, failures int, failure *loginFailure) {
+	pkg.LoginAttemptRepository.Mock.On("IncrementThrottle", key, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		failure.Counted++
+		failure.FailedAt = args.Get(1).(time.Time)
+		failure.WindowStart = args.Get(2).(time.Time)
+	}).Return(nil)
+	pkg.LoginAttemptRepository.Mock.On("FindThrottleForUpdate", key).Return(&model.LoginThrottle{Key: key, Failures: failures}, nil)
+	pkg.LoginAttemptRepository.Mock.On("LockThrottle", key, mock.Anything).Run(func(args mock.Arguments) {
+		lockedUntil := args.Get(1).(time.Time)
+		failure.LockedUntil = &lockedUntil
+	}).Return(nil)
+}
+
+var mailTokenPattern = regexp.MustCompile(`token=(\S+)`)
+
+// lastMailToken reads the token of the link in the last email sent to the address.
+func lastMailToken(t *testing.T, to string) string {
+	msg, ok := testMailer.Last(to)
+
+	if !assert.True(t, ok, "no email sent to %s", to) {
+		return ""
+	}
+
+	match := mailTokenPattern.FindStringSubmatch(msg.Body)
+
+	if !assert.Len(t, match, 2, "no token in the email sent to %s", to) {
+		return ""
+	}
+
+	return match[1]
+}
+
+func TestAuthUsecase_Login(t *testing.T) {
+	configs.InitConfig()
+
+	hashedPassword, _ := helper.HashPassword("123")
+
+	user := &model.User{
+		ID:       "1a2b3c4d-5e6f-4a7b-8c9d-0e1f2a3b4c5d",
+		Role:     "customer",
+		Email:    "josuke@mail.com",
+		Password: hashedPassword,
+	}
+
+	pkg.UserRepository.Mock.On("FindByEmail", "josuke@mail.com").Return(user, nil)
+	mockLoginThrottles("josuke@mail.com", nil, "10.0.0.1", nil)
+
+	var (
+		created  model.Session
+		attempts []model.LoginAttempt
+	)
+
+	mockLoginAttempt("josuke@mail.com", &attempts)
+
+	pkg.SessionRepository.Mock.On("Create", mock.MatchedBy(func(session model.Session) bool {
+		created = session
+
+		return session.UserId == user.ID
+	})).Return(nil)
+
+	result, err := authUsecaseTest.Login("josuke@mail.com", "123", "Mozilla/5.0", "10.0.0.1")
+
+	assert.Nil(t, err)
+	assert.NotEmpty(t, result.Token)
+
+	// only the hash of the refresh token is stored
+	assert.Equal(t, helper.HashToken(result.RefreshToken), created.RefreshTokenHash)
+	assert.NotEqual(t, result.RefreshToken, created.RefreshTokenHash)
+	assert.NotEmpty(t, created.AccessTokenJti)
+	assert.Equal(t, "Mozilla/5.0", created.UserAgent)
+	assert.Equal(t, "10.0.0.1", created.IPAddress)
+	assert.Equal(t, created.ExpiresAt, result.RefreshExpiresAt)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), result.ExpiresAt, time.Minute)
+
+	// the successful attempt is recorded, an email without failures has no throttle to delete
+	assert.Len(t, attempts, 1)
+	assert.True(t, attempts[0].Success)
+	assert.Equal(t, user.ID, attempts[0].UserId)
+	assert.Equal(t, "10.0.0.1", attempts[0].IPAddress)
+	pkg.LoginAttemptRepository.Mock.AssertNotCalled(t, "DeleteThrottle", loginThrottleKey("email", "josuke@mail.com"))
+}
+
+func TestAuthUsecase_LoginWrongPassword(t *testing.T) {
+	hashedPassword, _ := helper.HashPassword("123")
+
+	pkg.UserRepository.Mock.On("FindByEmail", "okuyasu@mail.com").Return(&model.User{ID: "2b3c4d5e-6f7a-4b8c-9d0e-1f2a3b4c5d6e", Password: hashedPassword}, nil)
+	mockLoginThrottles("okuyasu@mail.com", nil, "10.0.1.1", nil)
+
+	var (
+		attempts     []model.LoginAttempt
+		emailFailure loginFailure
+		ipFailure    loginFailure
+	)
+
+	mockLoginAttempt("okuyasu@mail.com", &attempts)
+	mockLoginFailure(loginThrottleKey("email", "okuyasu@mail.com"), 1, &emailFailure)
+	mockLoginFailure(loginThrottleKey("ip", "10.0.1.1"), 1, &ipFailure)
+
+	result, err := authUsecaseTest.Login("okuyasu@mail.com", "321", "Mozilla/5.0", "10.0.1.1")
+
+	assert.Nil(t, result)
+	assert.Equal(t, pkg.ErrRecordNotFound, err)
+
+	assert.Len(t, attempts, 1)
+	assert.False(t, attempts[0].Success)
+	assert.Equal(t, model.LoginFailureWrongPassword, attempts[0].FailureReason)
+	assert.Equal(t, "2b3c4d5e-6f7a-4b8c-9d0e-1f2a3b4c5d6e", attempts[0].UserId)
+
+	// the failure counts for the email and for the ip address
+	assert.Equal(t, 1, emailFailure.Counted)
+	assert.Nil(t, emailFailure.LockedUntil)
+	assert.Equal(t, 1, ipFailure.Counted)
+	assert.Nil(t, ipFailure.LockedUntil)
+}
+
+func TestAuthUsecase_LoginUnknownEmail(t *testing.T) {
+	pkg.UserRepository.Mock.On("FindByEmail", "rohan@mail.com").Return(nil, pkg.ErrRecordNotFound)
+	mockLoginThrottles("rohan@mail.com", nil, "10.0.2.1", nil)
+
+	var (
+		attempts     []model.LoginAttempt
+		emailFailure loginFailure
+		ipFailure    loginFailure
+	)
+
+	mockLoginAttempt("rohan@mail.com", &attempts)
+	mockLoginFailure(loginThrottleKey("email", "rohan@mail.com"), 1, &emailFailure)
+	mockLoginFailure(loginThrottleKey("ip", "10.0.2.1"), 1, &ipFailure)
+
+	result, err := authUsecaseTest.Login("rohan@mail.com", "123", "Mozilla/5.0", "10.0.2.1")
+
+	// an unknown email is answered like a wrong password
+	assert.Nil(t, result)
+	assert.Equal(t, pkg.ErrRecordNotFound, err)
+
+	assert.Len(t, attempts, 1)
+	assert.Equal(t, model.LoginFailureUnknownEmail, attempts[0].FailureReason)
+	assert.Empty(t, attempts[0].UserId)
+	assert.Equal(t, 1, emailFailure.Counted)
+	assert.Equal(t, 1, ipFailure.Counted)
+}
+
+func TestAuthUsecase_LoginThrottled(t *testing.T) {
+	lastFailureAt := time.Now().Add(-time.Second)
+
+	// the fourth failure in a row waits two seconds
+	mockLoginThrottles("yukako@mail.com", &model.LoginThrottle{
+		Key:           loginThrottleKey("email", "yukako@mail.com"),
+		Failures:      4,
+		LastFailureAt: lastFailureAt,
+		UpdatedAt:     lastFailureAt,
+	}, "10.0.3.1", nil)
+
+	var attempts []model.LoginAttempt
+
+	mockLoginAttempt("yukako@mail.com", &attempts)
+
+	result, err := authUsecaseTest.Login("yukako@mail.com", "123", "Mozilla/5.0", "10.0.3.1")
+
+	var throttledErr *LoginThrottledError
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, pkg.ErrTooManyLoginAttempts)
+	assert.True(t, errors.As(err, &throttledErr))
+	assert.WithinDuration(t, lastFailureAt.Add(2*time.Second), throttledErr.RetryAt, time.Millisecond)
+
+	// the password is not checked and the refused attempt does not count as a failure
+	assert.Len(t, attempts, 1)
+	assert.Equal(t, model.LoginFailureThrottled, attempts[0].FailureReason)
+	pkg.UserRepository.Mock.AssertNotCalled(t, "FindByEmail", "yukako@mail.com")
+	pkg.LoginAttemptRepository.Mock.AssertNotCalled(t, "IncrementThrottle", loginThrottleKey("email", "yukako@mail.com"), mock.Anything, mock.Anything)
+}
+
+func TestAuthUsecase_LoginLocked(t *testing.T) {
+	lockedUntil := time.Now().Add(10 * time.Minute)
+
+	mockLoginThrottles("kira@mail.com", &model.LoginThrottle{
+		Key:           loginThrottleKey("email", "kira@mail.com"),
+		Failures:      10,
+		LastFailureAt: time.Now().Add(-5 * time.Minute),
+		LockedUntil:   &lockedUntil,
+	}, "10.0.4.1", nil)
+
+	var attempts []model.LoginAttempt
+
+	mockLoginAttempt("kira@mail.com", &attempts)
+
+	result, err := authUsecaseTest.Login("kira@mail.com", "123", "Mozilla/5.0", "10.0.4.1")
+
+	var throttledErr *LoginThrottledError
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, pkg.ErrAccountLocked)
+	assert.True(t, errors.As(err, &throttledErr))
+	assert.Equal(t, lockedUntil, throttledErr.RetryAt)
+
+	assert.Len(t, attempts, 1)
+	assert.Equal(t, model.LoginFailureLocked, attempts[0].FailureReason)
+	pkg.UserRepository.Mock.AssertNotCalled(t, "FindByEmail", "kira@mail.com")
+}
+
+func TestAuthUsecase_LoginLockedIPAddress(t *testing.T) {
+	lockedUntil := time.Now().Add(10 * time.Minute)
+
+	mockLoginThrottles("shigechi@mail.com", nil, "10.0.5.1", &model.LoginThrottle{
+		Key:           loginThrottleKey("ip", "10.0.5.1"),
+		Failures:      50,
+		LastFailureAt: time.Now().Add(-5 * time.Minute),
+		LockedUntil:   &lockedUntil,
+	})
+
+	var attempts []model.LoginAttempt
+
+	mockLoginAttempt("shigechi@mail.com", &attempts)
+
+	result, err := authUsecaseTest.Login("shigechi@mail.com", "123", "Mozilla/5.0", "10.0.5.1")
+
+	// an ip address trying many accounts does not lock any of them
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, pkg.ErrTooManyLoginAttempts)
+	assert.Len(t, attempts, 1)
+	assert.Equal(t, model.LoginFailureThrottled, attempts[0].FailureReason)
+}
+
+func TestAuthUsecase_LoginLockoutReached(t *testing.T) {
+	hashedPassword, _ := helper.HashPassword("123")
+
+	pkg.UserRepository.Mock.On("FindByEmail", "hayato@mail.com").Return(&model.User{ID: "8d9e0f1a-2b3c-4d4e-9f5a-7b8c9d0e1f2a", Password: hashedPassword}, nil)
+
+	// the delay of the ninth failure is over
+	mockLoginThrottles("hayato@mail.com", &model.LoginThrottle{
+		Key:           loginThrottleKey("email", "hayato@mail.com"),
+		Failures:      9,
+		LastFailureAt: time.Now().Add(-2 * time.Minute),
+		UpdatedAt:     time.Now().Add(-2 * time.Minute),
+	}, "10.0.6.1", nil)
+
+	var (
+		attempts     []model.LoginAttempt
+		emailFailure loginFailure
+		ipFailure    loginFailure
+	)
+
+	// the database counts the tenth failure of the email
+	mockLoginAttempt("hayato@mail.com", &attempts)
+	mockLoginFailure(loginThrottleKey("email", "hayato@mail.com"), 10, &emailFailure)
+	mockLoginFailure(loginThrottleKey("ip", "10.0.6.1"), 10, &ipFailure)
+
+	_, err := authUsecaseTest.Login("hayato@mail.com", "321", "Mozilla/5.0", "10.0.6.1")
+
+	assert.Equal(t, pkg.ErrRecordNotFound, err)
+
+	// the tenth failure locks the account, not the ip address
+	assert.Equal(t, 1, emailFailure.Counted)
+	assert.NotNil(t, emailFailure.LockedUntil)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), *emailFailure.LockedUntil, time.Minute)
+	assert.Equal(t, 1, ipFailure.Counted)
+	assert.Nil(t, ipFailure.LockedUntil)
+}
+
+func TestAuthUsecase_LoginFailureWindow(t *testing.T) {
+	hashedPassword, _ := helper.HashPassword("123")
+
+	pkg.UserRepository.Mock.On("FindByEmail", "mikitaka@mail.com").Return(&model.User{ID: "9e0f1a2b-3c4d-4e5f-8a6b-8c9d0e1f2a3b", Password: hashedPassword}, nil)
+
+	// the failures are older than the window
+	mockLoginThrottles("mikitaka@mail.com", &model.LoginThrottle{
+		Key:           loginThrottleKey("email", "mikitaka@mail.com"),
+		Failures:      8,
+		LastFailureAt: time.Now().Add(-time.Hour),
+		UpdatedAt:     time.Now().Add(-time.Hour),
+	}, "10.0.7.1", nil)
+
+	var (
+		attempts     []model.LoginAttempt
+		emailFailure loginFailure
+		ipFailure    loginFailure
+	)
+
+	mockLoginAttempt("mikitaka@mail.com", &attempts)
+	mockLoginFailure(loginThrottleKey("email", "mikitaka@mail.com"), 1, &emailFailure)
+	mockLoginFailure(loginThrottleKey("ip", "10.0.7.1"), 1, &ipFailure)
+
+	_, err := authUsecaseTest.Login("mikitaka@mail.com", "321", "Mozilla/5.0", "10.0.7.1")
+
+	assert.Equal(t, pkg.ErrRecordNotFound, err)
+	// the database starts the count over, the failures before the window are forgotten
+	assert.Equal(t, 1, emailFailure.Counted)
+	assert.Equal(t, emailFailure.FailedAt.Add(-15*time.Minute), emailFailure.WindowStart)
+	assert.Nil(t, emailFailure.LockedUntil)
+}
+
+func TestAuthUsecase_LoginResetsEmailThrottle(t *testing.T) {
+	configs.InitConfig()
+
+	hashedPassword, _ := helper.HashPassword("123")
+	user := &model.User{ID: "0f1a2b3c-4d5e-4f6a-9b7c-9d0e1f2a3b4c", Role: "customer", Email: "tonio@mail.com", Password: hashedPassword}
+
+	pkg.UserRepository.Mock.On("FindByEmail", "tonio@mail.com").Return(user, nil)
+	pkg.SessionRepository.Mock.On("Create", mock.MatchedBy(func(session model.Session) bool {
+		return session.UserId == user.ID
+	})).Return(nil)
+
+	// the delay of the third failure is over
+	mockLoginThrottles("tonio@mail.com", &model.LoginThrottle{
+		Key:           loginThrottleKey("email", "tonio@mail.com"),
+		Failures:      3,
+		LastFailureAt: time.Now().Add(-2 * time.Second),
+		UpdatedAt:     time.Now().Add(-2 * time.Second),
+	}, "10.0.8.1", nil)
+	pkg.LoginAttemptRepository.Mock.On("DeleteThrottle", loginThrottleKey("email", "tonio@mail.com")).Return(nil)
+
+	var attempts []model.LoginAttempt
+
+	mockLoginAttempt("tonio@mail.com", &attempts)
+
+	result, err := authUsecaseTest.Login("tonio@mail.com", "123", "Mozilla/5.0", "10.0.8.1")
+
+	assert.Nil(t, err)
+	assert.NotEmpty(t, result.Token)
+	pkg.LoginAttemptRepository.Mock.AssertCalled(t, "DeleteThrottle", loginThrottleKey("email", "tonio@mail.com"))
+	pkg.LoginAttemptRepository.Mock.AssertNotCalled(t, "DeleteThrottle", loginThrottleKey("ip", "10.0.8.1"))
+	assert.Len(t, attempts, 1)
+	assert.True(t, attempts[0].Success)
+}
+
+func TestAuthUsecase_FindAllLoginAttempts(t *testing.T) {
+	pkg.LoginAttemptRepository.Mock.On("FindAll", "joseph@mail.com", "", loginAttemptsLimit).Return(&[]model.LoginAttempt{
+		{ID: "1a2b3c4d-5e6f-4a7b-8c9d-1e2f3a4b5c6d", Email: "joseph@mail.com", Success: true},
+	}, nil)
+
+	attempts, err := authUsecaseTest.FindAllLoginAttempts("joseph@mail.com", "")
+
+	assert.Nil(t, err)
+	assert.Len(t, *attempts, 1)
+}
+
+func TestAuthUsecase_RefreshToken(t *testing.T) {
+	configs.InitConfig()
+
+	userId := "3c4d5e6f-7a8b-4c9d-8e0f-2a3b4c5d6e7f"
+	sessionId := "4d5e6f7a-8b9c-4d0e-9f1a-3b4c5d6e7f8a"
+	hash := helper.HashToken("refresh-token-koichi")
+
+	pkg.SessionRepository.Mock.On("FindByRefreshTokenHashForUpdate", hash).Return(&model.Session{
+		ID:                   sessionId,
+		UserId:               userId,
+		RefreshTokenHash:     hash,
+		AccessTokenJti:       "jti-koichi-1",
+		AccessTokenExpiresAt: time.Now().Add(30 * time.Minute),
+		ExpiresAt:            time.Now().Add(time.Hour),
+	}, nil)
+	pkg.UserRepository.Mock.On("FindById", userId).Return(&model.User{ID: userId, Role: "customer"}, nil)
+	pkg.SessionRepository.Mock.On("DeleteExpiredRevokedTokens", mock.Anything).Return(nil)
+
+	// the access token issued with the old refresh token is revoked
+	pkg.SessionRepository.Mock.On("RevokeToken", mock.MatchedBy(func(token model.RevokedToken) bool {
+		return token.Jti == "jti-koichi-1"
+	})).Return(nil)
+
+	var updated model.Session
+
+	pkg.SessionRepository.Mock.On("Update", sessionId, mock.MatchedBy(func(session model.Session) bool {
+		updated = session
+
+		return session.PreviousRefreshTokenHash == hash
+	})).Return(nil)
+
+	result, err := authUsecaseTest.RefreshToken("refresh-token-koichi", "curl/7.85.0", "10.0.0.2")
+
+	assert.Nil(t, err)
+	assert.NotEmpty(t, result.Token)
+	assert.NotEqual(t, "refresh-token-koichi", result.RefreshToken)
+	assert.Equal(t, helper.HashToken(result.RefreshToken), updated.RefreshTokenHash)
+	assert.NotEqual(t, "jti-koichi-1", updated.AccessTokenJti)
+	assert.Equal(t, "curl/7.85.0", updated.UserAgent)
+	assert.WithinDuration(t, time.Now().Add(720*time.Hour), updated.ExpiresAt, time.Minute)
+}
+
+func TestAuthUsecase_RefreshTokenReused(t *testing.T) {
+	sessionId := "5e6f7a8b-9c0d-4e1f-8a2b-4c5d6e7f8a9b"
+	hash := helper.HashToken("refresh-token-rohan")
+
+	// the refresh token was already rotated to another one
+	pkg.SessionRepository.Mock.On("FindByRefreshTokenHashForUpdate", hash).Return(&model.Session{
+		ID:                       sessionId,
+		UserId:                   "6f7a8b9c-0d1e-4f2a-9b3c-5d6e7f8a9b0c",
+		RefreshTokenHash:         helper.HashToken("refresh-token-rohan-2"),
+		PreviousRefreshTokenHash: hash,
+		AccessTokenJti:           "jti-rohan-2",
+		AccessTokenExpiresAt:     time.Now().Add(30 * time.Minute),
+		ExpiresAt:                time.Now().Add(time.Hour),
+	}, nil)
+	pkg.SessionRepository.Mock.On("DeleteExpiredRevokedTokens", mock.Anything).Return(nil)
+	pkg.SessionRepository.Mock.On("RevokeToken", mock.MatchedBy(func(token model.RevokedToken) bool {
+		return token.Jti == "jti-rohan-2"
+	})).Return(nil)
+	pkg.SessionRepository.Mock.On("Update", sessionId, mock.MatchedBy(func(session model.Session) bool {
+		return session.RevokedAt != nil
+	})).Return(nil)
+
+	result, err := authUsecaseTest.RefreshToken("refresh-token-rohan", "curl/7.85.0", "10.0.0.3")
+
+	assert.Nil(t, result)
+	assert.Equal(t, pkg.ErrInvalidRefreshToken, err)
+
+	// the whole session is logged out
+	pkg.SessionRepository.Mock.AssertCalled(t, "Update", sessionId, mock.MatchedBy(func(session model.Session) bool {
+		return session.RevokedAt != nil
+	}))
+	pkg.SessionRepository.Mock.AssertCalled(t, "RevokeToken", mock.MatchedBy(func(token model.RevokedToken) bool {
+		return token.Jti == "jti-rohan-2"
+	}))
+}
+
+func TestAuthUsecase_RefreshTokenInvalid(t *testing.T) {
+	expiredHash := helper.HashToken("refresh-token-expired")
+	unknownHash := helper.HashToken("refresh-token-unknown")
+	revokedAt := time.Now().Add(-time.Minute)
+
+	pkg.SessionRepository.Mock.On("FindByRefreshTokenHashForUpdate", expiredHash).Return(&model.Session{
+		ID:               "7a8b9c0d-1e2f-4a3b-8c4d-6e7f8a9b0c1d",
+		RefreshTokenHash: expiredHash,
+		ExpiresAt:        time.Now().Add(-time.Hour),
+	}, nil)
+	pkg.SessionRepository.Mock.On("FindByRefreshTokenHashForUpdate", helper.HashToken("refresh-token-revoked")).Return(&model.Session{
+		ID:               "8b9c0d1e-2f3a-4b4c-9d5e-7f8a9b0c1d2e",
+		RefreshTokenHash: helper.HashToken("refresh-token-revoked"),
+		ExpiresAt:        time.Now().Add(time.Hour),
+		RevokedAt:        &revokedAt,
+	}, nil)
+	pkg.SessionRepository.Mock.On("FindByRefreshTokenHashForUpdate", unknownHash).Return((*model.Session)(nil), pkg.ErrRecordNotFound)
+
+	for _, refreshToken := range []string{"refresh-token-expired", "refresh-token-revoked", "refresh-token-unknown"} {
+		result, err := authUsecaseTest.RefreshToken(refreshToken, "curl/7.85.0", "10.0.0.4")
+
+		assert.Nil(t, result)
+		assert.Equal(t, pkg.ErrInvalidRefreshToken, err)
+	}
+}
+
+func TestAuthUsecase_FindAllSessions(t *testing.T) {
+	userId := "9c0d1e2f-3a4b-4c5d-8e6f-8a9b0c1d2e3f"
+
+	pkg.SessionRepository.Mock.On("FindAllActiveByIdUser", userId, mock.Anything).Return(&[]model.Session{
+		{ID: "0d1e2f3a-4b5c-4d6e-9f7a-9b0c1d2e3f4a", UserId: userId},
+		{ID: "1e2f3a4b-5c6d-4e7f-8a8b-0c1d2e3f4a5b", UserId: userId},
+	}, nil)
+
+	result, err := authUsecaseTest.FindAllSessions(userId, "1e2f3a4b-5c6d-4e7f-8a8b-0c1d2e3f4a5b")
+
+	assert.Nil(t, err)
+	assert.Len(t, *result, 2)
+	assert.False(t, (*result)[0].Current)
+	assert.True(t, (*result)[1].Current)
+}
+
+func TestAuthUsecase_RevokeSession(t *testing.T) {
+	userId := "2f3a4b5c-6d7e-4f8a-9b9c-1d2e3f4a5b6c"
+	sessionId := "3a4b5c6d-7e8f-4a9b-8c0d-2e3f4a5b6c7d"
+
+	pkg.SessionRepository.Mock.On("FindByIdForUpdate", sessionId).Return(&model.Session{
+		ID:                   sessionId,
+		UserId:               userId,
+		AccessTokenJti:       "jti-jolyne-1",
+		AccessTokenExpiresAt: time.Now().Add(30 * time.Minute),
+	}, nil)
+	pkg.SessionRepository.Mock.On("Update", sessionId, mock.MatchedBy(func(session model.Session) bool {
+		return session.RevokedAt != nil
+	})).Return(nil)
+	pkg.SessionRepository.Mock.On("DeleteExpiredRevokedTokens", mock.Anything).Return(nil)
+	pkg.SessionRepository.Mock.On("RevokeToken", mock.MatchedBy(func(token model.RevokedToken) bool {
+		return token.Jti == "jti-jolyne-1"
+	})).Return(nil)
+
+	err := authUsecaseTest.RevokeSession(userId, sessionId)
+
+	assert.Nil(t, err)
+	pkg.SessionRepository.Mock.AssertCalled(t, "RevokeToken", mock.MatchedBy(func(token model.RevokedToken) bool {
+		return token.Jti == "jti-jolyne-1"
+	}))
+
+	// the sessions of other users cannot be revoked
+	err = authUsecaseTest.RevokeSession("4b5c6d7e-8f9a-4b0c-9d1e-3f4a5b6c7d8e", sessionId)
+
+	assert.Equal(t, pkg.ErrRecordNotFound, err)
+}
+
+func TestAuthUsecase_RevokeSessionAlreadyRevoked(t *testing.T) {
+	userId := "5c6d7e8f-9a0b-4c1d-8e2f-4a5b6c7d8e9f"
+	sessionId := "6d7e8f9a-0b1c-4d2e-9f3a-5b6c7d8e9f0a"
+	revokedAt := time.Now().Add(-time.Hour)
+
+	pkg.SessionRepository.Mock.On("FindByIdForUpdate", sessionId).Return(&model.Session{ID: sessionId, UserId: userId, RevokedAt: &revokedAt}, nil)
+
+	err := authUsecaseTest.RevokeSession(userId, sessionId)
+
+	assert.Nil(t, err)
+	pkg.SessionRepository.Mock.AssertNotCalled(t, "Update", sessionId, mock.Anything)
+}
+
+func TestAuthUsecase_SendEmailVerification(t *testing.T) {
+	userId := "a1b2c3d4-e5f6-4a7b-8c9d-0e1f2a3b4c6e"
+
+	pkg.UserRepository.Mock.On("FindById", userId).Return(&model.User{ID: userId, Fullname: "Giorno Giovanna", Email: "giorno@mail.com"}, nil)
+	pkg.UserTokenRepository.Mock.On("MarkAllUsed", userId, model.UserTokenEmailVerification, mock.Anything).Return(nil)
+
+	var created model.UserToken
+
+	pkg.UserTokenRepository.Mock.On("Create", mock.MatchedBy(func(token model.UserToken) bool {
+		if token.UserId != userId {
+			return false
+		}
+
+		created = token
+
+		return true
+	})).Return(nil)
+
+	err := authUsecaseTest.SendEmailVerification(userId)
+
+	assert.Nil(t, err)
+
+	msg, _ := testMailer.Last("giorno@mail.com")
+
+	assert.Equal(t, "Verify your email", msg.Subject)
+	assert.Contains(t, msg.Body, "Hi Giorno Giovanna")
+	assert.Contains(t, msg.Body, "http://localhost:3000/verify-email?token=")
+	assert.Contains(t, msg.Body, "48 hours")
+
+	// only the hash of the token sent is stored
+	assert.Equal(t, helper.HashToken(lastMailToken(t, "giorno@mail.com")), created.TokenHash)
+	assert.Equal(t, model.UserTokenEmailVerification, created.Purpose)
+	assert.WithinDuration(t, time.Now().Add(48*time.Hour), created.ExpiresAt, time.Minute)
+
+	// the links sent before stop working
+	pkg.UserTokenRepository.Mock.AssertCalled(t, "MarkAllUsed", userId, model.UserTokenEmailVerification, mock.Anything)
+}
+
+func TestAuthUsecase_SendEmailVerificationAlreadyVerified(t *testing.T) {
+	userId := "b2c3d4e5-f6a7-4b8c-9d0e-1f2a3b4c5d7f"
+	verifiedAt := time.Now().Add(-time.Hour)
+
+	pkg.UserRepository.Mock.On("FindById", userId).Return(&model.User{ID: userId, Email: "bruno@mail.com", EmailVerifiedAt: &verifiedAt}, nil)
+
+	err := authUsecaseTest.SendEmailVerification(userId)
+
+	assert.Equal(t, pkg.ErrEmailAlreadyVerified, err)
+
+	_, sent := testMailer.Last("bruno@mail.com")
+	assert.False(t, sent)
+}
+
+func TestAuthUsecase_VerifyEmail(t *testing.T) {
+	userId := "c3d4e5f6-a7b8-4c9d-8e0f-2a3b4c5d6e8a"
+	tokenId := "d4e5f6a7-b8c9-4d0e-9f1a-3b4c5d6e7f9b"
+	hash := helper.HashToken("verification-token-narancia")
+
+	pkg.UserTokenRepository.Mock.On("FindByTokenHashForUpdate", hash).Return(&model.UserToken{
+		ID:        tokenId,
+		UserId:    userId,
+		Purpose:   model.UserTokenEmailVerification,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	pkg.UserTokenRepository.Mock.On("MarkUsed", tokenId, mock.Anything).Return(nil)
+	pkg.UserRepository.Mock.On("FindById", userId).Return(&model.User{ID: userId}, nil)
+	pkg.UserRepository.Mock.On("Update", userId, mock.MatchedBy(func(user model.User) bool {
+		return user.EmailVerifiedAt != nil
+	})).Return(nil)
+
+	err := authUsecaseTest.VerifyEmail("verification-token-narancia")
+
+	assert.Nil(t, err)
+	pkg.UserTokenRepository.Mock.AssertCalled(t, "MarkUsed", tokenId, mock.Anything)
+	pkg.UserRepository.Mock.AssertCalled(t, "Update", userId, mock.MatchedBy(func(user model.User) bool {
+		return user.EmailVerifiedAt != nil
+	}))
+}
+
+func TestAuthUsecase_VerifyEmailInvalidToken(t *testing.T) {
+	usedAt := time.Now().Add(-time.Minute)
+
+	pkg.UserTokenRepository.Mock.On("FindByTokenHashForUpdate", helper.HashToken("verification-token-expired")).Return(&model.UserToken{
+		ID:        "e5f6a7b8-c9d0-4e1f-8a2b-4c5d6e7f8a0c",
+		Purpose:   model.UserTokenEmailVerification,
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}, nil)
+	pkg.UserTokenRepository.Mock.On("FindByTokenHashForUpdate", helper.HashToken("verification-token-used")).Return(&model.UserToken{
+		ID:        "f6a7b8c9-d0e1-4f2a-9b3c-5d6e7f8a9b1d",
+		Purpose:   model.UserTokenEmailVerification,
+		ExpiresAt: time.Now().Add(time.Hour),
+		UsedAt:    &usedAt,
+	}, nil)
+	// a password reset token does not verify the email
+	pkg.UserTokenRepository.Mock.On("FindByTokenHashForUpdate", helper.HashToken("reset-token-for-verification")).Return(&model.UserToken{
+		ID:        "a7b8c9d0-e1f2-4a3b-8c4d-6e7f8a9b0c2e",
+		Purpose:   model.UserTokenPasswordReset,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	pkg.UserTokenRepository.Mock.On("FindByTokenHashForUpdate", helper.HashToken("verification-token-unknown")).Return((*model.UserToken)(nil), pkg.ErrRecordNotFound)
+
+	for _, token := range []string{"verification-token-expired", "verification-token-used", "reset-token-for-verification", "verification-token-unknown"} {
+		err := authUsecaseTest.VerifyEmail(token)
+
+		assert.Equal(t, pkg.ErrInvalidUserToken, err, token)
+	}
+}
+
+func TestAuthUsecase_ForgotPassword(t *testing.T) {
+	userId := "b8c9d0e1-f2a3-4b4c-9d5e-7f8a9b0c1d3f"
+
+	pkg.UserRepository.Mock.On("FindByEmail", "mista@mail.com").Return(&model.User{ID: userId, Fullname: "Guido Mista", Email: "mista@mail.com"}, nil)
+	pkg.UserRepository.Mock.On("FindByEmail", "nobody@mail.com").Return(nil, pkg.ErrRecordNotFound)
+	pkg.UserTokenRepository.Mock.On("MarkAllUsed", userId, model.UserTokenPasswordReset, mock.Anything).Return(nil)
+	pkg.UserTokenRepository.Mock.On("Create", mock.MatchedBy(func(token model.UserToken) bool {
+		return token.UserId == userId && token.Purpose == model.UserTokenPasswordReset
+	})).Return(nil)
+
+	err := authUsecaseTest.ForgotPassword("mista@mail.com")
+
+	assert.Nil(t, err)
+
+	msg, _ := testMailer.Last("mista@mail.com")
+
+	assert.Equal(t, "Reset your password", msg.Subject)
+	assert.Contains(t, msg.Body, "http://localhost:3000/reset-password?token=")
+	assert.Contains(t, msg.Body, "30 minutes")
+	pkg.UserTokenRepository.Mock.AssertCalled(t, "Create", mock.MatchedBy(func(token model.UserToken) bool {
+		return token.TokenHash == helper.HashToken(lastMailToken(t, "mista@mail.com"))
+	}))
+
+	// an unknown email is answered the same way, without sending anything
+	err = authUsecaseTest.ForgotPassword("nobody@mail.com")
+
+	assert.Nil(t, err)
+
+	_, sent := testMailer.Last("nobody@mail.com")
+	assert.False(t, sent)
+}
+
+func TestAuthUsecase_ForgotPasswordMailNotSent(t *testing.T) {
+	userId := "c9d0e1f2-a3b4-4c5d-8e6f-8a9b0c1d2e4a"
+	failingMailer := &mailermock.MailerMock{}
+	failingMailer.Mock.On("Send", mock.Anything).Return(errors.New("dial tcp: connection refused"))
+
+	usecase := NewAuthUsecase(&pkg.UnitOfWork, &pkg.UserRepository, &pkg.SessionRepository, &pkg.LoginAttemptRepository, failingMailer, SessionPolicy{}, testAccountPolicy, testLoginPolicy)
+
+	pkg.UserRepository.Mock.On("FindByEmail", "fugo@mail.com").Return(&model.User{ID: userId, Email: "fugo@mail.com"}, nil)
+	pkg.UserTokenRepository.Mock.On("MarkAllUsed", userId, model.UserTokenPasswordReset, mock.Anything).Return(nil)
+	pkg.UserTokenRepository.Mock.On("Create", mock.MatchedBy(func(token model.UserToken) bool {
+		return token.UserId == userId
+	})).Return(nil)
+
+	err := usecase.ForgotPassword("fugo@mail.com")
+
+	assert.True(t, errors.Is(err, pkg.ErrMailNotSent))
+}
+
+func TestAuthUsecase_ResetPassword(t *testing.T) {
+	userId := "d0e1f2a3-b4c5-4d6e-9f7a-9b0c1d2e3f5b"
+	tokenId := "e1f2a3b4-c5d6-4e7f-8a8b-0c1d2e3f4a6c"
+	sessionId := "f2a3b4c5-d6e7-4f8a-9b9c-1d2e3f4a5b7d"
+	hash := helper.HashToken("reset-token-abbacchio")
+
+	pkg.UserTokenRepository.Mock.On("FindByTokenHashForUpdate", hash).Return(&model.UserToken{
+		ID:        tokenId,
+		UserId:    userId,
+		Purpose:   model.UserTokenPasswordReset,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(10 * time.Minute),
+	}, nil)
+	pkg.UserTokenRepository.Mock.On("MarkUsed", tokenId, mock.Anything).Return(nil)
+	pkg.UserTokenRepository.Mock.On("MarkAllUsed", userId, model.UserTokenPasswordReset, mock.Anything).Return(nil)
+	pkg.UserRepository.Mock.On("FindById", userId).Return(&model.User{ID: userId}, nil)
+
+	var updated model.User
+
+	pkg.UserRepository.Mock.On("Update", userId, mock.MatchedBy(func(user model.User) bool {
+		updated = user
+
+		return user.Password != ""
+	})).Return(nil)
+	pkg.SessionRepository.Mock.On("FindAllActiveByIdUser", userId, mock.Anything).Return(&[]model.Session{
+		{ID: sessionId, UserId: userId, AccessTokenJti: "jti-abbacchio-1", AccessTokenExpiresAt: time.Now().Add(30 * time.Minute)},
+	}, nil)
+	pkg.SessionRepository.Mock.On("Update", sessionId, mock.MatchedBy(func(session model.Session) bool {
+		return session.RevokedAt != nil
+	})).Return(nil)
+	pkg.SessionRepository.Mock.On("DeleteExpiredRevokedTokens", mock.Anything).Return(nil)
+	pkg.SessionRepository.Mock.On("RevokeToken", mock.MatchedBy(func(token model.RevokedToken) bool {
+		return token.Jti == "jti-abbacchio-1"
+	})).Return(nil)
+
+	err := authUsecaseTest.ResetPassword("reset-token-abbacchio", "new-secret")
+
+	assert.Nil(t, err)
+	assert.True(t, helper.ComparePassword(updated.Password, "new-secret"))
+	assert.NotNil(t, updated.EmailVerifiedAt)
+
+	// every session of the user is logged out
+	pkg.SessionRepository.Mock.AssertCalled(t, "Update", sessionId, mock.MatchedBy(func(session model.Session) bool {
+		return session.RevokedAt != nil
+	}))
+	pkg.SessionRepository.Mock.AssertCalled(t, "RevokeToken", mock.MatchedBy(func(token model.RevokedToken) bool {
+		return token.Jti == "jti-abbacchio-1"
+	}))
+}
+
+func TestAuthUsecase_ResetPasswordInvalidToken(t *testing.T) {
+	usedAt := time.Now().Add(-time.Minute)
+
+	pkg.UserTokenRepository.Mock.On("FindByTokenHashForUpdate", helper.HashToken("reset-token-used")).Return(&model.UserToken{
+		ID:        "a3b4c5d6-e7f8-4a9b-8c0d-2e3f4a5b6c8e",
+		UserId:    "b4c5d6e7-f8a9-4b0c-9d1e-3f4a5b6c7d9f",
+		Purpose:   model.UserTokenPasswordReset,
+		ExpiresAt: time.Now().Add(10 * time.Minute),
+		UsedAt:    &usedAt,
+	}, nil)
+
+	err := authUsecaseTest.ResetPassword("reset-token-used", "new-secret")
+
+	assert.Equal(t, pkg.ErrInvalidUserToken, err)
+	pkg.UserRepository.Mock.AssertNotCalled(t, "Update", "b4c5d6e7-f8a9-4b0c-9d1e-3f4a5b6c7d9f", mock.Anything)
+}
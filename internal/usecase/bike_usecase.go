@@ -5,55 +5,81 @@ import (
 
 	"github.com/arvinpaundra/go-rent-bike/internal/dto"
 	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/pricing"
 	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
 	"github.com/google/uuid"
 )
 
 type BikeUsecase interface {
-	CreateNewBike(bikeDTO dto.BikeDTO) error
+	CreateNewBike(actorId string, bikeDTO dto.BikeDTO) error
 	CreateNewBikeReview(bikeId string, reviewDTO dto.ReviewDTO) error
 	FindAllBikes(bikeName string) (*[]model.Bike, error)
 	FindByIdBike(bikeId string) (*model.Bike, error)
 	FindBikesByRenter(renterId string) (*[]model.Bike, error)
 	FindBikesByCategory(categoryId string) (*[]model.Bike, error)
+	CheckBikeAvailability(bikeId string, startAt time.Time, endAt time.Time) (*dto.BikeAvailabilityDTO, error)
+	QuoteBike(bikeId string, startAt time.Time, endAt time.Time) (*pricing.Quote, error)
 	UpdateBike(bikeId string, bikeDTO dto.BikeDTO) error
 	DeleteBike(bikeId string) error
 }
 
 type bikeUsecase struct {
-	bikeRepository     repository.BikeRepository
-	renterRepository   repository.RenterRepository
-	categoryRepository repository.CategoryRepository
-	userRepository     repository.UserRepository
-	reviewRepository   repository.ReviewRepository
+	bikeRepository        repository.BikeRepository
+	renterRepository      repository.RenterRepository
+	categoryRepository    repository.CategoryRepository
+	userRepository        repository.UserRepository
+	reviewRepository      repository.ReviewRepository
+	pricingRuleRepository repository.PricingRuleRepository
+	pricingEngine         pricing.Engine
 }
 
-func (u bikeUsecase) CreateNewBike(bikeDTO dto.BikeDTO) error {
+// CreateNewBike adds a bike to a renter, only the user of the renter may add bikes to it.
+func (u bikeUsecase) CreateNewBike(actorId string, bikeDTO dto.BikeDTO) error {
 	renterId := bikeDTO.RenterId
 	categoryId := bikeDTO.CategoryId
 
-	if _, err := u.renterRepository.FindById(renterId); err != nil {
+	renter, err := u.renterRepository.FindById(renterId)
+
+	if err != nil {
 		return err
 	}
 
+	if renter.UserId != actorId {
+		return pkg.ErrForbidden
+	}
+
+	if renter.IsSuspended() {
+		return pkg.ErrRenterSuspended
+	}
+
 	if _, err := u.categoryRepository.FindById(categoryId); err != nil {
 		return err
 	}
 
+	if err := validateBikeRates(bikeDTO); err != nil {
+		return err
+	}
+
 	bike := model.Bike{
-		ID:           uuid.NewString(),
-		RenterId:     renterId,
-		CategoryId:   categoryId,
-		Name:         bikeDTO.Name,
-		PricePerHour: bikeDTO.PricePerHour,
-		Condition:    bikeDTO.Condition,
-		Description:  bikeDTO.Description,
-		IsAvailable:  bikeDTO.IsAvailable,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		ID:                uuid.NewString(),
+		RenterId:          renterId,
+		CategoryId:        categoryId,
+		Name:              bikeDTO.Name,
+		PricePerHour:      bikeDTO.PricePerHour,
+		PricePerDay:       bikeDTO.PricePerDay,
+		PricePerWeek:      bikeDTO.PricePerWeek,
+		WeekendMultiplier: bikeDTO.WeekendMultiplier,
+		HolidayMultiplier: bikeDTO.HolidayMultiplier,
+		Deposit:           bikeDTO.Deposit,
+		Condition:         bikeDTO.Condition,
+		Description:       bikeDTO.Description,
+		IsAvailable:       bikeDTO.IsAvailable,
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
 	}
 
-	err := u.bikeRepository.Create(bike)
+	err = u.bikeRepository.Create(bike)
 
 	if err != nil {
 		return err
@@ -110,6 +136,53 @@ func (u bikeUsecase) FindBikesByCategory(categoryId string) (*[]model.Bike, erro
 	return bikes, nil
 }
 
+func (u bikeUsecase) CheckBikeAvailability(bikeId string, startAt time.Time, endAt time.Time) (*dto.BikeAvailabilityDTO, error) {
+	if err := validateRentWindow(startAt, endAt); err != nil {
+		return nil, err
+	}
+
+	bike, err := u.bikeRepository.FindById(bikeId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the same windows the order flow refuses a booking for
+	conflicts, err := findBookedWindows(u.bikeRepository, bikeId, startAt, endAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	availability := &dto.BikeAvailabilityDTO{
+		BikeId:    bike.ID,
+		Available: bike.IsAvailable != "0" && len(conflicts) == 0,
+		Conflicts: conflicts,
+	}
+
+	return availability, nil
+}
+
+func (u bikeUsecase) QuoteBike(bikeId string, startAt time.Time, endAt time.Time) (*pricing.Quote, error) {
+	if !endAt.After(startAt) {
+		return nil, pkg.ErrInvalidRentWindow
+	}
+
+	bike, err := u.bikeRepository.FindById(bikeId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := quoteBike(u.pricingEngine, u.pricingRuleRepository, *bike, startAt, endAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &quote, nil
+}
+
 func (u bikeUsecase) UpdateBike(bikeId string, bikeDTO dto.BikeDTO) error {
 	var err error
 	_, err = u.bikeRepository.FindById(bikeId)
@@ -128,14 +201,23 @@ func (u bikeUsecase) UpdateBike(bikeId string, bikeDTO dto.BikeDTO) error {
 		return err
 	}
 
+	if err := validateBikeRates(bikeDTO); err != nil {
+		return err
+	}
+
 	updatedBike := model.Bike{
-		CategoryId:   categoryId,
-		Name:         bikeDTO.Name,
-		PricePerHour: bikeDTO.PricePerHour,
-		Condition:    bikeDTO.Condition,
-		Description:  bikeDTO.Description,
-		IsAvailable:  bikeDTO.IsAvailable,
-		UpdatedAt:    time.Now(),
+		CategoryId:        categoryId,
+		Name:              bikeDTO.Name,
+		PricePerHour:      bikeDTO.PricePerHour,
+		PricePerDay:       bikeDTO.PricePerDay,
+		PricePerWeek:      bikeDTO.PricePerWeek,
+		WeekendMultiplier: bikeDTO.WeekendMultiplier,
+		HolidayMultiplier: bikeDTO.HolidayMultiplier,
+		Deposit:           bikeDTO.Deposit,
+		Condition:         bikeDTO.Condition,
+		Description:       bikeDTO.Description,
+		IsAvailable:       bikeDTO.IsAvailable,
+		UpdatedAt:         time.Now(),
 	}
 
 	err = u.bikeRepository.Update(bikeId, updatedBike)
@@ -193,18 +275,60 @@ func (u bikeUsecase) CreateNewBikeReview(bikeId string, reviewDTO dto.ReviewDTO)
 	return nil
 }
 
+// quoteBike prices a bike for the rent window with its rate table and the rules of its renter.
+// Orders are charged with the same quote, so the price shown to customers is the price they pay.
+func quoteBike(engine pricing.Engine, pricingRuleRepository repository.PricingRuleRepository, bike model.Bike, startAt time.Time, endAt time.Time) (pricing.Quote, error) {
+	pricingRules, err := pricingRuleRepository.FindActive(bike.RenterId, startAt, endAt)
+
+	if err != nil {
+		return pricing.Quote{}, err
+	}
+
+	rules := []pricing.Rule{}
+	for i := range *pricingRules {
+		rules = append(rules, pricing.Rule{
+			Name:       (*pricingRules)[i].Name,
+			StartAt:    (*pricingRules)[i].StartAt,
+			EndAt:      (*pricingRules)[i].EndAt,
+			Multiplier: (*pricingRules)[i].Multiplier,
+		})
+	}
+
+	rates := pricing.Rates{
+		Hourly:            bike.PricePerHour,
+		Daily:             bike.PricePerDay,
+		Weekly:            bike.PricePerWeek,
+		WeekendMultiplier: bike.WeekendMultiplier,
+		HolidayMultiplier: bike.HolidayMultiplier,
+	}
+
+	return engine.Quote(rates, rules, startAt, endAt), nil
+}
+
+func validateBikeRates(bikeDTO dto.BikeDTO) error {
+	if bikeDTO.PricePerHour < 0 || bikeDTO.PricePerDay < 0 || bikeDTO.PricePerWeek < 0 || bikeDTO.WeekendMultiplier < 0 || bikeDTO.HolidayMultiplier < 0 || bikeDTO.Deposit < 0 {
+		return pkg.ErrInvalidPricing
+	}
+
+	return nil
+}
+
 func NewBikeUsecase(
 	bikeRepo repository.BikeRepository,
 	renterRepo repository.RenterRepository,
 	categoryRepo repository.CategoryRepository,
 	userRepo repository.UserRepository,
 	reviewRepo repository.ReviewRepository,
+	pricingRuleRepo repository.PricingRuleRepository,
+	pricingEngine pricing.Engine,
 ) BikeUsecase {
 	return bikeUsecase{
-		bikeRepository:     bikeRepo,
-		renterRepository:   renterRepo,
-		categoryRepository: categoryRepo,
-		userRepository:     userRepo,
-		reviewRepository:   reviewRepo,
+		bikeRepository:        bikeRepo,
+		renterRepository:      renterRepo,
+		categoryRepository:    categoryRepo,
+		userRepository:        userRepo,
+		reviewRepository:      reviewRepo,
+		pricingRuleRepository: pricingRuleRepo,
+		pricingEngine:         pricingEngine,
 	}
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/arvinpaundra/go-rent-bike/internal/dto"
 	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/pricing"
 	repomock "github.com/arvinpaundra/go-rent-bike/internal/repository/gormdb/mock"
 	"github.com/arvinpaundra/go-rent-bike/pkg"
 	"github.com/stretchr/testify/assert"
@@ -19,6 +20,8 @@ var bikeUsecaseTest = NewBikeUsecase(
 	&pkg.CategoryRepository,
 	&pkg.UserRepository,
 	&pkg.ReviewRepository,
+	&pkg.PricingRuleRepository,
+	pricing.NewEngine(nil),
 )
 
 func TestBikeUsecase_CreateNewBike(t *testing.T) {
@@ -59,9 +62,31 @@ func TestBikeUsecase_CreateNewBike(t *testing.T) {
 		IsAvailable:  "1",
 	}
 
-	err := bikeUsecaseTest.CreateNewBike(bikeDTO)
+	err := bikeUsecaseTest.CreateNewBike(renter.UserId, bikeDTO)
 
 	assert.Nil(t, err)
+
+	// another renter cannot add bikes to this one
+	err = bikeUsecaseTest.CreateNewBike("5b9e2c1d-8f3a-4d6e-9a7b-0c1d2e3f4a5b", bikeDTO)
+
+	assert.Equal(t, pkg.ErrForbidden, err)
+}
+
+func TestBikeUsecase_CreateNewBikeRenterSuspended(t *testing.T) {
+	renterId := "0f2a4c6e-8b1d-4f3a-95c7-e9b1d3f5a7c2"
+	suspendedAt := time.Now()
+
+	renter := &model.Renter{
+		ID:          renterId,
+		UserId:      "2a4c6e8b-1d3f-4a5c-b7e9-1d3f5a7c9e14",
+		SuspendedAt: &suspendedAt,
+	}
+
+	pkg.RenterRepository.Mock.On("FindById", renterId).Return(renter, nil)
+
+	err := bikeUsecaseTest.CreateNewBike(renter.UserId, dto.BikeDTO{RenterId: renterId, Name: "Sample BMX Bike"})
+
+	assert.Equal(t, pkg.ErrRenterSuspended, err)
 }
 
 func TestBikeUsecase_CreateNewBikeReview(t *testing.T) {
@@ -170,6 +195,125 @@ func TestBikeUsecase_FindByIdBike(t *testing.T) {
 	assert.Equal(t, bike.IsAvailable, result.IsAvailable)
 }
 
+func TestBikeUsecase_CheckBikeAvailability(t *testing.T) {
+	bikeId := "5e0a3a9c-0d8e-4a57-9d0c-0b3f3c1c2b11"
+
+	bike := &model.Bike{
+		ID:           "5e0a3a9c-0d8e-4a57-9d0c-0b3f3c1c2b11",
+		RenterId:     "abd85a80-200b-4c76-9376-1f968e3e7393",
+		CategoryId:   "79770d28-69d0-4c6c-95f7-505e86c880ba",
+		Name:         "Sample BMX Bike",
+		PricePerHour: 15000,
+		Condition:    "Good",
+		Description:  "This is a description section",
+		IsAvailable:  "1",
+	}
+
+	bikeRepository.Mock.On("FindById", bikeId).Return(bike, nil)
+
+	now := time.Now()
+	startAt := now.Add(24 * time.Hour)
+	endAt := now.Add(28 * time.Hour)
+
+	// the first order waits for its extension to be paid, which holds the bike until the extended end
+	pendingEndAt := now.Add(27 * time.Hour)
+	orders := &[]model.Order{
+		{
+			ID:           "a3e0d0f4-6a43-4f0f-9a4c-4a3bd1a0c3f1",
+			StartAt:      now.Add(20 * time.Hour),
+			EndAt:        now.Add(23 * time.Hour),
+			PendingEndAt: &pendingEndAt,
+		},
+		{
+			ID:      "b4f1e1a5-7b54-4a1a-8b5d-5b4ce2b1d4a2",
+			StartAt: now.Add(27 * time.Hour),
+			EndAt:   now.Add(30 * time.Hour),
+		},
+	}
+
+	bikeRepository.Mock.On("FindOverlappingOrders", bikeId, startAt, endAt).Return(orders, nil)
+
+	result, err := bikeUsecaseTest.CheckBikeAvailability(bikeId, startAt, endAt)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+
+	assert.Equal(t, bikeId, result.BikeId)
+	assert.False(t, result.Available)
+	assert.Len(t, result.Conflicts, 2)
+	assert.Equal(t, (*orders)[0].StartAt, result.Conflicts[0].StartAt)
+	assert.Equal(t, pendingEndAt, result.Conflicts[0].EndAt)
+	assert.Equal(t, (*orders)[1].StartAt, result.Conflicts[1].StartAt)
+	assert.Equal(t, (*orders)[1].EndAt, result.Conflicts[1].EndAt)
+
+	_, err = bikeUsecaseTest.CheckBikeAvailability(bikeId, endAt, startAt)
+
+	assert.ErrorIs(t, err, pkg.ErrInvalidRentWindow)
+}
+
+func TestBikeUsecase_CheckBikeAvailabilityStartedWindow(t *testing.T) {
+	bikeId := "5e0a3a9c-0d8e-4a57-9d0c-0b3f3c1c2b11"
+
+	now := time.Now()
+
+	// the window ends in the future but already started
+	result, err := bikeUsecaseTest.CheckBikeAvailability(bikeId, now.Add(-time.Hour), now.Add(3*time.Hour))
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, pkg.ErrInvalidRentWindow)
+	bikeRepository.Mock.AssertNotCalled(t, "FindOverlappingOrders", bikeId, now.Add(-time.Hour), now.Add(3*time.Hour))
+}
+
+func TestBikeUsecase_QuoteBike(t *testing.T) {
+	bikeId := "6f1b4bad-1e9f-4b68-8e1d-1c4f4d2d3c22"
+	renterId := "7a2c5cbe-2fa0-4c79-9f2e-2d5a5e3e4d33"
+
+	bike := &model.Bike{
+		ID:           "6f1b4bad-1e9f-4b68-8e1d-1c4f4d2d3c22",
+		RenterId:     "7a2c5cbe-2fa0-4c79-9f2e-2d5a5e3e4d33",
+		CategoryId:   "79770d28-69d0-4c6c-95f7-505e86c880ba",
+		Name:         "Sample Touring Bike",
+		PricePerHour: 15000,
+		PricePerDay:  100000,
+		IsAvailable:  "1",
+	}
+
+	bikeRepository.Mock.On("FindById", bikeId).Return(bike, nil)
+
+	// a monday, the second day falls in the renter's high season
+	startAt := time.Date(2022, 11, 21, 8, 0, 0, 0, time.UTC)
+	endAt := time.Date(2022, 11, 23, 8, 0, 0, 0, time.UTC)
+
+	pricingRules := &[]model.PricingRule{
+		{
+			ID:         "8b3d6dcf-3ab1-4d8a-8a3f-3e6b6f4f5e44",
+			RenterId:   "7a2c5cbe-2fa0-4c79-9f2e-2d5a5e3e4d33",
+			Name:       "high season",
+			StartAt:    time.Date(2022, 11, 22, 8, 0, 0, 0, time.UTC),
+			EndAt:      time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC),
+			Multiplier: 1.5,
+		},
+	}
+
+	pkg.PricingRuleRepository.Mock.On("FindActive", renterId, startAt, endAt).Return(pricingRules, nil)
+
+	result, err := bikeUsecaseTest.QuoteBike(bikeId, startAt, endAt)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+
+	assert.Equal(t, 48, result.TotalHour)
+	assert.Len(t, result.Lines, 2)
+	assert.Equal(t, float32(100000), result.Lines[0].Amount)
+	assert.Equal(t, float32(150000), result.Lines[1].Amount)
+	assert.Equal(t, []string{"high season"}, result.Lines[1].Adjustments)
+	assert.Equal(t, float32(250000), result.Total)
+
+	_, err = bikeUsecaseTest.QuoteBike(bikeId, endAt, startAt)
+
+	assert.ErrorIs(t, err, pkg.ErrInvalidRentWindow)
+}
+
 func TestBikeUsecase_FindBikesByRenter(t *testing.T) {
 	renterId := "127fe83c-21b2-4d2e-ab98-369b88d4eec5"
 
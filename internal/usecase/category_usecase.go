@@ -8,6 +8,7 @@ import (
 	"github.com/arvinpaundra/go-rent-bike/internal/dto"
 	"github.com/arvinpaundra/go-rent-bike/internal/model"
 	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
 )
 
 type CategoryUsecase interface {
@@ -23,9 +24,14 @@ type categoryUsecase struct {
 }
 
 func (c categoryUsecase) CreateCategory(categoryDTO dto.CategoryDTO) error {
+	if categoryDTO.Deposit < 0 {
+		return pkg.ErrInvalidPricing
+	}
+
 	category := model.Category{
 		ID:        uuid.NewString(),
 		Name:      categoryDTO.Name,
+		Deposit:   categoryDTO.Deposit,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -68,8 +74,13 @@ func (c categoryUsecase) UpdateCategory(categoryId string, categoryDTO dto.Categ
 		return err
 	}
 
+	if categoryDTO.Deposit < 0 {
+		return pkg.ErrInvalidPricing
+	}
+
 	categoryUC := model.Category{
 		Name:      categoryDTO.Name,
+		Deposit:   categoryDTO.Deposit,
 		UpdatedAt: time.Now(),
 	}
 
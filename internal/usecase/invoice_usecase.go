@@ -0,0 +1,341 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/invoice"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/google/uuid"
+)
+
+type InvoiceUsecase interface {
+	GenerateInvoice(orderId string, actorId string) ([]byte, error)
+	GenerateReceipt(orderId string, actorId string) ([]byte, error)
+}
+
+// InvoicePolicy holds the tax shown on invoices, the prices of the bikes already include it.
+type InvoicePolicy struct {
+	TaxName    string
+	TaxPercent float32
+}
+
+type invoiceUsecase struct {
+	unitOfWork repository.UnitOfWork
+	policy     InvoicePolicy
+}
+
+// GenerateInvoice renders the invoices of an order to pdf, one invoice for every renter of the order.
+// The customer gets all of them, a renter only its own.
+func (u invoiceUsecase) GenerateInvoice(orderId string, actorId string) ([]byte, error) {
+	return u.generate(orderId, actorId, invoice.TitleInvoice)
+}
+
+// GenerateReceipt renders the invoices of an order as receipts, once its payment is settled.
+func (u invoiceUsecase) GenerateReceipt(orderId string, actorId string) ([]byte, error) {
+	return u.generate(orderId, actorId, invoice.TitleReceipt)
+}
+
+func (u invoiceUsecase) generate(orderId string, actorId string, title string) ([]byte, error) {
+	var docs []invoice.Document
+
+	err := u.unitOfWork.WithTx(func(repos repository.Repositories) error {
+		order, err := repos.Order.FindById(orderId)
+
+		if err != nil {
+			return err
+		}
+
+		details, err := repos.OrderDetail.FindByIdOrder(order.ID)
+
+		if err != nil {
+			return err
+		}
+
+		subtotals := map[string]float32{}
+
+		for _, detail := range *details {
+			if detail.Bike != nil {
+				subtotals[detail.Bike.RenterId] += detail.Subtotal
+			}
+		}
+
+		// the bikes of the order were deleted since, nobody is left to issue an invoice
+		if len(subtotals) == 0 {
+			return fmt.Errorf("%w: the order has no bikes to invoice", pkg.ErrRecordNotFound)
+		}
+
+		renterIds, err := invoiceRenters(repos.Renter, *order, subtotals, actorId)
+
+		if err != nil {
+			return err
+		}
+
+		payment, err := repos.Payment.FindById(order.PaymentId)
+
+		if err != nil {
+			return err
+		}
+
+		var paidAt *time.Time
+
+		if title == invoice.TitleReceipt {
+			paidAt, err = findPaidAt(repos.OrderStatusHistory, *order, *payment)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		customer, err := repos.User.FindById(order.UserId)
+
+		if err != nil {
+			return err
+		}
+
+		invoices, err := issueInvoices(repos.Invoice, order.ID, subtotals)
+
+		if err != nil {
+			return err
+		}
+
+		for _, renterId := range renterIds {
+			renter, err := repos.Renter.FindById(renterId)
+
+			if err != nil {
+				return err
+			}
+
+			doc := u.buildDocument(*order, *details, subtotals, *renter, invoices[renterId])
+			doc.Title = title
+			doc.Customer = invoice.Party{Name: customer.Fullname, Address: customer.Address, Email: customer.Email, Phone: customer.Phone}
+			doc.PaymentType = payment.PaymentType
+			doc.PaidAt = paidAt
+
+			docs = append(docs, doc)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return invoice.Render(docs)
+}
+
+// buildDocument itemizes the bikes a renter rented out in the order. The discount and the extensions of the order
+// are shared between its renters the same way its payment is.
+func (u invoiceUsecase) buildDocument(order model.Order, details []model.OrderDetail, subtotals map[string]float32, renter model.Renter, issued model.Invoice) invoice.Document {
+	doc := invoice.Document{
+		Number:   issued.Number,
+		IssuedAt: issued.CreatedAt,
+		OrderId:  order.ID,
+		StartAt:  order.StartAt,
+		EndAt:    order.EndAt,
+		Seller:   invoice.Party{Name: renter.RentName, Address: renter.RentAddress, Email: renter.User.Email, Phone: renter.User.Phone},
+	}
+
+	var rented, deposit float32
+
+	// a bike is billed for the hours it was first rented for, the extensions bill the rest.
+	// Bikes ordered before those hours were kept are billed for the hours of the order.
+	hours := order.TotalHour
+	deposits := []invoice.Line{}
+
+	for _, detail := range details {
+		if detail.Bike == nil || detail.Bike.RenterId != renter.ID {
+			continue
+		}
+
+		if detail.Hours > 0 {
+			hours = detail.Hours
+		}
+
+		description := detail.Bike.Name
+		if detail.Bike.Category.Name != "" {
+			description = fmt.Sprintf("%s (%s)", detail.Bike.Name, detail.Bike.Category.Name)
+		}
+
+		doc.Lines = append(doc.Lines, invoice.Line{Description: description, Hours: hours, Amount: detail.Subtotal})
+		rented += detail.Subtotal
+
+		if detail.Deposit > 0 {
+			deposits = append(deposits, invoice.Line{Description: "Security deposit " + detail.Bike.Name, Amount: detail.Deposit})
+			deposit += detail.Deposit
+		}
+	}
+
+	var ordered float32
+	for _, subtotal := range subtotals {
+		ordered += subtotal
+	}
+
+	// the total payment grows with every paid extension
+	extension := renterShareOf(order.TotalPayment+order.Discount-ordered, subtotals, renter.ID)
+	if extension > 0 {
+		doc.Lines = append(doc.Lines, invoice.Line{Description: "Rent extension", Hours: order.TotalHour - hours, Amount: extension})
+	}
+
+	discount := renterShareOf(order.Discount, subtotals, renter.ID)
+	if discount > 0 {
+		doc.Lines = append(doc.Lines, invoice.Line{Description: "Voucher discount", Amount: -discount})
+	}
+
+	doc.Lines = append(doc.Lines, deposits...)
+
+	// the deposit is given back, so only the rent is taxed
+	rent := rented + extension - discount
+
+	if u.policy.TaxPercent > 0 {
+		doc.Taxes = []invoice.Tax{{Name: u.policy.TaxName, Percent: u.policy.TaxPercent, Amount: invoice.IncludedTax(rent, u.policy.TaxPercent)}}
+	}
+
+	doc.Total = rent + deposit
+
+	return doc
+}
+
+// invoiceRenters are the renters whose invoices the actor may see: all of them for the customer of the order,
+// only its own for a renter of the order.
+func invoiceRenters(renterRepository repository.RenterRepository, order model.Order, subtotals map[string]float32, actorId string) ([]string, error) {
+	if actorId == order.UserId {
+		renterIds := make([]string, 0, len(subtotals))
+
+		for renterId := range subtotals {
+			renterIds = append(renterIds, renterId)
+		}
+
+		sort.Strings(renterIds)
+
+		return renterIds, nil
+	}
+
+	renter, err := findActorRenter(renterRepository, actorId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := subtotals[renter.ID]; !ok {
+		return nil, pkg.ErrForbidden
+	}
+
+	return []string{renter.ID}, nil
+}
+
+// issueInvoices numbers the invoices of an order the first time they are asked for, every renter in the order
+// takes the next number of its own sequence. The renters are always locked in the same order, so two orders
+// issuing their invoices at the same time cannot deadlock.
+func issueInvoices(invoiceRepository repository.InvoiceRepository, orderId string, subtotals map[string]float32) (map[string]model.Invoice, error) {
+	issued, err := invoiceRepository.FindByIdOrder(orderId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	invoices := map[string]model.Invoice{}
+
+	for _, inv := range *issued {
+		invoices[inv.RenterId] = inv
+	}
+
+	renterIds := make([]string, 0, len(subtotals))
+
+	for renterId := range subtotals {
+		if _, ok := invoices[renterId]; !ok {
+			renterIds = append(renterIds, renterId)
+		}
+	}
+
+	sort.Strings(renterIds)
+
+	for _, renterId := range renterIds {
+		sequence := 1
+
+		last, err := invoiceRepository.FindLastByIdRenterForUpdate(renterId)
+
+		if err == nil {
+			sequence = last.Sequence + 1
+		} else if !errors.Is(err, pkg.ErrRecordNotFound) {
+			return nil, err
+		}
+
+		inv := model.Invoice{
+			ID:        uuid.NewString(),
+			OrderId:   orderId,
+			RenterId:  renterId,
+			Sequence:  sequence,
+			Number:    invoiceNumber(renterId, sequence),
+			CreatedAt: time.Now(),
+		}
+
+		if err := invoiceRepository.Create(inv); err != nil {
+			return nil, err
+		}
+
+		invoices[renterId] = inv
+	}
+
+	return invoices, nil
+}
+
+// invoiceNumber tells the renter apart by the first part of its id, e.g. INV-5E6F7A8B-00042.
+func invoiceNumber(renterId string, sequence int) string {
+	prefix := strings.ToUpper(strings.SplitN(renterId, "-", 2)[0])
+
+	return fmt.Sprintf("INV-%s-%05d", prefix, sequence)
+}
+
+// findPaidAt is when the order was paid. Only a settled payment has a receipt, even once part of it was refunded.
+func findPaidAt(statusHistoryRepository repository.OrderStatusHistoryRepository, order model.Order, payment model.Payment) (*time.Time, error) {
+	switch payment.PaymentStatus {
+	case model.PaymentStatusSettlement, model.PaymentStatusPartialRefund, model.PaymentStatusRefund:
+	default:
+		return nil, pkg.ErrPaymentNotSettled
+	}
+
+	statusHistories, err := statusHistoryRepository.FindByIdOrder(order.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, statusHistory := range *statusHistories {
+		if statusHistory.ToStatus == model.OrderStatusPaid {
+			paidAt := statusHistory.CreatedAt
+
+			return &paidAt, nil
+		}
+	}
+
+	return &payment.UpdatedAt, nil
+}
+
+// renterShareOf is the part of amount splitPayment gives the renter.
+func renterShareOf(amount float32, subtotals map[string]float32, renterId string) float32 {
+	if amount <= 0 {
+		return 0
+	}
+
+	for _, share := range splitPayment(amount, subtotals, 0) {
+		if share.RenterId == renterId {
+			return share.Amount
+		}
+	}
+
+	return 0
+}
+
+func NewInvoiceUsecase(unitOfWork repository.UnitOfWork, policy InvoicePolicy) InvoiceUsecase {
+	return invoiceUsecase{
+		unitOfWork: unitOfWork,
+		policy:     policy,
+	}
+}
@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var invoiceUsecaseTest = NewInvoiceUsecase(&pkg.UnitOfWork, InvoicePolicy{TaxName: "PPN", TaxPercent: 11})
+
+func TestInvoiceUsecase_GenerateInvoice(t *testing.T) {
+	customerId := "0a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d"
+	orderId := "1b2c3d4e-5f6a-4b7c-9d8e-0f1a2b3c4d5e"
+	paymentId := "2c3d4e5f-6a7b-4c8d-8e9f-1a2b3c4d5e6f"
+	startAt := time.Date(2022, 11, 20, 8, 0, 0, 0, time.UTC)
+
+	firstRenter := &model.Renter{
+		ID:          "a1b2c3d4-5e6f-4a7b-8c9d-0e1f2a3b4c5d",
+		RentName:    "Twins' Brother Bike Rental",
+		RentAddress: "Jl. Malioboro",
+	}
+	secondRenter := &model.Renter{
+		ID:       "b2c3d4e5-6f7a-4b8c-9d0e-1f2a3b4c5d6e",
+		RentName: "Kaliurang Bikes",
+	}
+
+	// a voucher took 15000 off the two bikes, the first bike also holds a deposit
+	pkg.OrderRepository.Mock.On("FindById", orderId).Return(&model.Order{
+		ID:           orderId,
+		UserId:       customerId,
+		PaymentId:    paymentId,
+		TotalPayment: 135000,
+		Discount:     15000,
+		Deposit:      50000,
+		TotalHour:    5,
+		StartAt:      startAt,
+		EndAt:        startAt.Add(5 * time.Hour),
+		Status:       model.OrderStatusPaid,
+	}, nil)
+	pkg.OrderDetailRepository.Mock.On("FindByIdOrder", orderId).Return(&[]model.OrderDetail{
+		{OrderId: orderId, Hours: 5, Subtotal: 75000, Deposit: 50000, Bike: &model.Bike{Name: "Sample BMX Bike", RenterId: firstRenter.ID, Category: model.Category{Name: "BMX"}}},
+		{OrderId: orderId, Hours: 5, Subtotal: 75000, Bike: &model.Bike{Name: "Sample Road Bike", RenterId: secondRenter.ID}},
+	}, nil)
+	pkg.PaymentRepository.Mock.On("FindById", paymentId).Return(&model.Payment{ID: paymentId, OrderId: orderId, Amount: 185000, Deposit: 50000, PaymentStatus: model.PaymentStatusSettlement, PaymentType: "bank_transfer"}, nil)
+	pkg.UserRepository.Mock.On("FindById", customerId).Return(&model.User{ID: customerId, Fullname: "Arvin Paundra", Email: "arvin@mail.com"}, nil)
+	pkg.RenterRepository.Mock.On("FindById", firstRenter.ID).Return(firstRenter, nil)
+	pkg.RenterRepository.Mock.On("FindById", secondRenter.ID).Return(secondRenter, nil)
+
+	// every renter takes the next number of its own sequence
+	pkg.InvoiceRepository.Mock.On("FindByIdOrder", orderId).Return(&[]model.Invoice{}, nil)
+	pkg.InvoiceRepository.Mock.On("FindLastByIdRenterForUpdate", firstRenter.ID).Return(&model.Invoice{RenterId: firstRenter.ID, Sequence: 41}, nil)
+	pkg.InvoiceRepository.Mock.On("FindLastByIdRenterForUpdate", secondRenter.ID).Return((*model.Invoice)(nil), pkg.ErrRecordNotFound)
+	pkg.InvoiceRepository.Mock.On("Create", mock.MatchedBy(func(invoice model.Invoice) bool {
+		return invoice.OrderId == orderId && invoice.RenterId == firstRenter.ID && invoice.Sequence == 42 && invoice.Number == "INV-A1B2C3D4-00042"
+	})).Return(nil)
+	pkg.InvoiceRepository.Mock.On("Create", mock.MatchedBy(func(invoice model.Invoice) bool {
+		return invoice.OrderId == orderId && invoice.RenterId == secondRenter.ID && invoice.Sequence == 1 && invoice.Number == "INV-B2C3D4E5-00001"
+	})).Return(nil)
+
+	file, err := invoiceUsecaseTest.GenerateInvoice(orderId, customerId)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	// the customer gets the invoices of both renters
+	assert.Contains(t, string(file), "/Count 2 >>")
+	assert.Contains(t, string(file), "(INV-A1B2C3D4-00042) Tj")
+	assert.Contains(t, string(file), "(INV-B2C3D4E5-00001) Tj")
+	assert.Contains(t, string(file), "(Sample BMX Bike \\(BMX\\)) Tj")
+	assert.Contains(t, string(file), "(-Rp 7.500) Tj")
+	assert.Contains(t, string(file), "(Security deposit Sample BMX Bike) Tj")
+
+	// the first renter bills 67500 of rent, which includes 6689 of tax, and the deposit
+	assert.Contains(t, string(file), "(Rp 117.500) Tj")
+	assert.Contains(t, string(file), "(Rp 6.689) Tj")
+	assert.Contains(t, string(file), "(Rp 67.500) Tj")
+	assert.NotContains(t, string(file), "(RECEIPT) Tj")
+}
+
+func TestInvoiceUsecase_GenerateReceipt(t *testing.T) {
+	renterUserId := "3d4e5f6a-7b8c-4d9e-9f0a-2b3c4d5e6f7a"
+	orderId := "4e5f6a7b-8c9d-4e0f-8a1b-3c4d5e6f7a8b"
+	paymentId := "5f6a7b8c-9d0e-4f1a-9b2c-4d5e6f7a8b9c"
+	startAt := time.Date(2022, 11, 20, 8, 0, 0, 0, time.UTC)
+	paidAt := time.Date(2022, 11, 19, 10, 30, 0, 0, time.UTC)
+
+	renter := &model.Renter{
+		ID:       "c3d4e5f6-7a8b-4c9d-8e0f-2a3b4c5d6e7f",
+		UserId:   renterUserId,
+		RentName: "Twins' Brother Bike Rental",
+	}
+
+	// the order was extended from 5 to 8 hours
+	pkg.OrderRepository.Mock.On("FindById", orderId).Return(&model.Order{
+		ID:           orderId,
+		UserId:       "6a7b8c9d-0e1f-4a2b-8c3d-5e6f7a8b9c0d",
+		PaymentId:    paymentId,
+		TotalPayment: 120000,
+		TotalHour:    8,
+		StartAt:      startAt,
+		EndAt:        startAt.Add(8 * time.Hour),
+		Status:       model.OrderStatusReturned,
+	}, nil)
+	pkg.OrderDetailRepository.Mock.On("FindByIdOrder", orderId).Return(&[]model.OrderDetail{
+		{OrderId: orderId, Hours: 5, Subtotal: 75000, Bike: &model.Bike{Name: "Sample BMX Bike", RenterId: renter.ID}},
+	}, nil)
+	pkg.RenterRepository.Mock.On("FindByIdUser", renterUserId).Return(renter, nil)
+	pkg.RenterRepository.Mock.On("FindById", renter.ID).Return(renter, nil)
+	pkg.PaymentRepository.Mock.On("FindById", paymentId).Return(&model.Payment{ID: paymentId, OrderId: orderId, Amount: 75000, PaymentStatus: model.PaymentStatusPartialRefund, PaymentType: "bank_transfer"}, nil)
+	pkg.OrderStatusHistoryRepository.Mock.On("FindByIdOrder", orderId).Return(&[]model.OrderStatusHistory{
+		{OrderId: orderId, ToStatus: model.OrderStatusPendingPayment, CreatedAt: paidAt.Add(-time.Hour)},
+		{OrderId: orderId, FromStatus: model.OrderStatusPendingPayment, ToStatus: model.OrderStatusPaid, CreatedAt: paidAt},
+	}, nil)
+	pkg.UserRepository.Mock.On("FindById", "6a7b8c9d-0e1f-4a2b-8c3d-5e6f7a8b9c0d").Return(&model.User{Fullname: "Arvin Paundra"}, nil)
+
+	// the invoice was numbered when it was first downloaded
+	pkg.InvoiceRepository.Mock.On("FindByIdOrder", orderId).Return(&[]model.Invoice{
+		{OrderId: orderId, RenterId: renter.ID, Sequence: 7, Number: "INV-C3D4E5F6-00007", CreatedAt: paidAt},
+	}, nil)
+
+	file, err := invoiceUsecaseTest.GenerateReceipt(orderId, renterUserId)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	assert.Contains(t, string(file), "(RECEIPT) Tj")
+	assert.Contains(t, string(file), "(INV-C3D4E5F6-00007) Tj")
+	assert.Contains(t, string(file), "(Rent extension) Tj")
+	assert.Contains(t, string(file), "(Rp 45.000) Tj")
+	assert.Contains(t, string(file), "(PAID 19 Nov 2022 10:30 via bank transfer) Tj")
+	pkg.InvoiceRepository.Mock.AssertNotCalled(t, "Create", mock.MatchedBy(func(invoice model.Invoice) bool {
+		return invoice.OrderId == orderId
+	}))
+}
+
+func TestInvoiceUsecase_GenerateReceiptNotSettled(t *testing.T) {
+	customerId := "7b8c9d0e-1f2a-4b3c-9d4e-6f7a8b9c0d1e"
+	orderId := "8c9d0e1f-2a3b-4c4d-8e5f-7a8b9c0d1e2f"
+	paymentId := "9d0e1f2a-3b4c-4d5e-9f6a-8b9c0d1e2f3a"
+
+	pkg.OrderRepository.Mock.On("FindById", orderId).Return(&model.Order{ID: orderId, UserId: customerId, PaymentId: paymentId, Status: model.OrderStatusPendingPayment}, nil)
+	pkg.OrderDetailRepository.Mock.On("FindByIdOrder", orderId).Return(&[]model.OrderDetail{
+		{OrderId: orderId, Subtotal: 75000, Bike: &model.Bike{RenterId: "d4e5f6a7-8b9c-4d0e-9f1a-3b4c5d6e7f8a"}},
+	}, nil)
+	pkg.PaymentRepository.Mock.On("FindById", paymentId).Return(&model.Payment{ID: paymentId, PaymentStatus: model.PaymentStatusPending}, nil)
+
+	file, err := invoiceUsecaseTest.GenerateReceipt(orderId, customerId)
+
+	assert.Nil(t, file)
+	assert.Equal(t, pkg.ErrPaymentNotSettled, err)
+}
+
+func TestInvoiceUsecase_GenerateInvoiceForbidden(t *testing.T) {
+	renterUserId := "0e1f2a3b-4c5d-4e6f-8a7b-9c0d1e2f3a4b"
+	orderId := "1f2a3b4c-5d6e-4f7a-9b8c-0d1e2f3a4b5c"
+
+	pkg.OrderRepository.Mock.On("FindById", orderId).Return(&model.Order{ID: orderId, UserId: "2a3b4c5d-6e7f-4a8b-8c9d-1e2f3a4b5c6d"}, nil)
+	pkg.OrderDetailRepository.Mock.On("FindByIdOrder", orderId).Return(&[]model.OrderDetail{
+		{OrderId: orderId, Subtotal: 75000, Bike: &model.Bike{RenterId: "e5f6a7b8-9c0d-4e1f-8a2b-4c5d6e7f8a9b"}},
+	}, nil)
+
+	// a renter of other bikes
+	pkg.RenterRepository.Mock.On("FindByIdUser", renterUserId).Return(&model.Renter{ID: "f6a7b8c9-0d1e-4f2a-9b3c-5d6e7f8a9b0c", UserId: renterUserId}, nil)
+
+	file, err := invoiceUsecaseTest.GenerateInvoice(orderId, renterUserId)
+
+	assert.Nil(t, file)
+	assert.Equal(t, pkg.ErrForbidden, err)
+}
+
+func TestInvoiceNumber(t *testing.T) {
+	assert.Equal(t, "INV-A1B2C3D4-00042", invoiceNumber("a1b2c3d4-5e6f-4a7b-8c9d-0e1f2a3b4c5d", 42))
+	assert.Equal(t, "INV-RID-123456", invoiceNumber("rid", 123456))
+}
@@ -0,0 +1,65 @@
+package usecasemock
+
+import (
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type AuthUsecaseMock struct {
+	Mock mock.Mock
+}
+
+func (u *AuthUsecaseMock) Login(email string, password string, userAgent string, ipAddress string) (*dto.AuthTokenDTO, error) {
+	ret := u.Mock.Called(email, password, userAgent, ipAddress)
+
+	return ret.Get(0).(*dto.AuthTokenDTO), ret.Error(1)
+}
+
+func (u *AuthUsecaseMock) RefreshToken(refreshToken string, userAgent string, ipAddress string) (*dto.AuthTokenDTO, error) {
+	ret := u.Mock.Called(refreshToken, userAgent, ipAddress)
+
+	return ret.Get(0).(*dto.AuthTokenDTO), ret.Error(1)
+}
+
+func (u *AuthUsecaseMock) FindAllSessions(userId string, currentSessionId string) (*[]model.Session, error) {
+	ret := u.Mock.Called(userId, currentSessionId)
+
+	return ret.Get(0).(*[]model.Session), ret.Error(1)
+}
+
+func (u *AuthUsecaseMock) FindAllLoginAttempts(email string, ipAddress string) (*[]model.LoginAttempt, error) {
+	ret := u.Mock.Called(email, ipAddress)
+
+	return ret.Get(0).(*[]model.LoginAttempt), ret.Error(1)
+}
+
+func (u *AuthUsecaseMock) RevokeSession(userId string, sessionId string) error {
+	ret := u.Mock.Called(userId, sessionId)
+
+	return ret.Error(0)
+}
+
+func (u *AuthUsecaseMock) SendEmailVerification(userId string) error {
+	ret := u.Mock.Called(userId)
+
+	return ret.Error(0)
+}
+
+func (u *AuthUsecaseMock) VerifyEmail(token string) error {
+	ret := u.Mock.Called(token)
+
+	return ret.Error(0)
+}
+
+func (u *AuthUsecaseMock) ForgotPassword(email string) error {
+	ret := u.Mock.Called(email)
+
+	return ret.Error(0)
+}
+
+func (u *AuthUsecaseMock) ResetPassword(token string, password string) error {
+	ret := u.Mock.Called(token, password)
+
+	return ret.Error(0)
+}
@@ -1,8 +1,11 @@
 package usecasemock
 
 import (
+	"time"
+
 	"github.com/arvinpaundra/go-rent-bike/internal/dto"
 	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/pricing"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -10,8 +13,8 @@ type BikeUsecaseMock struct {
 	Mock mock.Mock
 }
 
-func (u *BikeUsecaseMock) CreateNewBike(bikeDTO dto.BikeDTO) error {
-	ret := u.Mock.Called(bikeDTO)
+func (u *BikeUsecaseMock) CreateNewBike(actorId string, bikeDTO dto.BikeDTO) error {
+	ret := u.Mock.Called(actorId, bikeDTO)
 
 	return ret.Error(0)
 }
@@ -57,3 +60,15 @@ func (u *BikeUsecaseMock) DeleteBike(bikeId string) error {
 
 	return ret.Error(0)
 }
+
+func (u *BikeUsecaseMock) CheckBikeAvailability(bikeId string, startAt time.Time, endAt time.Time) (*dto.BikeAvailabilityDTO, error) {
+	ret := u.Mock.Called(bikeId, startAt, endAt)
+
+	return ret.Get(0).(*dto.BikeAvailabilityDTO), ret.Error(1)
+}
+
+func (u *BikeUsecaseMock) QuoteBike(bikeId string, startAt time.Time, endAt time.Time) (*pricing.Quote, error) {
+	ret := u.Mock.Called(bikeId, startAt, endAt)
+
+	return ret.Get(0).(*pricing.Quote), ret.Error(1)
+}
@@ -0,0 +1,21 @@
+package usecasemock
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+type InvoiceUsecaseMock struct {
+	Mock mock.Mock
+}
+
+func (u *InvoiceUsecaseMock) GenerateInvoice(orderId string, actorId string) ([]byte, error) {
+	ret := u.Mock.Called(orderId, actorId)
+
+	return ret.Get(0).([]byte), ret.Error(1)
+}
+
+func (u *InvoiceUsecaseMock) GenerateReceipt(orderId string, actorId string) ([]byte, error) {
+	ret := u.Mock.Called(orderId, actorId)
+
+	return ret.Get(0).([]byte), ret.Error(1)
+}
@@ -1,7 +1,10 @@
 package usecasemock
 
 import (
+	"time"
+
 	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -9,14 +12,56 @@ type OrderUsecaseMock struct {
 	Mock mock.Mock
 }
 
-func (u *OrderUsecaseMock) CreateOrder(orderDTO dto.OrderDTO) (map[string]interface{}, error) {
-	ret := u.Mock.Called(orderDTO)
+func (u *OrderUsecaseMock) CreateOrder(customerId string, orderDTO dto.OrderDTO) (map[string]interface{}, error) {
+	ret := u.Mock.Called(customerId, orderDTO)
+
+	return ret.Get(0).(map[string]interface{}), ret.Error(1)
+}
+
+func (u *OrderUsecaseMock) PickupBike(orderId string, actorId string) error {
+	ret := u.Mock.Called(orderId, actorId)
+
+	return ret.Error(0)
+}
+
+func (u *OrderUsecaseMock) UpdateRentStatus(orderId string, actorId string) (map[string]interface{}, error) {
+	ret := u.Mock.Called(orderId, actorId)
 
 	return ret.Get(0).(map[string]interface{}), ret.Error(1)
 }
 
-func (u *OrderUsecaseMock) UpdateRentStatus(orderId string) error {
+func (u *OrderUsecaseMock) FindOrderStatusHistories(orderId string) (*[]model.OrderStatusHistory, error) {
 	ret := u.Mock.Called(orderId)
 
+	return ret.Get(0).(*[]model.OrderStatusHistory), ret.Error(1)
+}
+
+func (u *OrderUsecaseMock) CancelOrder(orderId string, actorId string) (map[string]interface{}, error) {
+	ret := u.Mock.Called(orderId, actorId)
+
+	return ret.Get(0).(map[string]interface{}), ret.Error(1)
+}
+
+func (u *OrderUsecaseMock) SettleDeposit(orderId string, actorId string, depositDTO dto.OrderDepositDTO) (map[string]interface{}, error) {
+	ret := u.Mock.Called(orderId, actorId, depositDTO)
+
+	return ret.Get(0).(map[string]interface{}), ret.Error(1)
+}
+
+func (u *OrderUsecaseMock) ExtendOrder(orderId string, extensionDTO dto.OrderExtensionDTO) (map[string]interface{}, error) {
+	ret := u.Mock.Called(orderId, extensionDTO)
+
+	return ret.Get(0).(map[string]interface{}), ret.Error(1)
+}
+
+func (u *OrderUsecaseMock) ExpireUnpaidOrders(now time.Time) (int, error) {
+	ret := u.Mock.Called(now)
+
+	return ret.Int(0), ret.Error(1)
+}
+
+func (u *OrderUsecaseMock) OverrideOrderStatus(orderId string, actorId string, overrideDTO dto.OrderOverrideDTO) error {
+	ret := u.Mock.Called(orderId, actorId, overrideDTO)
+
 	return ret.Error(0)
 }
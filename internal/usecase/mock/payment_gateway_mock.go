@@ -1,13 +1,30 @@
 package usecasemock
 
-import "github.com/stretchr/testify/mock"
+import (
+	"time"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/stretchr/testify/mock"
+)
 
 type PaymentGatewayMock struct {
 	Mock mock.Mock
 }
 
-func (u *PaymentGatewayMock) MidtransNotification(orderId string) error {
+func (u *PaymentGatewayMock) MidtransNotification(notification dto.MidtransNotificationDTO) error {
+	ret := u.Mock.Called(notification)
+
+	return ret.Error(0)
+}
+
+func (u *PaymentGatewayMock) SyncTransaction(orderId string) error {
 	ret := u.Mock.Called(orderId)
 
 	return ret.Error(0)
 }
+
+func (u *PaymentGatewayMock) ReconcilePayments(now time.Time) (*dto.ReconciliationReport, error) {
+	ret := u.Mock.Called(now)
+
+	return ret.Get(0).(*dto.ReconciliationReport), ret.Error(1)
+}
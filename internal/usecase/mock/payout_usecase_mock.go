@@ -0,0 +1,53 @@
+package usecasemock
+
+import (
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type PayoutUsecaseMock struct {
+	Mock mock.Mock
+}
+
+func (u *PayoutUsecaseMock) CreatePayoutBatch() (*model.PayoutBatch, error) {
+	ret := u.Mock.Called()
+
+	return ret.Get(0).(*model.PayoutBatch), ret.Error(1)
+}
+
+func (u *PayoutUsecaseMock) FindAllPayoutBatches() (*[]model.PayoutBatch, error) {
+	ret := u.Mock.Called()
+
+	return ret.Get(0).(*[]model.PayoutBatch), ret.Error(1)
+}
+
+func (u *PayoutUsecaseMock) FindPayoutBatchById(batchId string) (*model.PayoutBatch, error) {
+	ret := u.Mock.Called(batchId)
+
+	return ret.Get(0).(*model.PayoutBatch), ret.Error(1)
+}
+
+func (u *PayoutUsecaseMock) ExportPayoutBatch(batchId string) ([]byte, error) {
+	ret := u.Mock.Called(batchId)
+
+	return ret.Get(0).([]byte), ret.Error(1)
+}
+
+func (u *PayoutUsecaseMock) UpdatePayoutStatus(batchId string, payoutId string, payoutStatusDTO dto.PayoutStatusDTO) (*model.Payout, error) {
+	ret := u.Mock.Called(batchId, payoutId, payoutStatusDTO)
+
+	return ret.Get(0).(*model.Payout), ret.Error(1)
+}
+
+func (u *PayoutUsecaseMock) FindRenterEarnings(actorId string, renterId string) (map[string]interface{}, error) {
+	ret := u.Mock.Called(actorId, renterId)
+
+	return ret.Get(0).(map[string]interface{}), ret.Error(1)
+}
+
+func (u *PayoutUsecaseMock) FindAllRenterPayouts(actorId string, renterId string) (*[]model.Payout, error) {
+	ret := u.Mock.Called(actorId, renterId)
+
+	return ret.Get(0).(*[]model.Payout), ret.Error(1)
+}
@@ -0,0 +1,23 @@
+package usecasemock
+
+import (
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type RefundUsecaseMock struct {
+	Mock mock.Mock
+}
+
+func (u *RefundUsecaseMock) CreateRefund(actorId string, actorRole string, paymentId string, refundDTO dto.RefundDTO) (*model.Refund, error) {
+	ret := u.Mock.Called(actorId, actorRole, paymentId, refundDTO)
+
+	return ret.Get(0).(*model.Refund), ret.Error(1)
+}
+
+func (u *RefundUsecaseMock) FindAllRefunds(actorId string, actorRole string, paymentId string) (*[]model.Refund, error) {
+	ret := u.Mock.Called(actorId, actorRole, paymentId)
+
+	return ret.Get(0).(*[]model.Refund), ret.Error(1)
+}
@@ -40,6 +40,24 @@ func (r *RenterUsecaseMock) FindAllRenterReports(renterId string) (*[]model.Repo
 	return ret.Get(0).(*[]model.Report), ret.Error(1)
 }
 
+func (r *RenterUsecaseMock) CreatePricingRule(renterId string, pricingRuleDTO dto.PricingRuleDTO) error {
+	ret := r.Mock.Called(renterId, pricingRuleDTO)
+
+	return ret.Error(0)
+}
+
+func (r *RenterUsecaseMock) FindAllPricingRules(renterId string) (*[]model.PricingRule, error) {
+	ret := r.Mock.Called(renterId)
+
+	return ret.Get(0).(*[]model.PricingRule), ret.Error(1)
+}
+
+func (r *RenterUsecaseMock) DeletePricingRule(renterId string, pricingRuleId string) error {
+	ret := r.Mock.Called(renterId, pricingRuleId)
+
+	return ret.Error(0)
+}
+
 func (r *RenterUsecaseMock) UpdateRenter(renterId string, renterDTO dto.RenterDTO) error {
 	ret := r.Mock.Called(renterId, renterDTO)
 
@@ -51,3 +69,27 @@ func (r *RenterUsecaseMock) DeleteRenter(renterId string) error {
 
 	return ret.Error(0)
 }
+
+func (r *RenterUsecaseMock) SuspendRenter(renterId string, suspensionDTO dto.RenterSuspensionDTO) error {
+	ret := r.Mock.Called(renterId, suspensionDTO)
+
+	return ret.Error(0)
+}
+
+func (r *RenterUsecaseMock) UnsuspendRenter(renterId string) error {
+	ret := r.Mock.Called(renterId)
+
+	return ret.Error(0)
+}
+
+func (r *RenterUsecaseMock) FindAllReports(status string) (*[]model.Report, error) {
+	ret := r.Mock.Called(status)
+
+	return ret.Get(0).(*[]model.Report), ret.Error(1)
+}
+
+func (r *RenterUsecaseMock) ResolveReport(reportId string, actorId string, resolutionDTO dto.ReportResolutionDTO) error {
+	ret := r.Mock.Called(reportId, actorId, resolutionDTO)
+
+	return ret.Error(0)
+}
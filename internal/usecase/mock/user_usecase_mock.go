@@ -16,10 +16,10 @@ func (u *UserUsecaseMock) RegisterUser(userDTO dto.UserDTO) error {
 	return ret.Error(0)
 }
 
-func (u *UserUsecaseMock) LoginUser(email string, password string) (string, error) {
-	ret := u.Mock.Called(email, password)
+func (u *UserUsecaseMock) CreateAdmin(userDTO dto.UserDTO) error {
+	ret := u.Mock.Called(userDTO)
 
-	return ret.Get(0).(string), ret.Error(1)
+	return ret.Error(0)
 }
 
 func (u *UserUsecaseMock) FindAllUsers() (*[]model.User, error) {
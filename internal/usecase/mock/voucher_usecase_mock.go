@@ -0,0 +1,71 @@
+package usecasemock
+
+import (
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type VoucherUsecaseMock struct {
+	Mock mock.Mock
+}
+
+func (u *VoucherUsecaseMock) CreateVoucher(actorId string, voucherDTO dto.VoucherDTO) (*model.Voucher, error) {
+	ret := u.Mock.Called(actorId, voucherDTO)
+
+	return ret.Get(0).(*model.Voucher), ret.Error(1)
+}
+
+func (u *VoucherUsecaseMock) FindAllVouchers(actorId string) (*[]model.Voucher, error) {
+	ret := u.Mock.Called(actorId)
+
+	return ret.Get(0).(*[]model.Voucher), ret.Error(1)
+}
+
+func (u *VoucherUsecaseMock) FindByIdVoucher(actorId string, voucherId string) (*model.Voucher, error) {
+	ret := u.Mock.Called(actorId, voucherId)
+
+	return ret.Get(0).(*model.Voucher), ret.Error(1)
+}
+
+func (u *VoucherUsecaseMock) UpdateVoucher(actorId string, voucherId string, voucherDTO dto.VoucherDTO) error {
+	ret := u.Mock.Called(actorId, voucherId, voucherDTO)
+
+	return ret.Error(0)
+}
+
+func (u *VoucherUsecaseMock) DeleteVoucher(actorId string, voucherId string) error {
+	ret := u.Mock.Called(actorId, voucherId)
+
+	return ret.Error(0)
+}
+
+func (u *VoucherUsecaseMock) CreatePlatformVoucher(voucherDTO dto.VoucherDTO) (*model.Voucher, error) {
+	ret := u.Mock.Called(voucherDTO)
+
+	return ret.Get(0).(*model.Voucher), ret.Error(1)
+}
+
+func (u *VoucherUsecaseMock) FindAllPlatformVouchers() (*[]model.Voucher, error) {
+	ret := u.Mock.Called()
+
+	return ret.Get(0).(*[]model.Voucher), ret.Error(1)
+}
+
+func (u *VoucherUsecaseMock) FindByIdPlatformVoucher(voucherId string) (*model.Voucher, error) {
+	ret := u.Mock.Called(voucherId)
+
+	return ret.Get(0).(*model.Voucher), ret.Error(1)
+}
+
+func (u *VoucherUsecaseMock) UpdatePlatformVoucher(voucherId string, voucherDTO dto.VoucherDTO) error {
+	ret := u.Mock.Called(voucherId, voucherDTO)
+
+	return ret.Error(0)
+}
+
+func (u *VoucherUsecaseMock) DeletePlatformVoucher(voucherId string) error {
+	ret := u.Mock.Called(voucherId)
+
+	return ret.Error(0)
+}
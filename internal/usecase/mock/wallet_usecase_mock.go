@@ -0,0 +1,29 @@
+package usecasemock
+
+import (
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type WalletUsecaseMock struct {
+	Mock mock.Mock
+}
+
+func (u *WalletUsecaseMock) FindWallet(userId string) (*model.Wallet, error) {
+	ret := u.Mock.Called(userId)
+
+	return ret.Get(0).(*model.Wallet), ret.Error(1)
+}
+
+func (u *WalletUsecaseMock) FindAllWalletTransactions(userId string) (*[]model.WalletTransaction, error) {
+	ret := u.Mock.Called(userId)
+
+	return ret.Get(0).(*[]model.WalletTransaction), ret.Error(1)
+}
+
+func (u *WalletUsecaseMock) TopUpWallet(userId string, topUpDTO dto.WalletTopUpDTO) (map[string]interface{}, error) {
+	ret := u.Mock.Called(userId, topUpDTO)
+
+	return ret.Get(0).(map[string]interface{}), ret.Error(1)
+}
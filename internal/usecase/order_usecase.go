@@ -1,120 +1,276 @@
 package usecase
 
 import (
-	"errors"
+	"fmt"
+	"math"
 	"time"
 
 	"github.com/arvinpaundra/go-rent-bike/internal/dto"
-	pgMidtrans "github.com/arvinpaundra/go-rent-bike/internal/midtrans"
 	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/payment"
+	"github.com/arvinpaundra/go-rent-bike/internal/pricing"
 	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
 	"github.com/google/uuid"
 	"github.com/midtrans/midtrans-go"
 )
 
 type OrderUsecase interface {
-	CreateOrder(orderDTO dto.OrderDTO) (map[string]interface{}, error)
-	UpdateRentStatus(orderId string) error
+	CreateOrder(customerId string, orderDTO dto.OrderDTO) (map[string]interface{}, error)
+	PickupBike(orderId string, actorId string) error
+	UpdateRentStatus(orderId string, actorId string) (map[string]interface{}, error)
+	CancelOrder(orderId string, actorId string) (map[string]interface{}, error)
+	SettleDeposit(orderId string, actorId string, depositDTO dto.OrderDepositDTO) (map[string]interface{}, error)
+	ExtendOrder(orderId string, extensionDTO dto.OrderExtensionDTO) (map[string]interface{}, error)
+	ExpireUnpaidOrders(now time.Time) (int, error)
+	FindOrderStatusHistories(orderId string) (*[]model.OrderStatusHistory, error)
+	OverrideOrderStatus(orderId string, actorId string, overrideDTO dto.OrderOverrideDTO) error
 }
 
-type orderUsecase struct {
-	orderRepository          repository.OrderRepository
-	paymentGatewayRepository pgMidtrans.PaymentGateway
-	orderDetailRepository    repository.OrderDetailRepository
-	userRepository           repository.UserRepository
-	bikeRepository           repository.BikeRepository
-	paymentRepository        repository.PaymentRepository
-	historyRepository        repository.HistoryRepository
+// OrderPolicy holds the configurable rules of the order lifecycle.
+type OrderPolicy struct {
+	// PaymentTTL is how long an order may wait for its payment before it expires
+	PaymentTTL time.Duration
+	// LateReturnGrace is how long a bike may be returned after the end of the rent without a late fee
+	LateReturnGrace time.Duration
+	// CommissionPercent is the part of an order paid from a wallet the platform keeps
+	CommissionPercent float32
 }
 
-func (u orderUsecase) CreateOrder(orderDTO dto.OrderDTO) (map[string]interface{}, error) {
-	u.paymentGatewayRepository.InitializeClientMidtrans()
+type orderUsecase struct {
+	unitOfWork                   repository.UnitOfWork
+	orderRepository              repository.OrderRepository
+	paymentProvider              payment.PaymentProvider
+	orderDetailRepository        repository.OrderDetailRepository
+	userRepository               repository.UserRepository
+	bikeRepository               repository.BikeRepository
+	paymentRepository            repository.PaymentRepository
+	historyRepository            repository.HistoryRepository
+	orderStatusHistoryRepository repository.OrderStatusHistoryRepository
+	policy                       OrderPolicy
+	pricingEngine                pricing.Engine
+}
 
+// CreateOrder books the bikes for the customer, the customer is the user of the token and never comes from
+// the request, so nobody orders, and later cancels or pays, on behalf of somebody else.
+func (u orderUsecase) CreateOrder(customerId string, orderDTO dto.OrderDTO) (map[string]interface{}, error) {
 	var err error
 
 	// check if customer is exist
 	var customer *model.User
-	customer, err = u.userRepository.FindById(orderDTO.CustomerId)
+	customer, err = u.userRepository.FindById(customerId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !customer.IsEmailVerified() {
+		return nil, pkg.ErrEmailNotVerified
+	}
+
+	if err := validateRentWindow(orderDTO.StartAt, orderDTO.EndAt); err != nil {
+		return nil, err
+	}
+
+	// without bikes there is nothing to lock, no locked bike would be missing and an empty order would be placed
+	if len(orderDTO.BikeIds) == 0 {
+		return nil, pkg.ErrNoBikeChosen
+	}
+
+	// every write of the order runs in one transaction, so a failure at any step leaves nothing behind
+	var placed *placedOrder
+
+	err = u.unitOfWork.WithTx(func(repos repository.Repositories) error {
+		var err error
+
+		placed, err = u.placeOrder(repos, *customer, orderDTO)
+
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	// a wallet order is paid already, it has no payment link
+	if placed.snapReq == nil {
+		return orderResponse(placed.order, placed.payment), nil
+	}
+
+	// the payment gateway is called once the order is committed, so the bikes are not locked while it answers
+	if err := u.createPaymentLink(&placed.payment, *placed.snapReq); err != nil {
+		// an order without payment link can never be paid, it gives its bikes back right away
+		if abandonErr := u.abandonOrder(placed.order, placed.payment.ID); abandonErr != nil {
+			return nil, fmt.Errorf("%w, and the order was not canceled: %v", err, abandonErr)
+		}
+
+		return nil, err
+	}
+
+	return orderResponse(placed.order, placed.payment), nil
+}
+
+// placedOrder is an order written by placeOrder, snapReq asks the payment gateway for its payment link
+// and is nil for an order paid from the wallet.
+type placedOrder struct {
+	order   model.Order
+	payment model.Payment
+	snapReq *dto.PaymentGateway
+}
+
+func (u orderUsecase) placeOrder(repos repository.Repositories, customer model.User, orderDTO dto.OrderDTO) (*placedOrder, error) {
+	totalHour := countRentHours(orderDTO.StartAt, orderDTO.EndAt)
+	bikeIds := uniqueIds(orderDTO.BikeIds)
+
+	// lock the chosen bikes until the transaction ends, so a concurrent order for the same bike
+	// waits here and only sees the schedule after this order is committed or rolled back
+	lockedBikes, err := repos.Bike.FindByIdsForUpdate(bikeIds)
 
 	if err != nil {
 		return nil, err
+	} else if len(*lockedBikes) != len(bikeIds) {
+		return nil, pkg.ErrRecordNotFound
 	}
 
 	// check the bikes that customers choose
-	// if the each bike are exist, append to slice bikes
+	// if the each bike are exist and free during the requested window, append to slice bikes
 	bikes := []model.Bike{}
-	for i := range orderDTO.BikeIds {
-		bike, err := u.bikeRepository.FindById(orderDTO.BikeIds[i])
+	for i := range bikeIds {
+		bike, err := repos.Bike.FindById(bikeIds[i])
 
 		if err != nil {
 			return nil, err
 		} else if bike.IsAvailable == "0" {
-			return nil, errors.New("bike not available")
+			return nil, pkg.ErrBikeNotAvailable
 		}
 
-		bike.IsAvailable = "0"
-		bike.UpdatedAt = time.Now()
+		// the bikes of a suspended renter cannot be booked until an admin lifts the suspension
+		renter, err := repos.Renter.FindById(bike.RenterId)
+
+		if err != nil {
+			return nil, err
+		} else if renter.IsSuspended() {
+			return nil, pkg.ErrRenterSuspended
+		}
 
-		// update availability of each bike to "0", which is unavailable
-		if err := u.bikeRepository.Update(bike.ID, *bike); err != nil {
+		if err := checkBikeSchedule(repos.Bike, *bike, orderDTO.StartAt, orderDTO.EndAt); err != nil {
 			return nil, err
 		}
 
 		bikes = append(bikes, *bike)
 	}
 
-	// calculate total payments
+	// calculate total payments with the same quote customers see for each bike
 	var totalPayments float32
 
+	quotes := []pricing.Quote{}
+	for i := range bikes {
+		quote, err := quoteBike(u.pricingEngine, repos.PricingRule, bikes[i], orderDTO.StartAt, orderDTO.EndAt)
+
+		if err != nil {
+			return nil, err
+		}
+
+		totalPayments += quote.Total
+		quotes = append(quotes, quote)
+	}
+
+	// the voucher is optional, its discount is taken off the total before payment
+	var voucher *model.Voucher
+	var discount float32
+
+	if orderDTO.VoucherCode != "" {
+		voucher, discount, err = applyVoucher(repos, orderDTO.VoucherCode, customer.ID, bikes, quotes, time.Now())
+
+		if err != nil {
+			return nil, err
+		}
+
+		totalPayments -= discount
+	}
+
+	// the security deposit is charged with the rent, but kept apart from it until the bikes are returned
+	var deposit float32
 	for i := range bikes {
-		totalPayments += (bikes[i].PricePerHour * float32(orderDTO.TotalHour))
+		deposit += bikes[i].DepositAmount()
 	}
 
 	// initiate the payment, then create payment
+	orderId := uuid.NewString()
 	paymentId := uuid.NewString()
 	payment := model.Payment{
 		ID:            paymentId,
-		PaymentStatus: "pending",
+		OrderId:       orderId,
+		Kind:          model.PaymentKindRent,
+		Amount:        totalPayments + deposit,
+		Deposit:       deposit,
+		PaymentStatus: model.PaymentStatusPending,
 		PaymentType:   orderDTO.PaymentType,
 		CreatedAt:     time.Now(),
 		UpdatedAt:     time.Now(),
 	}
 
-	if err := u.paymentRepository.Create(payment); err != nil {
+	if err := repos.Payment.Create(payment); err != nil {
 		return nil, err
 	}
 
 	// initiate the order, then create order
-	orderId := uuid.NewString()
 	order := model.Order{
 		ID:           orderId,
-		UserId:       orderDTO.CustomerId,
+		UserId:       customer.ID,
 		PaymentId:    paymentId,
 		TotalPayment: totalPayments,
+		Discount:     discount,
+		Deposit:      deposit,
 		TotalQty:     len(bikes),
-		TotalHour:    orderDTO.TotalHour,
+		TotalHour:    totalHour,
+		StartAt:      orderDTO.StartAt,
+		EndAt:        orderDTO.EndAt,
+		Status:       model.OrderStatusPendingPayment,
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 	}
 
-	if err := u.orderRepository.Create(order); err != nil {
+	if deposit > 0 {
+		order.DepositStatus = model.DepositStatusHeld
+	}
+
+	if err := repos.Order.Create(order); err != nil {
 		return nil, err
 	}
 
+	if voucher != nil {
+		voucherUsage := model.VoucherUsage{
+			ID:        uuid.NewString(),
+			VoucherId: voucher.ID,
+			UserId:    customer.ID,
+			OrderId:   orderId,
+			Discount:  discount,
+			CreatedAt: time.Now(),
+		}
+
+		if err := repos.Voucher.CreateUsage(voucherUsage); err != nil {
+			return nil, err
+		}
+	}
+
 	// initiate the order detail with loop over the bikes above and append to slice bikesRented
 	// then create the order detail
 	bikesRented := []model.OrderDetail{}
 	for i := range bikes {
 		bike := model.OrderDetail{
-			ID:      uuid.NewString(),
-			OrderId: orderId,
-			BikeId:  bikes[i].ID,
+			ID:       uuid.NewString(),
+			OrderId:  orderId,
+			BikeId:   bikes[i].ID,
+			Hours:    totalHour,
+			Subtotal: quotes[i].Total,
+			Deposit:  bikes[i].DepositAmount(),
 		}
 
 		bikesRented = append(bikesRented, bike)
 	}
 
-	if err := u.orderDetailRepository.Create(bikesRented); err != nil {
+	if err := repos.OrderDetail.Create(bikesRented); err != nil {
 		return nil, err
 	}
 
@@ -122,51 +278,180 @@ func (u orderUsecase) CreateOrder(orderDTO dto.OrderDTO) (map[string]interface{}
 	history := model.History{
 		ID:         uuid.NewString(),
 		OrderId:    orderId,
-		RentStatus: "pending payment",
+		RentStatus: string(order.Status),
 		CreatedAt:  time.Now(),
 		UpdatedAt:  time.Now(),
 	}
 
-	if err := u.historyRepository.Create(history); err != nil {
+	if err := repos.History.Create(history); err != nil {
+		return nil, err
+	}
+
+	statusHistory := model.OrderStatusHistory{
+		ID:        uuid.NewString(),
+		OrderId:   orderId,
+		ToStatus:  order.Status,
+		Actor:     customer.ID,
+		CreatedAt: time.Now(),
+	}
+
+	if err := repos.OrderStatusHistory.Create(statusHistory); err != nil {
 		return nil, err
 	}
 
+	// a wallet order is paid from the balance in this transaction, it never waits for the payment gateway
+	if orderDTO.PaymentType == model.PaymentTypeWallet {
+		if err := u.payWithWallet(repos, &order, &payment); err != nil {
+			return nil, err
+		}
+
+		return &placedOrder{order: order, payment: payment}, nil
+	}
+
 	// set the item details to send to payment gateway
 	items := []midtrans.ItemDetails{}
 	for i := range bikes {
-		item := midtrans.ItemDetails{
-			ID:       bikes[i].ID,
-			Name:     bikes[i].Name,
-			Price:    int64(bikes[i].PricePerHour),
-			Qty:      int32(order.TotalHour),
-			Category: bikes[i].Category.Name,
-		}
+		items = append(items, quoteItems(bikes[i], quotes[i], "")...)
+	}
+
+	// the discount is its own negative item, so the items still add up to the gross amount
+	if voucher != nil {
+		items = append(items, midtrans.ItemDetails{
+			ID:    voucher.ID,
+			Name:  fmt.Sprintf("Voucher %s", voucher.Code),
+			Price: -int64(discount),
+			Qty:   1,
+		})
+	}
 
-		items = append(items, item)
+	for i := range bikesRented {
+		if bikesRented[i].Deposit > 0 {
+			items = append(items, midtrans.ItemDetails{
+				ID:    bikesRented[i].ID,
+				Name:  fmt.Sprintf("Deposit %s", bikes[i].Name),
+				Price: int64(bikesRented[i].Deposit),
+				Qty:   1,
+			})
+		}
 	}
 
 	// init the request body to send to payment gateway
 	snapReq := dto.PaymentGateway{
-		Email:    customer.Email,
-		Phone:    customer.Phone,
-		OrderId:  orderId,
-		GrossAmt: int64(totalPayments),
-		Items:    items,
+		Email:         customer.Email,
+		Phone:         customer.Phone,
+		OrderId:       orderId,
+		GrossAmt:      int64(payment.Amount),
+		Items:         items,
+		ExpiryStartAt: order.CreatedAt,
+		ExpiryMinutes: int64(u.policy.PaymentTTL / time.Minute),
 	}
 
-	// send request to payment gateway
-	snapUrl := u.paymentGatewayRepository.CreateUrlTransactionWithGateway(snapReq)
+	return &placedOrder{order: order, payment: payment, snapReq: &snapReq}, nil
+}
+
+// createPaymentLink asks the payment gateway for the link of a committed payment and saves it.
+func (u orderUsecase) createPaymentLink(payment *model.Payment, snapReq dto.PaymentGateway) error {
+	snapUrl, err := requestPaymentLink(u.paymentProvider, snapReq)
+
+	if err != nil {
+		return err
+	}
 
-	// save payments
 	payment.PaymentLink = snapUrl
-	if err := u.paymentRepository.Update(paymentId, payment); err != nil {
-		return nil, err
+	payment.UpdatedAt = time.Now()
+
+	return u.paymentRepository.Update(payment.ID, model.Payment{PaymentLink: payment.PaymentLink, UpdatedAt: payment.UpdatedAt})
+}
+
+// requestPaymentLink asks the payment gateway for the link of a payment, it is never called while rows are locked.
+func requestPaymentLink(paymentProvider payment.PaymentProvider, snapReq dto.PaymentGateway) (string, error) {
+	snapUrl, err := paymentProvider.CreateUrlTransactionWithGateway(snapReq)
+
+	if err != nil {
+		return "", paymentGatewayError(err)
+	}
+
+	if snapUrl == "" {
+		return "", pkg.ErrPaymentLinkNotCreated
+	}
+
+	return snapUrl, nil
+}
+
+// abandonOrder cancels a new order the payment gateway made no payment link for, unless it got paid meanwhile
+// through a link the gateway made before it failed to answer.
+func (u orderUsecase) abandonOrder(order model.Order, paymentId string) error {
+	return u.unitOfWork.WithTx(func(repos repository.Repositories) error {
+		payment, err := repos.Payment.FindByIdForUpdate(paymentId)
+
+		if err != nil {
+			return err
+		}
+
+		if payment.PaymentStatus != model.PaymentStatusPending {
+			return nil
+		}
+
+		if err := transitionOrder(repos, &order, model.OrderStatusCanceled, "system"); err != nil {
+			return err
+		}
+
+		if order.DepositStatus == model.DepositStatusHeld {
+			if err := repos.Order.Update(order.ID, model.Order{DepositStatus: model.DepositStatusReleased}); err != nil {
+				return err
+			}
+		}
+
+		payment.PaymentStatus = model.PaymentStatusCancel
+		payment.UpdatedAt = time.Now()
+
+		return repos.Payment.Update(payment.ID, *payment)
+	})
+}
+
+// payWithWallet takes the payment of a new order from the wallet of its customer and marks the order paid.
+func (u orderUsecase) payWithWallet(repos repository.Repositories, order *model.Order, payment *model.Payment) error {
+	wallet, err := lockWallet(repos, order.UserId)
+
+	if err != nil {
+		return err
+	}
+
+	err = applyWalletTransaction(repos, wallet, model.WalletTransaction{
+		Type:        model.WalletTransactionPayment,
+		Amount:      -payment.Amount,
+		PaymentId:   payment.ID,
+		Description: fmt.Sprintf("payment of order %s", order.ID),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	payment.PaymentStatus = model.PaymentStatusSettlement
+	payment.UpdatedAt = time.Now()
+
+	if err := repos.Payment.Update(payment.ID, *payment); err != nil {
+		return err
+	}
+
+	if err := postSettlement(repos, *order, *payment, u.policy.CommissionPercent); err != nil {
+		return err
 	}
 
-	// setup response from my app
-	data := map[string]interface{}{
+	return transitionOrder(repos, order, model.OrderStatusPaid, order.UserId)
+}
+
+// orderResponse is what customers get back for a new order, a wallet order has no payment link.
+func orderResponse(order model.Order, payment model.Payment) map[string]interface{} {
+	return map[string]interface{}{
 		"order_id":       order.ID,
-		"total_payments": totalPayments,
+		"status":         order.Status,
+		"start_at":       order.StartAt,
+		"end_at":         order.EndAt,
+		"total_payments": order.TotalPayment,
+		"discount":       order.Discount,
+		"deposit":        order.Deposit,
 		"payments": map[string]interface{}{
 			"id":             payment.ID,
 			"payment_status": payment.PaymentStatus,
@@ -174,67 +459,1031 @@ func (u orderUsecase) CreateOrder(orderDTO dto.OrderDTO) (map[string]interface{}
 			"created_at":     payment.CreatedAt,
 			"updated_at":     payment.UpdatedAt,
 		},
-		"payment_link": snapUrl,
+		"payment_link": payment.PaymentLink,
 	}
+}
 
-	return data, nil
+func (u orderUsecase) PickupBike(orderId string, actorId string) error {
+	return u.unitOfWork.WithTx(func(repos repository.Repositories) error {
+		order, err := repos.Order.FindById(orderId)
+
+		if err != nil {
+			return err
+		}
+
+		return transitionOrder(repos, order, model.OrderStatusPickedUp, actorId)
+	})
 }
 
-func (u orderUsecase) UpdateRentStatus(orderId string) error {
-	var err error
+func (u orderUsecase) UpdateRentStatus(orderId string, actorId string) (map[string]interface{}, error) {
+	var (
+		data    map[string]interface{}
+		payment *model.Payment
+		snapReq *dto.PaymentGateway
+	)
+
+	err := u.unitOfWork.WithTx(func(repos repository.Repositories) error {
+		order, err := repos.Order.FindById(orderId)
+
+		if err != nil {
+			return err
+		}
+
+		// bikes become bookable again as soon as the order is returned,
+		// since availability is derived from the active orders of each bike
+		if err := transitionOrder(repos, order, model.OrderStatusReturned, actorId); err != nil {
+			return err
+		}
+
+		returnedAt := time.Now()
+		lateHours, lateFee := countLateFee(*order, returnedAt, u.policy.LateReturnGrace)
+
+		if err := repos.Order.Update(order.ID, model.Order{ReturnedAt: &returnedAt, LateFee: lateFee}); err != nil {
+			return err
+		}
+
+		data = map[string]interface{}{
+			"order_id":    order.ID,
+			"returned_at": returnedAt,
+			"late_hours":  lateHours,
+			"late_fee":    lateFee,
+		}
+
+		// a late return stays returned until its late fee is paid, the payment notification closes it
+		if lateFee > 0 {
+			items := []midtrans.ItemDetails{}
+			for i := range order.OrderDetails {
+				bike := order.OrderDetails[i].Bike
+
+				if bike == nil {
+					continue
+				}
+
+				items = append(items, midtrans.ItemDetails{
+					ID:    bike.ID,
+					Name:  "Late return " + bike.Name,
+					Price: int64(bike.PricePerHour),
+					Qty:   int32(lateHours),
+				})
+			}
+
+			payment, snapReq, err = recordFollowUpPayment(repos, *order, model.PaymentKindLateFee, lateFee, items, 0)
+
+			if err != nil {
+				return err
+			}
+
+			data["status"] = order.Status
+
+			return nil
+		}
+
+		if err := transitionOrder(repos, order, model.OrderStatusClosed, actorId); err != nil {
+			return err
+		}
+
+		data["status"] = order.Status
+
+		return nil
+	})
 
-	var order *model.Order
-	order, err = u.orderRepository.FindById(orderId)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// get the bike from Order.OrderDetails
-	for i := range order.OrderDetails {
-		bike := order.OrderDetails[i].Bike
+	if payment == nil {
+		return data, nil
+	}
+
+	// the payment link of the late fee is asked for once the return is committed, the return stands even
+	// when the payment gateway does not answer
+	if err := u.createPaymentLink(payment, *snapReq); err != nil {
+		return nil, fmt.Errorf("%w, the bike is returned but its late fee has no payment link", err)
+	}
+
+	data["payment_link"] = payment.PaymentLink
+
+	return data, nil
+}
+
+func (u orderUsecase) ExtendOrder(orderId string, extensionDTO dto.OrderExtensionDTO) (map[string]interface{}, error) {
+	var (
+		data    map[string]interface{}
+		payment *model.Payment
+		snapReq *dto.PaymentGateway
+	)
 
-		// update the bike availability
-		bike.IsAvailable = "1"
+	err := u.unitOfWork.WithTx(func(repos repository.Repositories) error {
+		// lock the bikes like a new order does before anything is read, so the schedule read below is taken
+		// after the bikes are held and the extra hours cannot be booked by someone else meanwhile
+		if _, err := repos.Bike.FindByIdOrderForUpdate(orderId); err != nil {
+			return err
+		}
 
-		err := u.bikeRepository.Update(bike.ID, *bike)
+		order, err := repos.Order.FindByIdForUpdate(orderId)
 
 		if err != nil {
 			return err
 		}
-	}
 
-	var history *model.History
-	history, err = u.historyRepository.FindByIdOrder(orderId)
+		if order.Status != model.OrderStatusPaid && order.Status != model.OrderStatusPickedUp {
+			return pkg.ErrOrderNotExtendable
+		}
+
+		if order.PendingEndAt != nil {
+			return pkg.ErrExtensionPending
+		}
+
+		// an overdue order is extended from its past end
+		if err := validateRentEnd(order.EndAt, extensionDTO.EndAt); err != nil {
+			return err
+		}
+
+		extraHours := countRentHours(order.EndAt, extensionDTO.EndAt)
+
+		// the extra hours are priced as a rent of their own, starting where the order ends
+		var extraPayment float32
+
+		items := []midtrans.ItemDetails{}
+		for i := range order.OrderDetails {
+			bike := order.OrderDetails[i].Bike
+
+			if bike == nil {
+				continue
+			}
+
+			// the order itself ends where the extension starts, so it never clashes with its own bikes
+			if err := checkBikeSchedule(repos.Bike, *bike, order.EndAt, extensionDTO.EndAt); err != nil {
+				return err
+			}
+
+			quote, err := quoteBike(u.pricingEngine, repos.PricingRule, *bike, order.EndAt, extensionDTO.EndAt)
+
+			if err != nil {
+				return err
+			}
+
+			extraPayment += quote.Total
+			items = append(items, quoteItems(*bike, quote, "Extension ")...)
+		}
+
+		// the bikes are held until the extended end while the extension waits for its payment
+		if err := repos.Order.UpdatePendingEndAt(order.ID, &extensionDTO.EndAt); err != nil {
+			return err
+		}
+
+		payment, snapReq, err = recordFollowUpPayment(repos, *order, model.PaymentKindExtension, extraPayment, items, u.policy.PaymentTTL)
+
+		if err != nil {
+			return err
+		}
+
+		data = map[string]interface{}{
+			"order_id":       order.ID,
+			"end_at":         order.EndAt,
+			"pending_end_at": extensionDTO.EndAt,
+			"extra_hours":    extraHours,
+			"extra_payment":  extraPayment,
+		}
+
+		return nil
+	})
 
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	// like a new order, the payment link is asked for once the bikes are no longer locked
+	if err := u.createPaymentLink(payment, *snapReq); err != nil {
+		if abandonErr := u.abandonExtension(orderId, payment.ID); abandonErr != nil {
+			return nil, fmt.Errorf("%w, and the extension was not dropped: %v", err, abandonErr)
+		}
+
+		return nil, err
 	}
 
-	history.RentStatus = "done"
+	data["payment_link"] = payment.PaymentLink
+
+	return data, nil
+}
+
+// abandonExtension drops an extension the payment gateway made no payment link for, the bikes are no longer held
+// for its extra hours.
+func (u orderUsecase) abandonExtension(orderId string, paymentId string) error {
+	return u.unitOfWork.WithTx(func(repos repository.Repositories) error {
+		payment, err := repos.Payment.FindByIdForUpdate(paymentId)
+
+		if err != nil {
+			return err
+		}
+
+		if payment.PaymentStatus != model.PaymentStatusPending {
+			return nil
+		}
+
+		payment.PaymentStatus = model.PaymentStatusCancel
+		payment.UpdatedAt = time.Now()
+
+		if err := repos.Payment.Update(payment.ID, *payment); err != nil {
+			return err
+		}
+
+		return repos.Order.UpdatePendingEndAt(orderId, nil)
+	})
+}
 
-	err = u.historyRepository.Update(orderId, *history)
+// recordFollowUpPayment creates a pending payment of an order and the request for its payment link.
+// The payment id is sent to the payment gateway as its order id, since the gateway needs a unique one.
+// A zero expiry leaves the payment link open as long as the payment gateway allows.
+func recordFollowUpPayment(repos repository.Repositories, order model.Order, kind string, amount float32, items []midtrans.ItemDetails, expiry time.Duration) (*model.Payment, *dto.PaymentGateway, error) {
+	customer, err := repos.User.FindById(order.UserId)
 
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	return nil
+	payment := model.Payment{
+		ID:            uuid.NewString(),
+		OrderId:       order.ID,
+		Kind:          kind,
+		Amount:        amount,
+		PaymentStatus: model.PaymentStatusPending,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	if err := repos.Payment.Create(payment); err != nil {
+		return nil, nil, err
+	}
+
+	snapReq := dto.PaymentGateway{
+		Email:         customer.Email,
+		Phone:         customer.Phone,
+		OrderId:       payment.ID,
+		GrossAmt:      int64(amount),
+		Items:         items,
+		ExpiryStartAt: payment.CreatedAt,
+		ExpiryMinutes: int64(expiry / time.Minute),
+	}
+
+	return &payment, &snapReq, nil
+}
+
+func (u orderUsecase) CancelOrder(orderId string, actorId string) (map[string]interface{}, error) {
+	var (
+		data    map[string]interface{}
+		payment *model.Payment
+		refunds []model.Refund
+	)
+
+	err := u.unitOfWork.WithTx(func(repos repository.Repositories) error {
+		order, err := repos.Order.FindById(orderId)
+
+		if err != nil {
+			return err
+		}
+
+		// like a payment notification, the payment is locked before the order is read again,
+		// so a settlement that comes at the same time is never overwritten
+		payment, err = repos.Payment.FindByIdForUpdate(order.PaymentId)
+
+		if err != nil {
+			return err
+		}
+
+		order, err = repos.Order.FindByIdForUpdate(orderId)
+
+		if err != nil {
+			return err
+		}
+
+		// nothing is charged before the payment settles, so only paid orders pay the cancellation fee
+		previousStatus := order.Status
+
+		var cancellationFee float32
+
+		if previousStatus == model.OrderStatusPaid {
+			cancellationFee, err = countCancellationFee(repos.Renter, *order, time.Now())
+
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := transitionOrder(repos, order, model.OrderStatusCanceled, actorId); err != nil {
+			return err
+		}
+
+		if cancellationFee > 0 {
+			if err := repos.Order.Update(order.ID, model.Order{CancellationFee: cancellationFee}); err != nil {
+				return err
+			}
+		}
+
+		refundAmount := order.TotalPayment - cancellationFee
+
+		switch previousStatus {
+		case model.OrderStatusPendingPayment:
+			payment.PaymentStatus = model.PaymentStatusCancel
+
+			// the deposit was never charged, so there is nothing to give back
+			if order.DepositStatus == model.DepositStatusHeld {
+				order.DepositStatus = model.DepositStatusReleased
+
+				if err := repos.Order.Update(order.ID, model.Order{DepositStatus: order.DepositStatus}); err != nil {
+					return err
+				}
+			}
+
+			// the payment gateway is called last, so a failure there rolls the cancellation back
+			if err := u.paymentProvider.CancelTransaction(order.ID); err != nil {
+				return paymentGatewayError(err)
+			}
+		case model.OrderStatusPaid:
+			// a renter may have refunded part of the payment already
+			refundable, err := refundableAmount(repos, *payment)
+
+			if err != nil {
+				return err
+			}
+
+			if refundAmount > refundable {
+				refundAmount = refundable
+			}
+
+			if refundAmount <= 0 {
+				refundAmount = 0
+			} else {
+				refund, err := requestRefund(repos, payment, refundAmount, "order canceled by customer", actorId)
+
+				if err != nil {
+					return err
+				}
+
+				refunds = append(refunds, *refund)
+			}
+
+			// the bikes were never picked up, so the whole deposit goes back too
+			if order.DepositStatus == model.DepositStatusHeld {
+				refund, err := settleDeposit(repos, order, payment, 0, "", "", actorId)
+
+				if err != nil {
+					return err
+				}
+
+				if refund != nil {
+					refunds = append(refunds, *refund)
+				}
+			}
+		}
+
+		payment.UpdatedAt = time.Now()
+
+		if err := repos.Payment.Update(payment.ID, *payment); err != nil {
+			return err
+		}
+
+		data = map[string]interface{}{
+			"order_id":         order.ID,
+			"status":           order.Status,
+			"payment_status":   payment.PaymentStatus,
+			"cancellation_fee": cancellationFee,
+			"refund_amount":    refundAmount,
+			"deposit_status":   order.DepositStatus,
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the refunds are recorded with the cancellation and sent once it is committed, so a failure of the payment
+	// gateway can never undo a refund it already made. A refund that was not sent stays pending and the
+	// reconciliation sends it again under the same refund key
+	for i := range refunds {
+		_ = sendRefund(u.paymentProvider, *payment, refunds[i])
+	}
+
+	return data, nil
+}
+
+// SettleDeposit settles the security deposit of a returned order: a renter of the order withholds part of it
+// for damage or loss, and the rest goes back to the customer as a refund of the payment.
+func (u orderUsecase) SettleDeposit(orderId string, actorId string, depositDTO dto.OrderDepositDTO) (map[string]interface{}, error) {
+	if depositDTO.WithheldAmount < 0 || len(depositDTO.Reason) > 255 {
+		return nil, fmt.Errorf("%w: withheld amount must not be negative and reason at most 255 characters", pkg.ErrInvalidDeposit)
+	}
+
+	if depositDTO.WithheldAmount > 0 && depositDTO.Reason == "" {
+		return nil, fmt.Errorf("%w: a reason is required to withhold the deposit", pkg.ErrInvalidDeposit)
+	}
+
+	var data map[string]interface{}
+
+	err := u.unitOfWork.WithTx(func(repos repository.Repositories) error {
+		order, err := repos.Order.FindById(orderId)
+
+		if err != nil {
+			return err
+		}
+
+		renter, err := findActorRenter(repos.Renter, actorId)
+
+		if err != nil {
+			return err
+		}
+
+		if err := authorizeRefund(repos.OrderDetail, renter.ID, order.ID); err != nil {
+			return err
+		}
+
+		if order.Status != model.OrderStatusReturned && order.Status != model.OrderStatusClosed {
+			return fmt.Errorf("%w: the bikes of the order are not returned yet", pkg.ErrDepositNotSettleable)
+		}
+
+		// the payment is locked first, so the same deposit cannot be settled twice at the same time
+		payment, err := repos.Payment.FindByIdForUpdate(order.PaymentId)
+
+		if err != nil {
+			return err
+		}
+
+		if order.DepositStatus != model.DepositStatusHeld {
+			return fmt.Errorf("%w: there is no deposit held", pkg.ErrDepositNotSettleable)
+		}
+
+		if depositDTO.WithheldAmount > order.Deposit {
+			return fmt.Errorf("%w: at most %.0f can be withheld", pkg.ErrInvalidDeposit, order.Deposit)
+		}
+
+		refund, err := settleDeposit(repos, order, payment, depositDTO.WithheldAmount, depositDTO.Reason, renter.ID, actorId)
+
+		if err != nil {
+			return err
+		}
+
+		// the payment gateway is called last, so a failure there rolls the settlement back
+		if refund != nil {
+			if err := sendRefund(u.paymentProvider, *payment, *refund); err != nil {
+				return err
+			}
+		}
+
+		data = map[string]interface{}{
+			"order_id":         order.ID,
+			"deposit":          order.Deposit,
+			"deposit_status":   order.DepositStatus,
+			"deposit_withheld": order.DepositWithheld,
+			"deposit_released": order.Deposit - order.DepositWithheld,
+			"deposit_reason":   order.DepositReason,
+			"payment_status":   payment.PaymentStatus,
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// settleDeposit releases the deposit held for an order, less the withheld amount which is owed to the renter.
+// It returns the refund of the released deposit, nil when all of it is withheld, which still has to be sent with sendRefund.
+func settleDeposit(repos repository.Repositories, order *model.Order, payment *model.Payment, withheld float32, reason string, renterId string, actorId string) (*model.Refund, error) {
+	order.DepositStatus = model.DepositStatusReleased
+	order.DepositWithheld = withheld
+	order.DepositReason = reason
+	order.UpdatedAt = time.Now()
+
+	if withheld > 0 {
+		order.DepositStatus = model.DepositStatusWithheld
+
+		j := newJournal(fmt.Sprintf("deposit withheld of order %s: %s", order.ID, reason))
+		j.debit(model.LedgerAccountDepositHeld, "", withheld)
+		j.credit(model.LedgerAccountRenterPayable, renterId, withheld)
+
+		if err := j.post(repos.Ledger); err != nil {
+			return nil, err
+		}
+	}
+
+	err := repos.Order.Update(order.ID, model.Order{
+		DepositStatus:   order.DepositStatus,
+		DepositWithheld: order.DepositWithheld,
+		DepositReason:   order.DepositReason,
+		UpdatedAt:       order.UpdatedAt,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	released := order.Deposit - withheld
+
+	if released <= 0 {
+		return nil, repos.Payment.Update(payment.ID, *payment)
+	}
+
+	refund := model.Refund{
+		ID:          uuid.NewString(),
+		PaymentId:   payment.ID,
+		Kind:        model.RefundKindDeposit,
+		Amount:      released,
+		Reason:      "deposit released",
+		Status:      model.RefundStatusPending,
+		RequestedBy: actorId,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	if err := recordRefund(repos, payment, &refund); err != nil {
+		return nil, err
+	}
+
+	if err := repos.Payment.Update(payment.ID, *payment); err != nil {
+		return nil, err
+	}
+
+	return &refund, nil
+}
+
+// ExpireUnpaidOrders expires every order and extension still waiting for payment longer than the payment ttl,
+// which releases their bikes. It returns how many orders and extensions were expired.
+func (u orderUsecase) ExpireUnpaidOrders(now time.Time) (int, error) {
+	orders, err := u.orderRepository.FindPendingPayment(now.Add(-u.policy.PaymentTTL))
+
+	if err != nil {
+		return 0, err
+	}
+
+	// every order is expired in its own transaction, so one failing order does not hold back the others
+	var (
+		expired  int
+		firstErr error
+	)
+
+	for i := range *orders {
+		orderId := (*orders)[i].ID
+		paymentId := (*orders)[i].PaymentId
+
+		err := u.unitOfWork.WithTx(func(repos repository.Repositories) error {
+			// like a payment notification, the payment is locked before the order is read again,
+			// so an order is never expired while its settlement is being applied
+			payment, err := repos.Payment.FindByIdForUpdate(paymentId)
+
+			if err != nil {
+				return err
+			}
+
+			order, err := repos.Order.FindByIdForUpdate(orderId)
+
+			if err != nil {
+				return err
+			}
+
+			// the order may have been paid or canceled since it was listed
+			if order.Status != model.OrderStatusPendingPayment || payment.PaymentStatus != model.PaymentStatusPending {
+				return nil
+			}
+
+			if err := transitionOrder(repos, order, model.OrderStatusExpired, "system"); err != nil {
+				return err
+			}
+
+			// the deposit was never charged, so it is released along with the bikes
+			if order.DepositStatus == model.DepositStatusHeld {
+				order.DepositStatus = model.DepositStatusReleased
+
+				if err := repos.Order.Update(order.ID, model.Order{DepositStatus: order.DepositStatus}); err != nil {
+					return err
+				}
+			}
+
+			payment.PaymentStatus = model.PaymentStatusExpire
+			payment.UpdatedAt = time.Now()
+
+			if err := repos.Payment.Update(payment.ID, *payment); err != nil {
+				return err
+			}
+
+			// the payment gateway is called last, so a failure there leaves the order for the next run
+			if err := u.closePaymentLink(gatewayOrderId(*payment)); err != nil {
+				return err
+			}
+
+			expired++
+
+			return nil
+		})
+
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("expire order %s: %w", orderId, err)
+		}
+	}
+
+	payments, err := u.paymentRepository.FindPending(model.PaymentKindExtension, now.Add(-u.policy.PaymentTTL))
+
+	if err != nil {
+		return expired, err
+	}
+
+	for i := range *payments {
+		paymentId := (*payments)[i].ID
+
+		err := u.unitOfWork.WithTx(func(repos repository.Repositories) error {
+			payment, err := repos.Payment.FindByIdForUpdate(paymentId)
+
+			if err != nil {
+				return err
+			}
+
+			if payment.PaymentStatus != model.PaymentStatusPending {
+				return nil
+			}
+
+			payment.PaymentStatus = model.PaymentStatusExpire
+			payment.UpdatedAt = time.Now()
+
+			if err := repos.Payment.Update(payment.ID, *payment); err != nil {
+				return err
+			}
+
+			order, err := repos.Order.FindByIdForUpdate(payment.OrderId)
+
+			if err != nil {
+				return err
+			}
+
+			if err := dropExtension(repos, order); err != nil {
+				return err
+			}
+
+			if err := u.closePaymentLink(gatewayOrderId(*payment)); err != nil {
+				return err
+			}
+
+			expired++
+
+			return nil
+		})
+
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("expire extension %s: %w", paymentId, err)
+		}
+	}
+
+	return expired, firstErr
+}
+
+// closePaymentLink cancels a pending transaction in the payment gateway, so the customer can no longer pay
+// for bikes that were given back. A transaction the payment gateway already closed itself is left as it is.
+func (u orderUsecase) closePaymentLink(gatewayOrderId string) error {
+	err := u.paymentProvider.CancelTransaction(gatewayOrderId)
+
+	if err == nil {
+		return nil
+	}
+
+	transactionStatusRes, checkErr := u.paymentProvider.CheckTransaction(gatewayOrderId)
+
+	if checkErr == nil {
+		switch transactionStatusRes.TransactionStatus {
+		case model.PaymentStatusExpire, model.PaymentStatusCancel, model.PaymentStatusDeny:
+			return nil
+		}
+	}
+
+	return paymentGatewayError(err)
+}
+
+func (u orderUsecase) FindOrderStatusHistories(orderId string) (*[]model.OrderStatusHistory, error) {
+	if _, err := u.orderRepository.FindById(orderId); err != nil {
+		return nil, err
+	}
+
+	statusHistories, err := u.orderStatusHistoryRepository.FindByIdOrder(orderId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return statusHistories, nil
+}
+
+// OverrideOrderStatus lets an admin move an order to any other status, outside of its lifecycle, to
+// unblock the orders stuck in a support case. The reason is kept in the status history, no money is moved.
+func (u orderUsecase) OverrideOrderStatus(orderId string, actorId string, overrideDTO dto.OrderOverrideDTO) error {
+	if !overrideDTO.Status.IsValid() || overrideDTO.Reason == "" {
+		return pkg.ErrInvalidOrderOverride
+	}
+
+	return u.unitOfWork.WithTx(func(repos repository.Repositories) error {
+		order, err := repos.Order.FindById(orderId)
+
+		if err != nil {
+			return err
+		}
+
+		if order.Status == overrideDTO.Status {
+			return pkg.ErrInvalidOrderOverride
+		}
+
+		return recordTransition(repos, order, overrideDTO.Status, actorId, overrideDTO.Reason)
+	})
+}
+
+// transitionOrder moves the order to the next status when the state machine allows it,
+// records who moved it and keeps the customer history in line with the order.
+func transitionOrder(repos repository.Repositories, order *model.Order, next model.OrderStatus, actor string) error {
+	if !order.Status.CanTransitionTo(next) {
+		return fmt.Errorf("%w: %s to %s", pkg.ErrInvalidStatusTransition, order.Status, next)
+	}
+
+	return recordTransition(repos, order, next, actor, "")
+}
+
+// recordTransition moves the order to the next status without checking the state machine.
+func recordTransition(repos repository.Repositories, order *model.Order, next model.OrderStatus, actor string, reason string) error {
+	statusHistory := model.OrderStatusHistory{
+		ID:         uuid.NewString(),
+		OrderId:    order.ID,
+		FromStatus: order.Status,
+		ToStatus:   next,
+		Actor:      actor,
+		Reason:     reason,
+		CreatedAt:  time.Now(),
+	}
+
+	order.Status = next
+	order.UpdatedAt = time.Now()
+
+	if err := repos.Order.Update(order.ID, model.Order{Status: order.Status, UpdatedAt: order.UpdatedAt}); err != nil {
+		return err
+	}
+
+	if err := repos.OrderStatusHistory.Create(statusHistory); err != nil {
+		return err
+	}
+
+	history, err := repos.History.FindByIdOrder(order.ID)
+
+	if err != nil {
+		return err
+	}
+
+	history.RentStatus = string(next)
+	history.UpdatedAt = time.Now()
+
+	return repos.History.Update(order.ID, *history)
+}
+
+// quoteItems lists every line of a quote as its own item, so the items add up to the gross amount.
+func quoteItems(bike model.Bike, quote pricing.Quote, prefix string) []midtrans.ItemDetails {
+	items := []midtrans.ItemDetails{}
+
+	for i := range quote.Lines {
+		items = append(items, midtrans.ItemDetails{
+			ID:       bike.ID,
+			Name:     fmt.Sprintf("%s%s (%s)", prefix, bike.Name, quote.Lines[i].Description),
+			Price:    int64(quote.Lines[i].Amount),
+			Qty:      1,
+			Category: bike.Category.Name,
+		})
+	}
+
+	return items
+}
+
+// applyExtension moves the end of the order to its extended end once the extension is paid.
+func applyExtension(repos repository.Repositories, order *model.Order, payment model.Payment) error {
+	// a repeated notification finds the extension already applied
+	if order.PendingEndAt == nil {
+		return nil
+	}
+
+	order.EndAt = *order.PendingEndAt
+	order.TotalHour = countRentHours(order.StartAt, order.EndAt)
+	order.TotalPayment += payment.Amount
+	order.UpdatedAt = time.Now()
+
+	orderUC := model.Order{
+		EndAt:        order.EndAt,
+		TotalHour:    order.TotalHour,
+		TotalPayment: order.TotalPayment,
+		UpdatedAt:    order.UpdatedAt,
+	}
+
+	if err := repos.Order.Update(order.ID, orderUC); err != nil {
+		return err
+	}
+
+	order.PendingEndAt = nil
+
+	return repos.Order.UpdatePendingEndAt(order.ID, nil)
+}
+
+// dropExtension releases the extra hours of an extension that will never be paid.
+func dropExtension(repos repository.Repositories, order *model.Order) error {
+	if order.PendingEndAt == nil {
+		return nil
+	}
+
+	order.PendingEndAt = nil
+
+	return repos.Order.UpdatePendingEndAt(order.ID, nil)
+}
+
+// countLateFee charges every started hour past the booked end of the rent for each bike,
+// nothing is charged while the bikes are returned within the grace period.
+func countLateFee(order model.Order, returnedAt time.Time, grace time.Duration) (int, float32) {
+	late := returnedAt.Sub(order.EndAt)
+
+	if late <= grace {
+		return 0, 0
+	}
+
+	lateHours := int(math.Ceil(late.Hours()))
+
+	var lateFee float32
+
+	for i := range order.OrderDetails {
+		if bike := order.OrderDetails[i].Bike; bike != nil {
+			lateFee += bike.PricePerHour * float32(lateHours)
+		}
+	}
+
+	return lateHours, lateFee
+}
+
+// countCancellationFee applies the cancellation policy of the renter of each bike, a bike costs its fee
+// once the order is canceled less than the free cancellation hours of its renter before the rent starts.
+// The fee is a part of what the customer paid for the bike: its subtotal less its share of the discount,
+// so the fee never goes over the rent paid for the order.
+func countCancellationFee(renterRepository repository.RenterRepository, order model.Order, canceledAt time.Time) (float32, error) {
+	renters := map[string]*model.Renter{}
+
+	// the discount is shared between the bikes in proportion to their subtotals, like the renter shares
+	var subtotals float64
+	for i := range order.OrderDetails {
+		subtotals += float64(order.OrderDetails[i].Subtotal)
+	}
+
+	var fee float32
+
+	for i := range order.OrderDetails {
+		bike := order.OrderDetails[i].Bike
+
+		if bike == nil {
+			continue
+		}
+
+		renter, ok := renters[bike.RenterId]
+
+		if !ok {
+			var err error
+			renter, err = renterRepository.FindById(bike.RenterId)
+
+			if err != nil {
+				return 0, err
+			}
+
+			renters[bike.RenterId] = renter
+		}
+
+		freeUntil := order.StartAt.Add(-time.Duration(renter.FreeCancellationHours) * time.Hour)
+
+		if canceledAt.Before(freeUntil) {
+			continue
+		}
+
+		paid := float64(order.OrderDetails[i].Subtotal)
+		if subtotals > 0 {
+			paid -= float64(order.Discount) * paid / subtotals
+		}
+
+		fee += float32(math.Round(paid * float64(renter.CancellationFeePercent) / 100))
+	}
+
+	if fee > order.TotalPayment {
+		fee = order.TotalPayment
+	}
+
+	return fee, nil
+}
+
+func checkBikeSchedule(bikeRepository repository.BikeRepository, bike model.Bike, startAt time.Time, endAt time.Time) error {
+	windows, err := findBookedWindows(bikeRepository, bike.ID, startAt, endAt)
+
+	if err != nil {
+		return err
+	}
+
+	if len(windows) > 0 {
+		return fmt.Errorf(
+			"%w: %s is booked from %s to %s",
+			pkg.ErrBookingConflict,
+			bike.Name,
+			windows[0].StartAt.Format(time.RFC3339),
+			windows[0].EndAt.Format(time.RFC3339),
+		)
+	}
+
+	return nil
+}
+
+// findBookedWindows lists when the orders overlapping a rent window hold a bike, without who booked it.
+func findBookedWindows(bikeRepository repository.BikeRepository, bikeId string, startAt time.Time, endAt time.Time) ([]dto.BookedWindowDTO, error) {
+	orders, err := bikeRepository.FindOverlappingOrders(bikeId, startAt, endAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	windows := []dto.BookedWindowDTO{}
+	for i := range *orders {
+		order := (*orders)[i]
+
+		// an unpaid extension holds the bike until its extended end
+		windowEndAt := order.EndAt
+		if order.PendingEndAt != nil {
+			windowEndAt = *order.PendingEndAt
+		}
+
+		windows = append(windows, dto.BookedWindowDTO{
+			StartAt: order.StartAt,
+			EndAt:   windowEndAt,
+		})
+	}
+
+	return windows, nil
+}
+
+func uniqueIds(ids []string) []string {
+	seen := map[string]bool{}
+	unique := []string{}
+
+	for i := range ids {
+		if !seen[ids[i]] {
+			seen[ids[i]] = true
+			unique = append(unique, ids[i])
+		}
+	}
+
+	return unique
+}
+
+// validateRentWindow accepts a window for a new booking, a window that already started can no longer be booked.
+func validateRentWindow(startAt time.Time, endAt time.Time) error {
+	if startAt.Before(time.Now()) {
+		return pkg.ErrInvalidRentWindow
+	}
+
+	return validateRentEnd(startAt, endAt)
+}
+
+// validateRentEnd accepts an end after the start and in the future, the start may be past for a rent
+// that is already running.
+func validateRentEnd(startAt time.Time, endAt time.Time) error {
+	if startAt.IsZero() || endAt.IsZero() || !endAt.After(startAt) || !endAt.After(time.Now()) {
+		return pkg.ErrInvalidRentWindow
+	}
+
+	return nil
+}
+
+// countRentHours rounds the rent window up to whole hours, since bikes are charged per started hour.
+func countRentHours(startAt time.Time, endAt time.Time) int {
+	return int(math.Ceil(endAt.Sub(startAt).Hours()))
 }
 
 func NewOrderUsecase(
+	unitOfWork repository.UnitOfWork,
+	paymentProvider payment.PaymentProvider,
 	orderRepo repository.OrderRepository,
 	orderDetailRepo repository.OrderDetailRepository,
 	userRepo repository.UserRepository,
 	bikeRepo repository.BikeRepository,
 	paymentRepo repository.PaymentRepository,
 	historyRepo repository.HistoryRepository,
+	orderStatusHistoryRepo repository.OrderStatusHistoryRepository,
+	policy OrderPolicy,
+	pricingEngine pricing.Engine,
 ) OrderUsecase {
 	return orderUsecase{
-		orderRepository:       orderRepo,
-		orderDetailRepository: orderDetailRepo,
-		userRepository:        userRepo,
-		bikeRepository:        bikeRepo,
-		paymentRepository:     paymentRepo,
-		historyRepository:     historyRepo,
+		unitOfWork:                   unitOfWork,
+		paymentProvider:              paymentProvider,
+		orderRepository:              orderRepo,
+		orderDetailRepository:        orderDetailRepo,
+		userRepository:               userRepo,
+		bikeRepository:               bikeRepo,
+		paymentRepository:            paymentRepo,
+		historyRepository:            historyRepo,
+		orderStatusHistoryRepository: orderStatusHistoryRepo,
+		policy:                       policy,
+		pricingEngine:                pricingEngine,
 	}
 }
@@ -1,214 +1,2311 @@
 package usecase
 
 import (
+	"errors"
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
 	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/payment"
+	paymentmock "github.com/arvinpaundra/go-rent-bike/internal/payment/mock"
+	"github.com/arvinpaundra/go-rent-bike/internal/pricing"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	repomock "github.com/arvinpaundra/go-rent-bike/internal/repository/gormdb/mock"
 	"github.com/arvinpaundra/go-rent-bike/pkg"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"sync"
 	"testing"
 	"time"
 )
 
+var paymentGateway = paymentmock.PaymentProviderMock{Mock: mock.Mock{}}
+
+// only customers with a verified email can order
+var emailVerifiedAt = time.Now().Add(-24 * time.Hour)
+
 var orderUsecaseTest = NewOrderUsecase(
+	&pkg.UnitOfWork,
+	&paymentGateway,
 	&pkg.OrderRepository,
 	&pkg.OrderDetailRepository,
 	&pkg.UserRepository,
 	&pkg.BikeRepository,
 	&pkg.PaymentRepository,
 	&pkg.HistoryRepository,
+	&pkg.OrderStatusHistoryRepository,
+	OrderPolicy{PaymentTTL: time.Hour, LateReturnGrace: 15 * time.Minute},
+	pricing.NewEngine(nil),
 )
 
-// TODO belum berhasil buat test midtrans
-//func TestOrderUsecase_CreateOrder(t *testing.T) {
-//	pgMidtrans.PaymentGateway{}.InitializeClientMidtrans()
-//
-//	customerId := "28dc0243-7553-4ebc-9937-a0f5505df7e3"
-//
-//	customer := &model.User{
-//		ID:        "28dc0243-7553-4ebc-9937-a0f5505df7e3",
-//		Fullname:  "Arvin Paundra",
-//		Phone:     "0876534321",
-//		Address:   "Jl Rinjani",
-//		Role:      "customer",
-//		Email:     "arvin@mail.com",
-//		CreatedAt: time.Now(),
-//		UpdatedAt: time.Now(),
-//	}
-//
-//	pkg.UserRepository.Mock.On("FindById", customerId).Return(customer, nil)
-//
-//	orderDTO := dto.OrderDTO{
-//		CustomerId:  "28dc0243-7553-4ebc-9937-a0f5505df7e3",
-//		BikeIds:     []string{"c4b10642-95a5-4aca-a612-fdc1b0837f37"},
-//		TotalHour:   5,
-//		PaymentType: "bank_transfer",
-//	}
-//
-//	bikes := &[]model.Bike{
-//		{
-//			ID:           "c4b10642-95a5-4aca-a612-fdc1b0837f37",
-//			RenterId:     "ffad8203-b32d-46dd-b488-a700ad61dac7",
-//			CategoryId:   "463c249c-300e-4138-8cfb-8a7fa5042da0",
-//			Name:         "Sample BMX Bike",
-//			PricePerHour: 15000,
-//			Condition:    "Good",
-//			Description:  "This is a description section",
-//			IsAvailable:  "1",
-//		},
-//	}
-//
-//	for i := range orderDTO.BikeIds {
-//		pkg.BikeRepository.Mock.On("FindById").Return((*bikes)[i], nil)
-//
-//		(*bikes)[i].IsAvailable = "0"
-//		(*bikes)[i].UpdatedAt = time.Now()
-//
-//		pkg.BikeRepository.Mock.On("Update", (*bikes)[i].ID, (*bikes)[i]).Return(nil)
-//	}
-//
-//	paymentUC := model.Payment{
-//		ID:            "9b5d19ff-0406-46b6-b9f5-d64c2fd9a5bd",
-//		PaymentStatus: "pending",
-//		PaymentType:   orderDTO.PaymentType,
-//		PaymentLink:   "https://app.sandbox.midtrans.com/snap/redirect/v3/...",
-//		CreatedAt:     time.Now(),
-//		UpdatedAt:     time.Now(),
-//	}
-//
-//	pkg.PaymentRepository.Mock.On("Create", paymentUC).Return(nil)
-//
-//	var totalPayments float32
-//
-//	for i := range *bikes {
-//		totalPayments += ((*bikes)[i].PricePerHour * float32(orderDTO.TotalHour))
-//	}
-//
-//	orderUC := model.Order{
-//		ID:           "2a47fd52-893f-4717-a2c3-4bd2c68912af",
-//		UserId:       "28dc0243-7553-4ebc-9937-a0f5505df7e3",
-//		PaymentId:    "9b5d19ff-0406-46b6-b9f5-d64c2fd9a5bd",
-//		TotalPayment: totalPayments,
-//		TotalQty:     len(*bikes),
-//		TotalHour:    orderDTO.TotalHour,
-//		CreatedAt:    time.Now(),
-//		UpdatedAt:    time.Now(),
-//	}
-//
-//	pkg.OrderRepository.Mock.On("Create", orderUC).Return(nil)
-//
-//	orderDetailsUC := &[]model.OrderDetail{
-//		{
-//			ID:      "3a85833f-e946-4729-9c89-48d087b89e86\n\n",
-//			OrderId: "2a47fd52-893f-4717-a2c3-4bd2c68912af",
-//			BikeId:  "c4b10642-95a5-4aca-a612-fdc1b0837f37",
-//			Bike: &model.Bike{
-//				ID:           "c4b10642-95a5-4aca-a612-fdc1b0837f37",
-//				RenterId:     "ffad8203-b32d-46dd-b488-a700ad61dac7",
-//				CategoryId:   "463c249c-300e-4138-8cfb-8a7fa5042da0",
-//				Name:         "Sample BMX Bike",
-//				PricePerHour: 15000,
-//				Condition:    "Good",
-//				Description:  "This is a description section",
-//				IsAvailable:  "1",
-//			},
-//		},
-//	}
-//
-//	pkg.OrderDetailRepository.Mock.On("Create", orderDetailsUC).Return(nil)
-//
-//	historyUC := model.History{
-//		ID:         "6872428e-2fb4-4100-a400-a8b434998ba8",
-//		OrderId:    "2a47fd52-893f-4717-a2c3-4bd2c68912af",
-//		RentStatus: "pending payment",
-//		CreatedAt:  time.Now(),
-//		UpdatedAt:  time.Now(),
-//	}
-//
-//	pkg.HistoryRepository.Mock.On("Create", historyUC).Return(nil)
-//
-//	//items := []midtrans.ItemDetails{
-//	//	{
-//	//		ID:       (*bikes)[1].ID,
-//	//		Name:     (*bikes)[1].Name,
-//	//		Price:    int64((*bikes)[1].PricePerHour),
-//	//		Qty:      int32(orderUC.TotalHour),
-//	//		Category: (*bikes)[1].Category.Name,
-//	//	},
-//	//}
-//
-//	//snapReq := dto.PaymentGateway{
-//	//	Email:    customer.Email,
-//	//	Phone:    customer.Phone,
-//	//	OrderId:  orderUC.ID,
-//	//	GrossAmt: int64(totalPayments),
-//	//	Items:    items,
-//	//}
-//
-//	snapUrl := fmt.Sprintf("https://app.sandbox.midtrans.com/snap/v3/redirection/%v", orderUC.ID)
-//
-//	paymentUC.PaymentLink = snapUrl
-//	pkg.PaymentRepository.Mock.On("Update", paymentUC.ID, paymentUC).Return(nil)
-//
-//	result, err := orderUsecaseTest.CreateOrder(orderDTO)
-//
-//	assert.Nil(t, err)
-//	assert.NotNil(t, result)
-//}
+func TestOrderUsecase_CreateOrder(t *testing.T) {
+	customerId := "28dc0243-7553-4ebc-9937-a0f5505df7e3"
+
+	customer := &model.User{
+		ID:              "28dc0243-7553-4ebc-9937-a0f5505df7e3",
+		Fullname:        "Arvin Paundra",
+		Phone:           "0876534321",
+		Address:         "Jl Rinjani",
+		Role:            "customer",
+		Email:           "arvin@mail.com",
+		EmailVerifiedAt: &emailVerifiedAt,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+
+	pkg.UserRepository.Mock.On("FindById", customerId).Return(customer, nil)
+
+	bikeId := "c4b10642-95a5-4aca-a612-fdc1b0837f37"
+
+	bike := &model.Bike{
+		ID:           "c4b10642-95a5-4aca-a612-fdc1b0837f37",
+		RenterId:     "ffad8203-b32d-46dd-b488-a700ad61dac7",
+		CategoryId:   "463c249c-300e-4138-8cfb-8a7fa5042da0",
+		Name:         "Sample BMX Bike",
+		PricePerHour: 15000,
+		Condition:    "Good",
+		Description:  "This is a description section",
+		IsAvailable:  "1",
+	}
+
+	startAt := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	endAt := startAt.Add(5 * time.Hour)
+
+	orderDTO := dto.OrderDTO{
+		BikeIds:     []string{bikeId},
+		StartAt:     startAt,
+		EndAt:       endAt,
+		PaymentType: "bank_transfer",
+	}
+
+	pkg.BikeRepository.Mock.On("FindByIdsForUpdate", []string{bikeId}).Return(&[]model.Bike{*bike}, nil)
+	pkg.BikeRepository.Mock.On("FindById", bikeId).Return(bike, nil)
+	pkg.BikeRepository.Mock.On("FindOverlappingOrders", bikeId, startAt, endAt).Return(&[]model.Order{}, nil)
+	pkg.PricingRuleRepository.Mock.On("FindActive", bike.RenterId, startAt, endAt).Return(&[]model.PricingRule{}, nil)
+	pkg.RenterRepository.Mock.On("FindById", bike.RenterId).Return(&model.Renter{ID: bike.RenterId}, nil)
+
+	pkg.PaymentRepository.Mock.On("Create", mock.MatchedBy(func(payment model.Payment) bool {
+		return payment.PaymentStatus == "pending" && payment.PaymentType == "bank_transfer"
+	})).Return(nil)
+
+	pkg.OrderRepository.Mock.On("Create", mock.MatchedBy(func(order model.Order) bool {
+		return order.UserId == customerId && order.TotalHour == 5 && order.TotalPayment == 75000 && order.Status == model.OrderStatusPendingPayment
+	})).Return(nil)
+
+	pkg.OrderDetailRepository.Mock.On("Create", mock.MatchedBy(func(details []model.OrderDetail) bool {
+		return len(details) == 1 && details[0].BikeId == bikeId && details[0].Subtotal == 75000
+	})).Return(nil)
+
+	pkg.HistoryRepository.Mock.On("Create", mock.MatchedBy(func(history model.History) bool {
+		return history.RentStatus == "pending_payment"
+	})).Return(nil)
+
+	pkg.OrderStatusHistoryRepository.Mock.On("Create", mock.MatchedBy(func(statusHistory model.OrderStatusHistory) bool {
+		return statusHistory.FromStatus == "" && statusHistory.ToStatus == model.OrderStatusPendingPayment && statusHistory.Actor == customerId
+	})).Return(nil)
+
+	snapUrl := "https://app.sandbox.midtrans.com/snap/v3/redirection/a1b2c3"
+
+	paymentGateway.Mock.On("CreateUrlTransactionWithGateway", mock.MatchedBy(func(req dto.PaymentGateway) bool {
+		return req.Email == customer.Email && req.GrossAmt == 75000
+	})).Return(snapUrl, nil)
+
+	pkg.PaymentRepository.Mock.On("Update", mock.Anything, mock.MatchedBy(func(payment model.Payment) bool {
+		return payment.PaymentLink == snapUrl
+	})).Return(nil)
+
+	result, err := orderUsecaseTest.CreateOrder(customerId, orderDTO)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+
+	assert.Equal(t, float32(75000), result["total_payments"])
+	assert.Equal(t, snapUrl, result["payment_link"])
+}
+
+func TestOrderUsecase_CreateOrderGatewayTimeout(t *testing.T) {
+	customerId := "9b3f6a1d-4c2e-4d8a-b7f0-2e5c8a1d3f64"
+
+	customer := &model.User{
+		ID:              customerId,
+		Role:            "customer",
+		Email:           "timeout@mail.com",
+		EmailVerifiedAt: &emailVerifiedAt,
+	}
+
+	pkg.UserRepository.Mock.On("FindById", customerId).Return(customer, nil)
+
+	bikeId := "5e7a9c1b-3d5f-4a7b-9c1d-3e5f7a9b1c2d"
+
+	bike := &model.Bike{
+		ID:           bikeId,
+		RenterId:     "7c9e1a3b-5d7f-4b9d-8e1a-3c5e7a9b1d3f",
+		PricePerHour: 10000,
+		IsAvailable:  "1",
+	}
+
+	startAt := time.Now().Add(72 * time.Hour).Truncate(time.Hour)
+	endAt := startAt.Add(2 * time.Hour)
+
+	orderDTO := dto.OrderDTO{
+		BikeIds:     []string{bikeId},
+		StartAt:     startAt,
+		EndAt:       endAt,
+		PaymentType: "gopay",
+	}
+
+	pkg.BikeRepository.Mock.On("FindByIdsForUpdate", []string{bikeId}).Return(&[]model.Bike{*bike}, nil)
+	pkg.BikeRepository.Mock.On("FindById", bikeId).Return(bike, nil)
+	pkg.BikeRepository.Mock.On("FindOverlappingOrders", bikeId, startAt, endAt).Return(&[]model.Order{}, nil)
+	pkg.PricingRuleRepository.Mock.On("FindActive", bike.RenterId, startAt, endAt).Return(&[]model.PricingRule{}, nil)
+	pkg.RenterRepository.Mock.On("FindById", bike.RenterId).Return(&model.Renter{ID: bike.RenterId}, nil)
+
+	// the ids are made by the usecase, they are kept to mock the cancellation of the order
+	var orderId string
+	pendingPayment := &model.Payment{PaymentStatus: model.PaymentStatusPending}
+
+	pkg.PaymentRepository.Mock.On("Create", mock.MatchedBy(func(payment model.Payment) bool {
+		if payment.PaymentStatus == "pending" && payment.PaymentType == "gopay" {
+			pendingPayment.ID = payment.ID
+			return true
+		}
+
+		return false
+	})).Return(nil)
+
+	pkg.OrderRepository.Mock.On("Create", mock.MatchedBy(func(order model.Order) bool {
+		if order.UserId == customerId {
+			orderId = order.ID
+			return true
+		}
+
+		return false
+	})).Return(nil)
+
+	pkg.OrderDetailRepository.Mock.On("Create", mock.MatchedBy(func(details []model.OrderDetail) bool {
+		return len(details) == 1 && details[0].BikeId == bikeId
+	})).Return(nil)
+
+	pkg.OrderStatusHistoryRepository.Mock.On("Create", mock.MatchedBy(func(statusHistory model.OrderStatusHistory) bool {
+		return statusHistory.Actor == customerId
+	})).Return(nil)
+
+	// the gateway times out after the order is committed, so the order is canceled and gives its bike back
+	paymentGateway.Mock.On("CreateUrlTransactionWithGateway", mock.MatchedBy(func(req dto.PaymentGateway) bool {
+		return req.Email == customer.Email
+	})).Return("", &payment.GatewayError{Kind: pkg.ErrGatewayTimeout, StatusCode: 504, Message: "timeout"})
+
+	isOrder := mock.MatchedBy(func(id string) bool { return id != "" && id == orderId })
+	isPayment := mock.MatchedBy(func(id string) bool { return id != "" && id == pendingPayment.ID })
+
+	pkg.PaymentRepository.Mock.On("FindByIdForUpdate", isPayment).Return(pendingPayment, nil)
+	pkg.OrderRepository.Mock.On("Update", isOrder, mock.MatchedBy(func(order model.Order) bool {
+		return order.Status == model.OrderStatusCanceled
+	})).Return(nil)
+	pkg.OrderStatusHistoryRepository.Mock.On("Create", mock.MatchedBy(func(statusHistory model.OrderStatusHistory) bool {
+		return statusHistory.OrderId == orderId && statusHistory.ToStatus == model.OrderStatusCanceled && statusHistory.Actor == "system"
+	})).Return(nil)
+	pkg.HistoryRepository.Mock.On("FindByIdOrder", isOrder).Return(&model.History{RentStatus: "pending_payment"}, nil)
+	pkg.HistoryRepository.Mock.On("Update", isOrder, mock.Anything).Return(nil)
+	pkg.PaymentRepository.Mock.On("Update", isPayment, mock.MatchedBy(func(payment model.Payment) bool {
+		return payment.PaymentStatus == model.PaymentStatusCancel
+	})).Return(nil)
+
+	result, err := orderUsecaseTest.CreateOrder(customerId, orderDTO)
+
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, pkg.ErrGatewayTimeout))
+	assert.True(t, errors.Is(err, pkg.ErrPaymentGateway))
+	assert.Equal(t, model.PaymentStatusCancel, pendingPayment.PaymentStatus)
+	pkg.OrderStatusHistoryRepository.Mock.AssertCalled(t, "Create", mock.MatchedBy(func(statusHistory model.OrderStatusHistory) bool {
+		return statusHistory.OrderId == orderId && statusHistory.ToStatus == model.OrderStatusCanceled
+	}))
+}
+
+func TestOrderUsecase_CreateOrderWithWallet(t *testing.T) {
+	customerId := "3d8f2b6a-1c4e-4f7a-9b2d-6e8a1c3f5b7d"
+
+	customer := &model.User{
+		ID:              customerId,
+		Role:            "customer",
+		Email:           "wallet@mail.com",
+		EmailVerifiedAt: &emailVerifiedAt,
+	}
+
+	pkg.UserRepository.Mock.On("FindById", customerId).Return(customer, nil)
+
+	bikeId := "4e9a3c7b-2d5f-4a8b-8c3e-7f9b2d4a6c8e"
+
+	bike := &model.Bike{
+		ID:           bikeId,
+		RenterId:     "5f1b4d8c-3e6a-4b9c-9d4f-8a1c3e5b7d9f",
+		PricePerHour: 15000,
+		IsAvailable:  "1",
+	}
+
+	startAt := time.Now().Add(96 * time.Hour).Truncate(time.Hour)
+	endAt := startAt.Add(5 * time.Hour)
+
+	orderDTO := dto.OrderDTO{
+		BikeIds:     []string{bikeId},
+		StartAt:     startAt,
+		EndAt:       endAt,
+		PaymentType: model.PaymentTypeWallet,
+	}
+
+	pkg.BikeRepository.Mock.On("FindByIdsForUpdate", []string{bikeId}).Return(&[]model.Bike{*bike}, nil)
+	pkg.BikeRepository.Mock.On("FindById", bikeId).Return(bike, nil)
+	pkg.BikeRepository.Mock.On("FindOverlappingOrders", bikeId, startAt, endAt).Return(&[]model.Order{}, nil)
+	pkg.PricingRuleRepository.Mock.On("FindActive", bike.RenterId, startAt, endAt).Return(&[]model.PricingRule{}, nil)
+	pkg.RenterRepository.Mock.On("FindById", bike.RenterId).Return(&model.Renter{ID: bike.RenterId}, nil)
+
+	pkg.PaymentRepository.Mock.On("Create", mock.MatchedBy(func(payment model.Payment) bool {
+		return payment.PaymentStatus == "pending" && payment.PaymentType == model.PaymentTypeWallet
+	})).Return(nil)
+
+	// the order id is only known once the order is created
+	var orderId string
+
+	pkg.OrderRepository.Mock.On("Create", mock.MatchedBy(func(order model.Order) bool {
+		if order.UserId != customerId {
+			return false
+		}
+
+		orderId = order.ID
+
+		return true
+	})).Return(nil)
+
+	isOrder := mock.MatchedBy(func(id string) bool {
+		return id != "" && id == orderId
+	})
+
+	pkg.OrderDetailRepository.Mock.On("Create", mock.MatchedBy(func(details []model.OrderDetail) bool {
+		return len(details) == 1 && details[0].BikeId == bikeId
+	})).Return(nil)
+	pkg.HistoryRepository.Mock.On("Create", mock.MatchedBy(func(history model.History) bool {
+		return history.OrderId == orderId
+	})).Return(nil)
+	pkg.OrderDetailRepository.Mock.On("FindByIdOrder", isOrder).Return(&[]model.OrderDetail{
+		{BikeId: bikeId, Subtotal: 75000, Bike: bike},
+	}, nil)
+
+	pkg.OrderStatusHistoryRepository.Mock.On("Create", mock.MatchedBy(func(statusHistory model.OrderStatusHistory) bool {
+		return statusHistory.Actor == customerId
+	})).Return(nil)
+
+	wallet := &model.Wallet{
+		ID:      "6a2c5e9d-4f7b-4c1d-8e5a-9b2d4f6a8c1e",
+		UserId:  customerId,
+		Balance: 100000,
+	}
+
+	pkg.WalletRepository.Mock.On("FindByIdUserForUpdate", customerId).Return(wallet, nil)
+	pkg.WalletRepository.Mock.On("UpdateBalance", wallet.ID, mock.MatchedBy(func(walletUC model.Wallet) bool {
+		return walletUC.Balance == 25000
+	})).Return(nil)
+	pkg.WalletRepository.Mock.On("CreateTransaction", mock.MatchedBy(func(transaction model.WalletTransaction) bool {
+		return transaction.WalletId == wallet.ID && transaction.Type == model.WalletTransactionPayment && transaction.Amount == -75000 && transaction.BalanceAfter == 25000
+	})).Return(nil)
+
+	pkg.PaymentRepository.Mock.On("Update", mock.Anything, mock.MatchedBy(func(payment model.Payment) bool {
+		return payment.PaymentType == model.PaymentTypeWallet && payment.PaymentStatus == model.PaymentStatusSettlement
+	})).Return(nil)
+
+	// the money is taken from the customer wallet, not the payment gateway
+	pkg.LedgerRepository.Mock.On("Create", mock.MatchedBy(func(entries []model.LedgerEntry) bool {
+		return len(entries) == 2 && entries[0].Account == model.LedgerAccountCustomerWallet && entries[0].Debit == 75000 &&
+			entries[1].Account == model.LedgerAccountRenterPayable && entries[1].RenterId == bike.RenterId && entries[1].Credit == 75000
+	})).Return(nil)
+
+	pkg.OrderRepository.Mock.On("Update", isOrder, mock.MatchedBy(func(orderUC model.Order) bool {
+		return orderUC.Status == model.OrderStatusPaid
+	})).Return(nil)
+	pkg.HistoryRepository.Mock.On("FindByIdOrder", isOrder).Return(&model.History{RentStatus: "pending_payment"}, nil)
+	pkg.HistoryRepository.Mock.On("Update", isOrder, mock.Anything).Return(nil)
+
+	result, err := orderUsecaseTest.CreateOrder(customerId, orderDTO)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+
+	assert.Equal(t, model.OrderStatusPaid, result["status"])
+	assert.Equal(t, "", result["payment_link"])
+	assert.Equal(t, float32(25000), wallet.Balance)
+	paymentGateway.Mock.AssertNotCalled(t, "CreateUrlTransactionWithGateway", mock.MatchedBy(func(req dto.PaymentGateway) bool {
+		return req.Email == customer.Email
+	}))
+}
+
+func TestOrderUsecase_CreateOrderWithWalletInsufficientBalance(t *testing.T) {
+	customerId := "7b3d6f1e-5a8c-4d2e-9f6b-1c3e5a7d9b2f"
+
+	customer := &model.User{
+		ID:              customerId,
+		Role:            "customer",
+		Email:           "empty-wallet@mail.com",
+		EmailVerifiedAt: &emailVerifiedAt,
+	}
+
+	pkg.UserRepository.Mock.On("FindById", customerId).Return(customer, nil)
+
+	bikeId := "8c4e7a2f-6b9d-4e3f-8a7c-2d4f6b8e1a3c"
+
+	bike := &model.Bike{
+		ID:           bikeId,
+		RenterId:     "9d5f8b3a-7c1e-4f4a-9b8d-3e5a7c9f2b4d",
+		PricePerHour: 15000,
+		IsAvailable:  "1",
+	}
+
+	startAt := time.Now().Add(120 * time.Hour).Truncate(time.Hour)
+	endAt := startAt.Add(2 * time.Hour)
+
+	orderDTO := dto.OrderDTO{
+		BikeIds:     []string{bikeId},
+		StartAt:     startAt,
+		EndAt:       endAt,
+		PaymentType: model.PaymentTypeWallet,
+	}
+
+	pkg.BikeRepository.Mock.On("FindByIdsForUpdate", []string{bikeId}).Return(&[]model.Bike{*bike}, nil)
+	pkg.BikeRepository.Mock.On("FindById", bikeId).Return(bike, nil)
+	pkg.BikeRepository.Mock.On("FindOverlappingOrders", bikeId, startAt, endAt).Return(&[]model.Order{}, nil)
+	pkg.PricingRuleRepository.Mock.On("FindActive", bike.RenterId, startAt, endAt).Return(&[]model.PricingRule{}, nil)
+	pkg.RenterRepository.Mock.On("FindById", bike.RenterId).Return(&model.Renter{ID: bike.RenterId}, nil)
+
+	pkg.PaymentRepository.Mock.On("Create", mock.MatchedBy(func(payment model.Payment) bool {
+		return payment.PaymentType == model.PaymentTypeWallet && payment.Amount == 30000
+	})).Return(nil)
+	pkg.OrderRepository.Mock.On("Create", mock.MatchedBy(func(order model.Order) bool {
+		return order.UserId == customerId
+	})).Return(nil)
+	pkg.HistoryRepository.Mock.On("Create", mock.Anything).Return(nil).Once()
+	pkg.OrderDetailRepository.Mock.On("Create", mock.MatchedBy(func(details []model.OrderDetail) bool {
+		return len(details) == 1 && details[0].BikeId == bikeId
+	})).Return(nil)
+	pkg.OrderStatusHistoryRepository.Mock.On("Create", mock.MatchedBy(func(statusHistory model.OrderStatusHistory) bool {
+		return statusHistory.Actor == customerId
+	})).Return(nil)
+
+	wallet := &model.Wallet{
+		ID:      "1e6a9c4b-8d2f-4a5b-8c9e-4f6b8d1a3c5e",
+		UserId:  customerId,
+		Balance: 10000,
+	}
+
+	pkg.WalletRepository.Mock.On("FindByIdUserForUpdate", customerId).Return(wallet, nil)
+
+	// the whole order is rolled back, the balance is never touched
+	result, err := orderUsecaseTest.CreateOrder(customerId, orderDTO)
+
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, pkg.ErrInsufficientBalance))
+	assert.Equal(t, float32(10000), wallet.Balance)
+	pkg.WalletRepository.Mock.AssertNotCalled(t, "UpdateBalance", wallet.ID, mock.Anything)
+}
+
+func TestOrderUsecase_CreateOrderWithDeposit(t *testing.T) {
+	customerId := "1b3d5f7a-9c2e-4a6b-8d1f-3a5c7e9b2d4f"
+
+	customer := &model.User{
+		ID:              customerId,
+		Role:            "customer",
+		Email:           "deposit@mail.com",
+		EmailVerifiedAt: &emailVerifiedAt,
+	}
+
+	pkg.UserRepository.Mock.On("FindById", customerId).Return(customer, nil)
+
+	bikeId := "2c4e6a8b-0d3f-4b7c-9e2a-4b6d8f0c3e5a"
+
+	// the bike has no deposit of its own, so the deposit of its category is charged
+	bike := &model.Bike{
+		ID:           bikeId,
+		RenterId:     "3d5f7b9c-1e4a-4c8d-8f3b-5c7e9a1d4f6b",
+		CategoryId:   "4e6a8c0d-2f5b-4d9e-9a4c-6d8f0b2e5a7c",
+		Name:         "Sample Road Bike",
+		PricePerHour: 15000,
+		IsAvailable:  "1",
+		Category: model.Category{
+			ID:      "4e6a8c0d-2f5b-4d9e-9a4c-6d8f0b2e5a7c",
+			Name:    "Road",
+			Deposit: 50000,
+		},
+	}
+
+	startAt := time.Now().Add(120 * time.Hour).Truncate(time.Hour)
+	endAt := startAt.Add(5 * time.Hour)
+
+	orderDTO := dto.OrderDTO{
+		BikeIds:     []string{bikeId},
+		StartAt:     startAt,
+		EndAt:       endAt,
+		PaymentType: "bank_transfer",
+	}
+
+	pkg.BikeRepository.Mock.On("FindByIdsForUpdate", []string{bikeId}).Return(&[]model.Bike{*bike}, nil)
+	pkg.BikeRepository.Mock.On("FindById", bikeId).Return(bike, nil)
+	pkg.BikeRepository.Mock.On("FindOverlappingOrders", bikeId, startAt, endAt).Return(&[]model.Order{}, nil)
+	pkg.PricingRuleRepository.Mock.On("FindActive", bike.RenterId, startAt, endAt).Return(&[]model.PricingRule{}, nil)
+	pkg.RenterRepository.Mock.On("FindById", bike.RenterId).Return(&model.Renter{ID: bike.RenterId}, nil)
+
+	pkg.PaymentRepository.Mock.On("Create", mock.MatchedBy(func(payment model.Payment) bool {
+		return payment.Amount == 125000 && payment.Deposit == 50000
+	})).Return(nil)
+
+	pkg.OrderRepository.Mock.On("Create", mock.MatchedBy(func(order model.Order) bool {
+		return order.UserId == customerId && order.TotalPayment == 75000 && order.Deposit == 50000 && order.DepositStatus == model.DepositStatusHeld
+	})).Return(nil)
+
+	pkg.OrderDetailRepository.Mock.On("Create", mock.MatchedBy(func(details []model.OrderDetail) bool {
+		return len(details) == 1 && details[0].BikeId == bikeId && details[0].Subtotal == 75000 && details[0].Deposit == 50000
+	})).Return(nil)
+
+	pkg.HistoryRepository.Mock.On("Create", mock.MatchedBy(func(history model.History) bool {
+		return history.RentStatus == "pending_payment"
+	})).Return(nil)
+
+	pkg.OrderStatusHistoryRepository.Mock.On("Create", mock.MatchedBy(func(statusHistory model.OrderStatusHistory) bool {
+		return statusHistory.Actor == customerId
+	})).Return(nil)
+
+	snapUrl := "https://app.sandbox.midtrans.com/snap/v3/redirection/d4e5f6"
+
+	// the deposit is its own item, so the items still add up to the gross amount
+	paymentGateway.Mock.On("CreateUrlTransactionWithGateway", mock.MatchedBy(func(req dto.PaymentGateway) bool {
+		if req.Email != customer.Email || req.GrossAmt != 125000 {
+			return false
+		}
+
+		var total int64
+		for _, item := range req.Items {
+			total += item.Price * int64(item.Qty)
+		}
+
+		return total == req.GrossAmt && req.Items[len(req.Items)-1].Name == "Deposit Sample Road Bike"
+	})).Return(snapUrl, nil)
+
+	pkg.PaymentRepository.Mock.On("Update", mock.Anything, mock.MatchedBy(func(payment model.Payment) bool {
+		return payment.PaymentLink == snapUrl
+	})).Return(nil)
+
+	result, err := orderUsecaseTest.CreateOrder(customerId, orderDTO)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+
+	assert.Equal(t, float32(75000), result["total_payments"])
+	assert.Equal(t, float32(50000), result["deposit"])
+	assert.Equal(t, snapUrl, result["payment_link"])
+}
+
+func TestOrderUsecase_CreateOrderWithVoucher(t *testing.T) {
+	customerId := "6f0b1c0e-2b8c-4f5e-9a51-6c1e0d7a3b21"
+
+	customer := &model.User{
+		ID:              customerId,
+		Role:            "customer",
+		Email:           "voucher@mail.com",
+		EmailVerifiedAt: &emailVerifiedAt,
+	}
+
+	pkg.UserRepository.Mock.On("FindById", customerId).Return(customer, nil)
+
+	bikeId := "0a8c2d55-1f3e-4b7a-8c90-d2e4f6a8b0c1"
+
+	bike := &model.Bike{
+		ID:           bikeId,
+		RenterId:     "3d7e9f11-5a2b-4c6d-8e0f-1a2b3c4d5e6f",
+		PricePerHour: 20000,
+		IsAvailable:  "1",
+	}
+
+	startAt := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	endAt := startAt.Add(5 * time.Hour)
+
+	orderDTO := dto.OrderDTO{
+		BikeIds:     []string{bikeId},
+		StartAt:     startAt,
+		EndAt:       endAt,
+		PaymentType: "bank_transfer",
+		VoucherCode: " hemat10 ",
+	}
+
+	voucher := &model.Voucher{
+		ID:            "1b2c3d4e-5f60-4718-a9b0-c1d2e3f4a5b6",
+		RenterId:      bike.RenterId,
+		Code:          "HEMAT10",
+		DiscountType:  model.VoucherDiscountPercentage,
+		DiscountValue: 10,
+		MaxDiscount:   8000,
+		MinSpend:      50000,
+		UsageLimit:    100,
+		PerUserLimit:  1,
+		StartAt:       time.Now().Add(-24 * time.Hour),
+		EndAt:         time.Now().Add(24 * time.Hour),
+	}
+
+	pkg.BikeRepository.Mock.On("FindByIdsForUpdate", []string{bikeId}).Return(&[]model.Bike{*bike}, nil)
+	pkg.BikeRepository.Mock.On("FindById", bikeId).Return(bike, nil)
+	pkg.BikeRepository.Mock.On("FindOverlappingOrders", bikeId, startAt, endAt).Return(&[]model.Order{}, nil)
+	pkg.PricingRuleRepository.Mock.On("FindActive", bike.RenterId, startAt, endAt).Return(&[]model.PricingRule{}, nil)
+	pkg.RenterRepository.Mock.On("FindById", bike.RenterId).Return(&model.Renter{ID: bike.RenterId}, nil)
+
+	pkg.VoucherRepository.Mock.On("FindByCodeForUpdate", "HEMAT10").Return(voucher, nil)
+	pkg.VoucherRepository.Mock.On("CountUsages", voucher.ID, "").Return(int64(10), nil)
+	pkg.VoucherRepository.Mock.On("CountUsages", voucher.ID, customerId).Return(int64(0), nil)
+
+	pkg.PaymentRepository.Mock.On("Create", mock.MatchedBy(func(payment model.Payment) bool {
+		return payment.PaymentStatus == "pending" && payment.PaymentType == "bank_transfer"
+	})).Return(nil)
+
+	// 10% of 100000 is capped at 8000
+	pkg.OrderRepository.Mock.On("Create", mock.MatchedBy(func(order model.Order) bool {
+		return order.UserId == customerId && order.TotalPayment == 92000 && order.Discount == 8000
+	})).Return(nil)
+
+	pkg.VoucherRepository.Mock.On("CreateUsage", mock.MatchedBy(func(usage model.VoucherUsage) bool {
+		return usage.VoucherId == voucher.ID && usage.UserId == customerId && usage.Discount == 8000
+	})).Return(nil)
+
+	pkg.OrderDetailRepository.Mock.On("Create", mock.MatchedBy(func(details []model.OrderDetail) bool {
+		return len(details) == 1 && details[0].BikeId == bikeId && details[0].Subtotal == 100000
+	})).Return(nil)
+
+	pkg.HistoryRepository.Mock.On("Create", mock.Anything).Return(nil)
+	pkg.OrderStatusHistoryRepository.Mock.On("Create", mock.Anything).Return(nil)
+
+	snapUrl := "https://app.sandbox.midtrans.com/snap/v3/redirection/v0uch3r"
+
+	paymentGateway.Mock.On("CreateUrlTransactionWithGateway", mock.MatchedBy(func(req dto.PaymentGateway) bool {
+		var sum int64
+		for i := range req.Items {
+			sum += req.Items[i].Price * int64(req.Items[i].Qty)
+		}
+
+		last := req.Items[len(req.Items)-1]
+
+		return req.Email == customer.Email && req.GrossAmt == 92000 && sum == req.GrossAmt && last.Name == "Voucher HEMAT10" && last.Price == -8000
+	})).Return(snapUrl, nil)
+
+	pkg.PaymentRepository.Mock.On("Update", mock.Anything, mock.MatchedBy(func(payment model.Payment) bool {
+		return payment.PaymentLink == snapUrl
+	})).Return(nil)
+
+	result, err := orderUsecaseTest.CreateOrder(customerId, orderDTO)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+
+	assert.Equal(t, float32(92000), result["total_payments"])
+	assert.Equal(t, float32(8000), result["discount"])
+}
+
+func TestOrderUsecase_CreateOrderVoucherNotApplicable(t *testing.T) {
+	customerId := "9e8d7c6b-5a49-4382-b1a0-f9e8d7c6b5a4"
+
+	pkg.UserRepository.Mock.On("FindById", customerId).Return(&model.User{ID: customerId, Role: "customer", EmailVerifiedAt: &emailVerifiedAt}, nil)
+
+	bikeId := "7c6b5a49-3827-4160-a5f4-e3d2c1b0a9f8"
+
+	bike := &model.Bike{
+		ID:           bikeId,
+		RenterId:     "5a493827-1605-4f4e-8d2c-1b0a9f8e7d6c",
+		PricePerHour: 20000,
+		IsAvailable:  "1",
+	}
+
+	startAt := time.Now().Add(72 * time.Hour).Truncate(time.Hour)
+	endAt := startAt.Add(2 * time.Hour)
+
+	pkg.BikeRepository.Mock.On("FindByIdsForUpdate", []string{bikeId}).Return(&[]model.Bike{*bike}, nil)
+	pkg.BikeRepository.Mock.On("FindById", bikeId).Return(bike, nil)
+	pkg.BikeRepository.Mock.On("FindOverlappingOrders", bikeId, startAt, endAt).Return(&[]model.Order{}, nil)
+	pkg.PricingRuleRepository.Mock.On("FindActive", bike.RenterId, startAt, endAt).Return(&[]model.PricingRule{}, nil)
+	pkg.RenterRepository.Mock.On("FindById", bike.RenterId).Return(&model.Renter{ID: bike.RenterId}, nil)
+
+	testCases := []struct {
+		Name    string
+		Code    string
+		Voucher *model.Voucher
+		Err     error
+	}{
+		{
+			Name: "not found",
+			Code: "NOPE",
+			Err:  pkg.ErrRecordNotFound,
+		},
+		{
+			Name: "expired",
+			Code: "OLD",
+			Voucher: &model.Voucher{
+				ID: "VID-OLD", Code: "OLD", DiscountType: model.VoucherDiscountFixed, DiscountValue: 5000,
+				StartAt: time.Now().Add(-48 * time.Hour), EndAt: time.Now().Add(-24 * time.Hour),
+			},
+		},
+		{
+			Name: "other renter",
+			Code: "OTHER",
+			Voucher: &model.Voucher{
+				ID: "VID-OTHER", RenterId: "RID-OTHER", Code: "OTHER", DiscountType: model.VoucherDiscountFixed, DiscountValue: 5000,
+				StartAt: time.Now().Add(-24 * time.Hour), EndAt: time.Now().Add(24 * time.Hour),
+			},
+		},
+		{
+			Name: "below min spend",
+			Code: "BIG",
+			Voucher: &model.Voucher{
+				ID: "VID-BIG", Code: "BIG", DiscountType: model.VoucherDiscountFixed, DiscountValue: 5000, MinSpend: 100000,
+				StartAt: time.Now().Add(-24 * time.Hour), EndAt: time.Now().Add(24 * time.Hour),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			pkg.VoucherRepository.Mock.On("FindByCodeForUpdate", tc.Code).Return(tc.Voucher, tc.Err)
+
+			result, err := orderUsecaseTest.CreateOrder(customerId, dto.OrderDTO{
+				BikeIds:     []string{bikeId},
+				StartAt:     startAt,
+				EndAt:       endAt,
+				PaymentType: "bank_transfer",
+				VoucherCode: tc.Code,
+			})
+
+			assert.Nil(t, result)
+			assert.True(t, errors.Is(err, pkg.ErrVoucherNotApplicable))
+		})
+	}
+}
+
+func TestCountDiscount(t *testing.T) {
+	assert.Equal(t, float32(15000), countDiscount(model.Voucher{DiscountType: model.VoucherDiscountPercentage, DiscountValue: 15}, 100000))
+	assert.Equal(t, float32(10000), countDiscount(model.Voucher{DiscountType: model.VoucherDiscountPercentage, DiscountValue: 15, MaxDiscount: 10000}, 100000))
+	assert.Equal(t, float32(25000), countDiscount(model.Voucher{DiscountType: model.VoucherDiscountFixed, DiscountValue: 25000}, 100000))
+	assert.Equal(t, float32(30000), countDiscount(model.Voucher{DiscountType: model.VoucherDiscountFixed, DiscountValue: 50000}, 30000))
+}
+
+// bookingStore keeps bikes and committed orders in memory and hands out one lock per bike,
+// the same way SELECT ... FOR UPDATE holds a bike row until the transaction ends.
+type bookingStore struct {
+	mu        sync.Mutex
+	bikeLocks map[string]*sync.Mutex
+	bikes     map[string]model.Bike
+	orders    []model.Order
+	details   []model.OrderDetail
+}
+
+type bookingTx struct {
+	store   *bookingStore
+	locked  []*sync.Mutex
+	orders  []model.Order
+	details []model.OrderDetail
+}
+
+type bookingUnitOfWork struct {
+	store *bookingStore
+}
+
+func (u bookingUnitOfWork) WithTx(fn func(repos repository.Repositories) error) error {
+	tx := &bookingTx{store: u.store}
+
+	err := fn(repository.Repositories{
+		Bike:               txBikeRepository{tx: tx},
+		Order:              txOrderRepository{tx: tx},
+		OrderDetail:        txOrderDetailRepository{tx: tx},
+		Payment:            txPaymentRepository{},
+		History:            txHistoryRepository{},
+		OrderStatusHistory: txOrderStatusHistoryRepository{},
+		PricingRule:        txPricingRuleRepository{},
+		Renter:             &pkg.RenterRepository,
+	})
+
+	if err == nil {
+		tx.store.mu.Lock()
+		tx.store.orders = append(tx.store.orders, tx.orders...)
+		tx.store.details = append(tx.store.details, tx.details...)
+		tx.store.mu.Unlock()
+	}
+
+	for i := range tx.locked {
+		tx.locked[i].Unlock()
+	}
+
+	return err
+}
+
+type txBikeRepository struct {
+	repository.BikeRepository
+	tx *bookingTx
+}
+
+func (r txBikeRepository) FindById(bikeId string) (*model.Bike, error) {
+	bike := r.tx.store.bikes[bikeId]
+
+	return &bike, nil
+}
+
+func (r txBikeRepository) FindByIdsForUpdate(bikeIds []string) (*[]model.Bike, error) {
+	bikes := []model.Bike{}
+
+	for i := range bikeIds {
+		lock := r.tx.store.bikeLocks[bikeIds[i]]
+		lock.Lock()
+
+		r.tx.locked = append(r.tx.locked, lock)
+		bikes = append(bikes, r.tx.store.bikes[bikeIds[i]])
+	}
+
+	return &bikes, nil
+}
+
+func (r txBikeRepository) FindOverlappingOrders(bikeId string, startAt time.Time, endAt time.Time) (*[]model.Order, error) {
+	// widen the gap between reading the schedule and committing the order
+	defer time.Sleep(5 * time.Millisecond)
+
+	r.tx.store.mu.Lock()
+	defer r.tx.store.mu.Unlock()
+
+	orders := []model.Order{}
+	for i := range r.tx.store.details {
+		if r.tx.store.details[i].BikeId != bikeId {
+			continue
+		}
+
+		for j := range r.tx.store.orders {
+			order := r.tx.store.orders[j]
+
+			if order.ID == r.tx.store.details[i].OrderId && order.Status.HoldsBikes() && order.StartAt.Before(endAt) && order.EndAt.After(startAt) {
+				orders = append(orders, order)
+			}
+		}
+	}
+
+	return &orders, nil
+}
+
+type txOrderRepository struct {
+	repository.OrderRepository
+	tx *bookingTx
+}
+
+func (r txOrderRepository) Create(orderUC model.Order) error {
+	r.tx.orders = append(r.tx.orders, orderUC)
+
+	return nil
+}
+
+type txOrderDetailRepository struct {
+	repository.OrderDetailRepository
+	tx *bookingTx
+}
+
+func (r txOrderDetailRepository) Create(orderDetailUC []model.OrderDetail) error {
+	r.tx.details = append(r.tx.details, orderDetailUC...)
+
+	return nil
+}
+
+type txPaymentRepository struct {
+	repository.PaymentRepository
+}
+
+func (r txPaymentRepository) Create(paymentUC model.Payment) error {
+	return nil
+}
+
+func (r txPaymentRepository) Update(paymentId string, paymentUC model.Payment) error {
+	return nil
+}
+
+type txHistoryRepository struct {
+	repository.HistoryRepository
+}
+
+func (r txHistoryRepository) Create(historyUC model.History) error {
+	return nil
+}
+
+type txOrderStatusHistoryRepository struct {
+	repository.OrderStatusHistoryRepository
+}
+
+func (r txOrderStatusHistoryRepository) Create(statusHistoryUC model.OrderStatusHistory) error {
+	return nil
+}
+
+type txPricingRuleRepository struct {
+	repository.PricingRuleRepository
+}
+
+func (r txPricingRuleRepository) FindActive(renterId string, startAt time.Time, endAt time.Time) (*[]model.PricingRule, error) {
+	return &[]model.PricingRule{}, nil
+}
+
+func TestOrderUsecase_CreateOrderConcurrently(t *testing.T) {
+	bikeId := "0d9c1a6e-3c57-4bb4-8a3c-5f5f4b1e7c2d"
+
+	store := &bookingStore{
+		bikeLocks: map[string]*sync.Mutex{bikeId: {}},
+		bikes: map[string]model.Bike{
+			bikeId: {
+				ID:           bikeId,
+				RenterId:     "ffad8203-b32d-46dd-b488-a700ad61dac7",
+				CategoryId:   "463c249c-300e-4138-8cfb-8a7fa5042da0",
+				Name:         "Sample BMX Bike",
+				PricePerHour: 15000,
+				IsAvailable:  "1",
+			},
+		},
+	}
+
+	customerId := "5f2b0a77-8c1d-4a6e-9b0f-2e7c4d1a9b33"
+
+	pkg.RenterRepository.Mock.On("FindById", "ffad8203-b32d-46dd-b488-a700ad61dac7").Return(&model.Renter{ID: "ffad8203-b32d-46dd-b488-a700ad61dac7"}, nil)
+
+	userRepository := repomock.UserRepositoryMock{Mock: mock.Mock{}}
+	userRepository.Mock.On("FindById", customerId).Return(&model.User{ID: customerId, Email: "race@mail.com", EmailVerifiedAt: &emailVerifiedAt}, nil)
+
+	gateway := paymentmock.PaymentProviderMock{Mock: mock.Mock{}}
+	gateway.Mock.On("CreateUrlTransactionWithGateway", mock.Anything).Return("https://app.sandbox.midtrans.com/snap/v3/redirection/race", nil)
+
+	// the payment link is saved after the booking is committed
+	paymentRepository := repomock.PaymentRepositoryMock{Mock: mock.Mock{}}
+	paymentRepository.Mock.On("Update", mock.Anything, mock.Anything).Return(nil)
+
+	usecase := NewOrderUsecase(
+		bookingUnitOfWork{store: store},
+		&gateway,
+		&pkg.OrderRepository,
+		&pkg.OrderDetailRepository,
+		&userRepository,
+		&pkg.BikeRepository,
+		&paymentRepository,
+		&pkg.HistoryRepository,
+		&pkg.OrderStatusHistoryRepository,
+		OrderPolicy{PaymentTTL: time.Hour, LateReturnGrace: 15 * time.Minute},
+		pricing.NewEngine(nil),
+	)
+
+	startAt := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+
+	orderDTO := dto.OrderDTO{
+		BikeIds:     []string{bikeId},
+		StartAt:     startAt,
+		EndAt:       startAt.Add(3 * time.Hour),
+		PaymentType: "bank_transfer",
+	}
+
+	const attempts = 10
+
+	var wg sync.WaitGroup
+	errs := make(chan error, attempts)
+
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := usecase.CreateOrder(customerId, orderDTO)
+			errs <- err
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	conflicted := 0
+	for err := range errs {
+		if err == nil {
+			succeeded++
+		} else if errors.Is(err, pkg.ErrBookingConflict) {
+			conflicted++
+		}
+	}
+
+	assert.Equal(t, 1, succeeded)
+	assert.Equal(t, attempts-1, conflicted)
+	assert.Len(t, store.orders, 1)
+}
+
+func TestOrderUsecase_PickupBike(t *testing.T) {
+	orderId := "0b3c9a2e-8f41-4f7e-a6a5-2b9d7c1e4f60"
+	actorId := "ffad8203-b32d-46dd-b488-a700ad61dac7"
+
+	order := &model.Order{
+		ID:           "0b3c9a2e-8f41-4f7e-a6a5-2b9d7c1e4f60",
+		UserId:       "02629953-7ac7-4c77-83c0-136a0f252427",
+		PaymentId:    "8807fa35-2305-47e1-86ed-d95deed566e4",
+		TotalPayment: 75000,
+		TotalQty:     1,
+		TotalHour:    5,
+		Status:       model.OrderStatusPaid,
+	}
+
+	pkg.OrderRepository.Mock.On("FindById", orderId).Return(order, nil)
+	pkg.OrderRepository.Mock.On("Update", orderId, mock.MatchedBy(func(orderUC model.Order) bool {
+		return orderUC.Status == model.OrderStatusPickedUp
+	})).Return(nil)
+
+	pkg.OrderStatusHistoryRepository.Mock.On("Create", mock.MatchedBy(func(statusHistory model.OrderStatusHistory) bool {
+		return statusHistory.OrderId == orderId && statusHistory.FromStatus == model.OrderStatusPaid && statusHistory.ToStatus == model.OrderStatusPickedUp && statusHistory.Actor == actorId
+	})).Return(nil)
+
+	history := &model.History{
+		ID:         "5b7d0c8e-2a8b-4d55-9a0e-3e1f2c4d6a77",
+		OrderId:    "0b3c9a2e-8f41-4f7e-a6a5-2b9d7c1e4f60",
+		RentStatus: "paid",
+	}
+
+	pkg.HistoryRepository.Mock.On("FindByIdOrder", orderId).Return(history, nil)
+	pkg.HistoryRepository.Mock.On("Update", orderId, mock.MatchedBy(func(historyUC model.History) bool {
+		return historyUC.RentStatus == "picked_up"
+	})).Return(nil)
+
+	err := orderUsecaseTest.PickupBike(orderId, actorId)
+
+	assert.Nil(t, err)
+	assert.Equal(t, model.OrderStatusPickedUp, order.Status)
+}
+
+func TestOrderUsecase_UpdateRentStatus(t *testing.T) {
+	orderId := "a1dcbf01-144c-4507-939c-449c18d5fbac"
+	actorId := "ffad8203-b32d-46dd-b488-a700ad61dac7"
+
+	order := &model.Order{
+		ID:           "a1dcbf01-144c-4507-939c-449c18d5fbac",
+		UserId:       "02629953-7ac7-4c77-83c0-136a0f252427",
+		PaymentId:    "8807fa35-2305-47e1-86ed-d95deed566e4",
+		TotalPayment: 200000,
+		TotalQty:     1,
+		TotalHour:    5,
+		StartAt:      time.Now().Add(-4 * time.Hour),
+		EndAt:        time.Now().Add(1 * time.Hour),
+		Status:       model.OrderStatusPickedUp,
+		OrderDetails: []model.OrderDetail{
+			{
+				ID:      "6faa175f-ee36-4489-a7b6-424b82a1b855",
+				OrderId: "a1dcbf01-144c-4507-939c-449c18d5fbac",
+				BikeId:  "aefde097-3145-4961-9eed-9e916b9def36",
+				Bike: &model.Bike{
+					ID:           "aefde097-3145-4961-9eed-9e916b9def36",
+					RenterId:     "ffad8203-b32d-46dd-b488-a700ad61dac7",
+					CategoryId:   "463c249c-300e-4138-8cfb-8a7fa5042da0",
+					Name:         "Sample BMX Bike",
+					PricePerHour: 15000,
+					Condition:    "Good",
+					Description:  "This is a description section",
+					IsAvailable:  "1",
+				},
+			},
+		},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	pkg.OrderRepository.Mock.On("FindById", orderId).Return(order, nil)
+	pkg.OrderRepository.Mock.On("Update", orderId, mock.Anything).Return(nil)
+
+	pkg.OrderStatusHistoryRepository.Mock.On("Create", mock.MatchedBy(func(statusHistory model.OrderStatusHistory) bool {
+		return statusHistory.OrderId == orderId && statusHistory.Actor == actorId
+	})).Return(nil)
+
+	history := &model.History{
+		ID:         "25512ed6-7969-4b84-a099-c4de82968ed7",
+		OrderId:    "a1dcbf01-144c-4507-939c-449c18d5fbac",
+		RentStatus: "picked_up",
+	}
+
+	pkg.HistoryRepository.Mock.On("FindByIdOrder", orderId).Return(history, nil)
+	pkg.HistoryRepository.Mock.On("Update", orderId, mock.Anything).Return(nil)
+
+	data, err := orderUsecaseTest.UpdateRentStatus(orderId, actorId)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, data)
+
+	assert.Equal(t, model.OrderStatusClosed, order.Status)
+	assert.Equal(t, "closed", history.RentStatus)
+	assert.Equal(t, float32(0), data["late_fee"])
+}
+
+func TestOrderUsecase_UpdateRentStatusLate(t *testing.T) {
+	orderId := "b2c3d4e5-f6a7-4b8c-9d0e-1f2a3b4c5d6e"
+	customerId := "c3d4e5f6-a7b8-4c9d-8e0f-2a3b4c5d6e7f"
+	actorId := "ffad8203-b32d-46dd-b488-a700ad61dac7"
+
+	// returned 3 hours and 10 minutes late, which is charged as 4 started hours
+	order := &model.Order{
+		ID:           "b2c3d4e5-f6a7-4b8c-9d0e-1f2a3b4c5d6e",
+		UserId:       "c3d4e5f6-a7b8-4c9d-8e0f-2a3b4c5d6e7f",
+		PaymentId:    "d4e5f6a7-b8c9-4d0e-9f1a-3b4c5d6e7f8a",
+		TotalPayment: 75000,
+		TotalQty:     1,
+		TotalHour:    5,
+		StartAt:      time.Now().Add(-(8*time.Hour + 10*time.Minute)),
+		EndAt:        time.Now().Add(-(3*time.Hour + 10*time.Minute)),
+		Status:       model.OrderStatusPickedUp,
+		OrderDetails: []model.OrderDetail{
+			{
+				ID:      "e5f6a7b8-c9d0-4e1f-8a2b-4c5d6e7f8a9b",
+				OrderId: "b2c3d4e5-f6a7-4b8c-9d0e-1f2a3b4c5d6e",
+				BikeId:  "f6a7b8c9-d0e1-4f2a-9b3c-5d6e7f8a9b0c",
+				Bike: &model.Bike{
+					ID:           "f6a7b8c9-d0e1-4f2a-9b3c-5d6e7f8a9b0c",
+					RenterId:     "ffad8203-b32d-46dd-b488-a700ad61dac7",
+					Name:         "Sample BMX Bike",
+					PricePerHour: 15000,
+				},
+			},
+		},
+	}
+
+	customer := &model.User{
+		ID:       "c3d4e5f6-a7b8-4c9d-8e0f-2a3b4c5d6e7f",
+		Fullname: "Arvin Paundra",
+		Phone:    "0876534321",
+		Role:     "customer",
+		Email:    "arvin@mail.com",
+	}
+
+	pkg.OrderRepository.Mock.On("FindById", orderId).Return(order, nil)
+	pkg.OrderRepository.Mock.On("Update", orderId, mock.Anything).Return(nil)
+	pkg.UserRepository.Mock.On("FindById", customerId).Return(customer, nil)
+
+	pkg.OrderStatusHistoryRepository.Mock.On("Create", mock.MatchedBy(func(statusHistory model.OrderStatusHistory) bool {
+		return statusHistory.OrderId == orderId && statusHistory.ToStatus == model.OrderStatusReturned
+	})).Return(nil)
+	pkg.HistoryRepository.Mock.On("FindByIdOrder", orderId).Return(&model.History{OrderId: orderId, RentStatus: "picked_up"}, nil)
+	pkg.HistoryRepository.Mock.On("Update", orderId, mock.Anything).Return(nil)
+
+	var paymentId string
+	pkg.PaymentRepository.Mock.On("Create", mock.MatchedBy(func(payment model.Payment) bool {
+		if payment.OrderId != orderId || payment.Kind != model.PaymentKindLateFee || payment.Amount != 60000 {
+			return false
+		}
+		paymentId = payment.ID
+		return true
+	})).Return(nil)
+	paymentGateway.Mock.On("CreateUrlTransactionWithGateway", mock.MatchedBy(func(req dto.PaymentGateway) bool {
+		return req.GrossAmt == 60000 && req.OrderId != orderId && req.Items[0].Qty == 4
+	})).Return("https://app.sandbox.midtrans.com/snap/v3/redirection/late-fee", nil)
+	pkg.PaymentRepository.Mock.On("Update", mock.MatchedBy(func(id string) bool {
+		return id != "" && id == paymentId
+	}), mock.MatchedBy(func(payment model.Payment) bool {
+		return payment.PaymentLink == "https://app.sandbox.midtrans.com/snap/v3/redirection/late-fee"
+	})).Return(nil)
+
+	data, err := orderUsecaseTest.UpdateRentStatus(orderId, actorId)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, data)
+
+	assert.Equal(t, model.OrderStatusReturned, order.Status)
+	assert.Equal(t, 4, data["late_hours"])
+	assert.Equal(t, float32(60000), data["late_fee"])
+	assert.Equal(t, "https://app.sandbox.midtrans.com/snap/v3/redirection/late-fee", data["payment_link"])
+	pkg.OrderRepository.Mock.AssertCalled(t, "Update", orderId, mock.MatchedBy(func(orderUC model.Order) bool {
+		return orderUC.ReturnedAt != nil && orderUC.LateFee == 60000
+	}))
+}
+
+func TestOrderUsecase_UpdateRentStatusLateGatewayTimeout(t *testing.T) {
+	orderId := "5ed2abfd-8bb3-4e2f-98a5-a58770c5f55d"
+	customerId := "0affddd9-f7d6-4a68-9431-1beaf5907b29"
+	actorId := "ffad8203-b32d-46dd-b488-a700ad61dac7"
+
+	// returned 2 hours and 10 minutes late, which is charged as 3 started hours
+	order := &model.Order{
+		ID:           orderId,
+		UserId:       customerId,
+		PaymentId:    "a3c49e15-0a92-4d9b-bed2-1e29f0d62dc7",
+		TotalPayment: 40000,
+		TotalQty:     1,
+		TotalHour:    2,
+		StartAt:      time.Now().Add(-(4*time.Hour + 10*time.Minute)),
+		EndAt:        time.Now().Add(-(2*time.Hour + 10*time.Minute)),
+		Status:       model.OrderStatusPickedUp,
+		OrderDetails: []model.OrderDetail{
+			{
+				ID:      "f630a8d0-cacb-4d82-8149-5baca02baf82",
+				OrderId: orderId,
+				BikeId:  "6b442a85-14ef-4253-927f-311c3e21bd04",
+				Bike: &model.Bike{
+					ID:           "6b442a85-14ef-4253-927f-311c3e21bd04",
+					RenterId:     "ffad8203-b32d-46dd-b488-a700ad61dac7",
+					Name:         "Sample Folding Bike",
+					PricePerHour: 20000,
+				},
+			},
+		},
+	}
+
+	pkg.OrderRepository.Mock.On("FindById", orderId).Return(order, nil)
+	pkg.OrderRepository.Mock.On("Update", orderId, mock.Anything).Return(nil)
+	pkg.UserRepository.Mock.On("FindById", customerId).Return(&model.User{ID: customerId, Email: "late@mail.com"}, nil)
+
+	pkg.OrderStatusHistoryRepository.Mock.On("Create", mock.MatchedBy(func(statusHistory model.OrderStatusHistory) bool {
+		return statusHistory.OrderId == orderId && statusHistory.ToStatus == model.OrderStatusReturned
+	})).Return(nil)
+	pkg.HistoryRepository.Mock.On("FindByIdOrder", orderId).Return(&model.History{OrderId: orderId, RentStatus: "picked_up"}, nil)
+	pkg.HistoryRepository.Mock.On("Update", orderId, mock.Anything).Return(nil)
+
+	var paymentId string
+	pkg.PaymentRepository.Mock.On("Create", mock.MatchedBy(func(payment model.Payment) bool {
+		if payment.OrderId != orderId || payment.Kind != model.PaymentKindLateFee || payment.Amount != 60000 {
+			return false
+		}
+		paymentId = payment.ID
+		return true
+	})).Return(nil)
+
+	// the gateway only answers once the return is committed, so the return stands without a payment link
+	paymentGateway.Mock.On("CreateUrlTransactionWithGateway", mock.MatchedBy(func(req dto.PaymentGateway) bool {
+		return req.Email == "late@mail.com"
+	})).Return("", &payment.GatewayError{Kind: pkg.ErrGatewayTimeout, StatusCode: 504, Message: "timeout"})
+
+	data, err := orderUsecaseTest.UpdateRentStatus(orderId, actorId)
+
+	assert.Nil(t, data)
+	assert.ErrorIs(t, err, pkg.ErrGatewayTimeout)
+	assert.ErrorIs(t, err, pkg.ErrPaymentGateway)
+
+	assert.Equal(t, model.OrderStatusReturned, order.Status)
+	assert.NotEmpty(t, paymentId)
+	pkg.PaymentRepository.Mock.AssertNotCalled(t, "Update", paymentId, mock.Anything)
+}
+
+func TestOrderUsecase_UpdateRentStatusUnpaid(t *testing.T) {
+	orderId := "c7e1f5a2-9d3b-4b8e-8f0a-6d2c1b3a4e5f"
+
+	order := &model.Order{
+		ID:           "c7e1f5a2-9d3b-4b8e-8f0a-6d2c1b3a4e5f",
+		UserId:       "02629953-7ac7-4c77-83c0-136a0f252427",
+		PaymentId:    "1f4e3d2c-7b6a-4c5d-9e8f-0a1b2c3d4e5f",
+		TotalPayment: 200000,
+		TotalQty:     1,
+		TotalHour:    5,
+		Status:       model.OrderStatusPendingPayment,
+	}
+
+	pkg.OrderRepository.Mock.On("FindById", orderId).Return(order, nil)
+
+	data, err := orderUsecaseTest.UpdateRentStatus(orderId, "ffad8203-b32d-46dd-b488-a700ad61dac7")
+
+	assert.Nil(t, data)
+	assert.ErrorIs(t, err, pkg.ErrInvalidStatusTransition)
+	assert.Equal(t, model.OrderStatusPendingPayment, order.Status)
+}
+
+func TestOrderUsecase_FindOrderStatusHistories(t *testing.T) {
+	orderId := "9e8d7c6b-5a4f-4e3d-8c2b-1a0f9e8d7c6b"
+
+	order := &model.Order{
+		ID:     "9e8d7c6b-5a4f-4e3d-8c2b-1a0f9e8d7c6b",
+		UserId: "02629953-7ac7-4c77-83c0-136a0f252427",
+		Status: model.OrderStatusPaid,
+	}
+
+	pkg.OrderRepository.Mock.On("FindById", orderId).Return(order, nil)
+
+	statusHistories := &[]model.OrderStatusHistory{
+		{
+			ID:        "7a6b5c4d-3e2f-4a1b-9c8d-7e6f5a4b3c2d",
+			OrderId:   "9e8d7c6b-5a4f-4e3d-8c2b-1a0f9e8d7c6b",
+			ToStatus:  model.OrderStatusPendingPayment,
+			Actor:     "02629953-7ac7-4c77-83c0-136a0f252427",
+			CreatedAt: time.Now(),
+		},
+		{
+			ID:         "1c2d3e4f-5a6b-4c7d-8e9f-0a1b2c3d4e5f",
+			OrderId:    "9e8d7c6b-5a4f-4e3d-8c2b-1a0f9e8d7c6b",
+			FromStatus: model.OrderStatusPendingPayment,
+			ToStatus:   model.OrderStatusPaid,
+			Actor:      "midtrans",
+			CreatedAt:  time.Now(),
+		},
+	}
+
+	pkg.OrderStatusHistoryRepository.Mock.On("FindByIdOrder", orderId).Return(statusHistories, nil)
+
+	results, err := orderUsecaseTest.FindOrderStatusHistories(orderId)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, results)
+
+	assert.Len(t, *results, 2)
+	assert.Equal(t, model.OrderStatusPaid, (*results)[1].ToStatus)
+}
+
+func TestOrderUsecase_CancelOrderPendingPayment(t *testing.T) {
+	orderId := "3c4d5e6f-7a8b-4c9d-8e0f-1a2b3c4d5e6f"
+	customerId := "02629953-7ac7-4c77-83c0-136a0f252427"
+
+	order := &model.Order{
+		ID:           "3c4d5e6f-7a8b-4c9d-8e0f-1a2b3c4d5e6f",
+		UserId:       "02629953-7ac7-4c77-83c0-136a0f252427",
+		PaymentId:    "4d5e6f7a-8b9c-4d0e-9f1a-2b3c4d5e6f7a",
+		TotalPayment: 75000,
+		TotalQty:     1,
+		TotalHour:    5,
+		StartAt:      time.Now().Add(1 * time.Hour),
+		EndAt:        time.Now().Add(6 * time.Hour),
+		Status:       model.OrderStatusPendingPayment,
+	}
+
+	pkg.OrderRepository.Mock.On("FindById", orderId).Return(order, nil)
+	pkg.OrderRepository.Mock.On("FindByIdForUpdate", orderId).Return(order, nil)
+	pkg.OrderRepository.Mock.On("Update", orderId, mock.Anything).Return(nil)
+	pkg.OrderStatusHistoryRepository.Mock.On("Create", mock.MatchedBy(func(statusHistory model.OrderStatusHistory) bool {
+		return statusHistory.OrderId == orderId && statusHistory.ToStatus == model.OrderStatusCanceled && statusHistory.Actor == customerId
+	})).Return(nil)
+	pkg.HistoryRepository.Mock.On("FindByIdOrder", orderId).Return(&model.History{OrderId: orderId, RentStatus: "pending_payment"}, nil)
+	pkg.HistoryRepository.Mock.On("Update", orderId, mock.Anything).Return(nil)
+
+	payment := &model.Payment{
+		ID:            "4d5e6f7a-8b9c-4d0e-9f1a-2b3c4d5e6f7a",
+		PaymentStatus: "pending",
+		PaymentType:   "bank_transfer",
+	}
+
+	pkg.PaymentRepository.Mock.On("FindByIdForUpdate", payment.ID).Return(payment, nil)
+	pkg.PaymentRepository.Mock.On("Update", payment.ID, mock.MatchedBy(func(paymentUC model.Payment) bool {
+		return paymentUC.PaymentStatus == "cancel"
+	})).Return(nil)
+
+	paymentGateway.Mock.On("CancelTransaction", orderId).Return(nil)
+
+	data, err := orderUsecaseTest.CancelOrder(orderId, customerId)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, data)
+
+	assert.Equal(t, model.OrderStatusCanceled, data["status"])
+	assert.Equal(t, float32(0), data["cancellation_fee"])
+	paymentGateway.Mock.AssertNotCalled(t, "RefundTransaction", orderId, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOrderUsecase_CancelOrderPaid(t *testing.T) {
+	customerId := "02629953-7ac7-4c77-83c0-136a0f252427"
+
+	renter := &model.Renter{
+		ID:                     "5e6f7a8b-9c0d-4e1f-8a2b-3c4d5e6f7a8b",
+		UserId:                 "6f7a8b9c-0d1e-4f2a-9b3c-4d5e6f7a8b9c",
+		RentName:               "Twins' Brother Bike Rental",
+		FreeCancellationHours:  24,
+		CancellationFeePercent: 50,
+	}
+
+	pkg.RenterRepository.Mock.On("FindById", renter.ID).Return(renter, nil)
+
+	testCases := []struct {
+		Name                  string
+		OrderId               string
+		PaymentId             string
+		StartAt               time.Time
+		ExpectedFee           float32
+		ExpectedRefund        int64
+		ExpectedPaymentStatus string
+	}{
+		{
+			Name:                  "canceled before the free cancellation window ends",
+			OrderId:               "7a8b9c0d-1e2f-4a3b-8c4d-5e6f7a8b9c0d",
+			PaymentId:             "8b9c0d1e-2f3a-4b4c-9d5e-6f7a8b9c0d1e",
+			StartAt:               time.Now().Add(48 * time.Hour),
+			ExpectedFee:           0,
+			ExpectedRefund:        75000,
+			ExpectedPaymentStatus: "refund",
+		},
+		{
+			Name:                  "canceled inside the fee window",
+			OrderId:               "9c0d1e2f-3a4b-4c5d-8e6f-7a8b9c0d1e2f",
+			PaymentId:             "0d1e2f3a-4b5c-4d6e-9f7a-8b9c0d1e2f3a",
+			StartAt:               time.Now().Add(2 * time.Hour),
+			ExpectedFee:           37500,
+			ExpectedRefund:        37500,
+			ExpectedPaymentStatus: "partial_refund",
+		},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.Name, func(t *testing.T) {
+			order := &model.Order{
+				ID:           v.OrderId,
+				UserId:       customerId,
+				PaymentId:    v.PaymentId,
+				TotalPayment: 75000,
+				TotalQty:     1,
+				TotalHour:    5,
+				StartAt:      v.StartAt,
+				EndAt:        v.StartAt.Add(5 * time.Hour),
+				Status:       model.OrderStatusPaid,
+				OrderDetails: []model.OrderDetail{
+					{
+						ID:       "1e2f3a4b-5c6d-4e7f-8a9b-0c1d2e3f4a5b",
+						OrderId:  v.OrderId,
+						BikeId:   "2f3a4b5c-6d7e-4f8a-9b0c-1d2e3f4a5b6c",
+						Hours:    5,
+						Subtotal: 75000,
+						Bike: &model.Bike{
+							ID:           "2f3a4b5c-6d7e-4f8a-9b0c-1d2e3f4a5b6c",
+							RenterId:     "5e6f7a8b-9c0d-4e1f-8a2b-3c4d5e6f7a8b",
+							Name:         "Sample BMX Bike",
+							PricePerHour: 15000,
+						},
+					},
+				},
+			}
+
+			pkg.OrderRepository.Mock.On("FindById", v.OrderId).Return(order, nil)
+			pkg.OrderRepository.Mock.On("FindByIdForUpdate", v.OrderId).Return(order, nil)
+			pkg.OrderRepository.Mock.On("Update", v.OrderId, mock.Anything).Return(nil)
+			pkg.OrderStatusHistoryRepository.Mock.On("Create", mock.MatchedBy(func(statusHistory model.OrderStatusHistory) bool {
+				return statusHistory.OrderId == v.OrderId && statusHistory.FromStatus == model.OrderStatusPaid
+			})).Return(nil)
+			pkg.HistoryRepository.Mock.On("FindByIdOrder", v.OrderId).Return(&model.History{OrderId: v.OrderId, RentStatus: "paid"}, nil)
+			pkg.HistoryRepository.Mock.On("Update", v.OrderId, mock.Anything).Return(nil)
+
+			payment := &model.Payment{
+				ID:            v.PaymentId,
+				OrderId:       v.OrderId,
+				Kind:          model.PaymentKindRent,
+				Amount:        75000,
+				PaymentStatus: "settlement",
+				PaymentType:   "gopay",
+			}
+
+			pkg.PaymentRepository.Mock.On("FindByIdForUpdate", v.PaymentId).Return(payment, nil)
+			pkg.PaymentRepository.Mock.On("Update", v.PaymentId, mock.MatchedBy(func(paymentUC model.Payment) bool {
+				return paymentUC.PaymentStatus == v.ExpectedPaymentStatus
+			})).Return(nil)
+
+			pkg.RefundRepository.Mock.On("FindByIdPayment", v.PaymentId).Return(&[]model.Refund{}, nil)
+			pkg.RefundRepository.Mock.On("Create", mock.MatchedBy(func(refund model.Refund) bool {
+				return refund.PaymentId == v.PaymentId && refund.Amount == float32(v.ExpectedRefund) && refund.Status == model.RefundStatusPending
+			})).Return(nil)
+
+			paymentGateway.Mock.On("RefundTransaction", v.OrderId, mock.Anything, v.ExpectedRefund, mock.Anything).Return(nil)
+
+			data, err := orderUsecaseTest.CancelOrder(v.OrderId, customerId)
+
+			assert.Nil(t, err)
+			assert.NotNil(t, data)
+
+			assert.Equal(t, model.OrderStatusCanceled, order.Status)
+			assert.Equal(t, v.ExpectedFee, data["cancellation_fee"])
+			assert.Equal(t, float32(v.ExpectedRefund), data["refund_amount"])
+			paymentGateway.Mock.AssertCalled(t, "RefundTransaction", v.OrderId, mock.Anything, v.ExpectedRefund, mock.Anything)
+		})
+	}
+}
+
+func TestOrderUsecase_CancelOrderPaidWithDeposit(t *testing.T) {
+	customerId := "02629953-7ac7-4c77-83c0-136a0f252427"
+	orderId := "be62f5d4-6369-4106-8d62-b4c59a21b51f"
+	paymentId := "48617c20-c59a-4ac1-9093-cbd91937a114"
+
+	renter := &model.Renter{
+		ID:                     "600c3054-8736-4fad-bf69-20d12d858256",
+		FreeCancellationHours:  24,
+		CancellationFeePercent: 50,
+	}
+
+	startAt := time.Now().Add(48 * time.Hour)
+
+	order := &model.Order{
+		ID:            orderId,
+		UserId:        customerId,
+		PaymentId:     paymentId,
+		TotalPayment:  125000,
+		TotalQty:      1,
+		TotalHour:     5,
+		Deposit:       50000,
+		DepositStatus: model.DepositStatusHeld,
+		StartAt:       startAt,
+		EndAt:         startAt.Add(5 * time.Hour),
+		Status:        model.OrderStatusPaid,
+		OrderDetails: []model.OrderDetail{
+			{
+				ID:       "b7fe09ac-3918-4a21-863e-e2fc91b3891a",
+				OrderId:  orderId,
+				BikeId:   "9935ee7e-b2e9-4bd3-920d-e0b5247559e0",
+				Hours:    5,
+				Subtotal: 75000,
+				Bike:     &model.Bike{ID: "9935ee7e-b2e9-4bd3-920d-e0b5247559e0", RenterId: renter.ID},
+			},
+		},
+	}
+
+	payment := &model.Payment{
+		ID:            paymentId,
+		OrderId:       orderId,
+		Kind:          model.PaymentKindRent,
+		Amount:        125000,
+		Deposit:       50000,
+		PaymentStatus: model.PaymentStatusSettlement,
+		PaymentType:   "gopay",
+	}
 
-func TestOrderUsecase_UpdateRentStatus(t *testing.T) {
-	orderId := "a1dcbf01-144c-4507-939c-449c18d5fbac"
+	pkg.RenterRepository.Mock.On("FindById", renter.ID).Return(renter, nil)
+	pkg.OrderRepository.Mock.On("FindById", orderId).Return(order, nil)
+	pkg.OrderRepository.Mock.On("FindByIdForUpdate", orderId).Return(order, nil)
+	pkg.OrderRepository.Mock.On("Update", orderId, mock.Anything).Return(nil)
+	pkg.OrderStatusHistoryRepository.Mock.On("Create", mock.MatchedBy(func(statusHistory model.OrderStatusHistory) bool {
+		return statusHistory.OrderId == orderId
+	})).Return(nil)
+	pkg.HistoryRepository.Mock.On("FindByIdOrder", orderId).Return(&model.History{OrderId: orderId, RentStatus: "paid"}, nil)
+	pkg.HistoryRepository.Mock.On("Update", orderId, mock.Anything).Return(nil)
+	pkg.PaymentRepository.Mock.On("FindByIdForUpdate", paymentId).Return(payment, nil)
+	pkg.PaymentRepository.Mock.On("Update", paymentId, mock.Anything).Return(nil)
+	pkg.RefundRepository.Mock.On("FindByIdPayment", paymentId).Return(&[]model.Refund{}, nil)
+
+	refundIds := map[string]float32{}
+
+	pkg.RefundRepository.Mock.On("Create", mock.MatchedBy(func(refund model.Refund) bool {
+		return refund.PaymentId == paymentId
+	})).Run(func(args mock.Arguments) {
+		refund := args.Get(0).(model.Refund)
+		refundIds[refund.ID] = refund.Amount
+	}).Return(nil)
+
+	// the gateway makes the refund of the rent, then fails on the deposit
+	paymentGateway.Mock.On("RefundTransaction", orderId, mock.Anything, int64(75000), mock.Anything).Return(nil)
+	paymentGateway.Mock.On("RefundTransaction", orderId, mock.Anything, int64(50000), mock.Anything).Return(pkg.ErrGatewayTimeout)
+
+	data, err := orderUsecaseTest.CancelOrder(orderId, customerId)
+
+	// the cancellation is committed before the gateway is called, so its failure undoes nothing
+	assert.Nil(t, err)
+	assert.NotNil(t, data)
+
+	assert.Equal(t, model.OrderStatusCanceled, order.Status)
+	assert.Equal(t, model.DepositStatusReleased, order.DepositStatus)
+	assert.Equal(t, float32(75000), data["refund_amount"])
+
+	// every refund is sent once, under the id it was recorded with
+	assert.Len(t, refundIds, 2)
+
+	for refundId, amount := range refundIds {
+		paymentGateway.Mock.AssertCalled(t, "RefundTransaction", orderId, refundId, int64(amount), mock.Anything)
+	}
+
+	var sent int
+
+	for _, call := range paymentGateway.Mock.Calls {
+		if call.Method == "RefundTransaction" && call.Arguments.String(0) == orderId {
+			sent++
+		}
+	}
+
+	assert.Equal(t, 2, sent)
+}
+
+func TestOrderUsecase_CancelOrderDiscountedDailyRate(t *testing.T) {
+	customerId := "02629953-7ac7-4c77-83c0-136a0f252427"
+	orderId := "c1d2e3f4-a5b6-4c7d-8e9f-0a1b2c3d4e5f"
+	paymentId := "d2e3f4a5-b6c7-4d8e-9f0a-1b2c3d4e5f6a"
+
+	halfFeeRenter := &model.Renter{
+		ID:                     "e3f4a5b6-c7d8-4e9f-8a1b-2c3d4e5f6a7b",
+		RentName:               "Half Fee Bike Rental",
+		FreeCancellationHours:  24,
+		CancellationFeePercent: 50,
+	}
+	fullFeeRenter := &model.Renter{
+		ID:                     "f4a5b6c7-d8e9-4f0a-9b2c-3d4e5f6a7b8c",
+		RentName:               "Full Fee Bike Rental",
+		FreeCancellationHours:  24,
+		CancellationFeePercent: 100,
+	}
+
+	pkg.RenterRepository.Mock.On("FindById", halfFeeRenter.ID).Return(halfFeeRenter, nil)
+	pkg.RenterRepository.Mock.On("FindById", fullFeeRenter.ID).Return(fullFeeRenter, nil)
 
+	// two days at the daily rate cost less than 48 hours at the hourly price, and the voucher took 30000 off
+	startAt := time.Now().Add(2 * time.Hour)
 	order := &model.Order{
-		ID:           "a1dcbf01-144c-4507-939c-449c18d5fbac",
-		UserId:       "02629953-7ac7-4c77-83c0-136a0f252427",
-		PaymentId:    "8807fa35-2305-47e1-86ed-d95deed566e4",
-		TotalPayment: 200000,
-		TotalQty:     1,
-		TotalHour:    5,
+		ID:           orderId,
+		UserId:       customerId,
+		PaymentId:    paymentId,
+		TotalPayment: 270000,
+		Discount:     30000,
+		TotalQty:     2,
+		TotalHour:    48,
+		StartAt:      startAt,
+		EndAt:        startAt.Add(48 * time.Hour),
+		Status:       model.OrderStatusPaid,
 		OrderDetails: []model.OrderDetail{
 			{
-				ID:      "6faa175f-ee36-4489-a7b6-424b82a1b855",
-				OrderId: "a1dcbf01-144c-4507-939c-449c18d5fbac",
-				BikeId:  "aefde097-3145-4961-9eed-9e916b9def36",
+				ID:       "a5b6c7d8-e9f0-4a1b-8c3d-4e5f6a7b8c9d",
+				OrderId:  orderId,
+				BikeId:   "b6c7d8e9-f0a1-4b2c-9d4e-5f6a7b8c9d0e",
+				Hours:    48,
+				Subtotal: 200000,
 				Bike: &model.Bike{
-					ID:           "aefde097-3145-4961-9eed-9e916b9def36",
-					RenterId:     "ffad8203-b32d-46dd-b488-a700ad61dac7",
-					CategoryId:   "463c249c-300e-4138-8cfb-8a7fa5042da0",
-					Name:         "Sample BMX Bike",
+					ID:           "b6c7d8e9-f0a1-4b2c-9d4e-5f6a7b8c9d0e",
+					RenterId:     halfFeeRenter.ID,
+					Name:         "Sample Road Bike",
 					PricePerHour: 15000,
-					Condition:    "Good",
-					Description:  "This is a description section",
-					IsAvailable:  "0",
+				},
+			},
+			{
+				ID:       "c7d8e9f0-a1b2-4c3d-8e5f-6a7b8c9d0e1f",
+				OrderId:  orderId,
+				BikeId:   "d8e9f0a1-b2c3-4d4e-9f6a-7b8c9d0e1f2a",
+				Hours:    48,
+				Subtotal: 100000,
+				Bike: &model.Bike{
+					ID:           "d8e9f0a1-b2c3-4d4e-9f6a-7b8c9d0e1f2a",
+					RenterId:     fullFeeRenter.ID,
+					Name:         "Sample City Bike",
+					PricePerHour: 10000,
 				},
 			},
 		},
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
 	}
 
 	pkg.OrderRepository.Mock.On("FindById", orderId).Return(order, nil)
+	pkg.OrderRepository.Mock.On("FindByIdForUpdate", orderId).Return(order, nil)
+	pkg.OrderRepository.Mock.On("Update", orderId, mock.Anything).Return(nil)
+	pkg.OrderStatusHistoryRepository.Mock.On("Create", mock.MatchedBy(func(statusHistory model.OrderStatusHistory) bool {
+		return statusHistory.OrderId == orderId && statusHistory.FromStatus == model.OrderStatusPaid
+	})).Return(nil)
+	pkg.HistoryRepository.Mock.On("FindByIdOrder", orderId).Return(&model.History{OrderId: orderId, RentStatus: "paid"}, nil)
+	pkg.HistoryRepository.Mock.On("Update", orderId, mock.Anything).Return(nil)
+
+	payment := &model.Payment{
+		ID:            paymentId,
+		OrderId:       orderId,
+		Kind:          model.PaymentKindRent,
+		Amount:        270000,
+		PaymentStatus: "settlement",
+		PaymentType:   "gopay",
+	}
+
+	pkg.PaymentRepository.Mock.On("FindByIdForUpdate", paymentId).Return(payment, nil)
+	pkg.PaymentRepository.Mock.On("Update", paymentId, mock.Anything).Return(nil)
 
-	for i := range order.OrderDetails {
-		bike := order.OrderDetails[i].Bike
+	pkg.RefundRepository.Mock.On("FindByIdPayment", paymentId).Return(&[]model.Refund{}, nil)
+	pkg.RefundRepository.Mock.On("Create", mock.MatchedBy(func(refund model.Refund) bool {
+		return refund.PaymentId == paymentId
+	})).Return(nil)
 
-		// update the bike availability
-		bike.IsAvailable = "1"
+	paymentGateway.Mock.On("RefundTransaction", orderId, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-		pkg.BikeRepository.Mock.On("Update", bike.ID, *bike).Return(nil)
+	data, err := orderUsecaseTest.CancelOrder(orderId, customerId)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, data)
+
+	// each bike is charged on what was paid for it: (200000 - 20000) * 50% + (100000 - 10000) * 100%
+	assert.Equal(t, float32(180000), data["cancellation_fee"])
+	assert.Equal(t, float32(90000), data["refund_amount"])
+	paymentGateway.Mock.AssertCalled(t, "RefundTransaction", orderId, mock.Anything, int64(90000), mock.Anything)
+}
+
+func TestOrderUsecase_CancelOrderPickedUp(t *testing.T) {
+	orderId := "4b5c6d7e-8f9a-4b0c-9d1e-2f3a4b5c6d7e"
+
+	order := &model.Order{
+		ID:           "4b5c6d7e-8f9a-4b0c-9d1e-2f3a4b5c6d7e",
+		UserId:       "02629953-7ac7-4c77-83c0-136a0f252427",
+		PaymentId:    "5c6d7e8f-9a0b-4c1d-8e2f-3a4b5c6d7e8f",
+		TotalPayment: 75000,
+		TotalQty:     1,
+		TotalHour:    5,
+		Status:       model.OrderStatusPickedUp,
 	}
 
-	history := &model.History{
-		ID:         "25512ed6-7969-4b84-a099-c4de82968ed7",
-		OrderId:    "a1dcbf01-144c-4507-939c-449c18d5fbac",
-		RentStatus: "rented",
+	pkg.OrderRepository.Mock.On("FindById", orderId).Return(order, nil)
+	pkg.OrderRepository.Mock.On("FindByIdForUpdate", orderId).Return(order, nil)
+	pkg.PaymentRepository.Mock.On("FindByIdForUpdate", order.PaymentId).Return(&model.Payment{ID: order.PaymentId, PaymentStatus: model.PaymentStatusSettlement}, nil)
+
+	data, err := orderUsecaseTest.CancelOrder(orderId, "02629953-7ac7-4c77-83c0-136a0f252427")
+
+	assert.Nil(t, data)
+	assert.ErrorIs(t, err, pkg.ErrInvalidStatusTransition)
+}
+
+func TestOrderUsecase_SettleDeposit(t *testing.T) {
+	renterUserId := "5f7b9d1e-3a6c-4e0f-8b5d-7e9a1c3f6b8d"
+	orderId := "6a8c0e2f-4b7d-4f1a-9c6e-8f0b2d4a7c9e"
+	paymentId := "7b9d1f3a-5c8e-4a2b-8d7f-9a1c3e5b8d0f"
+
+	renter := &model.Renter{
+		ID:     "8c0e2a4b-6d9f-4b3c-9e8a-0b2d4f6c9e1a",
+		UserId: renterUserId,
 	}
 
-	pkg.HistoryRepository.Mock.On("FindByIdOrder", orderId).Return(history, nil)
+	order := &model.Order{
+		ID:            orderId,
+		UserId:        "9d1f3b5c-7e0a-4c4d-8f9b-1c3e5a7d0f2b",
+		PaymentId:     paymentId,
+		TotalPayment:  75000,
+		Deposit:       50000,
+		DepositStatus: model.DepositStatusHeld,
+		Status:        model.OrderStatusReturned,
+	}
+
+	payment := &model.Payment{
+		ID:            paymentId,
+		OrderId:       orderId,
+		Kind:          model.PaymentKindRent,
+		Amount:        125000,
+		Deposit:       50000,
+		PaymentStatus: model.PaymentStatusSettlement,
+		PaymentType:   "gopay",
+	}
+
+	pkg.OrderRepository.Mock.On("FindById", orderId).Return(order, nil)
+	pkg.RenterRepository.Mock.On("FindByIdUser", renterUserId).Return(renter, nil)
+	pkg.OrderDetailRepository.Mock.On("FindByIdOrder", orderId).Return(&[]model.OrderDetail{
+		{OrderId: orderId, Deposit: 50000, Bike: &model.Bike{RenterId: renter.ID}},
+	}, nil)
+	pkg.PaymentRepository.Mock.On("FindByIdForUpdate", paymentId).Return(payment, nil)
+
+	// the withheld part of the deposit is owed to the renter
+	pkg.LedgerRepository.Mock.On("Create", mock.MatchedBy(func(entries []model.LedgerEntry) bool {
+		return len(entries) == 2 && entries[0].Account == model.LedgerAccountDepositHeld && entries[0].Debit == 20000 &&
+			entries[1].Account == model.LedgerAccountRenterPayable && entries[1].RenterId == renter.ID && entries[1].Credit == 20000
+	})).Return(nil)
+	pkg.OrderRepository.Mock.On("Update", orderId, mock.MatchedBy(func(orderUC model.Order) bool {
+		return orderUC.DepositStatus == model.DepositStatusWithheld && orderUC.DepositWithheld == 20000 && orderUC.DepositReason == "broken brake lever"
+	})).Return(nil)
+
+	// the rest of the deposit goes back to the customer
+	pkg.RefundRepository.Mock.On("FindByIdPayment", paymentId).Return(&[]model.Refund{}, nil)
+	pkg.RefundRepository.Mock.On("Create", mock.MatchedBy(func(refund model.Refund) bool {
+		return refund.PaymentId == paymentId && refund.Kind == model.RefundKindDeposit && refund.Amount == 30000
+	})).Return(nil)
+	paymentGateway.Mock.On("RefundTransaction", orderId, mock.Anything, int64(30000), mock.Anything).Return(nil)
+	pkg.PaymentRepository.Mock.On("Update", paymentId, mock.MatchedBy(func(paymentUC model.Payment) bool {
+		return paymentUC.PaymentStatus == model.PaymentStatusPartialRefund
+	})).Return(nil)
+
+	data, err := orderUsecaseTest.SettleDeposit(orderId, renterUserId, dto.OrderDepositDTO{WithheldAmount: 20000, Reason: "broken brake lever"})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, data)
+
+	assert.Equal(t, model.DepositStatusWithheld, data["deposit_status"])
+	assert.Equal(t, float32(30000), data["deposit_released"])
+	paymentGateway.Mock.AssertCalled(t, "RefundTransaction", orderId, mock.Anything, int64(30000), mock.Anything)
+}
+
+func TestOrderUsecase_SettleDepositToWallet(t *testing.T) {
+	renterUserId := "0e2a4c6d-8f1b-4d5e-9a0c-2d4f6b8e1a3c"
+	customerId := "1f3b5d7e-9a2c-4e6f-8b1d-3e5a7c9f2b4d"
+	orderId := "2a4c6e8f-0b3d-4f7a-9c2e-4f6b8d0a3c5e"
+	paymentId := "3b5d7f9a-1c4e-4a8b-8d3f-5a7c9e1b4d6f"
+
+	renter := &model.Renter{
+		ID:     "4c6e8a0b-2d5f-4b9c-9e4a-6b8d0f2c5e7a",
+		UserId: renterUserId,
+	}
+
+	order := &model.Order{
+		ID:            orderId,
+		UserId:        customerId,
+		PaymentId:     paymentId,
+		TotalPayment:  75000,
+		Deposit:       50000,
+		DepositStatus: model.DepositStatusHeld,
+		Status:        model.OrderStatusClosed,
+	}
+
+	payment := &model.Payment{
+		ID:            paymentId,
+		OrderId:       orderId,
+		Kind:          model.PaymentKindRent,
+		Amount:        125000,
+		Deposit:       50000,
+		PaymentStatus: model.PaymentStatusSettlement,
+		PaymentType:   model.PaymentTypeWallet,
+	}
+
+	wallet := &model.Wallet{
+		ID:      "5d7f9b1c-3e6a-4c0d-8f5b-7c9e1a3d6f8b",
+		UserId:  customerId,
+		Balance: 0,
+	}
+
+	pkg.OrderRepository.Mock.On("FindById", orderId).Return(order, nil)
+	pkg.RenterRepository.Mock.On("FindByIdUser", renterUserId).Return(renter, nil)
+	pkg.OrderDetailRepository.Mock.On("FindByIdOrder", orderId).Return(&[]model.OrderDetail{
+		{OrderId: orderId, Deposit: 50000, Bike: &model.Bike{RenterId: renter.ID}},
+	}, nil)
+	pkg.PaymentRepository.Mock.On("FindByIdForUpdate", paymentId).Return(payment, nil)
+	pkg.OrderRepository.Mock.On("Update", orderId, mock.MatchedBy(func(orderUC model.Order) bool {
+		return orderUC.DepositStatus == model.DepositStatusReleased && orderUC.DepositWithheld == 0
+	})).Return(nil)
+
+	pkg.RefundRepository.Mock.On("FindByIdPayment", paymentId).Return(&[]model.Refund{}, nil)
+	pkg.RefundRepository.Mock.On("Create", mock.MatchedBy(func(refund model.Refund) bool {
+		return refund.PaymentId == paymentId && refund.Kind == model.RefundKindDeposit && refund.Amount == 50000
+	})).Return(nil)
+	pkg.RefundRepository.Mock.On("Update", mock.Anything, mock.MatchedBy(func(refund model.Refund) bool {
+		return refund.Status == model.RefundStatusSucceeded
+	})).Return(nil)
+
+	pkg.WalletRepository.Mock.On("FindByIdUserForUpdate", customerId).Return(wallet, nil)
+	pkg.WalletRepository.Mock.On("UpdateBalance", wallet.ID, mock.MatchedBy(func(walletUC model.Wallet) bool {
+		return walletUC.Balance == 50000
+	})).Return(nil)
+	pkg.WalletRepository.Mock.On("CreateTransaction", mock.MatchedBy(func(transaction model.WalletTransaction) bool {
+		return transaction.WalletId == wallet.ID && transaction.Type == model.WalletTransactionRefund && transaction.Amount == 50000
+	})).Return(nil)
+
+	// the released deposit leaves the deposit held, the renters keep what the rent credited them
+	pkg.LedgerRepository.Mock.On("Create", mock.MatchedBy(func(entries []model.LedgerEntry) bool {
+		return len(entries) == 2 && entries[0].PaymentId == paymentId &&
+			entries[0].Account == model.LedgerAccountDepositHeld && entries[0].Debit == 50000 &&
+			entries[1].Account == model.LedgerAccountCustomerWallet && entries[1].Credit == 50000
+	})).Return(nil)
+
+	pkg.PaymentRepository.Mock.On("Update", paymentId, mock.MatchedBy(func(paymentUC model.Payment) bool {
+		return paymentUC.PaymentStatus == model.PaymentStatusPartialRefund
+	})).Return(nil)
+
+	data, err := orderUsecaseTest.SettleDeposit(orderId, renterUserId, dto.OrderDepositDTO{})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, data)
+
+	assert.Equal(t, model.DepositStatusReleased, data["deposit_status"])
+	assert.Equal(t, float32(50000), wallet.Balance)
+}
+
+func TestOrderUsecase_SettleDepositNotReturned(t *testing.T) {
+	renterUserId := "6e8a0c2d-4f7b-4d1e-9a6c-8d0f2b4e7a9c"
+	orderId := "7f9b1d3e-5a8c-4e2f-8b7d-9e1a3c5f8b0d"
+
+	renter := &model.Renter{
+		ID:     "8a0c2e4f-6b9d-4f3a-9c8e-0f2b4d6a9c1e",
+		UserId: renterUserId,
+	}
+
+	order := &model.Order{
+		ID:            orderId,
+		PaymentId:     "9b1d3f5a-7c0e-4a4b-8d9f-1a3c5e7b0d2f",
+		Deposit:       50000,
+		DepositStatus: model.DepositStatusHeld,
+		Status:        model.OrderStatusPickedUp,
+	}
+
+	pkg.OrderRepository.Mock.On("FindById", orderId).Return(order, nil)
+	pkg.RenterRepository.Mock.On("FindByIdUser", renterUserId).Return(renter, nil)
+	pkg.OrderDetailRepository.Mock.On("FindByIdOrder", orderId).Return(&[]model.OrderDetail{
+		{OrderId: orderId, Bike: &model.Bike{RenterId: renter.ID}},
+	}, nil)
+
+	data, err := orderUsecaseTest.SettleDeposit(orderId, renterUserId, dto.OrderDepositDTO{})
+
+	assert.Nil(t, data)
+	assert.ErrorIs(t, err, pkg.ErrDepositNotSettleable)
+}
+
+func TestOrderUsecase_SettleDepositWithoutReason(t *testing.T) {
+	data, err := orderUsecaseTest.SettleDeposit("0c2e4a6b-8d1f-4b5c-9e0a-2b4d6f8c1e3a", "1d3f5b7c-9e2a-4c6d-8f1b-3c5e7a9d2f4b", dto.OrderDepositDTO{WithheldAmount: 10000})
+
+	assert.Nil(t, data)
+	assert.ErrorIs(t, err, pkg.ErrInvalidDeposit)
+}
+
+func TestOrderUsecase_ExpireUnpaidOrders(t *testing.T) {
+	now := time.Date(2022, 11, 20, 8, 0, 0, 0, time.UTC)
+
+	pendingOrder := &model.Order{
+		ID:            "6e7f8a9b-0c1d-4e2f-9a3b-4c5d6e7f8a9b",
+		UserId:        "02629953-7ac7-4c77-83c0-136a0f252427",
+		PaymentId:     "7f8a9b0c-1d2e-4f3a-8b4c-5d6e7f8a9b0c",
+		Status:        model.OrderStatusPendingPayment,
+		Deposit:       100000,
+		DepositStatus: model.DepositStatusHeld,
+		CreatedAt:     now.Add(-2 * time.Hour),
+	}
+
+	// paid after it was listed as pending
+	paidOrder := &model.Order{
+		ID:        "8a9b0c1d-2e3f-4a4b-9c5d-6e7f8a9b0c1d",
+		UserId:    "02629953-7ac7-4c77-83c0-136a0f252427",
+		PaymentId: "9b0c1d2e-3f4a-4b5c-8d6e-7f8a9b0c1d2e",
+		Status:    model.OrderStatusPaid,
+		CreatedAt: now.Add(-3 * time.Hour),
+	}
+
+	pkg.OrderRepository.Mock.On("FindPendingPayment", now.Add(-time.Hour)).Return(&[]model.Order{*paidOrder, *pendingOrder}, nil)
+	pkg.OrderRepository.Mock.On("FindByIdForUpdate", pendingOrder.ID).Return(pendingOrder, nil)
+	pkg.OrderRepository.Mock.On("FindByIdForUpdate", paidOrder.ID).Return(paidOrder, nil)
+	pkg.PaymentRepository.Mock.On("FindByIdForUpdate", paidOrder.PaymentId).Return(&model.Payment{
+		ID:            "9b0c1d2e-3f4a-4b5c-8d6e-7f8a9b0c1d2e",
+		OrderId:       "8a9b0c1d-2e3f-4a4b-9c5d-6e7f8a9b0c1d",
+		Kind:          model.PaymentKindRent,
+		PaymentStatus: model.PaymentStatusSettlement,
+	}, nil)
+	pkg.OrderRepository.Mock.On("Update", pendingOrder.ID, mock.MatchedBy(func(orderUC model.Order) bool {
+		return orderUC.Status == model.OrderStatusExpired
+	})).Return(nil)
+	pkg.OrderRepository.Mock.On("Update", pendingOrder.ID, mock.MatchedBy(func(orderUC model.Order) bool {
+		return orderUC.DepositStatus == model.DepositStatusReleased
+	})).Return(nil)
+
+	pkg.OrderStatusHistoryRepository.Mock.On("Create", mock.MatchedBy(func(statusHistory model.OrderStatusHistory) bool {
+		return statusHistory.OrderId == pendingOrder.ID && statusHistory.ToStatus == model.OrderStatusExpired && statusHistory.Actor == "system"
+	})).Return(nil)
+	pkg.HistoryRepository.Mock.On("FindByIdOrder", pendingOrder.ID).Return(&model.History{OrderId: pendingOrder.ID, RentStatus: "pending_payment"}, nil)
+	pkg.HistoryRepository.Mock.On("Update", pendingOrder.ID, mock.Anything).Return(nil)
+
+	rentPayment := &model.Payment{
+		ID:            "7f8a9b0c-1d2e-4f3a-8b4c-5d6e7f8a9b0c",
+		OrderId:       "6e7f8a9b-0c1d-4e2f-9a3b-4c5d6e7f8a9b",
+		Kind:          model.PaymentKindRent,
+		PaymentStatus: "pending",
+	}
+
+	pkg.PaymentRepository.Mock.On("FindByIdForUpdate", rentPayment.ID).Return(rentPayment, nil)
+	pkg.PaymentRepository.Mock.On("Update", rentPayment.ID, mock.MatchedBy(func(paymentUC model.Payment) bool {
+		return paymentUC.PaymentStatus == "expire"
+	})).Return(nil)
+	paymentGateway.Mock.On("CancelTransaction", pendingOrder.ID).Return(nil)
+
+	// an extension of a rent in progress that is never paid
+	pendingEndAt := now.Add(3 * time.Hour)
+	extendedOrder := &model.Order{
+		ID:           "0c1d2e3f-4a5b-4c6d-9e7f-8a9b0c1d2e3f",
+		UserId:       "02629953-7ac7-4c77-83c0-136a0f252427",
+		PaymentId:    "1d2e3f4a-5b6c-4d7e-8f8a-9b0c1d2e3f4a",
+		Status:       model.OrderStatusPickedUp,
+		EndAt:        now.Add(time.Hour),
+		PendingEndAt: &pendingEndAt,
+	}
+
+	extensionPayment := &model.Payment{
+		ID:            "2e3f4a5b-6c7d-4e8f-9a9b-0c1d2e3f4a5b",
+		OrderId:       "0c1d2e3f-4a5b-4c6d-9e7f-8a9b0c1d2e3f",
+		Kind:          model.PaymentKindExtension,
+		PaymentStatus: "pending",
+		CreatedAt:     now.Add(-2 * time.Hour),
+	}
+
+	pkg.PaymentRepository.Mock.On("FindPending", model.PaymentKindExtension, now.Add(-time.Hour)).Return(&[]model.Payment{*extensionPayment}, nil)
+	pkg.PaymentRepository.Mock.On("FindByIdForUpdate", extensionPayment.ID).Return(extensionPayment, nil)
+	pkg.PaymentRepository.Mock.On("Update", extensionPayment.ID, mock.MatchedBy(func(paymentUC model.Payment) bool {
+		return paymentUC.PaymentStatus == "expire"
+	})).Return(nil)
+	pkg.OrderRepository.Mock.On("FindByIdForUpdate", extendedOrder.ID).Return(extendedOrder, nil)
+	pkg.OrderRepository.Mock.On("UpdatePendingEndAt", extendedOrder.ID, (*time.Time)(nil)).Return(nil)
+
+	// the payment gateway expired the link of the extension itself, there is nothing left to cancel
+	paymentGateway.Mock.On("CancelTransaction", extensionPayment.ID).Return(pkg.ErrPaymentGateway)
+	paymentGateway.Mock.On("CheckTransaction", extensionPayment.ID).Return(&payment.TransactionStatus{TransactionStatus: model.PaymentStatusExpire}, nil)
+
+	expired, err := orderUsecaseTest.ExpireUnpaidOrders(now)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, expired)
+
+	assert.Equal(t, model.OrderStatusExpired, pendingOrder.Status)
+	assert.Equal(t, model.DepositStatusReleased, pendingOrder.DepositStatus)
+	pkg.OrderRepository.Mock.AssertCalled(t, "Update", pendingOrder.ID, mock.MatchedBy(func(orderUC model.Order) bool {
+		return orderUC.DepositStatus == model.DepositStatusReleased
+	}))
+	paymentGateway.Mock.AssertCalled(t, "CancelTransaction", pendingOrder.ID)
+	assert.Equal(t, model.OrderStatusPaid, paidOrder.Status)
+	pkg.OrderRepository.Mock.AssertNotCalled(t, "Update", paidOrder.ID, mock.Anything)
+	paymentGateway.Mock.AssertNotCalled(t, "CancelTransaction", paidOrder.ID)
+
+	assert.Equal(t, model.OrderStatusPickedUp, extendedOrder.Status)
+	assert.Nil(t, extendedOrder.PendingEndAt)
+	pkg.OrderRepository.Mock.AssertCalled(t, "UpdatePendingEndAt", extendedOrder.ID, (*time.Time)(nil))
+}
+
+func TestOrderUsecase_ExtendOrder(t *testing.T) {
+	orderId := "3f4a5b6c-7d8e-4f9a-8b0c-1d2e3f4a5b6c"
+	customerId := "4a5b6c7d-8e9f-4a0b-9c1d-2e3f4a5b6c7d"
+	bikeId := "5b6c7d8e-9f0a-4b1c-8d2e-3f4a5b6c7d8e"
+
+	now := time.Now()
+
+	bike := &model.Bike{
+		ID:           "5b6c7d8e-9f0a-4b1c-8d2e-3f4a5b6c7d8e",
+		RenterId:     "ffad8203-b32d-46dd-b488-a700ad61dac7",
+		Name:         "Sample Gravel Bike",
+		PricePerHour: 12000,
+	}
+
+	order := &model.Order{
+		ID:           "3f4a5b6c-7d8e-4f9a-8b0c-1d2e3f4a5b6c",
+		UserId:       "4a5b6c7d-8e9f-4a0b-9c1d-2e3f4a5b6c7d",
+		PaymentId:    "6c7d8e9f-0a1b-4c2d-9e3f-4a5b6c7d8e9f",
+		TotalPayment: 36000,
+		TotalQty:     1,
+		TotalHour:    3,
+		StartAt:      now.Add(-time.Hour),
+		EndAt:        now.Add(2 * time.Hour),
+		Status:       model.OrderStatusPickedUp,
+		OrderDetails: []model.OrderDetail{
+			{
+				ID:      "7d8e9f0a-1b2c-4d3e-8f4a-5b6c7d8e9f0a",
+				OrderId: "3f4a5b6c-7d8e-4f9a-8b0c-1d2e3f4a5b6c",
+				BikeId:  "5b6c7d8e-9f0a-4b1c-8d2e-3f4a5b6c7d8e",
+				Bike:    bike,
+			},
+		},
+	}
+
+	customer := &model.User{
+		ID:       "4a5b6c7d-8e9f-4a0b-9c1d-2e3f4a5b6c7d",
+		Fullname: "Arvin Paundra",
+		Phone:    "0876534321",
+		Role:     "customer",
+		Email:    "arvin@mail.com",
+	}
+
+	extensionDTO := dto.OrderExtensionDTO{
+		EndAt: now.Add(5 * time.Hour),
+	}
+
+	pkg.BikeRepository.Mock.On("FindByIdOrderForUpdate", orderId).Return(&[]model.Bike{*bike}, nil)
+	pkg.OrderRepository.Mock.On("FindByIdForUpdate", orderId).Return(order, nil)
+	pkg.BikeRepository.Mock.On("FindOverlappingOrders", bikeId, order.EndAt, extensionDTO.EndAt).Return(&[]model.Order{}, nil)
+	pkg.PricingRuleRepository.Mock.On("FindActive", bike.RenterId, order.EndAt, extensionDTO.EndAt).Return(&[]model.PricingRule{}, nil)
+	pkg.OrderRepository.Mock.On("UpdatePendingEndAt", orderId, &extensionDTO.EndAt).Return(nil)
+	pkg.UserRepository.Mock.On("FindById", customerId).Return(customer, nil)
+
+	var paymentId string
+	pkg.PaymentRepository.Mock.On("Create", mock.MatchedBy(func(payment model.Payment) bool {
+		if payment.OrderId != orderId || payment.Kind != model.PaymentKindExtension || payment.Amount != 36000 {
+			return false
+		}
+		paymentId = payment.ID
+		return true
+	})).Return(nil)
+	paymentGateway.Mock.On("CreateUrlTransactionWithGateway", mock.MatchedBy(func(req dto.PaymentGateway) bool {
+		return req.GrossAmt == 36000 && req.Items[0].Price == 36000 && req.Items[0].Name == "Extension Sample Gravel Bike (3 hours)" && req.ExpiryMinutes == 60
+	})).Return("https://app.sandbox.midtrans.com/snap/v3/redirection/extension", nil)
+	pkg.PaymentRepository.Mock.On("Update", mock.MatchedBy(func(id string) bool {
+		return id != "" && id == paymentId
+	}), mock.MatchedBy(func(payment model.Payment) bool {
+		return payment.PaymentLink == "https://app.sandbox.midtrans.com/snap/v3/redirection/extension"
+	})).Return(nil)
+
+	data, err := orderUsecaseTest.ExtendOrder(orderId, extensionDTO)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, data)
+
+	assert.Equal(t, 3, data["extra_hours"])
+	assert.Equal(t, float32(36000), data["extra_payment"])
+	assert.Equal(t, "https://app.sandbox.midtrans.com/snap/v3/redirection/extension", data["payment_link"])
+
+	// the rent window only moves once the extension is paid
+	assert.Equal(t, now.Add(2*time.Hour), order.EndAt)
+	assert.Equal(t, float32(36000), order.TotalPayment)
+
+	// nothing is read before the bikes are locked, the order is read under its own lock
+	pkg.OrderRepository.Mock.AssertNotCalled(t, "FindById", orderId)
+}
+
+func TestOrderUsecase_ExtendOrderConflict(t *testing.T) {
+	orderId := "8e9f0a1b-2c3d-4e4f-9a5b-6c7d8e9f0a1b"
+	bikeId := "9f0a1b2c-3d4e-4f5a-8b6c-7d8e9f0a1b2c"
+
+	now := time.Now()
+
+	bike := &model.Bike{
+		ID:           "9f0a1b2c-3d4e-4f5a-8b6c-7d8e9f0a1b2c",
+		RenterId:     "ffad8203-b32d-46dd-b488-a700ad61dac7",
+		Name:         "Sample City Bike",
+		PricePerHour: 10000,
+	}
+
+	order := &model.Order{
+		ID:        "8e9f0a1b-2c3d-4e4f-9a5b-6c7d8e9f0a1b",
+		UserId:    "02629953-7ac7-4c77-83c0-136a0f252427",
+		PaymentId: "a0b1c2d3-e4f5-4a6b-9c7d-8e9f0a1b2c3d",
+		StartAt:   now.Add(-time.Hour),
+		EndAt:     now.Add(time.Hour),
+		Status:    model.OrderStatusPaid,
+		OrderDetails: []model.OrderDetail{
+			{
+				ID:      "b1c2d3e4-f5a6-4b7c-8d8e-9f0a1b2c3d4e",
+				OrderId: "8e9f0a1b-2c3d-4e4f-9a5b-6c7d8e9f0a1b",
+				BikeId:  "9f0a1b2c-3d4e-4f5a-8b6c-7d8e9f0a1b2c",
+				Bike:    bike,
+			},
+		},
+	}
+
+	// the next customer picks the bike up two hours from now
+	clash := model.Order{
+		ID:      "c2d3e4f5-a6b7-4c8d-9e9f-0a1b2c3d4e5f",
+		StartAt: now.Add(2 * time.Hour),
+		EndAt:   now.Add(6 * time.Hour),
+		Status:  model.OrderStatusPaid,
+	}
+
+	extensionDTO := dto.OrderExtensionDTO{
+		EndAt: now.Add(4 * time.Hour),
+	}
+
+	pkg.BikeRepository.Mock.On("FindByIdOrderForUpdate", orderId).Return(&[]model.Bike{*bike}, nil)
+	pkg.OrderRepository.Mock.On("FindByIdForUpdate", orderId).Return(order, nil)
+	pkg.BikeRepository.Mock.On("FindOverlappingOrders", bikeId, order.EndAt, extensionDTO.EndAt).Return(&[]model.Order{clash}, nil)
+
+	data, err := orderUsecaseTest.ExtendOrder(orderId, extensionDTO)
+
+	assert.Nil(t, data)
+	assert.ErrorIs(t, err, pkg.ErrBookingConflict)
+	pkg.OrderRepository.Mock.AssertNotCalled(t, "UpdatePendingEndAt", orderId, mock.Anything)
+}
+
+func TestOrderUsecase_ExtendOrderNotExtendable(t *testing.T) {
+	pendingEndAt := time.Now().Add(4 * time.Hour)
+
+	testCases := []struct {
+		name  string
+		order *model.Order
+		err   error
+	}{
+		{
+			name: "unpaid order",
+			order: &model.Order{
+				ID:     "d3e4f5a6-b7c8-4d9e-8f0a-1b2c3d4e5f6a",
+				EndAt:  time.Now().Add(2 * time.Hour),
+				Status: model.OrderStatusPendingPayment,
+			},
+			err: pkg.ErrOrderNotExtendable,
+		},
+		{
+			name: "returned order",
+			order: &model.Order{
+				ID:     "e4f5a6b7-c8d9-4e0f-9a1b-2c3d4e5f6a7b",
+				EndAt:  time.Now().Add(-time.Hour),
+				Status: model.OrderStatusReturned,
+			},
+			err: pkg.ErrOrderNotExtendable,
+		},
+		{
+			name: "unpaid extension",
+			order: &model.Order{
+				ID:           "f5a6b7c8-d9e0-4f1a-8b2c-3d4e5f6a7b8c",
+				EndAt:        time.Now().Add(2 * time.Hour),
+				PendingEndAt: &pendingEndAt,
+				Status:       model.OrderStatusPickedUp,
+			},
+			err: pkg.ErrExtensionPending,
+		},
+		{
+			name: "end before the current end",
+			order: &model.Order{
+				ID:     "a6b7c8d9-e0f1-4a2b-9c3d-4e5f6a7b8c9d",
+				EndAt:  time.Now().Add(6 * time.Hour),
+				Status: model.OrderStatusPaid,
+			},
+			err: pkg.ErrInvalidRentWindow,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pkg.BikeRepository.Mock.On("FindByIdOrderForUpdate", tc.order.ID).Return(&[]model.Bike{}, nil)
+			pkg.OrderRepository.Mock.On("FindByIdForUpdate", tc.order.ID).Return(tc.order, nil)
+
+			data, err := orderUsecaseTest.ExtendOrder(tc.order.ID, dto.OrderExtensionDTO{EndAt: time.Now().Add(5 * time.Hour)})
+
+			assert.Nil(t, data)
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func TestApplyExtension(t *testing.T) {
+	orderId := "b7c8d9e0-f1a2-4b3c-8d4e-5f6a7b8c9d0e"
+
+	startAt := time.Date(2022, 11, 20, 8, 0, 0, 0, time.UTC)
+	pendingEndAt := time.Date(2022, 11, 20, 16, 0, 0, 0, time.UTC)
+
+	order := &model.Order{
+		ID:           "b7c8d9e0-f1a2-4b3c-8d4e-5f6a7b8c9d0e",
+		TotalPayment: 75000,
+		TotalHour:    5,
+		StartAt:      startAt,
+		EndAt:        time.Date(2022, 11, 20, 13, 0, 0, 0, time.UTC),
+		PendingEndAt: &pendingEndAt,
+		Status:       model.OrderStatusPickedUp,
+	}
+
+	payment := model.Payment{
+		ID:            "c8d9e0f1-a2b3-4c4d-9e5f-6a7b8c9d0e1f",
+		OrderId:       "b7c8d9e0-f1a2-4b3c-8d4e-5f6a7b8c9d0e",
+		Kind:          model.PaymentKindExtension,
+		Amount:        45000,
+		PaymentStatus: "settlement",
+	}
+
+	pkg.OrderRepository.Mock.On("Update", orderId, mock.MatchedBy(func(orderUC model.Order) bool {
+		return orderUC.EndAt.Equal(pendingEndAt) && orderUC.TotalHour == 8 && orderUC.TotalPayment == 120000
+	})).Return(nil)
+	pkg.OrderRepository.Mock.On("UpdatePendingEndAt", orderId, (*time.Time)(nil)).Return(nil)
+
+	err := applyExtension(pkg.UnitOfWork.Repositories, order, payment)
+
+	assert.Nil(t, err)
+	assert.Equal(t, pendingEndAt, order.EndAt)
+	assert.Nil(t, order.PendingEndAt)
+
+	// a repeated settlement notification leaves the order as it is
+	err = applyExtension(pkg.UnitOfWork.Repositories, order, payment)
+
+	assert.Nil(t, err)
+	assert.Equal(t, float32(120000), order.TotalPayment)
+	assert.Equal(t, 8, order.TotalHour)
+}
+
+func TestOrderUsecase_CreateOrderRenterSuspended(t *testing.T) {
+	customerId := "7a9c1e3f-5b7d-4f9a-8c1e-3a5c7e9b1d50"
+	bikeId := "1e3a5c7e-9b1d-4f3a-a5c7-e9b1d3f5a761"
+	renterId := "3a5c7e9b-1d3f-4a5c-8e9b-1d3f5a7c9e82"
+	suspendedAt := time.Now()
+
+	bike := &model.Bike{
+		ID:           bikeId,
+		RenterId:     renterId,
+		Name:         "Sample BMX Bike",
+		PricePerHour: 15000,
+		IsAvailable:  "1",
+	}
+
+	startAt := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	endAt := startAt.Add(2 * time.Hour)
+
+	pkg.UserRepository.Mock.On("FindById", customerId).Return(&model.User{ID: customerId, EmailVerifiedAt: &emailVerifiedAt}, nil)
+	pkg.BikeRepository.Mock.On("FindByIdsForUpdate", []string{bikeId}).Return(&[]model.Bike{*bike}, nil)
+	pkg.BikeRepository.Mock.On("FindById", bikeId).Return(bike, nil)
+	pkg.RenterRepository.Mock.On("FindById", renterId).Return(&model.Renter{ID: renterId, SuspendedAt: &suspendedAt}, nil)
+
+	result, err := orderUsecaseTest.CreateOrder(customerId, dto.OrderDTO{
+		BikeIds:     []string{bikeId},
+		StartAt:     startAt,
+		EndAt:       endAt,
+		PaymentType: "bank_transfer",
+	})
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, pkg.ErrRenterSuspended)
+}
+
+func TestOrderUsecase_CreateOrderEmailNotVerified(t *testing.T) {
+	customerId := "4c6e8a0c-2e4a-4c6e-9a0c-2e4a6c8e0b93"
+	bikeId := "5d7f9b1d-3f5b-4d7f-8b1d-3f5b7d9f1ca4"
 
-	history.RentStatus = "done"
+	pkg.UserRepository.Mock.On("FindById", customerId).Return(&model.User{ID: customerId, Email: "unverified@mail.com"}, nil)
 
-	pkg.HistoryRepository.Mock.On("Update", orderId, *history).Return(nil)
+	startAt := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
 
-	err := orderUsecaseTest.UpdateRentStatus(orderId)
+	result, err := orderUsecaseTest.CreateOrder(customerId, dto.OrderDTO{
+		BikeIds:     []string{bikeId},
+		StartAt:     startAt,
+		EndAt:       startAt.Add(2 * time.Hour),
+		PaymentType: "bank_transfer",
+	})
+
+	assert.Nil(t, result)
+	assert.Equal(t, pkg.ErrEmailNotVerified, err)
+	pkg.BikeRepository.Mock.AssertNotCalled(t, "FindById", bikeId)
+}
+
+func TestOrderUsecase_CreateOrderInvalid(t *testing.T) {
+	customerId := "6d14ca85-2794-4e20-809d-fee3415d8629"
+	bikeId := "93292e21-75d1-45ae-bf6d-3f1e77c77af5"
+
+	emailVerifiedAt := time.Now().Add(-time.Hour)
+	pkg.UserRepository.Mock.On("FindById", customerId).Return(&model.User{ID: customerId, EmailVerifiedAt: &emailVerifiedAt}, nil)
+
+	startAt := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+
+	testCases := []struct {
+		name     string
+		orderDTO dto.OrderDTO
+		err      error
+	}{
+		{
+			name:     "window already started",
+			orderDTO: dto.OrderDTO{BikeIds: []string{bikeId}, StartAt: time.Now().Add(-time.Hour), EndAt: startAt},
+			err:      pkg.ErrInvalidRentWindow,
+		},
+		{
+			name:     "no bike",
+			orderDTO: dto.OrderDTO{BikeIds: []string{}, StartAt: startAt, EndAt: startAt.Add(2 * time.Hour)},
+			err:      pkg.ErrNoBikeChosen,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.orderDTO.PaymentType = "bank_transfer"
+
+			result, err := orderUsecaseTest.CreateOrder(customerId, tc.orderDTO)
+
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	pkg.BikeRepository.Mock.AssertNotCalled(t, "FindById", bikeId)
+	pkg.BikeRepository.Mock.AssertNotCalled(t, "FindByIdsForUpdate", []string{})
+}
+
+func TestOrderUsecase_OverrideOrderStatus(t *testing.T) {
+	orderId := "5c7e9b1d-3f5a-4c7e-9b1d-3f5a7c9e1b04"
+	adminId := "9b1d3f5a-7c9e-4b1d-8f5a-7c9e1b3d5f26"
+
+	order := &model.Order{
+		ID:     orderId,
+		UserId: "d3f5a7c9-e1b3-4d5f-a7c9-e1b3d5f7a948",
+		Status: model.OrderStatusPickedUp,
+	}
+
+	pkg.OrderRepository.Mock.On("FindById", orderId).Return(order, nil)
+	pkg.OrderRepository.Mock.On("Update", orderId, mock.MatchedBy(func(orderUC model.Order) bool {
+		return orderUC.Status == model.OrderStatusClosed
+	})).Return(nil)
+
+	pkg.OrderStatusHistoryRepository.Mock.On("Create", mock.MatchedBy(func(statusHistory model.OrderStatusHistory) bool {
+		return statusHistory.OrderId == orderId && statusHistory.FromStatus == model.OrderStatusPickedUp && statusHistory.ToStatus == model.OrderStatusClosed &&
+			statusHistory.Actor == adminId && statusHistory.Reason == "bike returned to the shop by a third party"
+	})).Return(nil)
+
+	pkg.HistoryRepository.Mock.On("FindByIdOrder", orderId).Return(&model.History{OrderId: orderId, RentStatus: "picked_up"}, nil)
+	pkg.HistoryRepository.Mock.On("Update", orderId, mock.MatchedBy(func(historyUC model.History) bool {
+		return historyUC.RentStatus == "closed"
+	})).Return(nil)
+
+	err := orderUsecaseTest.OverrideOrderStatus(orderId, adminId, dto.OrderOverrideDTO{
+		Status: model.OrderStatusClosed,
+		Reason: "bike returned to the shop by a third party",
+	})
 
 	assert.Nil(t, err)
+	assert.Equal(t, model.OrderStatusClosed, order.Status)
+}
+
+func TestOrderUsecase_OverrideOrderStatusInvalid(t *testing.T) {
+	orderId := "f5a7c9e1-b3d5-4f7a-9c1e-b3d5f7a9c160"
+
+	pkg.OrderRepository.Mock.On("FindById", orderId).Return(&model.Order{ID: orderId, Status: model.OrderStatusPaid}, nil)
+
+	testCases := []dto.OrderOverrideDTO{
+		{Status: "lost", Reason: "bike lost"},
+		{Status: model.OrderStatusClosed},
+		{Status: model.OrderStatusPaid, Reason: "already paid"},
+	}
+
+	for _, overrideDTO := range testCases {
+		err := orderUsecaseTest.OverrideOrderStatus(orderId, "9b1d3f5a-7c9e-4b1d-8f5a-7c9e1b3d5f26", overrideDTO)
+
+		assert.ErrorIs(t, err, pkg.ErrInvalidOrderOverride)
+	}
 }
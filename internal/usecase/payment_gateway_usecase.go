@@ -1,110 +1,398 @@
 package usecase
 
 import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/arvinpaundra/go-rent-bike/configs"
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	pgMidtrans "github.com/arvinpaundra/go-rent-bike/internal/midtrans"
 	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/payment"
 	"github.com/arvinpaundra/go-rent-bike/internal/repository"
-	"github.com/midtrans/midtrans-go"
-	"github.com/midtrans/midtrans-go/coreapi"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/google/uuid"
 )
 
-var c coreapi.Client
-
 type PaymentGatewayUsecase interface {
-	MidtransNotification(orderId string) error
+	MidtransNotification(notification dto.MidtransNotificationDTO) error
+	SyncTransaction(orderId string) error
+	ReconcilePayments(now time.Time) (*dto.ReconciliationReport, error)
 }
 
 type paymentGatewayUsecase struct {
+	unitOfWork        repository.UnitOfWork
+	paymentProvider   payment.PaymentProvider
+	serverKey         string
+	commissionPercent float32
 	orderRepository   repository.OrderRepository
 	paymentRepository repository.PaymentRepository
 	historyRepository repository.HistoryRepository
+	refundRepository  repository.RefundRepository
 }
 
-func (u paymentGatewayUsecase) InitializeCoreapiClient() {
-	c.New(configs.Cfg.MidtransServerKeyDev, midtrans.Sandbox)
-}
-
-func (u paymentGatewayUsecase) MidtransNotification(orderId string) error {
-	u.InitializeCoreapiClient()
-
-	transactionStatusRes, midtransError := c.CheckTransaction(orderId)
-
-	if midtransError != nil {
-		return midtransError
+func (u paymentGatewayUsecase) MidtransNotification(notification dto.MidtransNotificationDTO) error {
+	if notification.OrderId == "" || notification.TransactionId == "" || notification.TransactionStatus == "" || notification.StatusCode == "" || notification.GrossAmount == "" {
+		return pkg.ErrInvalidNotification
 	}
 
-	order, err := u.orderRepository.FindById(orderId)
+	// only midtrans knows the server key, so a valid signature proves the notification comes from midtrans
+	signature := pgMidtrans.NotificationSignature(notification.OrderId, notification.StatusCode, notification.GrossAmount, u.serverKey)
 
-	if err != nil {
-		return err
+	if subtle.ConstantTimeCompare([]byte(strings.ToLower(notification.SignatureKey)), []byte(signature)) != 1 {
+		return pkg.ErrInvalidSignature
 	}
 
-	var payment *model.Payment
-	payment, err = u.paymentRepository.FindById(order.PaymentId)
+	return u.syncTransaction(notification.OrderId, &notification)
+}
 
-	if err != nil {
-		return err
-	}
+// SyncTransaction brings the payment and its order up to date with the transaction in the payment provider.
+func (u paymentGatewayUsecase) SyncTransaction(orderId string) error {
+	return u.syncTransaction(orderId, nil)
+}
 
-	var history *model.History
-	history, err = u.historyRepository.FindByIdOrder(orderId)
+func (u paymentGatewayUsecase) syncTransaction(orderId string, notification *dto.MidtransNotificationDTO) error {
+	// never trust the notification itself, ask the payment provider for the status
+	transactionStatusRes, err := u.paymentProvider.CheckTransaction(orderId)
 
 	if err != nil {
 		return err
 	}
 
-	if transactionStatusRes.TransactionStatus == "settlement" && transactionStatusRes.FraudStatus == "accept" {
-		payment.PaymentStatus = "settlement"
-		payment.PaymentType = transactionStatusRes.PaymentType
-		payment.UpdatedAt = time.Now()
+	return u.applyTransaction(orderId, notification, *transactionStatusRes, "midtrans")
+}
 
-		if err := u.paymentRepository.Update(payment.ID, *payment); err != nil {
-			return err
+// applyTransaction moves the payment and its order to the transaction status the payment provider reported.
+func (u paymentGatewayUsecase) applyTransaction(orderId string, notification *dto.MidtransNotificationDTO, transactionStatusRes payment.TransactionStatus, actor string) error {
+	// payment and order status must move together, otherwise a paid order could stay pending
+	return u.unitOfWork.WithTx(func(repos repository.Repositories) error {
+		// midtrans retries a notification until it gets an answer, so the same one may come many times.
+		// the notification is logged in the same transaction, a failed one is processed again on retry
+		if notification != nil {
+			processed, err := logNotification(repos, *notification)
+
+			if err != nil || processed {
+				return err
+			}
 		}
 
-		history.RentStatus = "rented"
-		history.UpdatedAt = time.Now()
+		order, payment, err := findGatewayTransaction(repos, orderId)
 
-		if err := u.historyRepository.Update(orderId, *history); err != nil {
+		if err != nil {
 			return err
 		}
-	} else if transactionStatusRes.TransactionStatus == "deny" {
-		payment.PaymentStatus = "deny"
-		payment.PaymentType = transactionStatusRes.PaymentType
-		payment.UpdatedAt = time.Now()
 
-		if err := u.paymentRepository.Update(payment.ID, *payment); err != nil {
+		if err := completeRefunds(repos, *payment, transactionStatusRes.Refunds); err != nil {
 			return err
 		}
 
-		history.RentStatus = "denied"
-		history.UpdatedAt = time.Now()
+		// a settlement only counts once the fraud check accepted it
+		transactionStatus := transactionStatusRes.TransactionStatus
 
-		if err := u.historyRepository.Update(orderId, *history); err != nil {
-			return err
+		if transactionStatus == model.PaymentStatusSettlement && transactionStatusRes.FraudStatus != "accept" {
+			return nil
+		}
+
+		// a settlement after the payment expired or was canceled here charged the customer for bikes the order
+		// no longer holds, it is recorded as owed back instead of dropped, and moves neither the order nor the ledger
+		if transactionStatus == model.PaymentStatusSettlement && model.CanTransitionPaymentStatus(payment.PaymentStatus, model.PaymentStatusLateSettlement) {
+			transactionStatus = model.PaymentStatusLateSettlement
+		}
+
+		// the payment status only moves forward, a late pending or a repeated settlement changes nothing
+		if !model.CanTransitionPaymentStatus(payment.PaymentStatus, transactionStatus) {
+			return nil
+		}
+
+		payment.PaymentStatus = transactionStatus
+
+		// map the midtrans transaction status onto the order state machine
+		var next model.OrderStatus
+
+		switch transactionStatus {
+		case model.PaymentStatusSettlement:
+			next = model.OrderStatusPaid
+		case model.PaymentStatusDeny:
+			next = model.OrderStatusDenied
+		case model.PaymentStatusCancel:
+			next = model.OrderStatusCanceled
+		case model.PaymentStatusExpire:
+			next = model.OrderStatusExpired
+		}
+
+		// only the rent payment drives the order, a late fee only closes the returned order once it is paid,
+		// an extension only moves the end of the rent and a top up has no order at all
+		switch payment.Kind {
+		case model.PaymentKindTopUp:
+			next = ""
+		case model.PaymentKindLateFee:
+			next = ""
+
+			if payment.PaymentStatus == model.PaymentStatusSettlement {
+				next = model.OrderStatusClosed
+			}
+		case model.PaymentKindExtension:
+			next = ""
+
+			switch payment.PaymentStatus {
+			case model.PaymentStatusSettlement:
+				if err := applyExtension(repos, order, *payment); err != nil {
+					return err
+				}
+			case model.PaymentStatusDeny, model.PaymentStatusCancel, model.PaymentStatusExpire:
+				if err := dropExtension(repos, order); err != nil {
+					return err
+				}
+			}
+		}
+
+		if transactionStatusRes.PaymentType != "" {
+			payment.PaymentType = transactionStatusRes.PaymentType
 		}
-	} else if transactionStatusRes.TransactionStatus == "cancel" || transactionStatusRes.TransactionStatus == "expired" {
-		payment.PaymentStatus = transactionStatusRes.TransactionStatus
-		payment.PaymentType = transactionStatusRes.PaymentType
 		payment.UpdatedAt = time.Now()
 
-		if err := u.paymentRepository.Update(payment.ID, *payment); err != nil {
+		if err := repos.Payment.Update(payment.ID, *payment); err != nil {
 			return err
 		}
 
-		history.RentStatus = "canceled"
-		history.UpdatedAt = time.Now()
+		// the money of a settled payment is owed to the renters, minus the commission of the platform,
+		// a settled top up goes to the wallet of the customer instead
+		if payment.PaymentStatus == model.PaymentStatusSettlement {
+			if payment.Kind == model.PaymentKindTopUp {
+				err = settleTopUp(repos, *payment)
+			} else {
+				err = postSettlement(repos, *order, *payment, u.commissionPercent)
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+
+		// midtrans may notify the same status more than once
+		if next != "" && order.Status != next {
+			if err := transitionOrder(repos, order, next, actor); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ReconcilePayments checks every payment that is still pending, or waits for a refund, against the payment provider.
+// A payment that drifted is fixed the same way a notification would fix it, and reported for finance,
+// along with the payments settled too late that are still owed back.
+func (u paymentGatewayUsecase) ReconcilePayments(now time.Time) (*dto.ReconciliationReport, error) {
+	payments, err := u.paymentRepository.FindUnreconciled()
+
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dto.ReconciliationReport{
+		GeneratedAt:   now,
+		Discrepancies: []dto.ReconciliationDiscrepancy{},
+	}
+
+	// one failing payment does not hold back the others, it is reported instead
+	for i := range *payments {
+		report.Checked++
+
+		discrepancy := u.reconcilePayment((*payments)[i])
+
+		if discrepancy == nil {
+			continue
+		}
+
+		report.Discrepancies = append(report.Discrepancies, *discrepancy)
+
+		if discrepancy.StatusAfter != discrepancy.LocalStatus {
+			report.Fixed++
+		}
+	}
+
+	return report, nil
+}
+
+func (u paymentGatewayUsecase) reconcilePayment(payment model.Payment) *dto.ReconciliationDiscrepancy {
+	discrepancy := dto.ReconciliationDiscrepancy{
+		PaymentId:      payment.ID,
+		OrderId:        payment.OrderId,
+		GatewayOrderId: gatewayOrderId(payment),
+		PaymentKind:    payment.Kind,
+		LocalStatus:    payment.PaymentStatus,
+		StatusAfter:    payment.PaymentStatus,
+		LocalAmount:    payment.Amount,
+		Issues:         []string{},
+	}
+
+	transactionStatusRes, err := u.paymentProvider.CheckTransaction(discrepancy.GatewayOrderId)
+
+	if err != nil {
+		// a customer that never picked a payment method in snap leaves no transaction behind
+		if errors.Is(err, pkg.ErrRecordNotFound) && payment.PaymentStatus == model.PaymentStatusPending {
+			return nil
+		}
+
+		discrepancy.Issues = append(discrepancy.Issues, dto.ReconciliationIssueCheckFailed)
+		discrepancy.Error = err.Error()
+
+		return &discrepancy
+	}
+
+	discrepancy.GatewayStatus = transactionStatusRes.TransactionStatus
+	discrepancy.GatewayAmount = transactionStatusRes.GrossAmount
+
+	gatewayAmount, err := strconv.ParseFloat(transactionStatusRes.GrossAmount, 64)
+
+	if err != nil || math.Abs(gatewayAmount-float64(payment.Amount)) >= 1 {
+		discrepancy.Issues = append(discrepancy.Issues, dto.ReconciliationIssueAmountMismatch)
+	}
+
+	if err := u.applyTransaction(discrepancy.GatewayOrderId, nil, *transactionStatusRes, "reconciliation"); err != nil {
+		discrepancy.Issues = append(discrepancy.Issues, dto.ReconciliationIssueSyncFailed)
+		discrepancy.Error = err.Error()
+	} else {
+		if updated, err := u.paymentRepository.FindById(payment.ID); err == nil {
+			discrepancy.StatusAfter = updated.PaymentStatus
+		}
+
+		if resent, err := u.resendRefunds(payment, transactionStatusRes.Refunds); err != nil {
+			discrepancy.Issues = append(discrepancy.Issues, dto.ReconciliationIssueSyncFailed)
+			discrepancy.Error = err.Error()
+		} else if resent > 0 {
+			discrepancy.Issues = append(discrepancy.Issues, dto.ReconciliationIssueRefundResent)
+		}
+	}
+
+	// a status that could not follow the gateway is reported too, e.g. a settlement the fraud check did not accept yet.
+	// A late settlement is the settlement the gateway reports, it is listed on every report until it is refunded
+	lateSettlement := discrepancy.StatusAfter == model.PaymentStatusLateSettlement
+
+	if discrepancy.StatusAfter != discrepancy.LocalStatus {
+		discrepancy.Issues = append(discrepancy.Issues, dto.ReconciliationIssueStatusFixed)
+	} else if discrepancy.GatewayStatus != discrepancy.LocalStatus && !lateSettlement {
+		discrepancy.Issues = append(discrepancy.Issues, dto.ReconciliationIssueStatusMismatch)
+	}
+
+	if lateSettlement {
+		discrepancy.Issues = append(discrepancy.Issues, dto.ReconciliationIssueRefundOwed)
+	}
+
+	if len(discrepancy.Issues) == 0 {
+		return nil
+	}
+
+	return &discrepancy
+}
+
+// resendRefunds sends the pending refunds of a payment that the payment gateway never received once more,
+// e.g. when the gateway failed after a cancellation was committed. It returns how many refunds were sent.
+func (u paymentGatewayUsecase) resendRefunds(payment model.Payment, transactionRefunds []payment.TransactionRefund) (int, error) {
+	received := map[string]bool{}
+
+	for _, transactionRefund := range transactionRefunds {
+		received[transactionRefund.RefundKey] = true
+	}
+
+	refunds, err := u.refundRepository.FindByIdPayment(payment.ID)
+
+	if err != nil {
+		return 0, err
+	}
+
+	var resent int
+
+	for _, refund := range *refunds {
+		if refund.Status != model.RefundStatusPending || received[refund.ID] {
+			continue
+		}
+
+		if err := sendRefund(u.paymentProvider, payment, refund); err != nil {
+			return resent, err
+		}
+
+		resent++
+	}
+
+	return resent, nil
+}
+
+// logNotification records a notification, and reports whether the same notification was processed before.
+func logNotification(repos repository.Repositories, notification dto.MidtransNotificationDTO) (bool, error) {
+	_, err := repos.PaymentNotification.FindByTransaction(notification.TransactionId, notification.TransactionStatus, notification.RefundAmount)
+
+	if err == nil {
+		return true, nil
+	} else if !errors.Is(err, pkg.ErrRecordNotFound) {
+		return false, err
+	}
+
+	payload, err := json.Marshal(notification)
+
+	if err != nil {
+		return false, err
+	}
+
+	paymentNotification := model.PaymentNotification{
+		ID:                uuid.NewString(),
+		TransactionId:     notification.TransactionId,
+		TransactionStatus: notification.TransactionStatus,
+		OrderId:           notification.OrderId,
+		StatusCode:        notification.StatusCode,
+		FraudStatus:       notification.FraudStatus,
+		GrossAmount:       notification.GrossAmount,
+		RefundAmount:      notification.RefundAmount,
+		Payload:           string(payload),
+		CreatedAt:         time.Now(),
+	}
+
+	if err := repos.PaymentNotification.Create(paymentNotification); err != nil {
+		return false, err
+	}
+
+	return false, nil
+}
 
-		if err := u.historyRepository.Update(orderId, *history); err != nil {
+// completeRefunds marks the pending refunds of a payment that the payment gateway reports as refunded.
+func completeRefunds(repos repository.Repositories, payment model.Payment, transactionRefunds []payment.TransactionRefund) error {
+	if len(transactionRefunds) == 0 {
+		return nil
+	}
+
+	refunded := map[string]bool{}
+
+	for _, transactionRefund := range transactionRefunds {
+		refunded[transactionRefund.RefundKey] = true
+	}
+
+	refunds, err := repos.Refund.FindByIdPayment(payment.ID)
+
+	if err != nil {
+		return err
+	}
+
+	for _, refund := range *refunds {
+		if refund.Status != model.RefundStatusPending || !refunded[refund.ID] {
+			continue
+		}
+
+		err := repos.Refund.Update(refund.ID, model.Refund{
+			Status:    model.RefundStatusSucceeded,
+			UpdatedAt: time.Now(),
+		})
+
+		if err != nil {
 			return err
 		}
-	} else if transactionStatusRes.TransactionStatus == "pending" {
-		payment.PaymentType = transactionStatusRes.PaymentType
-		payment.UpdatedAt = time.Now()
 
-		if err := u.paymentRepository.Update(payment.ID, *payment); err != nil {
+		if err := postRefund(repos, payment, refund); err != nil {
 			return err
 		}
 	}
@@ -112,10 +400,69 @@ func (u paymentGatewayUsecase) MidtransNotification(orderId string) error {
 	return nil
 }
 
+// paymentGatewayError makes every failure of the payment provider match pkg.ErrPaymentGateway,
+// and keeps the kind of the typed gateway errors.
+func paymentGatewayError(err error) error {
+	if errors.Is(err, pkg.ErrPaymentGateway) {
+		return err
+	}
+
+	return fmt.Errorf("%w: %v", pkg.ErrPaymentGateway, err)
+}
+
+// findGatewayTransaction looks up the order and payment behind a payment gateway order id, which is
+// the order id for the rent payment and the payment id for the payments that follow it.
+// A wallet top up is not made for an order, so its order is nil.
+// The payment is locked before its order is read again with a lock, so a notification, the reconciliation,
+// a cancellation and the expiry of the same payment take turns and each sees the status the one before left.
+func findGatewayTransaction(repos repository.Repositories, gatewayOrderId string) (*model.Order, *model.Payment, error) {
+	paymentId := gatewayOrderId
+
+	order, err := repos.Order.FindById(gatewayOrderId)
+
+	if err == nil {
+		paymentId = order.PaymentId
+	} else if !errors.Is(err, pkg.ErrRecordNotFound) {
+		return nil, nil, err
+	}
+
+	payment, err := repos.Payment.FindByIdForUpdate(paymentId)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if payment.Kind == model.PaymentKindTopUp {
+		return nil, payment, nil
+	}
+
+	order, err = repos.Order.FindByIdForUpdate(payment.OrderId)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return order, payment, nil
+}
+
 func NewPaymentGatewayUsecase(
+	unitOfWork repository.UnitOfWork,
+	paymentProvider payment.PaymentProvider,
+	serverKey string,
+	commissionPercent float32,
 	orderRepo repository.OrderRepository,
 	paymentRepo repository.PaymentRepository,
 	historyRepo repository.HistoryRepository,
+	refundRepo repository.RefundRepository,
 ) PaymentGatewayUsecase {
-	return paymentGatewayUsecase{}
+	return paymentGatewayUsecase{
+		unitOfWork:        unitOfWork,
+		paymentProvider:   paymentProvider,
+		serverKey:         serverKey,
+		commissionPercent: commissionPercent,
+		orderRepository:   orderRepo,
+		paymentRepository: paymentRepo,
+		historyRepository: historyRepo,
+		refundRepository:  refundRepo,
+	}
 }
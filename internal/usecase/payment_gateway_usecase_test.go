@@ -0,0 +1,667 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	pgMidtrans "github.com/arvinpaundra/go-rent-bike/internal/midtrans"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/payment"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// the fake provider settles payments in memory, so notifications are tested without midtrans
+var fakePaymentProvider = payment.NewFakeProvider("http://localhost:8080")
+var paymentGatewayUsecaseTest = NewPaymentGatewayUsecase(
+	&pkg.UnitOfWork,
+	fakePaymentProvider,
+	"SB-Mid-server-test",
+	10,
+	&pkg.OrderRepository,
+	&pkg.PaymentRepository,
+	&pkg.HistoryRepository,
+	&pkg.RefundRepository,
+)
+
+func TestPaymentGatewayUsecase_SyncTransactionSettlement(t *testing.T) {
+	orderId := "5e6f7a8b-9c0d-4e1f-9a2b-3c4d5e6f7a8c"
+
+	order := &model.Order{
+		ID:        orderId,
+		PaymentId: "6f7a8b9c-0d1e-4f2a-8b3c-4d5e6f7a8b9d",
+		Status:    model.OrderStatusPendingPayment,
+	}
+
+	rentPayment := &model.Payment{
+		ID:            "6f7a8b9c-0d1e-4f2a-8b3c-4d5e6f7a8b9d",
+		OrderId:       orderId,
+		Kind:          model.PaymentKindRent,
+		Amount:        75000,
+		PaymentStatus: "pending",
+	}
+
+	fakePaymentProvider.CreateUrlTransactionWithGateway(dto.PaymentGateway{OrderId: orderId, GrossAmt: 75000})
+
+	_, err := fakePaymentProvider.Simulate(orderId, "settlement", "gopay")
+	assert.Nil(t, err)
+
+	pkg.OrderRepository.Mock.On("FindById", orderId).Return(order, nil)
+	pkg.OrderRepository.Mock.On("FindByIdForUpdate", orderId).Return(order, nil)
+	pkg.PaymentRepository.Mock.On("FindByIdForUpdate", rentPayment.ID).Return(rentPayment, nil)
+	pkg.PaymentRepository.Mock.On("Update", rentPayment.ID, mock.MatchedBy(func(payment model.Payment) bool {
+		return payment.PaymentStatus == "settlement" && payment.PaymentType == "gopay"
+	})).Return(nil)
+	pkg.OrderRepository.Mock.On("Update", orderId, mock.MatchedBy(func(order model.Order) bool {
+		return order.Status == model.OrderStatusPaid
+	})).Return(nil)
+	pkg.OrderStatusHistoryRepository.Mock.On("Create", mock.MatchedBy(func(statusHistory model.OrderStatusHistory) bool {
+		return statusHistory.OrderId == orderId && statusHistory.ToStatus == model.OrderStatusPaid && statusHistory.Actor == "midtrans"
+	})).Return(nil)
+	pkg.HistoryRepository.Mock.On("FindByIdOrder", orderId).Return(&model.History{OrderId: orderId, RentStatus: "pending_payment"}, nil)
+	pkg.HistoryRepository.Mock.On("Update", orderId, mock.MatchedBy(func(history model.History) bool {
+		return history.RentStatus == "paid"
+	})).Return(nil)
+
+	// the order rents bikes of two renters, each is owed its subtotal minus the commission of 10%
+	pkg.OrderDetailRepository.Mock.On("FindByIdOrder", orderId).Return(&[]model.OrderDetail{
+		{OrderId: orderId, Subtotal: 45000, Bike: &model.Bike{RenterId: "a1b2c3d4-e5f6-4a7b-8c9d-0e1f2a3b4c5d"}},
+		{OrderId: orderId, Subtotal: 30000, Bike: &model.Bike{RenterId: "b2c3d4e5-f6a7-4b8c-9d0e-1f2a3b4c5d6e"}},
+	}, nil)
+
+	var entries []model.LedgerEntry
+
+	pkg.LedgerRepository.Mock.On("Create", mock.MatchedBy(func(ledgerEntries []model.LedgerEntry) bool {
+		return len(ledgerEntries) > 0 && ledgerEntries[0].PaymentId == rentPayment.ID
+	})).Run(func(args mock.Arguments) {
+		entries = args.Get(0).([]model.LedgerEntry)
+	}).Return(nil)
+
+	err = paymentGatewayUsecaseTest.SyncTransaction(orderId)
+
+	assert.Nil(t, err)
+	assert.Equal(t, model.OrderStatusPaid, order.Status)
+
+	credits := map[string]float32{}
+
+	for _, entry := range entries {
+		credits[entry.Account+":"+entry.RenterId] += entry.Credit - entry.Debit
+	}
+
+	assert.Equal(t, map[string]float32{
+		"gateway_clearing:": -75000,
+		"renter_payable:a1b2c3d4-e5f6-4a7b-8c9d-0e1f2a3b4c5d":      40500,
+		"platform_commission:a1b2c3d4-e5f6-4a7b-8c9d-0e1f2a3b4c5d": 4500,
+		"renter_payable:b2c3d4e5-f6a7-4b8c-9d0e-1f2a3b4c5d6e":      27000,
+		"platform_commission:b2c3d4e5-f6a7-4b8c-9d0e-1f2a3b4c5d6e": 3000,
+	}, credits)
+}
+
+func TestPaymentGatewayUsecase_SyncTransactionTopUp(t *testing.T) {
+	paymentId := "8b9c0d1e-2f3a-4b4c-8d5e-6f7a8b9c0d1f"
+
+	topUpPayment := &model.Payment{
+		ID:            "8b9c0d1e-2f3a-4b4c-8d5e-6f7a8b9c0d1f",
+		WalletId:      "9c0d1e2f-3a4b-4c5d-9e6f-7a8b9c0d1e2a",
+		Kind:          model.PaymentKindTopUp,
+		Amount:        50000,
+		PaymentStatus: "pending",
+	}
+
+	wallet := &model.Wallet{
+		ID:      "9c0d1e2f-3a4b-4c5d-9e6f-7a8b9c0d1e2a",
+		UserId:  "0d1e2f3a-4b5c-4d6e-8f7a-8b9c0d1e2f3b",
+		Balance: 10000,
+	}
+
+	fakePaymentProvider.CreateUrlTransactionWithGateway(dto.PaymentGateway{OrderId: paymentId, GrossAmt: 50000})
+
+	_, err := fakePaymentProvider.Simulate(paymentId, "settlement", "bank_transfer")
+	assert.Nil(t, err)
+
+	// a top up has no order, the gateway order id is the payment id
+	pkg.OrderRepository.Mock.On("FindById", paymentId).Return((*model.Order)(nil), pkg.ErrRecordNotFound)
+	pkg.PaymentRepository.Mock.On("FindByIdForUpdate", paymentId).Return(topUpPayment, nil)
+	pkg.PaymentRepository.Mock.On("Update", paymentId, mock.MatchedBy(func(payment model.Payment) bool {
+		return payment.PaymentStatus == "settlement"
+	})).Return(nil)
+
+	pkg.WalletRepository.Mock.On("FindByIdForUpdate", wallet.ID).Return(wallet, nil)
+	pkg.WalletRepository.Mock.On("UpdateBalance", wallet.ID, mock.MatchedBy(func(walletUC model.Wallet) bool {
+		return walletUC.Balance == 60000
+	})).Return(nil)
+	pkg.WalletRepository.Mock.On("CreateTransaction", mock.MatchedBy(func(transaction model.WalletTransaction) bool {
+		return transaction.PaymentId == paymentId && transaction.Type == model.WalletTransactionTopUp && transaction.Amount == 50000 && transaction.BalanceAfter == 60000
+	})).Return(nil)
+
+	pkg.LedgerRepository.Mock.On("Create", mock.MatchedBy(func(entries []model.LedgerEntry) bool {
+		return len(entries) == 2 && entries[0].PaymentId == paymentId &&
+			entries[0].Account == model.LedgerAccountGatewayClearing && entries[0].Debit == 50000 &&
+			entries[1].Account == model.LedgerAccountCustomerWallet && entries[1].Credit == 50000
+	})).Return(nil)
+
+	err = paymentGatewayUsecaseTest.SyncTransaction(paymentId)
+
+	assert.Nil(t, err)
+	assert.Equal(t, float32(60000), wallet.Balance)
+
+	// a repeated settlement is not credited twice
+	topUpPayment.PaymentStatus = model.PaymentStatusSettlement
+
+	err = paymentGatewayUsecaseTest.SyncTransaction(paymentId)
+
+	assert.Nil(t, err)
+	assert.Equal(t, float32(60000), wallet.Balance)
+}
+
+func TestPaymentGatewayUsecase_SyncTransactionUnknownTransaction(t *testing.T) {
+	err := paymentGatewayUsecaseTest.SyncTransaction("7a8b9c0d-1e2f-4a3b-9c4d-5e6f7a8b9c0e")
+
+	assert.Equal(t, pkg.ErrRecordNotFound, err)
+}
+
+func TestPaymentGatewayUsecase_MidtransNotification(t *testing.T) {
+	orderId := "8b9c0d1e-2f3a-4b4c-9d5e-6f7a8b9c0d1f"
+
+	signature := pgMidtrans.NotificationSignature(orderId, "200", "75000.00", "SB-Mid-server-test")
+
+	testCases := []struct {
+		name         string
+		notification dto.MidtransNotificationDTO
+		err          error
+	}{
+		{
+			name:         "valid signature",
+			notification: dto.MidtransNotificationDTO{OrderId: orderId, TransactionId: "tx-1", TransactionStatus: "settlement", StatusCode: "200", GrossAmount: "75000.00", SignatureKey: signature},
+			// the signature is valid, so the transaction is looked up in the payment provider
+			err: pkg.ErrRecordNotFound,
+		},
+		{
+			name:         "signature of another amount",
+			notification: dto.MidtransNotificationDTO{OrderId: orderId, TransactionId: "tx-1", TransactionStatus: "settlement", StatusCode: "200", GrossAmount: "1.00", SignatureKey: signature},
+			err:          pkg.ErrInvalidSignature,
+		},
+		{
+			name:         "unsigned",
+			notification: dto.MidtransNotificationDTO{OrderId: orderId, TransactionId: "tx-1", TransactionStatus: "settlement", StatusCode: "200", GrossAmount: "75000.00"},
+			err:          pkg.ErrInvalidSignature,
+		},
+		{
+			name:         "missing order id",
+			notification: dto.MidtransNotificationDTO{TransactionId: "tx-1", TransactionStatus: "settlement", StatusCode: "200", GrossAmount: "75000.00", SignatureKey: signature},
+			err:          pkg.ErrInvalidNotification,
+		},
+		{
+			name:         "missing transaction id",
+			notification: dto.MidtransNotificationDTO{OrderId: orderId, TransactionStatus: "settlement", StatusCode: "200", GrossAmount: "75000.00", SignatureKey: signature},
+			err:          pkg.ErrInvalidNotification,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := paymentGatewayUsecaseTest.MidtransNotification(tc.notification)
+
+			assert.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func TestPaymentGatewayUsecase_MidtransNotificationDuplicate(t *testing.T) {
+	orderId := "9c0d1e2f-3a4b-4c5d-8e6f-7a8b9c0d1e2a"
+
+	fakePaymentProvider.CreateUrlTransactionWithGateway(dto.PaymentGateway{OrderId: orderId, GrossAmt: 75000})
+
+	transaction, err := fakePaymentProvider.Simulate(orderId, "settlement", "gopay")
+	assert.Nil(t, err)
+
+	notification := dto.MidtransNotificationDTO{
+		OrderId:           orderId,
+		TransactionId:     transaction.TransactionId,
+		TransactionStatus: "settlement",
+		StatusCode:        "200",
+		GrossAmount:       "75000.00",
+		SignatureKey:      pgMidtrans.NotificationSignature(orderId, "200", "75000.00", "SB-Mid-server-test"),
+	}
+
+	// the notification was processed before, so neither the order nor the payment is looked up again
+	pkg.PaymentNotificationRepository.Mock.On("FindByTransaction", transaction.TransactionId, "settlement", "").Return(&model.PaymentNotification{
+		ID:                "0d1e2f3a-4b5c-4d6e-9f7a-8b9c0d1e2f3b",
+		TransactionId:     transaction.TransactionId,
+		TransactionStatus: "settlement",
+		OrderId:           orderId,
+	}, nil)
+
+	err = paymentGatewayUsecaseTest.MidtransNotification(notification)
+
+	assert.Nil(t, err)
+	pkg.OrderRepository.Mock.AssertNotCalled(t, "FindById", orderId)
+}
+
+func TestPaymentGatewayUsecase_MidtransNotificationNoRegression(t *testing.T) {
+	orderId := "1e2f3a4b-5c6d-4e7f-8a9b-0c1d2e3f4a5c"
+
+	order := &model.Order{
+		ID:        orderId,
+		PaymentId: "2f3a4b5c-6d7e-4f8a-9b0c-1d2e3f4a5b6d",
+		Status:    model.OrderStatusPaid,
+	}
+
+	rentPayment := &model.Payment{
+		ID:            "2f3a4b5c-6d7e-4f8a-9b0c-1d2e3f4a5b6d",
+		OrderId:       orderId,
+		Kind:          model.PaymentKindRent,
+		Amount:        75000,
+		PaymentStatus: model.PaymentStatusSettlement,
+	}
+
+	// midtrans still reports pending, e.g. a late notification delivered after the settlement
+	fakePaymentProvider.CreateUrlTransactionWithGateway(dto.PaymentGateway{OrderId: orderId, GrossAmt: 75000})
+
+	transaction, err := fakePaymentProvider.CheckTransaction(orderId)
+	assert.Nil(t, err)
+
+	notification := dto.MidtransNotificationDTO{
+		OrderId:           orderId,
+		TransactionId:     transaction.TransactionId,
+		TransactionStatus: "pending",
+		StatusCode:        "201",
+		GrossAmount:       "75000.00",
+		SignatureKey:      pgMidtrans.NotificationSignature(orderId, "201", "75000.00", "SB-Mid-server-test"),
+	}
+
+	pkg.PaymentNotificationRepository.Mock.On("FindByTransaction", transaction.TransactionId, "pending", "").Return((*model.PaymentNotification)(nil), pkg.ErrRecordNotFound)
+	pkg.PaymentNotificationRepository.Mock.On("Create", mock.MatchedBy(func(paymentNotification model.PaymentNotification) bool {
+		return paymentNotification.OrderId == orderId && paymentNotification.TransactionStatus == "pending"
+	})).Return(nil)
+	pkg.OrderRepository.Mock.On("FindById", orderId).Return(order, nil)
+	pkg.OrderRepository.Mock.On("FindByIdForUpdate", orderId).Return(order, nil)
+	pkg.PaymentRepository.Mock.On("FindByIdForUpdate", rentPayment.ID).Return(rentPayment, nil)
+
+	err = paymentGatewayUsecaseTest.MidtransNotification(notification)
+
+	assert.Nil(t, err)
+	assert.Equal(t, model.PaymentStatusSettlement, rentPayment.PaymentStatus)
+	assert.Equal(t, model.OrderStatusPaid, order.Status)
+	pkg.PaymentRepository.Mock.AssertNotCalled(t, "Update", rentPayment.ID, mock.Anything)
+}
+
+func TestPaymentGatewayUsecase_MidtransNotificationLateSettlement(t *testing.T) {
+	orderId := "3a4b5c6d-7e8f-4a9b-8c0d-1e2f3a4b5c6e"
+
+	// the order expired here, but the customer still paid through its payment link
+	order := &model.Order{
+		ID:        orderId,
+		PaymentId: "4b5c6d7e-8f9a-4b0c-9d1e-2f3a4b5c6d7f",
+		Status:    model.OrderStatusExpired,
+	}
+
+	rentPayment := &model.Payment{
+		ID:            "4b5c6d7e-8f9a-4b0c-9d1e-2f3a4b5c6d7f",
+		OrderId:       orderId,
+		Kind:          model.PaymentKindRent,
+		Amount:        75000,
+		PaymentStatus: model.PaymentStatusExpire,
+	}
+
+	fakePaymentProvider.CreateUrlTransactionWithGateway(dto.PaymentGateway{OrderId: orderId, GrossAmt: 75000})
+
+	transaction, err := fakePaymentProvider.Simulate(orderId, "settlement", "gopay")
+	assert.Nil(t, err)
+
+	notification := dto.MidtransNotificationDTO{
+		OrderId:           orderId,
+		TransactionId:     transaction.TransactionId,
+		TransactionStatus: "settlement",
+		StatusCode:        "200",
+		GrossAmount:       "75000.00",
+		SignatureKey:      pgMidtrans.NotificationSignature(orderId, "200", "75000.00", "SB-Mid-server-test"),
+	}
+
+	pkg.PaymentNotificationRepository.Mock.On("FindByTransaction", transaction.TransactionId, "settlement", "").Return((*model.PaymentNotification)(nil), pkg.ErrRecordNotFound)
+	pkg.PaymentNotificationRepository.Mock.On("Create", mock.MatchedBy(func(paymentNotification model.PaymentNotification) bool {
+		return paymentNotification.OrderId == orderId && paymentNotification.TransactionStatus == "settlement"
+	})).Return(nil)
+	pkg.OrderRepository.Mock.On("FindById", orderId).Return(order, nil)
+	pkg.OrderRepository.Mock.On("FindByIdForUpdate", orderId).Return(order, nil)
+	pkg.PaymentRepository.Mock.On("FindByIdForUpdate", rentPayment.ID).Return(rentPayment, nil)
+	pkg.PaymentRepository.Mock.On("Update", rentPayment.ID, mock.MatchedBy(func(payment model.Payment) bool {
+		return payment.PaymentStatus == model.PaymentStatusLateSettlement && payment.PaymentType == "gopay"
+	})).Return(nil)
+
+	err = paymentGatewayUsecaseTest.MidtransNotification(notification)
+
+	assert.Nil(t, err)
+	assert.Equal(t, model.PaymentStatusLateSettlement, rentPayment.PaymentStatus)
+
+	// the order stays expired and nobody is owed the money but the customer
+	assert.Equal(t, model.OrderStatusExpired, order.Status)
+	pkg.OrderRepository.Mock.AssertNotCalled(t, "Update", orderId, mock.Anything)
+	pkg.LedgerRepository.Mock.AssertNotCalled(t, "Create", mock.MatchedBy(func(ledgerEntries []model.LedgerEntry) bool {
+		return len(ledgerEntries) > 0 && ledgerEntries[0].PaymentId == rentPayment.ID
+	}))
+}
+
+func TestPaymentGatewayUsecase_SyncTransactionRefund(t *testing.T) {
+	orderId := "6a7b8c9d-0e1f-4a2b-8c3d-4e5f6a7b8c9e"
+	refundId := "7b8c9d0e-1f2a-4b3c-9d4e-5f6a7b8c9d0f"
+
+	order := &model.Order{
+		ID:        orderId,
+		PaymentId: "8c9d0e1f-2a3b-4c4d-8e5f-6a7b8c9d0e1a",
+		Status:    model.OrderStatusPaid,
+	}
+
+	rentPayment := &model.Payment{
+		ID:            "8c9d0e1f-2a3b-4c4d-8e5f-6a7b8c9d0e1a",
+		OrderId:       orderId,
+		Kind:          model.PaymentKindRent,
+		Amount:        75000,
+		PaymentStatus: model.PaymentStatusPartialRefund,
+	}
+
+	fakePaymentProvider.CreateUrlTransactionWithGateway(dto.PaymentGateway{OrderId: orderId, GrossAmt: 75000})
+
+	_, err := fakePaymentProvider.Simulate(orderId, "settlement", "gopay")
+	assert.Nil(t, err)
+	assert.Nil(t, fakePaymentProvider.RefundTransaction(orderId, refundId, 25000, "bike was damaged"))
+
+	pkg.OrderRepository.Mock.On("FindById", orderId).Return(order, nil)
+	pkg.OrderRepository.Mock.On("FindByIdForUpdate", orderId).Return(order, nil)
+	pkg.PaymentRepository.Mock.On("FindByIdForUpdate", rentPayment.ID).Return(rentPayment, nil)
+	pkg.PaymentRepository.Mock.On("Update", rentPayment.ID, mock.MatchedBy(func(payment model.Payment) bool {
+		return payment.PaymentStatus == model.PaymentStatusPartialRefund
+	})).Return(nil)
+	pkg.RefundRepository.Mock.On("FindByIdPayment", rentPayment.ID).Return(&[]model.Refund{
+		{ID: "9d0e1f2a-3b4c-4d5e-9f6a-7b8c9d0e1f2b", PaymentId: rentPayment.ID, Amount: 10000, Status: model.RefundStatusPending},
+		{ID: refundId, PaymentId: rentPayment.ID, Amount: 25000, Status: model.RefundStatusPending},
+	}, nil)
+	pkg.RefundRepository.Mock.On("Update", refundId, mock.MatchedBy(func(refund model.Refund) bool {
+		return refund.Status == model.RefundStatusSucceeded
+	})).Return(nil)
+
+	// the refund is taken back from the renter and the commission in proportion to the settlement
+	renterId := "0a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d"
+
+	pkg.LedgerRepository.Mock.On("FindByIdPayment", rentPayment.ID).Return(&[]model.LedgerEntry{
+		{Account: model.LedgerAccountGatewayClearing, PaymentId: rentPayment.ID, Debit: 75000},
+		{Account: model.LedgerAccountRenterPayable, RenterId: renterId, PaymentId: rentPayment.ID, Credit: 67500},
+		{Account: model.LedgerAccountPlatformCommission, RenterId: renterId, PaymentId: rentPayment.ID, Credit: 7500},
+	}, nil)
+
+	var entries []model.LedgerEntry
+
+	pkg.LedgerRepository.Mock.On("Create", mock.MatchedBy(func(ledgerEntries []model.LedgerEntry) bool {
+		return len(ledgerEntries) > 0 && ledgerEntries[0].RefundId == refundId
+	})).Run(func(args mock.Arguments) {
+		entries = args.Get(0).([]model.LedgerEntry)
+	}).Return(nil)
+
+	err = paymentGatewayUsecaseTest.SyncTransaction(orderId)
+
+	assert.Nil(t, err)
+	pkg.RefundRepository.Mock.AssertCalled(t, "Update", refundId, mock.Anything)
+
+	assert.Len(t, entries, 3)
+	assert.Equal(t, model.LedgerEntry{Account: model.LedgerAccountGatewayClearing, Credit: 25000}, model.LedgerEntry{Account: entries[0].Account, Credit: entries[0].Credit})
+	assert.Equal(t, model.LedgerEntry{Account: model.LedgerAccountRenterPayable, RenterId: renterId, Debit: 22500}, model.LedgerEntry{Account: entries[1].Account, RenterId: entries[1].RenterId, Debit: entries[1].Debit})
+	assert.Equal(t, model.LedgerEntry{Account: model.LedgerAccountPlatformCommission, RenterId: renterId, Debit: 2500}, model.LedgerEntry{Account: entries[2].Account, RenterId: entries[2].RenterId, Debit: entries[2].Debit})
+	// the gateway does not know the other refund yet, so it stays pending
+	pkg.RefundRepository.Mock.AssertNotCalled(t, "Update", "9d0e1f2a-3b4c-4d5e-9f6a-7b8c9d0e1f2b", mock.Anything)
+	assert.Equal(t, model.OrderStatusPaid, order.Status)
+}
+
+func TestPaymentGatewayUsecase_ReconcilePayments(t *testing.T) {
+	now := time.Date(2022, 11, 21, 2, 0, 0, 0, time.UTC)
+
+	// paid in midtrans, but the notification never arrived
+	paidOrder := &model.Order{
+		ID:        "0e1f2a3b-4c5d-4e6f-8a7b-8c9d0e1f2a3c",
+		PaymentId: "1f2a3b4c-5d6e-4f7a-9b8c-9d0e1f2a3b4d",
+		Status:    model.OrderStatusPendingPayment,
+	}
+	paidPayment := &model.Payment{
+		ID:            "1f2a3b4c-5d6e-4f7a-9b8c-9d0e1f2a3b4d",
+		OrderId:       paidOrder.ID,
+		Kind:          model.PaymentKindRent,
+		Amount:        75000,
+		PaymentStatus: model.PaymentStatusPending,
+	}
+
+	// the customer never opened the payment link
+	unopenedPayment := model.Payment{
+		ID:            "2a3b4c5d-6e7f-4a8b-8c9d-0e1f2a3b4c5e",
+		OrderId:       "3b4c5d6e-7f8a-4b9c-9d0e-1f2a3b4c5d6f",
+		Kind:          model.PaymentKindRent,
+		Amount:        75000,
+		PaymentStatus: model.PaymentStatusPending,
+	}
+
+	// settled here, but unknown to midtrans
+	unknownPayment := model.Payment{
+		ID:            "4c5d6e7f-8a9b-4c0d-8e1f-2a3b4c5d6e7a",
+		OrderId:       "5d6e7f8a-9b0c-4d1e-9f2a-3b4c5d6e7f8b",
+		Kind:          model.PaymentKindRent,
+		Amount:        75000,
+		PaymentStatus: model.PaymentStatusPartialRefund,
+	}
+
+	// midtrans charges another amount than the payment
+	mismatchOrder := &model.Order{
+		ID:        "6e7f8a9b-0c1d-4e2f-8a3b-4c5d6e7f8a9c",
+		PaymentId: "7f8a9b0c-1d2e-4f3a-9b4c-5d6e7f8a9b0d",
+		Status:    model.OrderStatusPendingPayment,
+	}
+	mismatchPayment := &model.Payment{
+		ID:            "7f8a9b0c-1d2e-4f3a-9b4c-5d6e7f8a9b0d",
+		OrderId:       mismatchOrder.ID,
+		Kind:          model.PaymentKindRent,
+		Amount:        75000,
+		PaymentStatus: model.PaymentStatusPending,
+	}
+
+	// settled after it expired here and not refunded yet
+	lateOrder := &model.Order{
+		ID:        "8a9b0c1d-2e3f-4a4b-8c5d-6e7f8a9b0c1e",
+		PaymentId: "9b0c1d2e-3f4a-4b5c-9d6e-7f8a9b0c1d2f",
+		Status:    model.OrderStatusExpired,
+	}
+	latePayment := &model.Payment{
+		ID:            "9b0c1d2e-3f4a-4b5c-9d6e-7f8a9b0c1d2f",
+		OrderId:       lateOrder.ID,
+		Kind:          model.PaymentKindRent,
+		Amount:        75000,
+		PaymentStatus: model.PaymentStatusLateSettlement,
+	}
+
+	fakePaymentProvider.CreateUrlTransactionWithGateway(dto.PaymentGateway{OrderId: paidOrder.ID, GrossAmt: 75000})
+	fakePaymentProvider.CreateUrlTransactionWithGateway(dto.PaymentGateway{OrderId: mismatchOrder.ID, GrossAmt: 50000})
+	fakePaymentProvider.CreateUrlTransactionWithGateway(dto.PaymentGateway{OrderId: lateOrder.ID, GrossAmt: 75000})
+
+	// canceled and refunded here, but the payment gateway failed before it got the refund
+	canceledOrder := &model.Order{
+		ID:        "5e6f7a8b-9c0d-4e1f-8a2b-3c4d5e6f7a8d",
+		PaymentId: "6f7a8b9c-0d1e-4f2a-9b3c-4d5e6f7a8b9e",
+		Status:    model.OrderStatusCanceled,
+	}
+	canceledPayment := &model.Payment{
+		ID:            "6f7a8b9c-0d1e-4f2a-9b3c-4d5e6f7a8b9e",
+		OrderId:       canceledOrder.ID,
+		Kind:          model.PaymentKindRent,
+		Amount:        75000,
+		PaymentStatus: model.PaymentStatusRefund,
+	}
+	unsentRefund := model.Refund{
+		ID:        "7a8b9c0d-1e2f-4a3b-8c4d-5e6f7a8b9c0f",
+		PaymentId: canceledPayment.ID,
+		Kind:      model.RefundKindPayment,
+		Amount:    75000,
+		Reason:    "order canceled by customer",
+		Status:    model.RefundStatusPending,
+	}
+
+	fakePaymentProvider.CreateUrlTransactionWithGateway(dto.PaymentGateway{OrderId: canceledOrder.ID, GrossAmt: 75000})
+
+	_, err := fakePaymentProvider.Simulate(paidOrder.ID, "settlement", "gopay")
+	assert.Nil(t, err)
+
+	_, err = fakePaymentProvider.Simulate(lateOrder.ID, "settlement", "gopay")
+	assert.Nil(t, err)
+
+	_, err = fakePaymentProvider.Simulate(canceledOrder.ID, "settlement", "gopay")
+	assert.Nil(t, err)
+
+	pkg.PaymentRepository.Mock.On("FindUnreconciled").Return(&[]model.Payment{*paidPayment, unopenedPayment, unknownPayment, *mismatchPayment, *latePayment, *canceledPayment}, nil)
+
+	pkg.OrderRepository.Mock.On("FindById", paidOrder.ID).Return(paidOrder, nil)
+	pkg.OrderRepository.Mock.On("FindByIdForUpdate", paidOrder.ID).Return(paidOrder, nil)
+	pkg.PaymentRepository.Mock.On("FindById", paidPayment.ID).Return(paidPayment, nil)
+	pkg.PaymentRepository.Mock.On("FindByIdForUpdate", paidPayment.ID).Return(paidPayment, nil)
+	pkg.PaymentRepository.Mock.On("Update", paidPayment.ID, mock.MatchedBy(func(payment model.Payment) bool {
+		return payment.PaymentStatus == model.PaymentStatusSettlement
+	})).Return(nil)
+	pkg.OrderRepository.Mock.On("Update", paidOrder.ID, mock.Anything).Return(nil)
+	pkg.OrderStatusHistoryRepository.Mock.On("Create", mock.MatchedBy(func(statusHistory model.OrderStatusHistory) bool {
+		return statusHistory.OrderId == paidOrder.ID && statusHistory.Actor == "reconciliation"
+	})).Return(nil)
+	pkg.HistoryRepository.Mock.On("FindByIdOrder", paidOrder.ID).Return(&model.History{OrderId: paidOrder.ID, RentStatus: "pending_payment"}, nil)
+	pkg.HistoryRepository.Mock.On("Update", paidOrder.ID, mock.Anything).Return(nil)
+	pkg.OrderDetailRepository.Mock.On("FindByIdOrder", paidOrder.ID).Return(&[]model.OrderDetail{
+		{OrderId: paidOrder.ID, Subtotal: 75000, Bike: &model.Bike{RenterId: "2b3c4d5e-6f7a-4b8c-9d0e-1f2a3b4c5d6f"}},
+	}, nil)
+	pkg.LedgerRepository.Mock.On("Create", mock.MatchedBy(func(ledgerEntries []model.LedgerEntry) bool {
+		return len(ledgerEntries) > 0 && ledgerEntries[0].PaymentId == paidPayment.ID
+	})).Return(nil)
+
+	pkg.OrderRepository.Mock.On("FindById", mismatchOrder.ID).Return(mismatchOrder, nil)
+	pkg.OrderRepository.Mock.On("FindByIdForUpdate", mismatchOrder.ID).Return(mismatchOrder, nil)
+	pkg.PaymentRepository.Mock.On("FindById", mismatchPayment.ID).Return(mismatchPayment, nil)
+	pkg.PaymentRepository.Mock.On("FindByIdForUpdate", mismatchPayment.ID).Return(mismatchPayment, nil)
+
+	pkg.OrderRepository.Mock.On("FindById", lateOrder.ID).Return(lateOrder, nil)
+	pkg.OrderRepository.Mock.On("FindById", canceledOrder.ID).Return(canceledOrder, nil)
+	pkg.OrderRepository.Mock.On("FindByIdForUpdate", canceledOrder.ID).Return(canceledOrder, nil)
+	pkg.PaymentRepository.Mock.On("FindById", canceledPayment.ID).Return(canceledPayment, nil)
+	pkg.PaymentRepository.Mock.On("FindByIdForUpdate", canceledPayment.ID).Return(canceledPayment, nil)
+
+	pkg.RefundRepository.Mock.On("FindByIdPayment", paidPayment.ID).Return(&[]model.Refund{}, nil)
+	pkg.RefundRepository.Mock.On("FindByIdPayment", mismatchPayment.ID).Return(&[]model.Refund{}, nil)
+	pkg.RefundRepository.Mock.On("FindByIdPayment", latePayment.ID).Return(&[]model.Refund{}, nil)
+	pkg.RefundRepository.Mock.On("FindByIdPayment", canceledPayment.ID).Return(&[]model.Refund{unsentRefund}, nil)
+	pkg.OrderRepository.Mock.On("FindByIdForUpdate", lateOrder.ID).Return(lateOrder, nil)
+	pkg.PaymentRepository.Mock.On("FindById", latePayment.ID).Return(latePayment, nil)
+	pkg.PaymentRepository.Mock.On("FindByIdForUpdate", latePayment.ID).Return(latePayment, nil)
+
+	report, err := paymentGatewayUsecaseTest.ReconcilePayments(now)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, report)
+
+	assert.Equal(t, now, report.GeneratedAt)
+	assert.Equal(t, 6, report.Checked)
+	assert.Equal(t, 1, report.Fixed)
+	assert.Len(t, report.Discrepancies, 5)
+
+	assert.Equal(t, paidPayment.ID, report.Discrepancies[0].PaymentId)
+	assert.Equal(t, model.PaymentStatusPending, report.Discrepancies[0].LocalStatus)
+	assert.Equal(t, model.PaymentStatusSettlement, report.Discrepancies[0].StatusAfter)
+	assert.Equal(t, []string{dto.ReconciliationIssueStatusFixed}, report.Discrepancies[0].Issues)
+	assert.Equal(t, model.OrderStatusPaid, paidOrder.Status)
+
+	assert.Equal(t, unknownPayment.ID, report.Discrepancies[1].PaymentId)
+	assert.Equal(t, []string{dto.ReconciliationIssueCheckFailed}, report.Discrepancies[1].Issues)
+
+	assert.Equal(t, mismatchPayment.ID, report.Discrepancies[2].PaymentId)
+	assert.Equal(t, "50000.00", report.Discrepancies[2].GatewayAmount)
+	assert.Equal(t, []string{dto.ReconciliationIssueAmountMismatch}, report.Discrepancies[2].Issues)
+	pkg.PaymentRepository.Mock.AssertNotCalled(t, "Update", mismatchPayment.ID, mock.Anything)
+
+	assert.Equal(t, latePayment.ID, report.Discrepancies[3].PaymentId)
+	assert.Equal(t, model.PaymentStatusSettlement, report.Discrepancies[3].GatewayStatus)
+	assert.Equal(t, []string{dto.ReconciliationIssueRefundOwed}, report.Discrepancies[3].Issues)
+	pkg.PaymentRepository.Mock.AssertNotCalled(t, "Update", latePayment.ID, mock.Anything)
+
+	// the refund is sent again under its own refund key, the gateway still reported the settlement when it was checked
+	assert.Equal(t, canceledPayment.ID, report.Discrepancies[4].PaymentId)
+	assert.Equal(t, []string{dto.ReconciliationIssueRefundResent, dto.ReconciliationIssueStatusMismatch}, report.Discrepancies[4].Issues)
+
+	transaction, err := fakePaymentProvider.CheckTransaction(canceledOrder.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, model.PaymentStatusRefund, transaction.TransactionStatus)
+	assert.Len(t, transaction.Refunds, 1)
+	assert.Equal(t, unsentRefund.ID, transaction.Refunds[0].RefundKey)
+}
+
+func TestCanTransitionPaymentStatus(t *testing.T) {
+	assert.True(t, model.CanTransitionPaymentStatus(model.PaymentStatusPending, model.PaymentStatusSettlement))
+	assert.True(t, model.CanTransitionPaymentStatus(model.PaymentStatusSettlement, model.PaymentStatusRefund))
+	assert.False(t, model.CanTransitionPaymentStatus(model.PaymentStatusSettlement, model.PaymentStatusPending))
+	assert.False(t, model.CanTransitionPaymentStatus(model.PaymentStatusSettlement, model.PaymentStatusSettlement))
+	assert.False(t, model.CanTransitionPaymentStatus(model.PaymentStatusExpire, model.PaymentStatusSettlement))
+	assert.True(t, model.CanTransitionPaymentStatus(model.PaymentStatusExpire, model.PaymentStatusLateSettlement))
+	assert.True(t, model.CanTransitionPaymentStatus(model.PaymentStatusCancel, model.PaymentStatusLateSettlement))
+	assert.False(t, model.CanTransitionPaymentStatus(model.PaymentStatusSettlement, model.PaymentStatusLateSettlement))
+	assert.True(t, model.CanTransitionPaymentStatus(model.PaymentStatusLateSettlement, model.PaymentStatusRefund))
+}
+
+func TestFindGatewayTransaction_RentPayment(t *testing.T) {
+	orderId := "0a1b2c3d-4e5f-4a6b-8c7d-8e9f0a1b2c3d"
+
+	order := &model.Order{
+		ID:        "0a1b2c3d-4e5f-4a6b-8c7d-8e9f0a1b2c3d",
+		PaymentId: "1b2c3d4e-5f6a-4b7c-9d8e-9f0a1b2c3d4e",
+		Status:    model.OrderStatusPendingPayment,
+	}
+
+	payment := &model.Payment{
+		ID:            "1b2c3d4e-5f6a-4b7c-9d8e-9f0a1b2c3d4e",
+		OrderId:       "0a1b2c3d-4e5f-4a6b-8c7d-8e9f0a1b2c3d",
+		Kind:          model.PaymentKindRent,
+		PaymentStatus: "pending",
+	}
+
+	// the order was expired while the payment lock was awaited
+	lockedOrder := *order
+	lockedOrder.Status = model.OrderStatusExpired
+
+	pkg.OrderRepository.Mock.On("FindById", orderId).Return(order, nil)
+	pkg.OrderRepository.Mock.On("FindByIdForUpdate", orderId).Return(&lockedOrder, nil)
+	pkg.PaymentRepository.Mock.On("FindByIdForUpdate", payment.ID).Return(payment, nil)
+
+	resultOrder, resultPayment, err := findGatewayTransaction(pkg.UnitOfWork.Repositories, orderId)
+
+	assert.Nil(t, err)
+	assert.Equal(t, order.ID, resultOrder.ID)
+	assert.Equal(t, model.OrderStatusExpired, resultOrder.Status)
+	assert.Equal(t, payment.ID, resultPayment.ID)
+	pkg.PaymentRepository.Mock.AssertNotCalled(t, "FindById", payment.ID)
+}
+
+func TestFindGatewayTransaction_FollowUpPayment(t *testing.T) {
+	paymentId := "2c3d4e5f-6a7b-4c8d-8e9f-0a1b2c3d4e5f"
+
+	payment := &model.Payment{
+		ID:            "2c3d4e5f-6a7b-4c8d-8e9f-0a1b2c3d4e5f",
+		OrderId:       "3d4e5f6a-7b8c-4d9e-9f0a-1b2c3d4e5f6a",
+		Kind:          model.PaymentKindLateFee,
+		PaymentStatus: "pending",
+	}
+
+	order := &model.Order{
+		ID:        "3d4e5f6a-7b8c-4d9e-9f0a-1b2c3d4e5f6a",
+		PaymentId: "4e5f6a7b-8c9d-4e0f-8a1b-2c3d4e5f6a7b",
+		Status:    model.OrderStatusReturned,
+	}
+
+	pkg.OrderRepository.Mock.On("FindById", paymentId).Return((*model.Order)(nil), pkg.ErrRecordNotFound)
+	pkg.PaymentRepository.Mock.On("FindByIdForUpdate", paymentId).Return(payment, nil)
+	pkg.OrderRepository.Mock.On("FindByIdForUpdate", order.ID).Return(order, nil)
+
+	resultOrder, resultPayment, err := findGatewayTransaction(pkg.UnitOfWork.Repositories, paymentId)
+
+	assert.Nil(t, err)
+	assert.Equal(t, order.ID, resultOrder.ID)
+	assert.Equal(t, model.PaymentKindLateFee, resultPayment.Kind)
+}
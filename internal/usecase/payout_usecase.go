@@ -0,0 +1,526 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/google/uuid"
+)
+
+type PayoutUsecase interface {
+	CreatePayoutBatch() (*model.PayoutBatch, error)
+	FindAllPayoutBatches() (*[]model.PayoutBatch, error)
+	FindPayoutBatchById(batchId string) (*model.PayoutBatch, error)
+	ExportPayoutBatch(batchId string) ([]byte, error)
+	UpdatePayoutStatus(batchId string, payoutId string, payoutStatusDTO dto.PayoutStatusDTO) (*model.Payout, error)
+	FindRenterEarnings(actorId string, renterId string) (map[string]interface{}, error)
+	FindAllRenterPayouts(actorId string, renterId string) (*[]model.Payout, error)
+}
+
+type payoutUsecase struct {
+	unitOfWork       repository.UnitOfWork
+	renterRepository repository.RenterRepository
+	ledgerRepository repository.LedgerRepository
+	payoutRepository repository.PayoutRepository
+}
+
+// CreatePayoutBatch moves the balance of every renter with a bank account into a payout. The balances are
+// locked, so a balance is never paid out twice by batches created at the same time.
+func (u payoutUsecase) CreatePayoutBatch() (*model.PayoutBatch, error) {
+	batch := model.PayoutBatch{
+		ID:        uuid.NewString(),
+		Status:    model.PayoutBatchStatusPending,
+		Payouts:   []model.Payout{},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	err := u.unitOfWork.WithTx(func(repos repository.Repositories) error {
+		balances, err := repos.Ledger.FindRenterBalancesForUpdate()
+
+		if err != nil {
+			return err
+		}
+
+		entries := []model.LedgerEntry{}
+
+		for _, balance := range *balances {
+			// only whole rupiah can be transferred
+			amount := float32(math.Floor(float64(balance.Balance)))
+
+			if amount <= 0 {
+				continue
+			}
+
+			renter, err := repos.Renter.FindById(balance.RenterId)
+
+			if err != nil {
+				return err
+			}
+
+			// the balance stays with a renter that has no bank account yet, until the next batch
+			if renter.BankName == "" || renter.BankAccountNumber == "" || renter.BankAccountName == "" {
+				continue
+			}
+
+			payout := model.Payout{
+				ID:                uuid.NewString(),
+				BatchId:           batch.ID,
+				RenterId:          renter.ID,
+				Amount:            amount,
+				BankName:          renter.BankName,
+				BankAccountNumber: renter.BankAccountNumber,
+				BankAccountName:   renter.BankAccountName,
+				Status:            model.PayoutStatusPending,
+				CreatedAt:         time.Now(),
+				UpdatedAt:         time.Now(),
+			}
+
+			j := newJournal(fmt.Sprintf("payout %s to %s", payout.ID, renter.RentName))
+			j.payoutId = payout.ID
+			j.debit(model.LedgerAccountRenterPayable, renter.ID, amount)
+			j.credit(model.LedgerAccountPayoutClearing, renter.ID, amount)
+
+			entries = append(entries, j.entries...)
+
+			batch.Payouts = append(batch.Payouts, payout)
+			batch.TotalAmount += amount
+			batch.TotalPayout++
+		}
+
+		if len(batch.Payouts) == 0 {
+			return pkg.ErrNoPayableBalance
+		}
+
+		if err := repos.Payout.CreateBatch(batch); err != nil {
+			return err
+		}
+
+		if err := repos.Payout.Create(batch.Payouts); err != nil {
+			return err
+		}
+
+		return repos.Ledger.Create(entries)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &batch, nil
+}
+
+func (u payoutUsecase) FindAllPayoutBatches() (*[]model.PayoutBatch, error) {
+	batches, err := u.payoutRepository.FindAllBatches()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return batches, nil
+}
+
+func (u payoutUsecase) FindPayoutBatchById(batchId string) (*model.PayoutBatch, error) {
+	batch, err := u.payoutRepository.FindBatchById(batchId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return batch, nil
+}
+
+// ExportPayoutBatch writes the pending payouts of a batch as a csv file to upload to the bank as bulk transfers.
+func (u payoutUsecase) ExportPayoutBatch(batchId string) ([]byte, error) {
+	batch, err := u.payoutRepository.FindBatchById(batchId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows := [][]string{
+		{"payout_id", "bank_name", "bank_account_number", "bank_account_name", "amount", "description"},
+	}
+
+	for _, payout := range batch.Payouts {
+		if payout.Status != model.PayoutStatusPending {
+			continue
+		}
+
+		rows = append(rows, []string{
+			payout.ID,
+			payout.BankName,
+			payout.BankAccountNumber,
+			payout.BankAccountName,
+			fmt.Sprintf("%.0f", payout.Amount),
+			fmt.Sprintf("go-rent-bike payout %s", payout.ID),
+		})
+	}
+
+	var buf bytes.Buffer
+
+	writer := csv.NewWriter(&buf)
+
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UpdatePayoutStatus records how the bank transfer of a payout ended. A paid payout leaves the platform,
+// a failed one is given back to the balance of the renter.
+func (u payoutUsecase) UpdatePayoutStatus(batchId string, payoutId string, payoutStatusDTO dto.PayoutStatusDTO) (*model.Payout, error) {
+	if payoutStatusDTO.Status != model.PayoutStatusPaid && payoutStatusDTO.Status != model.PayoutStatusFailed {
+		return nil, fmt.Errorf("%w: status must be paid or failed", pkg.ErrInvalidPayout)
+	}
+
+	if len(payoutStatusDTO.Reference) > 255 || len(payoutStatusDTO.FailureReason) > 255 {
+		return nil, fmt.Errorf("%w: reference and failure reason must be at most 255 characters", pkg.ErrInvalidPayout)
+	}
+
+	var payout *model.Payout
+
+	err := u.unitOfWork.WithTx(func(repos repository.Repositories) error {
+		var err error
+
+		payout, err = repos.Payout.FindByIdForUpdate(payoutId)
+
+		if err != nil {
+			return err
+		}
+
+		if payout.BatchId != batchId {
+			return pkg.ErrRecordNotFound
+		}
+
+		if payout.Status != model.PayoutStatusPending {
+			return fmt.Errorf("%w: payout is %s", pkg.ErrPayoutNotPending, payout.Status)
+		}
+
+		payout.Status = payoutStatusDTO.Status
+		payout.Reference = payoutStatusDTO.Reference
+		payout.FailureReason = payoutStatusDTO.FailureReason
+		payout.UpdatedAt = time.Now()
+
+		j := newJournal(fmt.Sprintf("payout %s %s", payout.ID, payout.Status))
+		j.payoutId = payout.ID
+		j.debit(model.LedgerAccountPayoutClearing, payout.RenterId, payout.Amount)
+
+		if payout.Status == model.PayoutStatusPaid {
+			j.credit(model.LedgerAccountGatewayClearing, "", payout.Amount)
+		} else {
+			j.credit(model.LedgerAccountRenterPayable, payout.RenterId, payout.Amount)
+		}
+
+		if err := j.post(repos.Ledger); err != nil {
+			return err
+		}
+
+		if err := repos.Payout.Update(payout.ID, *payout); err != nil {
+			return err
+		}
+
+		pending, err := repos.Payout.CountPending(batchId)
+
+		if err != nil {
+			return err
+		}
+
+		if pending > 0 {
+			return nil
+		}
+
+		return repos.Payout.UpdateBatch(batchId, model.PayoutBatch{
+			Status:    model.PayoutBatchStatusCompleted,
+			UpdatedAt: time.Now(),
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return payout, nil
+}
+
+// FindRenterEarnings shows a renter its balance and every entry that moved it.
+func (u payoutUsecase) FindRenterEarnings(actorId string, renterId string) (map[string]interface{}, error) {
+	if err := u.authorizeRenter(actorId, renterId); err != nil {
+		return nil, err
+	}
+
+	balance, err := u.ledgerRepository.FindRenterBalance(renterId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := u.ledgerRepository.FindByIdRenter(renterId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"renter_id": renterId,
+		"balance":   balance.Balance,
+		"entries":   entries,
+	}, nil
+}
+
+func (u payoutUsecase) FindAllRenterPayouts(actorId string, renterId string) (*[]model.Payout, error) {
+	if err := u.authorizeRenter(actorId, renterId); err != nil {
+		return nil, err
+	}
+
+	payouts, err := u.payoutRepository.FindByIdRenter(renterId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return payouts, nil
+}
+
+// authorizeRenter only lets a renter see its own earnings.
+func (u payoutUsecase) authorizeRenter(actorId string, renterId string) error {
+	renter, err := findActorRenter(u.renterRepository, actorId)
+
+	if err != nil {
+		return err
+	}
+
+	if renter.ID != renterId {
+		return pkg.ErrForbidden
+	}
+
+	return nil
+}
+
+// renterShare is the part of a payment earned by one renter, and the commission the platform keeps of it.
+type renterShare struct {
+	RenterId   string
+	Amount     float32
+	Commission float32
+}
+
+// splitPayment shares amount between the renters in proportion to the subtotals of their bikes. Every share
+// is rounded to whole rupiah, the last renter takes what is left so the shares always add up to amount.
+func splitPayment(amount float32, subtotals map[string]float32, commissionPercent float32) []renterShare {
+	renterIds := make([]string, 0, len(subtotals))
+
+	var total float64
+
+	for renterId, subtotal := range subtotals {
+		renterIds = append(renterIds, renterId)
+		total += float64(subtotal)
+	}
+
+	sort.Strings(renterIds)
+
+	shares := make([]renterShare, 0, len(renterIds))
+	left := float64(amount)
+
+	for i, renterId := range renterIds {
+		share := left
+
+		if i < len(renterIds)-1 {
+			if total > 0 {
+				share = math.Round(float64(amount) * float64(subtotals[renterId]) / total)
+			} else {
+				share = math.Round(float64(amount) / float64(len(renterIds)))
+			}
+		}
+
+		left -= share
+
+		shares = append(shares, renterShare{
+			RenterId:   renterId,
+			Amount:     float32(share),
+			Commission: float32(math.Round(share * float64(commissionPercent) / 100)),
+		})
+	}
+
+	return shares
+}
+
+// postSettlement books a settled payment: the money collected by the payment gateway, or taken from the wallet
+// of the customer, is owed to the renters of the order, minus the commission of the platform.
+// The security deposit in the payment is held apart until the order settles it.
+func postSettlement(repos repository.Repositories, order model.Order, payment model.Payment, commissionPercent float32) error {
+	details, err := repos.OrderDetail.FindByIdOrder(order.ID)
+
+	if err != nil {
+		return err
+	}
+
+	subtotals := map[string]float32{}
+
+	for _, detail := range *details {
+		if detail.Bike != nil {
+			subtotals[detail.Bike.RenterId] += detail.Subtotal
+		}
+	}
+
+	j := newJournal(fmt.Sprintf("%s payment of order %s", payment.Kind, order.ID))
+	j.paymentId = payment.ID
+	j.debit(paymentAccount(payment), "", payment.Amount)
+	j.credit(model.LedgerAccountDepositHeld, "", payment.Deposit)
+
+	rent := payment.Amount - payment.Deposit
+
+	// the bikes of the order were deleted since, nobody is left to pay so the platform keeps it
+	if len(subtotals) == 0 {
+		j.credit(model.LedgerAccountPlatformCommission, "", rent)
+
+		return j.post(repos.Ledger)
+	}
+
+	for _, share := range splitPayment(rent, subtotals, commissionPercent) {
+		j.credit(model.LedgerAccountRenterPayable, share.RenterId, share.Amount-share.Commission)
+		j.credit(model.LedgerAccountPlatformCommission, share.RenterId, share.Commission)
+	}
+
+	return j.post(repos.Ledger)
+}
+
+// postRefund takes a refund back from the accounts credited by the settlement of the payment,
+// in proportion to what each of them was credited. A released deposit is taken back from the deposit held.
+func postRefund(repos repository.Repositories, payment model.Payment, refund model.Refund) error {
+	if refund.Kind == model.RefundKindDeposit {
+		j := newJournal(fmt.Sprintf("deposit refund %s of payment %s", refund.ID, payment.ID))
+		j.paymentId = payment.ID
+		j.refundId = refund.ID
+		j.debit(model.LedgerAccountDepositHeld, "", refund.Amount)
+		j.credit(paymentAccount(payment), "", refund.Amount)
+
+		return j.post(repos.Ledger)
+	}
+
+	entries, err := repos.Ledger.FindByIdPayment(payment.ID)
+
+	if err != nil {
+		return err
+	}
+
+	credited := []model.LedgerEntry{}
+
+	var total float64
+
+	for _, entry := range *entries {
+		if entry.RefundId == "" && entry.Credit > 0 && entry.Account != model.LedgerAccountDepositHeld {
+			credited = append(credited, entry)
+			total += float64(entry.Credit)
+		}
+	}
+
+	// a payment settled before the ledger existed has nothing to take back
+	if total == 0 {
+		return nil
+	}
+
+	j := newJournal(fmt.Sprintf("refund %s of payment %s", refund.ID, payment.ID))
+	j.paymentId = payment.ID
+	j.refundId = refund.ID
+	j.credit(paymentAccount(payment), "", refund.Amount)
+
+	left := float64(refund.Amount)
+
+	for i, entry := range credited {
+		amount := left
+
+		if i < len(credited)-1 {
+			amount = math.Round(float64(refund.Amount) * float64(entry.Credit) / total)
+		}
+
+		left -= amount
+
+		j.debit(entry.Account, entry.RenterId, float32(amount))
+	}
+
+	return j.post(repos.Ledger)
+}
+
+// journal collects the entries posted together, its debits must equal its credits.
+type journal struct {
+	id          string
+	description string
+	paymentId   string
+	refundId    string
+	payoutId    string
+	entries     []model.LedgerEntry
+}
+
+func newJournal(description string) *journal {
+	return &journal{
+		id:          uuid.NewString(),
+		description: description,
+	}
+}
+
+func (j *journal) debit(account string, renterId string, amount float32) {
+	j.add(account, renterId, amount, 0)
+}
+
+func (j *journal) credit(account string, renterId string, amount float32) {
+	j.add(account, renterId, 0, amount)
+}
+
+func (j *journal) add(account string, renterId string, debit float32, credit float32) {
+	// an entry of nothing, e.g. the commission of a platform that takes none, is left out
+	if debit == 0 && credit == 0 {
+		return
+	}
+
+	j.entries = append(j.entries, model.LedgerEntry{
+		ID:          uuid.NewString(),
+		JournalId:   j.id,
+		Account:     account,
+		RenterId:    renterId,
+		PaymentId:   j.paymentId,
+		RefundId:    j.refundId,
+		PayoutId:    j.payoutId,
+		Description: j.description,
+		Debit:       debit,
+		Credit:      credit,
+		CreatedAt:   time.Now(),
+	})
+}
+
+func (j *journal) post(ledgerRepository repository.LedgerRepository) error {
+	var debits, credits float64
+
+	for _, entry := range j.entries {
+		debits += float64(entry.Debit)
+		credits += float64(entry.Credit)
+	}
+
+	if math.Abs(debits-credits) >= 0.01 {
+		return fmt.Errorf("journal %s is not balanced: debits %.2f, credits %.2f", j.description, debits, credits)
+	}
+
+	return ledgerRepository.Create(j.entries)
+}
+
+func NewPayoutUsecase(
+	unitOfWork repository.UnitOfWork,
+	renterRepo repository.RenterRepository,
+	ledgerRepo repository.LedgerRepository,
+	payoutRepo repository.PayoutRepository,
+) PayoutUsecase {
+	return payoutUsecase{
+		unitOfWork:       unitOfWork,
+		renterRepository: renterRepo,
+		ledgerRepository: ledgerRepo,
+		payoutRepository: payoutRepo,
+	}
+}
@@ -0,0 +1,296 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var payoutUsecaseTest = NewPayoutUsecase(
+	&pkg.UnitOfWork,
+	&pkg.RenterRepository,
+	&pkg.LedgerRepository,
+	&pkg.PayoutRepository,
+)
+
+func TestSplitPayment(t *testing.T) {
+	testCases := []struct {
+		Name              string
+		Amount            float32
+		Subtotals         map[string]float32
+		CommissionPercent float32
+		Expected          []renterShare
+	}{
+		{
+			Name:              "one renter",
+			Amount:            75000,
+			Subtotals:         map[string]float32{"RID-1": 75000},
+			CommissionPercent: 10,
+			Expected:          []renterShare{{RenterId: "RID-1", Amount: 75000, Commission: 7500}},
+		},
+		{
+			// the voucher discount is shared by the renters in proportion to their bikes
+			Name:              "discounted order of two renters",
+			Amount:            60000,
+			Subtotals:         map[string]float32{"RID-2": 25000, "RID-1": 50000},
+			CommissionPercent: 10,
+			Expected: []renterShare{
+				{RenterId: "RID-1", Amount: 40000, Commission: 4000},
+				{RenterId: "RID-2", Amount: 20000, Commission: 2000},
+			},
+		},
+		{
+			Name:              "last renter takes the rounding",
+			Amount:            10000,
+			Subtotals:         map[string]float32{"RID-1": 1, "RID-2": 1, "RID-3": 1},
+			CommissionPercent: 0,
+			Expected: []renterShare{
+				{RenterId: "RID-1", Amount: 3333},
+				{RenterId: "RID-2", Amount: 3333},
+				{RenterId: "RID-3", Amount: 3334},
+			},
+		},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.Name, func(t *testing.T) {
+			assert.Equal(t, v.Expected, splitPayment(v.Amount, v.Subtotals, v.CommissionPercent))
+		})
+	}
+}
+
+func TestPayoutUsecase_CreatePayoutBatch(t *testing.T) {
+	withBank := &model.Renter{
+		ID:                "5a6b7c8d-9e0f-4a1b-8c2d-3e4f5a6b7c8d",
+		RentName:          "Twins' Brother Bike Rental",
+		BankName:          "BCA",
+		BankAccountNumber: "1234567890",
+		BankAccountName:   "Josuke Higashikata",
+	}
+
+	withoutBank := &model.Renter{
+		ID:       "6b7c8d9e-0f1a-4b2c-9d3e-4f5a6b7c8d9e",
+		RentName: "Kira Bikes",
+	}
+
+	pkg.LedgerRepository.Mock.On("FindRenterBalancesForUpdate").Return(&[]model.RenterBalance{
+		{RenterId: withBank.ID, Balance: 67500.4},
+		{RenterId: withoutBank.ID, Balance: 27000},
+		// paid out already, but a refund came later
+		{RenterId: "7c8d9e0f-1a2b-4c3d-8e4f-5a6b7c8d9e0f", Balance: -2500},
+	}, nil).Once()
+
+	pkg.RenterRepository.Mock.On("FindById", withBank.ID).Return(withBank, nil)
+	pkg.RenterRepository.Mock.On("FindById", withoutBank.ID).Return(withoutBank, nil)
+
+	pkg.PayoutRepository.Mock.On("CreateBatch", mock.MatchedBy(func(batch model.PayoutBatch) bool {
+		return batch.TotalAmount == 67500 && batch.TotalPayout == 1
+	})).Return(nil)
+	pkg.PayoutRepository.Mock.On("Create", mock.MatchedBy(func(payouts []model.Payout) bool {
+		return len(payouts) == 1 && payouts[0].RenterId == withBank.ID && payouts[0].BankAccountNumber == "1234567890"
+	})).Return(nil)
+
+	var entries []model.LedgerEntry
+
+	pkg.LedgerRepository.Mock.On("Create", mock.MatchedBy(func(ledgerEntries []model.LedgerEntry) bool {
+		return len(ledgerEntries) > 0 && ledgerEntries[0].RenterId == withBank.ID && ledgerEntries[0].PayoutId != ""
+	})).Run(func(args mock.Arguments) {
+		entries = args.Get(0).([]model.LedgerEntry)
+	}).Return(nil)
+
+	batch, err := payoutUsecaseTest.CreatePayoutBatch()
+
+	assert.Nil(t, err)
+	assert.NotNil(t, batch)
+
+	assert.Equal(t, model.PayoutBatchStatusPending, batch.Status)
+	assert.Len(t, batch.Payouts, 1)
+	assert.Equal(t, float32(67500), batch.Payouts[0].Amount)
+	assert.Equal(t, model.PayoutStatusPending, batch.Payouts[0].Status)
+
+	// the balance of the renter moves to the payout clearing account until the transfer is done
+	assert.Len(t, entries, 2)
+	assert.Equal(t, model.LedgerAccountRenterPayable, entries[0].Account)
+	assert.Equal(t, float32(67500), entries[0].Debit)
+	assert.Equal(t, model.LedgerAccountPayoutClearing, entries[1].Account)
+	assert.Equal(t, float32(67500), entries[1].Credit)
+}
+
+func TestPayoutUsecase_CreatePayoutBatchNothingToPay(t *testing.T) {
+	pkg.LedgerRepository.Mock.On("FindRenterBalancesForUpdate").Return(&[]model.RenterBalance{
+		{RenterId: "8d9e0f1a-2b3c-4d4e-9f5a-6b7c8d9e0f1a", Balance: 0.5},
+	}, nil).Once()
+
+	batch, err := payoutUsecaseTest.CreatePayoutBatch()
+
+	assert.Nil(t, batch)
+	assert.Equal(t, pkg.ErrNoPayableBalance, err)
+}
+
+func TestPayoutUsecase_UpdatePayoutStatus(t *testing.T) {
+	batchId := "9e0f1a2b-3c4d-4e5f-8a6b-7c8d9e0f1a2b"
+	renterId := "0f1a2b3c-4d5e-4f6a-9b7c-8d9e0f1a2b3c"
+
+	testCases := []struct {
+		Name            string
+		PayoutId        string
+		Status          string
+		Pending         int64
+		ExpectedAccount string
+		BatchCompleted  bool
+	}{
+		{
+			Name:            "paid",
+			PayoutId:        "1a2b3c4d-5e6f-4a7b-8c8d-9e0f1a2b3c4d",
+			Status:          model.PayoutStatusPaid,
+			Pending:         1,
+			ExpectedAccount: model.LedgerAccountGatewayClearing,
+		},
+		{
+			// the money of a failed transfer is owed to the renter again, and the batch has no pending payout left
+			Name:            "failed",
+			PayoutId:        "2b3c4d5e-6f7a-4b8c-9d9e-0f1a2b3c4d5e",
+			Status:          model.PayoutStatusFailed,
+			Pending:         0,
+			ExpectedAccount: model.LedgerAccountRenterPayable,
+			BatchCompleted:  true,
+		},
+	}
+
+	for _, v := range testCases {
+		payoutId := v.PayoutId
+
+		t.Run(v.Name, func(t *testing.T) {
+			pkg.PayoutRepository.Mock.On("FindByIdForUpdate", v.PayoutId).Return(&model.Payout{
+				ID:       v.PayoutId,
+				BatchId:  batchId,
+				RenterId: renterId,
+				Amount:   40000,
+				Status:   model.PayoutStatusPending,
+			}, nil)
+
+			var entries []model.LedgerEntry
+
+			pkg.LedgerRepository.Mock.On("Create", mock.MatchedBy(func(ledgerEntries []model.LedgerEntry) bool {
+				return len(ledgerEntries) > 0 && ledgerEntries[0].PayoutId == payoutId
+			})).Run(func(args mock.Arguments) {
+				entries = args.Get(0).([]model.LedgerEntry)
+			}).Return(nil)
+
+			pkg.PayoutRepository.Mock.On("Update", v.PayoutId, mock.Anything).Return(nil)
+			pkg.PayoutRepository.Mock.On("CountPending", batchId).Return(v.Pending, nil).Once()
+			pkg.PayoutRepository.Mock.On("UpdateBatch", batchId, mock.MatchedBy(func(batch model.PayoutBatch) bool {
+				return batch.Status == model.PayoutBatchStatusCompleted
+			})).Return(nil).Once()
+
+			payout, err := payoutUsecaseTest.UpdatePayoutStatus(batchId, v.PayoutId, dto.PayoutStatusDTO{Status: v.Status, Reference: "TRF-1"})
+
+			assert.Nil(t, err)
+			assert.Equal(t, v.Status, payout.Status)
+			assert.Equal(t, "TRF-1", payout.Reference)
+
+			assert.Len(t, entries, 2)
+			assert.Equal(t, model.LedgerAccountPayoutClearing, entries[0].Account)
+			assert.Equal(t, float32(40000), entries[0].Debit)
+			assert.Equal(t, v.ExpectedAccount, entries[1].Account)
+			assert.Equal(t, float32(40000), entries[1].Credit)
+
+			if v.BatchCompleted {
+				pkg.PayoutRepository.Mock.AssertCalled(t, "UpdateBatch", batchId, mock.Anything)
+			} else {
+				pkg.PayoutRepository.Mock.AssertNotCalled(t, "UpdateBatch", batchId, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestPayoutUsecase_UpdatePayoutStatusRejected(t *testing.T) {
+	batchId := "3c4d5e6f-7a8b-4c9d-8e0f-1a2b3c4d5e6f"
+	paidPayoutId := "4d5e6f7a-8b9c-4d0e-9f1a-2b3c4d5e6f7a"
+	otherBatchPayoutId := "5e6f7a8b-9c0d-4e1f-8a2b-3c4d5e6f7a8b"
+
+	pkg.PayoutRepository.Mock.On("FindByIdForUpdate", paidPayoutId).Return(&model.Payout{ID: paidPayoutId, BatchId: batchId, Status: model.PayoutStatusPaid}, nil)
+	pkg.PayoutRepository.Mock.On("FindByIdForUpdate", otherBatchPayoutId).Return(&model.Payout{ID: otherBatchPayoutId, BatchId: "6f7a8b9c-0d1e-4f2a-9b3c-4d5e6f7a8b9c", Status: model.PayoutStatusPending}, nil)
+
+	testCases := []struct {
+		Name     string
+		PayoutId string
+		Status   string
+		Err      error
+	}{
+		{
+			Name:     "unknown status",
+			PayoutId: paidPayoutId,
+			Status:   "pending",
+			Err:      pkg.ErrInvalidPayout,
+		},
+		{
+			Name:     "payout already paid",
+			PayoutId: paidPayoutId,
+			Status:   model.PayoutStatusFailed,
+			Err:      pkg.ErrPayoutNotPending,
+		},
+		{
+			Name:     "payout of another batch",
+			PayoutId: otherBatchPayoutId,
+			Status:   model.PayoutStatusPaid,
+			Err:      pkg.ErrRecordNotFound,
+		},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.Name, func(t *testing.T) {
+			payout, err := payoutUsecaseTest.UpdatePayoutStatus(batchId, v.PayoutId, dto.PayoutStatusDTO{Status: v.Status})
+
+			assert.Nil(t, payout)
+			assert.True(t, errors.Is(err, v.Err))
+		})
+	}
+}
+
+func TestPayoutUsecase_ExportPayoutBatch(t *testing.T) {
+	batchId := "7a8b9c0d-1e2f-4a3b-8c4d-5e6f7a8b9c0d"
+
+	pkg.PayoutRepository.Mock.On("FindBatchById", batchId).Return(&model.PayoutBatch{
+		ID:     batchId,
+		Status: model.PayoutBatchStatusPending,
+		Payouts: []model.Payout{
+			{ID: "PO-1", BankName: "BCA", BankAccountNumber: "1234567890", BankAccountName: "Josuke Higashikata", Amount: 67500, Status: model.PayoutStatusPending},
+			{ID: "PO-2", BankName: "BNI", BankAccountNumber: "0987654321", BankAccountName: "Okuyasu Nijimura", Amount: 27000, Status: model.PayoutStatusPaid},
+		},
+	}, nil)
+
+	file, err := payoutUsecaseTest.ExportPayoutBatch(batchId)
+
+	assert.Nil(t, err)
+	// only the transfers still to be made are exported
+	assert.Equal(t, "payout_id,bank_name,bank_account_number,bank_account_name,amount,description\n"+
+		"PO-1,BCA,1234567890,Josuke Higashikata,67500,go-rent-bike payout PO-1\n", string(file))
+}
+
+func TestPayoutUsecase_FindRenterEarnings(t *testing.T) {
+	renterUserId := "8b9c0d1e-2f3a-4b4c-9d5e-6f7a8b9c0d1e"
+	renterId := "9c0d1e2f-3a4b-4c5d-8e6f-7a8b9c0d1e2f"
+
+	pkg.RenterRepository.Mock.On("FindByIdUser", renterUserId).Return(&model.Renter{ID: renterId, UserId: renterUserId}, nil)
+	pkg.LedgerRepository.Mock.On("FindRenterBalance", renterId).Return(&model.RenterBalance{RenterId: renterId, Balance: 40500}, nil)
+	pkg.LedgerRepository.Mock.On("FindByIdRenter", renterId).Return(&[]model.LedgerEntry{
+		{Account: model.LedgerAccountRenterPayable, RenterId: renterId, Credit: 40500},
+	}, nil)
+
+	earnings, err := payoutUsecaseTest.FindRenterEarnings(renterUserId, renterId)
+
+	assert.Nil(t, err)
+	assert.Equal(t, float32(40500), earnings["balance"])
+
+	// a renter cannot see the earnings of another renter
+	earnings, err = payoutUsecaseTest.FindRenterEarnings(renterUserId, "0d1e2f3a-4b5c-4d6e-9f7a-8b9c0d1e2f3a")
+
+	assert.Nil(t, earnings)
+	assert.Equal(t, pkg.ErrForbidden, err)
+}
@@ -0,0 +1,333 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/payment"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/google/uuid"
+)
+
+type RefundUsecase interface {
+	CreateRefund(actorId string, actorRole string, paymentId string, refundDTO dto.RefundDTO) (*model.Refund, error)
+	FindAllRefunds(actorId string, actorRole string, paymentId string) (*[]model.Refund, error)
+}
+
+type refundUsecase struct {
+	unitOfWork            repository.UnitOfWork
+	paymentProvider       payment.PaymentProvider
+	renterRepository      repository.RenterRepository
+	paymentRepository     repository.PaymentRepository
+	orderDetailRepository repository.OrderDetailRepository
+	refundRepository      repository.RefundRepository
+}
+
+func (u refundUsecase) CreateRefund(actorId string, actorRole string, paymentId string, refundDTO dto.RefundDTO) (*model.Refund, error) {
+	if refundDTO.Amount < 0 || len(refundDTO.Reason) > 255 {
+		return nil, fmt.Errorf("%w: amount must not be negative and reason at most 255 characters", pkg.ErrInvalidRefund)
+	}
+
+	renter, err := findRefundingRenter(u.renterRepository, actorId, actorRole)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var refund *model.Refund
+
+	// the payment is locked, so two refunds at the same time cannot both take the refundable amount
+	err = u.unitOfWork.WithTx(func(repos repository.Repositories) error {
+		payment, err := repos.Payment.FindByIdForUpdate(paymentId)
+
+		if err != nil {
+			return err
+		}
+
+		if renter != nil {
+			if err := authorizeRefund(repos.OrderDetail, renter.ID, payment.OrderId); err != nil {
+				return err
+			}
+		}
+
+		refund, err = requestRefund(repos, payment, refundDTO.Amount, refundDTO.Reason, actorId)
+
+		if err != nil {
+			return err
+		}
+
+		if err := repos.Payment.Update(payment.ID, *payment); err != nil {
+			return err
+		}
+
+		// the payment gateway is called last, so a failure there rolls the refund back
+		return sendRefund(u.paymentProvider, *payment, *refund)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return refund, nil
+}
+
+func (u refundUsecase) FindAllRefunds(actorId string, actorRole string, paymentId string) (*[]model.Refund, error) {
+	renter, err := findRefundingRenter(u.renterRepository, actorId, actorRole)
+
+	if err != nil {
+		return nil, err
+	}
+
+	payment, err := u.paymentRepository.FindById(paymentId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if renter != nil {
+		if err := authorizeRefund(u.orderDetailRepository, renter.ID, payment.OrderId); err != nil {
+			return nil, err
+		}
+	}
+
+	return u.refundRepository.FindByIdPayment(payment.ID)
+}
+
+// findRefundingRenter finds the renter refunding a payment. Admins refund any payment on behalf of the platform,
+// they have no renter and skip the ownership check.
+func findRefundingRenter(renterRepository repository.RenterRepository, actorId string, actorRole string) (*model.Renter, error) {
+	if actorRole == model.RoleAdmin {
+		return nil, nil
+	}
+
+	return findActorRenter(renterRepository, actorId)
+}
+
+// findActorRenter finds the renter of the user, a user without a renter profile is not allowed to refund.
+func findActorRenter(renterRepository repository.RenterRepository, actorId string) (*model.Renter, error) {
+	renter, err := renterRepository.FindByIdUser(actorId)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return nil, pkg.ErrForbidden
+		}
+
+		return nil, err
+	}
+
+	return renter, nil
+}
+
+// authorizeRefund only lets a renter refund orders that rent at least one of its bikes.
+func authorizeRefund(orderDetailRepository repository.OrderDetailRepository, renterId string, orderId string) error {
+	details, err := orderDetailRepository.FindByIdOrder(orderId)
+
+	if err != nil {
+		return err
+	}
+
+	for _, detail := range *details {
+		if detail.Bike != nil && detail.Bike.RenterId == renterId {
+			return nil
+		}
+	}
+
+	return pkg.ErrForbidden
+}
+
+// refundableAmount is what was captured on the payment minus the refunds already made or requested.
+// A deposit is never refunded as part of the payment, it is released on its own.
+func refundableAmount(repos repository.Repositories, payment model.Payment) (float32, error) {
+	refunds, err := repos.Refund.FindByIdPayment(payment.ID)
+
+	if err != nil {
+		return 0, err
+	}
+
+	refundable := payment.Amount - payment.Deposit
+
+	for _, refund := range *refunds {
+		if refund.Kind != model.RefundKindDeposit {
+			refundable -= refund.Amount
+		}
+	}
+
+	if refundable < 0 {
+		refundable = 0
+	}
+
+	return refundable, nil
+}
+
+// requestRefund records a refund of part of a locked payment, an amount of zero refunds everything left.
+// The payment status is changed, but not saved, and the refund still has to be sent with sendRefund.
+func requestRefund(repos repository.Repositories, payment *model.Payment, amount float32, reason string, actorId string) (*model.Refund, error) {
+	// a top up was credited to the wallet, refunding it would pay the customer twice
+	if payment.Kind == model.PaymentKindTopUp {
+		return nil, fmt.Errorf("%w: a wallet top up is not refunded", pkg.ErrPaymentNotRefundable)
+	}
+
+	var refundable float32
+
+	switch payment.PaymentStatus {
+	case model.PaymentStatusSettlement, model.PaymentStatusPartialRefund:
+		var err error
+
+		refundable, err = refundableAmount(repos, *payment)
+
+		if err != nil {
+			return nil, err
+		}
+	case model.PaymentStatusLateSettlement:
+		// nothing of a payment settled too late was used, not even its deposit, so it is refunded in full at once
+		refundable = payment.Amount
+
+		if amount != 0 && amount != refundable {
+			return nil, fmt.Errorf("%w: a late settlement is refunded in full, %.0f", pkg.ErrInvalidRefund, refundable)
+		}
+	default:
+		return nil, pkg.ErrPaymentNotRefundable
+	}
+
+	if amount == 0 {
+		amount = refundable
+	}
+
+	if amount <= 0 || amount > refundable {
+		return nil, fmt.Errorf("%w: %.0f left to refund", pkg.ErrRefundExceedsCaptured, refundable)
+	}
+
+	refund := model.Refund{
+		ID:          uuid.NewString(),
+		PaymentId:   payment.ID,
+		Kind:        model.RefundKindPayment,
+		Amount:      amount,
+		Reason:      reason,
+		Status:      model.RefundStatusPending,
+		RequestedBy: actorId,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	if err := recordRefund(repos, payment, &refund); err != nil {
+		return nil, err
+	}
+
+	return &refund, nil
+}
+
+// recordRefund records a refund of a locked payment, a refund of a wallet payment is credited to the wallet right away.
+// The payment is fully refunded once its refunds add up to its amount.
+func recordRefund(repos repository.Repositories, payment *model.Payment, refund *model.Refund) error {
+	refunds, err := repos.Refund.FindByIdPayment(payment.ID)
+
+	if err != nil {
+		return err
+	}
+
+	refunded := refund.Amount
+
+	for _, previous := range *refunds {
+		refunded += previous.Amount
+	}
+
+	if err := repos.Refund.Create(*refund); err != nil {
+		return err
+	}
+
+	payment.PaymentStatus = model.PaymentStatusPartialRefund
+	if refunded >= payment.Amount {
+		payment.PaymentStatus = model.PaymentStatusRefund
+	}
+
+	payment.UpdatedAt = time.Now()
+
+	// a wallet payment never went through the payment gateway, it is refunded to the wallet right away
+	if payment.PaymentType == model.PaymentTypeWallet {
+		return refundToWallet(repos, *payment, refund)
+	}
+
+	return nil
+}
+
+// sendRefund asks the payment gateway for a recorded refund, a refund of a wallet payment was credited already.
+// The refund id is the refund key, so sending the same refund again never refunds it twice.
+func sendRefund(paymentProvider payment.PaymentProvider, payment model.Payment, refund model.Refund) error {
+	if payment.PaymentType == model.PaymentTypeWallet {
+		return nil
+	}
+
+	if err := paymentProvider.RefundTransaction(gatewayOrderId(payment), refund.ID, int64(refund.Amount), refund.Reason); err != nil {
+		return paymentGatewayError(err)
+	}
+
+	return nil
+}
+
+// refundToWallet credits a refund of a wallet payment back to the wallet of the customer of the order.
+func refundToWallet(repos repository.Repositories, payment model.Payment, refund *model.Refund) error {
+	order, err := repos.Order.FindById(payment.OrderId)
+
+	if err != nil {
+		return err
+	}
+
+	wallet, err := lockWallet(repos, order.UserId)
+
+	if err != nil {
+		return err
+	}
+
+	err = applyWalletTransaction(repos, wallet, model.WalletTransaction{
+		Type:        model.WalletTransactionRefund,
+		Amount:      refund.Amount,
+		PaymentId:   payment.ID,
+		RefundId:    refund.ID,
+		Description: fmt.Sprintf("%s refund of order %s", refund.Kind, order.ID),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	refund.Status = model.RefundStatusSucceeded
+	refund.UpdatedAt = time.Now()
+
+	if err := repos.Refund.Update(refund.ID, model.Refund{Status: refund.Status, UpdatedAt: refund.UpdatedAt}); err != nil {
+		return err
+	}
+
+	return postRefund(repos, payment, *refund)
+}
+
+// gatewayOrderId is the order id of the payment in the payment gateway, a rent payment is sent
+// with the id of its order and the other payments with their own id.
+func gatewayOrderId(payment model.Payment) string {
+	if payment.Kind == model.PaymentKindRent {
+		return payment.OrderId
+	}
+
+	return payment.ID
+}
+
+func NewRefundUsecase(
+	unitOfWork repository.UnitOfWork,
+	paymentProvider payment.PaymentProvider,
+	renterRepo repository.RenterRepository,
+	paymentRepo repository.PaymentRepository,
+	orderDetailRepo repository.OrderDetailRepository,
+	refundRepo repository.RefundRepository,
+) RefundUsecase {
+	return refundUsecase{
+		unitOfWork:            unitOfWork,
+		paymentProvider:       paymentProvider,
+		renterRepository:      renterRepo,
+		paymentRepository:     paymentRepo,
+		orderDetailRepository: orderDetailRepo,
+		refundRepository:      refundRepo,
+	}
+}
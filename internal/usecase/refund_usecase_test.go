@@ -0,0 +1,411 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var refundUsecaseTest = NewRefundUsecase(
+	&pkg.UnitOfWork,
+	fakePaymentProvider,
+	&pkg.RenterRepository,
+	&pkg.PaymentRepository,
+	&pkg.OrderDetailRepository,
+	&pkg.RefundRepository,
+)
+
+func TestRefundUsecase_CreateRefund(t *testing.T) {
+	renterUserId := "3a4b5c6d-7e8f-4a9b-8c0d-1e2f3a4b5c6e"
+
+	renter := &model.Renter{
+		ID:       "4b5c6d7e-8f9a-4b0c-9d1e-2f3a4b5c6d7f",
+		UserId:   renterUserId,
+		RentName: "Twins' Brother Bike Rental",
+	}
+
+	pkg.RenterRepository.Mock.On("FindByIdUser", renterUserId).Return(renter, nil)
+
+	testCases := []struct {
+		Name                  string
+		OrderId               string
+		PaymentId             string
+		Amount                float32
+		ExpectedAmount        float32
+		ExpectedPaymentStatus string
+	}{
+		{
+			Name:                  "partial refund",
+			OrderId:               "5c6d7e8f-9a0b-4c1d-8e2f-3a4b5c6d7e8a",
+			PaymentId:             "6d7e8f9a-0b1c-4d2e-9f3a-4b5c6d7e8f9b",
+			Amount:                25000,
+			ExpectedAmount:        25000,
+			ExpectedPaymentStatus: model.PaymentStatusPartialRefund,
+		},
+		{
+			Name:                  "full refund",
+			OrderId:               "7e8f9a0b-1c2d-4e3f-8a4b-5c6d7e8f9a0c",
+			PaymentId:             "8f9a0b1c-2d3e-4f4a-9b5c-6d7e8f9a0b1d",
+			Amount:                0,
+			ExpectedAmount:        75000,
+			ExpectedPaymentStatus: model.PaymentStatusRefund,
+		},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.Name, func(t *testing.T) {
+			fakePaymentProvider.CreateUrlTransactionWithGateway(dto.PaymentGateway{OrderId: v.OrderId, GrossAmt: 75000})
+
+			_, err := fakePaymentProvider.Simulate(v.OrderId, "settlement", "gopay")
+			assert.Nil(t, err)
+
+			rentPayment := &model.Payment{
+				ID:            v.PaymentId,
+				OrderId:       v.OrderId,
+				Kind:          model.PaymentKindRent,
+				Amount:        75000,
+				PaymentStatus: model.PaymentStatusSettlement,
+			}
+
+			pkg.PaymentRepository.Mock.On("FindByIdForUpdate", v.PaymentId).Return(rentPayment, nil)
+			pkg.OrderDetailRepository.Mock.On("FindByIdOrder", v.OrderId).Return(&[]model.OrderDetail{
+				{OrderId: v.OrderId, Bike: &model.Bike{RenterId: renter.ID}},
+			}, nil)
+			pkg.RefundRepository.Mock.On("FindByIdPayment", v.PaymentId).Return(&[]model.Refund{}, nil)
+			pkg.RefundRepository.Mock.On("Create", mock.MatchedBy(func(refund model.Refund) bool {
+				return refund.PaymentId == v.PaymentId && refund.Amount == v.ExpectedAmount && refund.RequestedBy == renterUserId
+			})).Return(nil)
+			pkg.PaymentRepository.Mock.On("Update", v.PaymentId, mock.MatchedBy(func(payment model.Payment) bool {
+				return payment.PaymentStatus == v.ExpectedPaymentStatus
+			})).Return(nil)
+
+			refund, err := refundUsecaseTest.CreateRefund(renterUserId, model.RoleRenter, v.PaymentId, dto.RefundDTO{Amount: v.Amount, Reason: "bike was damaged"})
+
+			assert.Nil(t, err)
+			assert.NotNil(t, refund)
+
+			assert.Equal(t, v.ExpectedAmount, refund.Amount)
+			assert.Equal(t, model.RefundStatusPending, refund.Status)
+
+			// the refund key sent to the gateway is the id of the refund
+			transaction, _ := fakePaymentProvider.CheckTransaction(v.OrderId)
+			assert.Equal(t, v.ExpectedPaymentStatus, transaction.TransactionStatus)
+			assert.Equal(t, refund.ID, transaction.Refunds[0].RefundKey)
+		})
+	}
+}
+
+func TestRefundUsecase_CreateRefundRejected(t *testing.T) {
+	renterUserId := "9a0b1c2d-3e4f-4a5b-8c6d-7e8f9a0b1c2e"
+
+	renter := &model.Renter{
+		ID:     "0b1c2d3e-4f5a-4b6c-9d7e-8f9a0b1c2d3f",
+		UserId: renterUserId,
+	}
+
+	pkg.RenterRepository.Mock.On("FindByIdUser", renterUserId).Return(renter, nil)
+	pkg.RenterRepository.Mock.On("FindByIdUser", "1c2d3e4f-5a6b-4c7d-8e8f-9a0b1c2d3e4a").Return((*model.Renter)(nil), pkg.ErrRecordNotFound)
+
+	testCases := []struct {
+		Name          string
+		ActorId       string
+		OrderId       string
+		PaymentId     string
+		PaymentStatus string
+		BikeRenterId  string
+		Refunds       []model.Refund
+		Amount        float32
+		ExpectedErr   error
+	}{
+		{
+			Name:          "more than the refundable amount",
+			ActorId:       renterUserId,
+			OrderId:       "2d3e4f5a-6b7c-4d8e-9f9a-0b1c2d3e4f5b",
+			PaymentId:     "3e4f5a6b-7c8d-4e9f-8a0b-1c2d3e4f5a6c",
+			PaymentStatus: model.PaymentStatusPartialRefund,
+			BikeRenterId:  renter.ID,
+			Refunds:       []model.Refund{{ID: "4f5a6b7c-8d9e-4f0a-9b1c-2d3e4f5a6b7d", Amount: 60000, Status: model.RefundStatusSucceeded}},
+			Amount:        20000,
+			ExpectedErr:   pkg.ErrRefundExceedsCaptured,
+		},
+		{
+			Name:          "unpaid payment",
+			ActorId:       renterUserId,
+			OrderId:       "5a6b7c8d-9e0f-4a1b-8c2d-3e4f5a6b7c8e",
+			PaymentId:     "6b7c8d9e-0f1a-4b2c-9d3e-4f5a6b7c8d9f",
+			PaymentStatus: model.PaymentStatusPending,
+			BikeRenterId:  renter.ID,
+			Amount:        20000,
+			ExpectedErr:   pkg.ErrPaymentNotRefundable,
+		},
+		{
+			Name:          "bikes of another renter",
+			ActorId:       renterUserId,
+			OrderId:       "7c8d9e0f-1a2b-4c3d-8e4f-5a6b7c8d9e0a",
+			PaymentId:     "8d9e0f1a-2b3c-4d4e-9f5a-6b7c8d9e0f1b",
+			PaymentStatus: model.PaymentStatusSettlement,
+			BikeRenterId:  "9e0f1a2b-3c4d-4e5f-8a6b-7c8d9e0f1a2c",
+			Amount:        20000,
+			ExpectedErr:   pkg.ErrForbidden,
+		},
+		{
+			Name:        "user without a renter profile",
+			ActorId:     "1c2d3e4f-5a6b-4c7d-8e8f-9a0b1c2d3e4a",
+			PaymentId:   "0f1a2b3c-4d5e-4f6a-9b7c-8d9e0f1a2b3d",
+			Amount:      20000,
+			ExpectedErr: pkg.ErrForbidden,
+		},
+		{
+			Name:        "negative amount",
+			ActorId:     renterUserId,
+			PaymentId:   "0f1a2b3c-4d5e-4f6a-9b7c-8d9e0f1a2b3d",
+			Amount:      -1,
+			ExpectedErr: pkg.ErrInvalidRefund,
+		},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.Name, func(t *testing.T) {
+			if v.OrderId != "" {
+				pkg.PaymentRepository.Mock.On("FindByIdForUpdate", v.PaymentId).Return(&model.Payment{
+					ID:            v.PaymentId,
+					OrderId:       v.OrderId,
+					Kind:          model.PaymentKindRent,
+					Amount:        75000,
+					PaymentStatus: v.PaymentStatus,
+				}, nil)
+				pkg.OrderDetailRepository.Mock.On("FindByIdOrder", v.OrderId).Return(&[]model.OrderDetail{
+					{OrderId: v.OrderId, Bike: &model.Bike{RenterId: v.BikeRenterId}},
+				}, nil)
+				pkg.RefundRepository.Mock.On("FindByIdPayment", v.PaymentId).Return(&v.Refunds, nil)
+			}
+
+			refund, err := refundUsecaseTest.CreateRefund(v.ActorId, model.RoleRenter, v.PaymentId, dto.RefundDTO{Amount: v.Amount})
+
+			assert.Nil(t, refund)
+			assert.True(t, errors.Is(err, v.ExpectedErr))
+			pkg.PaymentRepository.Mock.AssertNotCalled(t, "Update", v.PaymentId, mock.Anything)
+		})
+	}
+}
+
+func TestRefundUsecase_CreateRefundAdmin(t *testing.T) {
+	adminId := "0c1d2e3f-4a5b-4c6d-8e7f-9a0b1c2d3e4f"
+	orderId := "1d2e3f4a-5b6c-4d7e-9f8a-0b1c2d3e4f5a"
+	paymentId := "2e3f4a5b-6c7d-4e8f-8a9b-1c2d3e4f5a6b"
+
+	fakePaymentProvider.CreateUrlTransactionWithGateway(dto.PaymentGateway{OrderId: orderId, GrossAmt: 75000})
+
+	_, err := fakePaymentProvider.Simulate(orderId, "settlement", "gopay")
+	assert.Nil(t, err)
+
+	// the admin has no renter and the order rents no bike of one, neither is looked up
+	pkg.PaymentRepository.Mock.On("FindByIdForUpdate", paymentId).Return(&model.Payment{
+		ID:            paymentId,
+		OrderId:       orderId,
+		Kind:          model.PaymentKindRent,
+		Amount:        75000,
+		PaymentStatus: model.PaymentStatusSettlement,
+	}, nil)
+	pkg.RefundRepository.Mock.On("FindByIdPayment", paymentId).Return(&[]model.Refund{}, nil)
+	pkg.RefundRepository.Mock.On("Create", mock.MatchedBy(func(refund model.Refund) bool {
+		return refund.PaymentId == paymentId && refund.Amount == 30000 && refund.RequestedBy == adminId
+	})).Return(nil)
+	pkg.PaymentRepository.Mock.On("Update", paymentId, mock.MatchedBy(func(payment model.Payment) bool {
+		return payment.PaymentStatus == model.PaymentStatusPartialRefund
+	})).Return(nil)
+
+	refund, err := refundUsecaseTest.CreateRefund(adminId, model.RoleAdmin, paymentId, dto.RefundDTO{Amount: 30000, Reason: "goodwill refund"})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, refund)
+
+	assert.Equal(t, float32(30000), refund.Amount)
+	pkg.RenterRepository.Mock.AssertNotCalled(t, "FindByIdUser", adminId)
+	pkg.OrderDetailRepository.Mock.AssertNotCalled(t, "FindByIdOrder", orderId)
+
+	transaction, _ := fakePaymentProvider.CheckTransaction(orderId)
+	assert.Equal(t, model.PaymentStatusPartialRefund, transaction.TransactionStatus)
+}
+
+func TestRefundUsecase_CreateRefundTopUp(t *testing.T) {
+	adminId := "0c1d2e3f-4a5b-4c6d-8e7f-9a0b1c2d3e4f"
+	paymentId := "dce8ce08-b0c7-4da5-a98a-60bd235b9178"
+
+	fakePaymentProvider.CreateUrlTransactionWithGateway(dto.PaymentGateway{OrderId: paymentId, GrossAmt: 50000})
+
+	_, err := fakePaymentProvider.Simulate(paymentId, "settlement", "bank_transfer")
+	assert.Nil(t, err)
+
+	// the top up is in the wallet already, even an admin cannot pay it out a second time
+	pkg.PaymentRepository.Mock.On("FindByIdForUpdate", paymentId).Return(&model.Payment{
+		ID:            paymentId,
+		WalletId:      "5b227786-2599-45ae-af11-f2cd16a7b314",
+		Kind:          model.PaymentKindTopUp,
+		Amount:        50000,
+		PaymentStatus: model.PaymentStatusSettlement,
+		PaymentType:   "bank_transfer",
+	}, nil)
+
+	refund, err := refundUsecaseTest.CreateRefund(adminId, model.RoleAdmin, paymentId, dto.RefundDTO{Reason: "changed my mind"})
+
+	assert.Nil(t, refund)
+	assert.ErrorIs(t, err, pkg.ErrPaymentNotRefundable)
+	pkg.RefundRepository.Mock.AssertNotCalled(t, "Create", mock.MatchedBy(func(refund model.Refund) bool {
+		return refund.PaymentId == paymentId
+	}))
+
+	transaction, _ := fakePaymentProvider.CheckTransaction(paymentId)
+	assert.Equal(t, model.PaymentStatusSettlement, transaction.TransactionStatus)
+}
+
+func TestRefundUsecase_CreateRefundLateSettlement(t *testing.T) {
+	adminId := "0c1d2e3f-4a5b-4c6d-8e7f-9a0b1c2d3e4f"
+	orderId := "3f4a5b6c-7d8e-4f9a-9b0c-2d3e4f5a6b7c"
+	paymentId := "4a5b6c7d-8e9f-4a0b-8c1d-3e4f5a6b7c8d"
+
+	fakePaymentProvider.CreateUrlTransactionWithGateway(dto.PaymentGateway{OrderId: orderId, GrossAmt: 125000})
+
+	_, err := fakePaymentProvider.Simulate(orderId, "settlement", "gopay")
+	assert.Nil(t, err)
+
+	// the order expired before it was paid, so its deposit was never held either
+	pkg.PaymentRepository.Mock.On("FindByIdForUpdate", paymentId).Return(&model.Payment{
+		ID:            paymentId,
+		OrderId:       orderId,
+		Kind:          model.PaymentKindRent,
+		Amount:        125000,
+		Deposit:       50000,
+		PaymentStatus: model.PaymentStatusLateSettlement,
+	}, nil)
+	pkg.RefundRepository.Mock.On("FindByIdPayment", paymentId).Return(&[]model.Refund{}, nil)
+	pkg.RefundRepository.Mock.On("Create", mock.MatchedBy(func(refund model.Refund) bool {
+		return refund.PaymentId == paymentId && refund.Kind == model.RefundKindPayment && refund.Amount == 125000
+	})).Return(nil)
+	pkg.PaymentRepository.Mock.On("Update", paymentId, mock.MatchedBy(func(payment model.Payment) bool {
+		return payment.PaymentStatus == model.PaymentStatusRefund
+	})).Return(nil)
+
+	_, err = refundUsecaseTest.CreateRefund(adminId, model.RoleAdmin, paymentId, dto.RefundDTO{Amount: 30000, Reason: "paid after expiry"})
+
+	assert.ErrorIs(t, err, pkg.ErrInvalidRefund)
+
+	refund, err := refundUsecaseTest.CreateRefund(adminId, model.RoleAdmin, paymentId, dto.RefundDTO{Reason: "paid after expiry"})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, refund)
+
+	assert.Equal(t, float32(125000), refund.Amount)
+
+	transaction, _ := fakePaymentProvider.CheckTransaction(orderId)
+	assert.Equal(t, model.PaymentStatusRefund, transaction.TransactionStatus)
+}
+
+func TestRefundUsecase_CreateRefundWalletPayment(t *testing.T) {
+	renterUserId := "4a5b6c7d-8e9f-4a0b-9c1d-2e3f4a5b6c7e"
+	customerId := "5b6c7d8e-9f0a-4b1c-8d2e-3f4a5b6c7d8f"
+	orderId := "6c7d8e9f-0a1b-4c2d-9e3f-4a5b6c7d8e9a"
+	paymentId := "7d8e9f0a-1b2c-4d3e-8f4a-5b6c7d8e9f0b"
+
+	renter := &model.Renter{
+		ID:     "8e9f0a1b-2c3d-4e4f-9a5b-6c7d8e9f0a1c",
+		UserId: renterUserId,
+	}
+
+	pkg.RenterRepository.Mock.On("FindByIdUser", renterUserId).Return(renter, nil)
+
+	walletPayment := &model.Payment{
+		ID:            paymentId,
+		OrderId:       orderId,
+		Kind:          model.PaymentKindRent,
+		Amount:        75000,
+		PaymentStatus: model.PaymentStatusSettlement,
+		PaymentType:   model.PaymentTypeWallet,
+	}
+
+	wallet := &model.Wallet{
+		ID:      "9f0a1b2c-3d4e-4f5a-8b6c-7d8e9f0a1b2d",
+		UserId:  customerId,
+		Balance: 5000,
+	}
+
+	pkg.PaymentRepository.Mock.On("FindByIdForUpdate", paymentId).Return(walletPayment, nil)
+	pkg.OrderDetailRepository.Mock.On("FindByIdOrder", orderId).Return(&[]model.OrderDetail{
+		{OrderId: orderId, Bike: &model.Bike{RenterId: renter.ID}},
+	}, nil)
+	pkg.OrderRepository.Mock.On("FindById", orderId).Return(&model.Order{ID: orderId, UserId: customerId, PaymentId: paymentId}, nil)
+	pkg.RefundRepository.Mock.On("FindByIdPayment", paymentId).Return(&[]model.Refund{}, nil)
+	pkg.RefundRepository.Mock.On("Create", mock.MatchedBy(func(refund model.Refund) bool {
+		return refund.PaymentId == paymentId && refund.Amount == 25000
+	})).Return(nil)
+	pkg.RefundRepository.Mock.On("Update", mock.Anything, mock.MatchedBy(func(refund model.Refund) bool {
+		return refund.Status == model.RefundStatusSucceeded
+	})).Return(nil)
+
+	pkg.WalletRepository.Mock.On("FindByIdUserForUpdate", customerId).Return(wallet, nil)
+	pkg.WalletRepository.Mock.On("UpdateBalance", wallet.ID, mock.MatchedBy(func(walletUC model.Wallet) bool {
+		return walletUC.Balance == 30000
+	})).Return(nil)
+	pkg.WalletRepository.Mock.On("CreateTransaction", mock.MatchedBy(func(transaction model.WalletTransaction) bool {
+		return transaction.WalletId == wallet.ID && transaction.Type == model.WalletTransactionRefund && transaction.Amount == 25000 && transaction.RefundId != ""
+	})).Return(nil)
+
+	// the renter gives back what the wallet payment credited it
+	pkg.LedgerRepository.Mock.On("FindByIdPayment", paymentId).Return(&[]model.LedgerEntry{
+		{PaymentId: paymentId, Account: model.LedgerAccountCustomerWallet, Debit: 75000},
+		{PaymentId: paymentId, Account: model.LedgerAccountRenterPayable, RenterId: renter.ID, Credit: 75000},
+	}, nil)
+	pkg.LedgerRepository.Mock.On("Create", mock.MatchedBy(func(entries []model.LedgerEntry) bool {
+		return len(entries) == 2 && entries[0].PaymentId == paymentId &&
+			entries[0].Account == model.LedgerAccountCustomerWallet && entries[0].Credit == 25000 &&
+			entries[1].Account == model.LedgerAccountRenterPayable && entries[1].Debit == 25000
+	})).Return(nil)
+
+	pkg.PaymentRepository.Mock.On("Update", paymentId, mock.MatchedBy(func(payment model.Payment) bool {
+		return payment.PaymentStatus == model.PaymentStatusPartialRefund
+	})).Return(nil)
+
+	refund, err := refundUsecaseTest.CreateRefund(renterUserId, model.RoleRenter, paymentId, dto.RefundDTO{Amount: 25000, Reason: "bike was damaged"})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, refund)
+
+	// the wallet is credited right away, nothing waits for the payment gateway
+	assert.Equal(t, model.RefundStatusSucceeded, refund.Status)
+	assert.Equal(t, float32(30000), wallet.Balance)
+
+	_, err = fakePaymentProvider.CheckTransaction(orderId)
+	assert.Equal(t, pkg.ErrRecordNotFound, err)
+}
+
+func TestRefundUsecase_FindAllRefunds(t *testing.T) {
+	renterUserId := "1a2b3c4d-5e6f-4a7b-8c9d-0e1f2a3b4c5e"
+	paymentId := "2b3c4d5e-6f7a-4b8c-9d0e-1f2a3b4c5d6f"
+	orderId := "3c4d5e6f-7a8b-4c9d-8e0f-2a3b4c5d6e7a"
+
+	renter := &model.Renter{
+		ID:     "4d5e6f7a-8b9c-4d0e-9f1a-3b4c5d6e7f8b",
+		UserId: renterUserId,
+	}
+
+	refunds := &[]model.Refund{
+		{ID: "5e6f7a8b-9c0d-4e1f-8a2b-4c5d6e7f8a9c", PaymentId: paymentId, Amount: 25000, Status: model.RefundStatusSucceeded},
+	}
+
+	pkg.RenterRepository.Mock.On("FindByIdUser", renterUserId).Return(renter, nil)
+	pkg.PaymentRepository.Mock.On("FindById", paymentId).Return(&model.Payment{ID: paymentId, OrderId: orderId}, nil)
+	pkg.OrderDetailRepository.Mock.On("FindByIdOrder", orderId).Return(&[]model.OrderDetail{
+		{OrderId: orderId, Bike: &model.Bike{RenterId: renter.ID}},
+	}, nil)
+	pkg.RefundRepository.Mock.On("FindByIdPayment", paymentId).Return(refunds, nil)
+
+	results, err := refundUsecaseTest.FindAllRefunds(renterUserId, model.RoleRenter, paymentId)
+
+	assert.Nil(t, err)
+	assert.Equal(t, refunds, results)
+}
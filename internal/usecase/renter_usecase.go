@@ -6,6 +6,7 @@ import (
 	"github.com/arvinpaundra/go-rent-bike/internal/dto"
 	"github.com/arvinpaundra/go-rent-bike/internal/model"
 	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
 	"github.com/google/uuid"
 )
 
@@ -15,14 +16,22 @@ type RenterUsecase interface {
 	FindAllRenters(rentName string) (*[]model.Renter, error)
 	FindByIdRenter(renterId string) (*model.Renter, error)
 	FindAllRenterReports(renterId string) (*[]model.Report, error)
+	CreatePricingRule(renterId string, pricingRuleDTO dto.PricingRuleDTO) error
+	FindAllPricingRules(renterId string) (*[]model.PricingRule, error)
+	DeletePricingRule(renterId string, pricingRuleId string) error
 	UpdateRenter(renterId string, renterDTO dto.RenterDTO) error
 	DeleteRenter(renterId string) error
+	SuspendRenter(renterId string, suspensionDTO dto.RenterSuspensionDTO) error
+	UnsuspendRenter(renterId string) error
+	FindAllReports(status string) (*[]model.Report, error)
+	ResolveReport(reportId string, actorId string, resolutionDTO dto.ReportResolutionDTO) error
 }
 
 type renterUsecase struct {
-	renterRepository repository.RenterRepository
-	userRepository   repository.UserRepository
-	reportRepository repository.ReportRepository
+	renterRepository      repository.RenterRepository
+	userRepository        repository.UserRepository
+	reportRepository      repository.ReportRepository
+	pricingRuleRepository repository.PricingRuleRepository
 }
 
 func (r renterUsecase) CreateRenter(renterDTO dto.RenterDTO) error {
@@ -32,14 +41,23 @@ func (r renterUsecase) CreateRenter(renterDTO dto.RenterDTO) error {
 		return err
 	}
 
+	if err := validateCancellationPolicy(renterDTO); err != nil {
+		return err
+	}
+
 	renter := model.Renter{
-		ID:          uuid.NewString(),
-		UserId:      userId,
-		RentName:    renterDTO.RentName,
-		RentAddress: renterDTO.RentAddress,
-		Description: renterDTO.Description,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		ID:                     uuid.NewString(),
+		UserId:                 userId,
+		RentName:               renterDTO.RentName,
+		RentAddress:            renterDTO.RentAddress,
+		Description:            renterDTO.Description,
+		FreeCancellationHours:  renterDTO.FreeCancellationHours,
+		CancellationFeePercent: renterDTO.CancellationFeePercent,
+		BankName:               renterDTO.BankName,
+		BankAccountNumber:      renterDTO.BankAccountNumber,
+		BankAccountName:        renterDTO.BankAccountName,
+		CreatedAt:              time.Now(),
+		UpdatedAt:              time.Now(),
 	}
 
 	if err := r.renterRepository.Create(renter); err != nil {
@@ -60,6 +78,7 @@ func (r renterUsecase) CreateReportRenter(renterId string, reportDTO dto.ReportD
 		UserId:     reportDTO.UserId,
 		TitleIssue: reportDTO.TitleIssue,
 		BodyIssue:  reportDTO.BodyIssue,
+		Status:     model.ReportStatusOpen,
 		CreatedAt:  time.Now(),
 		UpdatedAt:  time.Now(),
 	}
@@ -107,6 +126,66 @@ func (r renterUsecase) FindAllRenterReports(renterId string) (*[]model.Report, e
 	return reports, nil
 }
 
+func (r renterUsecase) CreatePricingRule(renterId string, pricingRuleDTO dto.PricingRuleDTO) error {
+	if _, err := r.renterRepository.FindById(renterId); err != nil {
+		return err
+	}
+
+	if err := validatePricingRule(pricingRuleDTO); err != nil {
+		return err
+	}
+
+	pricingRule := model.PricingRule{
+		ID:         uuid.NewString(),
+		RenterId:   renterId,
+		Name:       pricingRuleDTO.Name,
+		StartAt:    pricingRuleDTO.StartAt,
+		EndAt:      pricingRuleDTO.EndAt,
+		Multiplier: pricingRuleDTO.Multiplier,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+
+	err := r.pricingRuleRepository.Create(pricingRule)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r renterUsecase) FindAllPricingRules(renterId string) (*[]model.PricingRule, error) {
+	if _, err := r.renterRepository.FindById(renterId); err != nil {
+		return nil, err
+	}
+
+	pricingRules, err := r.pricingRuleRepository.FindByIdRenter(renterId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return pricingRules, nil
+}
+
+func (r renterUsecase) DeletePricingRule(renterId string, pricingRuleId string) error {
+	pricingRules, err := r.pricingRuleRepository.FindByIdRenter(renterId)
+
+	if err != nil {
+		return err
+	}
+
+	// a renter can only delete its own rules
+	for i := range *pricingRules {
+		if (*pricingRules)[i].ID == pricingRuleId {
+			return r.pricingRuleRepository.Delete(pricingRuleId)
+		}
+	}
+
+	return pkg.ErrRecordNotFound
+}
+
 func (r renterUsecase) UpdateRenter(renterId string, renterDTO dto.RenterDTO) error {
 	var err error
 
@@ -117,14 +196,23 @@ func (r renterUsecase) UpdateRenter(renterId string, renterDTO dto.RenterDTO) er
 		return err
 	}
 
+	if err := validateCancellationPolicy(renterDTO); err != nil {
+		return err
+	}
+
 	renterUC := model.Renter{
-		ID:          renter.ID,
-		UserId:      renter.UserId,
-		RentName:    renterDTO.RentName,
-		RentAddress: renterDTO.RentAddress,
-		Description: renterDTO.Description,
-		CreatedAt:   renter.CreatedAt,
-		UpdatedAt:   time.Now(),
+		ID:                     renter.ID,
+		UserId:                 renter.UserId,
+		RentName:               renterDTO.RentName,
+		RentAddress:            renterDTO.RentAddress,
+		Description:            renterDTO.Description,
+		FreeCancellationHours:  renterDTO.FreeCancellationHours,
+		CancellationFeePercent: renterDTO.CancellationFeePercent,
+		BankName:               renterDTO.BankName,
+		BankAccountNumber:      renterDTO.BankAccountNumber,
+		BankAccountName:        renterDTO.BankAccountName,
+		CreatedAt:              renter.CreatedAt,
+		UpdatedAt:              time.Now(),
 	}
 
 	err = r.renterRepository.Update(renterId, renterUC)
@@ -150,14 +238,94 @@ func (r renterUsecase) DeleteRenter(renterId string) error {
 	return nil
 }
 
+func validateCancellationPolicy(renterDTO dto.RenterDTO) error {
+	if renterDTO.FreeCancellationHours < 0 || renterDTO.CancellationFeePercent < 0 || renterDTO.CancellationFeePercent > 100 {
+		return pkg.ErrInvalidCancellationPolicy
+	}
+
+	return nil
+}
+
+func (r renterUsecase) SuspendRenter(renterId string, suspensionDTO dto.RenterSuspensionDTO) error {
+	renter, err := r.renterRepository.FindById(renterId)
+
+	if err != nil {
+		return err
+	}
+
+	// suspending again keeps the date of the first suspension and only replaces its reason
+	suspendedAt := time.Now()
+	if renter.IsSuspended() {
+		suspendedAt = *renter.SuspendedAt
+	}
+
+	return r.renterRepository.UpdateSuspension(renterId, &suspendedAt, suspensionDTO.Reason)
+}
+
+func (r renterUsecase) UnsuspendRenter(renterId string) error {
+	if _, err := r.renterRepository.FindById(renterId); err != nil {
+		return err
+	}
+
+	return r.renterRepository.UpdateSuspension(renterId, nil, "")
+}
+
+func (r renterUsecase) FindAllReports(status string) (*[]model.Report, error) {
+	reports, err := r.reportRepository.FindAllByStatus(status)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
+
+// ResolveReport closes an open report as resolved or dismissed, recording the admin that closed it and why.
+func (r renterUsecase) ResolveReport(reportId string, actorId string, resolutionDTO dto.ReportResolutionDTO) error {
+	if resolutionDTO.Status != model.ReportStatusResolved && resolutionDTO.Status != model.ReportStatusDismissed {
+		return pkg.ErrInvalidReportResolution
+	}
+
+	report, err := r.reportRepository.FindById(reportId)
+
+	if err != nil {
+		return err
+	}
+
+	// reports filed before they had a status are still open
+	if report.Status != "" && report.Status != model.ReportStatusOpen {
+		return pkg.ErrReportAlreadyResolved
+	}
+
+	resolvedAt := time.Now()
+
+	return r.reportRepository.Update(reportId, model.Report{
+		Status:     resolutionDTO.Status,
+		Resolution: resolutionDTO.Resolution,
+		ResolvedBy: actorId,
+		ResolvedAt: &resolvedAt,
+		UpdatedAt:  resolvedAt,
+	})
+}
+
+func validatePricingRule(pricingRuleDTO dto.PricingRuleDTO) error {
+	if pricingRuleDTO.Name == "" || pricingRuleDTO.Multiplier <= 0 || !pricingRuleDTO.EndAt.After(pricingRuleDTO.StartAt) {
+		return pkg.ErrInvalidPricing
+	}
+
+	return nil
+}
+
 func NewRenterUsecase(
 	renterRepo repository.RenterRepository,
 	userRepo repository.UserRepository,
 	reportRepo repository.ReportRepository,
+	pricingRuleRepo repository.PricingRuleRepository,
 ) RenterUsecase {
 	return renterUsecase{
-		renterRepository: renterRepo,
-		userRepository:   userRepo,
-		reportRepository: reportRepo,
+		renterRepository:      renterRepo,
+		userRepository:        userRepo,
+		reportRepository:      reportRepo,
+		pricingRuleRepository: pricingRuleRepo,
 	}
 }
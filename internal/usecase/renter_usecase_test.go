@@ -16,6 +16,7 @@ var renterUsecaseTest = NewRenterUsecase(
 	&pkg.RenterRepository,
 	&pkg.UserRepository,
 	&pkg.ReportRepository,
+	&pkg.PricingRuleRepository,
 )
 
 func TestRenterUsecase_CreateRenter(t *testing.T) {
@@ -48,6 +49,34 @@ func TestRenterUsecase_CreateRenter(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestRenterUsecase_CreateRenterInvalidCancellationPolicy(t *testing.T) {
+	userId := "e694b986-cf9b-4b33-9147-3838e9014662"
+
+	user := &model.User{
+		ID:        "e694b986-cf9b-4b33-9147-3838e9014662",
+		Fullname:  "Arvin",
+		Role:      "customer",
+		Email:     "arvin@mail.com",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	pkg.UserRepository.Mock.On("FindById", userId).Return(user, nil)
+
+	renterDTO := dto.RenterDTO{
+		UserId:                 "e694b986-cf9b-4b33-9147-3838e9014662",
+		RentName:               "Twins' Brother Bike Rental",
+		RentAddress:            "Jl Morioh",
+		Description:            "Full with description texts",
+		FreeCancellationHours:  24,
+		CancellationFeePercent: 150,
+	}
+
+	err := renterUsecaseTest.CreateRenter(renterDTO)
+
+	assert.ErrorIs(t, err, pkg.ErrInvalidCancellationPolicy)
+}
+
 func TestRenterUsecase_CreateReportRenter(t *testing.T) {
 	renterId := "aefde097-3145-4961-9eed-9e916b9def36"
 
@@ -168,6 +197,91 @@ func TestRenterUsecase_FindAllRenterReports(t *testing.T) {
 	assert.Equal(t, (*reports)[0].BodyIssue, (*results)[0].BodyIssue)
 }
 
+func TestRenterUsecase_CreatePricingRule(t *testing.T) {
+	renterId := "9c4e7ed0-4bc2-4e9b-9b4a-4f7c7a5a6f55"
+
+	renter := &model.Renter{
+		ID:       "9c4e7ed0-4bc2-4e9b-9b4a-4f7c7a5a6f55",
+		UserId:   "b2a4d5da-198f-4742-adb1-6700957f9510",
+		RentName: "Twins' Brother Bike Rental",
+	}
+
+	pkg.RenterRepository.Mock.On("FindById", renterId).Return(renter, nil)
+	pkg.PricingRuleRepository.Mock.On("Create", mock.MatchedBy(func(pricingRule model.PricingRule) bool {
+		return pricingRule.RenterId == renterId && pricingRule.Name == "high season" && pricingRule.Multiplier == 1.5
+	})).Return(nil)
+
+	testCases := []struct {
+		name           string
+		pricingRuleDTO dto.PricingRuleDTO
+		err            error
+	}{
+		{
+			name: "valid rule",
+			pricingRuleDTO: dto.PricingRuleDTO{
+				Name:       "high season",
+				StartAt:    time.Date(2022, 12, 20, 0, 0, 0, 0, time.UTC),
+				EndAt:      time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC),
+				Multiplier: 1.5,
+			},
+			err: nil,
+		},
+		{
+			name: "window ends before it starts",
+			pricingRuleDTO: dto.PricingRuleDTO{
+				Name:       "high season",
+				StartAt:    time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC),
+				EndAt:      time.Date(2022, 12, 20, 0, 0, 0, 0, time.UTC),
+				Multiplier: 1.5,
+			},
+			err: pkg.ErrInvalidPricing,
+		},
+		{
+			name: "no multiplier",
+			pricingRuleDTO: dto.PricingRuleDTO{
+				Name:    "high season",
+				StartAt: time.Date(2022, 12, 20, 0, 0, 0, 0, time.UTC),
+				EndAt:   time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC),
+			},
+			err: pkg.ErrInvalidPricing,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := renterUsecaseTest.CreatePricingRule(renterId, tc.pricingRuleDTO)
+
+			assert.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func TestRenterUsecase_DeletePricingRule(t *testing.T) {
+	renterId := "ad5f8fe1-5cd3-4fac-8c5b-5a8d8b6b7a66"
+
+	pricingRules := &[]model.PricingRule{
+		{
+			ID:         "be6a9af2-6de4-4abd-9d6c-6b9e9c7c8b77",
+			RenterId:   "ad5f8fe1-5cd3-4fac-8c5b-5a8d8b6b7a66",
+			Name:       "low season",
+			Multiplier: 0.8,
+		},
+	}
+
+	pkg.PricingRuleRepository.Mock.On("FindByIdRenter", renterId).Return(pricingRules, nil)
+	pkg.PricingRuleRepository.Mock.On("Delete", "be6a9af2-6de4-4abd-9d6c-6b9e9c7c8b77").Return(nil)
+
+	err := renterUsecaseTest.DeletePricingRule(renterId, "be6a9af2-6de4-4abd-9d6c-6b9e9c7c8b77")
+
+	assert.Nil(t, err)
+
+	// a rule of another renter
+	err = renterUsecaseTest.DeletePricingRule(renterId, "cf7bab03-7ef5-4bce-8e7d-7caf0d8d9c88")
+
+	assert.ErrorIs(t, err, pkg.ErrRecordNotFound)
+	pkg.PricingRuleRepository.Mock.AssertNotCalled(t, "Delete", "cf7bab03-7ef5-4bce-8e7d-7caf0d8d9c88")
+}
+
 func TestRenterUsecase_UpdateRenter(t *testing.T) {
 	renterId := "aefde097-3145-4961-9eed-9e916b9def36"
 
@@ -215,3 +329,84 @@ func TestRenterUsecase_DeleteRenter(t *testing.T) {
 
 	assert.Nil(t, err)
 }
+
+func TestRenterUsecase_SuspendRenter(t *testing.T) {
+	renterId := "3c8e1f4a-6b2d-4e9f-a7c5-1d3f5b7e9a20"
+
+	pkg.RenterRepository.Mock.On("FindById", renterId).Return(&model.Renter{ID: renterId}, nil)
+	pkg.RenterRepository.Mock.On("UpdateSuspension", renterId, mock.AnythingOfType("*time.Time"), "fake bikes listed").Return(nil)
+
+	err := renterUsecaseTest.SuspendRenter(renterId, dto.RenterSuspensionDTO{Reason: "fake bikes listed"})
+
+	assert.Nil(t, err)
+}
+
+func TestRenterUsecase_SuspendRenterAgain(t *testing.T) {
+	renterId := "8d2f4a6c-0e1b-4c3d-9f5a-7b9d1e3f5a42"
+	suspendedAt := time.Now().Add(-48 * time.Hour)
+
+	pkg.RenterRepository.Mock.On("FindById", renterId).Return(&model.Renter{ID: renterId, SuspendedAt: &suspendedAt}, nil)
+	pkg.RenterRepository.Mock.On("UpdateSuspension", renterId, mock.MatchedBy(func(at *time.Time) bool {
+		return at != nil && at.Equal(suspendedAt)
+	}), "still listing fake bikes").Return(nil)
+
+	err := renterUsecaseTest.SuspendRenter(renterId, dto.RenterSuspensionDTO{Reason: "still listing fake bikes"})
+
+	assert.Nil(t, err)
+}
+
+func TestRenterUsecase_UnsuspendRenter(t *testing.T) {
+	renterId := "5e7a9c1d-3f4b-4a6e-8c0d-2f4a6c8e0b63"
+	suspendedAt := time.Now()
+
+	pkg.RenterRepository.Mock.On("FindById", renterId).Return(&model.Renter{ID: renterId, SuspendedAt: &suspendedAt}, nil)
+	pkg.RenterRepository.Mock.On("UpdateSuspension", renterId, (*time.Time)(nil), "").Return(nil)
+
+	err := renterUsecaseTest.UnsuspendRenter(renterId)
+
+	assert.Nil(t, err)
+}
+
+func TestRenterUsecase_FindAllReports(t *testing.T) {
+	reports := &[]model.Report{
+		{ID: "9a1c3e5f-7b9d-4f1a-8c3e-5f7a9b1d3e84", Status: model.ReportStatusOpen},
+	}
+
+	pkg.ReportRepository.Mock.On("FindAllByStatus", model.ReportStatusOpen).Return(reports, nil)
+
+	results, err := renterUsecaseTest.FindAllReports(model.ReportStatusOpen)
+
+	assert.Nil(t, err)
+	assert.Len(t, *results, 1)
+}
+
+func TestRenterUsecase_ResolveReport(t *testing.T) {
+	reportId := "2b4d6f8a-0c1e-4a3b-9d5f-7a9c1e3b5d06"
+	adminId := "6c8e0a2b-4d6f-4b8a-9c1e-3f5a7c9e1b27"
+
+	pkg.ReportRepository.Mock.On("FindById", reportId).Return(&model.Report{ID: reportId, Status: model.ReportStatusOpen}, nil)
+	pkg.ReportRepository.Mock.On("Update", reportId, mock.MatchedBy(func(report model.Report) bool {
+		return report.Status == model.ReportStatusResolved && report.Resolution == "renter warned" && report.ResolvedBy == adminId && report.ResolvedAt != nil
+	})).Return(nil)
+
+	err := renterUsecaseTest.ResolveReport(reportId, adminId, dto.ReportResolutionDTO{
+		Status:     model.ReportStatusResolved,
+		Resolution: "renter warned",
+	})
+
+	assert.Nil(t, err)
+}
+
+func TestRenterUsecase_ResolveReportInvalid(t *testing.T) {
+	resolvedId := "4f6a8c0e-2b3d-4e5f-a7b9-1c3e5a7c9e48"
+
+	pkg.ReportRepository.Mock.On("FindById", resolvedId).Return(&model.Report{ID: resolvedId, Status: model.ReportStatusDismissed}, nil)
+
+	err := renterUsecaseTest.ResolveReport(resolvedId, "6c8e0a2b-4d6f-4b8a-9c1e-3f5a7c9e1b27", dto.ReportResolutionDTO{Status: model.ReportStatusOpen})
+
+	assert.ErrorIs(t, err, pkg.ErrInvalidReportResolution)
+
+	err = renterUsecaseTest.ResolveReport(resolvedId, "6c8e0a2b-4d6f-4b8a-9c1e-3f5a7c9e1b27", dto.ReportResolutionDTO{Status: model.ReportStatusResolved})
+
+	assert.ErrorIs(t, err, pkg.ErrReportAlreadyResolved)
+}
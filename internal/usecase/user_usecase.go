@@ -9,13 +9,14 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/mailer"
 	"github.com/arvinpaundra/go-rent-bike/internal/model"
 	"github.com/arvinpaundra/go-rent-bike/internal/repository"
 )
 
 type UserUsecase interface {
 	RegisterUser(userDTO dto.UserDTO) error
-	LoginUser(email string, password string) (string, error)
+	CreateAdmin(userDTO dto.UserDTO) error
 	FindAllUsers() (*[]model.User, error)
 	FindByIdUser(userId string) (*model.User, error)
 	FindAllUserHistories(userId string) (*[]model.History, error)
@@ -29,54 +30,61 @@ type userUsecase struct {
 	userRepository    repository.UserRepository
 	historyRepository repository.HistoryRepository
 	orderRepository   repository.OrderRepository
+	userTokens        userTokenMailer
 }
 
+// RegisterUser creates the account and sends the verification email. The account is kept when the email cannot
+// be sent, ErrMailNotSent tells to ask for another one.
 func (u userUsecase) RegisterUser(userDTO dto.UserDTO) error {
-	user, _ := u.userRepository.FindByEmail(userDTO.Email)
+	user, err := u.createUser(userDTO, nil)
 
-	if user != nil {
-		return pkg.ErrDataAlreadyExist
+	if err != nil {
+		return err
 	}
 
-	hashedPassword, _ := helper.HashPassword(userDTO.Password)
+	return u.userTokens.send(user, model.UserTokenEmailVerification)
+}
 
-	userUC := model.User{
-		ID:        uuid.NewString(),
-		Fullname:  userDTO.Fullname,
-		Phone:     userDTO.Phone,
-		Address:   userDTO.Address,
-		Role:      userDTO.Role,
-		Email:     userDTO.Email,
-		Password:  hashedPassword,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-	}
+// CreateAdmin creates an admin account, admins cannot register through the api and are created from the command line.
+// The email of an admin is taken as verified.
+func (u userUsecase) CreateAdmin(userDTO dto.UserDTO) error {
+	userDTO.Role = model.RoleAdmin
+	now := time.Now()
 
-	err := u.userRepository.Create(userUC)
+	_, err := u.createUser(userDTO, &now)
 
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return err
 }
 
-func (u userUsecase) LoginUser(email string, password string) (string, error) {
-	user, err := u.userRepository.FindByEmail(email)
+func (u userUsecase) createUser(userDTO dto.UserDTO, emailVerifiedAt *time.Time) (model.User, error) {
+	user, _ := u.userRepository.FindByEmail(userDTO.Email)
 
-	if err != nil {
-		return "", err
+	if user != nil {
+		return model.User{}, pkg.ErrDataAlreadyExist
 	}
 
-	ok := helper.ComparePassword(user.Password, password)
+	hashedPassword, _ := helper.HashPassword(userDTO.Password)
 
-	if !ok {
-		return "", pkg.ErrRecordNotFound
+	userUC := model.User{
+		ID:              uuid.NewString(),
+		Fullname:        userDTO.Fullname,
+		Phone:           userDTO.Phone,
+		Address:         userDTO.Address,
+		Role:            userDTO.Role,
+		Email:           userDTO.Email,
+		Password:        hashedPassword,
+		EmailVerifiedAt: emailVerifiedAt,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
 	}
 
-	token, _ := helper.CreateToken(user.ID, user.Role)
+	err := u.userRepository.Create(userUC)
+
+	if err != nil {
+		return model.User{}, err
+	}
 
-	return token, nil
+	return userUC, nil
 }
 
 func (u userUsecase) FindAllUsers() (*[]model.User, error) {
@@ -176,10 +184,14 @@ func NewUserUsecase(
 	userRepo repository.UserRepository,
 	historyRepo repository.HistoryRepository,
 	orderRepo repository.OrderRepository,
+	unitOfWork repository.UnitOfWork,
+	mailer mailer.Mailer,
+	accountPolicy AccountPolicy,
 ) UserUsecase {
 	return userUsecase{
 		userRepository:    userRepo,
 		historyRepository: historyRepo,
 		orderRepository:   orderRepo,
+		userTokens:        userTokenMailer{unitOfWork: unitOfWork, mailer: mailer, policy: accountPolicy},
 	}
 }
@@ -4,9 +4,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/arvinpaundra/go-rent-bike/configs"
-	"github.com/arvinpaundra/go-rent-bike/helper"
-
 	"github.com/arvinpaundra/go-rent-bike/internal/dto"
 	"github.com/arvinpaundra/go-rent-bike/internal/model"
 	"github.com/arvinpaundra/go-rent-bike/pkg"
@@ -18,6 +15,9 @@ var userUsecaseTest = NewUserUsecase(
 	&pkg.UserRepository,
 	&pkg.HistoryRepository,
 	&pkg.OrderRepository,
+	&pkg.UnitOfWork,
+	testMailer,
+	testAccountPolicy,
 )
 
 // TODO register user test
@@ -34,39 +34,48 @@ func TestUserUsecase_Register(t *testing.T) {
 	pkg.UserRepository.Mock.On("FindByEmail", userDTO.Email).Return(nil, nil)
 
 	pkg.UserRepository.Mock.On("Create", mock.Anything).Return(nil)
+	pkg.UserTokenRepository.Mock.On("MarkAllUsed", mock.Anything, model.UserTokenEmailVerification, mock.Anything).Return(nil)
+	pkg.UserTokenRepository.Mock.On("Create", mock.MatchedBy(func(token model.UserToken) bool {
+		return token.Purpose == model.UserTokenEmailVerification
+	})).Return(nil)
 
 	err := userUsecaseTest.RegisterUser(userDTO)
 
 	assert.Nil(t, err)
-}
 
-// TODO login user test
-func TestUserUsecase_LoginUser(t *testing.T) {
-	configs.InitConfig()
+	// the account waits for its email to be verified
+	pkg.UserRepository.Mock.AssertCalled(t, "Create", mock.MatchedBy(func(user model.User) bool {
+		return user.Email == userDTO.Email && user.EmailVerifiedAt == nil
+	}))
 
-	email := "arvin@mail.com"
-	password := "123"
+	msg, sent := testMailer.Last(userDTO.Email)
 
-	hashedPassword, _ := helper.HashPassword(password)
+	assert.True(t, sent)
+	assert.Equal(t, "Verify your email", msg.Subject)
+}
 
-	user := &model.User{
-		ID:        "e694b986-cf9b-4b33-9147-3838e9014662",
-		Fullname:  "Arvin Paundra Ardana",
-		Phone:     "087654321",
-		Address:   "Jl Morioh",
-		Role:      "customer",
-		Email:     "arvin@mail.com",
-		Password:  hashedPassword,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+func TestUserUsecase_CreateAdmin(t *testing.T) {
+	userDTO := dto.UserDTO{
+		Fullname: "Speedwagon",
+		Role:     "customer",
+		Email:    "speedwagon@mail.com",
+		Password: "123",
 	}
 
-	pkg.UserRepository.Mock.On("FindByEmail", mock.Anything).Return(user, nil)
+	pkg.UserRepository.Mock.On("FindByEmail", userDTO.Email).Return(nil, nil)
+
+	pkg.UserRepository.Mock.On("Create", mock.Anything).Return(nil)
 
-	result, err := userUsecaseTest.LoginUser(email, password)
+	err := userUsecaseTest.CreateAdmin(userDTO)
 
 	assert.Nil(t, err)
-	assert.NotEmpty(t, result)
+
+	pkg.UserRepository.Mock.AssertCalled(t, "Create", mock.MatchedBy(func(user model.User) bool {
+		return user.Email == userDTO.Email && user.Role == model.RoleAdmin && user.IsEmailVerified()
+	}))
+
+	_, sent := testMailer.Last(userDTO.Email)
+	assert.False(t, sent)
 }
 
 func TestUserUsecase_FindAllUsers(t *testing.T) {
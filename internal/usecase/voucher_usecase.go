@@ -0,0 +1,340 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/pricing"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/google/uuid"
+)
+
+type VoucherUsecase interface {
+	CreateVoucher(actorId string, voucherDTO dto.VoucherDTO) (*model.Voucher, error)
+	FindAllVouchers(actorId string) (*[]model.Voucher, error)
+	FindByIdVoucher(actorId string, voucherId string) (*model.Voucher, error)
+	UpdateVoucher(actorId string, voucherId string, voucherDTO dto.VoucherDTO) error
+	DeleteVoucher(actorId string, voucherId string) error
+	CreatePlatformVoucher(voucherDTO dto.VoucherDTO) (*model.Voucher, error)
+	FindAllPlatformVouchers() (*[]model.Voucher, error)
+	FindByIdPlatformVoucher(voucherId string) (*model.Voucher, error)
+	UpdatePlatformVoucher(voucherId string, voucherDTO dto.VoucherDTO) error
+	DeletePlatformVoucher(voucherId string) error
+}
+
+type voucherUsecase struct {
+	voucherRepository repository.VoucherRepository
+	renterRepository  repository.RenterRepository
+}
+
+// platformVoucher is the renter of the vouchers admins make for the whole platform, they discount the bikes of any renter.
+const platformVoucher = ""
+
+func (u voucherUsecase) CreateVoucher(actorId string, voucherDTO dto.VoucherDTO) (*model.Voucher, error) {
+	renter, err := u.renterRepository.FindByIdUser(actorId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return u.createVoucher(renter.ID, voucherDTO)
+}
+
+func (u voucherUsecase) FindAllVouchers(actorId string) (*[]model.Voucher, error) {
+	renter, err := u.renterRepository.FindByIdUser(actorId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return u.findAllVouchers(renter.ID)
+}
+
+func (u voucherUsecase) FindByIdVoucher(actorId string, voucherId string) (*model.Voucher, error) {
+	renter, err := u.renterRepository.FindByIdUser(actorId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return u.findVoucher(renter.ID, voucherId)
+}
+
+func (u voucherUsecase) UpdateVoucher(actorId string, voucherId string, voucherDTO dto.VoucherDTO) error {
+	voucher, err := u.FindByIdVoucher(actorId, voucherId)
+
+	if err != nil {
+		return err
+	}
+
+	return u.updateVoucher(*voucher, voucherDTO)
+}
+
+func (u voucherUsecase) DeleteVoucher(actorId string, voucherId string) error {
+	if _, err := u.FindByIdVoucher(actorId, voucherId); err != nil {
+		return err
+	}
+
+	err := u.voucherRepository.Delete(voucherId)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u voucherUsecase) CreatePlatformVoucher(voucherDTO dto.VoucherDTO) (*model.Voucher, error) {
+	return u.createVoucher(platformVoucher, voucherDTO)
+}
+
+func (u voucherUsecase) FindAllPlatformVouchers() (*[]model.Voucher, error) {
+	return u.findAllVouchers(platformVoucher)
+}
+
+func (u voucherUsecase) FindByIdPlatformVoucher(voucherId string) (*model.Voucher, error) {
+	return u.findVoucher(platformVoucher, voucherId)
+}
+
+func (u voucherUsecase) UpdatePlatformVoucher(voucherId string, voucherDTO dto.VoucherDTO) error {
+	voucher, err := u.FindByIdPlatformVoucher(voucherId)
+
+	if err != nil {
+		return err
+	}
+
+	return u.updateVoucher(*voucher, voucherDTO)
+}
+
+func (u voucherUsecase) DeletePlatformVoucher(voucherId string) error {
+	if _, err := u.FindByIdPlatformVoucher(voucherId); err != nil {
+		return err
+	}
+
+	err := u.voucherRepository.Delete(voucherId)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u voucherUsecase) createVoucher(renterId string, voucherDTO dto.VoucherDTO) (*model.Voucher, error) {
+	if err := validateVoucher(voucherDTO); err != nil {
+		return nil, err
+	}
+
+	code := normalizeVoucherCode(voucherDTO.Code)
+
+	// codes are typed by customers at checkout, so they are unique across renters
+	_, err := u.voucherRepository.FindByCode(code)
+
+	if err == nil {
+		return nil, pkg.ErrDataAlreadyExist
+	} else if !errors.Is(err, pkg.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	voucher := model.Voucher{
+		ID:            uuid.NewString(),
+		RenterId:      renterId,
+		Code:          code,
+		DiscountType:  voucherDTO.DiscountType,
+		DiscountValue: voucherDTO.DiscountValue,
+		MaxDiscount:   voucherDTO.MaxDiscount,
+		MinSpend:      voucherDTO.MinSpend,
+		UsageLimit:    voucherDTO.UsageLimit,
+		PerUserLimit:  voucherDTO.PerUserLimit,
+		StartAt:       voucherDTO.StartAt,
+		EndAt:         voucherDTO.EndAt,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	if err := u.voucherRepository.Create(voucher); err != nil {
+		return nil, err
+	}
+
+	return &voucher, nil
+}
+
+func (u voucherUsecase) findAllVouchers(renterId string) (*[]model.Voucher, error) {
+	vouchers, err := u.voucherRepository.FindAll(renterId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return vouchers, nil
+}
+
+func (u voucherUsecase) findVoucher(renterId string, voucherId string) (*model.Voucher, error) {
+	voucher, err := u.voucherRepository.FindById(voucherId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// a renter can only see its own vouchers, and admins only the platform ones
+	if voucher.RenterId != renterId {
+		return nil, pkg.ErrRecordNotFound
+	}
+
+	return voucher, nil
+}
+
+func (u voucherUsecase) updateVoucher(voucher model.Voucher, voucherDTO dto.VoucherDTO) error {
+	if err := validateVoucher(voucherDTO); err != nil {
+		return err
+	}
+
+	code := normalizeVoucherCode(voucherDTO.Code)
+
+	if code != voucher.Code {
+		_, err := u.voucherRepository.FindByCode(code)
+
+		if err == nil {
+			return pkg.ErrDataAlreadyExist
+		} else if !errors.Is(err, pkg.ErrRecordNotFound) {
+			return err
+		}
+	}
+
+	voucherUC := model.Voucher{
+		ID:            voucher.ID,
+		RenterId:      voucher.RenterId,
+		Code:          code,
+		DiscountType:  voucherDTO.DiscountType,
+		DiscountValue: voucherDTO.DiscountValue,
+		MaxDiscount:   voucherDTO.MaxDiscount,
+		MinSpend:      voucherDTO.MinSpend,
+		UsageLimit:    voucherDTO.UsageLimit,
+		PerUserLimit:  voucherDTO.PerUserLimit,
+		StartAt:       voucherDTO.StartAt,
+		EndAt:         voucherDTO.EndAt,
+		CreatedAt:     voucher.CreatedAt,
+		UpdatedAt:     time.Now(),
+	}
+
+	err := u.voucherRepository.Update(voucher.ID, voucherUC)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// applyVoucher checks the voucher against the bikes of an order and counts its discount.
+// A renter voucher only discounts the bikes of that renter, a platform voucher the whole order.
+// The voucher row stays locked until the transaction ends, so its limits hold under concurrent orders.
+func applyVoucher(repos repository.Repositories, code string, userId string, bikes []model.Bike, quotes []pricing.Quote, now time.Time) (*model.Voucher, float32, error) {
+	voucher, err := repos.Voucher.FindByCodeForUpdate(normalizeVoucherCode(code))
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return nil, 0, fmt.Errorf("%w: voucher %s not found", pkg.ErrVoucherNotApplicable, code)
+		}
+
+		return nil, 0, err
+	}
+
+	if now.Before(voucher.StartAt) {
+		return nil, 0, fmt.Errorf("%w: voucher %s is valid from %s", pkg.ErrVoucherNotApplicable, voucher.Code, voucher.StartAt.Format(time.RFC3339))
+	} else if !now.Before(voucher.EndAt) {
+		return nil, 0, fmt.Errorf("%w: voucher %s has expired", pkg.ErrVoucherNotApplicable, voucher.Code)
+	}
+
+	var eligible float32
+	for i := range bikes {
+		if voucher.RenterId == "" || bikes[i].RenterId == voucher.RenterId {
+			eligible += quotes[i].Total
+		}
+	}
+
+	if eligible == 0 {
+		return nil, 0, fmt.Errorf("%w: voucher %s does not apply to the chosen bikes", pkg.ErrVoucherNotApplicable, voucher.Code)
+	} else if eligible < voucher.MinSpend {
+		return nil, 0, fmt.Errorf("%w: voucher %s needs a minimum spend of %.0f", pkg.ErrVoucherNotApplicable, voucher.Code, voucher.MinSpend)
+	}
+
+	if voucher.UsageLimit > 0 {
+		used, err := repos.Voucher.CountUsages(voucher.ID, "")
+
+		if err != nil {
+			return nil, 0, err
+		} else if used >= int64(voucher.UsageLimit) {
+			return nil, 0, fmt.Errorf("%w: voucher %s has run out", pkg.ErrVoucherNotApplicable, voucher.Code)
+		}
+	}
+
+	if voucher.PerUserLimit > 0 {
+		used, err := repos.Voucher.CountUsages(voucher.ID, userId)
+
+		if err != nil {
+			return nil, 0, err
+		} else if used >= int64(voucher.PerUserLimit) {
+			return nil, 0, fmt.Errorf("%w: voucher %s was already used", pkg.ErrVoucherNotApplicable, voucher.Code)
+		}
+	}
+
+	return voucher, countDiscount(*voucher, eligible), nil
+}
+
+func countDiscount(voucher model.Voucher, eligible float32) float32 {
+	discount := voucher.DiscountValue
+
+	if voucher.DiscountType == model.VoucherDiscountPercentage {
+		discount = eligible * voucher.DiscountValue / 100
+
+		if voucher.MaxDiscount > 0 && discount > voucher.MaxDiscount {
+			discount = voucher.MaxDiscount
+		}
+	}
+
+	if discount > eligible {
+		discount = eligible
+	}
+
+	return float32(math.Round(float64(discount)))
+}
+
+func validateVoucher(voucherDTO dto.VoucherDTO) error {
+	if normalizeVoucherCode(voucherDTO.Code) == "" || voucherDTO.DiscountValue <= 0 || !voucherDTO.EndAt.After(voucherDTO.StartAt) {
+		return pkg.ErrInvalidVoucher
+	}
+
+	if voucherDTO.MaxDiscount < 0 || voucherDTO.MinSpend < 0 || voucherDTO.UsageLimit < 0 || voucherDTO.PerUserLimit < 0 {
+		return pkg.ErrInvalidVoucher
+	}
+
+	switch voucherDTO.DiscountType {
+	case model.VoucherDiscountPercentage:
+		if voucherDTO.DiscountValue > 100 {
+			return pkg.ErrInvalidVoucher
+		}
+	case model.VoucherDiscountFixed:
+	default:
+		return pkg.ErrInvalidVoucher
+	}
+
+	return nil
+}
+
+func normalizeVoucherCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func NewVoucherUsecase(voucherRepo repository.VoucherRepository, renterRepo repository.RenterRepository) VoucherUsecase {
+	return voucherUsecase{
+		voucherRepository: voucherRepo,
+		renterRepository:  renterRepo,
+	}
+}
@@ -0,0 +1,228 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/pricing"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var voucherUsecaseTest = NewVoucherUsecase(
+	&pkg.VoucherRepository,
+	&pkg.RenterRepository,
+)
+
+func TestVoucherUsecase_CreateVoucher(t *testing.T) {
+	userId := "4c1f2a3b-6d7e-4f80-9a1b-2c3d4e5f6a7b"
+
+	renter := &model.Renter{
+		ID:       "8e9f0a1b-2c3d-4e5f-8a7b-9c0d1e2f3a4b",
+		UserId:   userId,
+		RentName: "Twins' Brother Bike Rental",
+	}
+
+	pkg.RenterRepository.Mock.On("FindByIdUser", userId).Return(renter, nil)
+	pkg.VoucherRepository.Mock.On("FindByCode", "NEWYEAR").Return((*model.Voucher)(nil), pkg.ErrRecordNotFound)
+	pkg.VoucherRepository.Mock.On("FindByCode", "TAKEN").Return(&model.Voucher{ID: "VID-TAKEN", Code: "TAKEN"}, nil)
+	pkg.VoucherRepository.Mock.On("Create", mock.MatchedBy(func(voucher model.Voucher) bool {
+		return voucher.RenterId == renter.ID && voucher.Code == "NEWYEAR"
+	})).Return(nil)
+
+	startAt := time.Date(2022, 12, 25, 0, 0, 0, 0, time.UTC)
+	endAt := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name       string
+		voucherDTO dto.VoucherDTO
+		err        error
+	}{
+		{
+			name:       "valid voucher",
+			voucherDTO: dto.VoucherDTO{Code: " newyear ", DiscountType: "percentage", DiscountValue: 20, StartAt: startAt, EndAt: endAt},
+			err:        nil,
+		},
+		{
+			name:       "code already used",
+			voucherDTO: dto.VoucherDTO{Code: "taken", DiscountType: "fixed", DiscountValue: 10000, StartAt: startAt, EndAt: endAt},
+			err:        pkg.ErrDataAlreadyExist,
+		},
+		{
+			name:       "percentage above 100",
+			voucherDTO: dto.VoucherDTO{Code: "NEWYEAR", DiscountType: "percentage", DiscountValue: 120, StartAt: startAt, EndAt: endAt},
+			err:        pkg.ErrInvalidVoucher,
+		},
+		{
+			name:       "unknown discount type",
+			voucherDTO: dto.VoucherDTO{Code: "NEWYEAR", DiscountType: "free", DiscountValue: 10, StartAt: startAt, EndAt: endAt},
+			err:        pkg.ErrInvalidVoucher,
+		},
+		{
+			name:       "window ends before it starts",
+			voucherDTO: dto.VoucherDTO{Code: "NEWYEAR", DiscountType: "fixed", DiscountValue: 10000, StartAt: endAt, EndAt: startAt},
+			err:        pkg.ErrInvalidVoucher,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			voucher, err := voucherUsecaseTest.CreateVoucher(userId, tc.voucherDTO)
+
+			assert.Equal(t, tc.err, err)
+
+			if tc.err == nil {
+				assert.Equal(t, "NEWYEAR", voucher.Code)
+			}
+		})
+	}
+}
+
+func TestVoucherUsecase_FindByIdVoucher(t *testing.T) {
+	userId := "d0e1f2a3-b4c5-4d6e-8f70-8192a3b4c5d6"
+
+	renter := &model.Renter{
+		ID:     "e1f2a3b4-c5d6-4e7f-8091-a2b3c4d5e6f7",
+		UserId: userId,
+	}
+
+	pkg.RenterRepository.Mock.On("FindByIdUser", userId).Return(renter, nil)
+	pkg.VoucherRepository.Mock.On("FindById", "VID-OWN").Return(&model.Voucher{ID: "VID-OWN", RenterId: renter.ID, Code: "OWN"}, nil)
+	pkg.VoucherRepository.Mock.On("FindById", "VID-FOREIGN").Return(&model.Voucher{ID: "VID-FOREIGN", RenterId: "RID-FOREIGN", Code: "FOREIGN"}, nil)
+
+	voucher, err := voucherUsecaseTest.FindByIdVoucher(userId, "VID-OWN")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "OWN", voucher.Code)
+
+	// another renter's voucher looks the same as a missing one
+	voucher, err = voucherUsecaseTest.FindByIdVoucher(userId, "VID-FOREIGN")
+
+	assert.Nil(t, voucher)
+	assert.Equal(t, pkg.ErrRecordNotFound, err)
+}
+
+func TestVoucherUsecase_UpdateVoucher(t *testing.T) {
+	userId := "f2a3b4c5-d6e7-4f80-9102-b3c4d5e6f7a8"
+
+	renter := &model.Renter{
+		ID:     "a3b4c5d6-e7f8-4091-8213-c4d5e6f7a8b9",
+		UserId: userId,
+	}
+
+	pkg.RenterRepository.Mock.On("FindByIdUser", userId).Return(renter, nil)
+	pkg.VoucherRepository.Mock.On("FindById", "VID-UPDATE").Return(&model.Voucher{ID: "VID-UPDATE", RenterId: renter.ID, Code: "SUMMER"}, nil)
+	pkg.VoucherRepository.Mock.On("Update", "VID-UPDATE", mock.MatchedBy(func(voucher model.Voucher) bool {
+		return voucher.Code == "SUMMER" && voucher.DiscountValue == 15000
+	})).Return(nil)
+
+	err := voucherUsecaseTest.UpdateVoucher(userId, "VID-UPDATE", dto.VoucherDTO{
+		Code:          "summer",
+		DiscountType:  "fixed",
+		DiscountValue: 15000,
+		StartAt:       time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC),
+		EndAt:         time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC),
+	})
+
+	assert.Nil(t, err)
+}
+
+func TestVoucherUsecase_DeleteVoucher(t *testing.T) {
+	userId := "b4c5d6e7-f8a9-4b0c-9d1e-2f3a4b5c6d7e"
+
+	renter := &model.Renter{
+		ID:     "c5d6e7f8-a9b0-4c1d-8e2f-3a4b5c6d7e8f",
+		UserId: userId,
+	}
+
+	pkg.RenterRepository.Mock.On("FindByIdUser", userId).Return(renter, nil)
+	pkg.VoucherRepository.Mock.On("FindById", "VID-DELETE").Return(&model.Voucher{ID: "VID-DELETE", RenterId: renter.ID}, nil)
+	pkg.VoucherRepository.Mock.On("Delete", "VID-DELETE").Return(nil)
+
+	err := voucherUsecaseTest.DeleteVoucher(userId, "VID-DELETE")
+
+	assert.Nil(t, err)
+}
+
+func TestVoucherUsecase_CreatePlatformVoucher(t *testing.T) {
+	pkg.VoucherRepository.Mock.On("FindByCode", "PLATFORM10").Return((*model.Voucher)(nil), pkg.ErrRecordNotFound)
+	pkg.VoucherRepository.Mock.On("Create", mock.MatchedBy(func(voucher model.Voucher) bool {
+		return voucher.Code == "PLATFORM10"
+	})).Return(nil)
+
+	voucher, err := voucherUsecaseTest.CreatePlatformVoucher(dto.VoucherDTO{
+		Code:          "platform10",
+		DiscountType:  "percentage",
+		DiscountValue: 10,
+		StartAt:       time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndAt:         time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "PLATFORM10", voucher.Code)
+	assert.Empty(t, voucher.RenterId)
+}
+
+func TestVoucherUsecase_FindByIdPlatformVoucher(t *testing.T) {
+	pkg.VoucherRepository.Mock.On("FindById", "VID-PLATFORM").Return(&model.Voucher{ID: "VID-PLATFORM", Code: "PLATFORM"}, nil)
+	pkg.VoucherRepository.Mock.On("FindById", "VID-RENTER").Return(&model.Voucher{ID: "VID-RENTER", RenterId: "RID-RENTER", Code: "RENTER"}, nil)
+
+	voucher, err := voucherUsecaseTest.FindByIdPlatformVoucher("VID-PLATFORM")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "PLATFORM", voucher.Code)
+
+	// admins manage the platform vouchers only, a renter's voucher stays with the renter
+	voucher, err = voucherUsecaseTest.FindByIdPlatformVoucher("VID-RENTER")
+
+	assert.Nil(t, voucher)
+	assert.Equal(t, pkg.ErrRecordNotFound, err)
+}
+
+func TestApplyVoucher_PlatformVoucher(t *testing.T) {
+	now := time.Now()
+
+	bikes := []model.Bike{
+		{ID: "BID-PLATFORM-1", RenterId: "RID-PLATFORM-1"},
+		{ID: "BID-PLATFORM-2", RenterId: "RID-PLATFORM-2"},
+	}
+	quotes := []pricing.Quote{{Total: 60000}, {Total: 40000}}
+
+	testCases := []struct {
+		name     string
+		voucher  *model.Voucher
+		discount float32
+	}{
+		{
+			name: "platform voucher discounts the bikes of every renter",
+			voucher: &model.Voucher{
+				ID: "VID-APPLY-PLATFORM", Code: "APPLYPLATFORM", DiscountType: model.VoucherDiscountPercentage, DiscountValue: 10,
+				StartAt: now.Add(-time.Hour), EndAt: now.Add(time.Hour),
+			},
+			discount: 10000,
+		},
+		{
+			name: "renter voucher discounts the bikes of its renter only",
+			voucher: &model.Voucher{
+				ID: "VID-APPLY-RENTER", RenterId: "RID-PLATFORM-2", Code: "APPLYRENTER", DiscountType: model.VoucherDiscountPercentage, DiscountValue: 10,
+				StartAt: now.Add(-time.Hour), EndAt: now.Add(time.Hour),
+			},
+			discount: 4000,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pkg.VoucherRepository.Mock.On("FindByCodeForUpdate", tc.voucher.Code).Return(tc.voucher, nil)
+
+			voucher, discount, err := applyVoucher(pkg.UnitOfWork.Repositories, tc.voucher.Code, "UID-APPLY", bikes, quotes, now)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tc.voucher.ID, voucher.ID)
+			assert.Equal(t, tc.discount, discount)
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/payment"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/google/uuid"
+	"github.com/midtrans/midtrans-go"
+)
+
+type WalletUsecase interface {
+	FindWallet(userId string) (*model.Wallet, error)
+	FindAllWalletTransactions(userId string) (*[]model.WalletTransaction, error)
+	TopUpWallet(userId string, topUpDTO dto.WalletTopUpDTO) (map[string]interface{}, error)
+}
+
+// WalletPolicy sets how long a top up waits for its payment.
+type WalletPolicy struct {
+	// PaymentTTL is how long a top up may wait for its payment, the same as the payment of an order
+	PaymentTTL time.Duration
+}
+
+type walletUsecase struct {
+	unitOfWork       repository.UnitOfWork
+	paymentProvider  payment.PaymentProvider
+	userRepository   repository.UserRepository
+	walletRepository repository.WalletRepository
+	policy           WalletPolicy
+}
+
+// FindWallet finds the wallet of the user, a user that never topped up has an empty one.
+func (u walletUsecase) FindWallet(userId string) (*model.Wallet, error) {
+	wallet, err := u.walletRepository.FindByIdUser(userId)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return &model.Wallet{UserId: userId}, nil
+		}
+
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
+func (u walletUsecase) FindAllWalletTransactions(userId string) (*[]model.WalletTransaction, error) {
+	wallet, err := u.walletRepository.FindByIdUser(userId)
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrRecordNotFound) {
+			return &[]model.WalletTransaction{}, nil
+		}
+
+		return nil, err
+	}
+
+	return u.walletRepository.FindTransactions(wallet.ID)
+}
+
+// TopUpWallet creates a top up payment, the balance is only credited once the payment gateway settles it.
+func (u walletUsecase) TopUpWallet(userId string, topUpDTO dto.WalletTopUpDTO) (map[string]interface{}, error) {
+	// the payment gateway only takes whole rupiah
+	if topUpDTO.Amount <= 0 || topUpDTO.Amount != float32(math.Floor(float64(topUpDTO.Amount))) {
+		return nil, fmt.Errorf("%w: amount must be a positive whole amount", pkg.ErrInvalidTopUp)
+	}
+
+	customer, err := u.userRepository.FindById(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		wallet  *model.Wallet
+		payment model.Payment
+	)
+
+	// the wallet is only locked to create the payment, the payment gateway is asked for its link after commit
+	err = u.unitOfWork.WithTx(func(repos repository.Repositories) error {
+		wallet, err = lockWallet(repos, customer.ID)
+
+		if err != nil {
+			return err
+		}
+
+		payment = model.Payment{
+			ID:            uuid.NewString(),
+			WalletId:      wallet.ID,
+			Kind:          model.PaymentKindTopUp,
+			Amount:        topUpDTO.Amount,
+			PaymentStatus: model.PaymentStatusPending,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		}
+
+		return repos.Payment.Create(payment)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	// a top up has no order, the payment id is its order id in the payment gateway
+	snapReq := dto.PaymentGateway{
+		Email:    customer.Email,
+		Phone:    customer.Phone,
+		OrderId:  payment.ID,
+		GrossAmt: int64(payment.Amount),
+		Items: []midtrans.ItemDetails{
+			{
+				ID:    wallet.ID,
+				Name:  "Wallet top up",
+				Price: int64(payment.Amount),
+				Qty:   1,
+			},
+		},
+		ExpiryStartAt: payment.CreatedAt,
+		ExpiryMinutes: int64(u.policy.PaymentTTL / time.Minute),
+	}
+
+	snapUrl, err := requestPaymentLink(u.paymentProvider, snapReq)
+
+	if err != nil {
+		// a top up without payment link can never be paid
+		if abandonErr := u.abandonTopUp(payment.ID); abandonErr != nil {
+			return nil, fmt.Errorf("%w, and the top up was not canceled: %v", err, abandonErr)
+		}
+
+		return nil, err
+	}
+
+	payment.PaymentLink = snapUrl
+	payment.UpdatedAt = time.Now()
+
+	err = u.unitOfWork.WithTx(func(repos repository.Repositories) error {
+		return repos.Payment.Update(payment.ID, model.Payment{PaymentLink: payment.PaymentLink, UpdatedAt: payment.UpdatedAt})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"wallet_id":      wallet.ID,
+		"payment_id":     payment.ID,
+		"amount":         payment.Amount,
+		"payment_status": payment.PaymentStatus,
+		"payment_link":   payment.PaymentLink,
+	}, nil
+}
+
+// abandonTopUp cancels a top up the payment gateway made no payment link for, unless it got paid meanwhile
+// through a link the gateway made before it failed to answer.
+func (u walletUsecase) abandonTopUp(paymentId string) error {
+	return u.unitOfWork.WithTx(func(repos repository.Repositories) error {
+		payment, err := repos.Payment.FindByIdForUpdate(paymentId)
+
+		if err != nil {
+			return err
+		}
+
+		if payment.PaymentStatus != model.PaymentStatusPending {
+			return nil
+		}
+
+		payment.PaymentStatus = model.PaymentStatusCancel
+		payment.UpdatedAt = time.Now()
+
+		return repos.Payment.Update(payment.ID, *payment)
+	})
+}
+
+// lockWallet locks the wallet of the user until the transaction ends, a user without a wallet gets an empty one.
+func lockWallet(repos repository.Repositories, userId string) (*model.Wallet, error) {
+	wallet, err := repos.Wallet.FindByIdUserForUpdate(userId)
+
+	if err == nil {
+		return wallet, nil
+	}
+
+	if !errors.Is(err, pkg.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	wallet = &model.Wallet{
+		ID:        uuid.NewString(),
+		UserId:    userId,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if err := repos.Wallet.Create(*wallet); err != nil {
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
+// applyWalletTransaction changes the balance of a locked wallet by the amount of the transaction
+// and records it, a debit never takes the balance below zero.
+func applyWalletTransaction(repos repository.Repositories, wallet *model.Wallet, transaction model.WalletTransaction) error {
+	balance := wallet.Balance + transaction.Amount
+
+	if balance < 0 {
+		return fmt.Errorf("%w: %.0f available", pkg.ErrInsufficientBalance, wallet.Balance)
+	}
+
+	wallet.Balance = balance
+	wallet.UpdatedAt = time.Now()
+
+	if err := repos.Wallet.UpdateBalance(wallet.ID, model.Wallet{Balance: wallet.Balance, UpdatedAt: wallet.UpdatedAt}); err != nil {
+		return err
+	}
+
+	transaction.ID = uuid.NewString()
+	transaction.WalletId = wallet.ID
+	transaction.BalanceAfter = wallet.Balance
+	transaction.CreatedAt = time.Now()
+
+	return repos.Wallet.CreateTransaction(transaction)
+}
+
+// settleTopUp credits a settled top up to the wallet it was made for.
+func settleTopUp(repos repository.Repositories, payment model.Payment) error {
+	wallet, err := repos.Wallet.FindByIdForUpdate(payment.WalletId)
+
+	if err != nil {
+		return err
+	}
+
+	err = applyWalletTransaction(repos, wallet, model.WalletTransaction{
+		Type:        model.WalletTransactionTopUp,
+		Amount:      payment.Amount,
+		PaymentId:   payment.ID,
+		Description: fmt.Sprintf("top up %s", payment.ID),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	// the money collected by the payment gateway is now held for the customer
+	j := newJournal(fmt.Sprintf("top up %s of wallet %s", payment.ID, wallet.ID))
+	j.paymentId = payment.ID
+	j.debit(model.LedgerAccountGatewayClearing, "", payment.Amount)
+	j.credit(model.LedgerAccountCustomerWallet, "", payment.Amount)
+
+	return j.post(repos.Ledger)
+}
+
+// paymentAccount is the account the money of a payment was taken from, the payment gateway or the wallet of the customer.
+func paymentAccount(payment model.Payment) string {
+	if payment.PaymentType == model.PaymentTypeWallet {
+		return model.LedgerAccountCustomerWallet
+	}
+
+	return model.LedgerAccountGatewayClearing
+}
+
+func NewWalletUsecase(
+	unitOfWork repository.UnitOfWork,
+	paymentProvider payment.PaymentProvider,
+	userRepo repository.UserRepository,
+	walletRepo repository.WalletRepository,
+	policy WalletPolicy,
+) WalletUsecase {
+	return walletUsecase{
+		unitOfWork:       unitOfWork,
+		paymentProvider:  paymentProvider,
+		userRepository:   userRepo,
+		walletRepository: walletRepo,
+		policy:           policy,
+	}
+}
@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/model"
+	"github.com/arvinpaundra/go-rent-bike/internal/payment"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var walletUsecaseTest = NewWalletUsecase(
+	&pkg.UnitOfWork,
+	fakePaymentProvider,
+	&pkg.UserRepository,
+	&pkg.WalletRepository,
+	WalletPolicy{PaymentTTL: time.Hour},
+)
+
+func TestWalletUsecase_TopUpWallet(t *testing.T) {
+	userId := "2b7e4a9c-6d1f-4b3a-8e7c-5a9d2f4b6e8a"
+
+	customer := &model.User{
+		ID:    userId,
+		Role:  "customer",
+		Email: "top-up@mail.com",
+	}
+
+	pkg.UserRepository.Mock.On("FindById", userId).Return(customer, nil)
+
+	// a customer without a wallet gets one on the first top up
+	pkg.WalletRepository.Mock.On("FindByIdUserForUpdate", userId).Return((*model.Wallet)(nil), pkg.ErrRecordNotFound)
+	pkg.WalletRepository.Mock.On("Create", mock.MatchedBy(func(wallet model.Wallet) bool {
+		return wallet.UserId == userId && wallet.Balance == 0
+	})).Return(nil)
+
+	var paymentId string
+	pkg.PaymentRepository.Mock.On("Create", mock.MatchedBy(func(payment model.Payment) bool {
+		if payment.Kind != model.PaymentKindTopUp || payment.WalletId == "" || payment.OrderId != "" || payment.Amount != 50000 {
+			return false
+		}
+		paymentId = payment.ID
+		return true
+	})).Return(nil)
+	pkg.PaymentRepository.Mock.On("Update", mock.MatchedBy(func(id string) bool {
+		return id != "" && id == paymentId
+	}), mock.MatchedBy(func(payment model.Payment) bool {
+		return payment.PaymentLink == "http://localhost:8080/api/v1/fake-payments/"+paymentId
+	})).Return(nil)
+
+	data, err := walletUsecaseTest.TopUpWallet(userId, dto.WalletTopUpDTO{Amount: 50000})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, data)
+
+	// the payment id is the order id of the top up in the payment gateway
+	assert.Equal(t, paymentId, data["payment_id"])
+	assert.Equal(t, "http://localhost:8080/api/v1/fake-payments/"+paymentId, data["payment_link"])
+	assert.Equal(t, model.PaymentStatusPending, data["payment_status"])
+
+	transaction, err := fakePaymentProvider.CheckTransaction(paymentId)
+	assert.Nil(t, err)
+	assert.Equal(t, "50000.00", transaction.GrossAmount)
+}
+
+func TestWalletUsecase_TopUpWalletGatewayTimeout(t *testing.T) {
+	userId := "d07b1a63-14aa-43ff-8735-852d90e326f7"
+	walletId := "8ef43c64-cb31-4e38-8d20-61661e3cc643"
+
+	walletUsecase := NewWalletUsecase(&pkg.UnitOfWork, &paymentGateway, &pkg.UserRepository, &pkg.WalletRepository, WalletPolicy{PaymentTTL: time.Hour})
+
+	pkg.UserRepository.Mock.On("FindById", userId).Return(&model.User{ID: userId, Email: "top-up-timeout@mail.com"}, nil)
+	pkg.WalletRepository.Mock.On("FindByIdUserForUpdate", userId).Return(&model.Wallet{ID: walletId, UserId: userId}, nil)
+
+	pendingPayment := &model.Payment{PaymentStatus: model.PaymentStatusPending}
+	pkg.PaymentRepository.Mock.On("Create", mock.MatchedBy(func(payment model.Payment) bool {
+		if payment.WalletId != walletId {
+			return false
+		}
+		pendingPayment.ID = payment.ID
+		return true
+	})).Return(nil)
+
+	// the link expires like the one of an order, and is asked for once the wallet is no longer locked
+	paymentGateway.Mock.On("CreateUrlTransactionWithGateway", mock.MatchedBy(func(req dto.PaymentGateway) bool {
+		return req.Email == "top-up-timeout@mail.com" && req.ExpiryMinutes == 60
+	})).Return("", &payment.GatewayError{Kind: pkg.ErrGatewayTimeout, StatusCode: 504, Message: "timeout"})
+
+	isPayment := mock.MatchedBy(func(id string) bool { return id != "" && id == pendingPayment.ID })
+
+	pkg.PaymentRepository.Mock.On("FindByIdForUpdate", isPayment).Return(pendingPayment, nil)
+	pkg.PaymentRepository.Mock.On("Update", isPayment, mock.MatchedBy(func(payment model.Payment) bool {
+		return payment.PaymentStatus == model.PaymentStatusCancel
+	})).Return(nil)
+
+	data, err := walletUsecase.TopUpWallet(userId, dto.WalletTopUpDTO{Amount: 75000})
+
+	assert.Nil(t, data)
+	assert.ErrorIs(t, err, pkg.ErrGatewayTimeout)
+	assert.Equal(t, model.PaymentStatusCancel, pendingPayment.PaymentStatus)
+}
+
+func TestWalletUsecase_TopUpWalletInvalidAmount(t *testing.T) {
+	testCases := []struct {
+		Name   string
+		Amount float32
+	}{
+		{
+			Name:   "zero amount",
+			Amount: 0,
+		},
+		{
+			Name:   "negative amount",
+			Amount: -10000,
+		},
+		{
+			Name:   "fraction of rupiah",
+			Amount: 10000.5,
+		},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.Name, func(t *testing.T) {
+			data, err := walletUsecaseTest.TopUpWallet("3c8f5b1d-7e2a-4c4b-9f8d-6b1e3a5c7f9b", dto.WalletTopUpDTO{Amount: v.Amount})
+
+			assert.Nil(t, data)
+			assert.True(t, errors.Is(err, pkg.ErrInvalidTopUp))
+		})
+	}
+}
+
+func TestWalletUsecase_FindWallet(t *testing.T) {
+	userId := "4d9a6c2e-8f3b-4d5c-8a9e-7c2f4b6d8a1c"
+
+	wallet := &model.Wallet{
+		ID:      "5e1b7d3f-9a4c-4e6d-9b1f-8d3a5c7e9b2d",
+		UserId:  userId,
+		Balance: 25000,
+	}
+
+	pkg.WalletRepository.Mock.On("FindByIdUser", userId).Return(wallet, nil)
+	pkg.WalletRepository.Mock.On("FindTransactions", wallet.ID).Return(&[]model.WalletTransaction{
+		{ID: "6f2c8e4a-1b5d-4f7e-8c2a-9e4b6d8f1c3e", WalletId: wallet.ID, Type: model.WalletTransactionTopUp, Amount: 25000, BalanceAfter: 25000},
+	}, nil)
+
+	result, err := walletUsecaseTest.FindWallet(userId)
+
+	assert.Nil(t, err)
+	assert.Equal(t, float32(25000), result.Balance)
+
+	transactions, err := walletUsecaseTest.FindAllWalletTransactions(userId)
+
+	assert.Nil(t, err)
+	assert.Len(t, *transactions, 1)
+}
+
+func TestWalletUsecase_FindWalletNeverToppedUp(t *testing.T) {
+	userId := "7a3d9f5b-2c6e-4a8f-9d3b-1f5c7e9a2d4f"
+
+	pkg.WalletRepository.Mock.On("FindByIdUser", userId).Return((*model.Wallet)(nil), pkg.ErrRecordNotFound)
+
+	result, err := walletUsecaseTest.FindWallet(userId)
+
+	assert.Nil(t, err)
+	assert.Equal(t, userId, result.UserId)
+	assert.Equal(t, float32(0), result.Balance)
+
+	transactions, err := walletUsecaseTest.FindAllWalletTransactions(userId)
+
+	assert.Nil(t, err)
+	assert.Empty(t, *transactions)
+}
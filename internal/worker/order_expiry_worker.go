@@ -0,0 +1,53 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/usecase"
+)
+
+type OrderExpiryWorker struct {
+	orderUsecase usecase.OrderUsecase
+	interval     time.Duration
+}
+
+// Start expires the unpaid orders every interval until ctx is canceled.
+func (w OrderExpiryWorker) Start(ctx context.Context) {
+	if w.interval <= 0 {
+		log.Printf("order expiry worker: not started, interval must be positive")
+		return
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			w.run(now)
+		}
+	}
+}
+
+func (w OrderExpiryWorker) run(now time.Time) {
+	expired, err := w.orderUsecase.ExpireUnpaidOrders(now)
+
+	if err != nil {
+		log.Printf("order expiry worker: %v", err)
+	}
+
+	if expired > 0 {
+		log.Printf("order expiry worker: expired %d unpaid orders", expired)
+	}
+}
+
+func NewOrderExpiryWorker(orderUsecase usecase.OrderUsecase, interval time.Duration) OrderExpiryWorker {
+	return OrderExpiryWorker{
+		orderUsecase: orderUsecase,
+		interval:     interval,
+	}
+}
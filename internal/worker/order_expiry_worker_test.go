@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	usecasemock "github.com/arvinpaundra/go-rent-bike/internal/usecase/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestOrderExpiryWorker_Start(t *testing.T) {
+	orderUsecase := &usecasemock.OrderUsecaseMock{}
+	orderUsecase.Mock.On("ExpireUnpaidOrders", mock.Anything).Return(1, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+
+	go func() {
+		NewOrderExpiryWorker(orderUsecase, 10*time.Millisecond).Start(ctx)
+		close(done)
+	}()
+
+	time.Sleep(55 * time.Millisecond)
+	cancel()
+	<-done
+
+	orderUsecase.Mock.AssertCalled(t, "ExpireUnpaidOrders", mock.Anything)
+}
+
+func TestOrderExpiryWorker_StartWithoutInterval(t *testing.T) {
+	orderUsecase := &usecasemock.OrderUsecaseMock{}
+
+	NewOrderExpiryWorker(orderUsecase, 0).Start(context.Background())
+
+	assert.Empty(t, orderUsecase.Mock.Calls)
+}
@@ -0,0 +1,136 @@
+package worker
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/usecase"
+)
+
+type PaymentReconciliationWorker struct {
+	paymentGatewayUsecase usecase.PaymentGatewayUsecase
+	runAt                 string
+	reportDir             string
+}
+
+// Start reconciles the payments once a day at runAt, written as HH:MM, until ctx is canceled.
+func (w PaymentReconciliationWorker) Start(ctx context.Context) {
+	runAt, err := time.Parse("15:04", w.runAt)
+
+	if err != nil {
+		log.Printf("payment reconciliation worker: not started, run time must be written as HH:MM")
+		return
+	}
+
+	for {
+		timer := time.NewTimer(time.Until(nextDailyRun(time.Now(), runAt)))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case now := <-timer.C:
+			w.run(now)
+		}
+	}
+}
+
+func (w PaymentReconciliationWorker) run(now time.Time) {
+	report, err := w.paymentGatewayUsecase.ReconcilePayments(now)
+
+	if err != nil {
+		log.Printf("payment reconciliation worker: %v", err)
+		return
+	}
+
+	files, err := writeReconciliationReport(w.reportDir, *report)
+
+	if err != nil {
+		log.Printf("payment reconciliation worker: %v", err)
+		return
+	}
+
+	log.Printf("payment reconciliation worker: checked %d payments, fixed %d, %d discrepancies, report in %s",
+		report.Checked, report.Fixed, len(report.Discrepancies), strings.Join(files, ", "))
+}
+
+// nextDailyRun is the first time of day runAt after now.
+func nextDailyRun(now time.Time, runAt time.Time) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), runAt.Hour(), runAt.Minute(), 0, 0, now.Location())
+
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+
+	return next
+}
+
+// writeReconciliationReport writes the report for finance as json and csv, and returns the paths of both files.
+func writeReconciliationReport(dir string, report dto.ReconciliationReport) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	name := filepath.Join(dir, fmt.Sprintf("payment-reconciliation-%s", report.GeneratedAt.Format("20060102-150405")))
+
+	reportJSON, err := json.MarshalIndent(report, "", "  ")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.WriteFile(name+".json", reportJSON, 0644); err != nil {
+		return nil, err
+	}
+
+	rows := [][]string{
+		{"payment_id", "order_id", "gateway_order_id", "payment_kind", "local_status", "gateway_status", "status_after", "local_amount", "gateway_amount", "issues", "error"},
+	}
+
+	for _, discrepancy := range report.Discrepancies {
+		rows = append(rows, []string{
+			discrepancy.PaymentId,
+			discrepancy.OrderId,
+			discrepancy.GatewayOrderId,
+			discrepancy.PaymentKind,
+			discrepancy.LocalStatus,
+			discrepancy.GatewayStatus,
+			discrepancy.StatusAfter,
+			fmt.Sprintf("%.2f", discrepancy.LocalAmount),
+			discrepancy.GatewayAmount,
+			strings.Join(discrepancy.Issues, ";"),
+			discrepancy.Error,
+		})
+	}
+
+	file, err := os.Create(name + ".csv")
+
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, err
+	}
+
+	return []string{name + ".json", name + ".csv"}, nil
+}
+
+func NewPaymentReconciliationWorker(paymentGatewayUsecase usecase.PaymentGatewayUsecase, runAt string, reportDir string) PaymentReconciliationWorker {
+	return PaymentReconciliationWorker{
+		paymentGatewayUsecase: paymentGatewayUsecase,
+		runAt:                 runAt,
+		reportDir:             reportDir,
+	}
+}
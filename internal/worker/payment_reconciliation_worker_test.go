@@ -0,0 +1,103 @@
+package worker
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	usecasemock "github.com/arvinpaundra/go-rent-bike/internal/usecase/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextDailyRun(t *testing.T) {
+	runAt, _ := time.Parse("15:04", "02:00")
+
+	testCases := []struct {
+		Name     string
+		Now      time.Time
+		Expected time.Time
+	}{
+		{
+			Name:     "later today",
+			Now:      time.Date(2022, 11, 21, 1, 30, 0, 0, time.UTC),
+			Expected: time.Date(2022, 11, 21, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			Name:     "already ran today",
+			Now:      time.Date(2022, 11, 21, 2, 0, 0, 0, time.UTC),
+			Expected: time.Date(2022, 11, 22, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			Name:     "end of the month",
+			Now:      time.Date(2022, 11, 30, 23, 0, 0, 0, time.UTC),
+			Expected: time.Date(2022, 12, 1, 2, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.Equal(t, tc.Expected, nextDailyRun(tc.Now, runAt))
+		})
+	}
+}
+
+func TestPaymentReconciliationWorker_Run(t *testing.T) {
+	now := time.Date(2022, 11, 21, 2, 0, 0, 0, time.UTC)
+	dir := filepath.Join(t.TempDir(), "reports")
+
+	report := &dto.ReconciliationReport{
+		GeneratedAt: now,
+		Checked:     2,
+		Fixed:       1,
+		Discrepancies: []dto.ReconciliationDiscrepancy{
+			{
+				PaymentId:      "PID-1",
+				OrderId:        "OID-1",
+				GatewayOrderId: "OID-1",
+				PaymentKind:    "rent",
+				LocalStatus:    "pending",
+				GatewayStatus:  "settlement",
+				StatusAfter:    "settlement",
+				LocalAmount:    75000,
+				GatewayAmount:  "50000.00",
+				Issues:         []string{dto.ReconciliationIssueAmountMismatch, dto.ReconciliationIssueStatusFixed},
+			},
+		},
+	}
+
+	paymentGatewayUsecase := &usecasemock.PaymentGatewayMock{}
+	paymentGatewayUsecase.Mock.On("ReconcilePayments", now).Return(report, nil)
+
+	NewPaymentReconciliationWorker(paymentGatewayUsecase, "02:00", dir).run(now)
+
+	reportJSON, err := os.ReadFile(filepath.Join(dir, "payment-reconciliation-20221121-020000.json"))
+	assert.Nil(t, err)
+
+	result := dto.ReconciliationReport{}
+	assert.Nil(t, json.Unmarshal(reportJSON, &result))
+	assert.Equal(t, *report, result)
+
+	file, err := os.Open(filepath.Join(dir, "payment-reconciliation-20221121-020000.csv"))
+	assert.Nil(t, err)
+	defer file.Close()
+
+	rows, err := csv.NewReader(file).ReadAll()
+	assert.Nil(t, err)
+
+	assert.Len(t, rows, 2)
+	assert.Equal(t, "payment_id", rows[0][0])
+	assert.Equal(t, []string{"PID-1", "OID-1", "OID-1", "rent", "pending", "settlement", "settlement", "75000.00", "50000.00", "amount_mismatch;status_fixed", ""}, rows[1])
+}
+
+func TestPaymentReconciliationWorker_StartWithoutRunTime(t *testing.T) {
+	paymentGatewayUsecase := &usecasemock.PaymentGatewayMock{}
+
+	NewPaymentReconciliationWorker(paymentGatewayUsecase, "", t.TempDir()).Start(context.Background())
+
+	assert.Empty(t, paymentGatewayUsecase.Mock.Calls)
+}
@@ -1,9 +1,19 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"log"
+	"os"
+
 	"github.com/arvinpaundra/go-rent-bike/configs"
 	"github.com/arvinpaundra/go-rent-bike/database"
+	"github.com/arvinpaundra/go-rent-bike/internal/dto"
+	"github.com/arvinpaundra/go-rent-bike/internal/mailer"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository/gormdb"
 	"github.com/arvinpaundra/go-rent-bike/internal/route"
+	"github.com/arvinpaundra/go-rent-bike/internal/usecase"
+	"github.com/arvinpaundra/go-rent-bike/pkg"
 	"github.com/labstack/echo/v4"
 )
 
@@ -11,9 +21,65 @@ func main() {
 	configs.InitConfig()
 	database.InitMysqlDatabase()
 
+	// admins cannot register through the api, the first one is created with
+	// go run . create-admin -email admin@mail.com -password secret
+	if len(os.Args) > 1 && os.Args[1] == "create-admin" {
+		createAdmin(os.Args[2:])
+		return
+	}
+
 	e := echo.New()
 
+	ipExtractor, err := route.NewIPExtractor(configs.Cfg.TrustedProxies)
+
+	if err != nil {
+		log.Fatalf("invalid TRUSTED_PROXIES: %s", err)
+	}
+
+	e.IPExtractor = ipExtractor
+
 	route.New(database.DB, e)
 
 	e.Logger.Fatal(e.Start(configs.Cfg.AppPort))
 }
+
+func createAdmin(args []string) {
+	flags := flag.NewFlagSet("create-admin", flag.ExitOnError)
+	fullname := flags.String("fullname", "Administrator", "full name of the admin")
+	email := flags.String("email", "", "email the admin logs in with")
+	password := flags.String("password", os.Getenv("ADMIN_PASSWORD"), "password of the admin, defaults to $ADMIN_PASSWORD")
+	phone := flags.String("phone", "", "phone number of the admin")
+
+	_ = flags.Parse(args)
+
+	if *email == "" || *password == "" {
+		log.Fatal("create-admin: email and password are required")
+	}
+
+	userUsecase := usecase.NewUserUsecase(
+		gormdb.NewUserRepositoryGorm(database.DB),
+		gormdb.NewHistoryRepository(database.DB),
+		gormdb.NewOrderRepository(database.DB),
+		gormdb.NewUnitOfWork(database.DB),
+		// admins are created with a verified email, no email is sent
+		mailer.NewMemoryMailer(),
+		usecase.AccountPolicy{},
+	)
+
+	err := userUsecase.CreateAdmin(dto.UserDTO{
+		Fullname: *fullname,
+		Phone:    *phone,
+		Email:    *email,
+		Password: *password,
+	})
+
+	if err != nil {
+		if errors.Is(err, pkg.ErrDataAlreadyExist) {
+			log.Fatalf("create-admin: %s is already registered", *email)
+		}
+
+		log.Fatalf("create-admin: %s", err)
+	}
+
+	log.Printf("create-admin: admin %s created", *email)
+}
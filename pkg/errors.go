@@ -3,7 +3,51 @@ package pkg
 import "errors"
 
 var (
-	ErrRecordNotFound      = errors.New("record not found")
-	ErrDataAlreadyExist    = errors.New("data already exist")
-	ErrStatusInternalError = errors.New("internal server error")
+	ErrRecordNotFound            = errors.New("record not found")
+	ErrDataAlreadyExist          = errors.New("data already exist")
+	ErrStatusInternalError       = errors.New("internal server error")
+	ErrBikeNotAvailable          = errors.New("bike not available")
+	ErrBookingConflict           = errors.New("bike already booked")
+	ErrInvalidRentWindow         = errors.New("start time must not be in the past and end time must be after it")
+	ErrNoBikeChosen              = errors.New("choose at least one bike")
+	ErrPaymentLinkNotCreated     = errors.New("failed to create payment link")
+	ErrInvalidStatusTransition   = errors.New("invalid order status transition")
+	ErrInvalidCancellationPolicy = errors.New("invalid cancellation policy")
+	ErrPaymentGateway            = errors.New("payment gateway error")
+	ErrGatewayTimeout            = errors.New("payment gateway timed out")
+	ErrGatewayUnavailable        = errors.New("payment gateway unavailable")
+	ErrGatewayUnreachable        = errors.New("payment gateway could not be reached")
+	ErrGatewayUnauthorized       = errors.New("payment gateway rejected the server key")
+	ErrGatewayInvalidRequest     = errors.New("payment gateway rejected the request")
+	ErrGatewayDuplicateOrder     = errors.New("order id already used in the payment gateway")
+	ErrOrderNotExtendable        = errors.New("only paid or picked up orders can be extended")
+	ErrExtensionPending          = errors.New("order already has an unpaid extension")
+	ErrInvalidPricing            = errors.New("invalid pricing")
+	ErrInvalidVoucher            = errors.New("invalid voucher")
+	ErrVoucherNotApplicable      = errors.New("voucher cannot be applied")
+	ErrInvalidNotification       = errors.New("invalid notification payload")
+	ErrInvalidSignature          = errors.New("invalid signature key")
+	ErrForbidden                 = errors.New("forbidden")
+	ErrInvalidRefund             = errors.New("invalid refund")
+	ErrPaymentNotRefundable      = errors.New("only settled payments can be refunded")
+	ErrRefundExceedsCaptured     = errors.New("refund exceeds the refundable amount")
+	ErrNoPayableBalance          = errors.New("no renter balance to pay out")
+	ErrInvalidPayout             = errors.New("invalid payout")
+	ErrPayoutNotPending          = errors.New("payout already settled")
+	ErrInsufficientBalance       = errors.New("insufficient wallet balance")
+	ErrInvalidTopUp              = errors.New("invalid top up")
+	ErrInvalidDeposit            = errors.New("invalid deposit")
+	ErrDepositNotSettleable      = errors.New("deposit cannot be settled")
+	ErrPaymentNotSettled         = errors.New("payment is not settled yet")
+	ErrInvalidRefreshToken       = errors.New("invalid refresh token")
+	ErrRenterSuspended           = errors.New("renter is suspended")
+	ErrInvalidReportResolution   = errors.New("invalid report resolution")
+	ErrReportAlreadyResolved     = errors.New("report already resolved")
+	ErrInvalidOrderOverride      = errors.New("invalid order override")
+	ErrInvalidUserToken          = errors.New("invalid or expired token")
+	ErrEmailNotVerified          = errors.New("email is not verified")
+	ErrEmailAlreadyVerified      = errors.New("email already verified")
+	ErrMailNotSent               = errors.New("email could not be sent")
+	ErrTooManyLoginAttempts      = errors.New("too many failed login attempts")
+	ErrAccountLocked             = errors.New("account temporarily locked")
 )
@@ -2,6 +2,7 @@ package pkg
 
 import (
 	"database/sql/driver"
+	"github.com/arvinpaundra/go-rent-bike/internal/repository"
 	repomock "github.com/arvinpaundra/go-rent-bike/internal/repository/gormdb/mock"
 	"github.com/stretchr/testify/mock"
 	"time"
@@ -18,14 +19,50 @@ func (a Anytime) Match(v driver.Value) bool {
 
 // usecase tests
 var (
-	UserRepository        = repomock.UserRepositoryMock{Mock: mock.Mock{}}
-	ReportRepository      = repomock.ReportRepositoryMock{Mock: mock.Mock{}}
-	HistoryRepository     = repomock.HistoryRepositoryMock{Mock: mock.Mock{}}
-	OrderRepository       = repomock.OrderRepositoryMock{Mock: mock.Mock{}}
-	OrderDetailRepository = repomock.OrderDetailRepositoryMock{Mock: mock.Mock{}}
-	PaymentRepository     = repomock.PaymentRepositoryMock{Mock: mock.Mock{}}
-	RenterRepository      = repomock.RenterRepositoryMock{Mock: mock.Mock{}}
-	CategoryRepository    = repomock.CategoryRepositoryMock{Mock: mock.Mock{}}
-	ReviewRepository      = repomock.ReviewRepositoryMock{Mock: mock.Mock{}}
-	BikeRepository        = repomock.BikeRepositoryMock{Mock: mock.Mock{}}
+	UserRepository                = repomock.UserRepositoryMock{Mock: mock.Mock{}}
+	ReportRepository              = repomock.ReportRepositoryMock{Mock: mock.Mock{}}
+	HistoryRepository             = repomock.HistoryRepositoryMock{Mock: mock.Mock{}}
+	OrderRepository               = repomock.OrderRepositoryMock{Mock: mock.Mock{}}
+	OrderDetailRepository         = repomock.OrderDetailRepositoryMock{Mock: mock.Mock{}}
+	PaymentRepository             = repomock.PaymentRepositoryMock{Mock: mock.Mock{}}
+	RenterRepository              = repomock.RenterRepositoryMock{Mock: mock.Mock{}}
+	CategoryRepository            = repomock.CategoryRepositoryMock{Mock: mock.Mock{}}
+	ReviewRepository              = repomock.ReviewRepositoryMock{Mock: mock.Mock{}}
+	BikeRepository                = repomock.BikeRepositoryMock{Mock: mock.Mock{}}
+	OrderStatusHistoryRepository  = repomock.OrderStatusHistoryRepositoryMock{Mock: mock.Mock{}}
+	PricingRuleRepository         = repomock.PricingRuleRepositoryMock{Mock: mock.Mock{}}
+	VoucherRepository             = repomock.VoucherRepositoryMock{Mock: mock.Mock{}}
+	PaymentNotificationRepository = repomock.PaymentNotificationRepositoryMock{Mock: mock.Mock{}}
+	RefundRepository              = repomock.RefundRepositoryMock{Mock: mock.Mock{}}
+	LedgerRepository              = repomock.LedgerRepositoryMock{Mock: mock.Mock{}}
+	PayoutRepository              = repomock.PayoutRepositoryMock{Mock: mock.Mock{}}
+	WalletRepository              = repomock.WalletRepositoryMock{Mock: mock.Mock{}}
+	InvoiceRepository             = repomock.InvoiceRepositoryMock{Mock: mock.Mock{}}
+	SessionRepository             = repomock.SessionRepositoryMock{Mock: mock.Mock{}}
+	UserTokenRepository           = repomock.UserTokenRepositoryMock{Mock: mock.Mock{}}
+	LoginAttemptRepository        = repomock.LoginAttemptRepositoryMock{Mock: mock.Mock{}}
+	UnitOfWork                    = repomock.UnitOfWorkMock{
+		Mock: mock.Mock{},
+		Repositories: repository.Repositories{
+			User:                &UserRepository,
+			Renter:              &RenterRepository,
+			Bike:                &BikeRepository,
+			Order:               &OrderRepository,
+			OrderDetail:         &OrderDetailRepository,
+			Payment:             &PaymentRepository,
+			History:             &HistoryRepository,
+			OrderStatusHistory:  &OrderStatusHistoryRepository,
+			PricingRule:         &PricingRuleRepository,
+			Voucher:             &VoucherRepository,
+			PaymentNotification: &PaymentNotificationRepository,
+			Refund:              &RefundRepository,
+			Ledger:              &LedgerRepository,
+			Payout:              &PayoutRepository,
+			Wallet:              &WalletRepository,
+			Invoice:             &InvoiceRepository,
+			Session:             &SessionRepository,
+			UserToken:           &UserTokenRepository,
+			LoginAttempt:        &LoginAttemptRepository,
+		},
+	}
 )